@@ -7,12 +7,27 @@ require (
 	github.com/diegoholiveira/jsonlogic v1.0.1-0.20200220175622-ab7989be08b9
 	github.com/eclipse/paho.mqtt.golang v1.3.5
 	github.com/edgexfoundry/go-mod-bootstrap/v2 v2.0.0
+	github.com/edgexfoundry/go-mod-configuration/v2 v2.0.0
 	github.com/edgexfoundry/go-mod-core-contracts/v2 v2.0.0
 	github.com/edgexfoundry/go-mod-messaging/v2 v2.0.1
 	github.com/edgexfoundry/go-mod-registry/v2 v2.0.0
 	github.com/fxamacker/cbor/v2 v2.2.0
 	github.com/gomodule/redigo v2.0.0+incompatible
-	github.com/google/uuid v1.2.0
+	github.com/google/cel-go v0.12.6
+	github.com/google/uuid v1.3.0
 	github.com/gorilla/mux v1.8.0
-	github.com/stretchr/testify v1.7.0
+	github.com/gorilla/websocket v1.4.2
+	github.com/gosnmp/gosnmp v1.32.0
+	github.com/hashicorp/golang-lru v0.5.3 // indirect
+	github.com/mattn/go-isatty v0.0.17 // indirect
+	github.com/rabbitmq/amqp091-go v1.9.0
+	github.com/segmentio/kafka-go v0.3.5
+	github.com/stretchr/objx v0.5.1 // indirect
+	github.com/stretchr/testify v1.8.2
+	github.com/vmihailenco/msgpack/v5 v5.3.4
+	golang.org/x/net v0.11.0 // indirect
+	golang.org/x/sys v0.9.0
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/protobuf v1.30.0
 )
@@ -23,6 +23,7 @@ import (
 type BackgroundMessage struct {
 	PublishTopic string
 	Payload      types.MessageEnvelope
+	PublishQoS   *byte
 }
 
 func (bg BackgroundMessage) Topic() string {
@@ -32,3 +33,10 @@ func (bg BackgroundMessage) Topic() string {
 func (bg BackgroundMessage) Message() types.MessageEnvelope {
 	return bg.Payload
 }
+
+func (bg BackgroundMessage) QoS() (byte, bool) {
+	if bg.PublishQoS == nil {
+		return 0, false
+	}
+	return *bg.PublishQoS, true
+}
@@ -18,40 +18,143 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"sync"
+
 	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 )
 
 type backgroundPublisher struct {
-	topic  string
-	output chan<- interfaces.BackgroundMessage
+	topic       string
+	contentType string
+	qos         *byte
+	output      chan<- interfaces.BackgroundMessage
 }
 
 // Publish provided message through the configured MessageBus output
 func (pub *backgroundPublisher) Publish(payload []byte, context interfaces.AppFunctionContext) error {
+	return pub.PublishWithOptions(payload, context, interfaces.BackgroundPublishOptions{})
+}
+
+// PublishWithOptions publishes the provided message through the configured MessageBus output, overriding the
+// publisher's topic, content type or QoS with those set in the options
+func (pub *backgroundPublisher) PublishWithOptions(payload []byte, context interfaces.AppFunctionContext, options interfaces.BackgroundPublishOptions) error {
+	contentType := options.ContentType
+	if len(contentType) == 0 {
+		contentType = pub.contentType
+	}
+	if len(contentType) == 0 {
+		contentType = context.InputContentType()
+	}
+
 	outputEnvelope := types.MessageEnvelope{
 		CorrelationID: context.CorrelationID(),
 		Payload:       payload,
-		ContentType:   context.InputContentType(),
+		ContentType:   contentType,
+	}
+
+	topic := options.Topic
+	if len(topic) == 0 {
+		topic = pub.topic
 	}
 
-	topic, err := context.ApplyValues(pub.topic)
+	topic, err := context.ApplyValues(topic)
 
 	if err != nil {
 		return fmt.Errorf("Failed to prepare topic for publishing: %s", err.Error())
 	}
 
+	qos := options.QoS
+	if qos == nil {
+		qos = pub.qos
+	}
+
 	pub.output <- BackgroundMessage{
 		Payload:      outputEnvelope,
 		PublishTopic: topic,
+		PublishQoS:   qos,
 	}
 
 	return nil
 }
 
 func newBackgroundPublisher(baseTopic string, capacity int) (<-chan interfaces.BackgroundMessage, interfaces.BackgroundPublisher) {
-	backgroundChannel := make(chan interfaces.BackgroundMessage, capacity)
-	return backgroundChannel, &backgroundPublisher{topic: baseTopic, output: backgroundChannel}
+	return newBackgroundPublisherWithOptions(interfaces.BackgroundPublisherOptions{Capacity: capacity, Topic: baseTopic})
+}
+
+func newBackgroundPublisherWithOptions(options interfaces.BackgroundPublisherOptions) (<-chan interfaces.BackgroundMessage, *backgroundPublisher) {
+	backgroundChannel := make(chan interfaces.BackgroundMessage, options.Capacity)
+	return backgroundChannel, &backgroundPublisher{
+		topic:       options.Topic,
+		contentType: options.ContentType,
+		qos:         options.QoS,
+		output:      backgroundChannel,
+	}
+}
+
+// backgroundPublishers merges the messages queued by each of the service's BackgroundPublishers onto the single
+// channel consumed by the trigger, so publishers with different capacities and topics don't share a queue.
+// The messages are forwarded until the service is stopped.
+type backgroundPublishers struct {
+	appCtx context.Context
+	output chan interfaces.BackgroundMessage
+	named  map[string]interfaces.BackgroundPublisher
+	mutex  sync.Mutex
+}
+
+func newBackgroundPublishers(appCtx context.Context) *backgroundPublishers {
+	return &backgroundPublishers{
+		appCtx: appCtx,
+		output: make(chan interfaces.BackgroundMessage),
+		named:  make(map[string]interfaces.BackgroundPublisher),
+	}
+}
+
+// add creates a publisher with the options and forwards its messages to the output. Publishers with a name are
+// registered so they can be looked up, in which case the name must be unique.
+func (publishers *backgroundPublishers) add(name string, options interfaces.BackgroundPublisherOptions) (interfaces.BackgroundPublisher, error) {
+	publishers.mutex.Lock()
+	defer publishers.mutex.Unlock()
+
+	if len(name) > 0 {
+		if _, exists := publishers.named[name]; exists {
+			return nil, fmt.Errorf("background publisher '%s' already exists", name)
+		}
+	}
+
+	queue, pub := newBackgroundPublisherWithOptions(options)
+	if len(name) > 0 {
+		publishers.named[name] = pub
+	}
+
+	go publishers.forward(queue)
+
+	return pub, nil
+}
+
+// forward passes the messages queued by a publisher to the output until the service is stopped
+func (publishers *backgroundPublishers) forward(queue <-chan interfaces.BackgroundMessage) {
+	for {
+		select {
+		case <-publishers.appCtx.Done():
+			return
+		case message := <-queue:
+			select {
+			case publishers.output <- message:
+			case <-publishers.appCtx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (publishers *backgroundPublishers) get(name string) (interfaces.BackgroundPublisher, bool) {
+	publishers.mutex.Lock()
+	defer publishers.mutex.Unlock()
+
+	pub, exists := publishers.named[name]
+	return pub, exists
 }
@@ -18,8 +18,10 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -106,3 +108,67 @@ func TestPublish_Topic_Formatting_Error(t *testing.T) {
 
 	require.Equal(t, fmt.Sprintf("Failed to prepare topic for publishing: failed to replace all context placeholders in input ('%s' after replacements)", topic), err.Error())
 }
+
+func TestPublishWithOptions(t *testing.T) {
+	qos := byte(2)
+	background, pub := newBackgroundPublisherWithOptions(interfaces.BackgroundPublisherOptions{
+		Capacity:    1,
+		Topic:       "default",
+		ContentType: "text/plain",
+	})
+
+	appCtx := appfunction.NewContext("id", nil, "type")
+	appCtx.AddValue("context-key", "replaced")
+
+	tests := []struct {
+		Name                string
+		Options             interfaces.BackgroundPublishOptions
+		ExpectedTopic       string
+		ExpectedContentType string
+		ExpectedQoSSet      bool
+	}{
+		{"Publisher defaults", interfaces.BackgroundPublishOptions{}, "default", "text/plain", false},
+		{"Overridden", interfaces.BackgroundPublishOptions{Topic: "{context-key}/topic", ContentType: "application/json", QoS: &qos}, "replaced/topic", "application/json", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			require.NoError(t, pub.PublishWithOptions([]byte("something"), appCtx, test.Options))
+
+			msgs := <-background
+			assert.Equal(t, test.ExpectedTopic, msgs.Topic())
+			assert.Equal(t, test.ExpectedContentType, msgs.Message().ContentType)
+			actualQoS, set := msgs.QoS()
+			assert.Equal(t, test.ExpectedQoSSet, set)
+			if set {
+				assert.Equal(t, qos, actualQoS)
+			}
+		})
+	}
+}
+
+func TestBackgroundPublishersStopForwarding(t *testing.T) {
+	appCtx, cancel := context.WithCancel(context.Background())
+	publishers := newBackgroundPublishers(appCtx)
+
+	pub, err := publishers.add("", interfaces.BackgroundPublisherOptions{Capacity: 1, Topic: "topic"})
+	require.NoError(t, err)
+
+	require.NoError(t, pub.Publish([]byte("before"), appfunction.NewContext("1", nil, "type")))
+	select {
+	case message := <-publishers.output:
+		assert.Equal(t, []byte("before"), message.Message().Payload)
+	case <-time.After(time.Second):
+		require.Fail(t, "message not forwarded")
+	}
+
+	// Messages are no longer forwarded once the service is stopped
+	cancel()
+	require.NoError(t, pub.Publish([]byte("after"), appfunction.NewContext("2", nil, "type")))
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case message := <-publishers.output:
+		assert.Fail(t, "message forwarded after the service stopped", string(message.Message().Payload))
+	default:
+	}
+}
@@ -17,9 +17,11 @@
 package app
 
 import (
+	"compress/gzip"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms"
@@ -35,6 +37,7 @@ const (
 	SourceNames         = "sourcenames"
 	ResourceNames       = "resourcenames"
 	FilterOut           = "filterout"
+	MatchMode           = "matchmode"
 	EncryptionKey       = "key"
 	InitVector          = "initvector"
 	Url                 = "url"
@@ -57,6 +60,8 @@ const (
 	MediaType           = "mediatype"
 	Rule                = "rule"
 	BatchThreshold      = "batchthreshold"
+	ByteThreshold       = "bytethreshold"
+	FlushName           = "flushname"
 	TimeInterval        = "timeinterval"
 	HeaderName          = "headername"
 	SecretPath          = "secretpath"
@@ -68,9 +73,11 @@ const (
 	TransformType       = "type"
 	TransformXml        = "xml"
 	TransformJson       = "json"
+	TransformMsgPack    = "msgpack"
 	AuthMode            = "authmode"
 	Tags                = "tags"
 	ResponseContentType = "responsecontenttype"
+	ResponseTopic       = "responsetopic"
 	Algorithm           = "algorithm"
 	CompressGZIP        = "gzip"
 	CompressZLIB        = "zlib"
@@ -79,12 +86,90 @@ const (
 	BatchByCount        = "bycount"
 	BatchByTime         = "bytime"
 	BatchByTimeAndCount = "bytimecount"
+	BatchByBytes        = "bybytes"
+	Host                = "host"
+	Port                = "port"
+	From                = "from"
+	To                  = "to"
+	Subject             = "subject"
+	MessageTemplate     = "messagetemplate"
+	Offset              = "offset"
+	NtpServer           = "ntpserver"
+	RefreshInterval     = "refreshinterval"
+	Fields              = "fields"
+	Template            = "template"
+	ContractName        = "contractname"
+	ContractVersion     = "contractversion"
+	Schema              = "schema"
+	StopOnViolation     = "stoponviolation"
+	Sender              = "sender"
+	Category            = "category"
+	Labels              = "labels"
+	Severity            = "severity"
+	Content             = "content"
+	ContentType         = "contenttype"
+	Description         = "description"
+	StatusTopic         = "statustopic"
+	OnlinePayload       = "onlinepayload"
+	OfflinePayload      = "offlinepayload"
+	BufferDirectory     = "bufferdirectory"
+	MaxBufferedMessages = "maxbufferedmessages"
+	Directory           = "directory"
+	FilePattern         = "filepattern"
+	RemoveAfterUpload   = "removeafterupload"
+	Expression          = "expression"
+	Variables           = "variables"
+	OutputResourceName  = "outputresourcename"
+	DropInvalid         = "dropinvalid"
+	OutputSourceName    = "outputsourcename"
+	TagKeys             = "tagkeys"
+	HashKey             = "hashkey"
+	Scheme              = "scheme"
+	MaxWidth            = "maxwidth"
+	MaxHeight           = "maxheight"
+	Format              = "format"
+	Quality             = "quality"
+	MaxBytes            = "maxbytes"
+	BlockSize           = "blocksize"
+	FullPayloadInterval = "fullpayloadinterval"
+	ResultTopic         = "resulttopic"
+	CorrelationField    = "correlationfield"
+	Timeout             = "timeout"
+	GroupId             = "groupid"
+	EdgeNodeId          = "edgenodeid"
+	EntityType          = "entitytype"
+	EntityId            = "entityid"
+	JSONLDContext       = "jsonldcontext"
+	DatastreamName      = "datastreamname"
+	Append              = "append"
+	Exchange            = "exchange"
+	RoutingKey          = "routingkey"
+	Persistent          = "persistent"
+	Index               = "index"
+	MaxRetries          = "maxretries"
+	InitialBackoff      = "initialbackoff"
+
+	PrimaryCheckInterval = "primarycheckinterval"
+	CompressionLevel     = "level"
+	Dictionary           = "dictionary"
+	LatencyHeaders       = "latencyheaders"
+	DefaultProfileName   = "defaultprofilename"
+	SourceName           = "sourcename"
+	MaxConcurrency       = "maxconcurrency"
+	Headers              = "headers"
+	VerifyUrl            = "verifyurl"
+	VerifyContains       = "verifycontains"
+	Checksum             = "checksum"
 )
 
 // Configurable contains the helper functions that return the function pointers for building the configurable function pipeline.
 // They transform the parameters map from the Pipeline configuration in to the actual actual parameters required by the function.
 type Configurable struct {
 	lc logger.LoggingClient
+	// flushers are the functions created which accumulate data, so it can be flushed when the service stops
+	flushers []interfaces.Flusher
+	// namedFlushers are the flushers given a name, so each can be flushed on its own when requested
+	namedFlushers map[string]interfaces.Flusher
 }
 
 // NewConfigurable returns a new instance of Configurable
@@ -162,7 +247,69 @@ func (app *Configurable) FilterByResourceName(parameters map[string]string) inte
 	return transform.FilterByResourceName
 }
 
-// Transform transforms an EdgeX event to XML or JSON based on specified transform type.
+// CoerceReadingTypes validates each reading's value against the ValueType declared in its Device Profile, retrieved from
+// Core Metadata, and normalizes valid values to the EdgeX format for that type. The optional DropInvalid parameter,
+// which defaults to true, determines whether readings whose values can't be parsed are dropped or passed on with the
+// Event tagged with the violations.
+// This function will return an error and stop the pipeline if a non-edgex event is received, if no data is received or
+// if Core Metadata can't be reached.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) CoerceReadingTypes(parameters map[string]string) interfaces.AppFunction {
+	dropInvalid := true
+	dropVal, ok := parameters[DropInvalid]
+	if ok {
+		var err error
+		dropInvalid, err = strconv.ParseBool(dropVal)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", dropVal, DropInvalid, err.Error())
+			return nil
+		}
+	}
+
+	transform := transforms.NewReadingTypeCoercion(dropInvalid)
+	return transform.Coerce
+}
+
+// Redact hashes, masks or drops, as specified by the Mode parameter, the readings for the ResourceNames parameter and
+// the tags for the TagKeys parameter, i.e. serial numbers or GPS coordinates, so that raw identifiers don't leave the site.
+// At least one of ResourceNames or TagKeys must be specified. When hashing, the optional HashKey parameter, or the key
+// stored in the Secret Store at the SecretPath and SecretName parameters, is used to hash with HMAC-SHA256.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) Redact(parameters map[string]string) interfaces.AppFunction {
+	mode, ok := parameters[Mode]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for Redact", Mode)
+		return nil
+	}
+
+	resourceNames := util.DeleteEmptyAndTrim(strings.FieldsFunc(parameters[ResourceNames], util.SplitComma))
+	tagKeys := util.DeleteEmptyAndTrim(strings.FieldsFunc(parameters[TagKeys], util.SplitComma))
+	hashKey := parameters[HashKey]
+	secretPath := parameters[SecretPath]
+	secretName := parameters[SecretName]
+
+	if len(hashKey) > 0 && (len(secretPath) > 0 || len(secretName) > 0) {
+		app.lc.Errorf("'%s' and '%s'/'%s' can not both be set for Redact", HashKey, SecretPath, SecretName)
+		return nil
+	}
+
+	var transform *transforms.Redact
+	var err error
+	if len(secretPath) > 0 || len(secretName) > 0 {
+		transform, err = transforms.NewRedactWithSecrets(mode, resourceNames, tagKeys, secretPath, secretName)
+	} else {
+		transform, err = transforms.NewRedact(mode, resourceNames, tagKeys, hashKey)
+	}
+
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for Redact: %s", err.Error())
+		return nil
+	}
+
+	return transform.Redact
+}
+
+// Transform transforms an EdgeX event to XML, JSON or MsgPack based on specified transform type.
 // It will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
 // This function is a configuration function and returns a function pointer.
 func (app *Configurable) Transform(parameters map[string]string) interfaces.AppFunction {
@@ -179,14 +326,399 @@ func (app *Configurable) Transform(parameters map[string]string) interfaces.AppF
 		return transform.TransformToXML
 	case TransformJson:
 		return transform.TransformToJSON
+	case TransformMsgPack:
+		return transform.TransformToMsgPack
 	default:
 		app.lc.Errorf(
-			"Invalid transform type '%s'. Must be '%s' or '%s'",
+			"Invalid transform type '%s'. Must be '%s', '%s' or '%s'",
 			transformType,
 			TransformXml,
-			TransformJson)
+			TransformJson,
+			TransformMsgPack)
+		return nil
+	}
+}
+
+// ExtractBinaryPayload extracts the raw binary value of an Event's binary reading, i.e. an image, for export as is.
+// The optional ResourceName parameter selects the binary reading, otherwise the first binary reading is used. The
+// response content type is set to the reading's media type.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ExtractBinaryPayload(parameters map[string]string) interfaces.AppFunction {
+	transform := transforms.NewBinaryPayload(strings.TrimSpace(parameters[ResourceName]))
+	return transform.ExtractBinaryPayload
+}
+
+// ProcessImages shrinks the JPEG and PNG images in an Event's binary readings, i.e. camera frames, before export.
+// The optional MaxWidth and MaxHeight parameters are the dimensions images are resized to fit within, Format is the
+// format, jpeg or png, they are re-encoded to, Quality is the JPEG quality and MaxBytes caps their encoded size.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ProcessImages(parameters map[string]string) interfaces.AppFunction {
+	options := transforms.ImageOptions{
+		Format: strings.TrimSpace(parameters[Format]),
+	}
+
+	intParameters := map[string]*int{
+		MaxWidth:  &options.MaxWidth,
+		MaxHeight: &options.MaxHeight,
+		Quality:   &options.Quality,
+		MaxBytes:  &options.MaxBytes,
+	}
+
+	for name, target := range intParameters {
+		value := strings.TrimSpace(parameters[name])
+		if len(value) == 0 {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to an int for '%s' parameter for ProcessImages: %s", value, name, err.Error())
+			return nil
+		}
+		*target = parsed
+	}
+
+	transform, err := transforms.NewImage(options)
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for ProcessImages: %s", err.Error())
+		return nil
+	}
+
+	return transform.ProcessImages
+}
+
+// EncodeDelta replaces the data, typically a large payload that changes little such as a configuration blob or an
+// image, with the delta against the previous payload exported for the same device. The optional BlockSize parameter
+// is the size of the chunks matched against the previous payload and the optional FullPayloadInterval parameter
+// specifies that every Nth payload per device is sent in full. Receivers re-create the payloads using the
+// transforms.DeltaReassembler.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) EncodeDelta(parameters map[string]string) interfaces.AppFunction {
+	var blockSize, fullPayloadInterval int
+
+	intParameters := map[string]*int{
+		BlockSize:           &blockSize,
+		FullPayloadInterval: &fullPayloadInterval,
+	}
+
+	for name, target := range intParameters {
+		value := strings.TrimSpace(parameters[name])
+		if len(value) == 0 {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to an int for '%s' parameter for EncodeDelta: %s", value, name, err.Error())
+			return nil
+		}
+		*target = parsed
+	}
+
+	transform, err := transforms.NewDeltaEncoder(blockSize, fullPayloadInterval)
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for EncodeDelta: %s", err.Error())
+		return nil
+	}
+
+	return transform.EncodeDelta
+}
+
+// AddRevision wraps the Events in records with a per-device revision number which increases with each record, and
+// passes on Core Metadata's device removal system events as tombstone records, so downstream stores can be rebuilt
+// deterministically from the exported stream. The revisions are persisted when State is enabled.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) AddRevision(_ map[string]string) interfaces.AppFunction {
+	transform := transforms.NewEventSourcer()
+	return transform.AddRevision
+}
+
+// ForwardToEKuiper sends the Event to an eKuiper rules engine instance, either to the REST (httppush) source at the
+// Url parameter or the MQTT source's Topic on the broker at the BrokerAddress parameter. When the ResultTopic
+// parameter is set the pipeline waits for the rule result for the Event, published by the rule's MQTT sink to that
+// topic, and continues with it. The rule must select the Event's Id into the result as the field specified by the
+// optional CorrelationField parameter, which defaults to "id". The optional Timeout parameter, which defaults to 5s,
+// is how long to wait for the result. The optional ClientID, AuthMode and SecretPath parameters are used to connect
+// to the broker.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ForwardToEKuiper(parameters map[string]string) interfaces.AppFunction {
+	config := transforms.EKuiperConfig{
+		IngestURL:        strings.TrimSpace(parameters[Url]),
+		BrokerAddress:    strings.TrimSpace(parameters[BrokerAddress]),
+		ClientId:         strings.TrimSpace(parameters[ClientID]),
+		AuthMode:         strings.TrimSpace(parameters[AuthMode]),
+		SecretPath:       strings.TrimSpace(parameters[SecretPath]),
+		IngestTopic:      strings.TrimSpace(parameters[Topic]),
+		ResultTopic:      strings.TrimSpace(parameters[ResultTopic]),
+		CorrelationField: strings.TrimSpace(parameters[CorrelationField]),
+	}
+
+	if timeout := strings.TrimSpace(parameters[Timeout]); len(timeout) > 0 {
+		var err error
+		config.Timeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a duration for '%s' parameter for ForwardToEKuiper: %s", timeout, Timeout, err.Error())
+			return nil
+		}
+	}
+
+	transform, err := transforms.NewEKuiper(config)
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for ForwardToEKuiper: %s", err.Error())
+		return nil
+	}
+
+	return transform.Forward
+}
+
+// ConvertToNGSILD converts the Event to an NGSI-LD entity with a Property for each reading. The optional EntityType
+// and EntityId parameters, which may contain context value placeholders such as {devicename}, default to the device
+// profile name and "urn:ngsi-ld:{profilename}:{devicename}". The optional JSONLDContext parameter is the entity's
+// @context URL, which defaults to the NGSI-LD core context.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ConvertToNGSILD(parameters map[string]string) interfaces.AppFunction {
+	transform := transforms.NewNGSILD(app.ngsiLDConfig(parameters))
+	return transform.ConvertToEntity
+}
+
+// NGSILDExport creates or updates the NGSI-LD entity for the Event, or the entities converted by a previous
+// function, in the NGSI-LD context broker, such as FIWARE Orion-LD, at the Url parameter. The optional EntityType,
+// EntityId and JSONLDContext parameters are as for ConvertToNGSILD. The optional PersistOnError, HeaderName,
+// SecretPath and SecretName parameters are as for HTTPExport.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) NGSILDExport(parameters map[string]string) interfaces.AppFunction {
+	config := app.ngsiLDConfig(parameters)
+	config.BrokerURL = strings.TrimSpace(parameters[Url])
+	if len(config.BrokerURL) == 0 {
+		app.lc.Errorf("Could not find '%s' parameter for NGSILDExport", Url)
+		return nil
+	}
+
+	if persistOnError := strings.TrimSpace(parameters[PersistOnError]); len(persistOnError) > 0 {
+		var err error
+		config.PersistOnError, err = strconv.ParseBool(persistOnError)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter for NGSILDExport: %s", persistOnError, PersistOnError, err.Error())
+			return nil
+		}
+	}
+
+	config.HTTPHeaderName = strings.TrimSpace(parameters[HeaderName])
+	config.SecretPath = strings.TrimSpace(parameters[SecretPath])
+	config.SecretName = strings.TrimSpace(parameters[SecretName])
+
+	transform := transforms.NewNGSILD(config)
+	return transform.ExportToContextBroker
+}
+
+// ConvertToV1 converts V2 Events to EdgeX V1 Events in JSON, so they can be republished on the topics and endpoints
+// of V1 services, i.e. by MQTTExport or HTTPExport. The Event's profile and source names are kept as tags.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ConvertToV1(_ map[string]string) interfaces.AppFunction {
+	transform := transforms.NewV1Bridge(transforms.V1BridgeConfig{})
+	return transform.ConvertToV1
+}
+
+// ConvertFromV1 converts EdgeX V1 Events in JSON, i.e. received from V1 topics, to V2 Events for the rest of the
+// pipeline. The optional ProfileNames parameter, a comma separated list of 'devicename:profilename', and the
+// DefaultProfileName parameter set the profile names V1 Events don't have, which default to the device name. The
+// optional SourceName parameter defaults to the name of the Event's first reading.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ConvertFromV1(parameters map[string]string) interfaces.AppFunction {
+	config, ok := app.v1BridgeConfig(parameters)
+	if !ok {
+		return nil
+	}
+
+	transform := transforms.NewV1Bridge(config)
+	return transform.ConvertFromV1
+}
+
+// V1Export sends V2 Events, converted to V1, or V1 Events to the V1 Core Data service at the Url parameter, i.e.
+// http://edgex-core-data:48080. The optional PersistOnError, HeaderName, SecretPath and SecretName parameters are
+// as for HTTPExport.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) V1Export(parameters map[string]string) interfaces.AppFunction {
+	config := transforms.V1BridgeConfig{
+		URL:            strings.TrimSpace(parameters[Url]),
+		HTTPHeaderName: strings.TrimSpace(parameters[HeaderName]),
+		SecretPath:     strings.TrimSpace(parameters[SecretPath]),
+		SecretName:     strings.TrimSpace(parameters[SecretName]),
+	}
+	if len(config.URL) == 0 {
+		app.lc.Errorf("Could not find '%s' parameter for V1Export", Url)
+		return nil
+	}
+
+	if persistOnError := strings.TrimSpace(parameters[PersistOnError]); len(persistOnError) > 0 {
+		var err error
+		config.PersistOnError, err = strconv.ParseBool(persistOnError)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter for V1Export: %s", persistOnError, PersistOnError, err.Error())
+			return nil
+		}
+	}
+
+	transform := transforms.NewV1Bridge(config)
+	return transform.ExportToV1
+}
+
+func (app *Configurable) v1BridgeConfig(parameters map[string]string) (transforms.V1BridgeConfig, bool) {
+	config := transforms.V1BridgeConfig{
+		DefaultProfileName: strings.TrimSpace(parameters[DefaultProfileName]),
+		SourceName:         strings.TrimSpace(parameters[SourceName]),
+	}
+
+	profileNames := strings.TrimSpace(parameters[ProfileNames])
+	if len(profileNames) == 0 {
+		return config, true
+	}
+
+	config.ProfileNames = make(map[string]string)
+	for _, mapping := range util.DeleteEmptyAndTrim(strings.FieldsFunc(profileNames, util.SplitComma)) {
+		deviceProfile := util.DeleteEmptyAndTrim(strings.FieldsFunc(mapping, util.SplitColon))
+		if len(deviceProfile) != 2 || len(deviceProfile[0]) == 0 || len(deviceProfile[1]) == 0 {
+			app.lc.Errorf("Bad '%s' parameter format. Expect comma separated list of 'devicename:profilename'. Got '%s'", ProfileNames, profileNames)
+			return transforms.V1BridgeConfig{}, false
+		}
+		config.ProfileNames[deviceProfile[0]] = deviceProfile[1]
+	}
+
+	return config, true
+}
+
+func (app *Configurable) ngsiLDConfig(parameters map[string]string) transforms.NGSILDConfig {
+	return transforms.NGSILDConfig{
+		EntityType: strings.TrimSpace(parameters[EntityType]),
+		EntityId:   strings.TrimSpace(parameters[EntityId]),
+		Context:    strings.TrimSpace(parameters[JSONLDContext]),
+	}
+}
+
+// SensorThingsExport creates an OGC SensorThings API Observation for each of the Event's readings in the service at
+// the Url parameter, i.e. http://frost:8080/FROST-Server/v1.1. Each Observation is added to the existing Datastream
+// named by the optional DatastreamName parameter, which may contain placeholders such as {devicename} and
+// {resourcename} and defaults to "{devicename}.{resourcename}". Readings without a Datastream are skipped. The
+// optional PersistOnError, HeaderName, SecretPath and SecretName parameters are as for HTTPExport.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) SensorThingsExport(parameters map[string]string) interfaces.AppFunction {
+	config := transforms.SensorThingsConfig{
+		URL:            strings.TrimSpace(parameters[Url]),
+		DatastreamName: strings.TrimSpace(parameters[DatastreamName]),
+		HTTPHeaderName: strings.TrimSpace(parameters[HeaderName]),
+		SecretPath:     strings.TrimSpace(parameters[SecretPath]),
+		SecretName:     strings.TrimSpace(parameters[SecretName]),
+	}
+
+	if persistOnError := strings.TrimSpace(parameters[PersistOnError]); len(persistOnError) > 0 {
+		var err error
+		config.PersistOnError, err = strconv.ParseBool(persistOnError)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter for SensorThingsExport: %s", persistOnError, PersistOnError, err.Error())
+			return nil
+		}
+	}
+
+	transform, err := transforms.NewSensorThings(config)
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for SensorThingsExport: %s", err.Error())
+		return nil
+	}
+
+	return transform.Export
+}
+
+// ElasticsearchExport bulk indexes the data from the previous function, i.e. an Event or a batch of documents, in
+// Elasticsearch or OpenSearch. If no previous function exists, then the event that triggered the pipeline will be used.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ElasticsearchExport(parameters map[string]string) interfaces.AppFunction {
+	config := transforms.ElasticsearchConfig{
+		URL:            strings.TrimSpace(parameters[Url]),
+		Index:          strings.TrimSpace(parameters[Index]),
+		AuthMode:       strings.TrimSpace(parameters[AuthMode]),
+		SecretPath:     strings.TrimSpace(parameters[SecretPath]),
+		Timeout:        strings.TrimSpace(parameters[Timeout]),
+		InitialBackoff: strings.TrimSpace(parameters[InitialBackoff]),
+	}
+
+	var err error
+	if value := strings.TrimSpace(parameters[MaxRetries]); len(value) > 0 {
+		config.MaxRetries, err = strconv.Atoi(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to an int for '%s' parameter for ElasticsearchExport: %s", value, MaxRetries, err.Error())
+			return nil
+		}
+	}
+
+	if value := strings.TrimSpace(parameters[SkipVerify]); len(value) > 0 {
+		config.SkipCertVerify, err = strconv.ParseBool(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter for ElasticsearchExport: %s", value, SkipVerify, err.Error())
+			return nil
+		}
+	}
+
+	if value := strings.TrimSpace(parameters[PersistOnError]); len(value) > 0 {
+		config.PersistOnError, err = strconv.ParseBool(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter for ElasticsearchExport: %s", value, PersistOnError, err.Error())
+			return nil
+		}
+	}
+
+	transform, err := transforms.NewElasticsearchSender(config)
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for ElasticsearchExport: %s", err.Error())
+		return nil
+	}
+
+	return transform.BulkIndex
+}
+
+// WebSocketExport sends the data from the previous function to a remote WebSocket endpoint, one message each. If no
+// previous function exists, then the event that triggered the pipeline will be used. The optional HeaderName,
+// SecretPath and SecretName parameters set a handshake request header to the secret, i.e. for authentication.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) WebSocketExport(parameters map[string]string) interfaces.AppFunction {
+	config := transforms.WebSocketConfig{
+		URL:        strings.TrimSpace(parameters[Url]),
+		HeaderName: strings.TrimSpace(parameters[HeaderName]),
+		SecretPath: strings.TrimSpace(parameters[SecretPath]),
+		SecretName: strings.TrimSpace(parameters[SecretName]),
+		Timeout:    strings.TrimSpace(parameters[Timeout]),
+	}
+
+	var err error
+	if value := strings.TrimSpace(parameters[SkipVerify]); len(value) > 0 {
+		config.SkipCertVerify, err = strconv.ParseBool(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter for WebSocketExport: %s", value, SkipVerify, err.Error())
+			return nil
+		}
+	}
+
+	if value := strings.TrimSpace(parameters[PersistOnError]); len(value) > 0 {
+		config.PersistOnError, err = strconv.ParseBool(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter for WebSocketExport: %s", value, PersistOnError, err.Error())
+			return nil
+		}
+	}
+
+	transform, err := transforms.NewWebSocketSender(config)
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for WebSocketExport: %s", err.Error())
 		return nil
 	}
+
+	return transform.Send
+}
+
+// StreamExport publishes the data from the previous function to the WebSocket clients connected to the service's
+// /stream endpoint, i.e. local dashboards, and passes the data on to the next function. Requires Stream.Enabled.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) StreamExport(_ map[string]string) interfaces.AppFunction {
+	return transforms.NewStreamSender().Publish
 }
 
 // PushToCore pushes the provided value as an event to CoreData using the device name and reading name that have been set. If validation is turned on in
@@ -251,7 +783,8 @@ func (app *Configurable) PushToCore(parameters map[string]string) interfaces.App
 }
 
 // Compress compresses data received as either a string,[]byte, or json.Marshaller using the specified algorithm (GZIP or ZLIB)
-// and returns a base64 encoded string as a []byte.
+// and returns a base64 encoded string as a []byte. The optional level and, for ZLIB, preset dictionary tune the
+// trade-off between speed and compression ratio.
 // This function is a configuration function and returns a function pointer.
 func (app *Configurable) Compress(parameters map[string]string) interfaces.AppFunction {
 	algorithm, ok := parameters[Algorithm]
@@ -260,7 +793,32 @@ func (app *Configurable) Compress(parameters map[string]string) interfaces.AppFu
 		return nil
 	}
 
-	transform := transforms.Compression{}
+	transform := transforms.NewCompression()
+
+	levelVal := strings.TrimSpace(parameters[CompressionLevel])
+	dictionary := parameters[Dictionary]
+	if len(levelVal) > 0 || len(dictionary) > 0 {
+		var err error
+		level := gzip.DefaultCompression
+		if len(levelVal) > 0 {
+			level, err = strconv.Atoi(levelVal)
+			if err != nil {
+				app.lc.Errorf("Could not parse '%s' to an int for '%s' parameter for Compress: %s", levelVal, CompressionLevel, err.Error())
+				return nil
+			}
+		}
+
+		if len(dictionary) > 0 && strings.ToLower(algorithm) != CompressZLIB {
+			app.lc.Errorf("The '%s' parameter for Compress is only supported by the '%s' algorithm", Dictionary, CompressZLIB)
+			return nil
+		}
+
+		transform, err = transforms.NewCompressionWithOptions(level, []byte(dictionary))
+		if err != nil {
+			app.lc.Errorf("Invalid parameters for Compress: %s", err.Error())
+			return nil
+		}
+	}
 
 	switch strings.ToLower(algorithm) {
 	case CompressGZIP:
@@ -279,6 +837,8 @@ func (app *Configurable) Compress(parameters map[string]string) interfaces.AppFu
 
 // Encrypt encrypts either a string, []byte, or json.Marshaller type using specified encryption
 // algorithm (AES only at this time). It will return a byte[] of the encrypted data.
+// If the ResourceNames parameter is specified only the values of the Event's Readings for those resources are encrypted.
+// If the Fields parameter is specified only those fields within the JSON data are encrypted.
 // This function is a configuration function and returns a function pointer.
 func (app *Configurable) Encrypt(parameters map[string]string) interfaces.AppFunction {
 	algorithm, ok := parameters[Algorithm]
@@ -312,15 +872,36 @@ func (app *Configurable) Encrypt(parameters map[string]string) interfaces.AppFun
 		return nil
 	}
 
+	// ResourceNames & Fields are optional and result in only portions of the data being encrypted.
+	resourceNames, encryptReadings := parameters[ResourceNames]
+	fields, encryptFields := parameters[Fields]
+	if encryptReadings && encryptFields {
+		app.lc.Errorf("'%s' and '%s' can not both be set in configuration", ResourceNames, Fields)
+		return nil
+	}
+
 	transform := transforms.Encryption{
 		EncryptionKey:        encryptionKey,
 		InitializationVector: initVector,
 		SecretPath:           secretPath,
 		SecretName:           secretName,
+		ResourceNames:        util.DeleteEmptyAndTrim(strings.FieldsFunc(resourceNames, util.SplitComma)),
+		Fields:               util.DeleteEmptyAndTrim(strings.FieldsFunc(fields, util.SplitComma)),
+	}
+
+	if encryptFields && len(transform.Fields) == 0 {
+		app.lc.Errorf("'%s' parameter for Encrypt can not be empty", Fields)
+		return nil
 	}
 
 	switch strings.ToLower(algorithm) {
 	case EncryptAES:
+		if encryptReadings {
+			return transform.EncryptReadingValuesWithAES
+		}
+		if encryptFields {
+			return transform.EncryptFieldsWithAES
+		}
 		return transform.EncryptWithAES
 	default:
 		app.lc.Errorf(
@@ -333,7 +914,11 @@ func (app *Configurable) Encrypt(parameters map[string]string) interfaces.AppFun
 
 // HTTPExport will send data from the previous function to the specified Endpoint via http POST or PUT. If no previous function exists,
 // then the event that triggered the pipeline will be used. Passing an empty string to the mimetype
-// method will default to application/json.
+// method will default to application/json. The optional Headers parameter is a comma separated list of 'name:value'
+// headers to add, where values in the form 'secret://<path>#<name>' are retrieved from the Secret Store. The optional
+// VerifyUrl parameter is a URL requested with GET after sending to confirm the destination accepted the data, whose
+// response must also contain the optional VerifyContains parameter when specified. The optional Checksum parameter,
+// crc32 or sha256, sends the checksum of the data in the X-Checksum-CRC32 or X-Checksum-SHA256 header.
 // This function is a configuration function and returns a function pointer.
 func (app *Configurable) HTTPExport(parameters map[string]string) interfaces.AppFunction {
 	options, method, err := app.processHttpExportParameters(parameters)
@@ -361,7 +946,8 @@ func (app *Configurable) HTTPExport(parameters map[string]string) interfaces.App
 
 //
 // MQTTExport will send data from the previous function to the specified Endpoint via MQTT publish. If no previous function exists,
-// then the event that triggered the pipeline will be used.
+// then the event that triggered the pipeline will be used. The optional Checksum parameter, crc32 or sha256, appends
+// the checksum of the data to each message as a trailing line.
 // This function is a configuration function and returns a function pointer.
 func (app *Configurable) MQTTExport(parameters map[string]string) interfaces.AppFunction {
 	var err error
@@ -433,6 +1019,11 @@ func (app *Configurable) MQTTExport(parameters map[string]string) interfaces.App
 	keepAlive := parameters[KeepAlive]
 	connectTimeout := parameters[ConnectTimeout]
 
+	// These are optional and a blank StatusTopic results in no status being published.
+	statusTopic := parameters[StatusTopic]
+	onlinePayload := parameters[OnlinePayload]
+	offlinePayload := parameters[OfflinePayload]
+
 	mqttConfig := transforms.MQTTSecretConfig{
 		Retain:         retain,
 		SkipCertVerify: skipCertVerify,
@@ -445,7 +1036,35 @@ func (app *Configurable) MQTTExport(parameters map[string]string) interfaces.App
 		SecretPath:     secretPath,
 		Topic:          topic,
 		AuthMode:       authMode,
+		StatusTopic:    statusTopic,
+		OnlinePayload:  onlinePayload,
+		OfflinePayload: offlinePayload,
+	}
+
+	// PrimaryCheckInterval is optional and a blank value results in the default interval being used.
+	mqttConfig.PrimaryCheckInterval = parameters[PrimaryCheckInterval]
+
+	// BufferDirectory is optional and a blank value results in messages not being buffered.
+	mqttConfig.BufferDirectory = parameters[BufferDirectory]
+	maxBufferedVal, ok := parameters[MaxBufferedMessages]
+	if ok {
+		mqttConfig.MaxBufferedMessages, err = strconv.Atoi(maxBufferedVal)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to an int for '%s' parameter: %s", maxBufferedVal, MaxBufferedMessages, err.Error())
+			return nil
+		}
+	}
+
+	// MaxConcurrency is optional and publishes aren't limited by default.
+	maxConcurrencyVal, ok := parameters[MaxConcurrency]
+	if ok {
+		mqttConfig.MaxConcurrency, err = parseMaxConcurrency(maxConcurrencyVal)
+		if err != nil {
+			app.lc.Errorf("MQTTExport %s", err.Error())
+			return nil
+		}
 	}
+
 	// PersistOnError is optional and is false by default.
 	persistOnError := false
 	value, ok := parameters[PersistOnError]
@@ -456,34 +1075,158 @@ func (app *Configurable) MQTTExport(parameters map[string]string) interfaces.App
 			return nil
 		}
 	}
+	// Checksum is optional and no checksum is added by default.
+	mqttConfig.Checksum, err = parseChecksum(parameters[Checksum])
+	if err != nil {
+		app.lc.Errorf("MQTTExport %s", err.Error())
+		return nil
+	}
+
 	transform := transforms.NewMQTTSecretSender(mqttConfig, persistOnError)
 	return transform.MQTTSend
 }
 
-// SetResponseData sets the response data to that passed in from the previous function and the response content type
-// to that set in the ResponseContentType configuration parameter. It will return an error and stop the pipeline if
-// data passed in is not of type []byte, string or json.Marshaller
+// EmailExport will send data from the previous function, or the configured message template, as an email to the
+// specified recipients via SMTP. If no previous function exists, then the event that triggered the pipeline will be used.
 // This function is a configuration function and returns a function pointer.
-func (app *Configurable) SetResponseData(parameters map[string]string) interfaces.AppFunction {
-	transform := transforms.ResponseData{}
-
-	value, ok := parameters[ResponseContentType]
-	if ok && len(value) > 0 {
-		transform.ResponseContentType = value
-	}
-
-	return transform.SetResponseData
-}
+func (app *Configurable) EmailExport(parameters map[string]string) interfaces.AppFunction {
+	var err error
 
-// Batch sets up Batching of events based on the specified mode parameter (BatchByCount, BatchByTime or BatchByTimeAndCount)
-// and mode specific parameters.
-// This function is a configuration function and returns a function pointer.
-func (app *Configurable) Batch(parameters map[string]string) interfaces.AppFunction {
-	mode, ok := parameters[Mode]
+	host, ok := parameters[Host]
 	if !ok {
-		app.lc.Errorf("Could not find '%s' parameter for Batch", Mode)
+		app.lc.Error("Could not find " + Host)
 		return nil
 	}
+	portVal, ok := parameters[Port]
+	if !ok {
+		app.lc.Error("Could not find " + Port)
+		return nil
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(portVal))
+	if err != nil {
+		app.lc.Errorf("Could not parse '%s' to an int for '%s' parameter: %s", portVal, Port, err.Error())
+		return nil
+	}
+	from, ok := parameters[From]
+	if !ok {
+		app.lc.Error("Could not find " + From)
+		return nil
+	}
+	to, ok := parameters[To]
+	if !ok {
+		app.lc.Error("Could not find " + To)
+		return nil
+	}
+
+	recipients := util.DeleteEmptyAndTrim(strings.FieldsFunc(to, util.SplitComma))
+	if len(recipients) == 0 {
+		app.lc.Errorf("'%s' parameter for EmailExport must contain at least one recipient", To)
+		return nil
+	}
+
+	// These are optional and blank values result in no authentication and the pipeline data being used as the body.
+	emailConfig := transforms.EmailConfig{
+		Host:            strings.TrimSpace(host),
+		Port:            port,
+		From:            strings.TrimSpace(from),
+		To:              recipients,
+		Subject:         parameters[Subject],
+		MessageTemplate: parameters[MessageTemplate],
+		AuthMode:        parameters[AuthMode],
+		SecretPath:      strings.TrimSpace(parameters[SecretPath]),
+	}
+
+	// PersistOnError is optional and is false by default.
+	persistOnError := false
+	value, ok := parameters[PersistOnError]
+	if ok {
+		persistOnError, err = strconv.ParseBool(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", value, PersistOnError, err.Error())
+			return nil
+		}
+	}
+
+	transform := transforms.NewEmailSender(emailConfig, persistOnError)
+	return transform.SendEmail
+}
+
+// SetResponseData sets the response data to that passed in from the previous function and the response content type
+// to that set in the ResponseContentType configuration parameter. Data not of type []byte or string is marshaled to
+// the format set in the optional Type parameter, json (default), xml or cbor. When the optional Append parameter is
+// true the data is added as a part of the response rather than replacing it. The optional ResponseTopic parameter
+// overrides the topic the trigger publishes the response to. It will return an error and stop the pipeline if the
+// data passed in can not be marshaled
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) SetResponseData(parameters map[string]string) interfaces.AppFunction {
+	transform := transforms.ResponseData{}
+
+	serialization, ok := parameters[TransformType]
+	if ok && len(serialization) > 0 {
+		var err error
+		transform, err = transforms.NewResponseDataWithSerialization(serialization)
+		if err != nil {
+			app.lc.Errorf("Invalid '%s' parameter for SetResponseData: %s", TransformType, err.Error())
+			return nil
+		}
+	}
+
+	value, ok := parameters[ResponseContentType]
+	if ok && len(value) > 0 {
+		transform.ResponseContentType = value
+	}
+
+	transform.ResponseTopic = strings.TrimSpace(parameters[ResponseTopic])
+
+	if appendVal := strings.TrimSpace(parameters[Append]); len(appendVal) > 0 {
+		var err error
+		transform.Append, err = strconv.ParseBool(appendVal)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", appendVal, Append, err.Error())
+			return nil
+		}
+	}
+
+	return transform.SetResponseData
+}
+
+// Batch sets up Batching of events based on the specified mode parameter (BatchByCount, BatchByTime, BatchByTimeAndCount
+// or BatchByBytes) and mode specific parameters. The optional ByteThreshold parameter also forwards the batch once its
+// size in bytes reaches the threshold in the other modes. Data batched by time is flushed when the service stops or
+// the flush is requested. The optional FlushName parameter names the batch, so it can be flushed on its own, i.e.
+// when a truck is leaving the site, which is only supported by the modes batching by time.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) Batch(parameters map[string]string) interfaces.AppFunction {
+	mode, ok := parameters[Mode]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for Batch", Mode)
+		return nil
+	}
+
+	byteThreshold := 0
+	if value, ok := parameters[ByteThreshold]; ok {
+		var err error
+		byteThreshold, err = strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || byteThreshold < 0 {
+			app.lc.Errorf("Could not parse '%s' to a non-negative int for '%s' parameter for Batch", value, ByteThreshold)
+			return nil
+		}
+	}
+
+	flushName := strings.TrimSpace(parameters[FlushName])
+	if len(flushName) > 0 {
+		if lowerMode := strings.ToLower(mode); lowerMode != BatchByTime && lowerMode != BatchByTimeAndCount {
+			app.lc.Errorf("'%s' parameter for Batch is only supported by the '%s' and '%s' modes", FlushName, BatchByTime, BatchByTimeAndCount)
+			return nil
+		}
+		if _, exists := app.namedFlushers[flushName]; exists {
+			app.lc.Errorf("'%s' parameter value '%s' for Batch is already used by another Batch", FlushName, flushName)
+			return nil
+		}
+	}
+
+	var transform *transforms.BatchConfig
+	var err error
 
 	switch strings.ToLower(mode) {
 	case BatchByCount:
@@ -501,11 +1244,10 @@ func (app *Configurable) Batch(parameters map[string]string) interfaces.AppFunct
 			return nil
 		}
 
-		transform, err := transforms.NewBatchByCount(thresholdValue)
+		transform, err = transforms.NewBatchByCount(thresholdValue)
 		if err != nil {
 			app.lc.Error(err.Error())
 		}
-		return transform.Batch
 
 	case BatchByTime:
 		timeInterval, ok := parameters[TimeInterval]
@@ -514,11 +1256,12 @@ func (app *Configurable) Batch(parameters map[string]string) interfaces.AppFunct
 			return nil
 		}
 
-		transform, err := transforms.NewBatchByTime(timeInterval)
+		transform, err = transforms.NewBatchByTime(timeInterval)
 		if err != nil {
 			app.lc.Error(err.Error())
+		} else {
+			app.flushers = append(app.flushers, transform)
 		}
-		return transform.Batch
 
 	case BatchByTimeAndCount:
 		timeInterval, ok := parameters[TimeInterval]
@@ -535,21 +1278,48 @@ func (app *Configurable) Batch(parameters map[string]string) interfaces.AppFunct
 		if err != nil {
 			app.lc.Errorf("Could not parse '%s' to an int for '%s' parameter: %s", batchThreshold, BatchThreshold, err.Error())
 		}
-		transform, err := transforms.NewBatchByTimeAndCount(timeInterval, thresholdValue)
+		transform, err = transforms.NewBatchByTimeAndCount(timeInterval, thresholdValue)
+		if err != nil {
+			app.lc.Error(err.Error())
+		} else {
+			app.flushers = append(app.flushers, transform)
+		}
+
+	case BatchByBytes:
+		if byteThreshold == 0 {
+			app.lc.Errorf("Could not find '%s' parameter for BatchByBytes", ByteThreshold)
+			return nil
+		}
+
+		transform, err = transforms.NewBatchByBytes(byteThreshold)
 		if err != nil {
 			app.lc.Error(err.Error())
+			return nil
 		}
-		return transform.Batch
 
 	default:
 		app.lc.Errorf(
-			"Invalid batch mode '%s'. Must be '%s', '%s' or '%s'",
+			"Invalid batch mode '%s'. Must be '%s', '%s', '%s' or '%s'",
 			mode,
 			BatchByCount,
 			BatchByTime,
-			BatchByTimeAndCount)
+			BatchByTimeAndCount,
+			BatchByBytes)
 		return nil
 	}
+
+	if transform != nil && byteThreshold > 0 {
+		transform.SetByteThreshold(byteThreshold)
+	}
+
+	if transform != nil && len(flushName) > 0 {
+		if app.namedFlushers == nil {
+			app.namedFlushers = make(map[string]interfaces.Flusher)
+		}
+		app.namedFlushers[flushName] = transform
+	}
+
+	return transform.Batch
 }
 
 // JSONLogic ...
@@ -599,6 +1369,383 @@ func (app *Configurable) AddTags(parameters map[string]string) interfaces.AppFun
 	return transform.AddTags
 }
 
+// AddLatencyTags adds tags with the milliseconds Events passed to the transform were queued before the functions
+// pipeline started processing them and have been processed for.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) AddLatencyTags(_ map[string]string) interfaces.AppFunction {
+	transform := transforms.NewTags(nil)
+	return transform.AddLatencyTags
+}
+
+// CorrectClockSkew adjusts the Origin timestamps of Events and their Readings by either the fixed offset specified
+// by the Offset parameter or the offset between the local clock and the NTP server specified by the NtpServer parameter.
+// Corrected Events are tagged with the offset that was applied.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) CorrectClockSkew(parameters map[string]string) interfaces.AppFunction {
+	offsetVal, hasOffset := parameters[Offset]
+	ntpServer, hasNtpServer := parameters[NtpServer]
+	ntpServer = strings.TrimSpace(ntpServer)
+
+	if hasOffset == hasNtpServer {
+		app.lc.Errorf("One and only one of '%s' or '%s' must be specified for CorrectClockSkew", Offset, NtpServer)
+		return nil
+	}
+
+	if hasOffset {
+		offset, err := time.ParseDuration(strings.TrimSpace(offsetVal))
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a duration for '%s' parameter: %s", offsetVal, Offset, err.Error())
+			return nil
+		}
+
+		transform := transforms.NewClockSkewWithOffset(offset)
+		return transform.CorrectTimestamps
+	}
+
+	if len(ntpServer) == 0 {
+		app.lc.Errorf("'%s' parameter for CorrectClockSkew can not be empty", NtpServer)
+		return nil
+	}
+
+	// RefreshInterval is optional and defaults to one hour.
+	var refreshInterval time.Duration
+	refreshVal, ok := parameters[RefreshInterval]
+	if ok {
+		var err error
+		refreshInterval, err = time.ParseDuration(strings.TrimSpace(refreshVal))
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a duration for '%s' parameter: %s", refreshVal, RefreshInterval, err.Error())
+			return nil
+		}
+	}
+
+	transform := transforms.NewClockSkewWithNTP(ntpServer, refreshInterval)
+	return transform.CorrectTimestamps
+}
+
+// Expression evaluates the arithmetic expression specified by the Expression parameter, i.e. "value * 0.1 + offset",
+// for the numeric readings of Events to calibrate or scale them. The optional Variables parameter is a comma separated
+// list of 'name:value' pairs available to the expression. The optional ResourceNames parameter limits the readings the
+// expression is evaluated for. The result replaces the reading's value unless the optional OutputResourceName parameter
+// is specified, in which case a new reading with that name is added to the Event.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) Expression(parameters map[string]string) interfaces.AppFunction {
+	expression, ok := parameters[Expression]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for Expression", Expression)
+		return nil
+	}
+
+	variables := make(map[string]float64)
+	if variablesSpec, ok := parameters[Variables]; ok {
+		for _, variable := range util.DeleteEmptyAndTrim(strings.FieldsFunc(variablesSpec, util.SplitComma)) {
+			nameValue := util.DeleteEmptyAndTrim(strings.FieldsFunc(variable, util.SplitColon))
+			if len(nameValue) != 2 || len(nameValue[0]) == 0 {
+				app.lc.Errorf("Bad Variables specification format. Expect comma separated list of 'name:value'. Got `%s`", variablesSpec)
+				return nil
+			}
+
+			value, err := strconv.ParseFloat(nameValue[1], 64)
+			if err != nil {
+				app.lc.Errorf("Could not parse '%s' to a number for variable '%s': %s", nameValue[1], nameValue[0], err.Error())
+				return nil
+			}
+
+			variables[nameValue[0]] = value
+		}
+	}
+
+	var resourceNames []string
+	if names, ok := parameters[ResourceNames]; ok {
+		resourceNames = util.DeleteEmptyAndTrim(strings.FieldsFunc(names, util.SplitComma))
+	}
+
+	transform, err := transforms.NewExpression(expression, variables, resourceNames, strings.TrimSpace(parameters[OutputResourceName]))
+	if err != nil {
+		app.lc.Error(err.Error())
+		return nil
+	}
+
+	return transform.Evaluate
+}
+
+// StreamJoin correlates the Events from the two sources specified by the SourceNames parameter, i.e. "temperature,humidity",
+// whose Origins are no more than the TimeInterval parameter, i.e. "5s", apart and continues the pipeline with one combined
+// Event per matched pair. The optional OutputSourceName parameter sets the source name of the combined Events.
+// Events are held until a match from the other source arrives and Events from other sources stop the pipeline.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) StreamJoin(parameters map[string]string) interfaces.AppFunction {
+	names, ok := parameters[SourceNames]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for StreamJoin", SourceNames)
+		return nil
+	}
+
+	sourceNames := util.DeleteEmptyAndTrim(strings.FieldsFunc(names, util.SplitComma))
+	if len(sourceNames) != 2 {
+		app.lc.Errorf("'%s' parameter for StreamJoin must specify two source names, found %d", SourceNames, len(sourceNames))
+		return nil
+	}
+
+	windowVal, ok := parameters[TimeInterval]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for StreamJoin", TimeInterval)
+		return nil
+	}
+
+	window, err := time.ParseDuration(strings.TrimSpace(windowVal))
+	if err != nil {
+		app.lc.Errorf("Could not parse '%s' to a duration for '%s' parameter: %s", windowVal, TimeInterval, err.Error())
+		return nil
+	}
+
+	transform, err := transforms.NewStreamJoin(sourceNames[0], sourceNames[1], window, strings.TrimSpace(parameters[OutputSourceName]))
+	if err != nil {
+		app.lc.Error(err.Error())
+		return nil
+	}
+
+	return transform.Join
+}
+
+// Template renders the Go text/template specified by the Template parameter against the data received, i.e. Event
+// readings and tags, and the values stored in the context. The rendered string is passed to the next function,
+// typically HTTPExport, so that APIs requiring a bespoke JSON or XML shape can be used without custom code.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) Template(parameters map[string]string) interfaces.AppFunction {
+	templateText, ok := parameters[Template]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for Template", Template)
+		return nil
+	}
+
+	transform, err := transforms.NewTemplate(templateText)
+	if err != nil {
+		app.lc.Error(err.Error())
+		return nil
+	}
+
+	return transform.Render
+}
+
+// EnforceDataContract validates the serialized output of the previous function against the JSON Schema registered for
+// the data contract specified by the ContractName and ContractVersion parameters. The optional Schema parameter registers
+// the schema for that version. The optional StopOnViolation parameter, which defaults to true, determines whether output
+// that doesn't conform to the data contract stops the pipeline or is only logged.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) EnforceDataContract(parameters map[string]string) interfaces.AppFunction {
+	name, ok := parameters[ContractName]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for EnforceDataContract", ContractName)
+		return nil
+	}
+	version, ok := parameters[ContractVersion]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for EnforceDataContract", ContractVersion)
+		return nil
+	}
+
+	name = strings.TrimSpace(name)
+	version = strings.TrimSpace(version)
+
+	if schema, ok := parameters[Schema]; ok {
+		if err := transforms.RegisterDataContract(name, version, schema); err != nil {
+			app.lc.Error(err.Error())
+			return nil
+		}
+	}
+
+	stopOnViolation := true
+	stopVal, ok := parameters[StopOnViolation]
+	if ok {
+		var err error
+		stopOnViolation, err = strconv.ParseBool(stopVal)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", stopVal, StopOnViolation, err.Error())
+			return nil
+		}
+	}
+
+	transform, err := transforms.NewDataContract(name, version, stopOnViolation)
+	if err != nil {
+		app.lc.Error(err.Error())
+		return nil
+	}
+
+	return transform.Enforce
+}
+
+// ValidateJSONSchema validates the data received, i.e. a raw HTTP or MQTT trigger payload, against the JSON Schema
+// specified by the Schema parameter so that malformed payloads are rejected early. The optional StopOnViolation parameter,
+// which defaults to true, determines whether data that doesn't conform to the schema stops the pipeline or is tagged
+// with the violations and passed on.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ValidateJSONSchema(parameters map[string]string) interfaces.AppFunction {
+	return app.validateSchema("ValidateJSONSchema", parameters, transforms.NewJSONSchemaValidator)
+}
+
+// ValidateXMLSchema validates the data received, i.e. a raw HTTP or MQTT trigger payload, against the XML Schema (XSD)
+// specified by the Schema parameter so that malformed payloads are rejected early. The optional StopOnViolation parameter,
+// which defaults to true, determines whether data that doesn't conform to the schema stops the pipeline or is tagged
+// with the violations and passed on. Only the subset of XSD described by transforms.NewXMLSchemaValidator is supported,
+// and a schema using any other construct fails to configure.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) ValidateXMLSchema(parameters map[string]string) interfaces.AppFunction {
+	return app.validateSchema("ValidateXMLSchema", parameters, transforms.NewXMLSchemaValidator)
+}
+
+// VerifySignature verifies the signature of the payload received, i.e. a raw HTTP or MQTT trigger payload, using the
+// scheme specified by the Scheme parameter, "hmac" or "jws". The key is specified by the Key parameter or retrieved from
+// the Secret Store at the SecretPath and SecretName parameters. The pipeline is stopped, and the HTTP trigger responds
+// with 401 (Unauthorized), if the signature is missing or doesn't match.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) VerifySignature(parameters map[string]string) interfaces.AppFunction {
+	scheme, ok := parameters[Scheme]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for VerifySignature", Scheme)
+		return nil
+	}
+
+	key := parameters[EncryptionKey]
+	secretPath := parameters[SecretPath]
+	secretName := parameters[SecretName]
+
+	if len(key) > 0 && (len(secretPath) > 0 || len(secretName) > 0) {
+		app.lc.Errorf("'%s' and '%s'/'%s' can not both be set for VerifySignature", EncryptionKey, SecretPath, SecretName)
+		return nil
+	}
+
+	var transform *transforms.SignatureVerifier
+	var err error
+	if len(secretPath) > 0 || len(secretName) > 0 {
+		transform, err = transforms.NewSignatureVerifierWithSecrets(scheme, secretPath, secretName)
+	} else {
+		transform, err = transforms.NewSignatureVerifier(scheme, key)
+	}
+
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for VerifySignature: %s", err.Error())
+		return nil
+	}
+
+	return transform.VerifySignature
+}
+
+func (app *Configurable) validateSchema(
+	funcName string,
+	parameters map[string]string,
+	newValidator func(schema string, stopOnViolation bool) (*transforms.SchemaValidator, error)) interfaces.AppFunction {
+	schema, ok := parameters[Schema]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for %s", Schema, funcName)
+		return nil
+	}
+
+	stopOnViolation := true
+	stopVal, ok := parameters[StopOnViolation]
+	if ok {
+		var err error
+		stopOnViolation, err = strconv.ParseBool(stopVal)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", stopVal, StopOnViolation, err.Error())
+			return nil
+		}
+	}
+
+	transform, err := newValidator(schema, stopOnViolation)
+	if err != nil {
+		app.lc.Errorf("Invalid '%s' parameter for %s: %s", Schema, funcName, err.Error())
+		return nil
+	}
+
+	return transform.Validate
+}
+
+// SendNotification creates a Support Notification using the Sender, Category, Labels, Severity and optional Content,
+// ContentType and Description parameters. Category and/or Labels must be specified. The data received is used as the
+// content when Content isn't specified. Parameter values may contain placeholders, i.e. {devicename}, which are replaced
+// with the matching values from the context.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) SendNotification(parameters map[string]string) interfaces.AppFunction {
+	config := transforms.NotificationConfig{
+		Sender:      strings.TrimSpace(parameters[Sender]),
+		Category:    strings.TrimSpace(parameters[Category]),
+		Severity:    strings.TrimSpace(parameters[Severity]),
+		Content:     parameters[Content],
+		ContentType: strings.TrimSpace(parameters[ContentType]),
+		Description: parameters[Description],
+	}
+
+	if len(config.Sender) == 0 {
+		app.lc.Errorf("Could not find '%s' parameter for SendNotification", Sender)
+		return nil
+	}
+
+	if len(config.Severity) == 0 {
+		app.lc.Errorf("Could not find '%s' parameter for SendNotification", Severity)
+		return nil
+	}
+
+	if labels, ok := parameters[Labels]; ok {
+		config.Labels = util.DeleteEmptyAndTrim(strings.FieldsFunc(labels, util.SplitComma))
+	}
+
+	if len(config.Category) == 0 && len(config.Labels) == 0 {
+		app.lc.Errorf("'%s' and/or '%s' parameters must be specified for SendNotification", Category, Labels)
+		return nil
+	}
+
+	transform := transforms.NewNotificationSender(config)
+	return transform.SendNotification
+}
+
+// FileExport writes the data as lines to files in the local Directory named by FilePattern, which may contain the time
+// placeholders {yyyy}, {MM}, {dd}, {HH} and {mm} and placeholders for context values, i.e. {devicename}. Each file is
+// finalized when its time partition rolls over and, when the optional Url parameter is set, uploaded with an HTTP PUT
+// to that URL joined with the file's path. The optional HeaderName, SecretPath and SecretName parameters set a header
+// on the upload from the Secret Store and RemoveAfterUpload removes the local file once uploaded. The optional Checksum
+// parameter, crc32 or sha256, writes the checksum of each finalized file to a sidecar file.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) FileExport(parameters map[string]string) interfaces.AppFunction {
+	config := transforms.RollingFileConfig{
+		Directory:      strings.TrimSpace(parameters[Directory]),
+		FilePattern:    strings.TrimSpace(parameters[FilePattern]),
+		UploadURL:      strings.TrimSpace(parameters[Url]),
+		HTTPHeaderName: strings.TrimSpace(parameters[HeaderName]),
+		SecretPath:     strings.TrimSpace(parameters[SecretPath]),
+		SecretName:     strings.TrimSpace(parameters[SecretName]),
+		Checksum:       strings.TrimSpace(parameters[Checksum]),
+	}
+
+	if len(config.Directory) == 0 {
+		app.lc.Errorf("Could not find '%s' parameter for FileExport", Directory)
+		return nil
+	}
+
+	if len(config.FilePattern) == 0 {
+		app.lc.Errorf("Could not find '%s' parameter for FileExport", FilePattern)
+		return nil
+	}
+
+	removeVal, ok := parameters[RemoveAfterUpload]
+	if ok {
+		var err error
+		config.RemoveAfterUpload, err = strconv.ParseBool(removeVal)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", removeVal, RemoveAfterUpload, err.Error())
+			return nil
+		}
+	}
+
+	transform, err := transforms.NewRollingFileWriter(config)
+	if err != nil {
+		app.lc.Error(err.Error())
+		return nil
+	}
+
+	return transform.Write
+}
+
 func (app *Configurable) processFilterParameters(
 	funcName string,
 	parameters map[string]string,
@@ -620,10 +1767,12 @@ func (app *Configurable) processFilterParameters(
 		}
 	}
 
+	// Names may be regular expressions or glob patterns when matchmode is specified, but can't contain commas
 	namesCleaned := util.DeleteEmptyAndTrim(strings.FieldsFunc(names, util.SplitComma))
-	transform := transforms.Filter{
-		FilterValues: namesCleaned,
-		FilterOut:    filterOutBool,
+	transform, err := transforms.NewPatternFilter(namesCleaned, filterOutBool, parameters[MatchMode])
+	if err != nil {
+		app.lc.Errorf("Could not create filter for %s: %s", funcName, err.Error())
+		return nil, false
 	}
 
 	return &transform, true
@@ -694,11 +1843,57 @@ func (app *Configurable) processHttpExportParameters(
 		}
 	}
 
+	// LatencyHeaders is optional and is false by default.
+	value, ok = parameters[LatencyHeaders]
+	if ok {
+		var err error
+		result.LatencyHeaders, err = strconv.ParseBool(value)
+		if err != nil {
+			return result, "",
+				fmt.Errorf("HTTPExport Could not parse '%s' to a bool for '%s' parameter: %s",
+					value,
+					LatencyHeaders,
+					err.Error())
+		}
+	}
+
+	// MaxConcurrency is optional and requests aren't limited by default.
+	value, ok = parameters[MaxConcurrency]
+	if ok {
+		var err error
+		result.MaxConcurrency, err = parseMaxConcurrency(value)
+		if err != nil {
+			return result, "", fmt.Errorf("HTTPExport %s", err.Error())
+		}
+	}
+
+	// Headers is optional and no additional headers are sent by default.
+	value, ok = parameters[Headers]
+	if ok {
+		var err error
+		result.Headers, err = parseHeaders(value)
+		if err != nil {
+			return result, "", fmt.Errorf("HTTPExport %s", err.Error())
+		}
+	}
+
 	result.URL = strings.TrimSpace(result.URL)
 	result.MimeType = strings.TrimSpace(result.MimeType)
 	result.HTTPHeaderName = strings.TrimSpace(parameters[HeaderName])
 	result.SecretPath = strings.TrimSpace(parameters[SecretPath])
 	result.SecretName = strings.TrimSpace(parameters[SecretName])
+	result.VerifyURL = strings.TrimSpace(parameters[VerifyUrl])
+	result.VerifyContains = parameters[VerifyContains]
+
+	checksum, err := parseChecksum(parameters[Checksum])
+	if err != nil {
+		return result, "", fmt.Errorf("HTTPExport %s", err.Error())
+	}
+	result.Checksum = checksum
+
+	if len(result.VerifyContains) != 0 && len(result.VerifyURL) == 0 {
+		return result, "", fmt.Errorf("HTTPExport missing %s since %s is specified", VerifyUrl, VerifyContains)
+	}
 
 	if len(result.HTTPHeaderName) == 0 && len(result.SecretPath) != 0 && len(result.SecretName) != 0 {
 		return result, "",
@@ -715,3 +1910,58 @@ func (app *Configurable) processHttpExportParameters(
 
 	return result, method, nil
 }
+
+// parseHeaders parses the Headers parameter of HTTPExport, which is a comma separated list of 'name:value' headers.
+// Only the first colon separates the name from the value, so values may be secret references such as
+// 'secret://<path>#<name>'.
+func parseHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, header := range util.DeleteEmptyAndTrim(strings.FieldsFunc(value, util.SplitComma)) {
+		nameValue := strings.SplitN(header, ":", 2)
+		if len(nameValue) != 2 {
+			return nil, fmt.Errorf("bad '%s' parameter format. Expect comma separated list of 'name:value'. Got '%s'", Headers, value)
+		}
+
+		name := strings.TrimSpace(nameValue[0])
+		headerValue := strings.TrimSpace(nameValue[1])
+		if len(name) == 0 {
+			return nil, fmt.Errorf("'%s' parameter header name missing. Got '%s'", Headers, header)
+		}
+		if len(headerValue) == 0 {
+			return nil, fmt.Errorf("'%s' parameter header value missing. Got '%s'", Headers, header)
+		}
+		if strings.HasPrefix(headerValue, transforms.SecretHeaderPrefix) && !strings.Contains(headerValue, "#") {
+			return nil, fmt.Errorf("'%s' parameter header '%s' secret reference must be in the form '%s<path>#<name>'",
+				Headers, name, transforms.SecretHeaderPrefix)
+		}
+
+		headers[name] = headerValue
+	}
+
+	return headers, nil
+}
+
+// parseChecksum parses the Checksum parameter of the export functions, which is blank or the algorithm of the checksum
+func parseChecksum(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "", transforms.ChecksumCRC32, transforms.ChecksumSHA256:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid '%s' parameter '%s'. Must be '%s' or '%s'",
+			Checksum, value, transforms.ChecksumCRC32, transforms.ChecksumSHA256)
+	}
+}
+
+// parseMaxConcurrency parses the MaxConcurrency parameter of the export functions, which can't be negative
+func parseMaxConcurrency(value string) (int, error) {
+	maxConcurrency, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("Could not parse '%s' to an int for '%s' parameter: %s", value, MaxConcurrency, err.Error())
+	}
+	if maxConcurrency < 0 {
+		return 0, fmt.Errorf("'%s' parameter must not be negative", MaxConcurrency)
+	}
+
+	return maxConcurrency, nil
+}
@@ -0,0 +1,105 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"strconv"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms"
+)
+
+// The function and its metadata are left out of builds with the no_amqp tag, which drops the amqp091-go dependency.
+func init() {
+	configurableFunctions["AMQPExport"] = interfaces.ConfigurableFunction{
+		Description: "Publishes the data to an AMQP 0-9-1 broker, i.e. RabbitMQ",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(BrokerAddress, interfaces.ParameterTypeString, "Address of the broker, i.e. amqps://rabbitmq:5671/vhost"),
+			requiredParameter(RoutingKey, interfaces.ParameterTypeString, "Routing key, which may contain context value placeholders"),
+			optionalParameter(Exchange, interfaces.ParameterTypeString, "", "Exchange published to. Blank is the default exchange"),
+			optionalParameter(Persistent, interfaces.ParameterTypeBool, "false", "Publish persistent messages"),
+			optionalParameter(ContentType, interfaces.ParameterTypeString, "", "Content type of the messages"),
+			mqttAuthModeParameter,
+			secretPathParameter,
+			optionalParameter(SkipVerify, interfaces.ParameterTypeBool, "false", "Skip verifying the broker's certificate"),
+			optionalParameter(ConnectTimeout, interfaces.ParameterTypeDuration, "", "How long to wait when connecting"),
+			persistOnErrorParameter,
+		},
+	}
+}
+
+// AMQPExport will publish data from the previous function to the specified exchange with the specified routing key on
+// an AMQP 0-9-1 broker, i.e. RabbitMQ. If no previous function exists, then the event that triggered the pipeline
+// will be used. This function is a configuration function and returns a function pointer.
+func (app *Configurable) AMQPExport(parameters map[string]string) interfaces.AppFunction {
+	var err error
+
+	brokerAddress, ok := parameters[BrokerAddress]
+	if !ok {
+		app.lc.Error("Could not find " + BrokerAddress)
+		return nil
+	}
+	routingKey, ok := parameters[RoutingKey]
+	if !ok {
+		app.lc.Error("Could not find " + RoutingKey)
+		return nil
+	}
+
+	config := transforms.AMQPSenderConfig{
+		BrokerAddress: brokerAddress,
+		RoutingKey:    routingKey,
+		// These are optional and blank values result in the default exchange, no content type,
+		// the default timeout and no authentication being used.
+		Exchange:       parameters[Exchange],
+		ContentType:    parameters[ContentType],
+		ConnectTimeout: parameters[ConnectTimeout],
+		AuthMode:       parameters[AuthMode],
+		SecretPath:     parameters[SecretPath],
+	}
+
+	if value, ok := parameters[Persistent]; ok {
+		config.Persistent, err = strconv.ParseBool(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", value, Persistent, err.Error())
+			return nil
+		}
+	}
+
+	if value, ok := parameters[SkipVerify]; ok {
+		config.SkipCertVerify, err = strconv.ParseBool(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", value, SkipVerify, err.Error())
+			return nil
+		}
+	}
+
+	// PersistOnError is optional and is false by default.
+	persistOnError := false
+	if value, ok := parameters[PersistOnError]; ok {
+		persistOnError, err = strconv.ParseBool(value)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter: %s", value, PersistOnError, err.Error())
+			return nil
+		}
+	}
+
+	transform := transforms.NewAMQPSender(config, persistOnError)
+	return transform.AMQPSend
+}
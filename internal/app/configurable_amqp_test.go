@@ -0,0 +1,50 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAMQPExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{BrokerAddress: "amqp://rabbitmq:5672/", RoutingKey: "edgex.{devicename}"}, false},
+		{"Valid with options", map[string]string{BrokerAddress: "amqps://rabbitmq:5671/edgex", RoutingKey: "events", Exchange: "amq.topic", Persistent: "true", ContentType: "application/json", AuthMode: "usernamepassword", SecretPath: "amqp", SkipVerify: "true", ConnectTimeout: "10s", PersistOnError: "true"}, false},
+		{"Missing broker", map[string]string{RoutingKey: "events"}, true},
+		{"Missing routing key", map[string]string{BrokerAddress: "amqp://rabbitmq:5672/"}, true},
+		{"Invalid persistent", map[string]string{BrokerAddress: "amqp://rabbitmq:5672/", RoutingKey: "events", Persistent: "bogus"}, true},
+		{"Invalid skip verify", map[string]string{BrokerAddress: "amqp://rabbitmq:5672/", RoutingKey: "events", SkipVerify: "bogus"}, true},
+		{"Invalid persist on error", map[string]string{BrokerAddress: "amqp://rabbitmq:5672/", RoutingKey: "events", PersistOnError: "bogus"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.AMQPExport(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !no_cel
+// +build !no_cel
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms"
+)
+
+// The function and its metadata are left out of builds with the no_cel tag, which drops the cel-go dependency.
+func init() {
+	configurableFunctions["FilterByCEL"] = interfaces.ConfigurableFunction{
+		Description: "Filters Events by a Common Expression Language (CEL) expression",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Expression, interfaces.ParameterTypeString, "CEL expression which evaluates to a bool"),
+		},
+	}
+}
+
+// FilterByCEL evaluates the Common Expression Language (CEL) expression specified by the Expression parameter against
+// each Event, i.e. `event.readings.exists(r, r.resourceName == "Float64" && double(r.value) > 100.0)`. Events for which
+// the expression is true continue through the pipeline and the pipeline is stopped for those for which it is false.
+// This function will return an error and stop the pipeline if a non-edgex event is received, if no data is received
+// or if the expression doesn't evaluate to a bool.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) FilterByCEL(parameters map[string]string) interfaces.AppFunction {
+	expression, ok := parameters[Expression]
+	if !ok {
+		app.lc.Errorf("Could not find '%s' parameter for FilterByCEL", Expression)
+		return nil
+	}
+
+	transform, err := transforms.NewCELFilter(expression)
+	if err != nil {
+		app.lc.Error(err.Error())
+		return nil
+	}
+
+	return transform.Evaluate
+}
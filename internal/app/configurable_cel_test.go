@@ -0,0 +1,47 @@
+//go:build !no_cel
+// +build !no_cel
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterByCEL(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid expression", map[string]string{Expression: `event.deviceName == "Random-Float-Device"`}, false},
+		{"Missing Expression", map[string]string{}, true},
+		{"Bad Expression", map[string]string{Expression: `event.deviceName ==`}, true},
+		{"Undeclared reference", map[string]string{Expression: `device.name == "Random-Float-Device"`}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.FilterByCEL(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
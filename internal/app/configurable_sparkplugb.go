@@ -0,0 +1,88 @@
+//go:build !no_sparkplugb
+// +build !no_sparkplugb
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms"
+)
+
+// The function and its metadata are left out of builds with the no_sparkplugb tag, which drops the protobuf dependency.
+func init() {
+	configurableFunctions["SparkplugBExport"] = interfaces.ConfigurableFunction{
+		Description: "Publishes Events as Sparkplug B device messages of an edge node",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(BrokerAddress, interfaces.ParameterTypeString, "Address of the broker"),
+			requiredParameter(GroupId, interfaces.ParameterTypeString, "Sparkplug group id"),
+			requiredParameter(EdgeNodeId, interfaces.ParameterTypeString, "Sparkplug edge node id"),
+			clientIdParameter,
+			mqttAuthModeParameter,
+			secretPathParameter,
+			optionalParameter(SkipVerify, interfaces.ParameterTypeBool, "false", "Skip verifying the broker's certificate"),
+			optionalParameter(ConnectTimeout, interfaces.ParameterTypeDuration, "30s", "How long to wait when connecting"),
+		},
+	}
+}
+
+// SparkplugBExport publishes the Event as a Sparkplug B DBIRTH or DDATA message of the device to the broker at the
+// BrokerAddress parameter, as a device of the edge node identified by the GroupId and EdgeNodeId parameters. The
+// edge node's NBIRTH is published when connecting and its NDEATH is registered as the Last Will and Testament. The
+// optional ClientID, AuthMode, SecretPath, SkipVerify and ConnectTimeout parameters are used to connect to the broker.
+// This function is a configuration function and returns a function pointer.
+func (app *Configurable) SparkplugBExport(parameters map[string]string) interfaces.AppFunction {
+	config := transforms.SparkplugBConfig{
+		BrokerAddress: strings.TrimSpace(parameters[BrokerAddress]),
+		ClientId:      strings.TrimSpace(parameters[ClientID]),
+		AuthMode:      strings.TrimSpace(parameters[AuthMode]),
+		SecretPath:    strings.TrimSpace(parameters[SecretPath]),
+		GroupId:       strings.TrimSpace(parameters[GroupId]),
+		EdgeNodeId:    strings.TrimSpace(parameters[EdgeNodeId]),
+	}
+
+	if skipVerify := strings.TrimSpace(parameters[SkipVerify]); len(skipVerify) > 0 {
+		var err error
+		config.SkipCertVerify, err = strconv.ParseBool(skipVerify)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a bool for '%s' parameter for SparkplugBExport: %s", skipVerify, SkipVerify, err.Error())
+			return nil
+		}
+	}
+
+	if connectTimeout := strings.TrimSpace(parameters[ConnectTimeout]); len(connectTimeout) > 0 {
+		var err error
+		config.ConnectTimeout, err = time.ParseDuration(connectTimeout)
+		if err != nil {
+			app.lc.Errorf("Could not parse '%s' to a duration for '%s' parameter for SparkplugBExport: %s", connectTimeout, ConnectTimeout, err.Error())
+			return nil
+		}
+	}
+
+	transform, err := transforms.NewSparkplugB(config)
+	if err != nil {
+		app.lc.Errorf("Invalid parameters for SparkplugBExport: %s", err.Error())
+		return nil
+	}
+
+	return transform.Export
+}
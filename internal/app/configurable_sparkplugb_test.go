@@ -0,0 +1,50 @@
+//go:build !no_sparkplugb
+// +build !no_sparkplugb
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkplugBExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{BrokerAddress: "tcp://broker:1883", GroupId: "plant", EdgeNodeId: "edgex"}, false},
+		{"Valid with options", map[string]string{BrokerAddress: "tcp://broker:1883", GroupId: "plant", EdgeNodeId: "edgex", ClientID: "edge", AuthMode: "usernamepassword", SecretPath: "sparkplug", SkipVerify: "true", ConnectTimeout: "10s"}, false},
+		{"Missing broker", map[string]string{GroupId: "plant", EdgeNodeId: "edgex"}, true},
+		{"Missing group id", map[string]string{BrokerAddress: "tcp://broker:1883", EdgeNodeId: "edgex"}, true},
+		{"Invalid edge node id", map[string]string{BrokerAddress: "tcp://broker:1883", GroupId: "plant", EdgeNodeId: "edge/x"}, true},
+		{"Invalid skip verify", map[string]string{BrokerAddress: "tcp://broker:1883", GroupId: "plant", EdgeNodeId: "edgex", SkipVerify: "bogus"}, true},
+		{"Invalid connect timeout", map[string]string{BrokerAddress: "tcp://broker:1883", GroupId: "plant", EdgeNodeId: "edgex", ConnectTimeout: "bogus"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.SparkplugBExport(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
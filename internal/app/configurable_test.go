@@ -37,6 +37,10 @@ func TestFilterByProfileName(t *testing.T) {
 		{"Valid Parameters", map[string]string{ProfileNames: "GS1-AC-Drive, GS0-DC-Drive, GSX-ACDC-Drive"}, false},
 		{"Empty FilterOut Parameters", map[string]string{ProfileNames: "GS1-AC-Drive, GS0-DC-Drive, GSX-ACDC-Drive", FilterOut: ""}, true},
 		{"Valid FilterOut Parameters", map[string]string{ProfileNames: "GS1-AC-Drive, GS0-DC-Drive, GSX-ACDC-Drive", FilterOut: "true"}, false},
+		{"Valid Regex MatchMode", map[string]string{ProfileNames: "GS[0-9]-.*-Drive", MatchMode: "regex"}, false},
+		{"Valid Glob MatchMode", map[string]string{ProfileNames: "GS?-*-Drive", MatchMode: "glob"}, false},
+		{"Invalid Regex", map[string]string{ProfileNames: "GS[0-9", MatchMode: "regex"}, true},
+		{"Invalid MatchMode", map[string]string{ProfileNames: "GS1-AC-Drive", MatchMode: "fuzzy"}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -138,6 +142,7 @@ func TestTransform(t *testing.T) {
 	}{
 		{"Good - XML", "xMl", true},
 		{"Good - JSON", "JsOn", true},
+		{"Good - MsgPack", "MsgPack", true},
 		{"Bad Type", "baDType", false},
 	}
 
@@ -151,6 +156,265 @@ func TestTransform(t *testing.T) {
 	}
 }
 
+func TestExtractBinaryPayload(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name   string
+		Params map[string]string
+	}{
+		{"No resource name", map[string]string{}},
+		{"Resource name", map[string]string{ResourceName: "image"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.ExtractBinaryPayload(test.Params)
+			assert.NotNil(t, transform)
+		})
+	}
+}
+
+func TestProcessImages(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"No parameters", map[string]string{}, false},
+		{"All parameters", map[string]string{MaxWidth: "640", MaxHeight: "480", Format: "JPEG", Quality: "60", MaxBytes: "50000"}, false},
+		{"Blank parameters", map[string]string{MaxWidth: "", Format: ""}, false},
+		{"Bad MaxWidth", map[string]string{MaxWidth: "wide"}, true},
+		{"Negative MaxHeight", map[string]string{MaxHeight: "-1"}, true},
+		{"Bad Quality", map[string]string{Quality: "101"}, true},
+		{"Bad Format", map[string]string{Format: "gif"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.ProcessImages(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestEncodeDelta(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"No parameters", map[string]string{}, false},
+		{"All parameters", map[string]string{BlockSize: "64", FullPayloadInterval: "10"}, false},
+		{"Bad BlockSize", map[string]string{BlockSize: "big"}, true},
+		{"Negative BlockSize", map[string]string{BlockSize: "-1"}, true},
+		{"Bad FullPayloadInterval", map[string]string{FullPayloadInterval: "often"}, true},
+		{"Negative FullPayloadInterval", map[string]string{FullPayloadInterval: "-5"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.EncodeDelta(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestAddRevision(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	transform := configurable.AddRevision(map[string]string{})
+	assert.NotNil(t, transform)
+}
+
+func TestForwardToEKuiper(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"REST", map[string]string{Url: "http://ekuiper:10081/edgex"}, false},
+		{"MQTT with result", map[string]string{BrokerAddress: "tcp://broker:1883", Topic: "events", ResultTopic: "result",
+			CorrelationField: "eventId", Timeout: "2s", ClientID: "app", AuthMode: "none"}, false},
+		{"No parameters", map[string]string{}, true},
+		{"Missing Topic", map[string]string{BrokerAddress: "tcp://broker:1883"}, true},
+		{"Bad Timeout", map[string]string{Url: "http://ekuiper:10081/edgex", Timeout: "soon"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.ForwardToEKuiper(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestConvertToNGSILD(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	assert.NotNil(t, configurable.ConvertToNGSILD(map[string]string{}))
+	assert.NotNil(t, configurable.ConvertToNGSILD(map[string]string{EntityType: "Thermostat", EntityId: "urn:ngsi-ld:Thermostat:{devicename}", JSONLDContext: "https://example.com/context.jsonld"}))
+}
+
+func TestNGSILDExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{Url: "http://orion-ld:1026"}, false},
+		{"Valid with options", map[string]string{Url: "http://orion-ld:1026", EntityType: "Thermostat", PersistOnError: "true", HeaderName: "Authorization", SecretPath: "orion", SecretName: "token"}, false},
+		{"Missing url", map[string]string{EntityType: "Thermostat"}, true},
+		{"Invalid persist on error", map[string]string{Url: "http://orion-ld:1026", PersistOnError: "bogus"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.NGSILDExport(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestSensorThingsExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{Url: "http://frost:8080/FROST-Server/v1.1"}, false},
+		{"Valid with options", map[string]string{Url: "http://frost:8080/FROST-Server/v1.1", DatastreamName: "{devicename}/{resourcename}", PersistOnError: "true", HeaderName: "Authorization", SecretPath: "frost", SecretName: "token"}, false},
+		{"Missing url", map[string]string{DatastreamName: "{resourcename}"}, true},
+		{"Invalid persist on error", map[string]string{Url: "http://frost:8080/FROST-Server/v1.1", PersistOnError: "bogus"}, true},
+		{"Missing secret name", map[string]string{Url: "http://frost:8080/FROST-Server/v1.1", HeaderName: "Authorization", SecretPath: "frost"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.SensorThingsExport(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestElasticsearchExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{Url: "http://elastic:9200"}, false},
+		{"Valid with options", map[string]string{Url: "http://elastic:9200", Index: "edgex-{devicename}-{date}", AuthMode: "basic", SecretPath: "elastic", SkipVerify: "true", Timeout: "10s", MaxRetries: "5", InitialBackoff: "500ms", PersistOnError: "true"}, false},
+		{"Missing url", map[string]string{Index: "edgex"}, true},
+		{"Invalid auth mode", map[string]string{Url: "http://elastic:9200", AuthMode: "bogus"}, true},
+		{"Missing secret path", map[string]string{Url: "http://elastic:9200", AuthMode: "apikey"}, true},
+		{"Invalid max retries", map[string]string{Url: "http://elastic:9200", MaxRetries: "bogus"}, true},
+		{"Invalid skip verify", map[string]string{Url: "http://elastic:9200", SkipVerify: "bogus"}, true},
+		{"Invalid persist on error", map[string]string{Url: "http://elastic:9200", PersistOnError: "bogus"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.ElasticsearchExport(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestConvertToV1(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	assert.NotNil(t, configurable.ConvertToV1(nil))
+}
+
+func TestConvertFromV1(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{}, false},
+		{"Valid with options", map[string]string{ProfileNames: "boiler-1:boiler, room-1:thermostat", DefaultProfileName: "generic", SourceName: "all"}, false},
+		{"Bad profile names", map[string]string{ProfileNames: "boiler-1"}, true},
+		{"Missing profile name", map[string]string{ProfileNames: "boiler-1:"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.ConvertFromV1(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestV1Export(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{Url: "http://edgex-core-data:48080"}, false},
+		{"Valid with options", map[string]string{Url: "http://edgex-core-data:48080", PersistOnError: "true", HeaderName: "Authorization", SecretPath: "coredata", SecretName: "token"}, false},
+		{"Missing url", map[string]string{PersistOnError: "true"}, true},
+		{"Invalid persist on error", map[string]string{Url: "http://edgex-core-data:48080", PersistOnError: "bogus"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.V1Export(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestWebSocketExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{Url: "ws://dashboard:8080/ingest"}, false},
+		{"Valid with options", map[string]string{Url: "wss://dashboard:8443/ingest", HeaderName: "Authorization", SecretPath: "dashboard", SecretName: "token", SkipVerify: "true", Timeout: "5s", PersistOnError: "true"}, false},
+		{"Missing url", map[string]string{Timeout: "5s"}, true},
+		{"Invalid url scheme", map[string]string{Url: "http://dashboard:8080/ingest"}, true},
+		{"Missing secret name", map[string]string{Url: "ws://dashboard:8080/ingest", HeaderName: "Authorization", SecretPath: "dashboard"}, true},
+		{"Invalid timeout", map[string]string{Url: "ws://dashboard:8080/ingest", Timeout: "bogus"}, true},
+		{"Invalid skip verify", map[string]string{Url: "ws://dashboard:8080/ingest", SkipVerify: "bogus"}, true},
+		{"Invalid persist on error", map[string]string{Url: "ws://dashboard:8080/ingest", PersistOnError: "bogus"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.WebSocketExport(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestStreamExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	assert.NotNil(t, configurable.StreamExport(nil))
+}
+
 func TestHTTPExport(t *testing.T) {
 	configurable := Configurable{lc: lc}
 
@@ -259,6 +523,13 @@ func TestSetOutputData(t *testing.T) {
 		{"Valid Parameter With Value", map[string]string{ResponseContentType: "application/json"}, false},
 		{"Valid Parameter Without Value", map[string]string{ResponseContentType: ""}, false},
 		{"Unknown Parameter", map[string]string{"Unknown": "scary/text"}, false},
+		{"CBOR Type", map[string]string{TransformType: "cbor"}, false},
+		{"XML Type", map[string]string{TransformType: "XML"}, false},
+		{"JSON Type With Content Type", map[string]string{TransformType: "json", ResponseContentType: "text/plain"}, false},
+		{"Empty Type", map[string]string{TransformType: ""}, false},
+		{"Invalid Type", map[string]string{TransformType: "yaml"}, true},
+		{"Append", map[string]string{Append: "true"}, false},
+		{"Invalid Append", map[string]string{Append: "bogus"}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -304,6 +575,78 @@ func TestBatchByTimeAndCount(t *testing.T) {
 	assert.NotNil(t, trx, "return result for BatchByTimeAndCount should not be nil")
 }
 
+func TestBatchByBytes(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name          string
+		Mode          string
+		ByteThreshold string
+		ExpectNil     bool
+	}{
+		{"Valid", BatchByBytes, "1048576", false},
+		{"Valid - with count", BatchByCount, "1048576", false},
+		{"Valid - with time", BatchByTime, "1048576", false},
+		{"Valid - with time and count", BatchByTimeAndCount, "1048576", false},
+		{"Missing threshold", BatchByBytes, "", true},
+		{"Zero threshold", BatchByBytes, "0", true},
+		{"Negative threshold", BatchByCount, "-1", true},
+		{"Invalid threshold", BatchByTime, "bogus", true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			params := map[string]string{
+				Mode:           testCase.Mode,
+				BatchThreshold: "30",
+				TimeInterval:   "10s",
+			}
+			if len(testCase.ByteThreshold) > 0 {
+				params[ByteThreshold] = testCase.ByteThreshold
+			}
+
+			transform := configurable.Batch(params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestBatchFlushName(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Mode      string
+		FlushName string
+		ExpectNil bool
+	}{
+		{"Valid - time", BatchByTime, "truck-dock", false},
+		{"Valid - time and count", BatchByTimeAndCount, "loading-bay", false},
+		{"Name already used", BatchByTime, "truck-dock", true},
+		{"Not supported by count", BatchByCount, "count", true},
+		{"Not supported by bytes", BatchByBytes, "bytes", true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			params := map[string]string{
+				Mode:           testCase.Mode,
+				BatchThreshold: "30",
+				TimeInterval:   "10s",
+				ByteThreshold:  "1048576",
+				FlushName:      testCase.FlushName,
+			}
+
+			transform := configurable.Batch(params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+
+	assert.Len(t, configurable.namedFlushers, 2)
+	assert.Contains(t, configurable.namedFlushers, "truck-dock")
+	assert.Contains(t, configurable.namedFlushers, "loading-bay")
+}
+
 func TestJSONLogic(t *testing.T) {
 	params := make(map[string]string)
 	params[Rule] = "{}"
@@ -336,6 +679,70 @@ func TestMQTTExport(t *testing.T) {
 	assert.NotNil(t, trx, "return result from MQTTSecretSend should not be nil")
 }
 
+func TestMQTTExport_Status(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	params := make(map[string]string)
+	params[BrokerAddress] = "mqtt://broker:8883"
+	params[Topic] = "topic"
+	params[SecretPath] = "/path"
+	params[ClientID] = "clientid"
+	params[AuthMode] = "none"
+	params[StatusTopic] = "edgex/status/app-mqtt-export"
+	params[OnlinePayload] = "1"
+	params[OfflinePayload] = "0"
+
+	trx := configurable.MQTTExport(params)
+	assert.NotNil(t, trx, "return result from MQTTSecretSend should not be nil")
+}
+
+func TestMQTTExport_Brokers(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	params := make(map[string]string)
+	params[BrokerAddress] = "mqtt://primary:8883, mqtt://standby:8883"
+	params[Topic] = "topic"
+	params[SecretPath] = "/path"
+	params[ClientID] = "clientid"
+	params[AuthMode] = "none"
+	params[PrimaryCheckInterval] = "1m"
+
+	trx := configurable.MQTTExport(params)
+	assert.NotNil(t, trx, "return result from MQTTSecretSend should not be nil")
+}
+
+func TestMQTTExport_Buffer(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name                string
+		MaxBufferedMessages string
+		ExpectNil           bool
+	}{
+		{"Valid", "500", false},
+		{"Valid - default max", "", false},
+		{"Invalid max", "lots", true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			params := make(map[string]string)
+			params[BrokerAddress] = "mqtt://broker:8883"
+			params[Topic] = "topic"
+			params[SecretPath] = "/path"
+			params[ClientID] = "clientid"
+			params[AuthMode] = "none"
+			params[BufferDirectory] = "/tmp/mqtt-buffer"
+			if len(testCase.MaxBufferedMessages) > 0 {
+				params[MaxBufferedMessages] = testCase.MaxBufferedMessages
+			}
+
+			transform := configurable.MQTTExport(params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
 func TestAddTags(t *testing.T) {
 	configurable := Configurable{lc: lc}
 
@@ -365,6 +772,157 @@ func TestAddTags(t *testing.T) {
 	}
 }
 
+func TestCompress(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid gzip", map[string]string{Algorithm: CompressGZIP}, false},
+		{"Valid zlib", map[string]string{Algorithm: CompressZLIB}, false},
+		{"Valid gzip with level", map[string]string{Algorithm: CompressGZIP, CompressionLevel: "1"}, false},
+		{"Valid zlib with level and dictionary", map[string]string{Algorithm: CompressZLIB, CompressionLevel: "9", Dictionary: `{"deviceName":"`}, false},
+		{"Missing algorithm", map[string]string{}, true},
+		{"Invalid algorithm", map[string]string{Algorithm: "bogus"}, true},
+		{"Invalid level", map[string]string{Algorithm: CompressGZIP, CompressionLevel: "bogus"}, true},
+		{"Level out of range", map[string]string{Algorithm: CompressGZIP, CompressionLevel: "10"}, true},
+		{"Dictionary with gzip", map[string]string{Algorithm: CompressGZIP, Dictionary: "bogus"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := configurable.Compress(test.Params)
+			assert.Equal(t, test.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestAddLatencyTags(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	trx := configurable.AddLatencyTags(nil)
+	assert.NotNil(t, trx, "return result from AddLatencyTags should not be nil")
+}
+
+func TestHTTPExport_LatencyHeaders(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	params := map[string]string{
+		ExportMethod:   ExportMethodPost,
+		Url:            "http://url",
+		MimeType:       common.ContentTypeJSON,
+		LatencyHeaders: "true",
+	}
+	assert.NotNil(t, configurable.HTTPExport(params))
+
+	params[LatencyHeaders] = "bogus"
+	assert.Nil(t, configurable.HTTPExport(params))
+}
+
+func TestHTTPExport_Headers(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Headers   string
+		ExpectNil bool
+	}{
+		{"Valid", "X-Api-Version:2", false},
+		{"Valid - multiple", "X-Api-Version:2, X-Api-Key:secret://cloud#apikey", false},
+		{"Valid - value with colons", "X-Api-Key:secret://cloud#apikey,Forwarded:for=10.0.0.1:8080", false},
+		{"Invalid - no value separator", "X-Api-Version", true},
+		{"Invalid - name missing", ":2", true},
+		{"Invalid - value missing", "X-Api-Version:", true},
+		{"Invalid - secret name missing", "X-Api-Key:secret://cloud", true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			params := map[string]string{
+				ExportMethod: ExportMethodPost,
+				Url:          "http://url",
+				MimeType:     common.ContentTypeJSON,
+				Headers:      testCase.Headers,
+			}
+			assert.Equal(t, testCase.ExpectNil, configurable.HTTPExport(params) == nil)
+		})
+	}
+}
+
+func TestHTTPExport_Verify(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	params := map[string]string{
+		ExportMethod:   ExportMethodPost,
+		Url:            "http://url",
+		MimeType:       common.ContentTypeJSON,
+		VerifyUrl:      "http://url/{correlationid}",
+		VerifyContains: "{correlationid}",
+	}
+	assert.NotNil(t, configurable.HTTPExport(params))
+
+	delete(params, VerifyUrl)
+	assert.Nil(t, configurable.HTTPExport(params))
+}
+
+func TestParseChecksum(t *testing.T) {
+	checksum, err := parseChecksum(" SHA256 ")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256", checksum)
+
+	checksum, err = parseChecksum("")
+	assert.NoError(t, err)
+	assert.Empty(t, checksum)
+
+	_, err = parseChecksum("md5")
+	assert.Error(t, err)
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := parseHeaders(" X-Api-Version : 2 ,X-Api-Key:secret://cloud#apikey")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Api-Version": "2", "X-Api-Key": "secret://cloud#apikey"}, headers)
+}
+
+func TestExport_MaxConcurrency(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name           string
+		MaxConcurrency string
+		ExpectNil      bool
+	}{
+		{"Valid", "4", false},
+		{"Valid - no limit", "0", false},
+		{"Invalid", "bogus", true},
+		{"Negative", "-1", true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			httpParams := map[string]string{
+				ExportMethod:   ExportMethodPost,
+				Url:            "http://url",
+				MimeType:       common.ContentTypeJSON,
+				MaxConcurrency: testCase.MaxConcurrency,
+			}
+			assert.Equal(t, testCase.ExpectNil, configurable.HTTPExport(httpParams) == nil)
+
+			mqttParams := map[string]string{
+				BrokerAddress:  "mqtt://broker:8883",
+				Topic:          "topic",
+				SecretPath:     "/path",
+				ClientID:       "clientid",
+				AuthMode:       "none",
+				MaxConcurrency: testCase.MaxConcurrency,
+			}
+			assert.Equal(t, testCase.ExpectNil, configurable.MQTTExport(mqttParams) == nil)
+		})
+	}
+}
+
 func TestEncrypt(t *testing.T) {
 	configurable := Configurable{lc: lc}
 
@@ -372,6 +930,9 @@ func TestEncrypt(t *testing.T) {
 	vector := "1243565"
 	secretsPath := "/aes"
 	secretName := "myKey"
+	resourceNames := "temperature, humidity"
+	fields := "readings.value"
+	empty := ""
 
 	tests := []struct {
 		Name          string
@@ -380,15 +941,22 @@ func TestEncrypt(t *testing.T) {
 		InitVector    string
 		SecretPath    string
 		SecretName    string
+		ResourceNames *string
+		Fields        *string
 		ExpectNil     bool
 	}{
-		{"Good - Key & vector ", EncryptAES, key, vector, "", "", false},
-		{"Good - Secrets & vector", "aEs", "", vector, secretsPath, secretName, false},
-		{"Bad - No algorithm ", "", key, "", "", "", true},
-		{"Bad - No vector ", EncryptAES, key, "", "", "", true},
-		{"Bad - No Key or secrets ", EncryptAES, "", vector, "", "", true},
-		{"Bad - Missing secretPath", EncryptAES, "", vector, "", secretName, true},
-		{"Bad - Missing secretName", EncryptAES, "", vector, secretsPath, "", true},
+		{"Good - Key & vector ", EncryptAES, key, vector, "", "", nil, nil, false},
+		{"Good - Secrets & vector", "aEs", "", vector, secretsPath, secretName, nil, nil, false},
+		{"Good - Resource names", EncryptAES, key, vector, "", "", &resourceNames, nil, false},
+		{"Good - Empty resource names", EncryptAES, key, vector, "", "", &empty, nil, false},
+		{"Good - Fields", EncryptAES, key, vector, "", "", nil, &fields, false},
+		{"Bad - No algorithm ", "", key, "", "", "", nil, nil, true},
+		{"Bad - No vector ", EncryptAES, key, "", "", "", nil, nil, true},
+		{"Bad - No Key or secrets ", EncryptAES, "", vector, "", "", nil, nil, true},
+		{"Bad - Missing secretPath", EncryptAES, "", vector, "", secretName, nil, nil, true},
+		{"Bad - Missing secretName", EncryptAES, "", vector, secretsPath, "", nil, nil, true},
+		{"Bad - Resource names and fields", EncryptAES, key, vector, "", "", &resourceNames, &fields, true},
+		{"Bad - Empty fields", EncryptAES, key, vector, "", "", nil, &empty, true},
 	}
 
 	for _, testCase := range tests {
@@ -409,6 +977,12 @@ func TestEncrypt(t *testing.T) {
 			if len(testCase.SecretName) > 0 {
 				params[SecretName] = testCase.SecretName
 			}
+			if testCase.ResourceNames != nil {
+				params[ResourceNames] = *testCase.ResourceNames
+			}
+			if testCase.Fields != nil {
+				params[Fields] = *testCase.Fields
+			}
 
 			transform := configurable.Encrypt(params)
 			assert.Equal(t, testCase.ExpectNil, transform == nil)
@@ -472,3 +1046,313 @@ func TestConfigurable_PushToCore(t *testing.T) {
 		})
 	}
 }
+
+func TestEmailExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{Host: "smtp.example.com", Port: "587", From: "edgex@example.com", To: "ops@example.com, oncall@example.com"}, false},
+		{"Valid with auth", map[string]string{Host: "smtp.example.com", Port: "587", From: "edgex@example.com", To: "ops@example.com", AuthMode: "usernamepassword", SecretPath: "smtp", PersistOnError: "true"}, false},
+		{"Missing Host", map[string]string{Port: "587", From: "edgex@example.com", To: "ops@example.com"}, true},
+		{"Missing Port", map[string]string{Host: "smtp.example.com", From: "edgex@example.com", To: "ops@example.com"}, true},
+		{"Bad Port", map[string]string{Host: "smtp.example.com", Port: "bogus", From: "edgex@example.com", To: "ops@example.com"}, true},
+		{"Missing From", map[string]string{Host: "smtp.example.com", Port: "587", To: "ops@example.com"}, true},
+		{"Missing To", map[string]string{Host: "smtp.example.com", Port: "587", From: "edgex@example.com"}, true},
+		{"Empty To", map[string]string{Host: "smtp.example.com", Port: "587", From: "edgex@example.com", To: " , "}, true},
+		{"Bad PersistOnError", map[string]string{Host: "smtp.example.com", Port: "587", From: "edgex@example.com", To: "ops@example.com", PersistOnError: "bogus"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.EmailExport(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestCorrectClockSkew(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid Offset", map[string]string{Offset: "-1.5s"}, false},
+		{"Valid NTP", map[string]string{NtpServer: "pool.ntp.org"}, false},
+		{"Valid NTP with refresh", map[string]string{NtpServer: "pool.ntp.org:123", RefreshInterval: "10m"}, false},
+		{"Missing Offset and NTP", map[string]string{}, true},
+		{"Both Offset and NTP", map[string]string{Offset: "1s", NtpServer: "pool.ntp.org"}, true},
+		{"Bad Offset", map[string]string{Offset: "bogus"}, true},
+		{"Empty NTP", map[string]string{NtpServer: " "}, true},
+		{"Bad RefreshInterval", map[string]string{NtpServer: "pool.ntp.org", RefreshInterval: "bogus"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.CorrectClockSkew(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestCoerceReadingTypes(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Default DropInvalid", map[string]string{}, false},
+		{"DropInvalid false", map[string]string{DropInvalid: "false"}, false},
+		{"Bad DropInvalid", map[string]string{DropInvalid: "bogus"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.CoerceReadingTypes(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestStreamJoin(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{SourceNames: "temperature, humidity", TimeInterval: "5s"}, false},
+		{"Valid with OutputSourceName", map[string]string{SourceNames: "temperature,humidity", TimeInterval: "5s", OutputSourceName: "climate"}, false},
+		{"Missing SourceNames", map[string]string{TimeInterval: "5s"}, true},
+		{"One SourceName", map[string]string{SourceNames: "temperature", TimeInterval: "5s"}, true},
+		{"Three SourceNames", map[string]string{SourceNames: "temperature,humidity,pressure", TimeInterval: "5s"}, true},
+		{"Same SourceNames", map[string]string{SourceNames: "temperature,temperature", TimeInterval: "5s"}, true},
+		{"Missing TimeInterval", map[string]string{SourceNames: "temperature,humidity"}, true},
+		{"Bad TimeInterval", map[string]string{SourceNames: "temperature,humidity", TimeInterval: "bogus"}, true},
+		{"Zero TimeInterval", map[string]string{SourceNames: "temperature,humidity", TimeInterval: "0s"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.StreamJoin(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Hash resources", map[string]string{Mode: "hash", ResourceNames: "serialNumber, latitude"}, false},
+		{"Hash with key", map[string]string{Mode: "hash", ResourceNames: "serialNumber", HashKey: "site-key"}, false},
+		{"Hash with secrets", map[string]string{Mode: "hash", TagKeys: "owner", SecretPath: "redact", SecretName: "hashKey"}, false},
+		{"Mask tags", map[string]string{Mode: "mask", TagKeys: "owner"}, false},
+		{"Drop", map[string]string{Mode: "drop", ResourceNames: "serialNumber", TagKeys: "owner"}, false},
+		{"Missing Mode", map[string]string{ResourceNames: "serialNumber"}, true},
+		{"Bad Mode", map[string]string{Mode: "scramble", ResourceNames: "serialNumber"}, true},
+		{"Nothing to redact", map[string]string{Mode: "hash", ResourceNames: " , "}, true},
+		{"Missing SecretName", map[string]string{Mode: "hash", ResourceNames: "serialNumber", SecretPath: "redact"}, true},
+		{"HashKey and secrets", map[string]string{Mode: "hash", ResourceNames: "serialNumber", HashKey: "key", SecretPath: "redact", SecretName: "hashKey"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.Redact(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"HMAC with key", map[string]string{Scheme: "hmac", EncryptionKey: "shared-secret"}, false},
+		{"JWS with secrets", map[string]string{Scheme: "JWS", SecretPath: "signing", SecretName: "key"}, false},
+		{"Missing Scheme", map[string]string{EncryptionKey: "shared-secret"}, true},
+		{"Bad Scheme", map[string]string{Scheme: "pgp", EncryptionKey: "shared-secret"}, true},
+		{"Missing key", map[string]string{Scheme: "hmac"}, true},
+		{"Missing SecretName", map[string]string{Scheme: "hmac", SecretPath: "signing"}, true},
+		{"Key and secrets", map[string]string{Scheme: "hmac", EncryptionKey: "shared-secret", SecretPath: "signing", SecretName: "key"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.VerifySignature(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	jsonSchema := `{"type": "object", "required": ["id"]}`
+	xmlSchema := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="id" type="xs:int"/></xs:schema>`
+
+	tests := []struct {
+		Name      string
+		XML       bool
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid JSON", false, map[string]string{Schema: jsonSchema}, false},
+		{"Valid JSON without stop", false, map[string]string{Schema: jsonSchema, StopOnViolation: "false"}, false},
+		{"Valid XML", true, map[string]string{Schema: xmlSchema, StopOnViolation: "true"}, false},
+		{"Missing JSON Schema", false, map[string]string{}, true},
+		{"Missing XML Schema", true, map[string]string{}, true},
+		{"Bad JSON Schema", false, map[string]string{Schema: "{"}, true},
+		{"Bad XML Schema", true, map[string]string{Schema: jsonSchema}, true},
+		{"Bad StopOnViolation", false, map[string]string{Schema: jsonSchema, StopOnViolation: "bogus"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			validateSchema := configurable.ValidateJSONSchema
+			if testCase.XML {
+				validateSchema = configurable.ValidateXMLSchema
+			}
+			transform := validateSchema(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestExpression(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid expression", map[string]string{Expression: "value * 0.1"}, false},
+		{"Valid with variables", map[string]string{Expression: "value * gain + offset", Variables: "gain:0.1, offset:-2.5"}, false},
+		{"Valid with resource names and output", map[string]string{Expression: "value", ResourceNames: "temperature, humidity", OutputResourceName: "scaled"}, false},
+		{"Missing Expression", map[string]string{}, true},
+		{"Bad Expression", map[string]string{Expression: "value *"}, true},
+		{"Unknown variable", map[string]string{Expression: "value * gain"}, true},
+		{"Bad Variables format", map[string]string{Expression: "value", Variables: "gain 0.1"}, true},
+		{"Bad Variable value", map[string]string{Expression: "value", Variables: "gain:bogus"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.Expression(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{Template: `{"device":"{{ .Event.DeviceName }}"}`}, false},
+		{"Missing Template", map[string]string{}, true},
+		{"Bad Template", map[string]string{Template: `{{ .Event.DeviceName `}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.Template(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestEnforceDataContract(t *testing.T) {
+	configurable := Configurable{lc: lc}
+	schema := `{"type": "object", "required": ["deviceName"]}`
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid with schema", map[string]string{ContractName: "configurable", ContractVersion: "v1", Schema: schema}, false},
+		{"Valid registered", map[string]string{ContractName: "configurable", ContractVersion: "v1", StopOnViolation: "false"}, false},
+		{"Missing name", map[string]string{ContractVersion: "v1", Schema: schema}, true},
+		{"Missing version", map[string]string{ContractName: "configurable", Schema: schema}, true},
+		{"Not registered", map[string]string{ContractName: "configurable", ContractVersion: "v2"}, true},
+		{"Bad schema", map[string]string{ContractName: "configurable", ContractVersion: "v3", Schema: "{"}, true},
+		{"Changed schema", map[string]string{ContractName: "configurable", ContractVersion: "v1", Schema: `{"type": "array"}`}, true},
+		{"Bad StopOnViolation", map[string]string{ContractName: "configurable", ContractVersion: "v1", StopOnViolation: "bogus"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.EnforceDataContract(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	configurable := Configurable{lc: lc}
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid Category", map[string]string{Sender: "app", Category: "alerts", Severity: "CRITICAL"}, false},
+		{"Valid Labels", map[string]string{Sender: "app", Labels: "temperature, {devicename}", Severity: "MINOR", Content: "Alert from {devicename}"}, false},
+		{"Missing Sender", map[string]string{Category: "alerts", Severity: "CRITICAL"}, true},
+		{"Missing Severity", map[string]string{Sender: "app", Category: "alerts"}, true},
+		{"Missing Category and Labels", map[string]string{Sender: "app", Labels: " , ", Severity: "CRITICAL"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.SendNotification(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
+
+func TestFileExport(t *testing.T) {
+	configurable := Configurable{lc: lc}
+	pattern := "site/{devicename}/{yyyy}/{MM}/{dd}/{HH}.ndjson.gz"
+
+	tests := []struct {
+		Name      string
+		Params    map[string]string
+		ExpectNil bool
+	}{
+		{"Valid", map[string]string{Directory: "/tmp/export", FilePattern: pattern}, false},
+		{"Valid with upload", map[string]string{Directory: "/tmp/export", FilePattern: pattern, Url: "http://localhost/bucket", HeaderName: "Authorization", SecretPath: "upload", SecretName: "token", RemoveAfterUpload: "true"}, false},
+		{"Missing Directory", map[string]string{FilePattern: pattern}, true},
+		{"Missing FilePattern", map[string]string{Directory: "/tmp/export"}, true},
+		{"No time placeholder", map[string]string{Directory: "/tmp/export", FilePattern: "{devicename}.ndjson"}, true},
+		{"Bad RemoveAfterUpload", map[string]string{Directory: "/tmp/export", FilePattern: pattern, RemoveAfterUpload: "bogus"}, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform := configurable.FileExport(testCase.Params)
+			assert.Equal(t, testCase.ExpectNil, transform == nil)
+		})
+	}
+}
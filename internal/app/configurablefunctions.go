@@ -0,0 +1,471 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms"
+)
+
+// ConfigurableFunctions returns the descriptions of the built in functions available to the configurable functions
+// pipeline and their parameters. The functions are the Configurable methods, so functions missing from the
+// configurableFunctions metadata are still listed, without their parameters.
+func (svc *Service) ConfigurableFunctions() []interfaces.ConfigurableFunction {
+	appFunctionType := reflect.TypeOf((*interfaces.AppFunction)(nil)).Elem()
+	parametersType := reflect.TypeOf(map[string]string{})
+
+	configurableType := reflect.TypeOf(&Configurable{})
+	functions := make([]interfaces.ConfigurableFunction, 0, configurableType.NumMethod())
+	for index := 0; index < configurableType.NumMethod(); index++ {
+		method := configurableType.Method(index)
+		// The method's type includes the receiver
+		if method.Type.NumIn() != 2 || method.Type.In(1) != parametersType ||
+			method.Type.NumOut() != 1 || method.Type.Out(0) != appFunctionType {
+			continue
+		}
+
+		function, found := configurableFunctions[method.Name]
+		if !found {
+			function = interfaces.ConfigurableFunction{Parameters: []interfaces.ConfigurableFunctionParameter{}}
+		}
+		function.Name = method.Name
+		functions = append(functions, function)
+	}
+
+	return functions
+}
+
+func requiredParameter(name string, parameterType string, description string) interfaces.ConfigurableFunctionParameter {
+	return interfaces.ConfigurableFunctionParameter{Name: name, Type: parameterType, Required: true, Description: description}
+}
+
+func optionalParameter(name string, parameterType string, defaultValue string, description string) interfaces.ConfigurableFunctionParameter {
+	return interfaces.ConfigurableFunctionParameter{Name: name, Type: parameterType, Default: defaultValue, Description: description}
+}
+
+func withValues(parameter interfaces.ConfigurableFunctionParameter, values ...string) interfaces.ConfigurableFunctionParameter {
+	parameter.Values = values
+	return parameter
+}
+
+func asSecret(parameter interfaces.ConfigurableFunctionParameter) interfaces.ConfigurableFunctionParameter {
+	parameter.Secret = true
+	return parameter
+}
+
+func filterFunction(description string, namesParameter string, namesDescription string) interfaces.ConfigurableFunction {
+	return interfaces.ConfigurableFunction{
+		Description: description,
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(namesParameter, interfaces.ParameterTypeList, namesDescription),
+			optionalParameter(FilterOut, interfaces.ParameterTypeBool, "false", "Filter out, rather than for, the names"),
+			withValues(optionalParameter(MatchMode, interfaces.ParameterTypeString, transforms.FilterMatchExact, "How the names are matched"),
+				transforms.FilterMatchExact, transforms.FilterMatchRegex, transforms.FilterMatchGlob),
+		},
+	}
+}
+
+func schemaValidationFunction(description string) interfaces.ConfigurableFunction {
+	return interfaces.ConfigurableFunction{
+		Description: description,
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Schema, interfaces.ParameterTypeString, "The schema the data must conform to"),
+			optionalParameter(StopOnViolation, interfaces.ParameterTypeBool, "true", "Stop the pipeline, rather than tag the data with the violations, when the data doesn't conform"),
+		},
+	}
+}
+
+var (
+	secretPathParameter     = optionalParameter(SecretPath, interfaces.ParameterTypeString, "", "Path in the Secret Store of the secret")
+	secretNameParameter     = optionalParameter(SecretName, interfaces.ParameterTypeString, "", "Name of the secret at the SecretPath")
+	headerNameParameter     = optionalParameter(HeaderName, interfaces.ParameterTypeString, "", "HTTP header set to the secret at the SecretPath and SecretName")
+	persistOnErrorParameter = optionalParameter(PersistOnError, interfaces.ParameterTypeBool, "false", "Store the data for retry when the export fails")
+	clientIdParameter       = optionalParameter(ClientID, interfaces.ParameterTypeString, "", "MQTT client id")
+	checksumParameter       = withValues(optionalParameter(Checksum, interfaces.ParameterTypeString, "", "Algorithm of the checksum added so the receiver can detect corrupted data"),
+		transforms.ChecksumCRC32, transforms.ChecksumSHA256)
+	maxConcurrencyParameter = optionalParameter(MaxConcurrency, interfaces.ParameterTypeInt, "0", "Most sends in flight to the destination, further sends wait in order. 0 for no limit")
+	mqttAuthModeParameter   = withValues(optionalParameter(AuthMode, interfaces.ParameterTypeString, "none", "How to authenticate with the broker using the secret at the SecretPath"),
+		"none", "usernamepassword", "clientcert", "cacert")
+)
+
+// configurableFunctions describes the parameters of each Configurable function, keyed by the function name. Must be
+// updated along with the Configurable functions.
+var configurableFunctions = map[string]interfaces.ConfigurableFunction{
+	"FilterByProfileName": filterFunction("Filters Events by their device profile name",
+		ProfileNames, "Comma separated device profile names"),
+	"FilterByDeviceName": filterFunction("Filters Events by their device name",
+		DeviceNames, "Comma separated device names"),
+	"FilterBySourceName": filterFunction("Filters Events by their source name",
+		SourceNames, "Comma separated source names"),
+	"FilterByResourceName": filterFunction("Filters Event readings by their resource name",
+		ResourceNames, "Comma separated resource names"),
+	"CoerceReadingTypes": {
+		Description: "Validates and normalizes reading values against the ValueTypes of their Device Profile",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			optionalParameter(DropInvalid, interfaces.ParameterTypeBool, "true", "Drop, rather than tag, readings whose values are invalid"),
+		},
+	},
+	"Redact": {
+		Description: "Hashes, masks or drops sensitive readings and tags",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			withValues(requiredParameter(Mode, interfaces.ParameterTypeString, "How the values are redacted"),
+				transforms.RedactModeHash, transforms.RedactModeMask, transforms.RedactModeDrop),
+			optionalParameter(ResourceNames, interfaces.ParameterTypeList, "", "Resource names of the readings to redact"),
+			optionalParameter(TagKeys, interfaces.ParameterTypeList, "", "Keys of the tags to redact"),
+			asSecret(optionalParameter(HashKey, interfaces.ParameterTypeString, "", "HMAC-SHA256 key when hashing, rather than the secret")),
+			secretPathParameter,
+			secretNameParameter,
+		},
+	},
+	"Transform": {
+		Description: "Converts Events to XML or JSON",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			withValues(requiredParameter(TransformType, interfaces.ParameterTypeString, "Format the Events are converted to"),
+				TransformXml, TransformJson, TransformMsgPack),
+		},
+	},
+	"ExtractBinaryPayload": {
+		Description: "Extracts the raw value of an Event's binary reading",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			optionalParameter(ResourceName, interfaces.ParameterTypeString, "", "Resource name of the binary reading, otherwise the first"),
+		},
+	},
+	"ProcessImages": {
+		Description: "Resizes and re-encodes the images in Events' binary readings",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			optionalParameter(MaxWidth, interfaces.ParameterTypeInt, "0", "Maximum width in pixels, zero is unlimited"),
+			optionalParameter(MaxHeight, interfaces.ParameterTypeInt, "0", "Maximum height in pixels, zero is unlimited"),
+			withValues(optionalParameter(Format, interfaces.ParameterTypeString, "", "Format images are re-encoded to, otherwise their original format"),
+				transforms.ImageFormatJPEG, transforms.ImageFormatPNG),
+			optionalParameter(Quality, interfaces.ParameterTypeInt, "75", "JPEG quality, from 1 to 100"),
+			optionalParameter(MaxBytes, interfaces.ParameterTypeInt, "0", "Maximum encoded size, zero is unlimited"),
+		},
+	},
+	"EncodeDelta": {
+		Description: "Replaces payloads with the delta against the previous payload of the device",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			optionalParameter(BlockSize, interfaces.ParameterTypeInt, strconv.Itoa(transforms.DefaultDeltaBlockSize), "Size of the chunks matched against the previous payload"),
+			optionalParameter(FullPayloadInterval, interfaces.ParameterTypeInt, "0", "Send every Nth payload in full, zero is never"),
+		},
+	},
+	"AddRevision": {
+		Description: "Wraps Events in records with per-device revision numbers and tombstones for removed devices",
+	},
+	"ForwardToEKuiper": {
+		Description: "Forwards Events to an eKuiper rules engine and optionally continues with the rule result",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			optionalParameter(Url, interfaces.ParameterTypeString, "", "URL of eKuiper's REST source, otherwise BrokerAddress is used"),
+			optionalParameter(BrokerAddress, interfaces.ParameterTypeString, "", "Address of the broker of eKuiper's MQTT source"),
+			optionalParameter(Topic, interfaces.ParameterTypeString, "", "Topic of eKuiper's MQTT source"),
+			optionalParameter(ResultTopic, interfaces.ParameterTypeString, "", "Topic the rule results are published to"),
+			optionalParameter(CorrelationField, interfaces.ParameterTypeString, "id", "Field of the rule result holding the Event id"),
+			optionalParameter(Timeout, interfaces.ParameterTypeDuration, "5s", "How long to wait for the rule result"),
+			clientIdParameter,
+			mqttAuthModeParameter,
+			secretPathParameter,
+		},
+	},
+	"ConvertToNGSILD": {
+		Description: "Converts Events to NGSI-LD entities",
+		Parameters:  ngsiLDParameters(),
+	},
+	"NGSILDExport": {
+		Description: "Creates or updates the NGSI-LD entities of Events in an NGSI-LD context broker",
+		Parameters: append([]interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Url, interfaces.ParameterTypeString, "Base URL of the context broker"),
+			persistOnErrorParameter,
+			headerNameParameter,
+			secretPathParameter,
+			secretNameParameter,
+		}, ngsiLDParameters()...),
+	},
+	"ConvertToV1": {
+		Description: "Converts Events to EdgeX V1 Events in JSON, keeping the profile and source names as tags",
+	},
+	"ConvertFromV1": {
+		Description: "Converts EdgeX V1 Events in JSON to V2 Events",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			optionalParameter(ProfileNames, interfaces.ParameterTypeString, "", "Comma separated list of 'devicename:profilename' of the profile names of V1 Events"),
+			optionalParameter(DefaultProfileName, interfaces.ParameterTypeString, "", "Profile name of V1 Events whose device isn't in the ProfileNames, otherwise the device name"),
+			optionalParameter(SourceName, interfaces.ParameterTypeString, "", "Source name of V1 Events, otherwise the name of the first reading"),
+		},
+	},
+	"V1Export": {
+		Description: "Sends Events, converted to V1, or V1 Events to a V1 Core Data service",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Url, interfaces.ParameterTypeString, "Base URL of the V1 Core Data service"),
+			persistOnErrorParameter,
+			headerNameParameter,
+			secretPathParameter,
+			secretNameParameter,
+		},
+	},
+	"SensorThingsExport": {
+		Description: "Creates OGC SensorThings API Observations of existing Datastreams for Event readings",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Url, interfaces.ParameterTypeString, "Base URL of the SensorThings API service"),
+			optionalParameter(DatastreamName, interfaces.ParameterTypeString, transforms.DefaultSensorThingsDatastreamName, "Name of the Datastream of each reading, which may contain context value placeholders and {resourcename}"),
+			persistOnErrorParameter,
+			headerNameParameter,
+			secretPathParameter,
+			secretNameParameter,
+		},
+	},
+	"ElasticsearchExport": {
+		Description: "Bulk indexes the Event or batch of documents in Elasticsearch or OpenSearch",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Url, interfaces.ParameterTypeString, "Base URL of the cluster"),
+			optionalParameter(Index, interfaces.ParameterTypeString, transforms.DefaultElasticsearchIndex, "Index name, which may contain context value placeholders and {date}"),
+			withValues(optionalParameter(AuthMode, interfaces.ParameterTypeString, transforms.ElasticsearchAuthModeNone, "How to authenticate with the cluster using the secrets at the SecretPath"),
+				transforms.ElasticsearchAuthModeNone, transforms.ElasticsearchAuthModeBasic, transforms.ElasticsearchAuthModeAPIKey),
+			secretPathParameter,
+			optionalParameter(SkipVerify, interfaces.ParameterTypeBool, "false", "Skip verifying the cluster's certificate"),
+			optionalParameter(Timeout, interfaces.ParameterTypeDuration, "30s", "How long to wait for each bulk request"),
+			optionalParameter(MaxRetries, interfaces.ParameterTypeInt, "3", "Number of retries of documents rejected with 429 Too Many Requests"),
+			optionalParameter(InitialBackoff, interfaces.ParameterTypeDuration, "1s", "Delay before the first retry, which doubles with each retry"),
+			persistOnErrorParameter,
+		},
+	},
+	"WebSocketExport": {
+		Description: "Sends the data to a WebSocket endpoint, one message each",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Url, interfaces.ParameterTypeString, "URL of the endpoint, using the ws or wss scheme"),
+			headerNameParameter,
+			secretPathParameter,
+			secretNameParameter,
+			optionalParameter(SkipVerify, interfaces.ParameterTypeBool, "false", "Skip verifying the endpoint's certificate"),
+			optionalParameter(Timeout, interfaces.ParameterTypeDuration, "10s", "How long to wait for the connection and for each message to be sent"),
+			persistOnErrorParameter,
+		},
+	},
+	"StreamExport": {
+		Description: "Publishes the data to the WebSocket clients of the service's /stream endpoint and passes it on",
+	},
+	"PushToCore": {
+		Description: "Pushes the data to Core Data as a new Event with a single reading",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(ProfileName, interfaces.ParameterTypeString, "Device profile name of the Event"),
+			requiredParameter(DeviceName, interfaces.ParameterTypeString, "Device name of the Event"),
+			requiredParameter(ResourceName, interfaces.ParameterTypeString, "Resource name of the reading"),
+			requiredParameter(ValueType, interfaces.ParameterTypeString, "Value type of the reading"),
+			optionalParameter(MediaType, interfaces.ParameterTypeString, "", "Media type of the reading, required when the ValueType is Binary"),
+		},
+	},
+	"Compress": {
+		Description: "Compresses the data and base64 encodes the result",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			withValues(requiredParameter(Algorithm, interfaces.ParameterTypeString, "Compression algorithm"), CompressGZIP, CompressZLIB),
+			optionalParameter(CompressionLevel, interfaces.ParameterTypeInt, "-1", "Compression level from -2 (Huffman only) and 0 (none) to 9 (best compression), -1 being the default"),
+			optionalParameter(Dictionary, interfaces.ParameterTypeString, "", "Preset dictionary of strings common in the data. ZLIB only"),
+		},
+	},
+	"Encrypt": {
+		Description: "Encrypts the data, the values of readings or fields of JSON data",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			withValues(requiredParameter(Algorithm, interfaces.ParameterTypeString, "Encryption algorithm"), EncryptAES),
+			asSecret(requiredParameter(InitVector, interfaces.ParameterTypeString, "Initialization vector")),
+			asSecret(optionalParameter(EncryptionKey, interfaces.ParameterTypeString, "", "Encryption key, rather than the secret")),
+			secretPathParameter,
+			secretNameParameter,
+			optionalParameter(ResourceNames, interfaces.ParameterTypeList, "", "Resource names of the readings to encrypt"),
+			optionalParameter(Fields, interfaces.ParameterTypeList, "", "Fields of the JSON data to encrypt"),
+		},
+	},
+	"HTTPExport": {
+		Description: "Sends the data to an HTTP endpoint",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			withValues(requiredParameter(ExportMethod, interfaces.ParameterTypeString, "HTTP method"), ExportMethodPost, ExportMethodPut),
+			requiredParameter(Url, interfaces.ParameterTypeString, "URL, which may contain context value placeholders"),
+			requiredParameter(MimeType, interfaces.ParameterTypeString, "Content type of the request, otherwise that set by the previous function"),
+			persistOnErrorParameter,
+			optionalParameter(ContinueOnSendError, interfaces.ParameterTypeBool, "false", "Continue the pipeline when the export fails"),
+			optionalParameter(ReturnInputData, interfaces.ParameterTypeBool, "false", "Continue with the data sent rather than the response"),
+			optionalParameter(LatencyHeaders, interfaces.ParameterTypeBool, "false", "Add headers with the milliseconds the data was queued and processed for"),
+			maxConcurrencyParameter,
+			optionalParameter(Headers, interfaces.ParameterTypeList, "", "Comma separated name:value headers, where values may be secret://<path>#<name> secret references"),
+			optionalParameter(VerifyUrl, interfaces.ParameterTypeString, "", "URL read back with GET after sending to confirm the data was accepted, which may contain context value placeholders"),
+			optionalParameter(VerifyContains, interfaces.ParameterTypeString, "", "Text the VerifyUrl response must contain, which may contain context value placeholders"),
+			checksumParameter,
+			headerNameParameter,
+			secretPathParameter,
+			secretNameParameter,
+		},
+	},
+	"MQTTExport": {
+		Description: "Publishes the data to an MQTT broker",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(BrokerAddress, interfaces.ParameterTypeString, "Address of the broker, or comma separated addresses of the primary and standby brokers"),
+			requiredParameter(Topic, interfaces.ParameterTypeString, "Topic published to"),
+			withValues(requiredParameter(AuthMode, interfaces.ParameterTypeString, "How to authenticate with the broker using the secret at the SecretPath"),
+				"none", "usernamepassword", "clientcert", "cacert"),
+			requiredParameter(SecretPath, interfaces.ParameterTypeString, "Path in the Secret Store of the secret"),
+			requiredParameter(ClientID, interfaces.ParameterTypeString, "MQTT client id"),
+			withValues(optionalParameter(Qos, interfaces.ParameterTypeInt, "0", "MQTT quality of service"), "0", "1", "2"),
+			optionalParameter(Retain, interfaces.ParameterTypeBool, "false", "Publish retained messages"),
+			optionalParameter(AutoReconnect, interfaces.ParameterTypeBool, "false", "Reconnect when the connection is lost"),
+			optionalParameter(SkipVerify, interfaces.ParameterTypeBool, "false", "Skip verifying the broker's certificate"),
+			optionalParameter(KeepAlive, interfaces.ParameterTypeDuration, "", "Interval between keepalive pings"),
+			optionalParameter(ConnectTimeout, interfaces.ParameterTypeDuration, "", "How long to wait when connecting"),
+			optionalParameter(PrimaryCheckInterval, interfaces.ParameterTypeDuration, "30s", "How often the primary broker is checked while connected to a standby broker"),
+			optionalParameter(StatusTopic, interfaces.ParameterTypeString, "", "Topic the connection status is published to"),
+			optionalParameter(OnlinePayload, interfaces.ParameterTypeString, "", "Status published when connected"),
+			optionalParameter(OfflinePayload, interfaces.ParameterTypeString, "", "Status published when disconnected"),
+			optionalParameter(BufferDirectory, interfaces.ParameterTypeString, "", "Directory messages are buffered in while disconnected"),
+			optionalParameter(MaxBufferedMessages, interfaces.ParameterTypeInt, "", "Maximum number of buffered messages"),
+			maxConcurrencyParameter,
+			persistOnErrorParameter,
+			checksumParameter,
+		},
+	},
+	"EmailExport": {
+		Description: "Sends the data as an email via SMTP",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Host, interfaces.ParameterTypeString, "SMTP server host"),
+			requiredParameter(Port, interfaces.ParameterTypeInt, "SMTP server port"),
+			requiredParameter(From, interfaces.ParameterTypeString, "Sender address"),
+			requiredParameter(To, interfaces.ParameterTypeList, "Recipient addresses"),
+			optionalParameter(Subject, interfaces.ParameterTypeString, "", "Subject, which may contain context value placeholders"),
+			optionalParameter(MessageTemplate, interfaces.ParameterTypeString, "", "Body, which may contain context value placeholders, otherwise the data"),
+			withValues(optionalParameter(AuthMode, interfaces.ParameterTypeString, transforms.EmailAuthModeNone, "How to authenticate with the SMTP server using the secret at the SecretPath"),
+				transforms.EmailAuthModeNone, transforms.EmailAuthModeUsernamePassword),
+			secretPathParameter,
+			persistOnErrorParameter,
+		},
+	},
+	"SetResponseData": {
+		Description: "Sets the data as the response returned to the trigger",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			optionalParameter(ResponseContentType, interfaces.ParameterTypeString, "", "Content type of the response"),
+			withValues(optionalParameter(TransformType, interfaces.ParameterTypeString, transforms.ResponseSerializationJSON, "Format data which isn't a string or []byte is marshaled to"),
+				transforms.ResponseSerializationJSON, transforms.ResponseSerializationXML, transforms.ResponseSerializationCBOR),
+			optionalParameter(Append, interfaces.ParameterTypeBool, "false", "Adds the data as a part of the response rather than replacing it"),
+			optionalParameter(ResponseTopic, interfaces.ParameterTypeString, "", "Topic the response is published to, overriding the trigger's publish topic"),
+		},
+	},
+	"Batch": {
+		Description: "Batches the data by count, time, both or size",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			withValues(requiredParameter(Mode, interfaces.ParameterTypeString, "Batch mode"), BatchByCount, BatchByTime, BatchByTimeAndCount, BatchByBytes),
+			optionalParameter(BatchThreshold, interfaces.ParameterTypeInt, "", "Number of items batched, required by the bycount and bytimecount modes"),
+			optionalParameter(TimeInterval, interfaces.ParameterTypeDuration, "", "Interval batched, required by the bytime and bytimecount modes"),
+			optionalParameter(ByteThreshold, interfaces.ParameterTypeInt, "", "Size in bytes at which the batch is forwarded, required by the bybytes mode"),
+			optionalParameter(FlushName, interfaces.ParameterTypeString, "", "Name used to flush the batch on its own, supported by the bytime and bytimecount modes"),
+		},
+	},
+	"JSONLogic": {
+		Description: "Filters the data with a JSONLogic rule",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Rule, interfaces.ParameterTypeString, "JSONLogic rule"),
+		},
+	},
+	"AddTags": {
+		Description: "Adds tags to Events",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Tags, interfaces.ParameterTypeList, "Comma separated key:value tags"),
+		},
+	},
+	"AddLatencyTags": {
+		Description: "Adds tags to Events with the milliseconds they were queued and have been processed for",
+	},
+	"CorrectClockSkew": {
+		Description: "Corrects the Origin timestamps of Events and their readings by a fixed or NTP offset",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			optionalParameter(Offset, interfaces.ParameterTypeDuration, "", "Fixed offset, otherwise NtpServer is used"),
+			optionalParameter(NtpServer, interfaces.ParameterTypeString, "", "NTP server the offset is measured against"),
+			optionalParameter(RefreshInterval, interfaces.ParameterTypeDuration, "1h", "Interval the NTP offset is measured at"),
+		},
+	},
+	"Expression": {
+		Description: "Evaluates an arithmetic expression for numeric readings, i.e. to calibrate them",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Expression, interfaces.ParameterTypeString, "Arithmetic expression of the reading's value"),
+			optionalParameter(Variables, interfaces.ParameterTypeList, "", "Comma separated name:value variables"),
+			optionalParameter(ResourceNames, interfaces.ParameterTypeList, "", "Resource names of the readings evaluated, otherwise all"),
+			optionalParameter(OutputResourceName, interfaces.ParameterTypeString, "", "Resource name of a new reading for the result, otherwise the value is replaced"),
+		},
+	},
+	"StreamJoin": {
+		Description: "Joins the Events from two sources whose Origins are close together",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(SourceNames, interfaces.ParameterTypeList, "The two source names"),
+			requiredParameter(TimeInterval, interfaces.ParameterTypeDuration, "Maximum time between the Events joined"),
+			optionalParameter(OutputSourceName, interfaces.ParameterTypeString, "", "Source name of the joined Events"),
+		},
+	},
+	"Template": {
+		Description: "Renders a Go text/template against the data",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Template, interfaces.ParameterTypeString, "Go text/template"),
+		},
+	},
+	"EnforceDataContract": {
+		Description: "Validates the output of the previous function against a registered data contract",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(ContractName, interfaces.ParameterTypeString, "Data contract name"),
+			requiredParameter(ContractVersion, interfaces.ParameterTypeString, "Data contract version"),
+			optionalParameter(Schema, interfaces.ParameterTypeString, "", "JSON Schema registered for the version"),
+			optionalParameter(StopOnViolation, interfaces.ParameterTypeBool, "true", "Stop the pipeline, rather than log, when the output doesn't conform"),
+		},
+	},
+	"ValidateJSONSchema": schemaValidationFunction("Validates the data against a JSON Schema"),
+	"ValidateXMLSchema":  schemaValidationFunction("Validates the data against an XML Schema (XSD)"),
+	"VerifySignature": {
+		Description: "Verifies the signature of the payload received",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			withValues(requiredParameter(Scheme, interfaces.ParameterTypeString, "Signature scheme"), transforms.SignatureSchemeHMAC, transforms.SignatureSchemeJWS),
+			asSecret(optionalParameter(EncryptionKey, interfaces.ParameterTypeString, "", "Verification key, rather than the secret")),
+			secretPathParameter,
+			secretNameParameter,
+		},
+	},
+	"SendNotification": {
+		Description: "Creates a Support Notification",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Sender, interfaces.ParameterTypeString, "Sender of the notification"),
+			withValues(requiredParameter(Severity, interfaces.ParameterTypeString, "Severity of the notification"), "MINOR", "NORMAL", "CRITICAL"),
+			optionalParameter(Category, interfaces.ParameterTypeString, "", "Category of the notification, required when Labels isn't specified"),
+			optionalParameter(Labels, interfaces.ParameterTypeList, "", "Labels of the notification, required when Category isn't specified"),
+			optionalParameter(Content, interfaces.ParameterTypeString, "", "Content of the notification, otherwise the data"),
+			optionalParameter(ContentType, interfaces.ParameterTypeString, "", "Content type of the content"),
+			optionalParameter(Description, interfaces.ParameterTypeString, "", "Description of the notification"),
+		},
+	},
+	"FileExport": {
+		Description: "Writes the data to rolling files and optionally uploads them",
+		Parameters: []interfaces.ConfigurableFunctionParameter{
+			requiredParameter(Directory, interfaces.ParameterTypeString, "Local directory of the files"),
+			requiredParameter(FilePattern, interfaces.ParameterTypeString, "File name pattern with time and context value placeholders"),
+			optionalParameter(Url, interfaces.ParameterTypeString, "", "URL the finalized files are uploaded to"),
+			headerNameParameter,
+			secretPathParameter,
+			secretNameParameter,
+			optionalParameter(RemoveAfterUpload, interfaces.ParameterTypeBool, "false", "Remove the files once uploaded"),
+			checksumParameter,
+		},
+	},
+}
+
+func ngsiLDParameters() []interfaces.ConfigurableFunctionParameter {
+	return []interfaces.ConfigurableFunctionParameter{
+		optionalParameter(EntityType, interfaces.ParameterTypeString, transforms.DefaultNGSILDEntityType, "Entity type, which may contain context value placeholders"),
+		optionalParameter(EntityId, interfaces.ParameterTypeString, transforms.DefaultNGSILDEntityId, "Entity id, which may contain context value placeholders"),
+		optionalParameter(JSONLDContext, interfaces.ParameterTypeString, transforms.NGSILDCoreContext, "JSON-LD @context URL of the entities"),
+	}
+}
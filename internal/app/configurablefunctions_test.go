@@ -0,0 +1,129 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+func TestConfigurableFunctions(t *testing.T) {
+	svc := &Service{lc: lc}
+	functions := svc.ConfigurableFunctions()
+
+	names := make(map[string]bool)
+	for _, function := range functions {
+		names[function.Name] = true
+	}
+
+	require.Contains(t, names, "HTTPExport")
+	assert.NotContains(t, names, "processFilterParameters", "unexported methods must not be listed")
+
+	for name := range configurableFunctions {
+		assert.Contains(t, names, name, "metadata for '%s' which is not a Configurable function", name)
+	}
+
+	validTypes := map[string]bool{
+		interfaces.ParameterTypeString:   true,
+		interfaces.ParameterTypeBool:     true,
+		interfaces.ParameterTypeInt:      true,
+		interfaces.ParameterTypeDuration: true,
+		interfaces.ParameterTypeList:     true,
+	}
+
+	registrySecrets := make(map[string]bool)
+	for _, function := range functions {
+		t.Run(function.Name, func(t *testing.T) {
+			_, found := configurableFunctions[function.Name]
+			require.True(t, found, "Configurable function '%s' is missing from the configurableFunctions metadata", function.Name)
+			assert.NotEmpty(t, function.Description)
+
+			parameters := make(map[string]bool)
+			for _, parameter := range function.Parameters {
+				assert.Equal(t, strings.ToLower(parameter.Name), parameter.Name)
+				assert.False(t, parameters[parameter.Name], "parameter '%s' is duplicated", parameter.Name)
+				parameters[parameter.Name] = true
+
+				assert.True(t, validTypes[parameter.Type], "parameter '%s' has invalid type '%s'", parameter.Name, parameter.Type)
+				assert.False(t, parameter.Required && len(parameter.Default) > 0, "required parameter '%s' has a default", parameter.Name)
+				if len(parameter.Values) > 0 && len(parameter.Default) > 0 {
+					assert.Contains(t, parameter.Values, parameter.Default)
+				}
+				if parameter.Secret {
+					registrySecrets[parameter.Name] = true
+				}
+			}
+		})
+	}
+
+	assert.Equal(t, secretParameters, registrySecrets, "secret parameters must match those redacted in pipeline snapshots")
+}
+
+func TestConfigurableFunctionsRequiredParameters(t *testing.T) {
+	configurable := Configurable{lc: lc}
+	examples := map[string]string{
+		interfaces.ParameterTypeString:   "value",
+		interfaces.ParameterTypeBool:     "true",
+		interfaces.ParameterTypeInt:      "1",
+		interfaces.ParameterTypeDuration: "1s",
+		interfaces.ParameterTypeList:     "a,b",
+	}
+
+	// Functions whose required parameters alone create the function
+	names := []string{"FilterByDeviceName", "StreamJoin", "SparkplugBExport", "NGSILDExport", "HTTPExport"}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			// Functions with optional dependencies are left out of builds with their tags
+			method := reflect.ValueOf(&configurable).MethodByName(name)
+			if !method.IsValid() {
+				t.Skipf("%s is not in this build", name)
+			}
+			function := method.Interface().(func(map[string]string) interfaces.AppFunction)
+
+			parameters := make(map[string]string)
+			for _, parameter := range configurableFunctions[name].Parameters {
+				if !parameter.Required {
+					continue
+				}
+				value := examples[parameter.Type]
+				if len(parameter.Values) > 0 {
+					value = parameter.Values[0]
+				}
+				parameters[parameter.Name] = value
+			}
+
+			assert.NotNil(t, function(parameters))
+
+			for required := range parameters {
+				partial := make(map[string]string)
+				for key, value := range parameters {
+					if key != required {
+						partial[key] = value
+					}
+				}
+				assert.Nil(t, function(partial), "'%s' must be required", required)
+			}
+		})
+	}
+}
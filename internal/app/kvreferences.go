@@ -0,0 +1,223 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/config"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/environment"
+	"github.com/edgexfoundry/go-mod-configuration/v2/configuration"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+)
+
+// KVReferencePrefix is the prefix of configurable function parameter values which reference a Consul KV key,
+// i.e. consul://edgex/shared/endpointUrl, whose value is substituted when the functions pipeline is loaded
+const KVReferencePrefix = "consul://"
+
+// kvReferenceWatchInterval is how often the referenced Consul KV keys are checked for updated values
+var kvReferenceWatchInterval = 15 * time.Second
+
+// kvValueGetter is the portion of the Configuration Provider client used to retrieve the referenced keys
+type kvValueGetter interface {
+	GetConfigurationValue(name string) ([]byte, error)
+}
+
+// kvReferenceWatcher resolves the Consul KV keys referenced by the configurable function parameters and
+// notifies when their values change
+type kvReferenceWatcher struct {
+	mutex sync.Mutex
+	// newClient creates the client used to retrieve the referenced keys when the first reference is resolved
+	newClient func() (kvValueGetter, error)
+	client    kvValueGetter
+	values    map[string]string
+}
+
+// newKVReferenceClient creates a Configuration Provider client without a base path, so references are to
+// the full key path, using the same Configuration Provider and access token as the service's configuration.
+func (svc *Service) newKVReferenceClient() (kvValueGetter, error) {
+	providerUrl := ""
+	if svc.flags != nil {
+		providerUrl = svc.flags.ConfigProviderUrl()
+	}
+
+	providerInfo, err := config.NewProviderInfo(environment.NewVariables(svc.lc), providerUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if !providerInfo.UseProvider() {
+		return nil, errors.New("the Configuration Provider must be used for parameters to reference Consul keys")
+	}
+
+	serviceConfig := providerInfo.ServiceConfig()
+	serviceConfig.BasePath = ""
+
+	if provider := svc.secretProvider(); provider != nil {
+		serviceConfig.AccessToken, err = provider.GetAccessToken(serviceConfig.Type, svc.serviceKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Configuration Provider (%s) access token: %s", serviceConfig.Type, err.Error())
+		}
+	}
+
+	return configuration.NewConfigurationClient(serviceConfig)
+}
+
+// resolve returns the parameters with the values that reference Consul keys replaced by the keys' values.
+// The parameters are returned unchanged when none reference a key so the client is only created when needed.
+func (watcher *kvReferenceWatcher) resolve(parameters map[string]string) (map[string]string, error) {
+	var resolved map[string]string
+
+	for name, value := range parameters {
+		if !strings.HasPrefix(value, KVReferencePrefix) {
+			continue
+		}
+
+		if resolved == nil {
+			resolved = make(map[string]string, len(parameters))
+			for name, value := range parameters {
+				resolved[name] = value
+			}
+		}
+
+		key := strings.TrimPrefix(value, KVReferencePrefix)
+		keyValue, err := watcher.getValue(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve '%s' parameter: %s", name, err.Error())
+		}
+
+		resolved[name] = keyValue
+	}
+
+	if resolved == nil {
+		return parameters, nil
+	}
+
+	return resolved, nil
+}
+
+func (watcher *kvReferenceWatcher) getValue(key string) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("missing key in '%s' reference", KVReferencePrefix)
+	}
+
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	if watcher.client == nil {
+		client, err := watcher.newClient()
+		if err != nil {
+			return "", fmt.Errorf("unable to create client for Consul references: %s", err.Error())
+		}
+		watcher.client = client
+	}
+
+	value, err := watcher.client.GetConfigurationValue(key)
+	if err != nil {
+		return "", err
+	}
+
+	if value == nil {
+		return "", fmt.Errorf("referenced Consul key '%s' not found", key)
+	}
+
+	if watcher.values == nil {
+		watcher.values = make(map[string]string)
+	}
+	watcher.values[key] = string(value)
+
+	return string(value), nil
+}
+
+// reset forgets the previously resolved keys, so only the keys referenced by the reloaded pipeline are watched
+func (watcher *kvReferenceWatcher) reset() {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	watcher.values = nil
+}
+
+// keys returns the sorted list of referenced keys
+func (watcher *kvReferenceWatcher) keys() []string {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	keys := make([]string, 0, len(watcher.values))
+	for key := range watcher.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// start runs the loop checking the referenced keys for updated values until the context is cancelled.
+// The changed callback is called, i.e. to reload the pipeline, when the value of any of the keys has changed.
+func (watcher *kvReferenceWatcher) start(appWg *sync.WaitGroup, appCtx context.Context, lc logger.LoggingClient, changed func()) {
+	appWg.Add(1)
+
+	go func() {
+		defer appWg.Done()
+
+		lc.Infof("Starting watcher for referenced Consul keys %v", watcher.keys())
+
+		for {
+			select {
+			case <-appCtx.Done():
+				lc.Info("Exiting watcher for referenced Consul keys")
+				return
+
+			case <-time.After(kvReferenceWatchInterval):
+				if watcher.checkForUpdates(lc) {
+					changed()
+				}
+			}
+		}
+	}()
+}
+
+// checkForUpdates returns whether the value of any of the referenced keys has changed since they were resolved
+func (watcher *kvReferenceWatcher) checkForUpdates(lc logger.LoggingClient) bool {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	updated := false
+	for key, previous := range watcher.values {
+		value, err := watcher.client.GetConfigurationValue(key)
+		if err != nil {
+			lc.Debugf("Unable to retrieve referenced Consul key '%s' to check for updates: %s", key, err.Error())
+			continue
+		}
+
+		if value == nil {
+			lc.Warnf("Referenced Consul key '%s' has been removed, keeping previous value", key)
+			continue
+		}
+
+		if string(value) != previous {
+			lc.Infof("Referenced Consul key '%s' has been updated", key)
+			updated = true
+		}
+	}
+
+	return updated
+}
@@ -0,0 +1,178 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKVClient holds the Consul KV keys in memory
+type fakeKVClient struct {
+	values map[string]string
+	err    error
+}
+
+func (client *fakeKVClient) GetConfigurationValue(name string) ([]byte, error) {
+	if client.err != nil {
+		return nil, client.err
+	}
+
+	value, found := client.values[name]
+	if !found {
+		return nil, nil
+	}
+	return []byte(value), nil
+}
+
+func newTestKVReferenceWatcher(client *fakeKVClient) *kvReferenceWatcher {
+	return &kvReferenceWatcher{
+		newClient: func() (kvValueGetter, error) {
+			return client, nil
+		},
+	}
+}
+
+func TestKVReferenceResolve(t *testing.T) {
+	client := &fakeKVClient{
+		values: map[string]string{
+			"edgex/shared/endpointUrl": "https://cloud.example.com",
+			"edgex/shared/siteId":      "site-1",
+		},
+	}
+
+	tests := []struct {
+		Name        string
+		Parameters  map[string]string
+		Expected    map[string]string
+		ExpectError bool
+	}{
+		{"No references", map[string]string{"url": "http://localhost"}, map[string]string{"url": "http://localhost"}, false},
+		{"References",
+			map[string]string{"url": "consul://edgex/shared/endpointUrl", "site": "consul://edgex/shared/siteId", "method": "post"},
+			map[string]string{"url": "https://cloud.example.com", "site": "site-1", "method": "post"}, false},
+		{"Key not found", map[string]string{"url": "consul://edgex/shared/bogus"}, nil, true},
+		{"Missing key", map[string]string{"url": "consul://"}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			watcher := newTestKVReferenceWatcher(client)
+			original := make(map[string]string)
+			for name, value := range test.Parameters {
+				original[name] = value
+			}
+
+			actual, err := watcher.resolve(test.Parameters)
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+			assert.Equal(t, original, test.Parameters, "parameters must not be modified")
+		})
+	}
+}
+
+func TestKVReferenceResolveClientNotCreated(t *testing.T) {
+	watcher := kvReferenceWatcher{
+		newClient: func() (kvValueGetter, error) {
+			return nil, errors.New("no configuration provider")
+		},
+	}
+
+	_, err := watcher.resolve(map[string]string{"url": "http://localhost"})
+	require.NoError(t, err, "client must only be created when parameters reference keys")
+
+	_, err = watcher.resolve(map[string]string{"url": "consul://edgex/shared/endpointUrl"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no configuration provider")
+}
+
+func TestKVReferenceCheckForUpdates(t *testing.T) {
+	client := &fakeKVClient{
+		values: map[string]string{
+			"edgex/shared/endpointUrl": "https://cloud.example.com",
+		},
+	}
+
+	watcher := newTestKVReferenceWatcher(client)
+	_, err := watcher.resolve(map[string]string{"url": "consul://edgex/shared/endpointUrl"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"edgex/shared/endpointUrl"}, watcher.keys())
+
+	assert.False(t, watcher.checkForUpdates(lc))
+
+	client.err = errors.New("unavailable")
+	assert.False(t, watcher.checkForUpdates(lc))
+	client.err = nil
+
+	delete(client.values, "edgex/shared/endpointUrl")
+	assert.False(t, watcher.checkForUpdates(lc), "removed key must keep previous value")
+
+	client.values["edgex/shared/endpointUrl"] = "https://other.example.com"
+	assert.True(t, watcher.checkForUpdates(lc))
+
+	watcher.reset()
+	assert.Empty(t, watcher.keys())
+	assert.False(t, watcher.checkForUpdates(lc))
+}
+
+func TestLoadConfigurablePipelineKVReferences(t *testing.T) {
+	client := &fakeKVClient{
+		values: map[string]string{
+			"edgex/shared/endpointUrl": "https://cloud.example.com",
+			"edgex/shared/deviceNames": "Random-Float-Device",
+		},
+	}
+
+	functions := map[string]common.PipelineFunction{
+		"FilterByDeviceName": {Parameters: map[string]string{"DeviceNames": "consul://edgex/shared/deviceNames"}},
+		"HTTPExport":         {Parameters: map[string]string{ExportMethod: ExportMethodPost, MimeType: "application/json", Url: "consul://edgex/shared/endpointUrl"}},
+	}
+
+	sdk := Service{
+		lc:           lc,
+		kvReferences: *newTestKVReferenceWatcher(client),
+		config: &common.ConfigurationStruct{
+			Writable: common.WritableInfo{
+				Pipeline: common.PipelineInfo{
+					ExecutionOrder: "FilterByDeviceName, HTTPExport",
+					Functions:      functions,
+				},
+			},
+		},
+	}
+
+	appFunctions, err := sdk.LoadConfigurablePipeline()
+	require.NoError(t, err)
+	assert.Len(t, appFunctions, 2)
+	assert.Equal(t, []string{"edgex/shared/deviceNames", "edgex/shared/endpointUrl"}, sdk.kvReferences.keys())
+	assert.Equal(t, "consul://edgex/shared/endpointUrl", functions["HTTPExport"].Parameters[Url], "configuration must keep the reference")
+
+	delete(client.values, "edgex/shared/endpointUrl")
+	_, err = sdk.LoadConfigurablePipeline()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "function HTTPExport: unable to resolve 'url' parameter")
+}
@@ -0,0 +1,199 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+// secretParameters are the configurable function parameters whose values are secrets, so are redacted in snapshots
+var secretParameters = map[string]bool{
+	EncryptionKey: true,
+	InitVector:    true,
+	HashKey:       true,
+}
+
+// ExportPipelineSnapshot returns a portable document of the effective configurable functions pipeline, its
+// parameters and the trigger topics, with secret parameter values redacted.
+func (svc *Service) ExportPipelineSnapshot() interfaces.PipelineSnapshot {
+	pipeline := svc.config.Writable.Pipeline
+
+	functions := make(map[string]interfaces.PipelineSnapshotFunction, len(pipeline.Functions))
+	for name, function := range pipeline.Functions {
+		parameters := make(map[string]string, len(function.Parameters))
+		for key, value := range function.Parameters {
+			if secretParameters[strings.ToLower(key)] && len(value) > 0 {
+				value = interfaces.PipelineSnapshotRedacted
+			}
+			parameters[key] = value
+		}
+		functions[name] = interfaces.PipelineSnapshotFunction{Parameters: parameters, Disabled: function.Disabled}
+	}
+
+	return interfaces.PipelineSnapshot{
+		ServiceKey:               svc.serviceKey,
+		Created:                  time.Now().UnixNano(),
+		ExecutionOrder:           pipeline.ExecutionOrder,
+		UseTargetTypeOfByteArray: pipeline.UseTargetTypeOfByteArray,
+		Functions:                functions,
+		Topics:                   svc.triggerTopics(),
+	}
+}
+
+// ImportPipelineSnapshot validates the snapshot's functions pipeline and applies it to the running service, then
+// persists it to the Configuration Provider, if used. Changes to the trigger topics take effect when the service
+// is restarted. The service must be using the configurable functions pipeline.
+func (svc *Service) ImportPipelineSnapshot(snapshot interfaces.PipelineSnapshot) error {
+	if !svc.usingConfigurablePipeline {
+		return errors.New("pipeline snapshot can not be imported as the service is not using the configurable functions pipeline")
+	}
+
+	current := svc.config.Writable.Pipeline
+	imported, err := svc.pipelineFromSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+
+	// Loading the pipeline from the imported configuration validates it, so the current pipeline is restored if it fails
+	previousTargetType := svc.targetType
+	svc.config.Writable.Pipeline = imported
+	transforms, err := svc.LoadConfigurablePipeline()
+	if err != nil {
+		svc.config.Writable.Pipeline = current
+		svc.targetType = previousTargetType
+		return fmt.Errorf("invalid pipeline snapshot: %s", err.Error())
+	}
+
+	if err := svc.SetFunctionsPipeline(transforms...); err != nil {
+		svc.config.Writable.Pipeline = current
+		svc.targetType = previousTargetType
+		return fmt.Errorf("unable to set functions pipeline from snapshot: %s", err.Error())
+	}
+
+	if snapshot.Topics != svc.triggerTopics() {
+		svc.setTriggerTopics(snapshot.Topics)
+		svc.lc.Warn("Trigger topics imported from pipeline snapshot take effect when the service is restarted")
+	}
+
+	svc.lc.Infof("Functions pipeline imported from snapshot of service '%s'", snapshot.ServiceKey)
+
+	configClient := bootstrapContainer.ConfigClientFrom(svc.dic.Get)
+	if configClient == nil {
+		return nil
+	}
+
+	if err := configClient.PutConfiguration(*svc.config, true); err != nil {
+		return fmt.Errorf("pipeline snapshot applied but could not be persisted to the Configuration Provider: %s", err.Error())
+	}
+
+	return nil
+}
+
+// pipelineFromSnapshot returns the pipeline configuration with the snapshot's functions pipeline. Redacted secret
+// parameter values are replaced by their current values.
+func (svc *Service) pipelineFromSnapshot(snapshot interfaces.PipelineSnapshot) (common.PipelineInfo, error) {
+	current := svc.config.Writable.Pipeline
+	pipeline := current
+	pipeline.ExecutionOrder = snapshot.ExecutionOrder
+	pipeline.UseTargetTypeOfByteArray = snapshot.UseTargetTypeOfByteArray
+	pipeline.Functions = make(map[string]common.PipelineFunction, len(snapshot.Functions))
+
+	for name, function := range snapshot.Functions {
+		parameters := make(map[string]string, len(function.Parameters))
+		for key, value := range function.Parameters {
+			if value == interfaces.PipelineSnapshotRedacted {
+				currentValue, found := lookupParameter(current.Functions[name].Parameters, key)
+				if !found {
+					return common.PipelineInfo{}, fmt.Errorf("parameter '%s' of function '%s' is redacted in the snapshot and not configured for this service", key, name)
+				}
+				value = currentValue
+			}
+			parameters[key] = value
+		}
+		pipeline.Functions[name] = common.PipelineFunction{Parameters: parameters, Disabled: function.Disabled}
+	}
+
+	return pipeline, nil
+}
+
+// triggerTopics returns the topics subscribed and published to by the configured trigger
+func (svc *Service) triggerTopics() interfaces.PipelineSnapshotTopics {
+	trigger := svc.config.Trigger
+
+	switch strings.ToUpper(trigger.Type) {
+	case TriggerTypeMessageBus:
+		return interfaces.PipelineSnapshotTopics{
+			SubscribeTopics: trigger.EdgexMessageBus.SubscribeHost.SubscribeTopics,
+			PublishTopic:    trigger.EdgexMessageBus.PublishHost.PublishTopic,
+		}
+	case TriggerTypeMQTT:
+		return interfaces.PipelineSnapshotTopics{
+			SubscribeTopics: trigger.ExternalMqtt.SubscribeTopics,
+			PublishTopic:    trigger.ExternalMqtt.PublishTopic,
+		}
+	case TriggerTypeRedisPubSub:
+		return interfaces.PipelineSnapshotTopics{
+			SubscribeTopics: trigger.RedisPubSub.SubscribePatterns,
+			PublishTopic:    trigger.RedisPubSub.PublishChannel,
+		}
+	case TriggerTypeAMQP:
+		return interfaces.PipelineSnapshotTopics{
+			SubscribeTopics: trigger.AMQP.Queue,
+			PublishTopic:    trigger.AMQP.PublishRoutingKey,
+		}
+	}
+
+	return interfaces.PipelineSnapshotTopics{}
+}
+
+func (svc *Service) setTriggerTopics(topics interfaces.PipelineSnapshotTopics) {
+	trigger := &svc.config.Trigger
+
+	switch strings.ToUpper(trigger.Type) {
+	case TriggerTypeMessageBus:
+		trigger.EdgexMessageBus.SubscribeHost.SubscribeTopics = topics.SubscribeTopics
+		trigger.EdgexMessageBus.PublishHost.PublishTopic = topics.PublishTopic
+	case TriggerTypeMQTT:
+		trigger.ExternalMqtt.SubscribeTopics = topics.SubscribeTopics
+		trigger.ExternalMqtt.PublishTopic = topics.PublishTopic
+	case TriggerTypeRedisPubSub:
+		trigger.RedisPubSub.SubscribePatterns = topics.SubscribeTopics
+		trigger.RedisPubSub.PublishChannel = topics.PublishTopic
+	case TriggerTypeAMQP:
+		trigger.AMQP.Queue = topics.SubscribeTopics
+		trigger.AMQP.PublishRoutingKey = topics.PublishTopic
+	}
+}
+
+// lookupParameter returns the value of the parameter ignoring the casing of its name, since LoadConfigurablePipeline
+// lowercases the names of the parameters in the configuration
+func lookupParameter(parameters map[string]string, name string) (string, bool) {
+	for key, value := range parameters {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
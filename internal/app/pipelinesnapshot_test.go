@@ -0,0 +1,155 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSnapshotTestService(usingConfigurablePipeline bool) *Service {
+	return &Service{
+		lc:                        lc,
+		dic:                       dic,
+		serviceKey:                "app-source",
+		usingConfigurablePipeline: usingConfigurablePipeline,
+		config: &common.ConfigurationStruct{
+			Trigger: common.TriggerInfo{
+				Type: "edgex-messagebus",
+				EdgexMessageBus: common.MessageBusConfig{
+					SubscribeHost: common.SubscribeHostInfo{SubscribeTopics: "edgex/events/#"},
+					PublishHost:   common.PublishHostInfo{PublishTopic: "processed"},
+				},
+			},
+			Writable: common.WritableInfo{
+				Pipeline: common.PipelineInfo{
+					ExecutionOrder: "Transform, Encrypt",
+					Functions: map[string]common.PipelineFunction{
+						"Transform": {Parameters: map[string]string{TransformType: TransformJson}},
+						"Encrypt": {Parameters: map[string]string{
+							Algorithm:     EncryptAES,
+							EncryptionKey: "aquqweoruqwpeoruqwpoeruqwpoierupqoweiurpoqwiuerpqowieurqpowieurpoqiweuroipwqure",
+							InitVector:    "123456789012345678901234567890",
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestExportPipelineSnapshot(t *testing.T) {
+	svc := newSnapshotTestService(true)
+
+	snapshot := svc.ExportPipelineSnapshot()
+
+	assert.Equal(t, "app-source", snapshot.ServiceKey)
+	assert.NotZero(t, snapshot.Created)
+	assert.Equal(t, "Transform, Encrypt", snapshot.ExecutionOrder)
+	assert.Equal(t, map[string]string{TransformType: TransformJson}, snapshot.Functions["Transform"].Parameters)
+	assert.Equal(t, EncryptAES, snapshot.Functions["Encrypt"].Parameters[Algorithm])
+	assert.Equal(t, interfaces.PipelineSnapshotRedacted, snapshot.Functions["Encrypt"].Parameters[EncryptionKey])
+	assert.Equal(t, interfaces.PipelineSnapshotRedacted, snapshot.Functions["Encrypt"].Parameters[InitVector])
+	assert.Equal(t, interfaces.PipelineSnapshotTopics{SubscribeTopics: "edgex/events/#", PublishTopic: "processed"}, snapshot.Topics)
+
+	// Redacting must not modify the service's configuration
+	assert.NotEqual(t, interfaces.PipelineSnapshotRedacted, svc.config.Writable.Pipeline.Functions["Encrypt"].Parameters[EncryptionKey])
+}
+
+func TestImportPipelineSnapshot(t *testing.T) {
+	source := newSnapshotTestService(true)
+	snapshot := source.ExportPipelineSnapshot()
+	snapshot.ExecutionOrder = "FilterByDeviceName, Transform, Encrypt"
+	snapshot.Functions["FilterByDeviceName"] = interfaces.PipelineSnapshotFunction{
+		Parameters: map[string]string{"DeviceNames": "Random-Float-Device"},
+	}
+	snapshot.Topics.PublishTopic = "exported"
+
+	target := newSnapshotTestService(true)
+	expectedKey := target.config.Writable.Pipeline.Functions["Encrypt"].Parameters[EncryptionKey]
+
+	err := target.ImportPipelineSnapshot(snapshot)
+	require.NoError(t, err)
+
+	pipeline := target.config.Writable.Pipeline
+	assert.Equal(t, "FilterByDeviceName, Transform, Encrypt", pipeline.ExecutionOrder)
+	assert.Equal(t, expectedKey, pipeline.Functions["Encrypt"].Parameters[EncryptionKey], "redacted key must keep the current value")
+	assert.Len(t, target.transforms, 3)
+	assert.Equal(t, "exported", target.config.Trigger.EdgexMessageBus.PublishHost.PublishTopic)
+	assert.Equal(t, "edgex/events/#", target.config.Trigger.EdgexMessageBus.SubscribeHost.SubscribeTopics)
+}
+
+func TestPipelineSnapshotDisabledFunction(t *testing.T) {
+	source := newSnapshotTestService(true)
+	transform := source.config.Writable.Pipeline.Functions["Transform"]
+	transform.Disabled = true
+	source.config.Writable.Pipeline.Functions["Transform"] = transform
+
+	snapshot := source.ExportPipelineSnapshot()
+	assert.True(t, snapshot.Functions["Transform"].Disabled)
+	assert.False(t, snapshot.Functions["Encrypt"].Disabled)
+
+	target := newSnapshotTestService(true)
+	require.NoError(t, target.ImportPipelineSnapshot(snapshot))
+	assert.True(t, target.config.Writable.Pipeline.Functions["Transform"].Disabled)
+	assert.False(t, target.config.Writable.Pipeline.Functions["Encrypt"].Disabled)
+	assert.Len(t, target.transforms, 2)
+}
+
+func TestImportPipelineSnapshotErrors(t *testing.T) {
+	tests := []struct {
+		Name                      string
+		UsingConfigurablePipeline bool
+		Modify                    func(snapshot *interfaces.PipelineSnapshot)
+		ExpectedError             string
+	}{
+		{"Not using configurable pipeline", false, func(snapshot *interfaces.PipelineSnapshot) {},
+			"not using the configurable functions pipeline"},
+		{"Redacted parameter not configured", true, func(snapshot *interfaces.PipelineSnapshot) {
+			snapshot.Functions["Other"] = snapshot.Functions["Encrypt"]
+			snapshot.ExecutionOrder = "Other"
+		}, "of function 'Other' is redacted in the snapshot"},
+		{"Unknown function", true, func(snapshot *interfaces.PipelineSnapshot) {
+			snapshot.Functions["Bogus"] = interfaces.PipelineSnapshotFunction{}
+			snapshot.ExecutionOrder = "Transform, Bogus"
+		}, "invalid pipeline snapshot: function Bogus is not a built in SDK function"},
+		{"Invalid parameters", true, func(snapshot *interfaces.PipelineSnapshot) {
+			snapshot.Functions["Transform"] = interfaces.PipelineSnapshotFunction{Parameters: map[string]string{TransformType: "bogus"}}
+		}, "invalid pipeline snapshot: Transform from configuration failed"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			snapshot := newSnapshotTestService(true).ExportPipelineSnapshot()
+			testCase.Modify(&snapshot)
+
+			target := newSnapshotTestService(testCase.UsingConfigurablePipeline)
+			expected := target.config.Writable.Pipeline
+
+			err := target.ImportPipelineSnapshot(snapshot)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.ExpectedError)
+			assert.Equal(t, expected, target.config.Writable.Pipeline, "configuration must not change when import fails")
+			assert.Nil(t, target.transforms)
+		})
+	}
+}
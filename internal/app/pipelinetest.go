@@ -0,0 +1,364 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	commonConstants "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// runPipelineTests runs each test from the Writable.Pipeline.Tests configuration against the functions pipeline
+// and logs whether it passed or failed. An error is returned if no tests are configured or any test failed.
+func (svc *Service) runPipelineTests() error {
+	tests := svc.config.Writable.Pipeline.Tests
+	if len(tests) == 0 {
+		return errors.New("no pipeline tests found in Writable.Pipeline.Tests configuration")
+	}
+
+	if len(svc.transforms) == 0 {
+		return errors.New("no functions pipeline set to test")
+	}
+
+	testDic := svc.newPipelineTestDic()
+
+	names := make([]string, 0, len(tests))
+	for name := range tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	failed := 0
+	for _, name := range names {
+		if err := svc.runPipelineTest(testDic, tests[name]); err != nil {
+			failed++
+			svc.lc.Errorf("Pipeline test '%s' FAILED: %s", name, err.Error())
+			continue
+		}
+
+		svc.lc.Infof("Pipeline test '%s' PASSED", name)
+	}
+
+	svc.lc.Infof("Pipeline tests complete: %d passed, %d failed", len(names)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pipeline tests failed", failed, len(names))
+	}
+
+	return nil
+}
+
+// newPipelineTestDic returns the DIC of the runtime the pipeline is tested with, which contains only the configuration
+// so nothing is tracked in the service's telemetry
+func (svc *Service) newPipelineTestDic() *di.Container {
+	// Store and Forward is disabled for the tests so failed exports are never stored for later retry
+	testConfig := *svc.config
+	testConfig.Writable.StoreAndForward.Enabled = false
+	// Sampling is disabled so every test input is processed
+	testConfig.Writable.Pipeline.Sampling = common.SamplingInfo{}
+	return di.NewContainer(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &testConfig
+		},
+	})
+}
+
+func (svc *Service) runPipelineTest(testDic *di.Container, test common.PipelineTest) error {
+	var output interface{}
+	completed := false
+
+	// Appended to the pipeline to capture the data that reaches the end of it
+	capture := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		output = data
+		completed = true
+		return false, nil
+	}
+
+	transforms := make([]interfaces.AppFunction, len(svc.transforms), len(svc.transforms)+1)
+	copy(transforms, svc.transforms)
+	transforms = append(transforms, capture)
+
+	testRuntime := &runtime.GolangRuntime{
+		TargetType: svc.targetType,
+		ServiceKey: svc.serviceKey,
+	}
+	testRuntime.Initialize(testDic)
+	testRuntime.SetTransforms(transforms)
+
+	contentType := test.ContentType
+	if len(contentType) == 0 {
+		contentType = commonConstants.ContentTypeJSON
+	}
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: uuid.New().String(),
+		Payload:       []byte(test.Input),
+		ContentType:   contentType,
+	}
+
+	appContext := appfunction.NewContext(envelope.CorrelationID, svc.dic, contentType)
+	messageError := testRuntime.ProcessMessage(appContext, envelope)
+
+	if len(test.ExpectedError) > 0 {
+		if messageError == nil {
+			return fmt.Errorf("expected error containing '%s' but pipeline did not fail", test.ExpectedError)
+		}
+		if !strings.Contains(messageError.Err.Error(), test.ExpectedError) {
+			return fmt.Errorf("expected error containing '%s' but got: %s", test.ExpectedError, messageError.Err.Error())
+		}
+		return nil
+	}
+
+	if messageError != nil {
+		return fmt.Errorf("pipeline failed: %s", messageError.Err.Error())
+	}
+
+	if test.ExpectFiltered {
+		if completed {
+			return errors.New("expected pipeline to stop before the end but it completed")
+		}
+		return nil
+	}
+
+	if !completed {
+		return errors.New("pipeline stopped before the end")
+	}
+
+	if len(test.ExpectedOutput) == 0 {
+		return nil
+	}
+
+	actual, err := util.CoerceType(output)
+	if err != nil {
+		return fmt.Errorf("unable to compare pipeline output: %s", err.Error())
+	}
+
+	if !outputMatches([]byte(test.ExpectedOutput), actual) {
+		return fmt.Errorf("expected output '%s' but got '%s'", test.ExpectedOutput, string(actual))
+	}
+
+	return nil
+}
+
+// outputMatches compares JSON by value and anything else as text ignoring surrounding whitespace
+func outputMatches(expected []byte, actual []byte) bool {
+	var expectedValue interface{}
+	var actualValue interface{}
+	if json.Unmarshal(expected, &expectedValue) == nil && json.Unmarshal(actual, &actualValue) == nil {
+		return reflect.DeepEqual(expectedValue, actualValue)
+	}
+
+	return bytes.Equal(bytes.TrimSpace(expected), bytes.TrimSpace(actual))
+}
+
+// exportFunctions are the built in functions which send data out of the service, so aren't executed when a pipeline
+// is tested through TestPipeline
+var exportFunctions = []string{
+	"HTTPExport",
+	"MQTTExport",
+	"AMQPExport",
+	"EmailExport",
+	"FileExport",
+	"PushToCore",
+	"ForwardToEKuiper",
+	"SparkplugBExport",
+	"NGSILDExport",
+	"V1Export",
+	"SensorThingsExport",
+	"ElasticsearchExport",
+	"WebSocketExport",
+	"StreamExport",
+	"SendNotification",
+}
+
+// TestPipeline runs the test's payload through the configurable functions pipeline, or the test's pipeline when
+// specified, and returns the output of each function. The pipeline is built separately from the service's pipeline
+// and run the same way as the pipeline tests, so it isn't applied, and the export functions pass the data through
+// rather than sending it.
+func (svc *Service) TestPipeline(test interfaces.PipelineTest) (interfaces.PipelineTestResult, error) {
+	if !svc.usingConfigurablePipeline {
+		return interfaces.PipelineTestResult{}, errors.New("pipeline can not be tested as the service is not using the configurable functions pipeline")
+	}
+
+	pipelineConfig := svc.config.Writable.Pipeline
+	if test.Pipeline != nil {
+		var err error
+		if pipelineConfig, err = svc.pipelineFromSnapshot(*test.Pipeline); err != nil {
+			return interfaces.PipelineTestResult{}, err
+		}
+	}
+
+	payload, err := decodePipelineTestPayload(test)
+	if err != nil {
+		return interfaces.PipelineTestResult{}, err
+	}
+
+	// A separate watcher so the Consul keys referenced by the tested pipeline aren't watched
+	watcher := &kvReferenceWatcher{newClient: svc.kvReferences.newClient}
+	if watcher.newClient == nil {
+		watcher.newClient = svc.newKVReferenceClient
+	}
+
+	transforms, err := svc.buildConfigurablePipeline(pipelineConfig, NewConfigurable(svc.lc), watcher)
+	if err != nil {
+		return interfaces.PipelineTestResult{}, fmt.Errorf("invalid pipeline: %s", err.Error())
+	}
+
+	executionOrder := util.DeleteEmptyAndTrim(strings.FieldsFunc(pipelineConfig.ExecutionOrder, util.SplitComma))
+	mocked := make([]bool, len(transforms))
+	for index, name := range executionOrder {
+		if isExportFunction(name) && !pipelineConfig.Functions[name].Disabled {
+			transforms[index] = mockExportFunction
+			mocked[index] = true
+		}
+	}
+
+	steps := make([]interfaces.PipelineTestStep, 0, len(transforms))
+	recordStep := func(_ interfaces.AppFunctionContext, execution interfaces.FunctionExecution) error {
+		if execution.Completed {
+			steps = append(steps, newPipelineTestStep(executionOrder[execution.Position], mocked[execution.Position], execution))
+		}
+		return nil
+	}
+
+	testRuntime := &runtime.GolangRuntime{
+		ServiceKey:   svc.serviceKey,
+		Interceptors: []interfaces.PipelineInterceptor{recordStep},
+	}
+	if pipelineConfig.UseTargetTypeOfByteArray {
+		testRuntime.TargetType = &[]byte{}
+	}
+	testRuntime.Initialize(svc.newPipelineTestDic())
+	testRuntime.SetTransforms(transforms)
+
+	contentType := test.ContentType
+	if len(contentType) == 0 {
+		contentType = commonConstants.ContentTypeJSON
+	}
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: uuid.New().String(),
+		Payload:       payload,
+		ContentType:   contentType,
+	}
+
+	appContext := appfunction.NewContext(envelope.CorrelationID, svc.dic, contentType)
+	messageError := testRuntime.ProcessMessage(appContext, envelope)
+	if messageError != nil && len(steps) == 0 {
+		// The payload wasn't passed to the pipeline, i.e. it isn't a valid Event
+		return interfaces.PipelineTestResult{}, messageError.Err
+	}
+
+	return interfaces.PipelineTestResult{
+		Completed: len(steps) == len(transforms) && len(steps[len(steps)-1].Error) == 0 && len(steps[len(steps)-1].Filtered) == 0,
+		Steps:     steps,
+	}, nil
+}
+
+// decodePipelineTestPayload returns the test's payload decoded as per its encoding
+func decodePipelineTestPayload(test interfaces.PipelineTest) ([]byte, error) {
+	switch test.Encoding {
+	case "", interfaces.PipelineTestEncodingText:
+		return []byte(test.Payload), nil
+	case interfaces.PipelineTestEncodingBase64:
+		payload, err := base64.StdEncoding.DecodeString(test.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode base64 payload: %s", err.Error())
+		}
+		return payload, nil
+	default:
+		return nil, fmt.Errorf("unsupported payload encoding '%s'", test.Encoding)
+	}
+}
+
+// isExportFunction determines if the pipeline function name starts with the name of one of the export functions, as
+// the built in functions are matched by LoadConfigurablePipeline
+func isExportFunction(name string) bool {
+	for _, function := range exportFunctions {
+		if strings.HasPrefix(name, function) {
+			return true
+		}
+	}
+	return false
+}
+
+// mockExportFunction takes the place of an export function when a pipeline is tested, passing the data it receives
+// through rather than sending it
+func mockExportFunction(_ interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	return true, data
+}
+
+// newPipelineTestStep returns the result of the function's execution, with its output serialized
+func newPipelineTestStep(function string, mocked bool, execution interfaces.FunctionExecution) interfaces.PipelineTestStep {
+	step := interfaces.PipelineTestStep{
+		Function:  function,
+		Mocked:    mocked,
+		Continued: execution.ContinuePipeline,
+	}
+
+	if execution.Filtered {
+		step.Filtered = execution.FilteredReason
+		if len(step.Filtered) == 0 {
+			step.Filtered = "filtered out"
+		}
+		return step
+	}
+
+	if err, ok := execution.Result.(error); ok && !execution.ContinuePipeline {
+		step.Error = err.Error()
+		return step
+	}
+
+	if execution.Result == nil {
+		return step
+	}
+
+	output, err := util.CoerceType(execution.Result)
+	if err != nil {
+		step.Error = fmt.Sprintf("unable to serialize output: %s", err.Error())
+		return step
+	}
+
+	if utf8.Valid(output) {
+		step.Encoding = interfaces.PipelineTestEncodingText
+		step.Output = string(output)
+	} else {
+		step.Encoding = interfaces.PipelineTestEncodingBase64
+		step.Output = base64.StdEncoding.EncodeToString(output)
+	}
+
+	return step
+}
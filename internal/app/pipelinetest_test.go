@@ -0,0 +1,266 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	contracts "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pipelineTestService(t *testing.T, tests map[string]common.PipelineTest) *Service {
+	sdk := &Service{
+		dic: dic,
+		lc:  lc,
+		config: &common.ConfigurationStruct{
+			Writable: common.WritableInfo{
+				Pipeline: common.PipelineInfo{
+					ExecutionOrder: "FilterByDeviceName",
+					Functions: map[string]common.PipelineFunction{
+						"FilterByDeviceName": {Parameters: map[string]string{DeviceNames: "Random-Float-Device"}},
+					},
+					Tests: tests,
+				},
+			},
+		},
+	}
+
+	transforms, err := sdk.LoadConfigurablePipeline()
+	require.NoError(t, err)
+	require.NoError(t, sdk.SetFunctionsPipeline(transforms...))
+
+	return sdk
+}
+
+func pipelineTestEvent(t *testing.T, deviceName string) string {
+	event := dtos.NewEvent("profile", deviceName, "source")
+	event.Id = "7a1707f0-166f-4c4b-bc9d-1d54c74e0137"
+	event.Origin = 1
+	require.NoError(t, event.AddSimpleReading("temperature", contracts.ValueTypeInt64, int64(38)))
+	event.Readings[0].Id = "4b9a8ef3-fd3c-4a63-89d5-4b8a83a0a4a1"
+	event.Readings[0].Origin = 1
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRunPipelineTest(t *testing.T) {
+	matchingEvent := pipelineTestEvent(t, "Random-Float-Device")
+	otherEvent := pipelineTestEvent(t, "Random-Integer-Device")
+
+	tests := []struct {
+		Name          string
+		Test          common.PipelineTest
+		ErrorContains string
+	}{
+		{"Output matches", common.PipelineTest{Input: matchingEvent, ExpectedOutput: matchingEvent}, ""},
+		{"Completes without output check", common.PipelineTest{Input: matchingEvent}, ""},
+		{"Filtered", common.PipelineTest{Input: otherEvent, ExpectFiltered: true}, ""},
+		{"Expected error", common.PipelineTest{Input: "bogus", ExpectedError: "unable to process payload"}, ""},
+		{"Output mismatch", common.PipelineTest{Input: matchingEvent, ExpectedOutput: otherEvent}, "expected output"},
+		{"Unexpectedly filtered", common.PipelineTest{Input: otherEvent, ExpectedOutput: otherEvent}, "stopped before the end"},
+		{"Unexpectedly completed", common.PipelineTest{Input: matchingEvent, ExpectFiltered: true}, "but it completed"},
+		{"Unexpected error", common.PipelineTest{Input: "bogus"}, "pipeline failed"},
+		{"Missing error", common.PipelineTest{Input: matchingEvent, ExpectedError: "boom"}, "did not fail"},
+		{"Wrong error", common.PipelineTest{Input: "bogus", ExpectedError: "boom"}, "but got"},
+	}
+
+	sdk := pipelineTestService(t, nil)
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := sdk.runPipelineTest(sdk.dic, test.Test)
+			if len(test.ErrorContains) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ErrorContains)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRunPipelineTests(t *testing.T) {
+	matchingEvent := pipelineTestEvent(t, "Random-Float-Device")
+
+	sdk := pipelineTestService(t, nil)
+	err := sdk.runPipelineTests()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no pipeline tests")
+
+	sdk = pipelineTestService(t, map[string]common.PipelineTest{
+		"pass": {Input: matchingEvent, ExpectedOutput: matchingEvent},
+	})
+	require.NoError(t, sdk.runPipelineTests())
+
+	sdk = pipelineTestService(t, map[string]common.PipelineTest{
+		"pass": {Input: matchingEvent, ExpectedOutput: matchingEvent},
+		"fail": {Input: matchingEvent, ExpectFiltered: true},
+	})
+	err = sdk.runPipelineTests()
+	require.Error(t, err)
+	assert.Equal(t, "1 of 2 pipeline tests failed", err.Error())
+}
+
+func TestOutputMatches(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Expected string
+		Actual   string
+		Match    bool
+	}{
+		{"JSON reordered", `{"a":1,"b":[1,2]}`, `{ "b": [1, 2], "a": 1 }`, true},
+		{"JSON different", `{"a":1}`, `{"a":2}`, false},
+		{"Text", "hello", "hello\n", true},
+		{"Text different", "hello", "world", false},
+		{"JSON and text", `{"a":1}`, "a=1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Match, outputMatches([]byte(test.Expected), []byte(test.Actual)))
+		})
+	}
+}
+
+func TestTestPipeline(t *testing.T) {
+	matchingEvent := pipelineTestEvent(t, "Random-Float-Device")
+	otherEvent := pipelineTestEvent(t, "Random-Integer-Device")
+
+	exported := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		exported++
+	}))
+	defer server.Close()
+
+	changedPipeline := &interfaces.PipelineSnapshot{
+		ExecutionOrder: "FilterByDeviceName, Transform, HTTPExport",
+		Functions: map[string]interfaces.PipelineSnapshotFunction{
+			"FilterByDeviceName": {Parameters: map[string]string{DeviceNames: "Random-Float-Device"}},
+			"Transform":          {Parameters: map[string]string{TransformType: TransformXml}},
+			"HTTPExport":         {Parameters: map[string]string{Url: server.URL, ExportMethod: ExportMethodPost, MimeType: "application/xml"}},
+		},
+	}
+
+	tests := []struct {
+		Name              string
+		Test              interfaces.PipelineTest
+		ExpectedCompleted bool
+		ExpectedContinued bool
+		ExpectedSteps     int
+		ErrorContains     string
+	}{
+		{"Current pipeline", interfaces.PipelineTest{Payload: matchingEvent}, true, true, 1, ""},
+		// Data marked as filtered isn't completed, even by the last function
+		{"Filtered by last function", interfaces.PipelineTest{Payload: otherEvent}, false, false, 1, ""},
+		{"Changed pipeline", interfaces.PipelineTest{Pipeline: changedPipeline, Payload: matchingEvent}, true, true, 3, ""},
+		{"Changed pipeline filtered", interfaces.PipelineTest{Pipeline: changedPipeline, Payload: otherEvent}, false, false, 1, ""},
+		{"Base64 payload", interfaces.PipelineTest{Encoding: interfaces.PipelineTestEncodingBase64, Payload: base64.StdEncoding.EncodeToString([]byte(matchingEvent))}, true, true, 1, ""},
+		{"Invalid base64 payload", interfaces.PipelineTest{Encoding: interfaces.PipelineTestEncodingBase64, Payload: "!"}, false, false, 0, "unable to decode base64"},
+		{"Unsupported encoding", interfaces.PipelineTest{Encoding: "hex", Payload: matchingEvent}, false, false, 0, "unsupported payload encoding"},
+		{"Invalid payload", interfaces.PipelineTest{Payload: "bogus"}, false, false, 0, "unable to process payload"},
+		{"Invalid pipeline", interfaces.PipelineTest{Pipeline: &interfaces.PipelineSnapshot{ExecutionOrder: "Bogus"}, Payload: matchingEvent}, false, false, 0, "invalid pipeline"},
+	}
+
+	sdk := pipelineTestService(t, nil)
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			result, err := sdk.TestPipeline(test.Test)
+			if len(test.ErrorContains) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ErrorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedCompleted, result.Completed)
+			require.Len(t, result.Steps, test.ExpectedSteps)
+			assert.Equal(t, "FilterByDeviceName", result.Steps[0].Function)
+			assert.Equal(t, test.ExpectedContinued, result.Steps[0].Continued)
+			assert.Equal(t, !test.ExpectedContinued, len(result.Steps[0].Filtered) > 0)
+		})
+	}
+
+	assert.Zero(t, exported, "export must not send data")
+	assert.Equal(t, "FilterByDeviceName", sdk.config.Writable.Pipeline.ExecutionOrder, "pipeline must not be applied")
+	assert.Len(t, sdk.transforms, 1)
+}
+
+func TestTestPipelineSteps(t *testing.T) {
+	sdk := pipelineTestService(t, nil)
+
+	result, err := sdk.TestPipeline(interfaces.PipelineTest{
+		Pipeline: &interfaces.PipelineSnapshot{
+			ExecutionOrder: "Transform, MQTTExport",
+			Functions: map[string]interfaces.PipelineSnapshotFunction{
+				"Transform": {Parameters: map[string]string{TransformType: TransformXml}},
+				"MQTTExport": {Parameters: map[string]string{
+					BrokerAddress: "tcp://localhost:1",
+					Topic:         "events",
+					SecretPath:    "",
+					AuthMode:      "none",
+					ClientID:      "test",
+				}},
+			},
+		},
+		Payload: pipelineTestEvent(t, "Random-Float-Device"),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Steps, 2)
+	assert.True(t, result.Completed)
+
+	transform := result.Steps[0]
+	assert.False(t, transform.Mocked)
+	assert.Equal(t, interfaces.PipelineTestEncodingText, transform.Encoding)
+	assert.Contains(t, transform.Output, "<Event>")
+
+	export := result.Steps[1]
+	assert.Equal(t, "MQTTExport", export.Function)
+	assert.True(t, export.Mocked)
+	assert.Equal(t, transform.Output, export.Output)
+}
+
+func TestTestPipelineNotConfigurable(t *testing.T) {
+	sdk := pipelineTestService(t, nil)
+	sdk.usingConfigurablePipeline = false
+
+	_, err := sdk.TestPipeline(interfaces.PipelineTest{Payload: pipelineTestEvent(t, "Random-Float-Device")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not using the configurable functions pipeline")
+}
+
+func TestIsExportFunction(t *testing.T) {
+	assert.True(t, isExportFunction("HTTPExport"))
+	assert.True(t, isExportFunction("HTTPExportBackup"))
+	assert.True(t, isExportFunction("PushToCore"))
+	assert.False(t, isExportFunction("Transform"))
+	assert.False(t, isExportFunction("Batch"))
+}
@@ -0,0 +1,158 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	bootstrapInterfaces "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+)
+
+// secretWatchInterval is how often the Secret Provider is checked for updated secrets
+var secretWatchInterval = 5 * time.Second
+
+// secretWatcher notifies the registered callbacks when the secret data at their path changes
+type secretWatcher struct {
+	mutex        sync.Mutex
+	callbacks    map[string][]func(path string)
+	fingerprints map[string][32]byte
+	lastUpdated  time.Time
+}
+
+// RegisterSecretUpdatedCallback registers a callback which is called when the secret data at the specified path
+// is updated, either via the /secret endpoint, InsecureSecrets configuration changes or the Secret Store.
+func (svc *Service) RegisterSecretUpdatedCallback(path string, callback func(path string)) error {
+	if callback == nil {
+		return errors.New("secret updated callback must not be nil")
+	}
+
+	svc.secretWatcher.register(svc.lc, svc.secretProvider(), path, callback)
+	return nil
+}
+
+func (svc *Service) secretProvider() bootstrapInterfaces.SecretProvider {
+	return bootstrapContainer.SecretProviderFrom(svc.dic.Get)
+}
+
+func (watcher *secretWatcher) register(lc logger.LoggingClient, provider bootstrapInterfaces.SecretProvider, path string, callback func(path string)) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	if watcher.callbacks == nil {
+		watcher.callbacks = make(map[string][]func(path string))
+		watcher.fingerprints = make(map[string][32]byte)
+		watcher.lastUpdated = provider.SecretsLastUpdated()
+	}
+
+	if _, exists := watcher.callbacks[path]; !exists {
+		// Secret may not exist yet, in which case the callback is called once it is added
+		if secretData, err := provider.GetSecret(path); err == nil {
+			watcher.fingerprints[path] = secretFingerprint(secretData)
+		}
+	}
+
+	watcher.callbacks[path] = append(watcher.callbacks[path], callback)
+	lc.Debugf("Registered callback for updates to secrets at path '%s'", path)
+}
+
+// start runs the loop checking for updated secrets until the context is cancelled
+func (watcher *secretWatcher) start(appWg *sync.WaitGroup, appCtx context.Context, lc logger.LoggingClient, provider bootstrapInterfaces.SecretProvider) {
+	appWg.Add(1)
+
+	go func() {
+		defer appWg.Done()
+
+		lc.Info("Starting secret updates watcher")
+
+		for {
+			select {
+			case <-appCtx.Done():
+				lc.Info("Exiting secret updates watcher")
+				return
+
+			case <-time.After(secretWatchInterval):
+				watcher.checkForUpdates(lc, provider)
+			}
+		}
+	}()
+}
+
+// checkForUpdates calls the callbacks for the paths whose secret data has changed since the last check
+func (watcher *secretWatcher) checkForUpdates(lc logger.LoggingClient, provider bootstrapInterfaces.SecretProvider) {
+	watcher.mutex.Lock()
+
+	lastUpdated := provider.SecretsLastUpdated()
+	if !watcher.lastUpdated.Before(lastUpdated) {
+		watcher.mutex.Unlock()
+		return
+	}
+	watcher.lastUpdated = lastUpdated
+
+	var updated []func()
+	for path, callbacks := range watcher.callbacks {
+		secretData, err := provider.GetSecret(path)
+		if err != nil {
+			lc.Debugf("Unable to retrieve secrets at path '%s' to check for updates: %s", path, err.Error())
+			continue
+		}
+
+		fingerprint := secretFingerprint(secretData)
+		if previous, found := watcher.fingerprints[path]; found && previous == fingerprint {
+			continue
+		}
+		watcher.fingerprints[path] = fingerprint
+
+		lc.Infof("Secrets at path '%s' have been updated. Notifying %d callbacks", path, len(callbacks))
+		for _, callback := range callbacks {
+			callback := callback
+			path := path
+			updated = append(updated, func() { callback(path) })
+		}
+	}
+
+	// Callbacks are called without the lock held so they are free to register more callbacks
+	watcher.mutex.Unlock()
+	for _, notify := range updated {
+		notify()
+	}
+}
+
+// secretFingerprint returns a hash of the secret data so the secret values aren't kept in memory by the watcher
+func secretFingerprint(secretData map[string]string) [32]byte {
+	keys := make([]string, 0, len(secretData))
+	for key := range secretData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(hash, "%d:%s%d:%s", len(key), key, len(secretData[key]), secretData[key])
+	}
+
+	var fingerprint [32]byte
+	copy(fingerprint[:], hash.Sum(nil))
+	return fingerprint
+}
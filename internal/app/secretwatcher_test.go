@@ -0,0 +1,107 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	bootstrapInterfaces "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretProvider stores secrets in memory, updating the last updated time when secrets are stored
+type fakeSecretProvider struct {
+	bootstrapInterfaces.SecretProvider
+	secrets     map[string]map[string]string
+	lastUpdated time.Time
+}
+
+func (provider *fakeSecretProvider) GetSecret(path string, _ ...string) (map[string]string, error) {
+	secretData, found := provider.secrets[path]
+	if !found {
+		return nil, errors.New("not found")
+	}
+	return secretData, nil
+}
+
+func (provider *fakeSecretProvider) StoreSecret(path string, secretData map[string]string) error {
+	provider.secrets[path] = secretData
+	provider.lastUpdated = provider.lastUpdated.Add(time.Second)
+	return nil
+}
+
+func (provider *fakeSecretProvider) SecretsLastUpdated() time.Time {
+	return provider.lastUpdated
+}
+
+func TestSecretWatcher(t *testing.T) {
+	provider := &fakeSecretProvider{
+		secrets: map[string]map[string]string{
+			"mqtt": {"username": "user", "password": "one"},
+			"http": {"header": "Bearer one"},
+		},
+		lastUpdated: time.Now(),
+	}
+
+	var notified []string
+	callback := func(path string) {
+		notified = append(notified, path)
+	}
+
+	watcher := secretWatcher{}
+	watcher.register(lc, provider, "mqtt", callback)
+	watcher.register(lc, provider, "http", callback)
+	watcher.register(lc, provider, "aes", callback)
+
+	// No updates yet
+	watcher.checkForUpdates(lc, provider)
+	assert.Empty(t, notified)
+
+	require.NoError(t, provider.StoreSecret("mqtt", map[string]string{"username": "user", "password": "two"}))
+	watcher.checkForUpdates(lc, provider)
+	assert.Equal(t, []string{"mqtt"}, notified)
+
+	// Same values stored again are not an update
+	notified = nil
+	require.NoError(t, provider.StoreSecret("http", map[string]string{"header": "Bearer one"}))
+	watcher.checkForUpdates(lc, provider)
+	assert.Empty(t, notified)
+
+	// Secret that didn't exist when registered
+	require.NoError(t, provider.StoreSecret("aes", map[string]string{"key": "abc"}))
+	watcher.checkForUpdates(lc, provider)
+	assert.Equal(t, []string{"aes"}, notified)
+}
+
+func TestSecretFingerprint(t *testing.T) {
+	assert.Equal(t,
+		secretFingerprint(map[string]string{"a": "1", "b": "2"}),
+		secretFingerprint(map[string]string{"b": "2", "a": "1"}))
+	assert.NotEqual(t,
+		secretFingerprint(map[string]string{"ab": "c"}),
+		secretFingerprint(map[string]string{"a": "bc"}))
+}
+
+func TestRegisterSecretUpdatedCallbackNil(t *testing.T) {
+	sdk := Service{lc: lc, dic: dic}
+	err := sdk.RegisterSecretUpdatedCallback("mqtt", nil)
+	require.Error(t, err)
+}
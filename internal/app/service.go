@@ -33,7 +33,9 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/handlers"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/faultinjection"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/webserver"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
@@ -78,22 +80,33 @@ type Service struct {
 	config                    *common.ConfigurationStruct
 	lc                        logger.LoggingClient
 	transforms                []interfaces.AppFunction
+	offlineTransforms         []interfaces.AppFunction
 	usingConfigurablePipeline bool
 	runtime                   *runtime.GolangRuntime
 	webserver                 *webserver.WebServer
 	ctx                       contextGroup
 	deferredFunctions         []bootstrap.Deferred
 	backgroundPublishChannel  <-chan interfaces.BackgroundMessage
+	backgroundPublishers      *backgroundPublishers
 	customTriggerFactories    map[string]func(sdk *Service) (interfaces.Trigger, error)
 	profileSuffixPlaceholder  string
 	commandLine               commandLineFlags
 	flags                     *flags.Default
 	configProcessor           *config.Processor
+	secretWatcher             secretWatcher
+	kvReferences              kvReferenceWatcher
+	interceptors              []interfaces.PipelineInterceptor
+	flushMutex                sync.Mutex
+	flushers                  []interfaces.Flusher
+	configurableFlushers      []interfaces.Flusher
+	namedFlushers             map[string]interfaces.Flusher
+	configurableNamedFlushers map[string]interfaces.Flusher
 }
 
 type commandLineFlags struct {
 	skipVersionCheck   bool
 	serviceKeyOverride string
+	testPipelines      bool
 }
 
 type contextGroup struct {
@@ -132,15 +145,48 @@ func (svc *Service) AddBackgroundPublisher(capacity int) (interfaces.BackgroundP
 // consumed by the MessageBus output and return a publisher that writes to it on a different
 // topic than configured for messagebus output.
 func (svc *Service) AddBackgroundPublisherWithTopic(capacity int, topic string) (interfaces.BackgroundPublisher, error) {
+	return svc.addBackgroundPublisher("", interfaces.BackgroundPublisherOptions{Capacity: capacity, Topic: topic})
+}
+
+// AddNamedBackgroundPublisher will create a BackgroundPublisher with its own channel of the capacity in the options
+// to be consumed by the MessageBus output. The publisher can later be retrieved by its name.
+func (svc *Service) AddNamedBackgroundPublisher(name string, options interfaces.BackgroundPublisherOptions) (interfaces.BackgroundPublisher, error) {
+	if len(strings.TrimSpace(name)) == 0 {
+		return nil, errors.New("background publisher name must not be empty")
+	}
+
+	if len(options.Topic) == 0 {
+		options.Topic = svc.config.Trigger.EdgexMessageBus.PublishHost.PublishTopic
+		if len(options.Topic) == 0 {
+			return nil, errors.New("no publish topic configured for messagebus or background publisher")
+		}
+	}
+
+	return svc.addBackgroundPublisher(name, options)
+}
+
+// NamedBackgroundPublisher returns the BackgroundPublisher added with the specified name, if any
+func (svc *Service) NamedBackgroundPublisher(name string) (interfaces.BackgroundPublisher, bool) {
+	if svc.backgroundPublishers == nil {
+		return nil, false
+	}
+
+	return svc.backgroundPublishers.get(name)
+}
+
+func (svc *Service) addBackgroundPublisher(name string, options interfaces.BackgroundPublisherOptions) (interfaces.BackgroundPublisher, error) {
 	// for custom triggers we don't know if background publishing available or not
 	// but probably makes sense to trust the caller.
 	if svc.config.Trigger.Type == TriggerTypeHTTP || svc.config.Trigger.Type == TriggerTypeMQTT {
 		return nil, fmt.Errorf("Background publishing not supported for %s trigger.", svc.config.Trigger.Type)
 	}
 
-	bgchan, pub := newBackgroundPublisher(topic, capacity)
-	svc.backgroundPublishChannel = bgchan
-	return pub, nil
+	if svc.backgroundPublishers == nil {
+		svc.backgroundPublishers = newBackgroundPublishers(svc.ctx.appCtx)
+		svc.backgroundPublishChannel = svc.backgroundPublishers.output
+	}
+
+	return svc.backgroundPublishers.add(name, options)
 }
 
 // MakeItStop will force the service loop to exit in the same fashion as SIGINT/SIGTERM received from the OS
@@ -161,12 +207,46 @@ func (svc *Service) MakeItRun() error {
 	svc.ctx.stop = stop
 
 	svc.runtime = &runtime.GolangRuntime{
-		TargetType: svc.targetType,
-		ServiceKey: svc.serviceKey,
+		TargetType:          svc.targetType,
+		ServiceKey:          svc.serviceKey,
+		MaxWorkers:          svc.config.Trigger.Concurrency.MaxWorkers,
+		PreserveDeviceOrder: svc.config.Trigger.Concurrency.PreserveDeviceOrder,
+		HighPriorityTopics:  util.DeleteEmptyAndTrim(strings.FieldsFunc(svc.config.Trigger.Concurrency.HighPriorityTopics, util.SplitComma)),
+		LowPriorityTopics:   util.DeleteEmptyAndTrim(strings.FieldsFunc(svc.config.Trigger.Concurrency.LowPriorityTopics, util.SplitComma)),
+		Interceptors:        svc.interceptors,
+	}
+
+	if svc.config.FaultInjection.Enabled {
+		injector, err := faultinjection.NewInjector(svc.config.FaultInjection)
+		if err != nil {
+			return err
+		}
+
+		svc.lc.Warnf("FaultInjection enabled. Failures and latency will be injected into pipeline functions %v. Must not be used in production.",
+			injector.Functions())
+		interceptors := append([]interfaces.PipelineInterceptor{}, svc.interceptors...)
+		svc.runtime.Interceptors = append(interceptors, injector.Interceptor())
 	}
 
 	svc.runtime.Initialize(svc.dic)
 	svc.runtime.SetTransforms(svc.transforms)
+	svc.runtime.SetOfflineTransforms(svc.offlineTransforms)
+	svc.runtime.StartWorkers(svc.ctx.appWg, svc.ctx.appCtx)
+
+	if svc.commandLine.testPipelines {
+		err := svc.runPipelineTests()
+		svc.ctx.stop = nil
+		svc.ctx.appCancelCtx()
+		svc.ctx.appWg.Wait()
+		for _, deferredFunc := range svc.deferredFunctions {
+			deferredFunc()
+		}
+		return err
+	}
+
+	if svc.config.PipelineStatistics.Persist {
+		svc.startPipelineStatsPersistence()
+	}
 
 	// determine input type and create trigger for it
 	t := svc.setupTrigger(svc.config, svc.runtime)
@@ -190,6 +270,14 @@ func (svc *Service) MakeItRun() error {
 		svc.lc.Info("StoreAndForward disabled. Not running retry loop.")
 	}
 
+	if svc.secretWatcher.callbacks != nil {
+		svc.secretWatcher.start(svc.ctx.appWg, svc.ctx.appCtx, svc.lc, svc.secretProvider())
+	}
+
+	if svc.usingConfigurablePipeline && len(svc.kvReferences.keys()) > 0 {
+		svc.kvReferences.start(svc.ctx.appWg, svc.ctx.appCtx, svc.lc, NewConfigUpdateProcessor(svc).processConfigChangedPipeline)
+	}
+
 	svc.lc.Info(svc.config.Service.StartupMsg)
 
 	signals := make(chan os.Signal)
@@ -214,6 +302,9 @@ func (svc *Service) MakeItRun() error {
 
 	svc.ctx.stop = nil
 
+	// Pass on the accumulated data, such as batches, rather than dropping it
+	svc.FlushPipelineData()
+
 	if svc.config.Writable.StoreAndForward.Enabled {
 		svc.ctx.storeForwardCancelCtx()
 		svc.ctx.storeForwardWg.Wait()
@@ -230,10 +321,25 @@ func (svc *Service) MakeItRun() error {
 	return err
 }
 
+// startPipelineStatsPersistence restores the pipeline statistics saved by the previous runs of the service, before any
+// messages are processed, and then saves them periodically
+func (svc *Service) startPipelineStatsPersistence() {
+	tracker := container.PipelineStatsTrackerFrom(svc.dic.Get)
+	storeClient := container.StoreClientFrom(svc.dic.Get)
+	if tracker == nil || storeClient == nil {
+		return
+	}
+
+	if err := tracker.Load(storeClient, svc.serviceKey); err != nil {
+		svc.lc.Errorf("Unable to restore the pipeline statistics, so the totals restart from zero: %s", err.Error())
+	}
+
+	svc.ctx.appWg.Add(1)
+	go tracker.StartPersisting(svc.ctx.appWg, svc.ctx.appCtx, svc.lc, storeClient, svc.serviceKey, svc.config.PipelineStatistics)
+}
+
 // LoadConfigurablePipeline sets the function pipeline from configuration
 func (svc *Service) LoadConfigurablePipeline() ([]interfaces.AppFunction, error) {
-	var pipeline []interfaces.AppFunction
-
 	svc.usingConfigurablePipeline = true
 
 	svc.targetType = nil
@@ -242,8 +348,45 @@ func (svc *Service) LoadConfigurablePipeline() ([]interfaces.AppFunction, error)
 		svc.targetType = &[]byte{}
 	}
 
-	configurable := reflect.ValueOf(NewConfigurable(svc.lc))
-	pipelineConfig := svc.config.Writable.Pipeline
+	configurableFunctions := NewConfigurable(svc.lc)
+
+	if svc.kvReferences.newClient == nil {
+		svc.kvReferences.newClient = svc.newKVReferenceClient
+	}
+	svc.kvReferences.reset()
+
+	pipeline, err := svc.buildConfigurablePipeline(svc.config.Writable.Pipeline, configurableFunctions, &svc.kvReferences)
+	if err != nil {
+		return nil, err
+	}
+
+	var offlinePipeline []interfaces.AppFunction
+	if offline := svc.config.Writable.Pipeline.Offline; len(strings.TrimSpace(offline.ExecutionOrder)) > 0 {
+		// The offline pipeline's functions are configured along with those of the functions pipeline
+		offlineConfig := svc.config.Writable.Pipeline
+		offlineConfig.ExecutionOrder = offline.ExecutionOrder
+		offlinePipeline, err = svc.buildConfigurablePipeline(offlineConfig, configurableFunctions, &svc.kvReferences)
+		if err != nil {
+			return nil, fmt.Errorf("offline pipeline: %s", err.Error())
+		}
+		svc.lc.Infof("Offline pipeline [%s] used while the functions pipeline exports are failing", offline.ExecutionOrder)
+	}
+
+	svc.offlineTransforms = offlinePipeline
+	svc.setConfigurableFlushers(configurableFunctions.flushers, configurableFunctions.namedFlushers)
+
+	return pipeline, nil
+}
+
+// buildConfigurablePipeline creates the functions of the pipeline configuration, in execution order, from the
+// configurable functions. Parameters referencing Consul keys are resolved by the watcher.
+func (svc *Service) buildConfigurablePipeline(
+	pipelineConfig common.PipelineInfo,
+	configurableFunctions *Configurable,
+	watcher *kvReferenceWatcher) ([]interfaces.AppFunction, error) {
+	var pipeline []interfaces.AppFunction
+
+	configurable := reflect.ValueOf(configurableFunctions)
 	executionOrder := util.DeleteEmptyAndTrim(strings.FieldsFunc(pipelineConfig.ExecutionOrder, util.SplitComma))
 
 	if len(executionOrder) <= 0 {
@@ -265,6 +408,14 @@ func (svc *Service) LoadConfigurablePipeline() ([]interfaces.AppFunction, error)
 			return nil, err
 		}
 
+		if configuration.Disabled {
+			// Kept in the pipeline, rather than removed, so the positions of the other functions are unchanged. Its
+			// parameters aren't validated so a misconfigured function can be disabled.
+			pipeline = append(pipeline, skipDisabledFunction)
+			svc.lc.Infof("%s function disabled in configurable pipeline, data passed through", functionName)
+			continue
+		}
+
 		// determine number of parameters required for function call
 		inputParameters := make([]reflect.Value, functionType.NumIn())
 		// set keys to be all lowercase to avoid casing issues from configuration
@@ -273,12 +424,17 @@ func (svc *Service) LoadConfigurablePipeline() ([]interfaces.AppFunction, error)
 			delete(configuration.Parameters, key) // Make sure the old key has been removed so don't have multiples
 			configuration.Parameters[strings.ToLower(key)] = value
 		}
+		// Resolved into a copy so the references, rather than their values, remain in the configuration
+		parameters, err := watcher.resolve(configuration.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("function %s: %s", functionName, err.Error())
+		}
 		for index := range inputParameters {
 			parameter := functionType.In(index)
 
 			switch parameter {
 			case reflect.TypeOf(map[string]string{}):
-				inputParameters[index] = reflect.ValueOf(configuration.Parameters)
+				inputParameters[index] = reflect.ValueOf(parameters)
 
 			default:
 				return nil, fmt.Errorf(
@@ -308,6 +464,12 @@ func (svc *Service) LoadConfigurablePipeline() ([]interfaces.AppFunction, error)
 	return pipeline, nil
 }
 
+// skipDisabledFunction takes the place of a disabled function in the configurable pipeline, passing the data it
+// receives through to the next function
+func skipDisabledFunction(_ interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	return true, data
+}
+
 // SetFunctionsPipeline sets the function pipeline to the list of specified functions in the order provided.
 func (svc *Service) SetFunctionsPipeline(transforms ...interfaces.AppFunction) error {
 	if len(transforms) == 0 {
@@ -318,6 +480,7 @@ func (svc *Service) SetFunctionsPipeline(transforms ...interfaces.AppFunction) e
 
 	if svc.runtime != nil {
 		svc.runtime.SetTransforms(transforms)
+		svc.runtime.SetOfflineTransforms(svc.offlineTransforms)
 		svc.runtime.TargetType = svc.targetType
 	}
 
@@ -367,13 +530,16 @@ func (svc *Service) Initialize() error {
 		"    -s/--skipVersionCheck           Indicates the service should skip the Core Service's version compatibility check.\n" +
 			"    -sk/--serviceKey                Overrides the service service key used with Registry and/or Configuration Providers.\n" +
 			"                                    If the name provided contains the text `<profile>`, this text will be replaced with\n" +
-			"                                    the name of the profile used."
+			"                                    the name of the profile used.\n" +
+			"    --test-pipelines                Runs the tests from the Writable.Pipeline.Tests configuration against the\n" +
+			"                                    functions pipeline, reports the results and exits rather than running the service."
 
 	svc.flags = flags.NewWithUsage(additionalUsage)
 	svc.flags.FlagSet.BoolVar(&svc.commandLine.skipVersionCheck, "skipVersionCheck", false, "")
 	svc.flags.FlagSet.BoolVar(&svc.commandLine.skipVersionCheck, "s", false, "")
 	svc.flags.FlagSet.StringVar(&svc.commandLine.serviceKeyOverride, "serviceKey", "", "")
 	svc.flags.FlagSet.StringVar(&svc.commandLine.serviceKeyOverride, "sk", "", "")
+	svc.flags.FlagSet.BoolVar(&svc.commandLine.testPipelines, "test-pipelines", false, "")
 
 	svc.flags.Parse(os.Args[1:])
 
@@ -389,6 +555,30 @@ func (svc *Service) Initialize() error {
 		container.ConfigurationName: func(get di.Get) interface{} {
 			return svc.config
 		},
+		container.SLATrackerName: func(get di.Get) interface{} {
+			return telemetry.NewSLATracker()
+		},
+		container.DeviceStatsTrackerName: func(get di.Get) interface{} {
+			return telemetry.NewDeviceStatsTracker()
+		},
+		container.PipelineProfilerName: func(get di.Get) interface{} {
+			return telemetry.NewPipelineProfiler()
+		},
+		container.PayloadPreviewTrackerName: func(get di.Get) interface{} {
+			return telemetry.NewPayloadPreviewTracker()
+		},
+		container.PipelineStatsTrackerName: func(get di.Get) interface{} {
+			return telemetry.NewPipelineStatsTracker()
+		},
+		container.TriggerStatsTrackerName: func(get di.Get) interface{} {
+			return telemetry.NewTriggerStatsTracker()
+		},
+		container.ExecutionSamplerName: func(get di.Get) interface{} {
+			return telemetry.NewExecutionSampler(svc.serviceKey)
+		},
+		container.ApplicationServiceName: func(get di.Get) interface{} {
+			return svc
+		},
 	})
 
 	svc.ctx.appCtx, svc.ctx.appCancelCtx = context.WithCancel(context.Background())
@@ -411,9 +601,14 @@ func (svc *Service) Initialize() error {
 		true,
 		[]bootstrapInterfaces.BootstrapHandler{
 			handlers.NewDatabase().BootstrapHandler,
+			handlers.NewState(svc.serviceKey).BootstrapHandler,
 			handlers.NewClients().BootstrapHandler,
 			handlers.NewTelemetry().BootstrapHandler,
+			handlers.NewTimezone().BootstrapHandler,
+			handlers.NewCache().BootstrapHandler,
+			handlers.NewStream().BootstrapHandler,
 			handlers.NewVersionValidator(svc.commandLine.skipVersionCheck, internal.SDKVersion).BootstrapHandler,
+			handlers.NewDependencies().BootstrapHandler,
 		},
 	)
 
@@ -439,6 +634,147 @@ func (svc *Service) Initialize() error {
 	return nil
 }
 
+// RegisterPipelineInterceptor registers an interceptor which is called before and after every function in the
+// functions pipeline executes. Interceptors are called in the order registered and must be registered before
+// MakeItRun is called.
+func (svc *Service) RegisterPipelineInterceptor(interceptor interfaces.PipelineInterceptor) error {
+	if interceptor == nil {
+		return errors.New("pipeline interceptor must not be nil")
+	}
+
+	svc.interceptors = append(svc.interceptors, interceptor)
+	return nil
+}
+
+// RegisterFlusher registers a function which accumulates data, such as the Batch transform, so its data is flushed
+// when the service stops or FlushPipelineData is called. Functions in the configurable functions pipeline are
+// registered automatically.
+func (svc *Service) RegisterFlusher(flusher interfaces.Flusher) error {
+	if flusher == nil {
+		return errors.New("flusher must not be nil")
+	}
+
+	svc.flushMutex.Lock()
+	defer svc.flushMutex.Unlock()
+
+	svc.flushers = append(svc.flushers, flusher)
+	return nil
+}
+
+// RegisterNamedFlusher registers a function which accumulates data, as RegisterFlusher does, which can also be
+// flushed on its own by its name with FlushNamedPipelineData, i.e. when a custom REST route or command requests it.
+// The name must not be used by another Flusher, including those named in the configurable functions pipeline.
+func (svc *Service) RegisterNamedFlusher(name string, flusher interfaces.Flusher) error {
+	if len(strings.TrimSpace(name)) == 0 {
+		return errors.New("flusher name must not be empty")
+	}
+	if flusher == nil {
+		return errors.New("flusher must not be nil")
+	}
+
+	svc.flushMutex.Lock()
+	defer svc.flushMutex.Unlock()
+
+	if _, exists := svc.namedFlusher(name); exists {
+		return fmt.Errorf("flusher with name '%s' already registered", name)
+	}
+
+	if svc.namedFlushers == nil {
+		svc.namedFlushers = make(map[string]interfaces.Flusher)
+	}
+	svc.namedFlushers[name] = flusher
+	svc.flushers = append(svc.flushers, flusher)
+	return nil
+}
+
+// FlushNamedPipelineData flushes the data accumulated by the Flusher with the name, rather than waiting for its
+// thresholds, and returns whether it had data to flush. An error is returned if no Flusher has the name.
+func (svc *Service) FlushNamedPipelineData(name string) (bool, error) {
+	svc.flushMutex.Lock()
+	defer svc.flushMutex.Unlock()
+
+	flusher, exists := svc.namedFlusher(name)
+	if !exists {
+		return false, fmt.Errorf("no flusher with name '%s' registered", name)
+	}
+
+	flushed := flusher.Flush()
+	if flushed {
+		svc.lc.Infof("Flushed the data accumulated by pipeline function '%s'", name)
+	}
+
+	return flushed, nil
+}
+
+// namedFlusher returns the Flusher registered with the name or named in the configurable functions pipeline. The
+// flushMutex must be held by the caller.
+func (svc *Service) namedFlusher(name string) (interfaces.Flusher, bool) {
+	if flusher, exists := svc.namedFlushers[name]; exists {
+		return flusher, true
+	}
+
+	flusher, exists := svc.configurableNamedFlushers[name]
+	return flusher, exists
+}
+
+// FlushPipelineData flushes the data accumulated by the registered Flushers, rather than waiting for their
+// thresholds, and returns how many had data to flush.
+func (svc *Service) FlushPipelineData() int {
+	svc.flushMutex.Lock()
+	defer svc.flushMutex.Unlock()
+
+	flushed := flushAll(svc.flushers) + flushAll(svc.configurableFlushers)
+	if flushed > 0 {
+		svc.lc.Infof("Flushed the data accumulated by %d pipeline function(s)", flushed)
+	}
+
+	return flushed
+}
+
+// setConfigurableFlushers replaces the Flushers of the previous configurable functions pipeline, which are flushed
+// since they would otherwise no longer be flushed when the service stops
+func (svc *Service) setConfigurableFlushers(flushers []interfaces.Flusher, namedFlushers map[string]interfaces.Flusher) {
+	svc.flushMutex.Lock()
+	defer svc.flushMutex.Unlock()
+
+	flushAll(svc.configurableFlushers)
+	svc.configurableFlushers = flushers
+
+	svc.configurableNamedFlushers = make(map[string]interfaces.Flusher, len(namedFlushers))
+	for name, flusher := range namedFlushers {
+		if _, exists := svc.namedFlushers[name]; exists {
+			svc.lc.Warnf("Flush name '%s' of the configurable functions pipeline is already registered and is ignored", name)
+			continue
+		}
+		svc.configurableNamedFlushers[name] = flusher
+	}
+}
+
+func flushAll(flushers []interfaces.Flusher) int {
+	flushed := 0
+	for _, flusher := range flushers {
+		if flusher.Flush() {
+			flushed++
+		}
+	}
+
+	return flushed
+}
+
+// ReplayStoredData re-runs the Store and Forward items matching the filter through the full functions pipeline.
+// Only available once MakeItRun has been called and Store and Forward is enabled.
+func (svc *Service) ReplayStoredData(filter interfaces.StoreReplayFilter) (interfaces.StoreReplayResult, error) {
+	if svc.runtime == nil {
+		return interfaces.StoreReplayResult{}, errors.New("stored data can not be replayed until the service is running")
+	}
+
+	if !svc.config.Writable.StoreAndForward.Enabled {
+		return interfaces.StoreReplayResult{}, errors.New("stored data can not be replayed as StoreAndForward is not enabled")
+	}
+
+	return svc.runtime.ReplayStoredData(filter)
+}
+
 // LoadCustomConfig uses the Config Processor from go-mod-bootstrap to attempt to load service's
 // custom configuration. It uses the same command line flags to process the custom config in the same manner
 // as the standard configuration.
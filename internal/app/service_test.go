@@ -17,6 +17,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
 	"github.com/google/uuid"
@@ -24,6 +25,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
@@ -104,6 +106,7 @@ func TestAddBackgroundPublisherNoTopic(t *testing.T) {
 
 func TestAddBackgroundPublisherMessageBus(t *testing.T) {
 	sdk := Service{
+		ctx: contextGroup{appCtx: context.Background()},
 		config: &common.ConfigurationStruct{
 			Trigger: common.TriggerInfo{
 				Type: TriggerTypeMessageBus,
@@ -129,13 +132,12 @@ func TestAddBackgroundPublisherMessageBus(t *testing.T) {
 	require.NotNil(t, sdk.backgroundPublishChannel, "svc should have a background channel set for passing to trigger initialization")
 	require.Equal(t, sdk.config.Trigger.EdgexMessageBus.PublishHost.PublishTopic, pub.topic)
 
-	// compare addresses since types will not match
-	assert.Equal(t, fmt.Sprintf("%p", sdk.backgroundPublishChannel), fmt.Sprintf("%p", pub.output),
-		"same channel should be referenced by the BackgroundPublisher and the SDK.")
+	requireBackgroundMessage(t, sdk.backgroundPublishChannel, pub, pub.topic)
 }
 
 func TestAddBackgroundPublisher_Arbitrary(t *testing.T) {
 	sdk := Service{
+		ctx: contextGroup{appCtx: context.Background()},
 		config: &common.ConfigurationStruct{
 			Trigger: common.TriggerInfo{
 				Type: "NOT MQTT OR HTTP",
@@ -161,13 +163,11 @@ func TestAddBackgroundPublisher_Arbitrary(t *testing.T) {
 	require.NotNil(t, sdk.backgroundPublishChannel, "svc should have a background channel set for passing to trigger initialization")
 	require.Equal(t, sdk.config.Trigger.EdgexMessageBus.PublishHost.PublishTopic, pub.topic)
 
-	// compare addresses since types will not match
-	assert.Equal(t, fmt.Sprintf("%p", sdk.backgroundPublishChannel), fmt.Sprintf("%p", pub.output),
-		"same channel should be referenced by the BackgroundPublisher and the SDK.")
+	requireBackgroundMessage(t, sdk.backgroundPublishChannel, pub, pub.topic)
 }
 
 func TestAddBackgroundPublisher_Custom_Topic(t *testing.T) {
-	sdk := Service{config: &common.ConfigurationStruct{}}
+	sdk := Service{config: &common.ConfigurationStruct{}, ctx: contextGroup{appCtx: context.Background()}}
 
 	topic := uuid.NewString()
 
@@ -185,9 +185,80 @@ func TestAddBackgroundPublisher_Custom_Topic(t *testing.T) {
 	require.NotNil(t, sdk.backgroundPublishChannel, "svc should have a background channel set for passing to trigger initialization")
 	require.Equal(t, topic, pub.topic)
 
-	// compare addresses since types will not match
-	assert.Equal(t, fmt.Sprintf("%p", sdk.backgroundPublishChannel), fmt.Sprintf("%p", pub.output),
-		"same channel should be referenced by the BackgroundPublisher and the SDK.")
+	requireBackgroundMessage(t, sdk.backgroundPublishChannel, pub, pub.topic)
+}
+
+func requireBackgroundMessage(t *testing.T, background <-chan interfaces.BackgroundMessage, pub interfaces.BackgroundPublisher, expectedTopic string) {
+	correlationId := uuid.NewString()
+	require.NoError(t, pub.Publish([]byte("something"), appfunction.NewContext(correlationId, nil, "type")))
+
+	select {
+	case message := <-background:
+		assert.Equal(t, correlationId, message.Message().CorrelationID)
+		assert.Equal(t, expectedTopic, message.Topic())
+	case <-time.After(time.Second):
+		require.Fail(t, "message not received on the background channel passed to the trigger")
+	}
+}
+
+func TestAddNamedBackgroundPublisher(t *testing.T) {
+	sdk := Service{
+		ctx: contextGroup{appCtx: context.Background()},
+		config: &common.ConfigurationStruct{
+			Trigger: common.TriggerInfo{
+				Type: TriggerTypeMessageBus,
+				EdgexMessageBus: common.MessageBusConfig{
+					PublishHost: common.PublishHostInfo{
+						PublishTopic: "topic",
+					},
+				},
+			},
+		}}
+
+	qos := byte(1)
+	alerts, err := sdk.AddNamedBackgroundPublisher("alerts", interfaces.BackgroundPublisherOptions{Capacity: 1, Topic: "alerts", QoS: &qos})
+	require.NoError(t, err)
+	reports, err := sdk.AddNamedBackgroundPublisher("reports", interfaces.BackgroundPublisherOptions{Capacity: 5, ContentType: "text/csv"})
+	require.NoError(t, err)
+	unnamed, err := sdk.AddBackgroundPublisher(1)
+	require.NoError(t, err)
+
+	_, err = sdk.AddNamedBackgroundPublisher("alerts", interfaces.BackgroundPublisherOptions{})
+	require.Error(t, err, "expected error for duplicate name")
+	_, err = sdk.AddNamedBackgroundPublisher(" ", interfaces.BackgroundPublisherOptions{})
+	require.Error(t, err, "expected error for empty name")
+
+	actual, found := sdk.NamedBackgroundPublisher("alerts")
+	require.True(t, found)
+	assert.Equal(t, alerts, actual)
+	_, found = sdk.NamedBackgroundPublisher("missing")
+	assert.False(t, found)
+
+	// All publishers share the channel passed to the trigger
+	requireBackgroundMessage(t, sdk.backgroundPublishChannel, alerts, "alerts")
+	requireBackgroundMessage(t, sdk.backgroundPublishChannel, reports, "topic")
+	requireBackgroundMessage(t, sdk.backgroundPublishChannel, unnamed, "topic")
+
+	require.NoError(t, reports.PublishWithOptions([]byte("a,b"), appfunction.NewContext("id", nil, "type"),
+		interfaces.BackgroundPublishOptions{Topic: "reports", QoS: &qos}))
+	select {
+	case message := <-sdk.backgroundPublishChannel:
+		assert.Equal(t, "reports", message.Topic())
+		assert.Equal(t, "text/csv", message.Message().ContentType)
+		actualQoS, set := message.QoS()
+		assert.True(t, set)
+		assert.Equal(t, qos, actualQoS)
+	case <-time.After(time.Second):
+		require.Fail(t, "message not received on the background channel passed to the trigger")
+	}
+}
+
+func TestAddNamedBackgroundPublisher_NoTopic(t *testing.T) {
+	sdk := Service{config: &common.ConfigurationStruct{Trigger: common.TriggerInfo{Type: TriggerTypeMessageBus}}}
+
+	pub, err := sdk.AddNamedBackgroundPublisher("alerts", interfaces.BackgroundPublisherOptions{Capacity: 1})
+	require.Error(t, err)
+	require.Nil(t, pub)
 }
 
 func TestAddBackgroundPublisher_MQTT(t *testing.T) {
@@ -466,6 +537,96 @@ func TestLoadConfigurablePipelineNumFunctions(t *testing.T) {
 	assert.Equal(t, 3, len(appFunctions))
 }
 
+func TestLoadConfigurablePipelineOffline(t *testing.T) {
+	functions := make(map[string]common.PipelineFunction)
+	functions["Transform"] = common.PipelineFunction{
+		Parameters: map[string]string{TransformType: TransformXml},
+	}
+	functions["SetResponseData"] = common.PipelineFunction{}
+	functions["SetResponseDataOffline"] = common.PipelineFunction{}
+
+	tests := []struct {
+		Name            string
+		Offline         common.OfflinePipelineInfo
+		ExpectedOffline int
+		ErrorContains   string
+	}{
+		{"Offline pipeline", common.OfflinePipelineInfo{ExecutionOrder: "Transform, SetResponseDataOffline"}, 2, ""},
+		{"Not configured", common.OfflinePipelineInfo{}, 0, ""},
+		{"Function not found", common.OfflinePipelineInfo{ExecutionOrder: "Bogus"}, 0, "offline pipeline"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			sdk := Service{
+				lc: lc,
+				config: &common.ConfigurationStruct{
+					Writable: common.WritableInfo{
+						Pipeline: common.PipelineInfo{
+							ExecutionOrder: "Transform, SetResponseData",
+							Functions:      functions,
+							Offline:        test.Offline,
+						},
+					},
+				},
+			}
+
+			appFunctions, err := sdk.LoadConfigurablePipeline()
+			if len(test.ErrorContains) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ErrorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, appFunctions, 2)
+			assert.Len(t, sdk.offlineTransforms, test.ExpectedOffline)
+		})
+	}
+}
+
+func TestLoadConfigurablePipelineDisabledFunction(t *testing.T) {
+	functions := make(map[string]common.PipelineFunction)
+	functions["AddTags"] = common.PipelineFunction{
+		// Not validated while disabled
+		Parameters: map[string]string{Tags: "bogus"},
+		Disabled:   true,
+	}
+	functions["SetResponseData"] = common.PipelineFunction{}
+
+	sdk := Service{
+		lc: lc,
+		config: &common.ConfigurationStruct{
+			Writable: common.WritableInfo{
+				Pipeline: common.PipelineInfo{
+					ExecutionOrder: "AddTags, SetResponseData",
+					Functions:      functions,
+				},
+			},
+		},
+	}
+
+	appFunctions, err := sdk.LoadConfigurablePipeline()
+	require.NoError(t, err)
+	require.Len(t, appFunctions, 2)
+
+	// The disabled function passes the data through
+	continuePipeline, result := appFunctions[0](nil, "data")
+	assert.True(t, continuePipeline)
+	assert.Equal(t, "data", result)
+
+	// Once enabled its parameters are validated
+	functions["AddTags"] = common.PipelineFunction{Parameters: map[string]string{Tags: "bogus"}}
+	_, err = sdk.LoadConfigurablePipeline()
+	require.Error(t, err)
+
+	// Disabled functions must still be built in functions
+	functions["Bogus"] = common.PipelineFunction{Disabled: true}
+	sdk.config.Writable.Pipeline.ExecutionOrder = "Bogus, SetResponseData"
+	_, err = sdk.LoadConfigurablePipeline()
+	require.Error(t, err)
+}
+
 func TestUseTargetTypeOfByteArrayTrue(t *testing.T) {
 	functions := make(map[string]common.PipelineFunction)
 	functions["Compress"] = common.PipelineFunction{
@@ -810,3 +971,157 @@ func TestService_BuildContext(t *testing.T) {
 	require.NotNil(t, castctx)
 	require.Equal(t, dic, castctx.Dic)
 }
+
+func TestService_ListenForCustomConfigChangesNotLoaded(t *testing.T) {
+	sdk := Service{
+		dic: dic,
+		lc:  lc,
+	}
+
+	err := sdk.ListenForCustomConfigChanges(&struct{}{}, "AppCustom", func(interface{}) {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'AppCustom'")
+}
+
+func TestService_RegisterPipelineInterceptor(t *testing.T) {
+	sdk := Service{
+		dic: dic,
+		lc:  lc,
+	}
+
+	require.Error(t, sdk.RegisterPipelineInterceptor(nil))
+
+	interceptor := func(appContext interfaces.AppFunctionContext, execution interfaces.FunctionExecution) error {
+		return nil
+	}
+	require.NoError(t, sdk.RegisterPipelineInterceptor(interceptor))
+	require.NoError(t, sdk.RegisterPipelineInterceptor(interceptor))
+	assert.Len(t, sdk.interceptors, 2)
+}
+
+type testFlusher struct {
+	hasData bool
+	calls   int
+}
+
+func (flusher *testFlusher) Flush() bool {
+	flusher.calls++
+	return flusher.hasData
+}
+
+func TestService_FlushPipelineData(t *testing.T) {
+	sdk := Service{
+		dic: dic,
+		lc:  lc,
+	}
+
+	require.Error(t, sdk.RegisterFlusher(nil))
+
+	withData := &testFlusher{hasData: true}
+	withoutData := &testFlusher{}
+	require.NoError(t, sdk.RegisterFlusher(withData))
+	require.NoError(t, sdk.RegisterFlusher(withoutData))
+
+	assert.Equal(t, 1, sdk.FlushPipelineData())
+	assert.Equal(t, 1, withData.calls)
+	assert.Equal(t, 1, withoutData.calls)
+}
+
+func TestService_FlushNamedPipelineData(t *testing.T) {
+	sdk := Service{
+		dic: dic,
+		lc:  lc,
+	}
+
+	withData := &testFlusher{hasData: true}
+	require.Error(t, sdk.RegisterNamedFlusher("", withData))
+	require.Error(t, sdk.RegisterNamedFlusher("truck-dock", nil))
+	require.NoError(t, sdk.RegisterNamedFlusher("truck-dock", withData))
+	require.Error(t, sdk.RegisterNamedFlusher("truck-dock", &testFlusher{}))
+
+	configurable := &testFlusher{}
+	sdk.setConfigurableFlushers(nil, map[string]interfaces.Flusher{"loading-bay": configurable, "truck-dock": &testFlusher{}})
+
+	flushed, err := sdk.FlushNamedPipelineData("truck-dock")
+	require.NoError(t, err)
+	assert.True(t, flushed)
+	assert.Equal(t, 1, withData.calls)
+
+	flushed, err = sdk.FlushNamedPipelineData("loading-bay")
+	require.NoError(t, err)
+	assert.False(t, flushed)
+	assert.Equal(t, 1, configurable.calls)
+
+	_, err = sdk.FlushNamedPipelineData("unknown")
+	require.Error(t, err)
+
+	// Named flushers are also flushed with the rest
+	assert.Equal(t, 1, sdk.FlushPipelineData())
+	assert.Equal(t, 2, withData.calls)
+}
+
+func TestLoadConfigurablePipelineFlushers(t *testing.T) {
+	functions := make(map[string]common.PipelineFunction)
+	functions["Batch"] = common.PipelineFunction{
+		Parameters: map[string]string{Mode: BatchByTime, TimeInterval: "10s"},
+	}
+	functions["SetResponseData"] = common.PipelineFunction{}
+
+	sdk := Service{
+		lc: lc,
+		config: &common.ConfigurationStruct{
+			Writable: common.WritableInfo{
+				Pipeline: common.PipelineInfo{
+					ExecutionOrder: "Batch, SetResponseData",
+					Functions:      functions,
+				},
+			},
+		},
+	}
+
+	_, err := sdk.LoadConfigurablePipeline()
+	require.NoError(t, err)
+	require.Len(t, sdk.configurableFlushers, 1)
+	previous := sdk.configurableFlushers[0]
+
+	// The flushers of the previous pipeline are replaced when reloaded
+	_, err = sdk.LoadConfigurablePipeline()
+	require.NoError(t, err)
+	require.Len(t, sdk.configurableFlushers, 1)
+	assert.NotSame(t, previous, sdk.configurableFlushers[0])
+
+	// Batches are flushed on their own by their flush name
+	functions["Batch"] = common.PipelineFunction{
+		Parameters: map[string]string{Mode: BatchByTime, TimeInterval: "10s", FlushName: "truck-dock"},
+	}
+	_, err = sdk.LoadConfigurablePipeline()
+	require.NoError(t, err)
+	flushed, err := sdk.FlushNamedPipelineData("truck-dock")
+	require.NoError(t, err)
+	assert.False(t, flushed)
+
+	// Data batched by count only can't be flushed
+	functions["Batch"] = common.PipelineFunction{
+		Parameters: map[string]string{Mode: BatchByCount, BatchThreshold: "10"},
+	}
+	_, err = sdk.LoadConfigurablePipeline()
+	require.NoError(t, err)
+	assert.Len(t, sdk.configurableFlushers, 0)
+}
+
+func TestService_ReplayStoredData(t *testing.T) {
+	sdk := Service{
+		dic:    dic,
+		lc:     lc,
+		config: &common.ConfigurationStruct{},
+	}
+
+	_, err := sdk.ReplayStoredData(interfaces.StoreReplayFilter{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "until the service is running")
+
+	sdk.runtime = &runtime.GolangRuntime{}
+	_, err = sdk.ReplayStoredData(interfaces.StoreReplayFilter{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "StoreAndForward is not enabled")
+}
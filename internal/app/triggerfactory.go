@@ -31,14 +31,25 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/http"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/messagebus"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/mqtt"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/redispubsub"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/serial"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/udp"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/webhook"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 )
 
 const (
 	// Valid types of App Service triggers
-	TriggerTypeMessageBus = "EDGEX-MESSAGEBUS"
-	TriggerTypeMQTT       = "EXTERNAL-MQTT"
-	TriggerTypeHTTP       = "HTTP"
+	TriggerTypeMessageBus  = "EDGEX-MESSAGEBUS"
+	TriggerTypeMQTT        = "EXTERNAL-MQTT"
+	TriggerTypeHTTP        = "HTTP"
+	TriggerTypeRedisPubSub = "REDIS-PUBSUB"
+	TriggerTypeKafka       = "KAFKA"
+	TriggerTypeAMQP        = "AMQP"
+	TriggerTypeUDP         = "UDP"
+	TriggerTypeSerial      = "SERIAL"
+	TriggerTypeSNMP        = "SNMP"
+	TriggerTypeWebhook     = "WEBHOOK"
 )
 
 // RegisterCustomTriggerFactory allows users to register builders for custom trigger types
@@ -48,7 +59,14 @@ func (svc *Service) RegisterCustomTriggerFactory(name string,
 
 	if nu == TriggerTypeMessageBus ||
 		nu == TriggerTypeHTTP ||
-		nu == TriggerTypeMQTT {
+		nu == TriggerTypeMQTT ||
+		nu == TriggerTypeRedisPubSub ||
+		nu == TriggerTypeKafka ||
+		nu == TriggerTypeAMQP ||
+		nu == TriggerTypeUDP ||
+		nu == TriggerTypeSerial ||
+		nu == TriggerTypeSNMP ||
+		nu == TriggerTypeWebhook {
 		return fmt.Errorf("cannot register custom trigger for builtin type (%s)", name)
 	}
 
@@ -108,6 +126,34 @@ func (svc *Service) setupTrigger(configuration *common.ConfigurationStruct, runt
 		svc.LoggingClient().Info("External MQTT trigger selected")
 		t = mqtt.NewTrigger(svc.dic, runtime)
 
+	case TriggerTypeRedisPubSub:
+		svc.LoggingClient().Info("Redis Pub/Sub trigger selected")
+		t = redispubsub.NewTrigger(svc.dic, runtime)
+
+	case TriggerTypeKafka:
+		svc.LoggingClient().Info("Kafka trigger selected")
+		t = svc.newKafkaTrigger(runtime)
+
+	case TriggerTypeAMQP:
+		svc.LoggingClient().Info("AMQP trigger selected")
+		t = svc.newAMQPTrigger(runtime)
+
+	case TriggerTypeUDP:
+		svc.LoggingClient().Info("UDP trigger selected")
+		t = udp.NewTrigger(svc.dic, runtime)
+
+	case TriggerTypeSerial:
+		svc.LoggingClient().Info("Serial trigger selected")
+		t = serial.NewTrigger(svc.dic, runtime)
+
+	case TriggerTypeSNMP:
+		svc.LoggingClient().Info("SNMP trigger selected")
+		t = svc.newSNMPTrigger(runtime)
+
+	case TriggerTypeWebhook:
+		svc.LoggingClient().Info("Webhook trigger selected")
+		t = webhook.NewTrigger(svc.dic, runtime, svc.webserver)
+
 	default:
 		if factory, found := svc.customTriggerFactories[triggerType]; found {
 			var err error
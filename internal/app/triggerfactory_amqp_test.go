@@ -0,0 +1,48 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2020 Technotects
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/amqp"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupTrigger_AMQP(t *testing.T) {
+	sdk := Service{
+		config: &common.ConfigurationStruct{
+			Trigger: common.TriggerInfo{
+				Type: TriggerTypeAMQP,
+			},
+		},
+		dic: dic,
+		lc:  logger.MockLogger{},
+	}
+
+	trigger := sdk.setupTrigger(sdk.config, sdk.runtime)
+
+	require.NotNil(t, trigger, "should be defined")
+	require.IsType(t, &amqp.Trigger{}, trigger, "should be an amqp trigger")
+}
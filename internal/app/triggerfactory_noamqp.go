@@ -0,0 +1,31 @@
+//go:build no_amqp
+// +build no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+// newAMQPTrigger reports that the AMQP trigger was left out of the build by the no_amqp tag.
+func (svc *Service) newAMQPTrigger(_ *runtime.GolangRuntime) interfaces.Trigger {
+	svc.LoggingClient().Errorf("AMQP trigger is not available, the service was built with the no_amqp tag")
+	return nil
+}
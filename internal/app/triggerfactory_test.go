@@ -31,6 +31,10 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/http"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/messagebus"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/mqtt"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/redispubsub"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/serial"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/udp"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/trigger/webhook"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
@@ -69,6 +73,76 @@ func TestRegisterCustomTriggerFactory_MQTT(t *testing.T) {
 	require.Zero(t, len(sdk.customTriggerFactories), "nothing should be registered")
 }
 
+func TestRegisterCustomTriggerFactory_RedisPubSub(t *testing.T) {
+	name := strings.ToTitle(TriggerTypeRedisPubSub)
+
+	sdk := Service{}
+	err := sdk.RegisterCustomTriggerFactory(name, nil)
+
+	require.Error(t, err, "should throw error")
+	require.Zero(t, len(sdk.customTriggerFactories), "nothing should be registered")
+}
+
+func TestRegisterCustomTriggerFactory_Kafka(t *testing.T) {
+	name := strings.ToTitle(TriggerTypeKafka)
+
+	sdk := Service{}
+	err := sdk.RegisterCustomTriggerFactory(name, nil)
+
+	require.Error(t, err, "should throw error")
+	require.Zero(t, len(sdk.customTriggerFactories), "nothing should be registered")
+}
+
+func TestRegisterCustomTriggerFactory_AMQP(t *testing.T) {
+	name := strings.ToTitle(TriggerTypeAMQP)
+
+	sdk := Service{}
+	err := sdk.RegisterCustomTriggerFactory(name, nil)
+
+	require.Error(t, err, "should throw error")
+	require.Zero(t, len(sdk.customTriggerFactories), "nothing should be registered")
+}
+
+func TestRegisterCustomTriggerFactory_UDP(t *testing.T) {
+	name := strings.ToTitle(TriggerTypeUDP)
+
+	sdk := Service{}
+	err := sdk.RegisterCustomTriggerFactory(name, nil)
+
+	require.Error(t, err, "should throw error")
+	require.Zero(t, len(sdk.customTriggerFactories), "nothing should be registered")
+}
+
+func TestRegisterCustomTriggerFactory_Serial(t *testing.T) {
+	name := strings.ToTitle(TriggerTypeSerial)
+
+	sdk := Service{}
+	err := sdk.RegisterCustomTriggerFactory(name, nil)
+
+	require.Error(t, err, "should throw error")
+	require.Zero(t, len(sdk.customTriggerFactories), "nothing should be registered")
+}
+
+func TestRegisterCustomTriggerFactory_SNMP(t *testing.T) {
+	name := strings.ToTitle(TriggerTypeSNMP)
+
+	sdk := Service{}
+	err := sdk.RegisterCustomTriggerFactory(name, nil)
+
+	require.Error(t, err, "should throw error")
+	require.Zero(t, len(sdk.customTriggerFactories), "nothing should be registered")
+}
+
+func TestRegisterCustomTriggerFactory_Webhook(t *testing.T) {
+	name := strings.ToTitle(TriggerTypeWebhook)
+
+	sdk := Service{}
+	err := sdk.RegisterCustomTriggerFactory(name, nil)
+
+	require.Error(t, err, "should throw error")
+	require.Zero(t, len(sdk.customTriggerFactories), "nothing should be registered")
+}
+
 func TestRegisterCustomTrigger(t *testing.T) {
 	name := "cUsToM tRiGgEr"
 	trig := mockCustomTrigger{}
@@ -148,6 +222,74 @@ func TestSetupTrigger_MQTT(t *testing.T) {
 	require.IsType(t, &mqtt.Trigger{}, trigger, "should be an external-MQTT trigger")
 }
 
+func TestSetupTrigger_RedisPubSub(t *testing.T) {
+	sdk := Service{
+		config: &common.ConfigurationStruct{
+			Trigger: common.TriggerInfo{
+				Type: TriggerTypeRedisPubSub,
+			},
+		},
+		dic: dic,
+		lc:  logger.MockLogger{},
+	}
+
+	trigger := sdk.setupTrigger(sdk.config, sdk.runtime)
+
+	require.NotNil(t, trigger, "should be defined")
+	require.IsType(t, &redispubsub.Trigger{}, trigger, "should be a redis-pubsub trigger")
+}
+
+func TestSetupTrigger_UDP(t *testing.T) {
+	sdk := Service{
+		config: &common.ConfigurationStruct{
+			Trigger: common.TriggerInfo{
+				Type: TriggerTypeUDP,
+			},
+		},
+		dic: dic,
+		lc:  logger.MockLogger{},
+	}
+
+	trigger := sdk.setupTrigger(sdk.config, sdk.runtime)
+
+	require.NotNil(t, trigger, "should be defined")
+	require.IsType(t, &udp.Trigger{}, trigger, "should be a udp trigger")
+}
+
+func TestSetupTrigger_Serial(t *testing.T) {
+	sdk := Service{
+		config: &common.ConfigurationStruct{
+			Trigger: common.TriggerInfo{
+				Type: TriggerTypeSerial,
+			},
+		},
+		dic: dic,
+		lc:  logger.MockLogger{},
+	}
+
+	trigger := sdk.setupTrigger(sdk.config, sdk.runtime)
+
+	require.NotNil(t, trigger, "should be defined")
+	require.IsType(t, &serial.Trigger{}, trigger, "should be a serial trigger")
+}
+
+func TestSetupTrigger_Webhook(t *testing.T) {
+	sdk := Service{
+		config: &common.ConfigurationStruct{
+			Trigger: common.TriggerInfo{
+				Type: TriggerTypeWebhook,
+			},
+		},
+		dic: dic,
+		lc:  logger.MockLogger{},
+	}
+
+	trigger := sdk.setupTrigger(sdk.config, sdk.runtime)
+
+	require.NotNil(t, trigger, "should be defined")
+	require.IsType(t, &webhook.Trigger{}, trigger, "should be a webhook trigger")
+}
+
 type mockCustomTrigger struct {
 }
 
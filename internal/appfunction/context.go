@@ -22,9 +22,12 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	sdkInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
@@ -44,6 +47,7 @@ func NewContext(correlationID string, dic *di.Container, inputContentType string
 		inputContentType:     inputContentType,
 		contextData:          make(map[string]string, 0),
 		valuePlaceholderSpec: regexp.MustCompile("{[^}]*}"),
+		pipelineId:           sdkInterfaces.DefaultPipelineId,
 	}
 }
 
@@ -54,10 +58,23 @@ type Context struct {
 	correlationID        string
 	inputContentType     string
 	responseData         []byte
+	responseParts        []responsePart
+	negotiated           *responsePart
+	responseMutex        sync.Mutex
 	retryData            []byte
+	pipelineInput        []byte
+	pipelineContentType  string
 	responseContentType  string
+	responseTopic        string
 	contextData          map[string]string
 	valuePlaceholderSpec *regexp.Regexp
+	pipelineId           string
+	functionName         string
+	filteredBy           string
+	filteredReason       string
+	filtered             bool
+	ctx                  context.Context
+	received             time.Time
 }
 
 // SetCorrelationID sets the correlationID. This function is not part of the AppFunctionContext interface,
@@ -71,6 +88,45 @@ func (appContext *Context) CorrelationID() string {
 	return appContext.correlationID
 }
 
+// PipelineId returns the ID of the pipeline that is executing
+func (appContext *Context) PipelineId() string {
+	return appContext.pipelineId
+}
+
+// Context returns the context.Context of the message being processed, which is context.Background() unless one
+// has been set by SetContext
+func (appContext *Context) Context() context.Context {
+	if appContext.ctx == nil {
+		return context.Background()
+	}
+
+	return appContext.ctx
+}
+
+// SetContext sets the context.Context of the message being processed. This function is not part of the
+// AppFunctionContext interface, so it is internal SDK use only
+func (appContext *Context) SetContext(ctx context.Context) {
+	appContext.ctx = ctx
+}
+
+// Received returns when the trigger received the message being processed, which is the zero time unless it has been
+// set by SetReceived
+func (appContext *Context) Received() time.Time {
+	return appContext.received
+}
+
+// SetReceived sets when the trigger received the message being processed. This function is not part of the
+// AppFunctionContext interface, so it is internal SDK use only
+func (appContext *Context) SetReceived(received time.Time) {
+	appContext.received = received
+}
+
+// SetFunctionName sets the name of the pipeline function that is executing. This function is not part of the
+// AppFunctionContext interface, so it is internal SDK use only
+func (appContext *Context) SetFunctionName(name string) {
+	appContext.functionName = name
+}
+
 // SetInputContentType sets the inputContentType. This function is not part of the AppFunctionContext interface,
 // so it is internal SDK use only
 func (appContext *Context) SetInputContentType(contentType string) {
@@ -85,23 +141,99 @@ func (appContext *Context) InputContentType() string {
 // SetResponseData provides a way to return the specified data as a response to the trigger that initiated
 // the execution of the function pipeline. In the case of an HTTP Trigger, the data will be returned as the http response.
 // In the case of a message bus trigger, the data will be published to the configured message bus publish topic.
+// Replaces any response data previously set or added.
 func (appContext *Context) SetResponseData(output []byte) {
+	appContext.responseMutex.Lock()
+	defer appContext.responseMutex.Unlock()
+
 	appContext.responseData = output
+	appContext.responseParts = nil
+	appContext.negotiated = nil
 }
 
-// ResponseData returns the context's responseData.
+// AddResponseData appends a part to the data returned as the response to the trigger. contentType is the content
+// type of the part, or empty to use the response content type. Safe to call from functions executing concurrently.
+func (appContext *Context) AddResponseData(data []byte, contentType string) {
+	appContext.responseMutex.Lock()
+	defer appContext.responseMutex.Unlock()
+
+	appContext.responseParts = append(appContext.responseParts, responsePart{data: data, contentType: contentType})
+	appContext.negotiated = nil
+}
+
+// ResponseData returns the context's responseData. When multiple parts have been added they are combined as
+// described by negotiateResponse.
 func (appContext *Context) ResponseData() []byte {
-	return appContext.responseData
+	appContext.responseMutex.Lock()
+	defer appContext.responseMutex.Unlock()
+
+	if len(appContext.responseParts) == 0 {
+		return appContext.responseData
+	}
+
+	return appContext.negotiateResponse().data
 }
 
 // SetResponseContentType sets the context's responseContentType
 func (appContext *Context) SetResponseContentType(contentType string) {
+	appContext.responseMutex.Lock()
+	defer appContext.responseMutex.Unlock()
+
 	appContext.responseContentType = contentType
+	appContext.negotiated = nil
 }
 
-// ResponseContentType returns the context's responseContentType
+// ResponseContentType returns the context's responseContentType, or the content type negotiated for the parts added
+// with AddResponseData
 func (appContext *Context) ResponseContentType() string {
-	return appContext.responseContentType
+	appContext.responseMutex.Lock()
+	defer appContext.responseMutex.Unlock()
+
+	if len(appContext.responseParts) == 0 {
+		return appContext.responseContentType
+	}
+
+	return appContext.negotiateResponse().contentType
+}
+
+// SetResponseTopic sets the context's responseTopic, overriding the topic the trigger publishes the response data to
+func (appContext *Context) SetResponseTopic(topic string) {
+	appContext.responseTopic = topic
+}
+
+// ResponseTopic returns the context's responseTopic, or the ResponseTopic configured for the functions pipeline when
+// not set. The trigger publishes to its configured topic when empty.
+func (appContext *Context) ResponseTopic() string {
+	if len(appContext.responseTopic) > 0 {
+		return appContext.responseTopic
+	}
+
+	if config, ok := appContext.Dic.Get(container.ConfigurationName).(*sdkCommon.ConfigurationStruct); ok {
+		return config.Writable.Pipeline.ResponseTopic
+	}
+
+	return ""
+}
+
+// SetFiltered marks the data as filtered out by the executing function for the reason specified
+func (appContext *Context) SetFiltered(reason string) {
+	appContext.filteredBy = appContext.functionName
+	appContext.filteredReason = reason
+	appContext.filtered = true
+}
+
+// Filtered returns the name of the function which marked the data as filtered out and the reason, if it was. This
+// function is not part of the AppFunctionContext interface, so it is internal SDK use only
+func (appContext *Context) Filtered() (string, string, bool) {
+	return appContext.filteredBy, appContext.filteredReason, appContext.filtered
+}
+
+// ClearFiltered clears the data being marked as filtered out. This function is not part of the AppFunctionContext
+// interface, so it is internal SDK use only
+func (appContext *Context) ClearFiltered() {
+	appContext.filteredBy = ""
+	appContext.filteredReason = ""
+	appContext.filtered = false
 }
 
 // SetRetryData sets the context's retryData to the specified payload to be stored for later retry
@@ -116,6 +248,19 @@ func (appContext *Context) RetryData() []byte {
 	return appContext.retryData
 }
 
+// SetPipelineInput sets the context's pipelineInput to the raw data and content type received by the trigger,
+// which is stored with the retryData so that the data can be replayed through the whole pipeline.
+func (appContext *Context) SetPipelineInput(payload []byte, contentType string) {
+	appContext.pipelineInput = payload
+	appContext.pipelineContentType = contentType
+}
+
+// PipelineInput returns the context's pipelineInput and its content type. This function is not part of the
+// AppFunctionContext interface, so it is internal SDK use only
+func (appContext *Context) PipelineInput() ([]byte, string) {
+	return appContext.pipelineInput, appContext.pipelineContentType
+}
+
 // GetSecret returns the secret data from the secret store (secure or insecure) for the specified path.
 func (appContext *Context) GetSecret(path string, keys ...string) (map[string]string, error) {
 	secretProvider := bootstrapContainer.SecretProviderFrom(appContext.Dic.Get)
@@ -168,6 +313,46 @@ func (appContext *Context) SubscriptionClient() interfaces.SubscriptionClient {
 	return container.SubscriptionClientFrom(appContext.Dic.Get)
 }
 
+// Cache returns the key-value cache shared by all executions of the functions pipeline from the dependency injection
+// container, which is nil until the service has been bootstrapped
+func (appContext *Context) Cache() sdkInterfaces.Cache {
+	if sharedCache := container.CacheFrom(appContext.Dic.Get); sharedCache != nil {
+		return sharedCache
+	}
+
+	return nil
+}
+
+// State returns the persistent key-value state from the dependency injection container, which is nil unless
+// State.Enabled is set
+func (appContext *Context) State() sdkInterfaces.State {
+	if persistentState := container.StateFrom(appContext.Dic.Get); persistentState != nil {
+		return persistentState
+	}
+
+	return nil
+}
+
+// Location returns the time zone configured for the service by Timezone from the dependency injection container,
+// which is UTC until the service has been bootstrapped or when no Timezone is configured
+func (appContext *Context) Location() *time.Location {
+	if location := container.LocationFrom(appContext.Dic.Get); location != nil {
+		return location
+	}
+
+	return time.UTC
+}
+
+// Stream returns the stream pushing data to the clients of the /stream endpoint from the dependency injection
+// container, which is nil unless Stream.Enabled is set
+func (appContext *Context) Stream() sdkInterfaces.Stream {
+	if hub := container.StreamHubFrom(appContext.Dic.Get); hub != nil {
+		return hub
+	}
+
+	return nil
+}
+
 // AddValue stores a value for access within other functions in pipeline
 func (appContext *Context) AddValue(key string, value string) {
 	appContext.contextData[strings.ToLower(key)] = value
@@ -17,13 +17,25 @@
 package appfunction
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/cache"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/state"
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
@@ -158,6 +170,71 @@ func TestContext_SubscriptionClient(t *testing.T) {
 	assert.NotNil(t, actual)
 }
 
+func TestContext_Cache(t *testing.T) {
+	actual := target.Cache()
+	assert.Nil(t, actual)
+
+	dic.Update(di.ServiceConstructorMap{
+		container.CacheName: func(get di.Get) interface{} {
+			return cache.NewCache(10, time.Minute)
+		},
+	})
+
+	actual = target.Cache()
+	require.NotNil(t, actual)
+
+	actual.Set("key", []byte("value"), 0)
+	value, found := NewContext("", dic, "").Cache().Get("key")
+	assert.True(t, found, "cache not shared between contexts")
+	assert.Equal(t, []byte("value"), value)
+}
+
+func TestContext_State(t *testing.T) {
+	assert.Nil(t, target.State())
+
+	persistentState := state.NewState(nil, "app-test")
+	dic.Update(di.ServiceConstructorMap{
+		container.StateName: func(get di.Get) interface{} {
+			return persistentState
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.StateName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	assert.Equal(t, persistentState, target.State())
+}
+
+func TestContext_Context(t *testing.T) {
+	appContext := NewContext("testId", dic, "")
+	assert.Equal(t, context.Background(), appContext.Context())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	appContext.SetContext(ctx)
+	assert.Equal(t, ctx, appContext.Context())
+}
+
+func TestContext_Location(t *testing.T) {
+	assert.Equal(t, time.UTC, target.Location())
+
+	location := time.FixedZone("CST", -6*60*60)
+	dic.Update(di.ServiceConstructorMap{
+		container.LocationName: func(get di.Get) interface{} {
+			return location
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.LocationName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	assert.Equal(t, location, target.Location())
+}
+
 func TestContext_LoggingClient(t *testing.T) {
 	actual := target.LoggingClient()
 	assert.NotNil(t, actual)
@@ -217,6 +294,41 @@ func TestContext_SetResponseContentType(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestContext_ResponseTopic(t *testing.T) {
+	dic := di.NewContainer(di.ServiceConstructorMap{})
+	appContext := NewContext("", dic, "")
+	assert.Empty(t, appContext.ResponseTopic(), "expected empty topic without configuration")
+
+	config := &sdkCommon.ConfigurationStruct{}
+	config.Writable.Pipeline.ResponseTopic = "results/{devicename}"
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+	assert.Equal(t, "results/{devicename}", appContext.ResponseTopic())
+
+	appContext.SetResponseTopic("alerts/{devicename}")
+	assert.Equal(t, "alerts/{devicename}", appContext.ResponseTopic())
+}
+
+func TestContext_SetFiltered(t *testing.T) {
+	appContext := NewContext("", dic, "")
+	_, _, filtered := appContext.Filtered()
+	assert.False(t, filtered)
+
+	appContext.SetFunctionName("FilterByDeviceName")
+	appContext.SetFiltered("device not accepted")
+	function, reason, filtered := appContext.Filtered()
+	assert.True(t, filtered)
+	assert.Equal(t, "FilterByDeviceName", function)
+	assert.Equal(t, "device not accepted", reason)
+
+	appContext.ClearFiltered()
+	_, _, filtered = appContext.Filtered()
+	assert.False(t, filtered)
+}
+
 func TestContext_SetResponseData(t *testing.T) {
 	expected := []byte("response data")
 
@@ -235,6 +347,91 @@ func TestContext_ResponseData(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestContext_AddResponseData(t *testing.T) {
+	tests := []struct {
+		Name                string
+		ResponseData        []byte
+		ResponseContentType string
+		Parts               []responsePart
+		ExpectedData        string
+		ExpectedContentType string
+	}{
+		{"Single part", nil, "", []responsePart{{[]byte("<a/>"), common.ContentTypeXML}}, "<a/>", common.ContentTypeXML},
+		{"Single part response content type", nil, common.ContentTypeText, []responsePart{{[]byte("a"), ""}}, "a", common.ContentTypeText},
+		{"JSON parts", nil, "", []responsePart{{[]byte(`{"a":1}`), ""}, {[]byte(`[2]`), common.ContentTypeJSON}}, `[{"a":1},[2]]`, common.ContentTypeJSON},
+		{"Set and added JSON parts", []byte(`{"a":1}`), common.ContentTypeJSON, []responsePart{{[]byte(`{"b":2}`), ""}}, `[{"a":1},{"b":2}]`, common.ContentTypeJSON},
+		{"Nil parts ignored", nil, "", []responsePart{{nil, ""}, {[]byte("a"), common.ContentTypeText}}, "a", common.ContentTypeText},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := NewContext("", dic, "")
+			target.SetResponseData(test.ResponseData)
+			target.SetResponseContentType(test.ResponseContentType)
+			for _, part := range test.Parts {
+				target.AddResponseData(part.data, part.contentType)
+			}
+
+			assert.Equal(t, test.ExpectedData, string(target.ResponseData()))
+			assert.Equal(t, test.ExpectedContentType, target.ResponseContentType())
+		})
+	}
+}
+
+func TestContext_AddResponseDataMultipart(t *testing.T) {
+	target := NewContext("", dic, "")
+	target.AddResponseData([]byte(`{"a":1}`), "")
+	target.AddResponseData([]byte("<a/>"), common.ContentTypeXML)
+	target.AddResponseData([]byte{0x01, 0x02}, "")
+
+	mediaType, params, err := mime.ParseMediaType(target.ResponseContentType())
+	require.NoError(t, err)
+	assert.Equal(t, contentTypeMultipartMixed, mediaType)
+
+	reader := multipart.NewReader(bytes.NewReader(target.ResponseData()), params["boundary"])
+	expected := []responsePart{
+		{[]byte(`{"a":1}`), common.ContentTypeJSON},
+		{[]byte("<a/>"), common.ContentTypeXML},
+		{[]byte{0x01, 0x02}, contentTypeOctetStream},
+	}
+	for _, part := range expected {
+		actual, err := reader.NextPart()
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(actual)
+		require.NoError(t, err)
+		assert.Equal(t, part.data, data)
+		assert.Equal(t, part.contentType, actual.Header.Get(common.ContentType))
+	}
+	_, err = reader.NextPart()
+	assert.Equal(t, io.EOF, err)
+
+	// Setting the response data replaces the parts
+	target.SetResponseData([]byte("replaced"))
+	target.SetResponseContentType(common.ContentTypeText)
+	assert.Equal(t, []byte("replaced"), target.ResponseData())
+	assert.Equal(t, common.ContentTypeText, target.ResponseContentType())
+}
+
+func TestContext_AddResponseDataConcurrent(t *testing.T) {
+	target := NewContext("", dic, "")
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target.AddResponseData([]byte(strconv.Itoa(i)), "")
+			_ = target.ResponseData()
+		}(i)
+	}
+	wg.Wait()
+
+	var actual []int
+	require.NoError(t, json.Unmarshal(target.ResponseData(), &actual))
+	assert.Len(t, actual, 50)
+	assert.Equal(t, common.ContentTypeJSON, target.ResponseContentType())
+}
+
 func TestContext_SetRetryData(t *testing.T) {
 	expected := []byte("retry data")
 
@@ -0,0 +1,108 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appfunction
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+)
+
+const (
+	pipelineLogKey = "pipeline"
+	functionLogKey = "function"
+)
+
+// Logger returns a Logger client that adds the correlation ID, pipeline ID and name of the executing pipeline
+// function to every log message.
+func (appContext *Context) Logger() logger.LoggingClient {
+	tags := []interface{}{
+		common.CorrelationHeader, appContext.correlationID,
+		pipelineLogKey, appContext.pipelineId,
+	}
+
+	if len(appContext.functionName) > 0 {
+		tags = append(tags, functionLogKey, appContext.functionName)
+	}
+
+	return contextLogger{
+		lc:   appContext.LoggingClient(),
+		tags: tags,
+	}
+}
+
+// contextLogger wraps a LoggingClient, adding key/value pairs to every message it logs
+type contextLogger struct {
+	lc   logger.LoggingClient
+	tags []interface{}
+}
+
+func (cl contextLogger) withTags(args []interface{}) []interface{} {
+	tagged := make([]interface{}, 0, len(cl.tags)+len(args))
+	tagged = append(tagged, cl.tags...)
+	return append(tagged, args...)
+}
+
+func (cl contextLogger) SetLogLevel(logLevel string) errors.EdgeX {
+	return cl.lc.SetLogLevel(logLevel)
+}
+
+func (cl contextLogger) LogLevel() string {
+	return cl.lc.LogLevel()
+}
+
+func (cl contextLogger) Debug(msg string, args ...interface{}) {
+	cl.lc.Debug(msg, cl.withTags(args)...)
+}
+
+func (cl contextLogger) Error(msg string, args ...interface{}) {
+	cl.lc.Error(msg, cl.withTags(args)...)
+}
+
+func (cl contextLogger) Info(msg string, args ...interface{}) {
+	cl.lc.Info(msg, cl.withTags(args)...)
+}
+
+func (cl contextLogger) Trace(msg string, args ...interface{}) {
+	cl.lc.Trace(msg, cl.withTags(args)...)
+}
+
+func (cl contextLogger) Warn(msg string, args ...interface{}) {
+	cl.lc.Warn(msg, cl.withTags(args)...)
+}
+
+func (cl contextLogger) Debugf(msg string, args ...interface{}) {
+	cl.lc.Debug(fmt.Sprintf(msg, args...), cl.tags...)
+}
+
+func (cl contextLogger) Errorf(msg string, args ...interface{}) {
+	cl.lc.Error(fmt.Sprintf(msg, args...), cl.tags...)
+}
+
+func (cl contextLogger) Infof(msg string, args ...interface{}) {
+	cl.lc.Info(fmt.Sprintf(msg, args...), cl.tags...)
+}
+
+func (cl contextLogger) Tracef(msg string, args ...interface{}) {
+	cl.lc.Trace(fmt.Sprintf(msg, args...), cl.tags...)
+}
+
+func (cl contextLogger) Warnf(msg string, args ...interface{}) {
+	cl.lc.Warn(fmt.Sprintf(msg, args...), cl.tags...)
+}
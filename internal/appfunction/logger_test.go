@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appfunction
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type loggedMessage struct {
+	level string
+	msg   string
+	args  []interface{}
+}
+
+// recordingLogger records the messages logged at the INFO, WARN and ERROR levels
+type recordingLogger struct {
+	logger.LoggingClient
+	messages []loggedMessage
+}
+
+func (rl *recordingLogger) Info(msg string, args ...interface{}) {
+	rl.messages = append(rl.messages, loggedMessage{"INFO", msg, args})
+}
+
+func (rl *recordingLogger) Warn(msg string, args ...interface{}) {
+	rl.messages = append(rl.messages, loggedMessage{"WARN", msg, args})
+}
+
+func (rl *recordingLogger) Error(msg string, args ...interface{}) {
+	rl.messages = append(rl.messages, loggedMessage{"ERROR", msg, args})
+}
+
+func TestContext_Logger(t *testing.T) {
+	recorder := &recordingLogger{LoggingClient: logger.NewMockClient()}
+	loggerDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return recorder
+		},
+	})
+
+	appContext := NewContext("correlation-123", loggerDic, "")
+	assert.Equal(t, interfaces.DefaultPipelineId, appContext.PipelineId())
+
+	appContext.Logger().Info("no function", "key", "value")
+
+	appContext.SetFunctionName("transforms.(*Conversion).TransformToJSON")
+	lc := appContext.Logger()
+	lc.Warnf("formatted %d", 42)
+	lc.Error("plain")
+
+	require.Len(t, recorder.messages, 3)
+
+	assert.Equal(t, loggedMessage{"INFO", "no function", []interface{}{
+		common.CorrelationHeader, "correlation-123",
+		pipelineLogKey, interfaces.DefaultPipelineId,
+		"key", "value",
+	}}, recorder.messages[0])
+
+	expectedTags := []interface{}{
+		common.CorrelationHeader, "correlation-123",
+		pipelineLogKey, interfaces.DefaultPipelineId,
+		functionLogKey, "transforms.(*Conversion).TransformToJSON",
+	}
+	assert.Equal(t, loggedMessage{"WARN", "formatted 42", expectedTags}, recorder.messages[1])
+	assert.Equal(t, loggedMessage{"ERROR", "plain", expectedTags}, recorder.messages[2])
+}
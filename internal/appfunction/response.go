@@ -0,0 +1,157 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appfunction
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+)
+
+// contentTypeMultipartMixed is the content type of responses combining parts of differing content types
+const contentTypeMultipartMixed = "multipart/mixed"
+
+// contentTypeOctetStream is the content type of multipart response parts whose content type isn't known
+const contentTypeOctetStream = "application/octet-stream"
+
+// responsePart is a part of the response added by a pipeline function, or the negotiated response
+type responsePart struct {
+	data        []byte
+	contentType string
+}
+
+// negotiateResponse combines the response data set by SetResponseData, if any, and the parts added by
+// AddResponseData into the response returned to the trigger:
+//   - a single part is returned as is
+//   - parts which are all JSON are returned as a JSON array of the parts
+//   - otherwise the parts are returned as a multipart/mixed document with each part's content type
+//
+// Parts without a content type use the response content type, if set. The result is kept until the response
+// changes so the data and content type are consistent. Must be called with the response mutex held.
+func (appContext *Context) negotiateResponse() *responsePart {
+	if appContext.negotiated != nil {
+		return appContext.negotiated
+	}
+
+	var parts []responsePart
+	if appContext.responseData != nil {
+		parts = append(parts, responsePart{data: appContext.responseData})
+	}
+	for _, part := range appContext.responseParts {
+		if part.data != nil {
+			parts = append(parts, part)
+		}
+	}
+
+	for i := range parts {
+		if len(parts[i].contentType) == 0 {
+			parts[i].contentType = appContext.responseContentType
+		}
+	}
+
+	switch {
+	case len(parts) == 0:
+		appContext.negotiated = &responsePart{contentType: appContext.responseContentType}
+	case len(parts) == 1:
+		appContext.negotiated = &parts[0]
+	case allJSONParts(parts):
+		appContext.negotiated = joinJSONParts(parts)
+	default:
+		negotiated, err := joinMultipartParts(parts)
+		if err != nil {
+			// Only fails if writing to the buffer fails, so fall back to the raw parts rather than losing the response
+			appContext.LoggingClient().Errorf("unable to combine response parts: %s", err.Error())
+			negotiated = &responsePart{data: bytes.Join(partsData(parts), []byte("\n"))}
+		}
+		appContext.negotiated = negotiated
+	}
+
+	return appContext.negotiated
+}
+
+func allJSONParts(parts []responsePart) bool {
+	for _, part := range parts {
+		if len(part.contentType) > 0 {
+			if !strings.HasPrefix(part.contentType, common.ContentTypeJSON) {
+				return false
+			}
+			continue
+		}
+
+		if !json.Valid(part.data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func joinJSONParts(parts []responsePart) *responsePart {
+	data := []byte{'['}
+	data = append(data, bytes.Join(partsData(parts), []byte{','})...)
+	data = append(data, ']')
+
+	return &responsePart{data: data, contentType: common.ContentTypeJSON}
+}
+
+func joinMultipartParts(parts []responsePart) (*responsePart, error) {
+	buffer := &bytes.Buffer{}
+	writer := multipart.NewWriter(buffer)
+
+	for _, part := range parts {
+		contentType := part.contentType
+		if len(contentType) == 0 {
+			if json.Valid(part.data) {
+				contentType = common.ContentTypeJSON
+			} else {
+				contentType = contentTypeOctetStream
+			}
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set(common.ContentType, contentType)
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = partWriter.Write(part.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return &responsePart{
+		data:        buffer.Bytes(),
+		contentType: fmt.Sprintf("%s; boundary=%s", contentTypeMultipartMixed, writer.Boundary()),
+	}, nil
+}
+
+func partsData(parts []responsePart) [][]byte {
+	data := make([][]byte, len(parts))
+	for i, part := range parts {
+		data[i] = part.data
+	}
+	return data
+}
@@ -0,0 +1,51 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package container
+
+import (
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/stream"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+)
+
+// StreamHubName contains the name of the stream.Hub in the DIC.
+var StreamHubName = di.TypeInstanceToName(stream.Hub{})
+
+// StreamHubFrom helper function queries the DIC and returns the stream.Hub.
+func StreamHubFrom(get di.Get) *stream.Hub {
+	item := get(StreamHubName)
+
+	if item == nil {
+		return nil
+	}
+
+	return item.(*stream.Hub)
+}
+
+// PipelineHubsName contains the name of the stream.PipelineHubs in the DIC.
+var PipelineHubsName = di.TypeInstanceToName(stream.PipelineHubs{})
+
+// PipelineHubsFrom helper function queries the DIC and returns the stream.PipelineHubs.
+func PipelineHubsFrom(get di.Get) *stream.PipelineHubs {
+	item := get(PipelineHubsName)
+
+	if item == nil {
+		return nil
+	}
+
+	return item.(*stream.PipelineHubs)
+}
@@ -0,0 +1,75 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/cache"
+)
+
+// Cache contains references to dependencies required by the Cache bootstrap implementation.
+type Cache struct {
+}
+
+// NewCache create a new instance of Cache
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+// BootstrapHandler creates the cache shared by all executions of the functions pipeline, loading the persisted
+// entries when Cache.PersistFile is set, and starts purging its expired entries
+func (_ *Cache) BootstrapHandler(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	_ startup.Timer,
+	dic *di.Container) bool {
+
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	config := container.ConfigurationFrom(dic.Get)
+
+	sharedCache, err := cache.NewCacheFromConfig(config.Cache)
+	if err != nil {
+		lc.Error(err.Error())
+		return false
+	}
+
+	if len(config.Cache.PersistFile) > 0 {
+		if err := sharedCache.Load(config.Cache.PersistFile); err != nil {
+			// The cache is only an optimization, so start with it empty rather than failing
+			lc.Warnf("Unable to load persisted cache: %s", err.Error())
+		} else {
+			lc.Infof("Loaded %d cache entries from %s", sharedCache.Len(), config.Cache.PersistFile)
+		}
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.CacheName: func(get di.Get) interface{} {
+			return sharedCache
+		},
+	})
+
+	wg.Add(1)
+	go sharedCache.StartPurging(wg, ctx, lc, config.Cache.PersistFile)
+
+	return true
+}
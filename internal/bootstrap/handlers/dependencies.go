@@ -0,0 +1,235 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	clients "github.com/edgexfoundry/go-mod-core-contracts/v2/clients/http"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+const (
+	defaultDependenciesTimeout  = 60 * time.Second
+	defaultDependenciesInterval = time.Second
+	dependencyCheckTimeout      = 5 * time.Second
+)
+
+// dependency is a service or endpoint which must be available before the trigger starts receiving messages
+type dependency struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// Dependencies contains references to dependencies required by the Startup Dependencies bootstrap implementation.
+type Dependencies struct {
+}
+
+// NewDependencies create a new instance of Dependencies
+func NewDependencies() *Dependencies {
+	return &Dependencies{}
+}
+
+// BootstrapHandler waits for the configured StartupDependencies to be available, failing when they are not all
+// available within the Timeout
+func (_ *Dependencies) BootstrapHandler(
+	ctx context.Context,
+	_ *sync.WaitGroup,
+	_ startup.Timer,
+	dic *di.Container) bool {
+
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	config := container.ConfigurationFrom(dic.Get)
+
+	pending, err := startupDependencies(config)
+	if err != nil {
+		lc.Errorf("Invalid StartupDependencies: %s", err.Error())
+		return false
+	}
+
+	if len(pending) == 0 {
+		return true
+	}
+
+	timeout, err := parseDependenciesDuration(config.StartupDependencies.Timeout, defaultDependenciesTimeout)
+	if err != nil {
+		lc.Errorf("Invalid StartupDependencies Timeout: %s", err.Error())
+		return false
+	}
+
+	interval, err := parseDependenciesDuration(config.StartupDependencies.Interval, defaultDependenciesInterval)
+	if err != nil {
+		lc.Errorf("Invalid StartupDependencies Interval: %s", err.Error())
+		return false
+	}
+
+	lc.Infof("Waiting up to %s for %d startup dependencies", timeout.String(), len(pending))
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var unavailable []dependency
+		for _, item := range pending {
+			checkCtx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+			err := item.check(checkCtx)
+			cancel()
+
+			if err != nil {
+				lc.Debugf("Startup dependency '%s' not yet available: %s", item.name, err.Error())
+				unavailable = append(unavailable, item)
+				continue
+			}
+
+			lc.Infof("Startup dependency '%s' is available", item.name)
+		}
+
+		if len(unavailable) == 0 {
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			names := make([]string, len(unavailable))
+			for index, item := range unavailable {
+				names[index] = item.name
+			}
+			lc.Errorf("Startup dependencies not available after %s: %s", timeout.String(), strings.Join(names, ", "))
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(interval):
+		}
+
+		pending = unavailable
+	}
+}
+
+// startupDependencies returns the dependencies for the configured services and endpoints
+func startupDependencies(config *common.ConfigurationStruct) ([]dependency, error) {
+	var dependencies []dependency
+
+	for _, serviceKey := range util.DeleteEmptyAndTrim(strings.FieldsFunc(config.StartupDependencies.Services, util.SplitComma)) {
+		client, ok := config.Clients[serviceKey]
+		if !ok {
+			return nil, fmt.Errorf("service '%s' missing from Clients configuration", serviceKey)
+		}
+
+		commonClient := clients.NewCommonClient(client.Url())
+		dependencies = append(dependencies, dependency{
+			name: serviceKey,
+			check: func(ctx context.Context) error {
+				if _, err := commonClient.Ping(ctx); err != nil {
+					return err
+				}
+				return nil
+			},
+		})
+	}
+
+	for _, endpoint := range util.DeleteEmptyAndTrim(strings.FieldsFunc(config.StartupDependencies.Endpoints, util.SplitComma)) {
+		item, err := endpointDependency(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, item)
+	}
+
+	return dependencies, nil
+}
+
+// endpointDependency returns the dependency checking an HTTP(S) endpoint responds with a 2xx status or, for other
+// URLs, that the host accepts TCP connections
+func endpointDependency(endpoint string) (dependency, error) {
+	parsedUrl, err := url.Parse(endpoint)
+	if err != nil || len(parsedUrl.Host) == 0 {
+		return dependency{}, fmt.Errorf("endpoint '%s' is not a URL with a host", endpoint)
+	}
+
+	// Only the host is logged in case the URL contains credentials
+	name := parsedUrl.Scheme + "://" + parsedUrl.Host
+
+	switch strings.ToLower(parsedUrl.Scheme) {
+	case "http", "https":
+		return dependency{
+			name: name,
+			check: func(ctx context.Context) error {
+				request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+				if err != nil {
+					return err
+				}
+
+				response, err := http.DefaultClient.Do(request)
+				if err != nil {
+					return err
+				}
+				_ = response.Body.Close()
+
+				if response.StatusCode < 200 || response.StatusCode >= 300 {
+					return fmt.Errorf("responded with %d HTTP status code", response.StatusCode)
+				}
+				return nil
+			},
+		}, nil
+
+	default:
+		if len(parsedUrl.Port()) == 0 {
+			return dependency{}, fmt.Errorf("endpoint '%s' has no port", name)
+		}
+
+		return dependency{
+			name: name,
+			check: func(ctx context.Context) error {
+				connection, err := (&net.Dialer{}).DialContext(ctx, "tcp", parsedUrl.Host)
+				if err != nil {
+					return err
+				}
+				return connection.Close()
+			},
+		}, nil
+	}
+}
+
+// parseDependenciesDuration parses the duration, which must be positive, returning the default when it is empty
+func parseDependenciesDuration(value string, defaultValue time.Duration) (time.Duration, error) {
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("'%s' must be greater than zero", value)
+	}
+
+	return duration, nil
+}
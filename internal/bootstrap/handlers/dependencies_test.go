@@ -0,0 +1,159 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
+	bootstrapConfig "github.com/edgexfoundry/go-mod-bootstrap/v2/config"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependenciesBootstrapHandler(t *testing.T) {
+	var healthy bool
+	lock := sync.Mutex{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == common.ApiPingRoute {
+			_, _ = w.Write([]byte(`{"apiVersion":"v2","timestamp":"now"}`))
+		}
+	}))
+	defer server.Close()
+
+	serverUrl, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverUrl.Port())
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closed.Addr().String()
+	_ = closed.Close()
+
+	clients := map[string]bootstrapConfig.ClientInfo{
+		common.CoreDataServiceKey: {Host: serverUrl.Hostname(), Port: port, Protocol: "http"},
+	}
+
+	tests := []struct {
+		Name          string
+		Dependencies  sdkCommon.StartupDependenciesInfo
+		Healthy       bool
+		HealthyLater  bool
+		ExpectSuccess bool
+	}{
+		{"None", sdkCommon.StartupDependenciesInfo{}, false, false, true},
+		{"Available", sdkCommon.StartupDependenciesInfo{
+			Services:  common.CoreDataServiceKey,
+			Endpoints: server.URL + "/health, tcp://" + listener.Addr().String(),
+		}, true, false, true},
+		{"Becomes available", sdkCommon.StartupDependenciesInfo{
+			Services: common.CoreDataServiceKey,
+			Timeout:  "5s",
+			Interval: "10ms",
+		}, false, true, true},
+		{"Unavailable", sdkCommon.StartupDependenciesInfo{
+			Endpoints: "tcp://" + closedAddress,
+			Timeout:   "50ms",
+			Interval:  "10ms",
+		}, true, false, false},
+		{"Unhealthy", sdkCommon.StartupDependenciesInfo{
+			Endpoints: server.URL,
+			Timeout:   "50ms",
+			Interval:  "10ms",
+		}, false, false, false},
+		{"Unknown service", sdkCommon.StartupDependenciesInfo{Services: "bogus"}, true, false, false},
+		{"Endpoint without port", sdkCommon.StartupDependenciesInfo{Endpoints: "tcp://broker"}, true, false, false},
+		{"Invalid timeout", sdkCommon.StartupDependenciesInfo{Services: common.CoreDataServiceKey, Timeout: "-1s"}, true, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			lock.Lock()
+			healthy = test.Healthy
+			lock.Unlock()
+
+			if test.HealthyLater {
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					lock.Lock()
+					healthy = true
+					lock.Unlock()
+				}()
+			}
+
+			configuration := &sdkCommon.ConfigurationStruct{Clients: clients, StartupDependencies: test.Dependencies}
+			dic := di.NewContainer(di.ServiceConstructorMap{
+				bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+					return logger.NewMockClient()
+				},
+				container.ConfigurationName: func(get di.Get) interface{} {
+					return configuration
+				},
+			})
+
+			startupTimer := startup.NewStartUpTimer("unit-test")
+			success := NewDependencies().BootstrapHandler(context.Background(), &sync.WaitGroup{}, startupTimer, dic)
+			assert.Equal(t, test.ExpectSuccess, success)
+		})
+	}
+}
+
+func TestDependenciesBootstrapHandlerCancelled(t *testing.T) {
+	configuration := &sdkCommon.ConfigurationStruct{
+		StartupDependencies: sdkCommon.StartupDependenciesInfo{Endpoints: "tcp://127.0.0.1:1", Timeout: "1h"},
+	}
+	dic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return configuration
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	startupTimer := startup.NewStartUpTimer("unit-test")
+	assert.False(t, NewDependencies().BootstrapHandler(ctx, &sync.WaitGroup{}, startupTimer, dic))
+}
@@ -0,0 +1,76 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/state"
+)
+
+// State contains references to dependencies required by the State bootstrap implementation.
+type State struct {
+	serviceKey string
+}
+
+// NewState create a new instance of State
+func NewState(serviceKey string) *State {
+	return &State{serviceKey: serviceKey}
+}
+
+// BootstrapHandler creates the persistent key-value state when State.Enabled is set, restoring the values stored by
+// the previous runs of the service. Must run after the Database bootstrap handler.
+func (handler *State) BootstrapHandler(
+	_ context.Context,
+	_ *sync.WaitGroup,
+	_ startup.Timer,
+	dic *di.Container) bool {
+
+	config := container.ConfigurationFrom(dic.Get)
+	if !config.State.Enabled {
+		return true
+	}
+
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	storeClient := container.StoreClientFrom(dic.Get)
+	if storeClient == nil {
+		lc.Error("State requires the Database, which hasn't been initialized")
+		return false
+	}
+
+	persistentState := state.NewState(storeClient, handler.serviceKey)
+	if err := persistentState.Load(); err != nil {
+		// Functions depend on their state being restored, so don't start with it empty
+		lc.Errorf("Unable to restore the persistent state: %s", err.Error())
+		return false
+	}
+	lc.Infof("Restored %d persistent state values", len(persistentState.Keys()))
+
+	dic.Update(di.ServiceConstructorMap{
+		container.StateName: func(get di.Get) interface{} {
+			return persistentState
+		},
+	})
+
+	return true
+}
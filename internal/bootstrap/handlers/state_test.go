@@ -0,0 +1,79 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/db/interfaces/mocks"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStateBootstrapHandler(t *testing.T) {
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("RetrieveFromStore", mock.Anything).Return([]contracts.StoredObject{}, nil)
+
+	tests := []struct {
+		Name          string
+		Enabled       bool
+		WithDatabase  bool
+		ExpectState   bool
+		ExpectFailure bool
+	}{
+		{"Disabled", false, false, false, false},
+		{"Enabled", true, true, true, false},
+		{"Enabled without Database", true, false, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			configuration := &sdkCommon.ConfigurationStruct{State: sdkCommon.StateInfo{Enabled: test.Enabled}}
+			dic := di.NewContainer(di.ServiceConstructorMap{
+				bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+					return logger.NewMockClient()
+				},
+				container.ConfigurationName: func(get di.Get) interface{} {
+					return configuration
+				},
+			})
+			if test.WithDatabase {
+				dic.Update(di.ServiceConstructorMap{
+					container.StoreClientName: func(get di.Get) interface{} {
+						return storeClient
+					},
+				})
+			}
+
+			startupTimer := startup.NewStartUpTimer("unit-test")
+			success := NewState("app-test").BootstrapHandler(context.Background(), &sync.WaitGroup{}, startupTimer, dic)
+
+			assert.Equal(t, !test.ExpectFailure, success)
+			assert.Equal(t, test.ExpectState, container.StateFrom(dic.Get) != nil)
+		})
+	}
+}
@@ -43,7 +43,8 @@ func NewDatabase() *Database {
 	return &Database{}
 }
 
-// BootstrapHandler creates the new interfaces.StoreClient use for database access by Store & Forward capability
+// BootstrapHandler creates the new interfaces.StoreClient use for database access by Store & Forward capability, the
+// persisting of the pipeline statistics and the persistent state
 func (_ *Database) BootstrapHandler(
 	_ context.Context,
 	_ *sync.WaitGroup,
@@ -52,8 +53,9 @@ func (_ *Database) BootstrapHandler(
 
 	config := container.ConfigurationFrom(dic.Get)
 
-	// Only need the database client if Store and Forward is enabled
-	if !config.Writable.StoreAndForward.Enabled {
+	// Only need the database client if Store and Forward is enabled, the pipeline statistics are persisted or the
+	// persistent state is enabled
+	if !config.Writable.StoreAndForward.Enabled && !config.PipelineStatistics.Persist && !config.State.Enabled {
 		dic.Update(di.ServiceConstructorMap{
 			container.StoreClientName: func(get di.Get) interface{} {
 				return nil
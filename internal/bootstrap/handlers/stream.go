@@ -0,0 +1,68 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/stream"
+)
+
+// Stream contains references to dependencies required by the Stream bootstrap implementation.
+type Stream struct {
+}
+
+// NewStream create a new instance of Stream
+func NewStream() *Stream {
+	return &Stream{}
+}
+
+// BootstrapHandler creates the hub pushing the data published by the functions pipeline to the clients connected to
+// the /stream endpoint, when Stream.Enabled is set, and the hubs streaming each pipeline's response data to the
+// clients of the /pipelines/{id}/events endpoint, when ServerSentEvents.Enabled is set
+func (_ *Stream) BootstrapHandler(
+	_ context.Context,
+	_ *sync.WaitGroup,
+	_ startup.Timer,
+	dic *di.Container) bool {
+
+	config := container.ConfigurationFrom(dic.Get)
+
+	if config.Stream.Enabled {
+		hub := stream.NewHubFromConfig(config.Stream)
+		dic.Update(di.ServiceConstructorMap{
+			container.StreamHubName: func(get di.Get) interface{} {
+				return hub
+			},
+		})
+	}
+
+	if config.ServerSentEvents.Enabled {
+		pipelineHubs := stream.NewPipelineHubsFromConfig(config.ServerSentEvents)
+		dic.Update(di.ServiceConstructorMap{
+			container.PipelineHubsName: func(get di.Get) interface{} {
+				return pipelineHubs
+			},
+		})
+	}
+
+	return true
+}
@@ -19,10 +19,11 @@ import (
 	"context"
 	"sync"
 
-	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
 )
 
@@ -42,10 +43,15 @@ func (_ *Telemetry) BootstrapHandler(
 	_ startup.Timer,
 	dic *di.Container) bool {
 
-	logger := container.LoggingClientFrom(dic.Get)
+	logger := bootstrapContainer.LoggingClientFrom(dic.Get)
 
 	wg.Add(1)
 	go telemetry.StartCpuUsageAverage(wg, ctx, logger)
 
+	if tracker := container.DeviceStatsTrackerFrom(dic.Get); tracker != nil {
+		wg.Add(1)
+		go tracker.StartMonitor(wg, ctx, logger, container.ConfigurationFrom(dic.Get))
+	}
+
 	return true
 }
@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+)
+
+// Timezone contains references to dependencies required by the Timezone bootstrap implementation.
+type Timezone struct {
+}
+
+// NewTimezone create a new instance of Timezone
+func NewTimezone() *Timezone {
+	return &Timezone{}
+}
+
+// BootstrapHandler loads the location of the configured Timezone used by the time based functions, failing when the
+// time zone is unknown rather than silently using UTC
+func (_ *Timezone) BootstrapHandler(
+	_ context.Context,
+	_ *sync.WaitGroup,
+	_ startup.Timer,
+	dic *di.Container) bool {
+
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	config := container.ConfigurationFrom(dic.Get)
+
+	location := time.UTC
+	if len(config.Timezone) > 0 {
+		var err error
+		location, err = time.LoadLocation(config.Timezone)
+		if err != nil {
+			lc.Errorf("Unable to load Timezone '%s': %s", config.Timezone, err.Error())
+			return false
+		}
+
+		lc.Infof("Time based functions using the '%s' time zone", location.String())
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.LocationName: func(get di.Get) interface{} {
+			return location
+		},
+	})
+
+	return true
+}
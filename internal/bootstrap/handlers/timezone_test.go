@@ -0,0 +1,74 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimezoneBootstrapHandler(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Timezone         string
+		ExpectedLocation string
+		ExpectFailure    bool
+	}{
+		{"Default", "", "UTC", false},
+		{"Valid", "America/Chicago", "America/Chicago", false},
+		{"Unknown", "Bogus/Zone", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			configuration := &sdkCommon.ConfigurationStruct{Timezone: test.Timezone}
+			dic := di.NewContainer(di.ServiceConstructorMap{
+				bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+					return logger.NewMockClient()
+				},
+				container.ConfigurationName: func(get di.Get) interface{} {
+					return configuration
+				},
+			})
+
+			startupTimer := startup.NewStartUpTimer("unit-test")
+			success := NewTimezone().BootstrapHandler(context.Background(), &sync.WaitGroup{}, startupTimer, dic)
+
+			location := container.LocationFrom(dic.Get)
+			if test.ExpectFailure {
+				assert.False(t, success)
+				assert.Nil(t, location)
+				return
+			}
+
+			require.True(t, success)
+			require.NotNil(t, location)
+			assert.Equal(t, test.ExpectedLocation, location.String())
+		})
+	}
+}
@@ -0,0 +1,327 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+const (
+	// DefaultMaxEntries is the number of entries the cache holds when Cache.MaxEntries isn't set
+	DefaultMaxEntries = 10000
+	// DefaultTTL is how long entries are kept when Cache.DefaultTTL isn't set
+	DefaultTTL = time.Hour
+	// purgeInterval is how often expired entries are removed from the cache
+	purgeInterval = time.Minute
+)
+
+type cacheEntry struct {
+	key   string
+	value []byte
+	// expires is zero for entries which don't expire
+	expires time.Time
+}
+
+// persistedEntry is the form of an entry saved to the Cache.PersistFile
+type persistedEntry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+	// Expires is in nanoseconds since the epoch, zero for entries which don't expire
+	Expires int64 `json:"expires,omitempty"`
+}
+
+// Cache is a bounded, least recently used key-value cache whose entries expire after their time to live.
+// It implements the interfaces.Cache interface.
+type Cache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	defaultTTL time.Duration
+	entries    map[string]*list.Element
+	// recent orders the entries from most to least recently used
+	recent *list.List
+	now    func() time.Time
+}
+
+// NewCache creates a new Cache holding at most maxEntries entries, which expire after defaultTTL unless
+// stored with their own time to live
+func NewCache(maxEntries int, defaultTTL time.Duration) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	if defaultTTL == 0 {
+		defaultTTL = DefaultTTL
+	}
+
+	return &Cache{
+		maxEntries: maxEntries,
+		defaultTTL: defaultTTL,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+		now:        time.Now,
+	}
+}
+
+// NewCacheFromConfig creates a new Cache using the Cache configuration
+func NewCacheFromConfig(config common.CacheInfo) (*Cache, error) {
+	var defaultTTL time.Duration
+	if len(config.DefaultTTL) > 0 {
+		var err error
+		defaultTTL, err = time.ParseDuration(config.DefaultTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Cache DefaultTTL '%s': %s", config.DefaultTTL, err.Error())
+		}
+	}
+
+	return NewCache(config.MaxEntries, defaultTTL), nil
+}
+
+// Get returns a copy of the value stored for the key, if present and not expired
+func (cache *Cache) Get(key string) ([]byte, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, found := cache.entries[key]
+	if !found {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if entry.expired(cache.now()) {
+		cache.remove(element)
+		return nil, false
+	}
+
+	cache.recent.MoveToFront(element)
+	return copyBytes(entry.value), true
+}
+
+// Set stores a copy of the value for the key. The entry expires after ttl, or the cache's default time to live when
+// ttl is zero. A negative ttl stores an entry that doesn't expire. The least recently used entry is evicted when
+// the cache is full.
+func (cache *Cache) Set(key string, value []byte, ttl time.Duration) {
+	if ttl == 0 {
+		ttl = cache.defaultTTL
+	}
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = cache.now().Add(ttl)
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.set(key, copyBytes(value), expires)
+}
+
+// set stores the entry as the most recently used. Must be called with the mutex held.
+func (cache *Cache) set(key string, value []byte, expires time.Time) {
+	if element, found := cache.entries[key]; found {
+		entry := element.Value.(*cacheEntry)
+		entry.value = value
+		entry.expires = expires
+		cache.recent.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.recent.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+
+	for len(cache.entries) > cache.maxEntries {
+		cache.remove(cache.recent.Back())
+	}
+}
+
+// Delete removes the entry for the key, if present
+func (cache *Cache) Delete(key string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, found := cache.entries[key]; found {
+		cache.remove(element)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired entries which haven't been purged
+func (cache *Cache) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return len(cache.entries)
+}
+
+// Purge removes the expired entries and returns the number removed
+func (cache *Cache) Purge() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := cache.now()
+	purged := 0
+	for element := cache.recent.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*cacheEntry).expired(now) {
+			cache.remove(element)
+			purged++
+		}
+		element = next
+	}
+
+	return purged
+}
+
+// remove removes the element's entry. Must be called with the mutex held.
+func (cache *Cache) remove(element *list.Element) {
+	cache.recent.Remove(element)
+	delete(cache.entries, element.Value.(*cacheEntry).key)
+}
+
+// Save writes the entries which haven't expired to the file, replacing it, so they can be loaded when the
+// service restarts
+func (cache *Cache) Save(path string) error {
+	cache.mutex.Lock()
+	now := cache.now()
+	entries := make([]persistedEntry, 0, len(cache.entries))
+	for element := cache.recent.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*cacheEntry)
+		if entry.expired(now) {
+			continue
+		}
+
+		persisted := persistedEntry{Key: entry.key, Value: entry.value}
+		if !entry.expires.IsZero() {
+			persisted.Expires = entry.expires.UnixNano()
+		}
+		entries = append(entries, persisted)
+	}
+	cache.mutex.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("unable to marshal cache entries: %s", err.Error())
+	}
+
+	// Write to a temporary file first so the previous file isn't lost if writing fails part way
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create cache file: %s", err.Error())
+	}
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("unable to write cache file: %s", err.Error())
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("unable to write cache file: %s", err.Error())
+	}
+
+	if err = os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("unable to replace cache file '%s': %s", path, err.Error())
+	}
+
+	return nil
+}
+
+// Load adds the entries which haven't expired from the file written by Save. A file which doesn't exist is ignored.
+func (cache *Cache) Load(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to read cache file '%s': %s", path, err.Error())
+	}
+
+	var entries []persistedEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("unable to unmarshal cache file '%s': %s", path, err.Error())
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := cache.now()
+	// Entries are saved most recently used first, so are added in reverse to keep their order
+	for i := len(entries) - 1; i >= 0; i-- {
+		var expires time.Time
+		if entries[i].Expires != 0 {
+			expires = time.Unix(0, entries[i].Expires)
+			if !now.Before(expires) {
+				continue
+			}
+		}
+
+		cache.set(entries[i].Key, entries[i].Value, expires)
+	}
+
+	return nil
+}
+
+// StartPurging periodically removes the expired entries until the service is stopped, then saves the cache to
+// persistFile, when set
+func (cache *Cache) StartPurging(
+	appWg *sync.WaitGroup,
+	appCtx context.Context,
+	lc logger.LoggingClient,
+	persistFile string) {
+	defer appWg.Done()
+
+	for {
+		select {
+		case <-appCtx.Done():
+			if len(persistFile) > 0 {
+				if err := cache.Save(persistFile); err != nil {
+					lc.Errorf("Unable to persist cache: %s", err.Error())
+				} else {
+					lc.Infof("Cache persisted to %s", persistFile)
+				}
+			}
+			return
+
+		case <-time.After(purgeInterval):
+			if purged := cache.Purge(); purged > 0 {
+				lc.Debugf("Purged %d expired entries from the cache", purged)
+			}
+		}
+	}
+}
+
+func (entry *cacheEntry) expired(now time.Time) bool {
+	return !entry.expires.IsZero() && !now.Before(entry.expires)
+}
+
+func copyBytes(value []byte) []byte {
+	if value == nil {
+		return nil
+	}
+
+	return append([]byte{}, value...)
+}
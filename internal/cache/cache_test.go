@@ -0,0 +1,182 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+func newTestCache(maxEntries int, defaultTTL time.Duration) (*Cache, *time.Time) {
+	now := time.Unix(1600000000, 0)
+	target := NewCache(maxEntries, defaultTTL)
+	target.now = func() time.Time {
+		return now
+	}
+	return target, &now
+}
+
+func TestCacheSetGet(t *testing.T) {
+	target, _ := newTestCache(10, time.Minute)
+
+	value := []byte("value")
+	target.Set("key", value, 0)
+	value[0] = 'V'
+
+	actual, found := target.Get("key")
+	require.True(t, found)
+	assert.Equal(t, []byte("value"), actual, "stored value must be a copy")
+
+	actual[0] = 'V'
+	actual, _ = target.Get("key")
+	assert.Equal(t, []byte("value"), actual, "returned value must be a copy")
+
+	target.Set("key", []byte("updated"), 0)
+	actual, _ = target.Get("key")
+	assert.Equal(t, []byte("updated"), actual)
+	assert.Equal(t, 1, target.Len())
+
+	target.Delete("key")
+	_, found = target.Get("key")
+	assert.False(t, found)
+	assert.Equal(t, 0, target.Len())
+}
+
+func TestCacheExpiry(t *testing.T) {
+	target, now := newTestCache(10, time.Minute)
+
+	target.Set("default", []byte("1"), 0)
+	target.Set("short", []byte("2"), time.Second)
+	target.Set("forever", []byte("3"), -1)
+
+	*now = now.Add(2 * time.Second)
+	_, found := target.Get("short")
+	assert.False(t, found)
+	_, found = target.Get("default")
+	assert.True(t, found)
+
+	*now = now.Add(time.Hour)
+	_, found = target.Get("default")
+	assert.False(t, found)
+	_, found = target.Get("forever")
+	assert.True(t, found)
+}
+
+func TestCacheEviction(t *testing.T) {
+	target, _ := newTestCache(2, time.Minute)
+
+	target.Set("a", []byte("1"), 0)
+	target.Set("b", []byte("2"), 0)
+	// Using a makes b the least recently used
+	_, _ = target.Get("a")
+	target.Set("c", []byte("3"), 0)
+
+	assert.Equal(t, 2, target.Len())
+	_, found := target.Get("b")
+	assert.False(t, found)
+	_, found = target.Get("a")
+	assert.True(t, found)
+	_, found = target.Get("c")
+	assert.True(t, found)
+}
+
+func TestCachePurge(t *testing.T) {
+	target, now := newTestCache(10, time.Minute)
+
+	target.Set("a", []byte("1"), time.Second)
+	target.Set("b", []byte("2"), time.Hour)
+	target.Set("c", []byte("3"), time.Second)
+
+	*now = now.Add(time.Minute)
+	assert.Equal(t, 2, target.Purge())
+	assert.Equal(t, 1, target.Len())
+}
+
+func TestCacheSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	target, now := newTestCache(10, time.Minute)
+	target.Set("a", []byte("1"), time.Hour)
+	target.Set("b", []byte("2"), time.Second)
+	target.Set("c", []byte("3"), -1)
+	*now = now.Add(time.Minute)
+
+	require.NoError(t, target.Save(path))
+
+	loaded, loadedNow := newTestCache(2, time.Minute)
+	*loadedNow = *now
+	require.NoError(t, loaded.Load(path))
+
+	assert.Equal(t, 2, loaded.Len())
+	actual, found := loaded.Get("a")
+	require.True(t, found)
+	assert.Equal(t, []byte("1"), actual)
+	_, found = loaded.Get("b")
+	assert.False(t, found, "expired entry must not be loaded")
+
+	*loadedNow = loadedNow.Add(2 * time.Hour)
+	_, found = loaded.Get("a")
+	assert.False(t, found, "loaded entry must keep its expiry")
+	_, found = loaded.Get("c")
+	assert.True(t, found)
+
+	missing := NewCache(10, time.Minute)
+	assert.NoError(t, missing.Load(filepath.Join(t.TempDir(), "missing.json")))
+}
+
+func TestNewCacheFromConfig(t *testing.T) {
+	target, err := NewCacheFromConfig(common.CacheInfo{MaxEntries: 5, DefaultTTL: "10m"})
+	require.NoError(t, err)
+	assert.Equal(t, 5, target.maxEntries)
+	assert.Equal(t, 10*time.Minute, target.defaultTTL)
+
+	target, err = NewCacheFromConfig(common.CacheInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxEntries, target.maxEntries)
+	assert.Equal(t, DefaultTTL, target.defaultTTL)
+
+	_, err = NewCacheFromConfig(common.CacheInfo{DefaultTTL: "bogus"})
+	assert.Error(t, err)
+}
+
+func TestCacheStartPurgingPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	target := NewCache(10, time.Minute)
+	target.Set("key", []byte("value"), 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go target.StartPurging(wg, ctx, logger.NewMockClient(), path)
+	cancel()
+	wg.Wait()
+
+	loaded := NewCache(10, time.Minute)
+	require.NoError(t, loaded.Load(path))
+	actual, found := loaded.Get("key")
+	require.True(t, found)
+	assert.Equal(t, []byte("value"), actual)
+}
@@ -32,6 +32,13 @@ type WritableInfo struct {
 	Pipeline        PipelineInfo
 	StoreAndForward StoreAndForwardInfo
 	InsecureSecrets bootstrapConfig.InsecureSecrets
+	// DeviceStatistics contains the settings for tracking the messages received from each device
+	DeviceStatistics DeviceStatisticsInfo
+	// PayloadPreview contains the settings for keeping the payload most recently exported by the functions pipeline
+	PayloadPreview PayloadPreviewInfo
+	// ExecutionHistory contains the settings for publishing a sample of the functions pipelines' executions to the
+	// MessageBus
+	ExecutionHistory ExecutionHistoryInfo
 }
 
 // ConfigurationStruct
@@ -43,6 +50,9 @@ type ConfigurationStruct struct {
 	Registry bootstrapConfig.RegistryInfo
 	// Service contains the standard 'service' configuration for the Application service
 	Service bootstrapConfig.ServiceInfo
+	// Timezone is the IANA time zone, i.e. 'America/Chicago', used by the time based functions, such as when naming
+	// files and indexes by date, in place of UTC. UTC is used when empty.
+	Timezone string
 	// HttpServer contains the configuration for the HTTP Server
 	HttpServer HttpConfig
 	// Trigger contains the configuration for the Function Pipeline Trigger
@@ -55,23 +65,77 @@ type ConfigurationStruct struct {
 	Database db.DatabaseInfo
 	// SecretStore contains the configuration for connection to the Secret Store when in secure mode
 	SecretStore bootstrapConfig.SecretStoreInfo
+	// FaultInjection contains the configuration for injecting faults when testing the service's resilience.
+	// Must not be enabled in production.
+	FaultInjection FaultInjectionInfo
+	// Cache contains the configuration for the key-value cache shared by all executions of the functions pipeline
+	Cache CacheInfo
+	// Profiling contains the configuration for the endpoints profiling the service and its functions pipeline
+	Profiling ProfilingInfo
+	// Stream contains the configuration for the endpoint pushing the functions pipeline's output to WebSocket clients
+	Stream StreamInfo
+	// ServerSentEvents contains the configuration for the endpoint streaming each pipeline's response data to
+	// Server-Sent Events clients
+	ServerSentEvents ServerSentEventsInfo
+	// PipelineStatistics contains the configuration for persisting the lifetime totals of the messages processed by
+	// each functions pipeline
+	PipelineStatistics PipelineStatisticsInfo
+	// State contains the configuration for the persistent key-value state used by stateful functions
+	State StateInfo
+	// StartupDependencies contains the services and endpoints waited for before the trigger starts receiving messages
+	StartupDependencies StartupDependenciesInfo
 }
 
 // TriggerInfo contains Metadata associated with each Trigger
 type TriggerInfo struct {
 	// Type of trigger to start pipeline
-	// enum: http, edgex-messagebus, or external-mqtt
+	// enum: http, edgex-messagebus, external-mqtt, redis-pubsub, kafka, amqp, udp, serial, snmp or webhook
 	Type string
 	// Used when Type=edgex-messagebus
 	EdgexMessageBus MessageBusConfig
 	// Used when Type=external-mqtt
 	ExternalMqtt ExternalMqttConfig
+	// Used when Type=redis-pubsub
+	RedisPubSub RedisPubSubConfig
+	// Used when Type=kafka
+	Kafka KafkaConfig
+	// Used when Type=amqp
+	AMQP AMQPConfig
+	// Used when Type=udp
+	UDP UDPConfig
+	// Used when Type=serial
+	Serial SerialConfig
+	// Used when Type=snmp
+	SNMP SNMPConfig
+	// Used when Type=webhook
+	Webhook WebhookConfig
+	// Concurrency contains the settings controlling how many messages received by the trigger are processed concurrently
+	Concurrency ConcurrencyInfo
+}
+
+// ConcurrencyInfo contains the settings for the worker pool used to process messages received by the
+// edgex-messagebus, redis-pubsub, kafka, amqp, udp, serial, snmp and webhook triggers
+type ConcurrencyInfo struct {
+	// MaxWorkers is the maximum number of messages processed concurrently by the function pipeline.
+	// Zero (the default) processes each message in its own goroutine with no limit.
+	MaxWorkers int
+	// PreserveDeviceOrder indicates messages received on the same topic, which for EdgeX events contains the device
+	// name, are processed in the order received. Only applies when MaxWorkers is greater than zero.
+	PreserveDeviceOrder bool
+	// HighPriorityTopics is a comma separated list of the topics whose messages, i.e. alarms, are processed ahead of
+	// all other queued messages when the workers are busy. A topic ending in a '#' or '*' wildcard matches all the
+	// topics starting with the text before it, i.e. "edgex/events/device/alarms/#".
+	// Only applies when MaxWorkers is greater than zero.
+	HighPriorityTopics string
+	// LowPriorityTopics is a comma separated list of the topics whose messages, i.e. bulk exports, are only processed
+	// when no other messages are queued. Only applies when MaxWorkers is greater than zero.
+	LowPriorityTopics string
 }
 
 // HttpConfig contains the addition configuration for HTTP Server
 type HttpConfig struct {
 	// Protocol is the for the HTTP Server to use HTTP or HTTPS
-	// If HTTPS then the Secret store must contain the HTTPS cert and key
+	// If HTTPS then the Secret store must contain the PEM encoded HTTPS cert and key, which are reloaded when rotated
 	Protocol string
 	// SecretName is the name in the secret store for the HTTPS cert and key
 	SecretName string
@@ -93,6 +157,30 @@ type MessageBusConfig struct {
 	// Optional contains all other properties of MessageBus that is specific to
 	// certain concrete implementation like MQTT's QoS, for example
 	Optional map[string]string
+	// QueueSize is the number of messages received per subscribed topic that are held until they can be processed.
+	// Zero (the default) disables queuing, so receiving blocks until the previous message has been dispatched.
+	// When Trigger.Concurrency.MaxWorkers is zero each topic's queued messages are processed one at a time, otherwise
+	// they are held while all the workers are busy.
+	QueueSize int
+	// OverflowPolicy is the action taken when a message is received and the topic's queue is full.
+	// Options are "block" (default), "drop-oldest" and "drop-newest".
+	OverflowPolicy string
+	// Failover contains the settings for reconnecting to standby brokers when the connection to the MessageBus fails
+	Failover MessageBusFailoverInfo
+}
+
+// MessageBusFailoverInfo contains the settings for failing over to standby MessageBus brokers, i.e. Redis replicas
+type MessageBusFailoverInfo struct {
+	// Enabled indicates whether to reconnect when receiving from the MessageBus fails. The standby Hosts are tried in
+	// order, followed by the original broker, so a broker restart is also recovered from when no Hosts are set.
+	Enabled bool
+	// Hosts is a comma separated list of the host:port of the standby brokers. The PublishHost, when set, is
+	// switched to the same host and port as the SubscribeHost.
+	Hosts string
+	// ErrorThreshold is the number of consecutive receive errors after which the connection has failed. Defaults to 3.
+	ErrorThreshold int
+	// RetryInterval is the minimum duration between failovers. Defaults to 5s.
+	RetryInterval string
 }
 
 // SubscribeHostInfo is the host information for connecting and subscribing to the MessageBus
@@ -125,6 +213,13 @@ type ExternalMqttConfig struct {
 	Url string
 	// SubscribeTopics is a comma separated list of topics in which to subscribe
 	SubscribeTopics string
+	// SubscribeQoS contains the QoS, by topic, for the SubscribeTopics which don't use QoS,
+	// i.e. { "alarms/#" = 2 }. Each topic must be in SubscribeTopics.
+	SubscribeQoS map[string]byte
+	// SharedSubscriptionGroup, when set, subscribes to each topic as a shared subscription, i.e.
+	// $share/<SharedSubscriptionGroup>/<topic>, so that messages are load balanced across all
+	// service instances in the group. Requires a broker which supports shared subscriptions.
+	SharedSubscriptionGroup string
 	// PublishTopic is the topic to publish pipeline output (if any)
 	PublishTopic string
 	// ClientId to connect to the broker with.
@@ -146,23 +241,433 @@ type ExternalMqttConfig struct {
 	// AuthMode indicates what to use when connecting to the broker. Options are "none", "cacert" , "usernamepassword", "clientcert".
 	// If a CA Cert exists in the SecretPath then it will be used for all modes except "none".
 	AuthMode string
+	// Mappings contains the mappings, by name, which convert the JSON messages received on matching topics into EdgeX
+	// Events, so data from third-party devices enters the functions pipeline as if sent by a device service.
+	// Messages received on topics without a mapping are passed to the pipeline unchanged.
+	Mappings map[string]TopicMapping
+}
+
+// TopicMapping maps the JSON object messages received on an MQTT topic to EdgeX Events. The names may contain the
+// placeholders {1}, {2}, etc., which are replaced with that level of the received topic, i.e. DeviceName "{2}" for
+// the topic "factory/press-01/telemetry" is "press-01".
+type TopicMapping struct {
+	// Topic is the topic filter, which may contain the + and # wildcards, the mapping applies to. When several
+	// mappings match a topic the first by name is used.
+	Topic string
+	// DeviceName is the Event's device name
+	DeviceName string
+	// ProfileName is the Event's profile name
+	ProfileName string
+	// SourceName is the Event's source name. Defaults to the mapping's name.
+	SourceName string
+	// Resources maps the message's field names to the resource names of the Event's readings. Nested fields are
+	// named by their path, i.e. "env.temp". Fields without a mapping are ignored. When empty, each top level field
+	// is a reading named as the field.
+	Resources map[string]string
+}
+
+// RedisPubSubConfig contains the Redis server configuration for the Redis Pub/Sub Trigger
+type RedisPubSubConfig struct {
+	// Host is the hostname or IP address of the Redis server
+	Host string
+	// Port is the port of the Redis server
+	Port int
+	// SubscribePatterns is a comma separated list of glob-style channel patterns in which to subscribe, i.e. edgex.events.*
+	SubscribePatterns string
+	// PublishChannel is the channel to publish pipeline output (if any)
+	PublishChannel string
+	// ConnectTimeout is a time duration indicating how long to wait timing out on the server connection
+	ConnectTimeout string
+	// SecretPath is the name of the path in secret provider to retrieve your secrets
+	SecretPath string
+	// AuthMode indicates what to use when connecting to the server. Options are "none" and "usernamepassword".
+	// The username secret is optional for "usernamepassword", in which case only the password is used to authenticate.
+	AuthMode string
+}
+
+// KafkaConfig contains the Kafka cluster configuration for the Kafka Trigger
+type KafkaConfig struct {
+	// Brokers is a comma separated list of the addresses of the Kafka brokers, i.e. localhost:9092
+	Brokers string
+	// GroupId is the consumer group the trigger joins, which shares the partitions of the topics between the instances
+	// of the service and stores their committed offsets
+	GroupId string
+	// SubscribeTopics is a comma separated list of topics in which to subscribe
+	SubscribeTopics string
+	// PublishTopic is the topic to publish pipeline output (if any)
+	PublishTopic string
+	// ConnectTimeout is a time duration indicating how long to wait timing out on the broker connection
+	ConnectTimeout string
+	// UseTLS indicates the connection to the brokers uses TLS, which is always the case for the "clientcert" and
+	// "cacert" AuthModes
+	UseTLS bool
+	// SkipCertVerify indicates if the certificate verification should be skipped
+	SkipCertVerify bool
+	// SecretPath is the name of the path in secret provider to retrieve your secrets
+	SecretPath string
+	// AuthMode indicates what to use when connecting to the brokers. Options are "none", "cacert" , "usernamepassword", "clientcert".
+	// If a CA Cert exists in the SecretPath then it will be used for all modes except "none".
+	AuthMode string
+	// SaslMechanism is the SASL mechanism used to authenticate with the "usernamepassword" AuthMode. Options are
+	// "plain", the default, "scram-sha-256" and "scram-sha-512".
+	SaslMechanism string
+}
+
+// AMQPConfig contains the broker configuration for the AMQP Trigger
+type AMQPConfig struct {
+	// Url is the address of the AMQP 0-9-1 broker, i.e. amqp://localhost:5672/ or amqps://localhost:5671/ for TLS.
+	// The path, when present, is the virtual host.
+	Url string
+	// Queue is the name of the existing queue to consume messages from
+	Queue string
+	// Prefetch is the maximum number of unacknowledged messages the broker delivers at a time. Zero is unlimited.
+	Prefetch int
+	// PublishExchange is the exchange to publish pipeline output (if any). Empty is the default exchange.
+	PublishExchange string
+	// PublishRoutingKey is the routing key to publish pipeline output with. Output is only published when set.
+	PublishRoutingKey string
+	// ConnectTimeout is a time duration indicating how long to wait timing out on the broker connection
+	ConnectTimeout string
+	// SkipCertVerify indicates whether to skip verification of the broker's TLS certificate
+	SkipCertVerify bool
+	// SecretPath is the name of the path in secret provider to retrieve your secrets
+	SecretPath string
+	// AuthMode indicates what to use when connecting to the broker. Options are "none", "cacert" , "usernamepassword", "clientcert".
+	// If a CA Cert exists in the SecretPath then it will be used for all modes except "none".
+	AuthMode string
+}
+
+// UDPConfig contains the listener configuration for the UDP Trigger
+type UDPConfig struct {
+	// Host is the address to listen on. Empty listens on all addresses.
+	Host string
+	// Port is the UDP port to listen on
+	Port int
+	// MaxDatagramSize is the size in bytes of the largest datagram accepted. Larger datagrams are truncated.
+	// Defaults to 65507, the largest UDP payload over IPv4.
+	MaxDatagramSize int
+	// Format indicates how datagrams are parsed before being passed to the pipeline.
+	// Options are "raw" (default), "syslog" and "statsd". Parsed datagrams are passed as JSON.
+	Format string
+}
+
+// SerialConfig contains the serial port configuration for the Serial Trigger
+type SerialConfig struct {
+	// Device is the path of the serial port device, i.e. /dev/ttyUSB0
+	Device string
+	// BaudRate is the speed of the serial port. Defaults to 9600.
+	BaudRate int
+	// DataBits is the number of data bits per character, 5 to 8. Defaults to 8.
+	DataBits int
+	// Parity is the parity checking used. Options are "none" (default), "odd" and "even".
+	Parity string
+	// StopBits is the number of stop bits, 1 (default) or 2
+	StopBits int
+	// Delimiter marks the end of each frame and is removed from the data passed to the pipeline.
+	// Escape sequences such as \r\n or \x03 may be used. Defaults to \n unless FrameLength is set.
+	Delimiter string
+	// FrameLength is the size in bytes of fixed length frames. Can not be used with Delimiter.
+	FrameLength int
+	// MaxFrameSize is the size in bytes of the largest delimited frame accepted. Defaults to 65536.
+	MaxFrameSize int
+}
+
+// SNMPConfig contains the listener configuration for the SNMP Trigger
+type SNMPConfig struct {
+	// Host is the address to listen on for traps. Empty listens on all addresses.
+	Host string
+	// Port is the UDP port to listen on for traps. Defaults to 162.
+	Port int
+	// SecretPath is the name of the path in secret provider to retrieve your secrets.
+	// The "community" secret is required to match for SNMPv2c traps, when present.
+	// The "username" secret, with the optional "authpassword" and "privpassword" secrets, is required to accept SNMPv3 traps.
+	// Traps must use the security level implied by the passwords present.
+	SecretPath string
+	// AuthProtocol is the SNMPv3 authentication protocol. Options are "MD5", "SHA" (default) and "SHA256".
+	AuthProtocol string
+	// PrivProtocol is the SNMPv3 privacy protocol. Options are "DES" and "AES" (default), which is AES-128.
+	PrivProtocol string
+}
+
+// WebhookConfig contains the configuration for the Webhook Trigger
+type WebhookConfig struct {
+	// Route is the path webhooks are received on. Defaults to /api/v2/webhook.
+	Route string
+	// Scheme is the signature validation scheme. Options are "none", "github", "stripe" and "hmac".
+	// The "hmac" scheme validates a hex encoded HMAC-SHA256 of the body sent in SignatureHeader.
+	Scheme string
+	// SecretPath is the name of the path in secret provider containing the "secret" used to validate signatures
+	SecretPath string
+	// SignatureHeader is the header containing the signature for the "hmac" scheme
+	SignatureHeader string
+	// SignaturePrefix is removed from the signature for the "hmac" scheme, i.e. sha256=
+	SignaturePrefix string
+	// TimestampHeader, when set for the "hmac" scheme, is the header containing the Unix time the webhook was sent.
+	// The signature is then of the timestamp and body joined by a '.', as used by the "stripe" scheme.
+	TimestampHeader string
+	// Tolerance is how long a signature is accepted for. Timestamps older than this are rejected and signatures
+	// already received within this duration are rejected as replays. Defaults to 5m.
+	Tolerance string
+	// MaxBodySize is the largest body in bytes accepted. Defaults to 1048576.
+	MaxBodySize int64
 }
 
 type PipelineInfo struct {
 	ExecutionOrder           string
 	UseTargetTypeOfByteArray bool
 	Functions                map[string]PipelineFunction
+	// Tests contains the sample inputs and expected outcomes run against the pipeline when the service
+	// is started with the --test-pipelines flag
+	Tests map[string]PipelineTest
+	// SLA contains the Service Level Objectives the functions pipeline is tracked against
+	SLA SLAInfo
+	// Sampling contains the settings for processing only a subset of the messages received
+	Sampling SamplingInfo
+	// Timeout is the maximum time, i.e. '30s', the functions pipeline may take to process a message, after which the
+	// context returned by the function context's Context() is done and no further functions are executed.
+	// Messages have no deadline when empty.
+	Timeout string
+	// Offline contains the settings for routing the data through an alternate local pipeline while the exports of the
+	// functions pipeline are failing
+	Offline OfflinePipelineInfo
+	// ResponseTopic overrides the trigger's publish topic, i.e. PublishHost.PublishTopic, for the response data of the
+	// functions pipeline and may contain placeholders for the context values, i.e. 'results/{devicename}'
+	ResponseTopic string
+}
+
+// OfflinePipelineInfo contains the settings for routing the data through an alternate local pipeline, i.e. exporting
+// to a local file and alerting locally, while the exports of the functions pipeline are failing, such as when the
+// cloud is unreachable, and back once they have recovered
+type OfflinePipelineInfo struct {
+	// ExecutionOrder is the comma separated list of the offline pipeline's functions, whose parameters are in the
+	// Pipeline's Functions along with those of the functions pipeline. Offline mode is disabled when empty.
+	ExecutionOrder string
+	// FailureThreshold is the number of consecutive messages failing with a retryable error, i.e. an export unable
+	// to connect, after which the data is routed through the offline pipeline. Defaults to 3.
+	FailureThreshold int
+	// RecoveryInterval is how long, i.e. '30s', the data is routed through the offline pipeline before a message is
+	// processed by the functions pipeline again to check whether its exports have recovered. Defaults to 30s.
+	RecoveryInterval string
+}
+
+// SamplingInfo contains the settings for sampling the messages processed by the functions pipeline, so that expensive
+// pipelines, i.e. ML inference, can run on a representative subset of the messages received
+type SamplingInfo struct {
+	// Mode is 'Count' to process 1 of every Count messages or 'Percentage' to process a random Percentage of the
+	// messages. Sampling is disabled when empty.
+	Mode string
+	// Count is the number of messages, of which 1 is processed, for the Count mode
+	Count int
+	// Percentage is the percentage, from 0 to 100, of messages processed for the Percentage mode
+	Percentage float64
+	// PerDevice counts the Events from each device separately for the Count mode, so every device is represented
+	PerDevice bool
+}
+
+// SLAInfo contains the Service Level Objectives for delivery success rate and latency of the functions pipeline,
+// measured over a rolling window
+type SLAInfo struct {
+	// Window is the rolling window, i.e. '1h', the objectives are measured over. Tracking is disabled when empty.
+	Window string
+	// SuccessRateTarget is the minimum fraction, from 0 to 1, of messages which must be processed without error
+	SuccessRateTarget float64
+	// LatencyTarget is the maximum time, i.e. '500ms', to process a message from when it is received by the service
+	LatencyTarget string
+	// LatencyPercentile is the minimum fraction, from 0 to 1, of messages which must be processed within LatencyTarget
+	LatencyPercentile float64
+}
+
+// DeviceStatisticsInfo contains the settings for tracking the count, rate and last-seen time of the Events received from
+// each device and the message rates which are alerted on
+type DeviceStatisticsInfo struct {
+	// Enabled turns on tracking of the Events received from each device
+	Enabled bool
+	// RateWindow is the rolling window, i.e. '1m', message rates are measured over. Defaults to 1m.
+	RateWindow string
+	// MinRate is the minimum rate, in messages per second, expected from each device. A device whose rate drops
+	// below it, including one that stops sending, is alerted on. Zero disables the check.
+	MinRate float64
+	// MaxRate is the maximum rate, in messages per second, expected from each device. A device whose rate exceeds
+	// it is alerted on. Zero disables the check.
+	MaxRate float64
+	// Devices contains the rates for individual devices, by device name, which override MinRate and MaxRate
+	Devices map[string]DeviceRateInfo
+}
+
+// DeviceRateInfo contains the message rates expected from an individual device
+type DeviceRateInfo struct {
+	// MinRate is the minimum rate, in messages per second, expected from the device. Zero disables the check.
+	MinRate float64
+	// MaxRate is the maximum rate, in messages per second, expected from the device. Zero disables the check.
+	MaxRate float64
 }
 
 type PipelineFunction struct {
 	// Name	string
 	Parameters map[string]string
+	// Disabled skips the function, which passes the data it receives through to the next function, without removing
+	// it from the ExecutionOrder. Being Writable, functions can be toggled at runtime, i.e. per site.
+	Disabled bool
+}
+
+// PipelineTest contains a sample input for the function pipeline and the outcome expected from it
+type PipelineTest struct {
+	// Input is the payload passed to the pipeline as if it had been received by the trigger
+	Input string
+	// ContentType is the content type of Input. Defaults to application/json
+	ContentType string
+	// ExpectedOutput is the data expected to reach the end of the pipeline. JSON is compared by value rather
+	// than by text. Leave empty to not check the output.
+	ExpectedOutput string
+	// ExpectFiltered indicates the pipeline is expected to stop before the end without an error
+	ExpectFiltered bool
+	// ExpectedError is text the error returned by the pipeline is expected to contain
+	ExpectedError string
 }
 
 type StoreAndForwardInfo struct {
 	Enabled       bool
 	RetryInterval string
 	MaxRetryCount int
+	// Ordered retries the stored data strictly in the order it was stored and stops each retry pass at the first
+	// failure, so no data is exported ahead of data that arrived before it.
+	Ordered bool
+	// MaxRetryBackoff is the maximum delay between retries of a stored item. Each failed retry of an item doubles its
+	// delay, starting at RetryInterval, so a failing destination isn't retried as often as healthy ones.
+	// Empty disables backoff so all stored items are retried every RetryInterval.
+	MaxRetryBackoff string
+	// EncryptionSecretPath and EncryptionSecretName locate the key in the Secret Store used to encrypt the stored
+	// data. Stored data is not encrypted when these are empty.
+	EncryptionSecretPath string
+	EncryptionSecretName string
+	// MaxQueueSize and MaxQueueBytes limit the number of items and the total bytes stored, counting both the payload
+	// and the pipeline input of each item. Zero is unlimited.
+	MaxQueueSize  int
+	MaxQueueBytes int64
+	// EvictionPolicy is applied when storing an item would exceed the limits. Valid values are DropOldest (default),
+	// DropNewest and Block, which waits until retries have made room in the queue.
+	EvictionPolicy string
+}
+
+// FaultInjectionInfo contains the configuration for injecting random failures and latency into export functions and
+// MessageBus publishes, allowing Store and Forward and other error handling to be tested under controlled conditions.
+type FaultInjectionInfo struct {
+	Enabled bool
+	// Functions is the list of pipeline function names faults are injected into, i.e. HTTPPost.
+	// Defaults to the SDK's export functions when empty.
+	Functions []string
+	// FailureRate is the probability, from 0 to 1, of a function failing
+	FailureRate float64
+	// LatencyRate is the probability, from 0 to 1, of Latency being added before a function executes
+	LatencyRate float64
+	Latency     string
+	// PublishFailureRate is the probability, from 0 to 1, of a MessageBus publish failing
+	PublishFailureRate float64
+}
+
+// CacheInfo contains the configuration for the key-value cache returned by the context's Cache function
+type CacheInfo struct {
+	// MaxEntries is the maximum number of entries held. The least recently used entry is evicted when the cache is
+	// full. Defaults to 10000.
+	MaxEntries int
+	// DefaultTTL is how long entries stored without their own time to live are kept, i.e. '10m'. Defaults to 1h.
+	DefaultTTL string
+	// PersistFile, when set, is the file the cache is saved to when the service stops and loaded from when it starts,
+	// so cached data survives restarts. The cache is only held in memory when empty.
+	PersistFile string
+}
+
+// PayloadPreviewInfo contains the settings for keeping the serialized payload most recently passed to the last
+// function of the functions pipeline, i.e. the export, which is returned by the /pipelines/{id}/preview endpoint
+type PayloadPreviewInfo struct {
+	// Enabled turns on keeping the payload preview. Off by default as each exported payload is copied.
+	Enabled bool
+	// MaxPayloadSize is the most bytes of the payload kept, beyond which it is truncated. Defaults to 65536.
+	MaxPayloadSize int
+}
+
+// ExecutionHistoryInfo contains the settings for publishing a record of every Nth execution of the functions pipelines,
+// with its pipeline id, duration, outcome and payload sizes, to the MessageBus so a central monitoring service can
+// aggregate the behavior of the pipelines. Requires the edgex-messagebus trigger.
+type ExecutionHistoryInfo struct {
+	// SampleEvery is N, so 1 of every N executions is published. Zero disables publishing.
+	SampleEvery int
+	// PublishTopic is the MessageBus topic the records are published to. Defaults to
+	// 'edgex/telemetry/pipeline-executions'.
+	PublishTopic string
+}
+
+// ProfilingInfo contains the configuration for the Go runtime's pprof endpoints and the endpoint profiling the next
+// executions of the functions pipeline, which are only registered when enabled.
+type ProfilingInfo struct {
+	Enabled bool
+	// SecretPath is the name of the path in secret provider containing the "token" which requests must send as a
+	// Bearer token in the Authorization header. Required when enabled.
+	SecretPath string
+	// MaxDuration is the longest a profile of the functions pipeline waits for the executions requested. Defaults to 60s.
+	MaxDuration string
+}
+
+// StreamInfo contains the configuration for the /stream endpoint, which pushes the data published by the StreamExport
+// pipeline function to the connected WebSocket clients. The endpoint is only registered when enabled.
+type StreamInfo struct {
+	Enabled bool
+	// MaxClients is the most WebSocket clients which can be connected at once. Defaults to 10.
+	MaxClients int
+	// ClientBufferSize is the number of messages buffered for each client, beyond which messages are dropped for
+	// clients too slow to receive them. Defaults to 100.
+	ClientBufferSize int
+	// AllowedOrigins are the origins of the web pages allowed to connect, i.e. http://dashboard:3000, or "*" to allow
+	// any origin. Connections from pages on other origins than the service's are rejected when empty.
+	AllowedOrigins []string
+}
+
+// ServerSentEventsInfo contains the configuration for the /pipelines/{id}/events endpoint, which streams the response
+// data of each execution of the pipeline to the subscribed HTTP clients as Server-Sent Events. The endpoint is only
+// registered when enabled.
+type ServerSentEventsInfo struct {
+	Enabled bool
+	// MaxClients is the most clients which can subscribe to each pipeline at once. Defaults to 10.
+	MaxClients int
+	// ClientBufferSize is the number of events buffered for each client, beyond which events are dropped for clients
+	// too slow to receive them. Defaults to 100.
+	ClientBufferSize int
+	// KeepAliveInterval is how often a comment is sent to idle clients, so proxies don't close the connection.
+	// Defaults to 15s.
+	KeepAliveInterval string
+}
+
+// PipelineStatisticsInfo contains the configuration for persisting the lifetime totals of the messages processed,
+// exported, failed and filtered by each functions pipeline
+type PipelineStatisticsInfo struct {
+	// Persist turns on saving the totals to the Database, which is then used even when Store and Forward is disabled,
+	// and restoring them on startup so they aren't reset when the service restarts
+	Persist bool
+	// PersistInterval is how often, i.e. '30s', the totals are saved while they're changing. They are also saved
+	// when the service stops. Defaults to 30s.
+	PersistInterval string
+}
+
+// StateInfo contains the configuration for the key-value state returned by the context's State function
+type StateInfo struct {
+	// Enabled turns on the persistent state, which is stored in the Database, so it is then used even when Store and
+	// Forward is disabled
+	Enabled bool
+}
+
+// StartupDependenciesInfo contains the services and endpoints which must be available before the trigger starts
+// receiving messages, so a cold start of the whole stack doesn't cause a burst of failures and Store and Forward retries
+type StartupDependenciesInfo struct {
+	// Services is a comma separated list of the keys of the Clients, i.e. "core-data", whose ping endpoint must respond
+	Services string
+	// Endpoints is a comma separated list of the addresses which must be reachable. HTTP and HTTPS URLs must respond
+	// to a GET with a 2xx status, i.e. a destination's health endpoint. Other URLs, i.e. "tcp://broker:1883", must
+	// accept a TCP connection.
+	Endpoints string
+	// Timeout is how long, i.e. '2m', to wait for the dependencies before the service fails to start. Defaults to 60s.
+	Timeout string
+	// Interval is how long, i.e. '5s', to wait between checks of the dependencies not yet available. Defaults to 1s.
+	Interval string
 }
 
 // Credentials encapsulates username-password attributes.
@@ -23,8 +23,35 @@ import (
 const (
 	ConfigRegistryStem = "edgex/appservices/"
 
+	// SignatureHeader is the HTTP trigger request header containing the signature of the request body
+	SignatureHeader = "X-Signature"
+
 	ApiTriggerRoute   = common.ApiBase + "/trigger"
 	ApiAddSecretRoute = common.ApiBase + "/secret"
+	ApiWebhookRoute   = common.ApiBase + "/webhook"
+	ApiSLARoute       = common.ApiBase + "/sla"
+
+	ApiDeviceStatsRoute = common.ApiBase + "/device/stats"
+
+	ApiPipelineSnapshotRoute    = common.ApiBase + "/pipeline/snapshot"
+	ApiPipelineFunctionsRoute   = common.ApiBase + "/pipeline/functions"
+	ApiPipelineStatsRoute       = common.ApiBase + "/pipeline/stats"
+	ApiPipelineFlushRoute       = common.ApiBase + "/pipeline/flush"
+	ApiPipelineFlushByNameRoute = ApiPipelineFlushRoute + "/{" + common.Name + "}"
+	ApiPipelineTestRoute        = common.ApiBase + "/pipeline/test"
+
+	ApiPipelinesRoute              = common.ApiBase + "/pipelines"
+	ApiPipelineByIdRoute           = ApiPipelinesRoute + "/{" + common.Id + "}"
+	ApiPipelineFunctionByNameRoute = ApiPipelineByIdRoute + "/functions/{" + common.Name + "}"
+	ApiPipelinePreviewRoute        = ApiPipelineByIdRoute + "/preview"
+	ApiPipelineEventsRoute         = ApiPipelineByIdRoute + "/events"
+
+	ApiStoreReplayRoute = common.ApiBase + "/store/replay"
+
+	ApiStreamRoute = common.ApiBase + "/stream"
+
+	ApiProfilePipelineRoute = common.ApiBase + "/profile/pipeline"
+	ApiDebugPprofRoute      = "/debug/pprof/"
 )
 
 // SDKVersion indicates the version of the SDK - will be overwritten by build
@@ -17,9 +17,12 @@
 package rest
 
 import (
+	"bytes"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
@@ -28,7 +31,9 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
 	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/stream"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
+	sdkInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
@@ -44,6 +49,81 @@ type Controller struct {
 	secretProvider interfaces.SecretProvider
 	lc             logger.LoggingClient
 	config         *sdkCommon.ConfigurationStruct
+	slaTracker     *telemetry.SLATracker
+	deviceStats    *telemetry.DeviceStatsTracker
+	pipelineStats  *telemetry.PipelineStatsTracker
+	triggerStats   *telemetry.TriggerStatsTracker
+	profiler       *telemetry.PipelineProfiler
+	previews       *telemetry.PayloadPreviewTracker
+	streamHub      *stream.Hub
+	pipelineHubs   *stream.PipelineHubs
+	appService     sdkInterfaces.ApplicationService
+	// pipelineLock serializes the changes made to the pipeline through the /pipelines endpoints
+	pipelineLock sync.Mutex
+}
+
+// SLAResponse defines the content of the response to the /sla endpoint
+type SLAResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	SLA                     telemetry.SLAReport `json:"sla"`
+}
+
+// DeviceStatsResponse defines the content of the response to the /device/stats endpoint
+type DeviceStatsResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	DeviceStats             telemetry.DeviceStatsReport `json:"deviceStats"`
+}
+
+// MetricsResponse defines the content of the response to the /metrics endpoint, the standard metrics along with the
+// lifetime totals of the messages processed by each functions pipeline
+type MetricsResponse struct {
+	commonDtos.MetricsResponse `json:",inline"`
+	PipelineStatistics         map[string]telemetry.PipelineStats `json:"pipelineStatistics"`
+	TriggerStatistics          telemetry.TriggerStats             `json:"triggerStatistics"`
+}
+
+// PipelineStatsResponse defines the content of the response to the /pipeline/stats endpoint
+type PipelineStatsResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	// PipelineStatistics are the lifetime totals of the messages processed by each functions pipeline, by pipeline id
+	PipelineStatistics map[string]telemetry.PipelineStats `json:"pipelineStatistics"`
+}
+
+// FlushResponse defines the content of the response to the /pipeline/flush endpoint
+type FlushResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	// Flushed is how many of the pipeline functions, such as batches, had accumulated data to flush
+	Flushed int `json:"flushed"`
+}
+
+// PipelineSnapshotResponse defines the content of the response to a GET of the /pipeline/snapshot endpoint
+type PipelineSnapshotResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	Snapshot                sdkInterfaces.PipelineSnapshot `json:"snapshot"`
+}
+
+// ConfigurableFunctionsResponse defines the content of the response to a GET of the /pipeline/functions endpoint
+type ConfigurableFunctionsResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	Functions               []sdkInterfaces.ConfigurableFunction `json:"functions"`
+}
+
+// PipelineSnapshotRequest defines the content of a POST to the /pipeline/snapshot endpoint
+type PipelineSnapshotRequest struct {
+	commonDtos.BaseRequest `json:",inline"`
+	Snapshot               sdkInterfaces.PipelineSnapshot `json:"snapshot"`
+}
+
+// StoreReplayRequest defines the content of a POST to the /store/replay endpoint
+type StoreReplayRequest struct {
+	commonDtos.BaseRequest `json:",inline"`
+	Filter                 sdkInterfaces.StoreReplayFilter `json:"filter"`
+}
+
+// StoreReplayResponse defines the content of the response to the /store/replay endpoint
+type StoreReplayResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	Result                  sdkInterfaces.StoreReplayResult `json:"result"`
 }
 
 // NewController creates and initializes an Controller
@@ -53,6 +133,15 @@ func NewController(router *mux.Router, dic *di.Container) *Controller {
 		secretProvider: bootstrapContainer.SecretProviderFrom(dic.Get),
 		lc:             bootstrapContainer.LoggingClientFrom(dic.Get),
 		config:         container.ConfigurationFrom(dic.Get),
+		slaTracker:     container.SLATrackerFrom(dic.Get),
+		deviceStats:    container.DeviceStatsTrackerFrom(dic.Get),
+		pipelineStats:  container.PipelineStatsTrackerFrom(dic.Get),
+		triggerStats:   container.TriggerStatsTrackerFrom(dic.Get),
+		profiler:       container.PipelineProfilerFrom(dic.Get),
+		previews:       container.PayloadPreviewTrackerFrom(dic.Get),
+		streamHub:      container.StreamHubFrom(dic.Get),
+		pipelineHubs:   container.PipelineHubsFrom(dic.Get),
+		appService:     container.ApplicationServiceFrom(dic.Get),
 	}
 }
 
@@ -77,7 +166,8 @@ func (c *Controller) Config(writer http.ResponseWriter, request *http.Request) {
 	c.sendResponse(writer, request, common.ApiVersionRoute, response, http.StatusOK)
 }
 
-// Metrics handles the request to the /metrics endpoint, memory and cpu utilization stats
+// Metrics handles the request to the /metrics endpoint, memory and cpu utilization stats along with the lifetime
+// totals of the messages processed by each functions pipeline and of those dropped by the trigger
 // It returns a response as specified by the V2 API swagger in openapi/v2
 func (c *Controller) Metrics(writer http.ResponseWriter, request *http.Request) {
 	t := telemetry.NewSystemUsage()
@@ -91,33 +181,264 @@ func (c *Controller) Metrics(writer http.ResponseWriter, request *http.Request)
 		CpuBusyAvg:     uint8(t.CpuBusyAvg),
 	}
 
-	response := commonDtos.NewMetricsResponse(metrics)
+	response := MetricsResponse{
+		MetricsResponse:    commonDtos.NewMetricsResponse(metrics),
+		PipelineStatistics: c.pipelineStatsReport(),
+	}
+	if c.triggerStats != nil {
+		response.TriggerStatistics = c.triggerStats.Report()
+	}
 	c.sendResponse(writer, request, common.ApiMetricsRoute, response, http.StatusOK)
 }
 
-// AddSecret handles the request to add App Service exclusive secret to the Secret Store
+// PipelineStats handles the request to the /pipeline/stats endpoint, the lifetime totals of the messages processed,
+// exported, failed and filtered by each functions pipeline, which include those of previous runs of the service when
+// PipelineStatistics.Persist is set
+func (c *Controller) PipelineStats(writer http.ResponseWriter, request *http.Request) {
+	response := PipelineStatsResponse{
+		BaseResponse:       commonDtos.NewBaseResponse("", "", http.StatusOK),
+		PipelineStatistics: c.pipelineStatsReport(),
+	}
+	c.sendResponse(writer, request, internal.ApiPipelineStatsRoute, response, http.StatusOK)
+}
+
+func (c *Controller) pipelineStatsReport() map[string]telemetry.PipelineStats {
+	if c.pipelineStats == nil {
+		return map[string]telemetry.PipelineStats{}
+	}
+
+	return c.pipelineStats.Report()
+}
+
+// SLA handles the request to the /sla endpoint, the functions pipeline's compliance with its Service Level Objectives
+// over the configured rolling window
+func (c *Controller) SLA(writer http.ResponseWriter, request *http.Request) {
+	tracker := c.slaTracker
+	if tracker == nil {
+		tracker = telemetry.NewSLATracker()
+	}
+
+	response := SLAResponse{
+		BaseResponse: commonDtos.NewBaseResponse("", "", http.StatusOK),
+		SLA:          tracker.Report(c.config.Writable.Pipeline.SLA),
+	}
+	c.sendResponse(writer, request, internal.ApiSLARoute, response, http.StatusOK)
+}
+
+// DeviceStats handles the request to the /device/stats endpoint, the count, rate and last-seen time of the Events
+// received from each device along with any rate alerts
+func (c *Controller) DeviceStats(writer http.ResponseWriter, request *http.Request) {
+	tracker := c.deviceStats
+	if tracker == nil {
+		tracker = telemetry.NewDeviceStatsTracker()
+	}
+
+	response := DeviceStatsResponse{
+		BaseResponse: commonDtos.NewBaseResponse("", "", http.StatusOK),
+		DeviceStats:  tracker.Report(c.config.Writable.DeviceStatistics),
+	}
+	c.sendResponse(writer, request, internal.ApiDeviceStatsRoute, response, http.StatusOK)
+}
+
+// AddSecret handles the request to add App Service exclusive secrets to the Secret Store. The request body is either
+// a single secret request or, to provision many paths in one call, an array of them. Each secret request of an
+// array is validated and stored independently and the per-path results are returned with the 207 status.
 // It returns a response as specified by the V2 API swagger in openapi/v2
 func (c *Controller) AddSecret(writer http.ResponseWriter, request *http.Request) {
 	defer func() {
 		_ = request.Body.Close()
 	}()
 
-	secretRequest := commonDtos.SecretRequest{}
-	err := json.NewDecoder(request.Body).Decode(&secretRequest)
+	body, err := ioutil.ReadAll(request.Body)
 	if err != nil {
+		c.sendError(writer, request, errors.KindIOError, "Reading request body failed", err, "")
+		return
+	}
+
+	if !isJSONArray(body) {
+		response := c.storeSecret(body)
+		c.sendResponse(writer, request, internal.ApiAddSecretRoute, response, response.StatusCode)
+		return
+	}
+
+	var secretRequests []json.RawMessage
+	if err := json.Unmarshal(body, &secretRequests); err != nil {
 		c.sendError(writer, request, errors.KindContractInvalid, "JSON decode failed", err, "")
 		return
 	}
 
+	if len(secretRequests) == 0 {
+		c.sendError(writer, request, errors.KindContractInvalid, "No secret requests received", nil, "")
+		return
+	}
+
+	responses := make([]commonDtos.BaseResponse, len(secretRequests))
+	for index, secretRequest := range secretRequests {
+		responses[index] = c.storeSecret(secretRequest)
+	}
+
+	c.sendResponse(writer, request, internal.ApiAddSecretRoute, responses, http.StatusMultiStatus)
+}
+
+// storeSecret validates the secret request, including that it has the keys required by the functions pipeline
+// for its path, and stores it in the Secret Store, returning the response for the request
+func (c *Controller) storeSecret(data []byte) commonDtos.BaseResponse {
+	secretRequest := commonDtos.SecretRequest{}
+	if err := json.Unmarshal(data, &secretRequest); err != nil {
+		return c.errorResponse(errors.KindContractInvalid, "JSON decode failed", err, "")
+	}
+
 	path, secret := c.prepareSecret(secretRequest)
 
+	if err := validateRequiredSecretKeys(c.config.Writable.Pipeline, path, secret); err != nil {
+		return c.errorResponse(errors.KindContractInvalid, "Secret validation failed", err, secretRequest.RequestId)
+	}
+
 	if err := c.secretProvider.StoreSecret(path, secret); err != nil {
-		c.sendError(writer, request, errors.KindServerError, "Storing secret failed", err, secretRequest.RequestId)
+		return c.errorResponse(errors.KindServerError, "Storing secret failed", err, secretRequest.RequestId)
+	}
+
+	return commonDtos.NewBaseResponse(secretRequest.RequestId, "", http.StatusCreated)
+}
+
+// ExportPipelineSnapshot handles the request to export the effective configurable functions pipeline as a portable
+// snapshot document, with secret parameter values redacted
+func (c *Controller) ExportPipelineSnapshot(writer http.ResponseWriter, request *http.Request) {
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Pipeline snapshots not available", nil, "")
+		return
+	}
+
+	response := PipelineSnapshotResponse{
+		BaseResponse: commonDtos.NewBaseResponse("", "", http.StatusOK),
+		Snapshot:     c.appService.ExportPipelineSnapshot(),
+	}
+	c.sendResponse(writer, request, internal.ApiPipelineSnapshotRoute, response, http.StatusOK)
+}
+
+// FlushPipelineData handles the request to the /pipeline/flush endpoint, which passes on the data accumulated by
+// pipeline functions such as batches rather than waiting for their thresholds
+func (c *Controller) FlushPipelineData(writer http.ResponseWriter, request *http.Request) {
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Pipeline data flush not available", nil, "")
+		return
+	}
+
+	response := FlushResponse{
+		BaseResponse: commonDtos.NewBaseResponse("", "", http.StatusOK),
+		Flushed:      c.appService.FlushPipelineData(),
+	}
+	c.sendResponse(writer, request, internal.ApiPipelineFlushRoute, response, http.StatusOK)
+}
+
+// FlushNamedPipelineData handles the request to the /pipeline/flush/{name} endpoint, which passes on the data
+// accumulated by the pipeline function with the name, such as a batch, rather than waiting for its thresholds
+func (c *Controller) FlushNamedPipelineData(writer http.ResponseWriter, request *http.Request) {
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Pipeline data flush not available", nil, "")
+		return
+	}
+
+	name := mux.Vars(request)[common.Name]
+	flushed, err := c.appService.FlushNamedPipelineData(name)
+	if err != nil {
+		c.sendError(writer, request, errors.KindEntityDoesNotExist, "Pipeline data flush failed", err, "")
+		return
+	}
+
+	response := FlushResponse{
+		BaseResponse: commonDtos.NewBaseResponse("", "", http.StatusOK),
+	}
+	if flushed {
+		response.Flushed = 1
+	}
+	c.sendResponse(writer, request, internal.ApiPipelineFlushByNameRoute, response, http.StatusOK)
+}
+
+// ConfigurableFunctions handles the request for the descriptions of the functions available to the configurable
+// functions pipeline and their parameters, i.e. for rendering pipeline configuration forms
+func (c *Controller) ConfigurableFunctions(writer http.ResponseWriter, request *http.Request) {
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Configurable functions not available", nil, "")
+		return
+	}
+
+	response := ConfigurableFunctionsResponse{
+		BaseResponse: commonDtos.NewBaseResponse("", "", http.StatusOK),
+		Functions:    c.appService.ConfigurableFunctions(),
+	}
+	c.sendResponse(writer, request, internal.ApiPipelineFunctionsRoute, response, http.StatusOK)
+}
+
+// ImportPipelineSnapshot handles the request to import a pipeline snapshot, typically exported from another instance,
+// and apply it to this service
+func (c *Controller) ImportPipelineSnapshot(writer http.ResponseWriter, request *http.Request) {
+	defer func() {
+		_ = request.Body.Close()
+	}()
+
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Pipeline snapshots not available", nil, "")
+		return
+	}
+
+	snapshotRequest := PipelineSnapshotRequest{}
+	err := json.NewDecoder(request.Body).Decode(&snapshotRequest)
+	if err != nil {
+		c.sendError(writer, request, errors.KindContractInvalid, "JSON decode failed", err, "")
 		return
 	}
 
-	response := commonDtos.NewBaseResponse(secretRequest.RequestId, "", http.StatusCreated)
-	c.sendResponse(writer, request, internal.ApiAddSecretRoute, response, http.StatusCreated)
+	if err := c.appService.ImportPipelineSnapshot(snapshotRequest.Snapshot); err != nil {
+		c.sendError(writer, request, errors.KindContractInvalid, "Importing pipeline snapshot failed", err, snapshotRequest.RequestId)
+		return
+	}
+
+	response := commonDtos.NewBaseResponse(snapshotRequest.RequestId, "", http.StatusOK)
+	c.sendResponse(writer, request, internal.ApiPipelineSnapshotRoute, response, http.StatusOK)
+}
+
+// ReplayStoredData handles the request to replay the Store and Forward items matching the filter through the full
+// functions pipeline
+func (c *Controller) ReplayStoredData(writer http.ResponseWriter, request *http.Request) {
+	defer func() {
+		_ = request.Body.Close()
+	}()
+
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Store and Forward replay not available", nil, "")
+		return
+	}
+
+	replayRequest := StoreReplayRequest{}
+	err := json.NewDecoder(request.Body).Decode(&replayRequest)
+	if err != nil {
+		c.sendError(writer, request, errors.KindContractInvalid, "JSON decode failed", err, "")
+		return
+	}
+
+	filter := replayRequest.Filter
+	if filter.Start > 0 && filter.End > 0 && filter.Start > filter.End {
+		c.sendError(writer, request, errors.KindContractInvalid, "Filter start must not be after end", nil, replayRequest.RequestId)
+		return
+	}
+
+	if filter.PipelinePosition != nil && *filter.PipelinePosition < 0 {
+		c.sendError(writer, request, errors.KindContractInvalid, "Filter pipelinePosition must not be negative", nil, replayRequest.RequestId)
+		return
+	}
+
+	result, err := c.appService.ReplayStoredData(filter)
+	if err != nil {
+		c.sendError(writer, request, errors.KindServiceUnavailable, "Replaying stored data failed", err, replayRequest.RequestId)
+		return
+	}
+
+	response := StoreReplayResponse{
+		BaseResponse: commonDtos.NewBaseResponse(replayRequest.RequestId, "", http.StatusOK),
+		Result:       result,
+	}
+	c.sendResponse(writer, request, internal.ApiStoreReplayRoute, response, http.StatusOK)
 }
 
 func (c *Controller) sendError(
@@ -127,11 +448,16 @@ func (c *Controller) sendError(
 	message string,
 	err error,
 	requestID string) {
+	response := c.errorResponse(errKind, message, err, requestID)
+	c.sendResponse(writer, request, internal.ApiAddSecretRoute, response, response.StatusCode)
+}
+
+// errorResponse logs the error and returns the response for it
+func (c *Controller) errorResponse(errKind errors.ErrKind, message string, err error, requestID string) commonDtos.BaseResponse {
 	edgexErr := errors.NewCommonEdgeX(errKind, message, err)
 	c.lc.Error(edgexErr.Error())
 	c.lc.Debug(edgexErr.DebugMessages())
-	response := commonDtos.NewBaseResponse(requestID, edgexErr.Message(), edgexErr.Code())
-	c.sendResponse(writer, request, internal.ApiAddSecretRoute, response, edgexErr.Code())
+	return commonDtos.NewBaseResponse(requestID, edgexErr.Message(), edgexErr.Code())
 }
 
 // sendResponse puts together the response packet for the V2 API
@@ -173,3 +499,8 @@ func (c *Controller) prepareSecret(request commonDtos.SecretRequest) (string, ma
 
 	return path, secretsKV
 }
+
+func isJSONArray(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '['
+}
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -30,6 +31,9 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
 	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
+	sdkInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	sdkMocks "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces/mocks"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
@@ -41,6 +45,7 @@ import (
 	commonDtos "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,7 +58,12 @@ func TestMain(m *testing.M) {
 		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
 			return logger.NewMockClient()
 		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &sdkCommon.ConfigurationStruct{}
+		},
 	})
+
+	os.Exit(m.Run())
 }
 
 func TestPingRequest(t *testing.T) {
@@ -92,6 +102,9 @@ func TestVersionRequest(t *testing.T) {
 }
 
 func TestMetricsRequest(t *testing.T) {
+	// A garbage collection may not have run yet, i.e. when run with -race, in which case MemFrees would be zero
+	runtime.GC()
+
 	target := NewController(nil, dic)
 
 	recorder := doRequest(t, http.MethodGet, common.ApiMetricsRoute, target.Metrics, nil)
@@ -160,8 +173,15 @@ func TestAddSecretRequest(t *testing.T) {
 	})
 
 	mockProvider := &mocks.SecretProvider{}
-	mockProvider.On("StoreSecrets", "/mqtt", map[string]string{"password": "password", "username": "username"}).Return(nil)
-	mockProvider.On("StoreSecrets", "/no", map[string]string{"password": "password", "username": "username"}).Return(errors.New("Invalid w/o Vault"))
+	mockProvider.On("StoreSecret", "mqtt", map[string]string{"password": "password", "username": "username"}).Return(nil)
+	mockProvider.On("StoreSecret", "/mqtt", map[string]string{"password": "password", "username": "username"}).Return(nil)
+	mockProvider.On("StoreSecret", "no", map[string]string{"password": "password", "username": "username"}).Return(errors.New("Invalid w/o Vault"))
+
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockProvider
+		},
+	})
 
 	target := NewController(nil, dic)
 	assert.NotNil(t, target)
@@ -253,6 +273,101 @@ func TestAddSecretRequest(t *testing.T) {
 	}
 }
 
+func TestAddSecretsRequest(t *testing.T) {
+	config := &sdkCommon.ConfigurationStruct{
+		Writable: sdkCommon.WritableInfo{
+			Pipeline: sdkCommon.PipelineInfo{
+				ExecutionOrder: "MQTTExport",
+				Functions: map[string]sdkCommon.PipelineFunction{
+					"MQTTExport": {Parameters: map[string]string{"SecretPath": "mqtt", "AuthMode": "usernamepassword"}},
+				},
+			},
+		},
+	}
+
+	mockProvider := &mocks.SecretProvider{}
+	mockProvider.On("StoreSecret", "mqtt", map[string]string{"password": "password", "username": "username"}).Return(nil)
+	mockProvider.On("StoreSecret", "http", map[string]string{"header": "Bearer 123"}).Return(nil)
+	mockProvider.On("StoreSecret", "no", map[string]string{"key": "value"}).Return(errors.New("Invalid w/o Vault"))
+
+	secretsDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockProvider
+		},
+	})
+
+	target := NewController(nil, secretsDic)
+
+	requestId := uuid.New().String()
+	newRequest := func(path string, keys ...string) commonDtos.SecretRequest {
+		request := commonDtos.SecretRequest{
+			BaseRequest: commonDtos.BaseRequest{RequestId: requestId, Versionable: commonDtos.NewVersionable()},
+			Path:        path,
+		}
+		for index := 0; index < len(keys); index += 2 {
+			request.SecretData = append(request.SecretData, commonDtos.SecretDataKeyValue{Key: keys[index], Value: keys[index+1]})
+		}
+		return request
+	}
+
+	t.Run("Bulk", func(t *testing.T) {
+		requests := []commonDtos.SecretRequest{
+			newRequest("mqtt", "username", "username", "password", "password"),
+			newRequest("http", "header", "Bearer 123"),
+			newRequest("mqtt", "username", "username"),
+			newRequest(""),
+			newRequest("no", "key", "value"),
+		}
+		expectedStatusCodes := []int{http.StatusCreated, http.StatusCreated, http.StatusBadRequest, http.StatusBadRequest, http.StatusInternalServerError}
+
+		jsonData, err := json.Marshal(requests)
+		require.NoError(t, err)
+
+		recorder := doRequest(t, http.MethodPost, internal.ApiAddSecretRoute, target.AddSecret, strings.NewReader(string(jsonData)))
+
+		var actual []commonDtos.BaseResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &actual))
+		require.Len(t, actual, len(requests))
+		for index, response := range actual {
+			assert.Equal(t, expectedStatusCodes[index], response.StatusCode, "status code of request %d not as expected", index)
+		}
+
+		assert.Equal(t, requestId, actual[0].RequestId)
+		assert.Empty(t, actual[0].Message)
+		assert.Contains(t, actual[2].Message, "Secret validation failed")
+		mockProvider.AssertNumberOfCalls(t, "StoreSecret", 3)
+	})
+
+	t.Run("Single missing required key", func(t *testing.T) {
+		jsonData, err := json.Marshal(newRequest("/mqtt", "password", "password"))
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, internal.ApiAddSecretRoute, strings.NewReader(string(jsonData)))
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		http.HandlerFunc(target.AddSecret).ServeHTTP(recorder, req)
+
+		actual := commonDtos.BaseResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &actual))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, requestId, actual.RequestId)
+	})
+
+	t.Run("Empty array", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, internal.ApiAddSecretRoute, strings.NewReader(" [ ] "))
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		http.HandlerFunc(target.AddSecret).ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
 func doRequest(t *testing.T, method string, api string, handler http.HandlerFunc, body io.Reader) *httptest.ResponseRecorder {
 	req, err := http.NewRequest(method, api, body)
 	require.NoError(t, err)
@@ -275,3 +390,381 @@ func doRequest(t *testing.T, method string, api string, handler http.HandlerFunc
 
 	return recorder
 }
+
+func TestSLARequest(t *testing.T) {
+	tracker := telemetry.NewSLATracker()
+	config := &sdkCommon.ConfigurationStruct{
+		Writable: sdkCommon.WritableInfo{
+			Pipeline: sdkCommon.PipelineInfo{
+				SLA: sdkCommon.SLAInfo{Window: "1h", SuccessRateTarget: 0.5},
+			},
+		},
+	}
+	slaDic := di.NewContainer(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+		container.SLATrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+
+	tracker.Record(config.Writable.Pipeline.SLA, time.Millisecond, true)
+	tracker.Record(config.Writable.Pipeline.SLA, time.Millisecond, false)
+
+	target := NewController(nil, slaDic)
+	recorder := doRequest(t, http.MethodGet, internal.ApiSLARoute, target.SLA, nil)
+
+	actual := SLAResponse{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, common.ApiVersion, actual.ApiVersion)
+	assert.True(t, actual.SLA.Enabled)
+	assert.Equal(t, uint64(2), actual.SLA.Total)
+	assert.Equal(t, uint64(1), actual.SLA.Failed)
+	assert.True(t, actual.SLA.Compliant)
+}
+
+func TestDeviceStatsRequest(t *testing.T) {
+	tracker := telemetry.NewDeviceStatsTracker()
+	config := &sdkCommon.ConfigurationStruct{
+		Writable: sdkCommon.WritableInfo{
+			DeviceStatistics: sdkCommon.DeviceStatisticsInfo{Enabled: true, RateWindow: "1m", MaxRate: 10},
+		},
+	}
+	statsDic := di.NewContainer(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+		container.DeviceStatsTrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+
+	tracker.Record(config.Writable.DeviceStatistics, "device1")
+	tracker.Record(config.Writable.DeviceStatistics, "device1")
+	tracker.Record(config.Writable.DeviceStatistics, "device2")
+
+	target := NewController(nil, statsDic)
+	recorder := doRequest(t, http.MethodGet, internal.ApiDeviceStatsRoute, target.DeviceStats, nil)
+
+	actual := DeviceStatsResponse{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, common.ApiVersion, actual.ApiVersion)
+	assert.True(t, actual.DeviceStats.Enabled)
+	assert.Equal(t, "1m0s", actual.DeviceStats.RateWindow)
+	require.Len(t, actual.DeviceStats.Devices, 2)
+	assert.Equal(t, uint64(2), actual.DeviceStats.Devices["device1"].MessageCount)
+	assert.Equal(t, uint64(1), actual.DeviceStats.Devices["device2"].MessageCount)
+	assert.Equal(t, float64(10), actual.DeviceStats.Devices["device1"].MaxRate)
+	assert.NotZero(t, actual.DeviceStats.Devices["device1"].LastSeen)
+	assert.Empty(t, actual.DeviceStats.Devices["device1"].Alert)
+}
+
+func TestPipelineStatsRequest(t *testing.T) {
+	tracker := telemetry.NewPipelineStatsTracker()
+	statsDic := di.NewContainer(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &sdkCommon.ConfigurationStruct{}
+		},
+		container.PipelineStatsTrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+
+	tracker.Record(sdkInterfaces.DefaultPipelineId, telemetry.PipelineExported)
+	tracker.Record(sdkInterfaces.DefaultPipelineId, telemetry.PipelineFiltered)
+	tracker.Record(sdkInterfaces.DefaultPipelineId, telemetry.PipelineFailed)
+	tracker.Record(sdkInterfaces.DefaultPipelineId, telemetry.PipelineExported)
+
+	expected := telemetry.PipelineStats{Processed: 4, Exported: 2, Failed: 1, Filtered: 1}
+	target := NewController(nil, statsDic)
+
+	recorder := doRequest(t, http.MethodGet, internal.ApiPipelineStatsRoute, target.PipelineStats, nil)
+	actual := PipelineStatsResponse{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &actual))
+	assert.Equal(t, common.ApiVersion, actual.ApiVersion)
+	assert.Equal(t, map[string]telemetry.PipelineStats{sdkInterfaces.DefaultPipelineId: expected}, actual.PipelineStatistics)
+
+	// The totals are also part of the metrics
+	recorder = doRequest(t, http.MethodGet, common.ApiMetricsRoute, target.Metrics, nil)
+	metrics := MetricsResponse{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &metrics))
+	assert.NotZero(t, metrics.Metrics.MemAlloc)
+	assert.Equal(t, expected, metrics.PipelineStatistics[sdkInterfaces.DefaultPipelineId])
+}
+
+func TestMetricsRequestTriggerStatistics(t *testing.T) {
+	tracker := telemetry.NewTriggerStatsTracker()
+	statsDic := di.NewContainer(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &sdkCommon.ConfigurationStruct{}
+		},
+		container.TriggerStatsTrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+
+	tracker.MessageDropped()
+	tracker.MessageDropped()
+
+	target := NewController(nil, statsDic)
+
+	recorder := doRequest(t, http.MethodGet, common.ApiMetricsRoute, target.Metrics, nil)
+	metrics := MetricsResponse{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &metrics))
+	assert.Equal(t, telemetry.TriggerStats{DroppedMessages: 2}, metrics.TriggerStatistics)
+	assert.Contains(t, recorder.Body.String(), `"triggerStatistics":{"droppedMessages":2}`)
+}
+
+func newPipelineSnapshotDic(appService *sdkMocks.ApplicationService) *di.Container {
+	return di.NewContainer(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &sdkCommon.ConfigurationStruct{}
+		},
+		container.ApplicationServiceName: func(get di.Get) interface{} {
+			return appService
+		},
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+}
+
+func TestExportPipelineSnapshotRequest(t *testing.T) {
+	snapshot := sdkInterfaces.PipelineSnapshot{
+		ServiceKey:     "app-source",
+		ExecutionOrder: "Transform",
+		Functions: map[string]sdkInterfaces.PipelineSnapshotFunction{
+			"Transform": {Parameters: map[string]string{"type": "json"}},
+		},
+		Topics: sdkInterfaces.PipelineSnapshotTopics{SubscribeTopics: "edgex/events/#"},
+	}
+
+	appService := &sdkMocks.ApplicationService{}
+	appService.On("ExportPipelineSnapshot").Return(snapshot)
+
+	target := NewController(nil, newPipelineSnapshotDic(appService))
+	recorder := doRequest(t, http.MethodGet, internal.ApiPipelineSnapshotRoute, target.ExportPipelineSnapshot, nil)
+
+	actual := PipelineSnapshotResponse{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, common.ApiVersion, actual.ApiVersion)
+	assert.Equal(t, snapshot, actual.Snapshot)
+}
+
+func TestConfigurableFunctionsRequest(t *testing.T) {
+	functions := []sdkInterfaces.ConfigurableFunction{
+		{
+			Name:        "Transform",
+			Description: "Converts Events to XML or JSON",
+			Parameters: []sdkInterfaces.ConfigurableFunctionParameter{
+				{Name: "type", Type: sdkInterfaces.ParameterTypeString, Required: true, Values: []string{"xml", "json"}},
+			},
+		},
+	}
+
+	appService := &sdkMocks.ApplicationService{}
+	appService.On("ConfigurableFunctions").Return(functions)
+
+	target := NewController(nil, newPipelineSnapshotDic(appService))
+	recorder := doRequest(t, http.MethodGet, internal.ApiPipelineFunctionsRoute, target.ConfigurableFunctions, nil)
+
+	actual := ConfigurableFunctionsResponse{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, common.ApiVersion, actual.ApiVersion)
+	assert.Equal(t, functions, actual.Functions)
+}
+
+func TestFlushPipelineDataRequest(t *testing.T) {
+	appService := &sdkMocks.ApplicationService{}
+	appService.On("FlushPipelineData").Return(2)
+
+	target := NewController(nil, newPipelineSnapshotDic(appService))
+	req, err := http.NewRequest(http.MethodPost, internal.ApiPipelineFlushRoute, nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	target.FlushPipelineData(recorder, req)
+
+	actual := FlushResponse{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, common.ApiVersion, actual.ApiVersion)
+	assert.Equal(t, 2, actual.Flushed)
+	appService.AssertExpectations(t)
+}
+
+func TestFlushNamedPipelineDataRequest(t *testing.T) {
+	appService := &sdkMocks.ApplicationService{}
+	appService.On("FlushNamedPipelineData", "truck-dock").Return(true, nil)
+	appService.On("FlushNamedPipelineData", "unknown").Return(false, errors.New("no flusher with name 'unknown' registered"))
+
+	tests := []struct {
+		Name            string
+		FlushName       string
+		ExpectedStatus  int
+		ExpectedFlushed int
+	}{
+		{"Flushed", "truck-dock", http.StatusOK, 1},
+		{"Not found", "unknown", http.StatusNotFound, 0},
+	}
+
+	target := NewController(nil, newPipelineSnapshotDic(appService))
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, internal.ApiPipelineFlushByNameRoute, nil)
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{common.Name: testCase.FlushName})
+			recorder := httptest.NewRecorder()
+			target.FlushNamedPipelineData(recorder, req)
+
+			require.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			if testCase.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			actual := FlushResponse{}
+			err = json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.ExpectedFlushed, actual.Flushed)
+		})
+	}
+	appService.AssertExpectations(t)
+}
+
+func TestImportPipelineSnapshotRequest(t *testing.T) {
+	expectedRequestId := "82eb2e26-0f24-48aa-ae4c-de9dac3fb9bc"
+	snapshot := sdkInterfaces.PipelineSnapshot{
+		ServiceKey:     "app-source",
+		ExecutionOrder: "Transform",
+		Functions: map[string]sdkInterfaces.PipelineSnapshotFunction{
+			"Transform": {Parameters: map[string]string{"type": "json"}},
+		},
+	}
+
+	tests := []struct {
+		Name           string
+		Body           string
+		ImportError    error
+		ExpectedStatus int
+	}{
+		{"Valid", "", nil, http.StatusOK},
+		{"Import failed", "", errors.New("invalid pipeline snapshot"), http.StatusBadRequest},
+		{"Bad JSON", "{", nil, http.StatusBadRequest},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			appService := &sdkMocks.ApplicationService{}
+			appService.On("ImportPipelineSnapshot", snapshot).Return(testCase.ImportError)
+
+			body := testCase.Body
+			if len(body) == 0 {
+				data, err := json.Marshal(PipelineSnapshotRequest{
+					BaseRequest: commonDtos.BaseRequest{RequestId: expectedRequestId, Versionable: commonDtos.NewVersionable()},
+					Snapshot:    snapshot,
+				})
+				require.NoError(t, err)
+				body = string(data)
+			}
+
+			target := NewController(nil, newPipelineSnapshotDic(appService))
+			req, err := http.NewRequest(http.MethodPost, internal.ApiPipelineSnapshotRoute, strings.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			target.ImportPipelineSnapshot(recorder, req)
+
+			actual := commonDtos.BaseResponse{}
+			err = json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			assert.Equal(t, testCase.ExpectedStatus, actual.StatusCode)
+			if len(testCase.Body) == 0 {
+				appService.AssertCalled(t, "ImportPipelineSnapshot", snapshot)
+				assert.Equal(t, expectedRequestId, actual.RequestId)
+			}
+		})
+	}
+}
+
+func TestReplayStoredDataRequest(t *testing.T) {
+	expectedRequestId := "82eb2e26-0f24-48aa-ae4c-de9dac3fb9bc"
+	position := 1
+	negativePosition := -1
+	filter := sdkInterfaces.StoreReplayFilter{Start: 100, End: 200, CorrelationID: "123", PipelinePosition: &position}
+	expectedResult := sdkInterfaces.StoreReplayResult{Matched: 3, Replayed: 1, Failed: 1, Skipped: 1}
+
+	tests := []struct {
+		Name           string
+		Body           string
+		Filter         sdkInterfaces.StoreReplayFilter
+		ReplayError    error
+		ExpectedStatus int
+	}{
+		{"Valid", "", filter, nil, http.StatusOK},
+		{"Valid empty filter", "", sdkInterfaces.StoreReplayFilter{}, nil, http.StatusOK},
+		{"Replay failed", "", filter, errors.New("StoreAndForward not enabled"), http.StatusServiceUnavailable},
+		{"Start after end", "", sdkInterfaces.StoreReplayFilter{Start: 200, End: 100}, nil, http.StatusBadRequest},
+		{"Negative position", "", sdkInterfaces.StoreReplayFilter{PipelinePosition: &negativePosition}, nil, http.StatusBadRequest},
+		{"Bad JSON", "{", filter, nil, http.StatusBadRequest},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			appService := &sdkMocks.ApplicationService{}
+			appService.On("ReplayStoredData", testCase.Filter).Return(expectedResult, testCase.ReplayError)
+
+			body := testCase.Body
+			if len(body) == 0 {
+				data, err := json.Marshal(StoreReplayRequest{
+					BaseRequest: commonDtos.BaseRequest{RequestId: expectedRequestId, Versionable: commonDtos.NewVersionable()},
+					Filter:      testCase.Filter,
+				})
+				require.NoError(t, err)
+				body = string(data)
+			}
+
+			target := NewController(nil, newPipelineSnapshotDic(appService))
+			req, err := http.NewRequest(http.MethodPost, internal.ApiStoreReplayRoute, strings.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			target.ReplayStoredData(recorder, req)
+
+			actual := StoreReplayResponse{}
+			err = json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			assert.Equal(t, testCase.ExpectedStatus, actual.StatusCode)
+			if testCase.ExpectedStatus == http.StatusOK {
+				assert.Equal(t, expectedRequestId, actual.RequestId)
+				assert.Equal(t, expectedResult, actual.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	commonDtos "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+	"github.com/gorilla/mux"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
+	sdkInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+// Pipeline is a configurable functions pipeline as managed through the /pipelines endpoints. Secret parameter
+// values are redacted and keep their current value when the pipeline is updated with them redacted.
+type Pipeline struct {
+	Id                       string                                            `json:"id"`
+	ExecutionOrder           string                                            `json:"executionOrder"`
+	UseTargetTypeOfByteArray bool                                              `json:"useTargetTypeOfByteArray"`
+	Functions                map[string]sdkInterfaces.PipelineSnapshotFunction `json:"functions"`
+	// FunctionNames are the names of the functions in the order they are executed
+	FunctionNames []string `json:"functionNames"`
+	// Hash identifies the pipeline's configuration, so deployments running the same pipeline can be recognized. It is
+	// calculated from the redacted parameters so changing a secret doesn't change it.
+	Hash string `json:"hash"`
+	// Statistics are the lifetime totals of the messages processed by the pipeline
+	Statistics telemetry.PipelineStats `json:"statistics"`
+}
+
+// PipelinesResponse defines the content of the response to a GET of the /pipelines endpoint
+type PipelinesResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	Pipelines               []Pipeline `json:"pipelines"`
+}
+
+// PipelineResponse defines the content of the response to the /pipelines/{id} endpoints
+type PipelineResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	Pipeline                Pipeline `json:"pipeline"`
+}
+
+// PayloadPreviewResponse defines the content of the response to the /pipelines/{id}/preview endpoint
+type PayloadPreviewResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	Preview                 telemetry.PayloadPreview `json:"preview"`
+}
+
+// UpdatePipelineRequest defines the content of a PUT to the /pipelines/{id} endpoint. Fields which aren't specified
+// keep their current value.
+type UpdatePipelineRequest struct {
+	commonDtos.BaseRequest   `json:",inline"`
+	ExecutionOrder           *string                                           `json:"executionOrder,omitempty"`
+	UseTargetTypeOfByteArray *bool                                             `json:"useTargetTypeOfByteArray,omitempty"`
+	Functions                map[string]sdkInterfaces.PipelineSnapshotFunction `json:"functions,omitempty"`
+}
+
+// UpdatePipelineFunctionRequest defines the content of a PUT to the /pipelines/{id}/functions/{name} endpoint
+type UpdatePipelineFunctionRequest struct {
+	commonDtos.BaseRequest `json:",inline"`
+	Parameters             map[string]string `json:"parameters"`
+	// Disabled skips the function, passing the data through to the next function
+	Disabled bool `json:"disabled"`
+}
+
+// PipelineTestRequest defines the content of a POST to the /pipeline/test endpoint
+type PipelineTestRequest struct {
+	commonDtos.BaseRequest `json:",inline"`
+	// PipelineId is the id of the pipeline to test, which defaults to interfaces.DefaultPipelineId
+	PipelineId string                     `json:"pipelineId,omitempty"`
+	Test       sdkInterfaces.PipelineTest `json:"test"`
+}
+
+// PipelineTestResponse defines the content of the response to the /pipeline/test endpoint
+type PipelineTestResponse struct {
+	commonDtos.BaseResponse `json:",inline"`
+	Result                  sdkInterfaces.PipelineTestResult `json:"result"`
+}
+
+// Pipelines handles the request for the service's configurable functions pipelines. The service has the single
+// pipeline identified by interfaces.DefaultPipelineId.
+func (c *Controller) Pipelines(writer http.ResponseWriter, request *http.Request) {
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Pipeline management not available", nil, "")
+		return
+	}
+
+	response := PipelinesResponse{
+		BaseResponse: commonDtos.NewBaseResponse("", "", http.StatusOK),
+		Pipelines:    []Pipeline{c.toPipeline(c.appService.ExportPipelineSnapshot())},
+	}
+	c.sendResponse(writer, request, internal.ApiPipelinesRoute, response, http.StatusOK)
+}
+
+// PipelineById handles the request for the configurable functions pipeline with the id
+func (c *Controller) PipelineById(writer http.ResponseWriter, request *http.Request) {
+	snapshot, ok := c.pipelineSnapshot(writer, request)
+	if !ok {
+		return
+	}
+
+	c.sendPipeline(writer, request, "", snapshot)
+}
+
+// PipelinePreview handles the request for the serialized payload most recently passed to the last function, i.e. the
+// export, of the pipeline with the id, so the exported format can be verified. Context values which may be secrets
+// are redacted.
+func (c *Controller) PipelinePreview(writer http.ResponseWriter, request *http.Request) {
+	if c.previews == nil || !c.config.Writable.PayloadPreview.Enabled {
+		c.sendError(writer, request, errors.KindServiceUnavailable, "Payload preview not enabled", nil, "")
+		return
+	}
+
+	id := mux.Vars(request)[common.Id]
+	if id != sdkInterfaces.DefaultPipelineId {
+		c.sendError(writer, request, errors.KindEntityDoesNotExist, fmt.Sprintf("Pipeline '%s' not found", id), nil, "")
+		return
+	}
+
+	preview, found := c.previews.Preview(id)
+	if !found {
+		c.sendError(writer, request, errors.KindEntityDoesNotExist, fmt.Sprintf("No payload exported by pipeline '%s' yet", id), nil, "")
+		return
+	}
+
+	response := PayloadPreviewResponse{
+		BaseResponse: commonDtos.NewBaseResponse("", "", http.StatusOK),
+		Preview:      preview,
+	}
+	c.sendResponse(writer, request, internal.ApiPipelinePreviewRoute, response, http.StatusOK)
+}
+
+// UpdatePipeline handles the request to update the execution order, target type and/or functions of the pipeline
+// with the id. The pipeline is validated and applied to the running service, then persisted to the Configuration
+// Provider, if used.
+func (c *Controller) UpdatePipeline(writer http.ResponseWriter, request *http.Request) {
+	defer func() {
+		_ = request.Body.Close()
+	}()
+
+	updateRequest := UpdatePipelineRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&updateRequest); err != nil {
+		c.sendError(writer, request, errors.KindContractInvalid, "JSON decode failed", err, "")
+		return
+	}
+
+	c.pipelineLock.Lock()
+	defer c.pipelineLock.Unlock()
+
+	snapshot, ok := c.pipelineSnapshot(writer, request)
+	if !ok {
+		return
+	}
+
+	if updateRequest.ExecutionOrder != nil {
+		snapshot.ExecutionOrder = *updateRequest.ExecutionOrder
+	}
+	if updateRequest.UseTargetTypeOfByteArray != nil {
+		snapshot.UseTargetTypeOfByteArray = *updateRequest.UseTargetTypeOfByteArray
+	}
+	if updateRequest.Functions != nil {
+		for name := range updateRequest.Functions {
+			if !c.isConfigurableFunction(name) {
+				c.sendError(writer, request, errors.KindContractInvalid, fmt.Sprintf("Function '%s' is not a built in SDK function", name), nil, updateRequest.RequestId)
+				return
+			}
+		}
+		snapshot.Functions = updateRequest.Functions
+	}
+
+	c.applyPipeline(writer, request, updateRequest.RequestId, snapshot)
+}
+
+// UpdatePipelineFunction handles the request to create or replace the parameters of the function with the name in the
+// pipeline with the id. The function name must start with the name of a built in function, i.e. HTTPExportBackup,
+// and must also be added to the execution order to be executed.
+func (c *Controller) UpdatePipelineFunction(writer http.ResponseWriter, request *http.Request) {
+	defer func() {
+		_ = request.Body.Close()
+	}()
+
+	updateRequest := UpdatePipelineFunctionRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&updateRequest); err != nil {
+		c.sendError(writer, request, errors.KindContractInvalid, "JSON decode failed", err, "")
+		return
+	}
+
+	name := mux.Vars(request)[common.Name]
+	if !c.isConfigurableFunction(name) {
+		c.sendError(writer, request, errors.KindContractInvalid, fmt.Sprintf("Function '%s' is not a built in SDK function", name), nil, updateRequest.RequestId)
+		return
+	}
+
+	c.pipelineLock.Lock()
+	defer c.pipelineLock.Unlock()
+
+	snapshot, ok := c.pipelineSnapshot(writer, request)
+	if !ok {
+		return
+	}
+
+	parameters := updateRequest.Parameters
+	if parameters == nil {
+		parameters = map[string]string{}
+	}
+	snapshot.Functions[name] = sdkInterfaces.PipelineSnapshotFunction{Parameters: parameters, Disabled: updateRequest.Disabled}
+
+	c.applyPipeline(writer, request, updateRequest.RequestId, snapshot)
+}
+
+// DeletePipelineFunction handles the request to remove the function with the name from the pipeline with the id,
+// including from its execution order
+func (c *Controller) DeletePipelineFunction(writer http.ResponseWriter, request *http.Request) {
+	c.pipelineLock.Lock()
+	defer c.pipelineLock.Unlock()
+
+	snapshot, ok := c.pipelineSnapshot(writer, request)
+	if !ok {
+		return
+	}
+
+	name := mux.Vars(request)[common.Name]
+	if _, found := snapshot.Functions[name]; !found {
+		c.sendError(writer, request, errors.KindEntityDoesNotExist, fmt.Sprintf("Function '%s' not found in pipeline", name), nil, "")
+		return
+	}
+
+	delete(snapshot.Functions, name)
+
+	var executionOrder []string
+	for _, function := range util.DeleteEmptyAndTrim(strings.FieldsFunc(snapshot.ExecutionOrder, util.SplitComma)) {
+		if function != name {
+			executionOrder = append(executionOrder, function)
+		}
+	}
+	snapshot.ExecutionOrder = strings.Join(executionOrder, ", ")
+
+	c.applyPipeline(writer, request, "", snapshot)
+}
+
+// TestPipeline handles the request to run the payload through the pipeline with the id, or the changed pipeline
+// supplied, without applying the pipeline or sending any data, and responds with the output of each function
+func (c *Controller) TestPipeline(writer http.ResponseWriter, request *http.Request) {
+	defer func() {
+		_ = request.Body.Close()
+	}()
+
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Pipeline testing not available", nil, "")
+		return
+	}
+
+	testRequest := PipelineTestRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&testRequest); err != nil {
+		c.sendError(writer, request, errors.KindContractInvalid, "JSON decode failed", err, "")
+		return
+	}
+
+	if len(testRequest.PipelineId) > 0 && testRequest.PipelineId != sdkInterfaces.DefaultPipelineId {
+		c.sendError(writer, request, errors.KindEntityDoesNotExist, fmt.Sprintf("Pipeline '%s' not found", testRequest.PipelineId), nil, testRequest.RequestId)
+		return
+	}
+
+	result, err := c.appService.TestPipeline(testRequest.Test)
+	if err != nil {
+		c.sendError(writer, request, errors.KindContractInvalid, "Testing pipeline failed", err, testRequest.RequestId)
+		return
+	}
+
+	response := PipelineTestResponse{
+		BaseResponse: commonDtos.NewBaseResponse(testRequest.RequestId, "", http.StatusOK),
+		Result:       result,
+	}
+	c.sendResponse(writer, request, internal.ApiPipelineTestRoute, response, http.StatusOK)
+}
+
+// pipelineSnapshot returns the snapshot of the pipeline with the id of the request, sending the error response if
+// pipelines can't be managed or the id doesn't match
+func (c *Controller) pipelineSnapshot(writer http.ResponseWriter, request *http.Request) (sdkInterfaces.PipelineSnapshot, bool) {
+	if c.appService == nil {
+		c.sendError(writer, request, errors.KindServerError, "Pipeline management not available", nil, "")
+		return sdkInterfaces.PipelineSnapshot{}, false
+	}
+
+	id := mux.Vars(request)[common.Id]
+	if id != sdkInterfaces.DefaultPipelineId {
+		c.sendError(writer, request, errors.KindEntityDoesNotExist, fmt.Sprintf("Pipeline '%s' not found", id), nil, "")
+		return sdkInterfaces.PipelineSnapshot{}, false
+	}
+
+	snapshot := c.appService.ExportPipelineSnapshot()
+	if snapshot.Functions == nil {
+		snapshot.Functions = make(map[string]sdkInterfaces.PipelineSnapshotFunction)
+	}
+	return snapshot, true
+}
+
+// applyPipeline applies the updated snapshot, which validates it, and responds with the resulting pipeline
+func (c *Controller) applyPipeline(writer http.ResponseWriter, request *http.Request, requestId string, snapshot sdkInterfaces.PipelineSnapshot) {
+	if err := c.appService.ImportPipelineSnapshot(snapshot); err != nil {
+		c.sendError(writer, request, errors.KindContractInvalid, "Updating pipeline failed", err, requestId)
+		return
+	}
+
+	c.sendPipeline(writer, request, requestId, c.appService.ExportPipelineSnapshot())
+}
+
+func (c *Controller) sendPipeline(writer http.ResponseWriter, request *http.Request, requestId string, snapshot sdkInterfaces.PipelineSnapshot) {
+	response := PipelineResponse{
+		BaseResponse: commonDtos.NewBaseResponse(requestId, "", http.StatusOK),
+		Pipeline:     c.toPipeline(snapshot),
+	}
+	c.sendResponse(writer, request, internal.ApiPipelineByIdRoute, response, http.StatusOK)
+}
+
+// isConfigurableFunction determines if the pipeline function name starts with the name of a built in function, as
+// required by LoadConfigurablePipeline
+func (c *Controller) isConfigurableFunction(name string) bool {
+	for _, function := range c.appService.ConfigurableFunctions() {
+		if strings.HasPrefix(name, function.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// toPipeline converts the snapshot to the pipeline returned by the /pipelines endpoints, adding its hash and
+// statistics
+func (c *Controller) toPipeline(snapshot sdkInterfaces.PipelineSnapshot) Pipeline {
+	functions := snapshot.Functions
+	if functions == nil {
+		functions = make(map[string]sdkInterfaces.PipelineSnapshotFunction)
+	}
+
+	functionNames := util.DeleteEmptyAndTrim(strings.FieldsFunc(snapshot.ExecutionOrder, util.SplitComma))
+	if functionNames == nil {
+		functionNames = []string{}
+	}
+
+	return Pipeline{
+		Id:                       sdkInterfaces.DefaultPipelineId,
+		ExecutionOrder:           snapshot.ExecutionOrder,
+		UseTargetTypeOfByteArray: snapshot.UseTargetTypeOfByteArray,
+		Functions:                functions,
+		FunctionNames:            functionNames,
+		Hash:                     pipelineHash(functionNames, snapshot.UseTargetTypeOfByteArray, functions),
+		Statistics:               c.pipelineStatsReport()[sdkInterfaces.DefaultPipelineId],
+	}
+}
+
+// pipelineHash returns the SHA-256 hash of the pipeline's configuration. The JSON encoding sorts the map keys, so
+// the same configuration always has the same hash.
+func pipelineHash(functionNames []string, useTargetTypeOfByteArray bool, functions map[string]sdkInterfaces.PipelineSnapshotFunction) string {
+	data, _ := json.Marshal(struct {
+		FunctionNames            []string
+		UseTargetTypeOfByteArray bool
+		Functions                map[string]sdkInterfaces.PipelineSnapshotFunction
+	}{functionNames, useTargetTypeOfByteArray, functions})
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
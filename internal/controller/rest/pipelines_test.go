@@ -0,0 +1,387 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	commonDtos "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
+	sdkInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	sdkMocks "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces/mocks"
+)
+
+func newTestPipelineSnapshot() sdkInterfaces.PipelineSnapshot {
+	return sdkInterfaces.PipelineSnapshot{
+		ServiceKey:     "app-source",
+		ExecutionOrder: "FilterByDeviceName, Transform, HTTPExport",
+		Functions: map[string]sdkInterfaces.PipelineSnapshotFunction{
+			"FilterByDeviceName": {Parameters: map[string]string{"DeviceNames": "Random-Float-Device"}},
+			"Transform":          {Parameters: map[string]string{"Type": "json"}},
+			"HTTPExport":         {Parameters: map[string]string{"Url": "http://localhost", "MimeType": "application/json"}},
+		},
+	}
+}
+
+func newPipelineAppService(importError error) *sdkMocks.ApplicationService {
+	appService := &sdkMocks.ApplicationService{}
+	appService.On("ExportPipelineSnapshot").Return(newTestPipelineSnapshot())
+	appService.On("ImportPipelineSnapshot", mock.Anything).Return(importError)
+	appService.On("ConfigurableFunctions").Return([]sdkInterfaces.ConfigurableFunction{
+		{Name: "FilterByDeviceName"},
+		{Name: "Transform"},
+		{Name: "HTTPExport"},
+		{Name: "Compress"},
+	})
+	return appService
+}
+
+func doPipelineRequest(t *testing.T, method string, vars map[string]string, handler http.HandlerFunc, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, internal.ApiPipelineByIdRoute, strings.NewReader(body))
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, vars)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, req)
+	return recorder
+}
+
+func TestPipelinesRequest(t *testing.T) {
+	tracker := telemetry.NewPipelineStatsTracker()
+	tracker.Record(sdkInterfaces.DefaultPipelineId, telemetry.PipelineExported)
+	tracker.Record(sdkInterfaces.DefaultPipelineId, telemetry.PipelineFailed)
+
+	dic := newPipelineSnapshotDic(newPipelineAppService(nil))
+	dic.Update(di.ServiceConstructorMap{
+		container.PipelineStatsTrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+	})
+
+	target := NewController(nil, dic)
+	recorder := doRequest(t, http.MethodGet, internal.ApiPipelinesRoute, target.Pipelines, nil)
+
+	actual := PipelinesResponse{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+	require.NoError(t, err)
+
+	require.Len(t, actual.Pipelines, 1)
+	assert.Equal(t, sdkInterfaces.DefaultPipelineId, actual.Pipelines[0].Id)
+	assert.Equal(t, newTestPipelineSnapshot().ExecutionOrder, actual.Pipelines[0].ExecutionOrder)
+	assert.Len(t, actual.Pipelines[0].Functions, 3)
+	assert.Equal(t, []string{"FilterByDeviceName", "Transform", "HTTPExport"}, actual.Pipelines[0].FunctionNames)
+	assert.Len(t, actual.Pipelines[0].Hash, 64)
+	assert.Equal(t, telemetry.PipelineStats{Processed: 2, Exported: 1, Failed: 1}, actual.Pipelines[0].Statistics)
+}
+
+func TestPipelineHash(t *testing.T) {
+	snapshot := newTestPipelineSnapshot()
+	names := []string{"FilterByDeviceName", "Transform", "HTTPExport"}
+	expected := pipelineHash(names, false, snapshot.Functions)
+
+	assert.Equal(t, expected, pipelineHash(names, false, newTestPipelineSnapshot().Functions), "same pipeline should have same hash")
+	assert.NotEqual(t, expected, pipelineHash(names, true, snapshot.Functions), "target type should change hash")
+	assert.NotEqual(t, expected, pipelineHash([]string{"Transform", "FilterByDeviceName", "HTTPExport"}, false, snapshot.Functions), "order should change hash")
+
+	snapshot.Functions["Transform"] = sdkInterfaces.PipelineSnapshotFunction{Parameters: map[string]string{"Type": "xml"}}
+	assert.NotEqual(t, expected, pipelineHash(names, false, snapshot.Functions), "parameters should change hash")
+}
+
+func TestPipelineByIdRequest(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Id             string
+		ExpectedStatus int
+	}{
+		{"Valid", sdkInterfaces.DefaultPipelineId, http.StatusOK},
+		{"Unknown pipeline", "other-pipeline", http.StatusNotFound},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			target := NewController(nil, newPipelineSnapshotDic(newPipelineAppService(nil)))
+			recorder := doPipelineRequest(t, http.MethodGet, map[string]string{common.Id: testCase.Id}, target.PipelineById, "")
+
+			actual := PipelineResponse{}
+			err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			assert.Equal(t, testCase.ExpectedStatus, actual.StatusCode)
+			if testCase.ExpectedStatus == http.StatusOK {
+				assert.Equal(t, testCase.Id, actual.Pipeline.Id)
+			}
+		})
+	}
+}
+
+func TestUpdatePipelineRequest(t *testing.T) {
+	expectedRequestId := "82eb2e26-0f24-48aa-ae4c-de9dac3fb9bc"
+	executionOrder := "Transform, HTTPExport"
+	byteArray := true
+
+	expected := newTestPipelineSnapshot()
+	expected.ExecutionOrder = executionOrder
+	expected.UseTargetTypeOfByteArray = true
+
+	tests := []struct {
+		Name           string
+		Id             string
+		Body           string
+		Functions      map[string]sdkInterfaces.PipelineSnapshotFunction
+		ImportError    error
+		ExpectedStatus int
+	}{
+		{"Valid", sdkInterfaces.DefaultPipelineId, "", nil, nil, http.StatusOK},
+		{"Valid functions", sdkInterfaces.DefaultPipelineId, "", map[string]sdkInterfaces.PipelineSnapshotFunction{"CompressGZIP": {}}, nil, http.StatusOK},
+		{"Unknown function", sdkInterfaces.DefaultPipelineId, "", map[string]sdkInterfaces.PipelineSnapshotFunction{"Bogus": {}}, nil, http.StatusBadRequest},
+		{"Import failed", sdkInterfaces.DefaultPipelineId, "", nil, errors.New("invalid pipeline"), http.StatusBadRequest},
+		{"Unknown pipeline", "other-pipeline", "", nil, nil, http.StatusNotFound},
+		{"Bad JSON", sdkInterfaces.DefaultPipelineId, "{", nil, nil, http.StatusBadRequest},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			appService := newPipelineAppService(testCase.ImportError)
+
+			body := testCase.Body
+			if len(body) == 0 {
+				data, err := json.Marshal(UpdatePipelineRequest{
+					BaseRequest:              commonDtos.BaseRequest{RequestId: expectedRequestId, Versionable: commonDtos.NewVersionable()},
+					ExecutionOrder:           &executionOrder,
+					UseTargetTypeOfByteArray: &byteArray,
+					Functions:                testCase.Functions,
+				})
+				require.NoError(t, err)
+				body = string(data)
+			}
+
+			target := NewController(nil, newPipelineSnapshotDic(appService))
+			recorder := doPipelineRequest(t, http.MethodPut, map[string]string{common.Id: testCase.Id}, target.UpdatePipeline, body)
+
+			actual := PipelineResponse{}
+			err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			assert.Equal(t, testCase.ExpectedStatus, actual.StatusCode)
+			if testCase.ExpectedStatus == http.StatusOK {
+				snapshot := expected
+				if testCase.Functions != nil {
+					snapshot.Functions = testCase.Functions
+				}
+				appService.AssertCalled(t, "ImportPipelineSnapshot", snapshot)
+				assert.Equal(t, expectedRequestId, actual.RequestId)
+			}
+		})
+	}
+}
+
+func TestUpdatePipelineFunctionRequest(t *testing.T) {
+	parameters := map[string]string{"Type": "xml"}
+
+	tests := []struct {
+		Name           string
+		Id             string
+		FunctionName   string
+		Disabled       bool
+		ImportError    error
+		ExpectedStatus int
+	}{
+		{"Valid update", sdkInterfaces.DefaultPipelineId, "Transform", false, nil, http.StatusOK},
+		{"Valid add", sdkInterfaces.DefaultPipelineId, "HTTPExportBackup", false, nil, http.StatusOK},
+		{"Valid disable", sdkInterfaces.DefaultPipelineId, "Transform", true, nil, http.StatusOK},
+		{"Unknown function", sdkInterfaces.DefaultPipelineId, "Bogus", false, nil, http.StatusBadRequest},
+		{"Import failed", sdkInterfaces.DefaultPipelineId, "Transform", false, errors.New("invalid pipeline"), http.StatusBadRequest},
+		{"Unknown pipeline", "other-pipeline", "Transform", false, nil, http.StatusNotFound},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			appService := newPipelineAppService(testCase.ImportError)
+
+			data, err := json.Marshal(UpdatePipelineFunctionRequest{
+				BaseRequest: commonDtos.BaseRequest{Versionable: commonDtos.NewVersionable()},
+				Parameters:  parameters,
+				Disabled:    testCase.Disabled,
+			})
+			require.NoError(t, err)
+
+			target := NewController(nil, newPipelineSnapshotDic(appService))
+			vars := map[string]string{common.Id: testCase.Id, common.Name: testCase.FunctionName}
+			recorder := doPipelineRequest(t, http.MethodPut, vars, target.UpdatePipelineFunction, string(data))
+
+			actual := PipelineResponse{}
+			err = json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			assert.Equal(t, testCase.ExpectedStatus, actual.StatusCode)
+			if testCase.ExpectedStatus == http.StatusOK {
+				expected := newTestPipelineSnapshot()
+				expected.Functions[testCase.FunctionName] = sdkInterfaces.PipelineSnapshotFunction{Parameters: parameters, Disabled: testCase.Disabled}
+				appService.AssertCalled(t, "ImportPipelineSnapshot", expected)
+			}
+		})
+	}
+}
+
+func TestDeletePipelineFunctionRequest(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Id             string
+		FunctionName   string
+		ExpectedStatus int
+	}{
+		{"Valid", sdkInterfaces.DefaultPipelineId, "Transform", http.StatusOK},
+		{"Function not found", sdkInterfaces.DefaultPipelineId, "Compress", http.StatusNotFound},
+		{"Unknown pipeline", "other-pipeline", "Transform", http.StatusNotFound},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			appService := newPipelineAppService(nil)
+
+			target := NewController(nil, newPipelineSnapshotDic(appService))
+			vars := map[string]string{common.Id: testCase.Id, common.Name: testCase.FunctionName}
+			recorder := doPipelineRequest(t, http.MethodDelete, vars, target.DeletePipelineFunction, "")
+
+			actual := PipelineResponse{}
+			err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			assert.Equal(t, testCase.ExpectedStatus, actual.StatusCode)
+			if testCase.ExpectedStatus == http.StatusOK {
+				expected := newTestPipelineSnapshot()
+				expected.ExecutionOrder = "FilterByDeviceName, HTTPExport"
+				delete(expected.Functions, testCase.FunctionName)
+				appService.AssertCalled(t, "ImportPipelineSnapshot", expected)
+			} else {
+				appService.AssertNotCalled(t, "ImportPipelineSnapshot", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPipelinePreviewRequest(t *testing.T) {
+	previews := telemetry.NewPayloadPreviewTracker()
+	previews.Record(sdkCommon.PayloadPreviewInfo{Enabled: true}, telemetry.PayloadPreview{
+		PipelineId: sdkInterfaces.DefaultPipelineId,
+		Function:   "HTTPPost",
+		Context:    map[string]string{"apikey": "secret"},
+	}, []byte(`{"id":"1"}`))
+
+	tests := []struct {
+		Name           string
+		Enabled        bool
+		Previews       *telemetry.PayloadPreviewTracker
+		Id             string
+		ExpectedStatus int
+	}{
+		{"Valid", true, previews, sdkInterfaces.DefaultPipelineId, http.StatusOK},
+		{"Not enabled", false, previews, sdkInterfaces.DefaultPipelineId, http.StatusServiceUnavailable},
+		{"Unknown pipeline", true, previews, "other-pipeline", http.StatusNotFound},
+		{"No preview yet", true, telemetry.NewPayloadPreviewTracker(), sdkInterfaces.DefaultPipelineId, http.StatusNotFound},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			dic := newPipelineSnapshotDic(newPipelineAppService(nil))
+			config := &sdkCommon.ConfigurationStruct{}
+			config.Writable.PayloadPreview.Enabled = testCase.Enabled
+			dic.Update(di.ServiceConstructorMap{
+				container.ConfigurationName: func(get di.Get) interface{} {
+					return config
+				},
+				container.PayloadPreviewTrackerName: func(get di.Get) interface{} {
+					return testCase.Previews
+				},
+			})
+
+			target := NewController(nil, dic)
+			recorder := doPipelineRequest(t, http.MethodGet, map[string]string{common.Id: testCase.Id}, target.PipelinePreview, "")
+
+			actual := PayloadPreviewResponse{}
+			err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			assert.Equal(t, testCase.ExpectedStatus, actual.StatusCode)
+			if testCase.ExpectedStatus == http.StatusOK {
+				assert.Equal(t, `{"id":"1"}`, actual.Preview.Payload)
+				assert.Equal(t, "HTTPPost", actual.Preview.Function)
+				assert.Equal(t, "<redacted>", actual.Preview.Context["apikey"])
+			}
+		})
+	}
+}
+
+func TestTestPipelineRequest(t *testing.T) {
+	result := sdkInterfaces.PipelineTestResult{
+		Completed: true,
+		Steps:     []sdkInterfaces.PipelineTestStep{{Function: "HTTPExport", Mocked: true, Continued: true}},
+	}
+
+	tests := []struct {
+		Name           string
+		Body           string
+		TestError      error
+		ExpectedStatus int
+	}{
+		{"Valid", `{"test":{"payload":"{}"}}`, nil, http.StatusOK},
+		{"Valid pipeline id", `{"pipelineId":"default-pipeline","test":{"payload":"{}"}}`, nil, http.StatusOK},
+		{"Unknown pipeline", `{"pipelineId":"other-pipeline","test":{"payload":"{}"}}`, nil, http.StatusNotFound},
+		{"Invalid JSON", `{`, nil, http.StatusBadRequest},
+		{"Test failed", `{"test":{"payload":"bogus"}}`, errors.New("unable to process payload"), http.StatusBadRequest},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			appService := newPipelineAppService(nil)
+			appService.On("TestPipeline", mock.Anything).Return(result, testCase.TestError)
+
+			target := NewController(nil, newPipelineSnapshotDic(appService))
+			recorder := doPipelineRequest(t, http.MethodPost, nil, target.TestPipeline, testCase.Body)
+
+			actual := PipelineTestResponse{}
+			err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			assert.Equal(t, testCase.ExpectedStatus, actual.StatusCode)
+			if testCase.ExpectedStatus == http.StatusOK {
+				assert.Equal(t, result, actual.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rest
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
+)
+
+const (
+	profilingTokenKey          = "token"
+	profileExecutionsParameter = "executions"
+	profileTimeoutParameter    = "timeout"
+	defaultProfileMaxDuration  = 60 * time.Second
+	bearerPrefix               = "Bearer "
+)
+
+// ProfilingAuth wraps the handler of a profiling endpoint so that requests must send the token from the Profiling
+// SecretPath as a Bearer token in the Authorization header. The token is retrieved for each request so that a
+// rotated token is used.
+func (c *Controller) ProfilingAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		secretPath := c.config.Profiling.SecretPath
+		if len(secretPath) == 0 || c.secretProvider == nil {
+			c.lc.Error("Profiling request rejected as no SecretPath is configured for the profiling token")
+			http.Error(writer, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		secrets, err := c.secretProvider.GetSecret(secretPath, profilingTokenKey)
+		if err != nil || len(secrets[profilingTokenKey]) == 0 {
+			c.lc.Errorf("Profiling request rejected as unable to get the profiling token from secret path '%s'", secretPath)
+			http.Error(writer, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		authorization := request.Header.Get("Authorization")
+		token := strings.TrimPrefix(authorization, bearerPrefix)
+		if !strings.HasPrefix(authorization, bearerPrefix) ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(secrets[profilingTokenKey])) != 1 {
+			writer.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(writer, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		handler(writer, request)
+	}
+}
+
+// ProfilePipeline handles the request to the /profile/pipeline endpoint, which takes a CPU profile of the next
+// executions of the functions pipeline. The profile is returned in the pprof format, which `go tool pprof -http`
+// shows as a flame graph.
+func (c *Controller) ProfilePipeline(writer http.ResponseWriter, request *http.Request) {
+	if c.profiler == nil {
+		c.sendError(writer, request, errors.KindServiceUnavailable, "Pipeline profiling not available", nil, "")
+		return
+	}
+
+	maxDuration := defaultProfileMaxDuration
+	if len(c.config.Profiling.MaxDuration) > 0 {
+		duration, err := time.ParseDuration(c.config.Profiling.MaxDuration)
+		if err != nil {
+			c.sendError(writer, request, errors.KindServerError, "Invalid Profiling MaxDuration configured", err, "")
+			return
+		}
+		maxDuration = duration
+	}
+
+	executions := 1
+	if value := request.URL.Query().Get(profileExecutionsParameter); len(value) > 0 {
+		var err error
+		executions, err = strconv.Atoi(value)
+		if err != nil || executions < 1 {
+			c.sendError(writer, request, errors.KindContractInvalid,
+				fmt.Sprintf("Query parameter '%s' must be a positive integer", profileExecutionsParameter), err, "")
+			return
+		}
+	}
+
+	timeout := maxDuration
+	if value := request.URL.Query().Get(profileTimeoutParameter); len(value) > 0 {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			c.sendError(writer, request, errors.KindContractInvalid,
+				fmt.Sprintf("Query parameter '%s' must be a positive duration", profileTimeoutParameter), err, "")
+			return
+		}
+		if duration < timeout {
+			timeout = duration
+		}
+	}
+
+	c.lc.Infof("Profiling the next %d functions pipeline executions for up to %s", executions, timeout)
+	profile, err := c.profiler.Profile(request.Context(), executions, timeout)
+	if err != nil {
+		errKind := errors.KindServerError
+		if err == telemetry.ErrProfileInProgress {
+			errKind = errors.KindStatusConflict
+		}
+		c.sendError(writer, request, errKind, "Profiling the functions pipeline failed", err, "")
+		return
+	}
+
+	c.lc.Infof("Profiled %d functions pipeline executions in %s", profile.Executions, profile.Duration)
+
+	writer.Header().Set("Content-Type", "application/octet-stream")
+	writer.Header().Set("Content-Disposition", `attachment; filename="pipeline.pprof"`)
+	writer.Header().Set("X-Profile-Executions", strconv.Itoa(profile.Executions))
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write(profile.Data)
+}
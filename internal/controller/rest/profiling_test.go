@@ -0,0 +1,148 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProfilingController(config sdkCommon.ProfilingInfo, profiler *telemetry.PipelineProfiler) *Controller {
+	mockProvider := &mocks.SecretProvider{}
+	mockProvider.On("GetSecret", "profiling", profilingTokenKey).Return(map[string]string{profilingTokenKey: "secret-token"}, nil)
+	mockProvider.On("GetSecret", "missing", profilingTokenKey).Return(nil, errors.New("not found"))
+
+	profilingDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &sdkCommon.ConfigurationStruct{Profiling: config}
+		},
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockProvider
+		},
+		container.PipelineProfilerName: func(get di.Get) interface{} {
+			return profiler
+		},
+	})
+
+	return NewController(nil, profilingDic)
+}
+
+func TestProfilingAuth(t *testing.T) {
+	tests := []struct {
+		Name               string
+		SecretPath         string
+		Authorization      string
+		ExpectedStatusCode int
+	}{
+		{"Valid token", "profiling", "Bearer secret-token", http.StatusOK},
+		{"Wrong token", "profiling", "Bearer bogus", http.StatusUnauthorized},
+		{"Missing bearer prefix", "profiling", "secret-token", http.StatusUnauthorized},
+		{"Missing authorization", "profiling", "", http.StatusUnauthorized},
+		{"No secret path", "", "Bearer secret-token", http.StatusForbidden},
+		{"Missing secret", "missing", "Bearer secret-token", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := newProfilingController(sdkCommon.ProfilingInfo{Enabled: true, SecretPath: test.SecretPath}, nil)
+			handler := target.ProfilingAuth(func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, internal.ApiProfilePipelineRoute, nil)
+			if len(test.Authorization) > 0 {
+				request.Header.Set("Authorization", test.Authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+
+			assert.Equal(t, test.ExpectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestProfilePipelineRequest(t *testing.T) {
+	profiler := telemetry.NewPipelineProfiler()
+	target := newProfilingController(sdkCommon.ProfilingInfo{Enabled: true, SecretPath: "profiling", MaxDuration: "5s"}, profiler)
+
+	done := make(chan struct{})
+	go func() {
+		// Executions complete until the profile has been returned
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				profiler.ExecutionDone()
+			}
+		}
+	}()
+
+	request := httptest.NewRequest(http.MethodGet, internal.ApiProfilePipelineRoute+"?executions=3", nil)
+	recorder := httptest.NewRecorder()
+	target.ProfilePipeline(recorder, request)
+	close(done)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "3", recorder.Header().Get("X-Profile-Executions"))
+	assert.NotEmpty(t, recorder.Body.Bytes())
+}
+
+func TestProfilePipelineRequest_Invalid(t *testing.T) {
+	tests := []struct {
+		Name               string
+		Query              string
+		MaxDuration        string
+		ExpectedStatusCode int
+	}{
+		{"Invalid executions", "?executions=bogus", "", http.StatusBadRequest},
+		{"Zero executions", "?executions=0", "", http.StatusBadRequest},
+		{"Invalid timeout", "?timeout=bogus", "", http.StatusBadRequest},
+		{"Invalid max duration", "", "bogus", http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := newProfilingController(sdkCommon.ProfilingInfo{Enabled: true, SecretPath: "profiling", MaxDuration: test.MaxDuration}, telemetry.NewPipelineProfiler())
+
+			request := httptest.NewRequest(http.MethodGet, internal.ApiProfilePipelineRoute+test.Query, nil)
+			recorder := httptest.NewRecorder()
+			target.ProfilePipeline(recorder, request)
+
+			assert.Equal(t, test.ExpectedStatusCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/messaging"
+
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+// Names of the configurable function parameters which specify the secrets the functions use
+const (
+	secretPathParameter = "secretpath"
+	secretNameParameter = "secretname"
+	authModeParameter   = "authmode"
+)
+
+// secretKeysByFunction returns the keys each configurable function that uses secrets requires at its SecretPath,
+// keyed by the function name
+var secretKeysByFunction = map[string]func(parameters map[string]string) []string{
+	"HTTPExport":          secretNameKeys,
+	"FileExport":          secretNameKeys,
+	"Encrypt":             secretNameKeys,
+	"Redact":              secretNameKeys,
+	"VerifySignature":     secretNameKeys,
+	"MQTTExport":          authModeKeys,
+	"AMQPExport":          authModeKeys,
+	"ForwardToEKuiper":    authModeKeys,
+	"EmailExport":         authModeKeys,
+	"SparkplugBExport":    authModeKeys,
+	"NGSILDExport":        secretNameKeys,
+	"SensorThingsExport":  secretNameKeys,
+	"ElasticsearchExport": elasticsearchKeys,
+	"WebSocketExport":     secretNameKeys,
+	"V1Export":            secretNameKeys,
+}
+
+func secretNameKeys(parameters map[string]string) []string {
+	if secretName := parameters[secretNameParameter]; len(secretName) > 0 {
+		return []string{secretName}
+	}
+	return nil
+}
+
+func elasticsearchKeys(parameters map[string]string) []string {
+	switch strings.ToLower(parameters[authModeParameter]) {
+	case "basic":
+		return []string{"username", "password"}
+	case "apikey":
+		return []string{"apikey"}
+	default:
+		return nil
+	}
+}
+
+func authModeKeys(parameters map[string]string) []string {
+	switch strings.ToLower(parameters[authModeParameter]) {
+	case messaging.AuthModeUsernamePassword:
+		return []string{messaging.SecretUsernameKey, messaging.SecretPasswordKey}
+	case messaging.AuthModeCert:
+		return []string{messaging.SecretClientCert, messaging.SecretClientKey}
+	case messaging.AuthModeCA:
+		return []string{messaging.SecretCACert}
+	default:
+		return nil
+	}
+}
+
+// validateRequiredSecretKeys returns an error if the secret for the path is missing any of the keys required by
+// the functions in the configurable functions pipeline whose SecretPath is the path
+func validateRequiredSecretKeys(pipeline sdkCommon.PipelineInfo, path string, secret map[string]string) error {
+	path = strings.Trim(path, "/")
+	missing := make(map[string][]string)
+
+	executionOrder := util.DeleteEmptyAndTrim(strings.FieldsFunc(pipeline.ExecutionOrder, util.SplitComma))
+	for _, functionName := range executionOrder {
+		function, found := pipeline.Functions[functionName]
+		if !found {
+			continue
+		}
+
+		parameters := make(map[string]string, len(function.Parameters))
+		for name, value := range function.Parameters {
+			parameters[strings.ToLower(name)] = strings.TrimSpace(value)
+		}
+
+		if strings.Trim(parameters[secretPathParameter], "/") != path {
+			continue
+		}
+
+		for name, requiredKeys := range secretKeysByFunction {
+			// Pipeline function names only need to start with the name of the configurable function
+			if !strings.HasPrefix(functionName, name) {
+				continue
+			}
+
+			for _, key := range requiredKeys(parameters) {
+				if _, found := secret[key]; !found {
+					missing[key] = append(missing[key], functionName)
+				}
+			}
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var details []string
+	for key, functionNames := range missing {
+		details = append(details, fmt.Sprintf("'%s' required by %s", key, strings.Join(functionNames, ", ")))
+	}
+	sort.Strings(details)
+
+	return fmt.Errorf("secret at path '%s' is missing keys: %s", path, strings.Join(details, "; "))
+}
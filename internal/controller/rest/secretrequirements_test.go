@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rest
+
+import (
+	"testing"
+
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequiredSecretKeys(t *testing.T) {
+	pipeline := sdkCommon.PipelineInfo{
+		ExecutionOrder: "HTTPExportCloud, MQTTExport, Encrypt, EmailExport",
+		Functions: map[string]sdkCommon.PipelineFunction{
+			"HTTPExportCloud": {Parameters: map[string]string{"SecretPath": "/cloud", "SecretName": "token", "HeaderName": "Authorization"}},
+			"MQTTExport":      {Parameters: map[string]string{"secretpath": "broker", "authmode": "clientcert"}},
+			"Encrypt":         {Parameters: map[string]string{"SecretPath": "cloud", "SecretName": "aeskey"}},
+			"EmailExport":     {Parameters: map[string]string{"SecretPath": "smtp", "AuthMode": "none"}},
+			"HTTPExportOther": {Parameters: map[string]string{"SecretPath": "other", "SecretName": "token"}},
+		},
+	}
+
+	tests := []struct {
+		Name          string
+		Path          string
+		Secret        map[string]string
+		ExpectedError string
+	}{
+		{"All keys", "cloud", map[string]string{"token": "123", "aeskey": "abc"}, ""},
+		{"Extra keys", "/cloud/", map[string]string{"token": "123", "aeskey": "abc", "extra": "x"}, ""},
+		{"Missing key", "cloud", map[string]string{"token": "123"}, "secret at path 'cloud' is missing keys: 'aeskey' required by Encrypt"},
+		{"Missing cert keys", "broker", map[string]string{"clientcert": "cert"}, "'clientkey' required by MQTTExport"},
+		{"No keys required by AuthMode", "smtp", map[string]string{"other": "x"}, ""},
+		{"Function not in execution order", "other", map[string]string{"other": "x"}, ""},
+		{"Path not used", "unused", map[string]string{"other": "x"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := validateRequiredSecretKeys(pipeline, test.Path, test.Secret)
+			if len(test.ExpectedError) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ExpectedError)
+		})
+	}
+}
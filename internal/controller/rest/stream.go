@@ -0,0 +1,245 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	sdkInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+const (
+	streamWriteTimeout = 10 * time.Second
+	streamPingInterval = 30 * time.Second
+	// streamPongTimeout is how long to wait for the client to answer a ping before it is considered gone
+	streamPongTimeout = streamPingInterval + streamWriteTimeout
+	allOrigins        = "*"
+
+	defaultEventsKeepAliveInterval = 15 * time.Second
+	contentTypeEventStream         = "text/event-stream"
+	// binaryEventType is the type of the events whose data is base64 encoded as the response data isn't UTF-8, while
+	// the other events have the default "message" type
+	binaryEventType = "binary"
+)
+
+// Stream handles the request to the /stream endpoint, upgrading the connection to a WebSocket over which the data
+// published by the StreamExport pipeline function is pushed, one message each. UTF-8 data is sent as text messages
+// and other data as binary messages.
+func (c *Controller) Stream(writer http.ResponseWriter, request *http.Request) {
+	if c.streamHub == nil {
+		c.sendError(writer, request, errors.KindServiceUnavailable, "Stream not enabled", nil, "")
+		return
+	}
+
+	client, err := c.streamHub.Register()
+	if err != nil {
+		c.sendError(writer, request, errors.KindServiceUnavailable, "Unable to connect to stream", err, "")
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: c.checkStreamOrigin}
+	conn, err := upgrader.Upgrade(writer, request, nil)
+	if err != nil {
+		// The Upgrader has already responded with the error
+		c.streamHub.Unregister(client)
+		c.lc.Errorf("Unable to upgrade stream connection from %s: %s", request.RemoteAddr, err.Error())
+		return
+	}
+
+	c.lc.Infof("Stream client connected from %s", request.RemoteAddr)
+
+	// The client only sends control messages, which are handled while reading. Reading fails once the client has
+	// disconnected or stopped answering pings, which unregisters the client and so ends the writing below.
+	go func() {
+		defer c.streamHub.Unregister(client)
+		_ = conn.SetReadDeadline(time.Now().Add(streamPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(streamPongTimeout))
+		})
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer func() {
+		ping.Stop()
+		c.streamHub.Unregister(client)
+		_ = conn.Close()
+		c.lc.Infof("Stream client from %s disconnected. %d messages dropped as the client wasn't keeping up", request.RemoteAddr, client.Dropped())
+	}()
+
+	for {
+		select {
+		case data, ok := <-client.Messages():
+			if !ok {
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(streamWriteTimeout))
+				return
+			}
+
+			messageType := websocket.TextMessage
+			if !utf8.Valid(data) {
+				messageType = websocket.BinaryMessage
+			}
+
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// checkStreamOrigin allows connections from pages on the service's own origin and on the Stream AllowedOrigins
+func (c *Controller) checkStreamOrigin(request *http.Request) bool {
+	origin := request.Header.Get("Origin")
+	if len(origin) == 0 {
+		// Not a browser, which are the only clients sending the origin
+		return true
+	}
+
+	for _, allowed := range c.config.Stream.AllowedOrigins {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == allOrigins || strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	originUrl, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(originUrl.Host, request.Host)
+}
+
+// PipelineEvents handles the request to the /pipelines/{id}/events endpoint, streaming the response data of each
+// execution of the pipeline with the id to the client as Server-Sent Events. UTF-8 response data is sent as "message"
+// events and other data base64 encoded as "binary" events.
+func (c *Controller) PipelineEvents(writer http.ResponseWriter, request *http.Request) {
+	if c.pipelineHubs == nil {
+		c.sendError(writer, request, errors.KindServiceUnavailable, "Server-Sent Events not enabled", nil, "")
+		return
+	}
+
+	id := mux.Vars(request)[common.Id]
+	if id != sdkInterfaces.DefaultPipelineId {
+		c.sendError(writer, request, errors.KindEntityDoesNotExist, fmt.Sprintf("Pipeline '%s' not found", id), nil, "")
+		return
+	}
+
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		c.sendError(writer, request, errors.KindServerError, "Streaming not supported by the connection", nil, "")
+		return
+	}
+
+	keepAliveInterval := defaultEventsKeepAliveInterval
+	if value := c.config.ServerSentEvents.KeepAliveInterval; len(value) > 0 {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			c.sendError(writer, request, errors.KindServerError, fmt.Sprintf("ServerSentEvents.KeepAliveInterval '%s' is invalid", value), err, "")
+			return
+		}
+		keepAliveInterval = interval
+	}
+
+	hub := c.pipelineHubs.Hub(id)
+	client, err := hub.Register()
+	if err != nil {
+		c.sendError(writer, request, errors.KindServiceUnavailable, "Unable to subscribe to pipeline events", err, "")
+		return
+	}
+	defer hub.Unregister(client)
+
+	writer.Header().Set(common.ContentType, contentTypeEventStream)
+	writer.Header().Set("Cache-Control", "no-cache")
+	// Disables the response buffering of proxies such as nginx, which would hold back the events
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	c.lc.Infof("Server-Sent Events client subscribed to pipeline '%s' from %s", id, request.RemoteAddr)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer func() {
+		keepAlive.Stop()
+		c.lc.Infof("Server-Sent Events client from %s unsubscribed. %d events dropped as the client wasn't keeping up", request.RemoteAddr, client.Dropped())
+	}()
+
+	var eventId uint64
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case data, ok := <-client.Messages():
+			if !ok {
+				return
+			}
+
+			eventId++
+			if _, err := writer.Write(formatServerSentEvent(eventId, data)); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := writer.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// formatServerSentEvent formats the data as an event of the text/event-stream format, with a data line for each line
+// of the data
+func formatServerSentEvent(id uint64, data []byte) []byte {
+	var event bytes.Buffer
+	fmt.Fprintf(&event, "id: %d\n", id)
+
+	if !utf8.Valid(data) {
+		fmt.Fprintf(&event, "event: %s\n", binaryEventType)
+		data = []byte(base64.StdEncoding.EncodeToString(data))
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		event.WriteString("data: ")
+		event.WriteString(line)
+		event.WriteString("\n")
+	}
+	event.WriteString("\n")
+
+	return event.Bytes()
+}
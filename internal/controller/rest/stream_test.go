@@ -0,0 +1,232 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rest
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/stream"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamController(config sdkCommon.StreamInfo, hub *stream.Hub) *Controller {
+	streamDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &sdkCommon.ConfigurationStruct{Stream: config}
+		},
+		container.StreamHubName: func(get di.Get) interface{} {
+			return hub
+		},
+	})
+
+	return NewController(nil, streamDic)
+}
+
+func TestStreamRequest(t *testing.T) {
+	hub := stream.NewHub(1, 10)
+	target := newStreamController(sdkCommon.StreamInfo{Enabled: true}, hub)
+
+	server := httptest.NewServer(http.HandlerFunc(target.Stream))
+	defer server.Close()
+	streamURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(streamURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool { return hub.Clients() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// The most clients allowed are already connected
+	_, response, err := websocket.DefaultDialer.Dial(streamURL, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+	assert.Equal(t, 1, hub.Publish([]byte(`{"id":"1"}`)))
+	assert.Equal(t, 1, hub.Publish([]byte{0xff, 0xfe}))
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	messageType, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, messageType)
+	assert.Equal(t, `{"id":"1"}`, string(data))
+
+	messageType, data, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+	assert.Equal(t, []byte{0xff, 0xfe}, data)
+
+	// The client is unregistered once it disconnects
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool { return hub.Clients() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStreamRequestNotEnabled(t *testing.T) {
+	target := newStreamController(sdkCommon.StreamInfo{}, nil)
+
+	recorder := httptest.NewRecorder()
+	target.Stream(recorder, httptest.NewRequest(http.MethodGet, internal.ApiStreamRoute, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+func TestCheckStreamOrigin(t *testing.T) {
+	tests := []struct {
+		Name           string
+		AllowedOrigins []string
+		Origin         string
+		Expected       bool
+	}{
+		{"No origin", nil, "", true},
+		{"Same origin", nil, "http://app-service:59700", true},
+		{"Other origin", nil, "http://dashboard:3000", false},
+		{"Allowed origin", []string{"http://dashboard:3000/"}, "http://dashboard:3000", true},
+		{"Not allowed origin", []string{"http://dashboard:3000"}, "http://other:3000", false},
+		{"All origins", []string{"*"}, "http://other:3000", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := newStreamController(sdkCommon.StreamInfo{Enabled: true, AllowedOrigins: test.AllowedOrigins}, nil)
+
+			request := httptest.NewRequest(http.MethodGet, "http://app-service:59700"+internal.ApiStreamRoute, nil)
+			if len(test.Origin) > 0 {
+				request.Header.Set("Origin", test.Origin)
+			}
+
+			assert.Equal(t, test.Expected, target.checkStreamOrigin(request))
+		})
+	}
+}
+
+func newPipelineEventsServer(config sdkCommon.ServerSentEventsInfo, hubs *stream.PipelineHubs) *httptest.Server {
+	eventsDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &sdkCommon.ConfigurationStruct{ServerSentEvents: config}
+		},
+		container.PipelineHubsName: func(get di.Get) interface{} {
+			return hubs
+		},
+	})
+
+	router := mux.NewRouter()
+	router.HandleFunc(internal.ApiPipelineEventsRoute, NewController(router, eventsDic).PipelineEvents)
+	return httptest.NewServer(router)
+}
+
+func pipelineEventsRoute(id string) string {
+	return strings.Replace(internal.ApiPipelineEventsRoute, "{id}", id, 1)
+}
+
+func TestPipelineEventsRequest(t *testing.T) {
+	hubs := stream.NewPipelineHubs(1, 10)
+	server := newPipelineEventsServer(sdkCommon.ServerSentEventsInfo{Enabled: true}, hubs)
+	defer server.Close()
+	eventsURL := server.URL + pipelineEventsRoute(interfaces.DefaultPipelineId)
+
+	resp, err := http.Get(eventsURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	hub := hubs.Hub(interfaces.DefaultPipelineId)
+	require.Eventually(t, func() bool { return hub.Clients() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// The most clients allowed are already subscribed
+	second, err := http.Get(eventsURL)
+	require.NoError(t, err)
+	_ = second.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, second.StatusCode)
+
+	assert.Equal(t, 1, hubs.Publish(interfaces.DefaultPipelineId, []byte("line1\nline2")))
+	assert.Equal(t, 1, hubs.Publish(interfaces.DefaultPipelineId, []byte{0xff, 0xfe}))
+
+	expected := []string{
+		"id: 1", "data: line1", "data: line2", "",
+		"id: 2", "event: binary", "data: //4=", "",
+	}
+	reader := bufio.NewReader(resp.Body)
+	for _, expectedLine := range expected {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, expectedLine, strings.TrimSuffix(line, "\n"))
+	}
+}
+
+func TestPipelineEventsRequestKeepAlive(t *testing.T) {
+	hubs := stream.NewPipelineHubs(1, 10)
+	server := newPipelineEventsServer(sdkCommon.ServerSentEventsInfo{Enabled: true, KeepAliveInterval: "10ms"}, hubs)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + pipelineEventsRoute(interfaces.DefaultPipelineId))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": keep-alive\n", line)
+}
+
+func TestPipelineEventsRequestErrors(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Config         sdkCommon.ServerSentEventsInfo
+		Hubs           *stream.PipelineHubs
+		PipelineId     string
+		ExpectedStatus int
+	}{
+		{"Not enabled", sdkCommon.ServerSentEventsInfo{}, nil, interfaces.DefaultPipelineId, http.StatusServiceUnavailable},
+		{"Unknown pipeline", sdkCommon.ServerSentEventsInfo{Enabled: true}, stream.NewPipelineHubs(1, 1), "bogus", http.StatusNotFound},
+		{"Bad keep alive interval", sdkCommon.ServerSentEventsInfo{Enabled: true, KeepAliveInterval: "bogus"}, stream.NewPipelineHubs(1, 1), interfaces.DefaultPipelineId, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			server := newPipelineEventsServer(test.Config, test.Hubs)
+			defer server.Close()
+
+			resp, err := http.Get(server.URL + pipelineEventsRoute(test.PipelineId))
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, test.ExpectedStatus, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package faultinjection
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-messaging/v2/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+)
+
+// defaultFunctions are the SDK's export functions, which faults are injected into when none are configured
+var defaultFunctions = []string{"HTTPPost", "HTTPPut", "MQTTSend", "SendEmail", "SendNotification"}
+
+// ErrInjected is the error returned for injected failures
+var ErrInjected = errors.New("fault injected")
+
+// Injector injects random failures and latency at the configured rates
+type Injector struct {
+	config    common.FaultInjectionInfo
+	latency   time.Duration
+	functions []string
+	mutex     sync.Mutex
+	random    *rand.Rand
+}
+
+// NewInjector creates an Injector from the configuration, validating the rates and latency
+func NewInjector(config common.FaultInjectionInfo) (*Injector, error) {
+	rates := map[string]float64{
+		"FailureRate":        config.FailureRate,
+		"LatencyRate":        config.LatencyRate,
+		"PublishFailureRate": config.PublishFailureRate,
+	}
+	for name, rate := range rates {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("FaultInjection %s must be between 0 and 1, got %v", name, rate)
+		}
+	}
+
+	injector := &Injector{
+		config:    config,
+		functions: config.Functions,
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	if len(injector.functions) == 0 {
+		injector.functions = defaultFunctions
+	}
+
+	if len(config.Latency) > 0 {
+		latency, err := time.ParseDuration(config.Latency)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse FaultInjection Latency '%s': %s", config.Latency, err.Error())
+		}
+		injector.latency = latency
+	}
+
+	return injector, nil
+}
+
+// Functions returns the names of the pipeline functions faults are injected into
+func (injector *Injector) Functions() []string {
+	return injector.functions
+}
+
+// occurs returns true with the specified probability
+func (injector *Injector) occurs(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+	return injector.random.Float64() < rate
+}
+
+// targeted returns true if faults are to be injected into the named function
+func (injector *Injector) targeted(functionName string) bool {
+	// Function names include the package and receiver, i.e. transforms.HTTPSender.HTTPPost
+	name := functionName[strings.LastIndex(functionName, ".")+1:]
+	for _, target := range injector.functions {
+		if strings.EqualFold(name, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Interceptor returns the pipeline interceptor which injects latency and failures before the targeted functions
+// execute. Failures set the function's data as the retry data, as the export functions do, so the data is stored
+// for retry when Store and Forward is enabled.
+func (injector *Injector) Interceptor() interfaces.PipelineInterceptor {
+	return func(appContext interfaces.AppFunctionContext, execution interfaces.FunctionExecution) error {
+		if execution.Completed || !injector.targeted(execution.FunctionName) {
+			return nil
+		}
+
+		if injector.latency > 0 && injector.occurs(injector.config.LatencyRate) {
+			appContext.LoggingClient().Debugf("Injecting %s latency before '%s'", injector.latency.String(), execution.FunctionName)
+			time.Sleep(injector.latency)
+		}
+
+		if !injector.occurs(injector.config.FailureRate) {
+			return nil
+		}
+
+		appContext.LoggingClient().Debugf("Injecting failure into '%s'", execution.FunctionName)
+		if data, err := util.CoerceType(execution.Data); err == nil {
+			appContext.SetRetryData(data)
+		}
+
+		return ErrInjected
+	}
+}
+
+// WrapMessageClient returns a MessageClient which fails publishes at the configured rate
+func (injector *Injector) WrapMessageClient(client messaging.MessageClient) messaging.MessageClient {
+	if injector.config.PublishFailureRate <= 0 {
+		return client
+	}
+
+	return &faultyMessageClient{MessageClient: client, injector: injector}
+}
+
+type faultyMessageClient struct {
+	messaging.MessageClient
+	injector *Injector
+}
+
+// Publish fails with ErrInjected at the configured rate, otherwise publishes the message
+func (client *faultyMessageClient) Publish(message types.MessageEnvelope, topic string) error {
+	if client.injector.occurs(client.injector.config.PublishFailureRate) {
+		return ErrInjected
+	}
+
+	return client.MessageClient.Publish(message, topic)
+}
@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package faultinjection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-messaging/v2/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dic = di.NewContainer(di.ServiceConstructorMap{
+	bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+		return logger.NewMockClient()
+	},
+})
+
+func TestNewInjector(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Config        common.FaultInjectionInfo
+		ErrorContains string
+	}{
+		{"Valid", common.FaultInjectionInfo{FailureRate: 0.5, LatencyRate: 1, Latency: "10ms", PublishFailureRate: 0}, ""},
+		{"Rate too high", common.FaultInjectionInfo{FailureRate: 1.5}, "FailureRate"},
+		{"Negative rate", common.FaultInjectionInfo{PublishFailureRate: -0.1}, "PublishFailureRate"},
+		{"Bad latency", common.FaultInjectionInfo{Latency: "soon"}, "Latency"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := NewInjector(test.Config)
+			if len(test.ErrorContains) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ErrorContains)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestInjector_Targeted(t *testing.T) {
+	injector, err := NewInjector(common.FaultInjectionInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultFunctions, injector.Functions())
+	assert.True(t, injector.targeted("github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms.HTTPSender.HTTPPost"))
+	assert.False(t, injector.targeted("transforms.Conversion.TransformToJSON"))
+
+	injector, err = NewInjector(common.FaultInjectionInfo{Functions: []string{"transformtojson"}})
+	require.NoError(t, err)
+	assert.True(t, injector.targeted("transforms.Conversion.TransformToJSON"))
+	assert.False(t, injector.targeted("transforms.HTTPSender.HTTPPost"))
+}
+
+func TestInjector_Interceptor(t *testing.T) {
+	execution := interfaces.FunctionExecution{FunctionName: "transforms.HTTPSender.HTTPPost", Data: "payload"}
+
+	tests := []struct {
+		Name          string
+		Config        common.FaultInjectionInfo
+		Execution     interfaces.FunctionExecution
+		ExpectFailure bool
+		MinDuration   time.Duration
+	}{
+		{"Always fail", common.FaultInjectionInfo{FailureRate: 1}, execution, true, 0},
+		{"Never fail", common.FaultInjectionInfo{FailureRate: 0}, execution, false, 0},
+		{"Latency", common.FaultInjectionInfo{LatencyRate: 1, Latency: "20ms"}, execution, false, 20 * time.Millisecond},
+		{"Not targeted", common.FaultInjectionInfo{FailureRate: 1}, interfaces.FunctionExecution{FunctionName: "transforms.Filter.FilterByDeviceName"}, false, 0},
+		{"After function", common.FaultInjectionInfo{FailureRate: 1}, interfaces.FunctionExecution{FunctionName: execution.FunctionName, Completed: true}, false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			injector, err := NewInjector(test.Config)
+			require.NoError(t, err)
+
+			appContext := appfunction.NewContext("123", dic, "")
+			started := time.Now()
+			err = injector.Interceptor()(appContext, test.Execution)
+			assert.GreaterOrEqual(t, int64(time.Since(started)), int64(test.MinDuration))
+
+			if test.ExpectFailure {
+				assert.Equal(t, ErrInjected, err)
+				assert.Equal(t, []byte("payload"), appContext.RetryData(), "expected data to be set for retry")
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Nil(t, appContext.RetryData())
+		})
+	}
+}
+
+type recordingMessageClient struct {
+	messaging.MessageClient
+	published int
+}
+
+func (client *recordingMessageClient) Publish(_ types.MessageEnvelope, _ string) error {
+	client.published++
+	return nil
+}
+
+func TestInjector_WrapMessageClient(t *testing.T) {
+	client := &recordingMessageClient{}
+
+	injector, err := NewInjector(common.FaultInjectionInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, client, injector.WrapMessageClient(client), "expected client not wrapped when publishes don't fail")
+
+	injector, err = NewInjector(common.FaultInjectionInfo{PublishFailureRate: 1})
+	require.NoError(t, err)
+	err = injector.WrapMessageClient(client).Publish(types.MessageEnvelope{}, "topic")
+	assert.Equal(t, ErrInjected, err)
+	assert.Equal(t, 0, client.published)
+}
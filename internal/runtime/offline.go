@@ -0,0 +1,171 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+)
+
+const (
+	defaultOfflineFailureThreshold = 3
+	defaultOfflineRecoveryInterval = 30 * time.Second
+)
+
+// offlineMode is the circuit breaker which routes the messages through the offline pipeline while the exports of the
+// functions pipeline are failing, i.e. the cloud is unreachable, and back once they have recovered
+type offlineMode struct {
+	lock       sync.Mutex
+	transforms []interfaces.AppFunction
+	// failures is the number of consecutive messages the functions pipeline failed to process with a retryable error
+	failures int
+	// openedAt is when the messages started being routed through the offline pipeline, which is zero while they are
+	// processed by the functions pipeline
+	openedAt time.Time
+	// probing is set while a message is processed by the functions pipeline to check whether its exports recovered
+	probing bool
+}
+
+// SetOfflineTransforms sets the functions of the offline pipeline the messages are routed through while the exports of
+// the functions pipeline are failing. Offline mode is disabled when there are none.
+func (gr *GolangRuntime) SetOfflineTransforms(transforms []interfaces.AppFunction) {
+	gr.offline.setTransforms(transforms)
+}
+
+// offlineTransforms returns the functions of the offline pipeline when the message is to be routed through it, otherwise
+// nil so the message is processed by the functions pipeline
+func (gr *GolangRuntime) offlineTransforms(lc logger.LoggingClient) []interfaces.AppFunction {
+	if gr.dic == nil {
+		return nil
+	}
+
+	config := container.ConfigurationFrom(gr.dic.Get)
+	return gr.offline.route(config.Writable.Pipeline.Offline, lc)
+}
+
+// recordOutcome tracks the outcome of a message processed by the functions pipeline, for deciding whether to route
+// the following messages through the offline pipeline
+func (gr *GolangRuntime) recordOutcome(messageError *MessageError, completed bool, lc logger.LoggingClient) {
+	if gr.dic == nil {
+		return
+	}
+
+	config := container.ConfigurationFrom(gr.dic.Get)
+	gr.offline.record(config.Writable.Pipeline.Offline, messageError, completed, lc)
+}
+
+func (mode *offlineMode) setTransforms(transforms []interfaces.AppFunction) {
+	mode.lock.Lock()
+	defer mode.lock.Unlock()
+
+	mode.transforms = transforms
+	if len(transforms) == 0 {
+		mode.failures = 0
+		mode.openedAt = time.Time{}
+		mode.probing = false
+	}
+}
+
+// route returns the functions of the offline pipeline while it is active, otherwise nil. Once the RecoveryInterval
+// has passed a single message at a time is processed by the functions pipeline to check whether its exports have
+// recovered.
+func (mode *offlineMode) route(config common.OfflinePipelineInfo, lc logger.LoggingClient) []interfaces.AppFunction {
+	mode.lock.Lock()
+	defer mode.lock.Unlock()
+
+	if len(mode.transforms) == 0 || mode.openedAt.IsZero() {
+		return nil
+	}
+
+	if mode.probing || time.Since(mode.openedAt) < offlineRecoveryInterval(config, lc) {
+		return mode.transforms
+	}
+
+	mode.probing = true
+	return nil
+}
+
+// record tracks the outcome of a message processed by the functions pipeline. The offline pipeline becomes active once
+// FailureThreshold consecutive messages have failed with a retryable error and inactive once a message is exported.
+func (mode *offlineMode) record(config common.OfflinePipelineInfo, messageError *MessageError, completed bool, lc logger.LoggingClient) {
+	mode.lock.Lock()
+	defer mode.lock.Unlock()
+
+	if len(mode.transforms) == 0 {
+		return
+	}
+
+	active := !mode.openedAt.IsZero()
+
+	switch {
+	case messageError != nil && messageError.Category == util.ErrorCategoryRetryable:
+		mode.failures++
+		if active {
+			if mode.probing {
+				// Still failing, so wait another RecoveryInterval before checking again
+				mode.openedAt = time.Now()
+				mode.probing = false
+				lc.Debug("Functions pipeline exports still failing, data remains routed through the offline pipeline")
+			}
+			return
+		}
+
+		threshold := config.FailureThreshold
+		if threshold <= 0 {
+			threshold = defaultOfflineFailureThreshold
+		}
+		if mode.failures >= threshold {
+			mode.openedAt = time.Now()
+			lc.Warnf("Functions pipeline failed %d consecutive times, routing data through the offline pipeline", mode.failures)
+		}
+
+	case messageError == nil && completed:
+		if active {
+			lc.Info("Functions pipeline exports recovered, data no longer routed through the offline pipeline")
+		}
+		mode.failures = 0
+		mode.openedAt = time.Time{}
+		mode.probing = false
+
+	default:
+		// Filtered, or failed with an error which isn't retryable, which doesn't tell whether the exports recovered
+		mode.probing = false
+	}
+}
+
+// offlineRecoveryInterval returns how long the data is routed through the offline pipeline before the functions
+// pipeline is checked again, defaulting it when not set or invalid
+func offlineRecoveryInterval(config common.OfflinePipelineInfo, lc logger.LoggingClient) time.Duration {
+	if len(config.RecoveryInterval) == 0 {
+		return defaultOfflineRecoveryInterval
+	}
+
+	interval, err := time.ParseDuration(config.RecoveryInterval)
+	if err != nil || interval <= 0 {
+		lc.Warnf("Invalid Pipeline Offline RecoveryInterval '%s', using default of %s", config.RecoveryInterval, defaultOfflineRecoveryInterval)
+		return defaultOfflineRecoveryInterval
+	}
+
+	return interval
+}
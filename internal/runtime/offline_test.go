@@ -0,0 +1,163 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passThrough(_ interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	return true, data
+}
+
+func TestOfflineModeActivates(t *testing.T) {
+	lc := logger.NewMockClient()
+	config := sdkCommon.OfflinePipelineInfo{FailureThreshold: 2, RecoveryInterval: "1h"}
+	retryable := &MessageError{Err: errors.New("unreachable"), Category: util.ErrorCategoryRetryable}
+	fatal := &MessageError{Err: errors.New("invalid"), Category: util.ErrorCategoryFatal}
+
+	target := offlineMode{}
+	target.setTransforms([]interfaces.AppFunction{passThrough})
+
+	target.record(config, retryable, false, lc)
+	assert.Nil(t, target.route(config, lc), "below threshold")
+
+	target.record(config, fatal, false, lc)
+	target.record(config, nil, false, lc)
+	assert.Nil(t, target.route(config, lc), "fatal errors and filtered messages don't count")
+
+	target.record(config, nil, true, lc)
+	target.record(config, retryable, false, lc)
+	assert.Nil(t, target.route(config, lc), "exported message resets the failures")
+
+	target.record(config, retryable, false, lc)
+	assert.Len(t, target.route(config, lc), 1, "threshold reached")
+	assert.Len(t, target.route(config, lc), 1, "remains active until the recovery interval has passed")
+}
+
+func TestOfflineModeRecovery(t *testing.T) {
+	lc := logger.NewMockClient()
+	config := sdkCommon.OfflinePipelineInfo{FailureThreshold: 1, RecoveryInterval: "10ms"}
+	retryable := &MessageError{Err: errors.New("unreachable"), Category: util.ErrorCategoryRetryable}
+
+	target := offlineMode{}
+	target.setTransforms([]interfaces.AppFunction{passThrough})
+	target.record(config, retryable, false, lc)
+	require.NotNil(t, target.route(config, lc))
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Nil(t, target.route(config, lc), "message processed by the functions pipeline to check for recovery")
+	assert.NotNil(t, target.route(config, lc), "only one message at a time checks for recovery")
+
+	// The check failing waits another recovery interval
+	target.record(config, retryable, false, lc)
+	assert.NotNil(t, target.route(config, lc))
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Nil(t, target.route(config, lc))
+	target.record(config, nil, true, lc)
+	assert.Nil(t, target.route(config, lc), "exports recovered")
+	assert.Nil(t, target.route(config, lc))
+}
+
+func TestOfflineModeDisabled(t *testing.T) {
+	lc := logger.NewMockClient()
+	config := sdkCommon.OfflinePipelineInfo{FailureThreshold: 1}
+	retryable := &MessageError{Err: errors.New("unreachable"), Category: util.ErrorCategoryRetryable}
+
+	target := offlineMode{}
+	target.record(config, retryable, false, lc)
+	assert.Nil(t, target.route(config, lc), "no offline pipeline")
+
+	target.setTransforms([]interfaces.AppFunction{passThrough})
+	target.record(config, retryable, false, lc)
+	require.NotNil(t, target.route(config, lc))
+
+	target.setTransforms(nil)
+	assert.Nil(t, target.route(config, lc), "offline pipeline removed")
+}
+
+func TestOfflineRecoveryInterval(t *testing.T) {
+	lc := logger.NewMockClient()
+
+	assert.Equal(t, defaultOfflineRecoveryInterval, offlineRecoveryInterval(sdkCommon.OfflinePipelineInfo{}, lc))
+	assert.Equal(t, defaultOfflineRecoveryInterval, offlineRecoveryInterval(sdkCommon.OfflinePipelineInfo{RecoveryInterval: "bogus"}, lc))
+	assert.Equal(t, defaultOfflineRecoveryInterval, offlineRecoveryInterval(sdkCommon.OfflinePipelineInfo{RecoveryInterval: "-1s"}, lc))
+	assert.Equal(t, time.Minute, offlineRecoveryInterval(sdkCommon.OfflinePipelineInfo{RecoveryInterval: "1m"}, lc))
+}
+
+func TestProcessMessageOffline(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.Pipeline.Offline = sdkCommon.OfflinePipelineInfo{FailureThreshold: 2, RecoveryInterval: "1h"}
+	defer func() {
+		config.Writable.Pipeline.Offline = sdkCommon.OfflinePipelineInfo{}
+	}()
+
+	payload, err := json.Marshal(testAddEventRequest)
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       payload,
+		ContentType:   common.ContentTypeJSON,
+	}
+
+	exportCalls := 0
+	export := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		exportCalls++
+		return false, errors.New("cloud unreachable")
+	}
+
+	offlineCalls := 0
+	offlineExport := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		offlineCalls++
+		return true, data
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{export})
+	runtime.SetOfflineTransforms([]interfaces.AppFunction{offlineExport})
+
+	for i := 0; i < 2; i++ {
+		result := runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope)
+		require.NotNil(t, result)
+	}
+
+	for i := 0; i < 3; i++ {
+		result := runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope)
+		require.Nil(t, result)
+	}
+
+	assert.Equal(t, 2, exportCalls)
+	assert.Equal(t, 3, offlineCalls)
+}
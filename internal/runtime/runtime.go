@@ -23,11 +23,18 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/stream"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
 
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
@@ -43,22 +50,54 @@ import (
 
 // GolangRuntime represents the golang runtime environment
 type GolangRuntime struct {
-	TargetType    interface{}
-	ServiceKey    string
+	TargetType interface{}
+	ServiceKey string
+	// MaxWorkers is the maximum number of messages dispatched via Dispatch that are processed concurrently.
+	// Zero means unbounded.
+	MaxWorkers int
+	// PreserveDeviceOrder indicates messages dispatched for the same topic are processed in the order received
+	PreserveDeviceOrder bool
+	// HighPriorityTopics and LowPriorityTopics are the topics of the messages dispatched via Dispatch which workers
+	// process before, or only after, all other queued messages
+	HighPriorityTopics []string
+	LowPriorityTopics  []string
+	// Interceptors are called before and after every function in the functions pipeline executes
+	Interceptors  []interfaces.PipelineInterceptor
 	transforms    []interfaces.AppFunction
 	isBusyCopying sync.Mutex
 	storeForward  storeForwardInfo
+	workers       workerPool
 	dic           *di.Container
+	slaTracker    *telemetry.SLATracker
+	deviceStats   *telemetry.DeviceStatsTracker
+	profiler      *telemetry.PipelineProfiler
+	previews      *telemetry.PayloadPreviewTracker
+	pipelineHubs  *stream.PipelineHubs
+	pipelineStats *telemetry.PipelineStatsTracker
+	executions    *telemetry.ExecutionSampler
+	sampler       sampler
+	offline       offlineMode
 }
 
 type MessageError struct {
 	Err       error
 	ErrorCode int
+	// Category is how the error is handled, i.e. whether the trigger should have the message redelivered
+	Category util.ErrorCategory
 }
 
 // Initialize sets the internal reference to the StoreClient for use when Store and Forward is enabled
 func (gr *GolangRuntime) Initialize(dic *di.Container) {
 	gr.dic = dic
+	if dic != nil {
+		gr.slaTracker = container.SLATrackerFrom(dic.Get)
+		gr.deviceStats = container.DeviceStatsTrackerFrom(dic.Get)
+		gr.profiler = container.PipelineProfilerFrom(dic.Get)
+		gr.previews = container.PayloadPreviewTrackerFrom(dic.Get)
+		gr.pipelineHubs = container.PipelineHubsFrom(dic.Get)
+		gr.pipelineStats = container.PipelineStatsTrackerFrom(dic.Get)
+		gr.executions = container.ExecutionSamplerFrom(dic.Get)
+	}
 	gr.storeForward.runtime = gr
 	gr.storeForward.dic = dic
 }
@@ -73,8 +112,66 @@ func (gr *GolangRuntime) SetTransforms(transforms []interfaces.AppFunction) {
 
 // ProcessMessage sends the contents of the message thru the functions pipeline
 func (gr *GolangRuntime) ProcessMessage(appContext *appfunction.Context, envelope types.MessageEnvelope) *MessageError {
+	return gr.processMessage(appContext, envelope, true)
+}
+
+// processMessage sends the contents of the message thru the functions pipeline, when it is sampled if applySampling
+// is set
+func (gr *GolangRuntime) processMessage(
+	appContext *appfunction.Context,
+	envelope types.MessageEnvelope,
+	applySampling bool) (messageError *MessageError) {
 	lc := appContext.LoggingClient()
 
+	// Messages not sampled aren't processed so aren't tracked against the SLA nor counted in the pipeline statistics
+	sampled := true
+	// completed is set when the message was processed by all the functions of the pipeline
+	completed := false
+
+	if gr.slaTracker != nil {
+		received := time.Now()
+		defer func() {
+			if !sampled {
+				return
+			}
+			config := container.ConfigurationFrom(gr.dic.Get)
+			gr.slaTracker.Record(config.Writable.Pipeline.SLA, time.Since(received), messageError == nil)
+		}()
+	}
+
+	if gr.pipelineStats != nil {
+		defer func() {
+			if !sampled {
+				return
+			}
+			outcome := pipelineOutcome(messageError, completed)
+			gr.pipelineStats.Record(appContext.PipelineId(), outcome)
+			if filteredBy, _, filtered := appContext.Filtered(); filtered && outcome == telemetry.PipelineFiltered {
+				gr.pipelineStats.RecordFilteredBy(appContext.PipelineId(), filteredBy)
+			}
+		}()
+	}
+
+	if gr.executions != nil {
+		received := time.Now()
+		defer func() {
+			if !sampled {
+				return
+			}
+			duration := time.Since(received)
+			config := container.ConfigurationFrom(gr.dic.Get)
+			gr.executions.Record(lc, config.Writable.ExecutionHistory, telemetry.ExecutionRecord{
+				PipelineId:    appContext.PipelineId(),
+				CorrelationId: envelope.CorrelationID,
+				Duration:      duration.String(),
+				DurationNanos: duration.Nanoseconds(),
+				Outcome:       pipelineOutcome(messageError, completed).String(),
+				InputSize:     len(envelope.Payload),
+				OutputSize:    len(appContext.ResponseData()),
+			})
+		}()
+	}
+
 	if len(gr.transforms) == 0 {
 		err := errors.New("No transforms configured. Please check log for errors loading pipeline")
 		logError(lc, err, envelope.CorrelationID)
@@ -100,6 +197,7 @@ func (gr *GolangRuntime) ProcessMessage(appContext *appfunction.Context, envelop
 
 	// Must make a copy of the type so that data isn't retained between calls for custom types
 	target := reflect.New(reflect.ValueOf(gr.TargetType).Elem().Type()).Interface()
+	deviceName := ""
 
 	switch target.(type) {
 	case *[]byte:
@@ -131,6 +229,12 @@ func (gr *GolangRuntime) ProcessMessage(appContext *appfunction.Context, envelop
 		appContext.AddValue(interfaces.PROFILENAME, event.ProfileName)
 		appContext.AddValue(interfaces.SOURCENAME, event.SourceName)
 
+		if gr.deviceStats != nil {
+			config := container.ConfigurationFrom(gr.dic.Get)
+			gr.deviceStats.Record(config.Writable.DeviceStatistics, event.DeviceName)
+		}
+
+		deviceName = event.DeviceName
 		target = event
 
 	default:
@@ -145,7 +249,15 @@ func (gr *GolangRuntime) ProcessMessage(appContext *appfunction.Context, envelop
 		}
 	}
 
+	if applySampling && !gr.isSampled(deviceName, lc) {
+		sampled = false
+		lc.Debugf("Message not sampled for processing by the functions pipeline. %s=%s",
+			common.CorrelationHeader, envelope.CorrelationID)
+		return nil
+	}
+
 	appContext.SetCorrelationID(envelope.CorrelationID)
+	appContext.SetPipelineInput(envelope.Payload, envelope.ContentType)
 
 	// All functions expect an object, not a pointer to an object, so must use reflection to
 	// dereference to pointer to the object
@@ -157,7 +269,34 @@ func (gr *GolangRuntime) ProcessMessage(appContext *appfunction.Context, envelop
 	copy(transforms, gr.transforms)
 	gr.isBusyCopying.Unlock()
 
-	return gr.ExecutePipeline(target, envelope.ContentType, appContext, transforms, 0, false)
+	if gr.profiler != nil {
+		defer gr.profiler.ExecutionDone()
+	}
+
+	started := time.Now()
+	enqueued := appContext.Received()
+	if enqueued.IsZero() {
+		enqueued = started
+	}
+	appContext.AddValue(interfaces.ENQUEUED, strconv.FormatInt(enqueued.UnixNano(), 10))
+	setPipelineStart(appContext, started)
+
+	if offlineTransforms := gr.offlineTransforms(lc); offlineTransforms != nil {
+		// Not stored for retry when the offline pipeline's exports fail, as its function positions don't match those
+		// of the functions pipeline the stored data is retried with
+		messageError, completed = gr.executePipeline(target, envelope.ContentType, appContext, offlineTransforms, 0, true)
+	} else {
+		messageError, completed = gr.executePipeline(target, envelope.ContentType, appContext, transforms, 0, false)
+		gr.recordOutcome(messageError, completed, lc)
+	}
+	if messageError == nil && gr.pipelineHubs != nil {
+		// Streamed to the pipeline's Server-Sent Events clients, if any
+		if responseData := appContext.ResponseData(); len(responseData) > 0 {
+			gr.pipelineHubs.Publish(appContext.PipelineId(), responseData)
+		}
+	}
+
+	return messageError
 }
 
 func (gr *GolangRuntime) ExecutePipeline(
@@ -168,41 +307,223 @@ func (gr *GolangRuntime) ExecutePipeline(
 	startPosition int,
 	isRetry bool) *MessageError {
 
+	messageError, _ := gr.executePipeline(target, contentType, appContext, transforms, startPosition, isRetry)
+	return messageError
+}
+
+// executePipeline executes the functions pipeline, returning whether the data was processed by all the functions
+// rather than the pipeline being stopped early, i.e. by a filter
+func (gr *GolangRuntime) executePipeline(
+	target interface{},
+	contentType string,
+	appContext *appfunction.Context,
+	transforms []interfaces.AppFunction,
+	startPosition int,
+	isRetry bool) (*MessageError, bool) {
+
 	var result interface{}
 	var continuePipeline bool
 
+	defer appContext.SetFunctionName("")
+
+	if timeout := gr.pipelineTimeout(appContext.LoggingClient()); timeout > 0 {
+		// Restored afterwards so the cancelled context isn't left on the function context once the pipeline has executed
+		parent := appContext.Context()
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer func() {
+			cancel()
+			appContext.SetContext(parent)
+		}()
+		appContext.SetContext(ctx)
+	}
+
 	for functionIndex, trxFunc := range transforms {
 		if functionIndex < startPosition {
 			continue
 		}
 
+		if err := appContext.Context().Err(); err != nil {
+			err = fmt.Errorf("pipeline function #%d not executed: %w", functionIndex, err)
+			appContext.LoggingClient().Error(err.Error(), common.CorrelationHeader, appContext.CorrelationID())
+			return &MessageError{Err: err, ErrorCode: http.StatusGatewayTimeout, Category: util.ErrorCategoryRetryable}, false
+		}
+
 		appContext.SetRetryData(nil)
+		appContext.ClearFiltered()
+		appContext.SetFunctionName(functionName(trxFunc))
 
+		data := result
 		if result == nil {
 			appContext.SetInputContentType(contentType)
-			continuePipeline, result = trxFunc(appContext, target)
-		} else {
-			continuePipeline, result = trxFunc(appContext, result)
+			data = target
+		}
+
+		if functionIndex == len(transforms)-1 {
+			gr.recordPayloadPreview(appContext, functionName(trxFunc), data)
 		}
 
+		continuePipeline, result = gr.executeFunction(appContext, trxFunc, functionIndex, data)
+
 		if continuePipeline != true {
+			if _, reason, filtered := appContext.Filtered(); filtered {
+				// Filtered data isn't a failure, even if the function also returned an error, so it is never stored
+				// for retry and isn't counted as exported when filtered by the last function
+				appContext.LoggingClient().Debug(
+					fmt.Sprintf("Pipeline function #%d filtered out the data: %s", functionIndex, reason),
+					common.CorrelationHeader, appContext.CorrelationID())
+				return nil, false
+			}
+
 			if result != nil {
 				if err, ok := result.(error); ok {
+					category := util.ErrorCategoryOf(err)
+					if category == util.ErrorCategoryIgnore {
+						appContext.LoggingClient().Debug(
+							fmt.Sprintf("Pipeline function #%d resulted in ignored error. Data dropped", functionIndex),
+							"error", err.Error(), common.CorrelationHeader, appContext.CorrelationID())
+						return nil, false
+					}
+
 					appContext.LoggingClient().Error(
-						fmt.Sprintf("Pipeline function #%d resulted in error", functionIndex),
+						fmt.Sprintf("Pipeline function #%d resulted in %s error", functionIndex, category),
 						"error", err.Error(), common.CorrelationHeader, appContext.CorrelationID())
-					if appContext.RetryData() != nil && !isRetry {
+					if appContext.RetryData() != nil && !isRetry && category != util.ErrorCategoryFatal {
 						gr.storeForward.storeForLaterRetry(appContext.RetryData(), appContext, functionIndex)
 					}
 
-					return &MessageError{Err: err, ErrorCode: http.StatusUnprocessableEntity}
+					errorCode := http.StatusUnprocessableEntity
+					var statusError util.HTTPStatusError
+					var pipelineError util.PipelineError
+					if errors.As(err, &statusError) {
+						errorCode = statusError.StatusCode
+					} else if errors.As(err, &pipelineError) && pipelineError.StatusCode != 0 {
+						errorCode = pipelineError.StatusCode
+					}
+
+					return &MessageError{Err: err, ErrorCode: errorCode, Category: category}, false
 				}
 			}
-			break
+
+			// The last function, i.e. the export, may stop the pipeline having processed the data
+			return nil, functionIndex == len(transforms)-1
 		}
 	}
 
-	return nil
+	return nil, true
+}
+
+// pipelineTimeout returns the maximum time the functions pipeline may take to process a message, which is zero when
+// there is no deadline
+func (gr *GolangRuntime) pipelineTimeout(lc logger.LoggingClient) time.Duration {
+	if gr.dic == nil {
+		return 0
+	}
+
+	config := container.ConfigurationFrom(gr.dic.Get)
+	if config == nil || len(config.Writable.Pipeline.Timeout) == 0 {
+		return 0
+	}
+
+	timeout, err := time.ParseDuration(config.Writable.Pipeline.Timeout)
+	if err != nil || timeout < 0 {
+		lc.Warnf("Invalid Pipeline Timeout '%s', messages processed without deadline", config.Writable.Pipeline.Timeout)
+		return 0
+	}
+
+	return timeout
+}
+
+// recordPayloadPreview keeps the payload passed to the last function of the pipeline, i.e. the export, when the
+// payload preview is enabled
+func (gr *GolangRuntime) recordPayloadPreview(appContext *appfunction.Context, function string, data interface{}) {
+	if gr.previews == nil {
+		return
+	}
+
+	config := container.ConfigurationFrom(gr.dic.Get)
+	if config == nil || !config.Writable.PayloadPreview.Enabled {
+		return
+	}
+
+	payload, err := util.CoerceType(data)
+	if err != nil {
+		appContext.LoggingClient().Debugf("Unable to record payload preview: %s", err.Error())
+		return
+	}
+
+	preview := telemetry.PayloadPreview{
+		PipelineId:    appContext.PipelineId(),
+		Function:      function,
+		CorrelationId: appContext.CorrelationID(),
+		Timestamp:     time.Now().UnixNano(),
+		ContentType:   appContext.ResponseContentType(),
+		Context:       appContext.GetAllValues(),
+	}
+
+	gr.previews.Record(config.Writable.PayloadPreview, preview, payload)
+}
+
+// setPipelineStart sets the context values for when the functions pipeline started processing the message and the
+// time the message was queued before then, i.e. waiting for a worker and in Store and Forward
+func setPipelineStart(appContext *appfunction.Context, started time.Time) {
+	appContext.AddValue(interfaces.PIPELINESTART, strconv.FormatInt(started.UnixNano(), 10))
+
+	value, found := appContext.GetValue(interfaces.ENQUEUED)
+	if !found {
+		return
+	}
+
+	enqueued, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return
+	}
+
+	queueTime := started.Sub(time.Unix(0, enqueued))
+	if queueTime < 0 {
+		// Stored data may have been created by an instance whose clock is ahead of this one
+		queueTime = 0
+	}
+	appContext.AddValue(interfaces.QUEUETIME, strconv.FormatInt(queueTime.Milliseconds(), 10))
+}
+
+// executeFunction executes the pipeline function, calling the interceptors before and after it executes
+func (gr *GolangRuntime) executeFunction(
+	appContext *appfunction.Context,
+	function interfaces.AppFunction,
+	position int,
+	data interface{}) (bool, interface{}) {
+
+	if len(gr.Interceptors) == 0 {
+		return function(appContext, data)
+	}
+
+	execution := interfaces.FunctionExecution{
+		FunctionName: functionName(function),
+		Position:     position,
+		Data:         data,
+	}
+
+	for _, interceptor := range gr.Interceptors {
+		if err := interceptor(appContext, execution); err != nil {
+			return false, fmt.Errorf("pipeline interceptor failed before function '%s': %w", execution.FunctionName, err)
+		}
+	}
+
+	started := time.Now()
+	execution.ContinuePipeline, execution.Result = function(appContext, data)
+	execution.Duration = time.Since(started)
+	execution.Completed = true
+	if !execution.ContinuePipeline {
+		_, execution.FilteredReason, execution.Filtered = appContext.Filtered()
+	}
+
+	for _, interceptor := range gr.Interceptors {
+		if err := interceptor(appContext, execution); err != nil {
+			return false, fmt.Errorf("pipeline interceptor failed after function '%s': %w", execution.FunctionName, err)
+		}
+	}
+
+	return execution.ContinuePipeline, execution.Result
 }
 
 func (gr *GolangRuntime) StartStoreAndForward(
@@ -257,6 +578,19 @@ func (gr *GolangRuntime) processEventPayload(envelope types.MessageEnvelope, lc
 	return nil, requestDtoErr
 }
 
+// pipelineOutcome returns the outcome of processing a message, given the error it resulted in and whether it was
+// processed by all the functions of the pipeline
+func pipelineOutcome(messageError *MessageError, completed bool) telemetry.PipelineOutcome {
+	if messageError != nil {
+		return telemetry.PipelineFailed
+	}
+	if !completed {
+		return telemetry.PipelineFiltered
+	}
+
+	return telemetry.PipelineExported
+}
+
 func (gr *GolangRuntime) unmarshalPayload(envelope types.MessageEnvelope, target interface{}) error {
 	var err error
 
@@ -267,6 +601,9 @@ func (gr *GolangRuntime) unmarshalPayload(envelope types.MessageEnvelope, target
 	case common.ContentTypeCBOR:
 		err = cbor.Unmarshal(envelope.Payload, target)
 
+	case util.ContentTypeMsgPack:
+		err = util.UnmarshalMsgPack(envelope.Payload, target)
+
 	default:
 		err = fmt.Errorf("unsupported content-type '%s' recieved", envelope.ContentType)
 	}
@@ -307,3 +644,11 @@ func (gr *GolangRuntime) debugLogEvent(lc logger.LoggingClient, event *dtos.Even
 func logError(lc logger.LoggingClient, err error, correlationID string) {
 	lc.Errorf("%s. %s=%s", err.Error(), common.CorrelationHeader, correlationID)
 }
+
+// functionName returns the package qualified name of the pipeline function, i.e. transforms.(*Conversion).TransformToJSON
+func functionName(function interfaces.AppFunction) string {
+	name := runtime.FuncForPC(reflect.ValueOf(function).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	// Method values have a '-fm' suffix
+	return strings.TrimSuffix(name, "-fm")
+}
@@ -16,18 +16,28 @@
 package runtime
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/stream"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
 
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/config"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
@@ -259,6 +269,71 @@ func TestProcessMessageTransformError(t *testing.T) {
 	assertReceivedTopicSet(t, context, envelope)
 }
 
+func TestProcessMessageTransformStatusError(t *testing.T) {
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, util.NewHTTPStatusError(http.StatusUnauthorized, errors.New("signature mismatch"))
+	}
+
+	runtime := GolangRuntime{TargetType: &[]byte{}}
+	runtime.Initialize(nil)
+	runtime.SetTransforms([]interfaces.AppFunction{transform})
+
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: []byte("data"), ContentType: common.ContentTypeJSON}
+	err := runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope)
+
+	require.NotNil(t, err, "Expected an error")
+	assert.EqualError(t, err.Err, "signature mismatch")
+	assert.Equal(t, http.StatusUnauthorized, err.ErrorCode)
+}
+
+func TestProcessMessageTransformPipelineError(t *testing.T) {
+	serviceKey := "AppService-PipelineError"
+
+	tests := []struct {
+		Name              string
+		Err               error
+		ExpectedError     bool
+		ExpectedCategory  util.ErrorCategory
+		ExpectedErrorCode int
+		ExpectedStored    int
+	}{
+		{"Plain error", errors.New("failed"), true, util.ErrorCategoryRetryable, http.StatusUnprocessableEntity, 1},
+		{"Retryable", util.NewRetryableError(errors.New("unavailable")).WithStatusCode(http.StatusServiceUnavailable),
+			true, util.ErrorCategoryRetryable, http.StatusServiceUnavailable, 1},
+		{"Fatal", util.NewFatalError(errors.New("invalid")).WithStatusCode(http.StatusBadRequest),
+			true, util.ErrorCategoryFatal, http.StatusBadRequest, 0},
+		{"Fatal default status", util.NewFatalError(errors.New("invalid")),
+			true, util.ErrorCategoryFatal, http.StatusUnprocessableEntity, 0},
+		{"Ignore", util.NewIgnoreError(errors.New("not applicable")), false, "", 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+				appContext.SetRetryData([]byte("retry"))
+				return false, test.Err
+			}
+
+			runtime := GolangRuntime{ServiceKey: serviceKey, TargetType: &[]byte{}}
+			runtime.Initialize(updateDicWithMockStoreClient())
+			runtime.SetTransforms([]interfaces.AppFunction{transform})
+
+			envelope := types.MessageEnvelope{CorrelationID: "123", Payload: []byte("data"), ContentType: common.ContentTypeJSON}
+			err := runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope)
+
+			assert.Len(t, mockRetrieveObjects(serviceKey), test.ExpectedStored)
+			if !test.ExpectedError {
+				assert.Nil(t, err)
+				return
+			}
+
+			require.NotNil(t, err, "Expected an error")
+			assert.Equal(t, test.ExpectedCategory, err.Category)
+			assert.Equal(t, test.ExpectedErrorCode, err.ErrorCode)
+		})
+	}
+}
+
 func assertEventMetadataSet(t *testing.T, context *appfunction.Context, envelope types.MessageEnvelope) {
 	assertReceivedTopicSet(t, context, envelope)
 
@@ -387,6 +462,12 @@ func TestProcessMessageTargetType(t *testing.T) {
 	eventCborPayload, err := cbor.Marshal(testV2Event)
 	require.NoError(t, err)
 
+	msgPackPayload, err := util.MarshalMsgPack(testAddEventRequest)
+	require.NoError(t, err)
+
+	eventMsgPackPayload, err := util.MarshalMsgPack(testV2Event)
+	require.NoError(t, err)
+
 	expected := CustomType{
 		ID: "Id1",
 	}
@@ -405,6 +486,8 @@ func TestProcessMessageTargetType(t *testing.T) {
 		{"CBOR default Target Type", nil, cborPayload, common.ContentTypeCBOR, eventJsonPayload, false},
 		{"JSON Event Event DTO", &dtos.Event{}, eventJsonPayload, common.ContentTypeJSON, eventJsonPayload, false},
 		{"CBOR Event Event DTO", &dtos.Event{}, eventCborPayload, common.ContentTypeCBOR, eventJsonPayload, false}, // Not re-encoding as CBOR
+		{"MsgPack default Target Type", nil, msgPackPayload, util.ContentTypeMsgPack, eventJsonPayload, false},
+		{"MsgPack Event Event DTO", &dtos.Event{}, eventMsgPackPayload, util.ContentTypeMsgPack, eventJsonPayload, false},
 		{"Custom Type Json", &CustomType{}, customJsonPayload, common.ContentTypeJSON, customJsonPayload, false},
 		{"Byte Slice", &[]byte{}, byteData, "application/binary", byteData, false},
 		{"Target Type Not a pointer", dtos.Event{}, nil, "", nil, true},
@@ -522,3 +605,548 @@ func TestGolangRuntime_processEventPayload(t *testing.T) {
 		})
 	}
 }
+
+func TestFunctionName(t *testing.T) {
+	closure := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return true, data
+	}
+
+	assert.Equal(t, "transforms.Conversion.TransformToJSON", functionName(transforms.NewConversion().TransformToJSON))
+	assert.Equal(t, "runtime.TestFunctionName.func1", functionName(closure))
+}
+
+func TestExecutePipelineInterceptors(t *testing.T) {
+	appendTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return true, string(data.([]byte)) + "!"
+	}
+	stopTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, nil
+	}
+
+	var executions []interfaces.FunctionExecution
+	recordInterceptor := func(appContext interfaces.AppFunctionContext, execution interfaces.FunctionExecution) error {
+		executions = append(executions, execution)
+		return nil
+	}
+
+	runtime := GolangRuntime{ServiceKey: serviceKey, Interceptors: []interfaces.PipelineInterceptor{recordInterceptor}}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{appendTransform, stopTransform})
+
+	result := runtime.ExecutePipeline([]byte("data"), "", appfunction.NewContext("testing", dic, ""), runtime.transforms, 0, false)
+	require.Nil(t, result)
+	require.Len(t, executions, 4)
+
+	assert.Equal(t, 0, executions[0].Position)
+	assert.False(t, executions[0].Completed)
+	assert.Equal(t, []byte("data"), executions[0].Data)
+	assert.Contains(t, executions[0].FunctionName, "TestExecutePipelineInterceptors.func1")
+
+	assert.True(t, executions[1].Completed)
+	assert.True(t, executions[1].ContinuePipeline)
+	assert.Equal(t, "data!", executions[1].Result)
+	assert.Greater(t, int64(executions[1].Duration), int64(0))
+
+	assert.Equal(t, 1, executions[2].Position)
+	assert.Equal(t, "data!", executions[2].Data)
+	assert.True(t, executions[3].Completed)
+	assert.False(t, executions[3].ContinuePipeline)
+}
+
+func TestExecutePipelineInterceptorError(t *testing.T) {
+	transformCalled := false
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		transformCalled = true
+		return true, data
+	}
+
+	failBefore := func(appContext interfaces.AppFunctionContext, execution interfaces.FunctionExecution) error {
+		if !execution.Completed {
+			return errors.New("injected fault")
+		}
+		return nil
+	}
+
+	runtime := GolangRuntime{ServiceKey: serviceKey, Interceptors: []interfaces.PipelineInterceptor{failBefore}}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{transform})
+
+	result := runtime.ExecutePipeline([]byte("data"), "", appfunction.NewContext("testing", dic, ""), runtime.transforms, 0, false)
+	require.NotNil(t, result)
+	require.Error(t, result.Err)
+	assert.Contains(t, result.Err.Error(), "injected fault")
+	assert.False(t, transformCalled)
+}
+
+func TestProcessMessageTimeout(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.Pipeline.Timeout = "10ms"
+	defer func() {
+		config.Writable.Pipeline.Timeout = ""
+	}()
+
+	transformCalled := false
+	stuckTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		_, hasDeadline := appContext.Context().Deadline()
+		require.True(t, hasDeadline)
+		<-appContext.Context().Done()
+		return true, data
+	}
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		transformCalled = true
+		return false, nil
+	}
+
+	runtime := GolangRuntime{TargetType: &[]byte{}}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{stuckTransform, transform})
+
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: []byte("data"), ContentType: common.ContentTypeJSON}
+	appContext := appfunction.NewContext("testId", dic, "")
+	result := runtime.ProcessMessage(appContext, envelope)
+
+	require.NotNil(t, result)
+	assert.True(t, errors.Is(result.Err, context.DeadlineExceeded))
+	assert.Equal(t, http.StatusGatewayTimeout, result.ErrorCode)
+	assert.False(t, transformCalled)
+
+	// The expired context isn't left on the function context
+	assert.NoError(t, appContext.Context().Err())
+	_, hasDeadline := appContext.Context().Deadline()
+	assert.False(t, hasDeadline)
+}
+
+func TestProcessMessageCancelled(t *testing.T) {
+	transformCalled := false
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		transformCalled = true
+		return false, nil
+	}
+
+	runtime := GolangRuntime{TargetType: &[]byte{}}
+	runtime.Initialize(nil)
+	runtime.SetTransforms([]interfaces.AppFunction{transform})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	appContext := appfunction.NewContext("testId", dic, "")
+	appContext.SetContext(ctx)
+
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: []byte("data"), ContentType: common.ContentTypeJSON}
+	result := runtime.ProcessMessage(appContext, envelope)
+
+	require.NotNil(t, result)
+	assert.True(t, errors.Is(result.Err, context.Canceled))
+	assert.False(t, transformCalled)
+}
+
+func TestProcessMessageRecordsSLA(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.Pipeline.SLA = sdkCommon.SLAInfo{Window: "1h"}
+	defer func() {
+		config.Writable.Pipeline.SLA = sdkCommon.SLAInfo{}
+	}()
+
+	tracker := telemetry.NewSLATracker()
+	dic.Update(di.ServiceConstructorMap{
+		container.SLATrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.SLATrackerName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, nil
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{transform})
+
+	payload, err := json.Marshal(testAddEventRequest)
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: payload, ContentType: common.ContentTypeJSON}
+
+	require.Nil(t, runtime.ProcessMessage(appfunction.NewContext("123", dic, ""), envelope))
+	require.NotNil(t, runtime.ProcessMessage(appfunction.NewContext("123", dic, ""), types.MessageEnvelope{Payload: []byte("bad"), ContentType: common.ContentTypeJSON}))
+
+	report := tracker.Report(config.Writable.Pipeline.SLA)
+	assert.Equal(t, uint64(2), report.Total)
+	assert.Equal(t, uint64(1), report.Failed)
+}
+
+func TestProcessMessageRecordsDeviceStats(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.DeviceStatistics = sdkCommon.DeviceStatisticsInfo{Enabled: true}
+	defer func() {
+		config.Writable.DeviceStatistics = sdkCommon.DeviceStatisticsInfo{}
+	}()
+
+	tracker := telemetry.NewDeviceStatsTracker()
+	dic.Update(di.ServiceConstructorMap{
+		container.DeviceStatsTrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.DeviceStatsTrackerName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, nil
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{transform})
+
+	payload, err := json.Marshal(testAddEventRequest)
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: payload, ContentType: common.ContentTypeJSON}
+
+	require.Nil(t, runtime.ProcessMessage(appfunction.NewContext("123", dic, ""), envelope))
+	require.Nil(t, runtime.ProcessMessage(appfunction.NewContext("123", dic, ""), envelope))
+	require.NotNil(t, runtime.ProcessMessage(appfunction.NewContext("123", dic, ""), types.MessageEnvelope{Payload: []byte("bad"), ContentType: common.ContentTypeJSON}))
+
+	report := tracker.Report(config.Writable.DeviceStatistics)
+	require.Len(t, report.Devices, 1)
+	assert.Equal(t, uint64(2), report.Devices[testAddEventRequest.Event.DeviceName].MessageCount)
+}
+
+func TestExecutePipelineRecordsPayloadPreview(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.PayloadPreview = sdkCommon.PayloadPreviewInfo{Enabled: true}
+	defer func() {
+		config.Writable.PayloadPreview = sdkCommon.PayloadPreviewInfo{}
+	}()
+
+	tracker := telemetry.NewPayloadPreviewTracker()
+	dic.Update(di.ServiceConstructorMap{
+		container.PayloadPreviewTrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.PayloadPreviewTrackerName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return true, `{"formatted":true}`
+	}
+	export := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, nil
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.AddValue("password", "secret")
+	require.Nil(t, runtime.ExecutePipeline([]byte("raw"), "", appContext, []interfaces.AppFunction{transform, export}, 0, false))
+
+	preview, found := tracker.Preview(interfaces.DefaultPipelineId)
+	require.True(t, found)
+	assert.Equal(t, `{"formatted":true}`, preview.Payload)
+	assert.Equal(t, "123", preview.CorrelationId)
+	assert.Contains(t, preview.Function, "TestExecutePipelineRecordsPayloadPreview")
+	assert.Equal(t, "<redacted>", preview.Context["password"])
+
+	config.Writable.PayloadPreview.Enabled = false
+	require.Nil(t, runtime.ExecutePipeline([]byte("raw"), "", appfunction.NewContext("456", dic, ""), []interfaces.AppFunction{transform, export}, 0, false))
+	preview, _ = tracker.Preview(interfaces.DefaultPipelineId)
+	assert.Equal(t, "123", preview.CorrelationId)
+}
+
+func TestProcessMessageRecordsLatency(t *testing.T) {
+	var values map[string]string
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		values = map[string]string{}
+		for _, key := range []string{interfaces.ENQUEUED, interfaces.PIPELINESTART, interfaces.QUEUETIME} {
+			values[key], _ = appContext.GetValue(key)
+		}
+		return false, nil
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{transform})
+
+	// The message is enqueued when the trigger received it
+	payload, err := json.Marshal(createAddEventRequest())
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: payload, ContentType: common.ContentTypeJSON}
+
+	received := time.Now().Add(-2 * time.Second)
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.SetReceived(received)
+	require.Nil(t, runtime.ProcessMessage(appContext, envelope))
+	assert.Equal(t, strconv.FormatInt(received.UnixNano(), 10), values[interfaces.ENQUEUED])
+	assert.NotEmpty(t, values[interfaces.PIPELINESTART])
+	queueTime, err := strconv.ParseInt(values[interfaces.QUEUETIME], 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, queueTime, int64(2000))
+
+	// The Event's Origin is when it was created by the device service, so isn't used
+	request := createAddEventRequest()
+	request.Event.Origin = time.Now().Add(-2 * time.Second).UnixNano()
+	envelope.Payload, err = json.Marshal(request)
+	require.NoError(t, err)
+	require.Nil(t, runtime.ProcessMessage(appfunction.NewContext("123", dic, ""), envelope))
+	assert.Equal(t, values[interfaces.PIPELINESTART], values[interfaces.ENQUEUED])
+	assert.Equal(t, "0", values[interfaces.QUEUETIME])
+
+	// Otherwise it's enqueued when processing starts
+	runtime.TargetType = &[]byte{}
+	require.Nil(t, runtime.ProcessMessage(appfunction.NewContext("123", dic, ""), envelope))
+	assert.Equal(t, values[interfaces.PIPELINESTART], values[interfaces.ENQUEUED])
+	assert.Equal(t, "0", values[interfaces.QUEUETIME])
+}
+
+func TestSetPipelineStart(t *testing.T) {
+	started := time.Now()
+
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.AddValue(interfaces.ENQUEUED, strconv.FormatInt(started.Add(-1500*time.Millisecond).UnixNano(), 10))
+	setPipelineStart(appContext, started)
+
+	value, _ := appContext.GetValue(interfaces.PIPELINESTART)
+	assert.Equal(t, strconv.FormatInt(started.UnixNano(), 10), value)
+	value, _ = appContext.GetValue(interfaces.QUEUETIME)
+	assert.Equal(t, "1500", value)
+
+	// Enqueued after the pipeline started
+	appContext.AddValue(interfaces.ENQUEUED, strconv.FormatInt(started.Add(time.Second).UnixNano(), 10))
+	setPipelineStart(appContext, started)
+	value, _ = appContext.GetValue(interfaces.QUEUETIME)
+	assert.Equal(t, "0", value)
+
+	appContext = appfunction.NewContext("123", dic, "")
+	setPipelineStart(appContext, started)
+	_, found := appContext.GetValue(interfaces.QUEUETIME)
+	assert.False(t, found)
+}
+
+func TestProcessMessagePublishesResponseData(t *testing.T) {
+	hubs := stream.NewPipelineHubs(1, 1)
+	dic.Update(di.ServiceConstructorMap{
+		container.PipelineHubsName: func(get di.Get) interface{} {
+			return hubs
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.PipelineHubsName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	hub := hubs.Hub(interfaces.DefaultPipelineId)
+	client, err := hub.Register()
+	require.NoError(t, err)
+	defer hub.Unregister(client)
+
+	payload, err := json.Marshal(testAddEventRequest)
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       payload,
+		ContentType:   common.ContentTypeJSON,
+	}
+
+	respond := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		appContext.SetResponseData([]byte("response"))
+		return false, nil
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{respond})
+	require.Nil(t, runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope))
+
+	select {
+	case data := <-client.Messages():
+		assert.Equal(t, []byte("response"), data)
+	default:
+		require.Fail(t, "response data not published")
+	}
+}
+
+func TestProcessMessageRecordsPipelineStats(t *testing.T) {
+	tracker := telemetry.NewPipelineStatsTracker()
+	dic.Update(di.ServiceConstructorMap{
+		container.PipelineStatsTrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.PipelineStatsTrackerName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	payload, err := json.Marshal(testAddEventRequest)
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       payload,
+		ContentType:   common.ContentTypeJSON,
+	}
+
+	pass := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return true, data
+	}
+	filter := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, nil
+	}
+	fail := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, errors.New("export failed")
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+
+	pipelines := [][]interfaces.AppFunction{
+		{pass, pass},
+		// The export stopping the pipeline having processed the data
+		{pass, filter},
+		{filter, pass},
+		{pass, fail},
+	}
+	for _, transforms := range pipelines {
+		runtime.SetTransforms(transforms)
+		_ = runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope)
+	}
+
+	expected := telemetry.PipelineStats{Processed: 4, Exported: 2, Filtered: 1, Failed: 1}
+	assert.Equal(t, expected, tracker.Report()[interfaces.DefaultPipelineId])
+}
+
+func TestProcessMessageFiltered(t *testing.T) {
+	tracker := telemetry.NewPipelineStatsTracker()
+	dic.Update(di.ServiceConstructorMap{
+		container.PipelineStatsTrackerName: func(get di.Get) interface{} {
+			return tracker
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.PipelineStatsTrackerName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	payload, err := json.Marshal(testAddEventRequest)
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       payload,
+		ContentType:   common.ContentTypeJSON,
+	}
+
+	pass := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return true, data
+	}
+	// Marked as filtered so neither the error nor the retry data are acted on
+	filter := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		appContext.SetRetryData([]byte("retry"))
+		appContext.SetFiltered("not of interest")
+		return false, errors.New("not an error")
+	}
+	// Marked as filtered but continuing isn't filtered out
+	passMarked := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		appContext.SetFiltered("ignored")
+		return true, data
+	}
+	fail := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, errors.New("export failed")
+	}
+
+	var executions []interfaces.FunctionExecution
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.Interceptors = []interfaces.PipelineInterceptor{
+		func(appContext interfaces.AppFunctionContext, execution interfaces.FunctionExecution) error {
+			if execution.Completed {
+				executions = append(executions, execution)
+			}
+			return nil
+		},
+	}
+
+	// Filtered by the last function isn't counted as exported
+	runtime.SetTransforms([]interfaces.AppFunction{pass, filter})
+	messageError := runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope)
+	require.Nil(t, messageError)
+	require.Len(t, executions, 2)
+	assert.True(t, executions[1].Filtered)
+	assert.Equal(t, "not of interest", executions[1].FilteredReason)
+
+	runtime.SetTransforms([]interfaces.AppFunction{passMarked, fail})
+	messageError = runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope)
+	require.NotNil(t, messageError)
+	assert.Equal(t, "export failed", messageError.Err.Error())
+
+	stats := tracker.Report()[interfaces.DefaultPipelineId]
+	assert.Equal(t, uint64(2), stats.Processed)
+	assert.Equal(t, uint64(1), stats.Filtered)
+	assert.Equal(t, uint64(1), stats.Failed)
+	assert.Equal(t, map[string]uint64{functionName(filter): 1}, stats.FilteredBy)
+}
+
+func TestProcessMessagePublishesExecutionRecords(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.ExecutionHistory = sdkCommon.ExecutionHistoryInfo{SampleEvery: 2, PublishTopic: "telemetry"}
+	defer func() {
+		config.Writable.ExecutionHistory = sdkCommon.ExecutionHistoryInfo{}
+	}()
+
+	var records []telemetry.ExecutionRecord
+	sampler := telemetry.NewExecutionSampler("app-test")
+	sampler.SetPublisher(func(envelope types.MessageEnvelope, topic string) error {
+		assert.Equal(t, "telemetry", topic)
+		var record telemetry.ExecutionRecord
+		require.NoError(t, json.Unmarshal(envelope.Payload, &record))
+		records = append(records, record)
+		return nil
+	})
+	dic.Update(di.ServiceConstructorMap{
+		container.ExecutionSamplerName: func(get di.Get) interface{} {
+			return sampler
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.ExecutionSamplerName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	payload, err := json.Marshal(testAddEventRequest)
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       payload,
+		ContentType:   common.ContentTypeJSON,
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{transforms.NewResponseData().SetResponseData})
+
+	for i := 0; i < 4; i++ {
+		require.Nil(t, runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope))
+	}
+
+	require.Len(t, records, 2)
+	assert.Equal(t, interfaces.DefaultPipelineId, records[0].PipelineId)
+	assert.Equal(t, envelope.CorrelationID, records[0].CorrelationId)
+	assert.Equal(t, "exported", records[0].Outcome)
+	assert.Equal(t, len(payload), records[0].InputSize)
+	assert.Greater(t, records[0].OutputSize, 0)
+	assert.Greater(t, records[0].DurationNanos, int64(0))
+}
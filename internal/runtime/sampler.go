@@ -0,0 +1,114 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+)
+
+const (
+	samplingModeCount      = "count"
+	samplingModePercentage = "percentage"
+)
+
+// sampler decides which of the messages received are processed by the functions pipeline when sampling is configured
+type sampler struct {
+	lock        sync.Mutex
+	counts      map[string]uint64
+	random      *rand.Rand
+	lastWarning string
+}
+
+// isSampled returns true if the message, from the device when known, should be processed by the functions pipeline
+// according to the configured sampling
+func (gr *GolangRuntime) isSampled(deviceName string, lc logger.LoggingClient) bool {
+	if gr.dic == nil {
+		return true
+	}
+
+	config := container.ConfigurationFrom(gr.dic.Get)
+	return gr.sampler.sample(config.Writable.Pipeline.Sampling, deviceName, lc)
+}
+
+// sample returns true if the message, from the device when known, should be processed by the functions pipeline.
+// All messages are processed when sampling is disabled or its configuration is invalid.
+func (s *sampler) sample(config common.SamplingInfo, deviceName string, lc logger.LoggingClient) bool {
+	mode := strings.ToLower(config.Mode)
+	if len(mode) == 0 {
+		return true
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch mode {
+	case samplingModeCount:
+		if config.Count < 1 {
+			s.warnOnce(lc, "Pipeline Sampling Count must be at least 1. Sampling disabled")
+			return true
+		}
+
+		key := ""
+		if config.PerDevice {
+			key = deviceName
+		}
+
+		if s.counts == nil {
+			s.counts = make(map[string]uint64)
+		}
+
+		// The first message, and every Count messages after it, are processed
+		count := s.counts[key]
+		s.counts[key] = count + 1
+		return count%uint64(config.Count) == 0
+
+	case samplingModePercentage:
+		if config.Percentage < 0 || config.Percentage > 100 {
+			s.warnOnce(lc, "Pipeline Sampling Percentage must be from 0 to 100. Sampling disabled")
+			return true
+		}
+
+		if s.random == nil {
+			s.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+
+		return s.random.Float64()*100 < config.Percentage
+
+	default:
+		s.warnOnce(lc, fmt.Sprintf("Pipeline Sampling Mode '%s' is invalid, must be 'Count' or 'Percentage'. Sampling disabled", config.Mode))
+		return true
+	}
+}
+
+// warnOnce logs the warning if it differs from the last one logged, so an invalid configuration is only logged once
+func (s *sampler) warnOnce(lc logger.LoggingClient, warning string) {
+	if warning == s.lastWarning {
+		return
+	}
+
+	s.lastWarning = warning
+	lc.Warn(warning)
+}
@@ -0,0 +1,154 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplerCount(t *testing.T) {
+	lc := logger.NewMockClient()
+	target := sampler{}
+	config := sdkCommon.SamplingInfo{Mode: "Count", Count: 3}
+
+	var actual []bool
+	for i := 0; i < 7; i++ {
+		actual = append(actual, target.sample(config, "device1", lc))
+	}
+
+	assert.Equal(t, []bool{true, false, false, true, false, false, true}, actual)
+}
+
+func TestSamplerCountPerDevice(t *testing.T) {
+	lc := logger.NewMockClient()
+	target := sampler{}
+	config := sdkCommon.SamplingInfo{Mode: "count", Count: 2, PerDevice: true}
+
+	var actual []bool
+	for _, device := range []string{"device1", "device2", "device1", "device2", "device1", "device3"} {
+		actual = append(actual, target.sample(config, device, lc))
+	}
+
+	assert.Equal(t, []bool{true, true, false, false, true, true}, actual)
+}
+
+func TestSamplerPercentage(t *testing.T) {
+	lc := logger.NewMockClient()
+
+	tests := []struct {
+		Name       string
+		Percentage float64
+		Min        int
+		Max        int
+	}{
+		{"None", 0, 0, 0},
+		{"All", 100, 1000, 1000},
+		{"Half", 50, 400, 600},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := sampler{}
+			config := sdkCommon.SamplingInfo{Mode: "Percentage", Percentage: test.Percentage}
+
+			processed := 0
+			for i := 0; i < 1000; i++ {
+				if target.sample(config, "", lc) {
+					processed++
+				}
+			}
+
+			assert.GreaterOrEqual(t, processed, test.Min)
+			assert.LessOrEqual(t, processed, test.Max)
+		})
+	}
+}
+
+func TestSamplerDisabled(t *testing.T) {
+	lc := logger.NewMockClient()
+
+	tests := []struct {
+		Name   string
+		Config sdkCommon.SamplingInfo
+	}{
+		{"No mode", sdkCommon.SamplingInfo{Count: 10, Percentage: 10}},
+		{"Invalid mode", sdkCommon.SamplingInfo{Mode: "bogus", Count: 10}},
+		{"Invalid count", sdkCommon.SamplingInfo{Mode: "Count", Count: 0}},
+		{"Invalid percentage", sdkCommon.SamplingInfo{Mode: "Percentage", Percentage: 101}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := sampler{}
+			for i := 0; i < 5; i++ {
+				assert.True(t, target.sample(test.Config, "device1", lc))
+			}
+		})
+	}
+}
+
+func TestProcessMessageSampling(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.Pipeline.Sampling = sdkCommon.SamplingInfo{Mode: "Count", Count: 2}
+	defer func() {
+		config.Writable.Pipeline.Sampling = sdkCommon.SamplingInfo{}
+	}()
+
+	payload, err := json.Marshal(testAddEventRequest)
+	require.NoError(t, err)
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       payload,
+		ContentType:   common.ContentTypeJSON,
+	}
+
+	calls := 0
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		calls++
+		return false, nil
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{transform})
+
+	for i := 0; i < 4; i++ {
+		result := runtime.ProcessMessage(appfunction.NewContext("testId", dic, ""), envelope)
+		require.Nil(t, result, "messages not sampled should not result in error")
+	}
+	assert.Equal(t, 2, calls)
+
+	// Replayed messages are not sampled
+	for i := 0; i < 2; i++ {
+		result := runtime.processMessage(appfunction.NewContext("testId", dic, ""), envelope, false)
+		require.Nil(t, result)
+	}
+	assert.Equal(t, 4, calls)
+}
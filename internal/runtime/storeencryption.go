@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+// storeEncryptionEnabled returns true if the configuration specifies a key for encrypting stored data
+func storeEncryptionEnabled(config common.StoreAndForwardInfo) bool {
+	return len(config.EncryptionSecretPath) > 0 && len(config.EncryptionSecretName) > 0
+}
+
+// newStoreCipher creates the AES-GCM cipher for encrypting stored data using the key from the Secret Store.
+// The secret value is hashed to derive a 256 bit key.
+func newStoreCipher(appContext interfaces.AppFunctionContext, config common.StoreAndForwardInfo) (cipher.AEAD, error) {
+	secretData, err := appContext.GetSecret(config.EncryptionSecretPath, config.EncryptionSecretName)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to retrieve Store and Forward encryption key at secret path=%s and name=%s: %s",
+			config.EncryptionSecretPath,
+			config.EncryptionSecretName,
+			err.Error())
+	}
+
+	secret, ok := secretData[config.EncryptionSecretName]
+	if !ok || len(secret) == 0 {
+		return nil, fmt.Errorf("unable to find Store and Forward encryption key in secret data for name=%s", config.EncryptionSecretName)
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encryptPayload encrypts the payload with a random nonce which is prepended to the result
+func encryptPayload(aead cipher.AEAD, payload []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, payload, nil), nil
+}
+
+// decryptPayload reverses encryptPayload, failing if the data has been modified or was encrypted with another key
+func decryptPayload(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted payload is too short")
+	}
+
+	nonce := data[:aead.NonceSize()]
+	return aead.Open(nil, nonce, data[aead.NonceSize():], nil)
+}
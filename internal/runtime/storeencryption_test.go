@@ -0,0 +1,196 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	bootstrapMocks "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStoreEncryption = common.StoreAndForwardInfo{
+	EncryptionSecretPath: "storeforward",
+	EncryptionSecretName: "key",
+}
+
+func updateDicWithMockSecretProvider(secret map[string]string, err error) {
+	mockSP := &bootstrapMocks.SecretProvider{}
+	mockSP.On("GetSecret", testStoreEncryption.EncryptionSecretPath, testStoreEncryption.EncryptionSecretName).Return(secret, err)
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+}
+
+func TestEncryptDecryptPayload(t *testing.T) {
+	updateDicWithMockSecretProvider(map[string]string{"key": "my secret"}, nil)
+	appContext := appfunction.NewContext("123", dic, "")
+
+	aead, err := newStoreCipher(appContext, testStoreEncryption)
+	require.NoError(t, err)
+
+	payload := []byte("sensitive sensor data")
+
+	encrypted, err := encryptPayload(aead, payload)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), string(payload))
+
+	again, err := encryptPayload(aead, payload)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "expected a unique nonce per encryption")
+
+	decrypted, err := decryptPayload(aead, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, payload, decrypted)
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = decryptPayload(aead, tampered)
+	assert.Error(t, err)
+
+	_, err = decryptPayload(aead, encrypted[:4])
+	assert.Error(t, err)
+
+	updateDicWithMockSecretProvider(map[string]string{"key": "other secret"}, nil)
+	otherAead, err := newStoreCipher(appContext, testStoreEncryption)
+	require.NoError(t, err)
+	_, err = decryptPayload(otherAead, encrypted)
+	assert.Error(t, err)
+}
+
+func TestNewStoreCipherErrors(t *testing.T) {
+	appContext := appfunction.NewContext("123", dic, "")
+
+	updateDicWithMockSecretProvider(nil, errors.New("not found"))
+	_, err := newStoreCipher(appContext, testStoreEncryption)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to retrieve")
+
+	updateDicWithMockSecretProvider(map[string]string{"key": ""}, nil)
+	_, err = newStoreCipher(appContext, testStoreEncryption)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to find")
+}
+
+func TestStoreAndForwardEncryption(t *testing.T) {
+	serviceKey := "AppService-UnitTest"
+	payload := []byte("My Payload")
+
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.StoreAndForward.EncryptionSecretPath = testStoreEncryption.EncryptionSecretPath
+	config.Writable.StoreAndForward.EncryptionSecretName = testStoreEncryption.EncryptionSecretName
+	defer func() {
+		config.Writable.StoreAndForward.EncryptionSecretPath = ""
+		config.Writable.StoreAndForward.EncryptionSecretName = ""
+	}()
+
+	var retried []byte
+	retryTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		retried = data.([]byte)
+		return false, nil
+	}
+
+	runtime := GolangRuntime{ServiceKey: serviceKey}
+	runtime.Initialize(updateDicWithMockStoreClient())
+	runtime.SetTransforms([]interfaces.AppFunction{retryTransform})
+
+	// Key unavailable so nothing is stored rather than storing the data unencrypted
+	updateDicWithMockSecretProvider(nil, errors.New("not found"))
+	runtime.storeForward.storeForLaterRetry(payload, appfunction.NewContext("123", dic, ""), 0)
+	require.Len(t, mockRetrieveObjects(serviceKey), 0)
+
+	updateDicWithMockSecretProvider(map[string]string{"key": "my secret"}, nil)
+	runtime.storeForward.storeForLaterRetry(payload, appfunction.NewContext("123", dic, ""), 0)
+
+	objects := mockRetrieveObjects(serviceKey)
+	require.Len(t, objects, 1)
+	assert.True(t, objects[0].Encrypted)
+	assert.NotContains(t, string(objects[0].Payload), string(payload))
+
+	// Failing to decrypt counts as a failed retry
+	updateDicWithMockSecretProvider(nil, errors.New("not found"))
+	runtime.storeForward.retryStoredData(serviceKey)
+	objects = mockRetrieveObjects(serviceKey)
+	require.Len(t, objects, 1)
+	assert.Equal(t, 1, objects[0].RetryCount)
+	assert.Nil(t, retried)
+
+	updateDicWithMockSecretProvider(map[string]string{"key": "my secret"}, nil)
+	runtime.storeForward.retryStoredData(serviceKey)
+	assert.Equal(t, payload, retried)
+	assert.Len(t, mockRetrieveObjects(serviceKey), 0)
+}
+
+func TestStoreForLaterRetryEncryptsWithAESGCM(t *testing.T) {
+	serviceKey := "AppService-UnitTest"
+	payload := []byte("My Payload")
+	input := []byte("My Pipeline Input")
+
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.StoreAndForward.EncryptionSecretPath = testStoreEncryption.EncryptionSecretPath
+	config.Writable.StoreAndForward.EncryptionSecretName = testStoreEncryption.EncryptionSecretName
+	defer func() {
+		config.Writable.StoreAndForward.EncryptionSecretPath = ""
+		config.Writable.StoreAndForward.EncryptionSecretName = ""
+	}()
+
+	export := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, nil
+	}
+
+	runtime := GolangRuntime{ServiceKey: serviceKey}
+	runtime.Initialize(updateDicWithMockStoreClient())
+	runtime.SetTransforms([]interfaces.AppFunction{export})
+
+	updateDicWithMockSecretProvider(map[string]string{"key": "my secret"}, nil)
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.SetPipelineInput(input, "text/plain")
+	runtime.storeForward.storeForLaterRetry(payload, appContext, 0)
+
+	objects := mockRetrieveObjects(serviceKey)
+	require.Len(t, objects, 1)
+	require.True(t, objects[0].Encrypted)
+
+	// The stored data is AES-256-GCM sealed, with the nonce prepended, using the SHA-256 of the secret as the key
+	key := sha256.Sum256([]byte("my secret"))
+	block, err := aes.NewCipher(key[:])
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	for expected, stored := range map[string][]byte{string(payload): objects[0].Payload, string(input): objects[0].Input} {
+		require.Greater(t, len(stored), aead.NonceSize())
+		decrypted, err := aead.Open(nil, stored[:aead.NonceSize()], stored[aead.NonceSize():], nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(decrypted))
+	}
+}
@@ -19,16 +19,21 @@ package runtime
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"reflect"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
@@ -40,6 +45,11 @@ const (
 )
 
 type storeForwardInfo struct {
+	// evictedCount is accessed atomically so must stay first for 64 bit alignment
+	evictedCount uint64
+	// lock keeps the retry loop and replays from processing the same stored items
+	lock         sync.Mutex
+	queue        storeQueue
 	runtime      *GolangRuntime
 	dic          *di.Container
 	pipelineHash string
@@ -58,6 +68,9 @@ func (sf *storeForwardInfo) startStoreAndForwardRetryLoop(
 	config := container.ConfigurationFrom(sf.dic.Get)
 	lc := bootstrapContainer.LoggingClientFrom(sf.dic.Get)
 
+	// The store client may have changed since Store and Forward was last enabled
+	sf.queue.reset()
+
 	go func() {
 		defer appWg.Done()
 		defer enabledWg.Done()
@@ -81,6 +94,11 @@ func (sf *storeForwardInfo) startStoreAndForwardRetryLoop(
 			config.Writable.StoreAndForward.MaxRetryCount = 1
 		}
 
+		if len(config.Writable.StoreAndForward.MaxRetryBackoff) > 0 && retryBackoffMax(config.Writable.StoreAndForward) <= 0 {
+			lc.Warnf("StoreAndForward MaxRetryBackoff value '%s' is invalid, retries will not be backed off",
+				config.Writable.StoreAndForward.MaxRetryBackoff)
+		}
+
 		lc.Info(
 			fmt.Sprintf("Starting StoreAndForward Retry Loop with %s RetryInterval and %d max retries",
 				retryInterval.String(), config.Writable.StoreAndForward.MaxRetryCount))
@@ -108,11 +126,12 @@ func (sf *storeForwardInfo) startStoreAndForwardRetryLoop(
 
 func (sf *storeForwardInfo) storeForLaterRetry(
 	payload []byte,
-	appContext interfaces.AppFunctionContext,
+	appContext *appfunction.Context,
 	pipelinePosition int) {
 
 	item := contracts.NewStoredObject(sf.runtime.ServiceKey, payload, pipelinePosition, sf.pipelineHash, appContext.GetAllValues())
 	item.CorrelationID = appContext.CorrelationID()
+	item.Input, item.InputContentType = appContext.PipelineInput()
 
 	appContext.LoggingClient().Trace("Storing data for later retry",
 		common.CorrelationHeader, appContext.CorrelationID())
@@ -125,9 +144,40 @@ func (sf *storeForwardInfo) storeForLaterRetry(
 		return
 	}
 
+	if storeEncryptionEnabled(config.Writable.StoreAndForward) {
+		// Never fall back to storing the data unencrypted
+		aead, err := newStoreCipher(appContext, config.Writable.StoreAndForward)
+		if err != nil {
+			appContext.LoggingClient().Error("Failed to store item for later retry",
+				"error", err,
+				common.CorrelationHeader, item.CorrelationID)
+			return
+		}
+
+		item.Payload, err = encryptPayload(aead, item.Payload)
+		if err == nil && len(item.Input) > 0 {
+			item.Input, err = encryptPayload(aead, item.Input)
+		}
+		if err != nil {
+			appContext.LoggingClient().Error("Failed to encrypt item for later retry",
+				"error", err,
+				common.CorrelationHeader, item.CorrelationID)
+			return
+		}
+		item.Encrypted = true
+	}
+
+	if err := sf.makeRoomForItem(appContext, item); err != nil {
+		appContext.LoggingClient().Error("Failed to store item for later retry",
+			"error", err,
+			common.CorrelationHeader, item.CorrelationID)
+		return
+	}
+
 	storeClient := container.StoreClientFrom(sf.dic.Get)
 
 	if _, err := storeClient.Store(item); err != nil {
+		sf.queue.removed(item)
 		appContext.LoggingClient().Error("Failed to store item for later retry",
 			"error", err,
 			common.CorrelationHeader, item.CorrelationID)
@@ -135,6 +185,8 @@ func (sf *storeForwardInfo) storeForLaterRetry(
 }
 
 func (sf *storeForwardInfo) retryStoredData(serviceKey string) {
+	sf.lock.Lock()
+	defer sf.lock.Unlock()
 
 	storeClient := container.StoreClientFrom(sf.dic.Get)
 	lc := bootstrapContainer.LoggingClientFrom(sf.dic.Get)
@@ -162,7 +214,9 @@ func (sf *storeForwardInfo) retryStoredData(serviceKey string) {
 					"error", err,
 					"objectID", item.ID,
 					common.CorrelationHeader, item.CorrelationID)
+				continue
 			}
+			sf.queue.removed(item)
 		}
 
 		for _, item := range itemsToUpdate {
@@ -183,12 +237,50 @@ func (sf *storeForwardInfo) processRetryItems(items []contracts.StoredObject) ([
 	var itemsToRemove []contracts.StoredObject
 	var itemsToUpdate []contracts.StoredObject
 
-	for _, item := range items {
+	ordered := config.Writable.StoreAndForward.Ordered
+	if ordered {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Created < items[j].Created
+		})
+	}
+
+	now := time.Now()
+	maxBackoff := retryBackoffMax(config.Writable.StoreAndForward)
+
+	retryFailed := false
+	for index, item := range items {
+		if ordered && retryFailed {
+			// Remaining items can't be exported ahead of the item that failed
+			lc.Debugf("Ordered retry stopped at first failure. %d stored data items left for next retry", len(items)-index)
+			break
+		}
+
+		if item.NextRetryTime > now.UnixNano() {
+			if ordered {
+				lc.Debugf("Ordered retry stopped at item not yet due for retry. %d stored data items left for next retry", len(items)-index)
+				break
+			}
+
+			// Item is left as is until it is due for retry
+			continue
+		}
+
 		if item.Version == sf.calculatePipelineHash() {
-			if !sf.retryExportFunction(item) {
+			if messageError := sf.retryExportFunction(&item); messageError != nil {
 				item.RetryCount++
-				if config.Writable.StoreAndForward.MaxRetryCount == 0 ||
+				if messageError.Category == util.ErrorCategoryFatal {
+					lc.Error(
+						"Export retry failed with fatal error. Removing item from DB",
+						"error", messageError.Err,
+						common.CorrelationHeader,
+						item.CorrelationID)
+				} else if config.Writable.StoreAndForward.MaxRetryCount == 0 ||
 					item.RetryCount < config.Writable.StoreAndForward.MaxRetryCount {
+					retryFailed = true
+					if maxBackoff > 0 {
+						delay := retryBackoffDelay(retryIntervalFrom(config.Writable.StoreAndForward), item.RetryCount, maxBackoff)
+						item.NextRetryTime = now.Add(delay).UnixNano()
+					}
 					lc.Trace("Export retry failed. Incrementing retry count",
 						"retries",
 						item.RetryCount,
@@ -196,13 +288,14 @@ func (sf *storeForwardInfo) processRetryItems(items []contracts.StoredObject) ([
 						item.CorrelationID)
 					itemsToUpdate = append(itemsToUpdate, item)
 					continue
+				} else {
+					retryFailed = true
+					lc.Trace(
+						"Max retries exceeded. Removing item from DB", "retries",
+						item.RetryCount,
+						common.CorrelationHeader,
+						item.CorrelationID)
 				}
-
-				lc.Trace(
-					"Max retries exceeded. Removing item from DB", "retries",
-					item.RetryCount,
-					common.CorrelationHeader,
-					item.CorrelationID)
 				// Note that item will be removed for DB below.
 			} else {
 				lc.Trace(
@@ -220,6 +313,7 @@ func (sf *storeForwardInfo) processRetryItems(items []contracts.StoredObject) ([
 		// Item will be remove from store if:
 		//    - successfully retried
 		//    - max retries exceeded
+		//    - retry failed with a fatal error
 		//    - version no longer matches current Pipeline
 		// Item will not be removed if retry failed and more retries available (hit 'continue' above)
 		itemsToRemove = append(itemsToRemove, item)
@@ -228,22 +322,89 @@ func (sf *storeForwardInfo) processRetryItems(items []contracts.StoredObject) ([
 	return itemsToRemove, itemsToUpdate
 }
 
-func (sf *storeForwardInfo) retryExportFunction(item contracts.StoredObject) bool {
+// retryIntervalFrom returns the configured RetryInterval, or the minimum interval if it is invalid or too small
+func retryIntervalFrom(config sdkCommon.StoreAndForwardInfo) time.Duration {
+	retryInterval, err := time.ParseDuration(config.RetryInterval)
+	if err != nil || retryInterval < defaultMinRetryInterval {
+		return defaultMinRetryInterval
+	}
+
+	return retryInterval
+}
+
+// retryBackoffMax returns the configured MaxRetryBackoff, or zero if retries aren't backed off
+func retryBackoffMax(config sdkCommon.StoreAndForwardInfo) time.Duration {
+	if len(config.MaxRetryBackoff) == 0 {
+		return 0
+	}
+
+	maxBackoff, err := time.ParseDuration(config.MaxRetryBackoff)
+	if err != nil {
+		return 0
+	}
+
+	return maxBackoff
+}
+
+// retryBackoffDelay returns the delay before the next retry of an item which has failed retryCount times.
+// The delay starts at the retry interval and doubles with each failure, up to maxBackoff.
+func retryBackoffDelay(retryInterval time.Duration, retryCount int, maxBackoff time.Duration) time.Duration {
+	delay := retryInterval
+	for failures := 1; failures < retryCount && delay < maxBackoff; failures++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+
+	return delay
+}
+
+// retryExportFunction re-runs the stored data through the pipeline from the position that failed with the context
+// values restored. The item's context values are updated with those at the end of the retry, so values added or
+// changed by the functions retried are kept for the next retry if it fails again.
+func (sf *storeForwardInfo) retryExportFunction(item *contracts.StoredObject) *MessageError {
 	appContext := appfunction.NewContext(item.CorrelationID, sf.dic, "")
 
 	for k, v := range item.ContextData {
 		appContext.AddValue(strings.ToLower(k), v)
 	}
 
+	// Data stored before the enqueued time was recorded is treated as enqueued when first stored
+	if _, found := appContext.GetValue(interfaces.ENQUEUED); !found {
+		appContext.AddValue(interfaces.ENQUEUED, strconv.FormatInt(item.Created, 10))
+	}
+	setPipelineStart(appContext, time.Now())
+
 	appContext.LoggingClient().Trace("Retrying stored data", common.CorrelationHeader, appContext.CorrelationID())
 
-	return sf.runtime.ExecutePipeline(
-		item.Payload,
+	payload := item.Payload
+	if item.Encrypted {
+		// Key may only be temporarily unavailable, so failing to decrypt counts as a failed retry
+		config := container.ConfigurationFrom(sf.dic.Get)
+		aead, err := newStoreCipher(appContext, config.Writable.StoreAndForward)
+		if err == nil {
+			payload, err = decryptPayload(aead, item.Payload)
+		}
+		if err != nil {
+			appContext.LoggingClient().Error("Unable to decrypt stored data",
+				"error", err,
+				common.CorrelationHeader, item.CorrelationID)
+			return &MessageError{Err: err, ErrorCode: http.StatusInternalServerError, Category: util.ErrorCategoryRetryable}
+		}
+	}
+
+	messageError := sf.runtime.ExecutePipeline(
+		payload,
 		"",
 		appContext,
 		sf.runtime.transforms,
 		item.PipelinePosition,
-		true) == nil
+		true)
+
+	item.ContextData = appContext.GetAllValues()
+	return messageError
 }
 
 func (sf *storeForwardInfo) calculatePipelineHash() string {
@@ -19,7 +19,12 @@ package runtime
 import (
 	"errors"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
@@ -36,6 +41,7 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/db/interfaces/mocks"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
 )
 
 var dic *di.Container
@@ -66,6 +72,16 @@ func TestProcessRetryItems(t *testing.T) {
 	expectedPayload := "This is a sample payload"
 	contextData := map[string]string{"x": "y"}
 
+	// The stored context data is restored along with the queue time, which includes the time spent in Store and Forward
+	requireContextData := func(appContext interfaces.AppFunctionContext) {
+		values := appContext.GetAllValues()
+		for key, value := range contextData {
+			require.Equal(t, value, values[key])
+		}
+		require.Contains(t, values, interfaces.QUEUETIME)
+		require.Contains(t, values, interfaces.PIPELINESTART)
+	}
+
 	transformPassthru := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
 		return true, data
 	}
@@ -77,15 +93,20 @@ func TestProcessRetryItems(t *testing.T) {
 
 		require.True(t, ok, "Expected []byte payload")
 		require.Equal(t, expectedPayload, string(actualPayload))
-		require.Equal(t, contextData, appContext.GetAllValues())
+		requireContextData(appContext)
 		return false, nil
 	}
 
 	failureTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
 		targetTransformWasCalled = true
-		require.Equal(t, contextData, appContext.GetAllValues())
+		requireContextData(appContext)
 		return false, errors.New("I failed")
 	}
+
+	fatalTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		targetTransformWasCalled = true
+		return false, util.NewFatalError(errors.New("I can't succeed"))
+	}
 	runtime := GolangRuntime{}
 
 	tests := []struct {
@@ -102,6 +123,7 @@ func TestProcessRetryItems(t *testing.T) {
 		{"Happy Path", successTransform, true, expectedPayload, 0, 0, 1, false, contextData},
 		{"RetryCount Increased", failureTransform, true, expectedPayload, 4, 5, 0, false, contextData},
 		{"Max Retries", failureTransform, true, expectedPayload, 9, 9, 1, false, contextData},
+		{"Fatal Error", fatalTransform, true, expectedPayload, 4, 4, 1, false, contextData},
 		{"Bad Version", successTransform, false, expectedPayload, 0, 0, 1, true, contextData},
 	}
 
@@ -131,6 +153,133 @@ func TestProcessRetryItems(t *testing.T) {
 	}
 }
 
+func TestProcessRetryItems_ContextValues(t *testing.T) {
+	var retriedValues []string
+	countRetries := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		retries, _ := appContext.GetValue("retries")
+		retriedValues = append(retriedValues, retries)
+		appContext.AddValue("retries", retries+"+")
+		return true, data
+	}
+	failureTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, errors.New("I failed")
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{countRetries, failureTransform})
+
+	item := contracts.NewStoredObject("dummy", []byte("data"), 0, runtime.storeForward.pipelineHash,
+		map[string]string{"topic": "computed/topic", "retries": ""})
+
+	for retry := 1; retry <= 2; retry++ {
+		removes, updates := runtime.storeForward.processRetryItems([]contracts.StoredObject{item})
+		require.Empty(t, removes)
+		require.Len(t, updates, 1)
+		item = updates[0]
+
+		// Values set by the functions retried are kept for the next retry, along with those stored originally
+		assert.Equal(t, "computed/topic", item.ContextData["topic"])
+		assert.Equal(t, strings.Repeat("+", retry), item.ContextData["retries"])
+	}
+
+	assert.Equal(t, []string{"", "+"}, retriedValues)
+}
+
+func TestProcessRetryItems_Ordered(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.StoreAndForward.Ordered = true
+	defer func() {
+		config.Writable.StoreAndForward.Ordered = false
+	}()
+
+	var exported []string
+	exportTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		payload := string(data.([]byte))
+		exported = append(exported, payload)
+		if payload == "second" {
+			return false, errors.New("I failed")
+		}
+		return false, nil
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{exportTransform})
+
+	var items []contracts.StoredObject
+	for created, payload := range map[int64]string{3: "third", 1: "first", 2: "second", 4: "fourth"} {
+		item := contracts.NewStoredObject("dummy", []byte(payload), 0, runtime.storeForward.pipelineHash, nil)
+		item.Created = created
+		items = append(items, item)
+	}
+
+	removes, updates := runtime.storeForward.processRetryItems(items)
+
+	assert.Equal(t, []string{"first", "second"}, exported)
+	require.Len(t, removes, 1)
+	assert.Equal(t, "first", string(removes[0].Payload))
+	require.Len(t, updates, 1)
+	assert.Equal(t, "second", string(updates[0].Payload))
+	assert.Equal(t, 1, updates[0].RetryCount)
+}
+
+func TestProcessRetryItems_Backoff(t *testing.T) {
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.StoreAndForward.RetryInterval = "1s"
+	config.Writable.StoreAndForward.MaxRetryBackoff = "10s"
+	defer func() {
+		config.Writable.StoreAndForward.RetryInterval = ""
+		config.Writable.StoreAndForward.MaxRetryBackoff = ""
+	}()
+
+	var exported []string
+	exportTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		exported = append(exported, string(data.([]byte)))
+		return false, errors.New("I failed")
+	}
+
+	runtime := GolangRuntime{}
+	runtime.Initialize(dic)
+	runtime.SetTransforms([]interfaces.AppFunction{exportTransform})
+
+	due := contracts.NewStoredObject("dummy", []byte("due"), 0, runtime.storeForward.pipelineHash, nil)
+	due.RetryCount = 2
+	notDue := contracts.NewStoredObject("dummy", []byte("not due"), 0, runtime.storeForward.pipelineHash, nil)
+	notDue.NextRetryTime = time.Now().Add(time.Hour).UnixNano()
+
+	started := time.Now()
+	removes, updates := runtime.storeForward.processRetryItems([]contracts.StoredObject{due, notDue})
+
+	assert.Equal(t, []string{"due"}, exported, "expected item not yet due to be skipped")
+	assert.Len(t, removes, 0)
+	require.Len(t, updates, 1)
+	assert.Equal(t, 3, updates[0].RetryCount)
+	// Third failure waits 4 times the retry interval
+	nextRetry := time.Unix(0, updates[0].NextRetryTime)
+	assert.WithinDuration(t, started.Add(4*time.Second), nextRetry, time.Second)
+}
+
+func TestRetryBackoffDelay(t *testing.T) {
+	tests := []struct {
+		RetryCount int
+		Expected   time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{5, 16 * time.Minute},
+		{6, 30 * time.Minute},
+		{1000, 30 * time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(strconv.Itoa(test.RetryCount), func(t *testing.T) {
+			assert.Equal(t, test.Expected, retryBackoffDelay(time.Minute, test.RetryCount, 30*time.Minute))
+		})
+	}
+}
+
 func TestDoStoreAndForwardRetry(t *testing.T) {
 	serviceKey := "AppService-UnitTest"
 	payload := []byte("My Payload")
@@ -181,6 +330,7 @@ func TestDoStoreAndForwardRetry(t *testing.T) {
 }
 
 var mockObjectStore map[string]contracts.StoredObject
+var mockObjectStoreMutex sync.Mutex
 
 func updateDicWithMockStoreClient() *di.Container {
 	mockObjectStore = make(map[string]contracts.StoredObject)
@@ -189,6 +339,7 @@ func updateDicWithMockStoreClient() *di.Container {
 	storeClient.Mock.On("RemoveFromStore", mock.Anything).Return(mockRemoveObject)
 	storeClient.Mock.On("Update", mock.Anything).Return(mockUpdateObject)
 	storeClient.Mock.On("RetrieveFromStore", mock.Anything).Return(mockRetrieveObjects, nil)
+	storeClient.Mock.On("RetrieveOldestFromStore", mock.Anything, mock.Anything).Return(mockRetrieveOldestObjects, nil)
 
 	dic.Update(di.ServiceConstructorMap{
 		container.StoreClientName: func(get di.Get) interface{} {
@@ -208,6 +359,8 @@ func mockStoreObject(object contracts.StoredObject) (string, error) {
 		object.ID = uuid.New().String()
 	}
 
+	mockObjectStoreMutex.Lock()
+	defer mockObjectStoreMutex.Unlock()
 	mockObjectStore[object.ID] = object
 
 	return object.ID, nil
@@ -219,6 +372,8 @@ func mockUpdateObject(object contracts.StoredObject) error {
 		return err
 	}
 
+	mockObjectStoreMutex.Lock()
+	defer mockObjectStoreMutex.Unlock()
 	mockObjectStore[object.ID] = object
 	return nil
 }
@@ -228,11 +383,16 @@ func mockRemoveObject(object contracts.StoredObject) error {
 		return err
 	}
 
+	mockObjectStoreMutex.Lock()
+	defer mockObjectStoreMutex.Unlock()
 	delete(mockObjectStore, object.ID)
 	return nil
 }
 
 func mockRetrieveObjects(serviceKey string) []contracts.StoredObject {
+	mockObjectStoreMutex.Lock()
+	defer mockObjectStoreMutex.Unlock()
+
 	var objects []contracts.StoredObject
 	for _, item := range mockObjectStore {
 		if item.AppServiceKey == serviceKey {
@@ -243,6 +403,19 @@ func mockRetrieveObjects(serviceKey string) []contracts.StoredObject {
 	return objects
 }
 
+func mockRetrieveOldestObjects(serviceKey string, count int) []contracts.StoredObject {
+	objects := mockRetrieveObjects(serviceKey)
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].Created < objects[j].Created
+	})
+
+	if len(objects) > count {
+		objects = objects[:count]
+	}
+
+	return objects
+}
+
 // TODO remove this and use verify func on StoredObject when it is available
 func validateContract(IDRequired bool, o contracts.StoredObject) error {
 	if IDRequired {
@@ -0,0 +1,272 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	storeInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/db/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+)
+
+const (
+	evictionPolicyDropOldest = "dropoldest"
+	evictionPolicyDropNewest = "dropnewest"
+	evictionPolicyBlock      = "block"
+
+	// evictionBatchSize is the number of the oldest stored items retrieved at a time for eviction
+	evictionBatchSize = 10
+)
+
+// queueFullPollInterval is how often the Block eviction policy checks whether the queue has room
+var queueFullPollInterval = defaultMinRetryInterval
+
+// storeQueue keeps running totals of the number and size of the stored items, so the queue limits can be checked
+// without retrieving every stored item. The totals are loaded from the store when first needed.
+type storeQueue struct {
+	lock   sync.Mutex
+	loaded bool
+	count  int
+	bytes  int64
+}
+
+// storedSize returns the size of the item counted against MaxQueueBytes
+func storedSize(item contracts.StoredObject) int64 {
+	return int64(len(item.Payload) + len(item.Input))
+}
+
+// storeQueueLimited returns true if the configuration limits the number of items or bytes stored
+func storeQueueLimited(config common.StoreAndForwardInfo) bool {
+	return config.MaxQueueSize > 0 || config.MaxQueueBytes > 0
+}
+
+// load retrieves the stored items to calculate the totals, unless they have already been loaded.
+// Must be called with the lock held.
+func (queue *storeQueue) load(storeClient storeInterfaces.StoreClient, serviceKey string) error {
+	if queue.loaded {
+		return nil
+	}
+
+	stored, err := storeClient.RetrieveFromStore(serviceKey)
+	if err != nil {
+		return fmt.Errorf("unable to determine StoreAndForward queue size: %s", err.Error())
+	}
+
+	queue.count = len(stored)
+	queue.bytes = 0
+	for _, item := range stored {
+		queue.bytes += storedSize(item)
+	}
+	queue.loaded = true
+
+	return nil
+}
+
+// hasRoom returns true if the item can be added to the stored items without exceeding the configured limits.
+// Must be called with the lock held.
+func (queue *storeQueue) hasRoom(config common.StoreAndForwardInfo, item contracts.StoredObject) bool {
+	if config.MaxQueueSize > 0 && queue.count+1 > config.MaxQueueSize {
+		return false
+	}
+
+	if config.MaxQueueBytes > 0 && queue.bytes+storedSize(item) > config.MaxQueueBytes {
+		return false
+	}
+
+	return true
+}
+
+// add counts the item in the totals. Must be called with the lock held.
+func (queue *storeQueue) add(item contracts.StoredObject) {
+	queue.count++
+	queue.bytes += storedSize(item)
+}
+
+// remove removes the item from the totals. Must be called with the lock held.
+func (queue *storeQueue) remove(item contracts.StoredObject) {
+	if !queue.loaded {
+		return
+	}
+
+	queue.count--
+	queue.bytes -= storedSize(item)
+	if queue.count <= 0 {
+		queue.count = 0
+		queue.bytes = 0
+	}
+}
+
+// removed removes an item which has been removed from the store from the totals
+func (queue *storeQueue) removed(item contracts.StoredObject) {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	queue.remove(item)
+}
+
+// reset discards the totals so they are loaded from the store when next needed
+func (queue *storeQueue) reset() {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	queue.loaded = false
+}
+
+// makeRoomForItem applies the configured eviction policy when storing the item would exceed the queue limits.
+// Returns an error if the item should not be stored. Otherwise the item is counted in the queue totals, so must
+// be removed from them if it fails to be stored.
+func (sf *storeForwardInfo) makeRoomForItem(appContext interfaces.AppFunctionContext, item contracts.StoredObject) error {
+	config := container.ConfigurationFrom(sf.dic.Get)
+	storeConfig := config.Writable.StoreAndForward
+	if !storeQueueLimited(storeConfig) {
+		// Items aren't counted while the queue is unlimited, so the totals must be reloaded if limits are set later
+		sf.queue.reset()
+		return nil
+	}
+
+	size := storedSize(item)
+	if storeConfig.MaxQueueBytes > 0 && size > storeConfig.MaxQueueBytes {
+		sf.recordEvictions(appContext, 1, "item is larger than MaxQueueBytes", item.CorrelationID)
+		return fmt.Errorf("item size of %d bytes exceeds StoreAndForward MaxQueueBytes of %d", size, storeConfig.MaxQueueBytes)
+	}
+
+	storeClient := container.StoreClientFrom(sf.dic.Get)
+
+	sf.queue.lock.Lock()
+	defer sf.queue.lock.Unlock()
+
+	for {
+		if err := sf.queue.load(storeClient, sf.runtime.ServiceKey); err != nil {
+			return err
+		}
+
+		if sf.queue.hasRoom(storeConfig, item) {
+			sf.queue.add(item)
+			return nil
+		}
+
+		switch strings.ToLower(storeConfig.EvictionPolicy) {
+		case evictionPolicyDropOldest, "":
+			evicted, err := sf.evictOldest(storeClient, storeConfig, item)
+			sf.recordEvictions(appContext, evicted, "evicted oldest stored data", item.CorrelationID)
+			if err != nil {
+				return err
+			}
+
+		case evictionPolicyDropNewest:
+			sf.recordEvictions(appContext, 1, "dropped newest data", item.CorrelationID)
+			return errors.New("StoreAndForward queue is full")
+
+		case evictionPolicyBlock:
+			appContext.LoggingClient().Debug("StoreAndForward queue is full. Waiting for stored data to be retried",
+				contractsCommon.CorrelationHeader, item.CorrelationID)
+
+			// Retries remove items from the queue totals while waiting
+			sf.queue.lock.Unlock()
+			time.Sleep(queueFullPollInterval)
+			sf.queue.lock.Lock()
+
+			// Stop waiting if Store and Forward has been disabled in the meantime
+			if !container.ConfigurationFrom(sf.dic.Get).Writable.StoreAndForward.Enabled {
+				return errors.New("StoreAndForward disabled while waiting for room in the queue")
+			}
+
+		default:
+			return fmt.Errorf("invalid StoreAndForward EvictionPolicy '%s'", storeConfig.EvictionPolicy)
+		}
+	}
+}
+
+// evictOldest removes the oldest stored items until there is room for the item and returns the number evicted.
+// Must be called with the queue lock held.
+func (sf *storeForwardInfo) evictOldest(
+	storeClient storeInterfaces.StoreClient,
+	storeConfig common.StoreAndForwardInfo,
+	item contracts.StoredObject) (int, error) {
+	evicted := 0
+
+	for !sf.queue.hasRoom(storeConfig, item) {
+		oldest, err := storeClient.RetrieveOldestFromStore(sf.runtime.ServiceKey, evictionBatchSize)
+		if err != nil {
+			return evicted, fmt.Errorf("unable to retrieve oldest stored data items to evict: %s", err.Error())
+		}
+
+		if len(oldest) == 0 {
+			// The totals are out of step with the store, so are reloaded
+			sf.queue.loaded = false
+			if err := sf.queue.load(storeClient, sf.runtime.ServiceKey); err != nil {
+				return evicted, err
+			}
+			if !sf.queue.hasRoom(storeConfig, item) {
+				return evicted, errors.New("unable to find the oldest stored data items to evict")
+			}
+			return evicted, nil
+		}
+
+		var removeErr error
+		removed := 0
+		for _, stored := range oldest {
+			if sf.queue.hasRoom(storeConfig, item) {
+				break
+			}
+
+			// The item may have just been removed by a retry, which removes it from the totals itself
+			if err := storeClient.RemoveFromStore(stored); err != nil {
+				removeErr = err
+				continue
+			}
+
+			sf.queue.remove(stored)
+			removed++
+		}
+
+		if removed == 0 && removeErr != nil {
+			return evicted, fmt.Errorf("unable to evict oldest stored data item: %s", removeErr.Error())
+		}
+
+		evicted += removed
+	}
+
+	return evicted, nil
+}
+
+// recordEvictions counts the evicted items and logs the eviction along with the running total
+func (sf *storeForwardInfo) recordEvictions(appContext interfaces.AppFunctionContext, count int, reason string, correlationID string) {
+	if count == 0 {
+		return
+	}
+
+	total := atomic.AddUint64(&sf.evictedCount, uint64(count))
+	appContext.LoggingClient().Warn(
+		fmt.Sprintf("StoreAndForward queue limit reached, %s", reason),
+		"evicted", count,
+		"totalEvicted", total,
+		contractsCommon.CorrelationHeader, correlationID)
+}
+
+// evictions returns the number of items dropped from Store and Forward because the queue was full
+func (sf *storeForwardInfo) evictions() uint64 {
+	return atomic.LoadUint64(&sf.evictedCount)
+}
@@ -0,0 +1,209 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/db/interfaces/mocks"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/transforms"
+
+	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreQueueHasRoom(t *testing.T) {
+	queue := storeQueue{}
+	queue.add(contracts.StoredObject{Payload: []byte("12345")})
+	queue.add(contracts.StoredObject{Payload: []byte("123"), Input: []byte("45")})
+	item := contracts.StoredObject{Payload: []byte("12"), Input: []byte("3")}
+
+	tests := []struct {
+		Name     string
+		Config   common.StoreAndForwardInfo
+		Expected bool
+	}{
+		{"Unlimited", common.StoreAndForwardInfo{}, true},
+		{"Room for item", common.StoreAndForwardInfo{MaxQueueSize: 3}, true},
+		{"Too many items", common.StoreAndForwardInfo{MaxQueueSize: 2}, false},
+		{"Room for bytes", common.StoreAndForwardInfo{MaxQueueBytes: 13}, true},
+		{"Too many bytes", common.StoreAndForwardInfo{MaxQueueBytes: 12}, false},
+		{"Bytes exceeded with room for items", common.StoreAndForwardInfo{MaxQueueSize: 10, MaxQueueBytes: 12}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, queue.hasRoom(test.Config, item))
+		})
+	}
+}
+
+func TestStoreForLaterRetry_QueueLimits(t *testing.T) {
+	serviceKey := "AppService-UnitTest"
+
+	config := container.ConfigurationFrom(dic.Get)
+	defer func() {
+		config.Writable.StoreAndForward.MaxQueueSize = 0
+		config.Writable.StoreAndForward.MaxQueueBytes = 0
+		config.Writable.StoreAndForward.EvictionPolicy = ""
+	}()
+
+	storedPayloads := func() []string {
+		objects := mockRetrieveObjects(serviceKey)
+		sort.Slice(objects, func(i, j int) bool {
+			return objects[i].Created < objects[j].Created
+		})
+		var payloads []string
+		for _, object := range objects {
+			payloads = append(payloads, string(object.Payload))
+		}
+		return payloads
+	}
+
+	tests := []struct {
+		Name            string
+		MaxQueueSize    int
+		MaxQueueBytes   int64
+		EvictionPolicy  string
+		Payloads        []string
+		ExpectedStored  []string
+		ExpectedEvicted uint64
+	}{
+		{"Unlimited", 0, 0, "", []string{"one", "two", "three"}, []string{"one", "two", "three"}, 0},
+		{"Drop oldest by default", 2, 0, "", []string{"one", "two", "three"}, []string{"two", "three"}, 1},
+		{"Drop oldest by bytes", 0, 8, "DropOldest", []string{"one", "two", "three"}, []string{"two", "three"}, 1},
+		{"Drop newest", 2, 0, "DropNewest", []string{"one", "two", "three"}, []string{"one", "two"}, 1},
+		{"Item larger than limit", 0, 4, "DropOldest", []string{"one", "three"}, []string{"one"}, 1},
+		{"Invalid policy", 1, 0, "bogus", []string{"one", "two"}, []string{"one"}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			config.Writable.StoreAndForward.MaxQueueSize = test.MaxQueueSize
+			config.Writable.StoreAndForward.MaxQueueBytes = test.MaxQueueBytes
+			config.Writable.StoreAndForward.EvictionPolicy = test.EvictionPolicy
+
+			runtime := GolangRuntime{ServiceKey: serviceKey}
+			runtime.Initialize(updateDicWithMockStoreClient())
+			runtime.SetTransforms([]interfaces.AppFunction{transforms.NewResponseData().SetResponseData})
+			runtime.SetTransforms([]interfaces.AppFunction{transforms.NewResponseData().SetResponseData})
+
+			for _, payload := range test.Payloads {
+				runtime.storeForward.storeForLaterRetry([]byte(payload), appfunction.NewContext("123", dic, ""), 0)
+				// Ensure each item has a distinct creation time so the oldest is deterministic
+				time.Sleep(time.Millisecond)
+			}
+
+			assert.Equal(t, test.ExpectedStored, storedPayloads())
+			assert.Equal(t, test.ExpectedEvicted, runtime.storeForward.evictions())
+		})
+	}
+}
+
+func TestStoreForLaterRetry_QueueTotals(t *testing.T) {
+	serviceKey := "AppService-UnitTest"
+
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.StoreAndForward.MaxQueueBytes = 18
+	defer func() {
+		config.Writable.StoreAndForward.MaxQueueBytes = 0
+	}()
+
+	runtime := GolangRuntime{ServiceKey: serviceKey}
+	runtime.Initialize(updateDicWithMockStoreClient())
+	runtime.SetTransforms([]interfaces.AppFunction{transforms.NewResponseData().SetResponseData})
+
+	// Each item is 9 bytes including its input, so only two fit
+	for _, payload := range []string{"one", "two", "six"} {
+		appContext := appfunction.NewContext("123", dic, "")
+		appContext.SetPipelineInput([]byte("input_"), contractsCommon.ContentTypeText)
+		runtime.storeForward.storeForLaterRetry([]byte(payload), appContext, 0)
+		time.Sleep(time.Millisecond)
+	}
+
+	objects := mockRetrieveOldestObjects(serviceKey, 10)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "two", string(objects[0].Payload))
+	assert.Equal(t, "six", string(objects[1].Payload))
+	assert.Equal(t, uint64(1), runtime.storeForward.evictions())
+
+	// The stored items are only retrieved to load the totals, after which they are kept up to date
+	storeClient := container.StoreClientFrom(dic.Get).(*mocks.StoreClient)
+	storeClient.AssertNumberOfCalls(t, "RetrieveFromStore", 1)
+	storeClient.AssertNumberOfCalls(t, "RetrieveOldestFromStore", 1)
+	assert.Equal(t, 2, runtime.storeForward.queue.count)
+	assert.Equal(t, int64(18), runtime.storeForward.queue.bytes)
+}
+
+func TestStoreForLaterRetry_Block(t *testing.T) {
+	serviceKey := "AppService-UnitTest"
+
+	config := container.ConfigurationFrom(dic.Get)
+	config.Writable.StoreAndForward.MaxQueueSize = 1
+	config.Writable.StoreAndForward.EvictionPolicy = "Block"
+	defer func() {
+		config.Writable.StoreAndForward.MaxQueueSize = 0
+		config.Writable.StoreAndForward.EvictionPolicy = ""
+	}()
+
+	pollInterval := queueFullPollInterval
+	queueFullPollInterval = 10 * time.Millisecond
+	defer func() { queueFullPollInterval = pollInterval }()
+
+	runtime := GolangRuntime{ServiceKey: serviceKey}
+	runtime.Initialize(updateDicWithMockStoreClient())
+	runtime.SetTransforms([]interfaces.AppFunction{transforms.NewResponseData().SetResponseData})
+
+	runtime.storeForward.storeForLaterRetry([]byte("one"), appfunction.NewContext("123", dic, ""), 0)
+	objects := mockRetrieveObjects(serviceKey)
+	require.Len(t, objects, 1)
+
+	stored := make(chan struct{})
+	go func() {
+		runtime.storeForward.storeForLaterRetry([]byte("two"), appfunction.NewContext("123", dic, ""), 0)
+		close(stored)
+	}()
+
+	select {
+	case <-stored:
+		require.Fail(t, "expected store to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A successful retry removes the stored item, making room in the queue
+	runtime.storeForward.retryStoredData(serviceKey)
+
+	select {
+	case <-stored:
+	case <-time.After(time.Second):
+		require.Fail(t, "expected store to complete once the queue has room")
+	}
+
+	objects = mockRetrieveObjects(serviceKey)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "two", string(objects[0].Payload))
+	assert.Equal(t, uint64(0), runtime.storeForward.evictions())
+}
@@ -0,0 +1,183 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+)
+
+// ReplayStoredData removes the stored items matching the filter from the store and re-runs them, in the order they
+// were stored, through the whole functions pipeline. Items which fail to export again are stored for later retry.
+func (gr *GolangRuntime) ReplayStoredData(filter interfaces.StoreReplayFilter) (interfaces.StoreReplayResult, error) {
+	return gr.storeForward.replayStoredData(filter)
+}
+
+func (sf *storeForwardInfo) replayStoredData(filter interfaces.StoreReplayFilter) (interfaces.StoreReplayResult, error) {
+	result := interfaces.StoreReplayResult{}
+
+	storeClient := container.StoreClientFrom(sf.dic.Get)
+	if storeClient == nil {
+		return result, errors.New("StoreAndForward not enabled")
+	}
+
+	lc := bootstrapContainer.LoggingClientFrom(sf.dic.Get)
+
+	items, err := sf.takeReplayItems(filter, &result)
+	if err != nil {
+		return result, err
+	}
+
+	// Items are replayed outside the lock so that items failing again can be stored without blocking the retry loop
+	for _, item := range items {
+		if sf.replayItem(item) {
+			result.Replayed++
+		} else {
+			result.Failed++
+		}
+	}
+
+	lc.Infof("Replayed %d of %d matching stored data items, %d failed and %d skipped",
+		result.Replayed, result.Matched, result.Failed, result.Skipped)
+
+	return result, nil
+}
+
+// takeReplayItems removes the stored items matching the filter from the store and returns them with their input
+// decrypted. Items which can't be replayed are left in the store.
+func (sf *storeForwardInfo) takeReplayItems(
+	filter interfaces.StoreReplayFilter,
+	result *interfaces.StoreReplayResult) ([]contracts.StoredObject, error) {
+	// Prevents the retry loop from retrying the items while they are being taken
+	sf.lock.Lock()
+	defer sf.lock.Unlock()
+
+	storeClient := container.StoreClientFrom(sf.dic.Get)
+	lc := bootstrapContainer.LoggingClientFrom(sf.dic.Get)
+
+	stored, err := storeClient.RetrieveFromStore(sf.runtime.ServiceKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load store and forward items from DB: %s", err.Error())
+	}
+
+	sort.SliceStable(stored, func(i, j int) bool {
+		return stored[i].Created < stored[j].Created
+	})
+
+	var items []contracts.StoredObject
+	for _, item := range stored {
+		if !replayFilterMatches(filter, item) {
+			continue
+		}
+
+		result.Matched++
+
+		if len(item.Input) == 0 {
+			lc.Warn("Stored data item was stored without its pipeline input and can not be replayed",
+				"objectID", item.ID,
+				common.CorrelationHeader, item.CorrelationID)
+			result.Skipped++
+			continue
+		}
+
+		// The queue totals are for the item as it was stored
+		storedItem := item
+
+		if item.Encrypted {
+			appContext := appfunction.NewContext(item.CorrelationID, sf.dic, "")
+			config := container.ConfigurationFrom(sf.dic.Get)
+			aead, err := newStoreCipher(appContext, config.Writable.StoreAndForward)
+			if err == nil {
+				item.Input, err = decryptPayload(aead, item.Input)
+			}
+			if err != nil {
+				lc.Error("Unable to decrypt stored data for replay",
+					"error", err,
+					"objectID", item.ID,
+					common.CorrelationHeader, item.CorrelationID)
+				result.Failed++
+				continue
+			}
+		}
+
+		if err := storeClient.RemoveFromStore(item); err != nil {
+			lc.Error("Unable to remove stored data item from DB for replay",
+				"error", err,
+				"objectID", item.ID,
+				common.CorrelationHeader, item.CorrelationID)
+			result.Failed++
+			continue
+		}
+		sf.queue.removed(storedItem)
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (sf *storeForwardInfo) replayItem(item contracts.StoredObject) bool {
+	appContext := appfunction.NewContext(item.CorrelationID, sf.dic, item.InputContentType)
+
+	for k, v := range item.ContextData {
+		appContext.AddValue(strings.ToLower(k), v)
+	}
+
+	appContext.LoggingClient().Trace("Replaying stored data", common.CorrelationHeader, appContext.CorrelationID())
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: item.CorrelationID,
+		Payload:       item.Input,
+		ContentType:   item.InputContentType,
+		ReceivedTopic: item.ContextData[interfaces.RECEIVEDTOPIC],
+	}
+
+	// Replayed data was sampled when first received
+	return sf.runtime.processMessage(appContext, envelope, false) == nil
+}
+
+// replayFilterMatches returns true if the stored item matches all the fields set in the filter
+func replayFilterMatches(filter interfaces.StoreReplayFilter, item contracts.StoredObject) bool {
+	if filter.Start > 0 && item.Created < filter.Start {
+		return false
+	}
+
+	if filter.End > 0 && item.Created > filter.End {
+		return false
+	}
+
+	if len(filter.CorrelationID) > 0 && item.CorrelationID != filter.CorrelationID {
+		return false
+	}
+
+	if filter.PipelinePosition != nil && item.PipelinePosition != *filter.PipelinePosition {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayFilterMatches(t *testing.T) {
+	position := 2
+	otherPosition := 1
+	item := contracts.StoredObject{Created: 200, CorrelationID: "123", PipelinePosition: 2}
+
+	tests := []struct {
+		Name     string
+		Filter   interfaces.StoreReplayFilter
+		Expected bool
+	}{
+		{"Empty filter", interfaces.StoreReplayFilter{}, true},
+		{"Within time range", interfaces.StoreReplayFilter{Start: 100, End: 300}, true},
+		{"Start of time range", interfaces.StoreReplayFilter{Start: 200}, true},
+		{"End of time range", interfaces.StoreReplayFilter{End: 200}, true},
+		{"Before time range", interfaces.StoreReplayFilter{Start: 201}, false},
+		{"After time range", interfaces.StoreReplayFilter{End: 199}, false},
+		{"Correlation ID", interfaces.StoreReplayFilter{CorrelationID: "123"}, true},
+		{"Other correlation ID", interfaces.StoreReplayFilter{CorrelationID: "456"}, false},
+		{"Pipeline position", interfaces.StoreReplayFilter{PipelinePosition: &position}, true},
+		{"Other pipeline position", interfaces.StoreReplayFilter{PipelinePosition: &otherPosition}, false},
+		{"All fields", interfaces.StoreReplayFilter{Start: 100, End: 300, CorrelationID: "123", PipelinePosition: &position}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, replayFilterMatches(test.Filter, item))
+		})
+	}
+}
+
+func TestReplayStoredData(t *testing.T) {
+	serviceKey := "AppService-UnitTest"
+
+	var received []string
+	recordTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		received = append(received, string(data.([]byte)))
+		return true, data
+	}
+	exportTransform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		if string(data.([]byte)) == "fail" {
+			appContext.SetRetryData([]byte("export fail"))
+			return false, errors.New("export failed")
+		}
+		return false, nil
+	}
+
+	runtime := GolangRuntime{ServiceKey: serviceKey, TargetType: &[]byte{}}
+	runtime.Initialize(updateDicWithMockStoreClient())
+	runtime.SetTransforms([]interfaces.AppFunction{recordTransform, exportTransform})
+
+	newItem := func(correlationID string, created int64, input string) contracts.StoredObject {
+		item := contracts.NewStoredObject(serviceKey, []byte("export "+input), 1, runtime.storeForward.pipelineHash, nil)
+		item.CorrelationID = correlationID
+		item.Created = created
+		item.Input = []byte(input)
+		return item
+	}
+
+	_, err := mockStoreObject(newItem("two", 200, "fail"))
+	require.NoError(t, err)
+	_, err = mockStoreObject(newItem("one", 100, "one"))
+	require.NoError(t, err)
+	_, err = mockStoreObject(newItem("three", 300, ""))
+	require.NoError(t, err)
+	_, err = mockStoreObject(newItem("four", 400, "four"))
+	require.NoError(t, err)
+
+	result, err := runtime.ReplayStoredData(interfaces.StoreReplayFilter{End: 350})
+	require.NoError(t, err)
+
+	assert.Equal(t, interfaces.StoreReplayResult{Matched: 3, Replayed: 1, Failed: 1, Skipped: 1}, result)
+	assert.Equal(t, []string{"one", "fail"}, received, "matching items not replayed through whole pipeline in stored order")
+
+	stored := mockRetrieveObjects(serviceKey)
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].Created < stored[j].Created
+	})
+	require.Len(t, stored, 3)
+	assert.Equal(t, "three", stored[0].CorrelationID, "item without input should be left in store")
+	assert.Equal(t, "four", stored[1].CorrelationID, "item not matching filter should be left in store")
+	assert.Equal(t, "two", stored[2].CorrelationID, "item failing replay should be stored again")
+	assert.Equal(t, "export fail", string(stored[2].Payload))
+	assert.Equal(t, "fail", string(stored[2].Input))
+	assert.Equal(t, 1, stored[2].PipelinePosition)
+}
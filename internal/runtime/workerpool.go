@@ -0,0 +1,196 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+)
+
+// priority is the class of a message, which determines the order queued messages are processed in
+type priority int
+
+const (
+	priorityHigh priority = iota
+	priorityNormal
+	priorityLow
+	priorityCount
+)
+
+// workerQueue contains a queue for each priority, which workers take messages from highest priority first
+type workerQueue [priorityCount]chan func()
+
+// workerPool contains the queues feeding the bounded set of workers which process messages
+type workerPool struct {
+	lock   sync.RWMutex
+	ctx    context.Context
+	queues []workerQueue
+}
+
+// StartWorkers starts the bounded pool of MaxWorkers workers used by Dispatch. The workers are added to the wait group
+// and stop once the context is done, at which point any messages still queued are not processed. The service starts
+// the workers with the application's context, and further calls have no effect.
+func (gr *GolangRuntime) StartWorkers(appWg *sync.WaitGroup, appCtx context.Context) {
+	if gr.MaxWorkers <= 0 {
+		return
+	}
+
+	gr.workers.lock.Lock()
+	defer gr.workers.lock.Unlock()
+
+	if gr.workers.ctx != nil {
+		return
+	}
+
+	gr.startWorkers(appWg, appCtx)
+}
+
+// Dispatch asynchronously runs the process function, which is expected to process the envelope via ProcessMessage.
+// When MaxWorkers is zero each message is processed in its own goroutine. Otherwise messages are processed by a
+// bounded pool of MaxWorkers workers and Dispatch blocks while all workers are busy, applying back pressure to the
+// trigger. When PreserveDeviceOrder is also set, messages received on the same topic, which for EdgeX events contains
+// the device name, are always processed by the same worker so they are processed in the order received. Queued
+// messages received on HighPriorityTopics are processed before all others and those received on LowPriorityTopics
+// only when no others are queued.
+// Until the workers are started by StartWorkers, i.e. when the runtime is used on its own, messages are processed in
+// their own goroutine as when MaxWorkers is zero.
+// Returns false if the message wasn't dispatched since the workers have been stopped.
+func (gr *GolangRuntime) Dispatch(envelope types.MessageEnvelope, process func()) bool {
+	if gr.MaxWorkers <= 0 {
+		go process()
+		return true
+	}
+
+	gr.workers.lock.RLock()
+	ctx, queues := gr.workers.ctx, gr.workers.queues
+	gr.workers.lock.RUnlock()
+
+	if ctx == nil {
+		go process()
+		return true
+	}
+
+	queue := queues[0]
+	if gr.PreserveDeviceOrder {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(envelope.ReceivedTopic))
+		queue = queues[hash.Sum32()%uint32(len(queues))]
+	}
+
+	select {
+	case queue[gr.priorityOf(envelope.ReceivedTopic)] <- process:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (gr *GolangRuntime) startWorkers(appWg *sync.WaitGroup, appCtx context.Context) {
+	gr.workers.ctx = appCtx
+
+	if gr.PreserveDeviceOrder {
+		// Each worker has its own queues so that messages for a device are processed sequentially
+		gr.workers.queues = make([]workerQueue, gr.MaxWorkers)
+		for index := range gr.workers.queues {
+			gr.workers.queues[index] = newWorkerQueue(1)
+			appWg.Add(1)
+			go runWorker(appWg, appCtx, gr.workers.queues[index])
+		}
+		return
+	}
+
+	// All workers share a single set of queues so that messages are processed by the first available worker
+	queue := newWorkerQueue(gr.MaxWorkers)
+	gr.workers.queues = []workerQueue{queue}
+	for index := 0; index < gr.MaxWorkers; index++ {
+		appWg.Add(1)
+		go runWorker(appWg, appCtx, queue)
+	}
+}
+
+func newWorkerQueue(size int) workerQueue {
+	var queue workerQueue
+	for index := range queue {
+		queue[index] = make(chan func(), size)
+	}
+	return queue
+}
+
+// runWorker processes the messages from the queues, always taking a queued message of a higher priority first,
+// until the context is done
+func runWorker(appWg *sync.WaitGroup, appCtx context.Context, queue workerQueue) {
+	defer appWg.Done()
+
+	for {
+		var process func()
+		select {
+		case <-appCtx.Done():
+			return
+		case process = <-queue[priorityHigh]:
+		default:
+			select {
+			case process = <-queue[priorityHigh]:
+			case process = <-queue[priorityNormal]:
+			default:
+				select {
+				case <-appCtx.Done():
+					return
+				case process = <-queue[priorityHigh]:
+				case process = <-queue[priorityNormal]:
+				case process = <-queue[priorityLow]:
+				}
+			}
+		}
+
+		process()
+	}
+}
+
+// priorityOf returns the priority of messages received on the topic
+func (gr *GolangRuntime) priorityOf(topic string) priority {
+	switch {
+	case matchesAnyTopic(gr.HighPriorityTopics, topic):
+		return priorityHigh
+	case matchesAnyTopic(gr.LowPriorityTopics, topic):
+		return priorityLow
+	default:
+		return priorityNormal
+	}
+}
+
+// matchesAnyTopic returns whether the topic is one of the topics, which match all topics starting with the text
+// before a trailing '#' or '*' wildcard
+func matchesAnyTopic(topics []string, topic string) bool {
+	for _, pattern := range topics {
+		if strings.HasSuffix(pattern, "#") || strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(topic, pattern[:len(pattern)-1]) {
+				return true
+			}
+			continue
+		}
+
+		if pattern == topic {
+			return true
+		}
+	}
+
+	return false
+}
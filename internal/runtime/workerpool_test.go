@@ -0,0 +1,247 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatch_Unbounded(t *testing.T) {
+	runtime := GolangRuntime{}
+
+	wg := sync.WaitGroup{}
+	var processed int32
+	for index := 0; index < 10; index++ {
+		wg.Add(1)
+		runtime.Dispatch(types.MessageEnvelope{}, func() {
+			defer wg.Done()
+			atomic.AddInt32(&processed, 1)
+		})
+	}
+
+	wg.Wait()
+	assert.Equal(t, int32(10), processed)
+}
+
+func TestDispatch_Bounded(t *testing.T) {
+	maxWorkers := 3
+	runtime := GolangRuntime{MaxWorkers: maxWorkers}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runtime.StartWorkers(&sync.WaitGroup{}, ctx)
+
+	wg := sync.WaitGroup{}
+	var active, maxActive int32
+	for index := 0; index < 20; index++ {
+		wg.Add(1)
+		runtime.Dispatch(types.MessageEnvelope{}, func() {
+			defer wg.Done()
+
+			current := atomic.AddInt32(&active, 1)
+			for {
+				previous := atomic.LoadInt32(&maxActive)
+				if current <= previous || atomic.CompareAndSwapInt32(&maxActive, previous, current) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&active, -1)
+		})
+	}
+
+	wg.Wait()
+	assert.LessOrEqual(t, int(maxActive), maxWorkers)
+	assert.Len(t, runtime.workers.queues, 1)
+}
+
+func TestDispatch_PreserveDeviceOrder(t *testing.T) {
+	runtime := GolangRuntime{MaxWorkers: 4, PreserveDeviceOrder: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runtime.StartWorkers(&sync.WaitGroup{}, ctx)
+
+	devices := []string{"device1", "device2", "device3"}
+	messageCount := 50
+
+	lock := sync.Mutex{}
+	received := make(map[string][]int)
+	wg := sync.WaitGroup{}
+
+	for index := 0; index < messageCount; index++ {
+		for _, device := range devices {
+			device := device
+			sequence := index
+			wg.Add(1)
+			runtime.Dispatch(types.MessageEnvelope{ReceivedTopic: "edgex/events/device/profile/" + device}, func() {
+				defer wg.Done()
+				lock.Lock()
+				received[device] = append(received[device], sequence)
+				lock.Unlock()
+			})
+		}
+	}
+
+	wg.Wait()
+	assert.Len(t, runtime.workers.queues, 4)
+	for _, device := range devices {
+		require.Len(t, received[device], messageCount)
+		for index, sequence := range received[device] {
+			require.Equal(t, index, sequence, fmt.Sprintf("%s messages out of order", device))
+		}
+	}
+}
+
+func TestDispatch_NotStarted(t *testing.T) {
+	runtime := GolangRuntime{MaxWorkers: 1}
+
+	// Without the pool each message is processed in its own goroutine, so neither blocks the other
+	release := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for index := 0; index < 3; index++ {
+		wg.Add(1)
+		require.True(t, runtime.Dispatch(types.MessageEnvelope{}, func() {
+			defer wg.Done()
+			<-release
+		}))
+	}
+
+	close(release)
+	wg.Wait()
+	assert.Nil(t, runtime.workers.queues, "Dispatch must not start the workers")
+}
+
+func TestDispatch_StopsWithContext(t *testing.T) {
+	runtime := GolangRuntime{MaxWorkers: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	appWg := &sync.WaitGroup{}
+	runtime.StartWorkers(appWg, ctx)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	require.True(t, runtime.Dispatch(types.MessageEnvelope{}, func() {
+		close(started)
+		<-release
+	}))
+	<-started
+
+	// Fill the queue so the next Dispatch blocks until the context is cancelled
+	require.True(t, runtime.Dispatch(types.MessageEnvelope{}, func() {}))
+
+	dispatched := make(chan bool)
+	go func() {
+		dispatched <- runtime.Dispatch(types.MessageEnvelope{}, func() {})
+	}()
+
+	cancel()
+	select {
+	case result := <-dispatched:
+		assert.False(t, result)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "Dispatch still blocked after the context was cancelled")
+	}
+
+	// The worker stops once the message it is processing completes
+	close(release)
+	stopped := make(chan struct{})
+	go func() {
+		appWg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "workers not stopped")
+	}
+}
+
+func TestDispatch_Priority(t *testing.T) {
+	runtime := GolangRuntime{
+		MaxWorkers:         1,
+		HighPriorityTopics: []string{"edgex/events/device/alarms/#"},
+		LowPriorityTopics:  []string{"edgex/events/device/bulk"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runtime.StartWorkers(&sync.WaitGroup{}, ctx)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	runtime.Dispatch(types.MessageEnvelope{}, func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	lock := sync.Mutex{}
+	var processed []string
+	wg := sync.WaitGroup{}
+	for _, topic := range []string{"edgex/events/device/bulk", "edgex/events/device/readings", "edgex/events/device/alarms/fire"} {
+		topic := topic
+		wg.Add(1)
+		runtime.Dispatch(types.MessageEnvelope{ReceivedTopic: topic}, func() {
+			defer wg.Done()
+			lock.Lock()
+			processed = append(processed, topic)
+			lock.Unlock()
+		})
+	}
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []string{"edgex/events/device/alarms/fire", "edgex/events/device/readings", "edgex/events/device/bulk"}, processed)
+}
+
+func TestMatchesAnyTopic(t *testing.T) {
+	topics := []string{"edgex/events/device/alarms/#", "edgex.events.*", "bulk"}
+
+	tests := []struct {
+		Topic    string
+		Expected bool
+	}{
+		{"edgex/events/device/alarms/fire", true},
+		{"edgex/events/device/alarms/", true},
+		{"edgex/events/device/readings", false},
+		{"edgex.events.device", true},
+		{"bulk", true},
+		{"bulk/export", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Topic, func(t *testing.T) {
+			assert.Equal(t, test.Expected, matchesAnyTopic(topics, test.Topic))
+		})
+	}
+
+	assert.False(t, matchesAnyTopic(nil, "bulk"))
+}
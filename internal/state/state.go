@@ -0,0 +1,170 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/db/interfaces"
+)
+
+const (
+	// stateKeySuffix is added to the service key for the key the state is stored with, which keeps it apart from the
+	// data stored for Store and Forward
+	stateKeySuffix = "-state"
+	// stateVersion is the version of the stored entries' format
+	stateVersion = "1"
+)
+
+// storedEntry is the payload each key's value is stored with
+type storedEntry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type entry struct {
+	// id is the ID of the StoredObject holding the value
+	id    string
+	value []byte
+}
+
+// State is the key-value store returned by the context's State function. Values are held in memory for reading and
+// each change is written through to the Database, so the state is restored by Load when the service restarts.
+type State struct {
+	mutex         sync.Mutex
+	storeClient   interfaces.StoreClient
+	appServiceKey string
+	entries       map[string]entry
+}
+
+// NewState creates an empty State for the service, which stores its values with the store client
+func NewState(storeClient interfaces.StoreClient, serviceKey string) *State {
+	return &State{
+		storeClient:   storeClient,
+		appServiceKey: serviceKey + stateKeySuffix,
+		entries:       make(map[string]entry),
+	}
+}
+
+// Load restores the values stored by the previous runs of the service
+func (s *State) Load() error {
+	stored, err := s.storeClient.RetrieveFromStore(s.appServiceKey)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, object := range stored {
+		var saved storedEntry
+		if err := json.Unmarshal(object.Payload, &saved); err != nil {
+			return err
+		}
+		s.entries[saved.Key] = entry{id: object.ID, value: saved.Value}
+	}
+
+	return nil
+}
+
+// Get returns a copy of the value stored for the key, if present
+func (s *State) Get(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, found := s.entries[key]
+	if !found {
+		return nil, false
+	}
+
+	return append([]byte{}, existing.value...), true
+}
+
+// Set stores the value for the key in the Database, replacing any existing value
+func (s *State) Set(key string, value []byte) error {
+	if len(key) == 0 {
+		return errors.New("state key cannot be empty")
+	}
+
+	value = append([]byte{}, value...)
+	payload, err := json.Marshal(storedEntry{Key: key, Value: value})
+	if err != nil {
+		return err
+	}
+
+	// Held while writing to the Database so concurrent changes to a key are stored in the order they're applied
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	object := contracts.NewStoredObject(s.appServiceKey, payload, 0, stateVersion, nil)
+	existing, found := s.entries[key]
+	if found {
+		object.ID = existing.id
+		err = s.storeClient.Update(object)
+	} else {
+		object.ID, err = s.storeClient.Store(object)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.entries[key] = entry{id: object.ID, value: value}
+	return nil
+}
+
+// Delete removes the value stored for the key from the Database, if present
+func (s *State) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, found := s.entries[key]
+	if !found {
+		return nil
+	}
+
+	payload, err := json.Marshal(storedEntry{Key: key, Value: existing.value})
+	if err != nil {
+		return err
+	}
+
+	object := contracts.NewStoredObject(s.appServiceKey, payload, 0, stateVersion, nil)
+	object.ID = existing.id
+	if err := s.storeClient.RemoveFromStore(object); err != nil {
+		return err
+	}
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Keys returns the keys which have a value stored, in sorted order
+func (s *State) Keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,103 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/db/interfaces/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testServiceKey = "app-test"
+
+func TestState_SetGetDelete(t *testing.T) {
+	storeClient := &mocks.StoreClient{}
+	storedId := uuid.New().String()
+	storeClient.On("Store", mock.Anything).Return(func(object contracts.StoredObject) (string, error) {
+		return storedId, nil
+	}).Once()
+	storeClient.On("Update", mock.MatchedBy(func(object contracts.StoredObject) bool {
+		return object.ID == storedId && object.AppServiceKey == testServiceKey+stateKeySuffix
+	})).Return(nil).Once()
+	storeClient.On("RemoveFromStore", mock.MatchedBy(func(object contracts.StoredObject) bool {
+		return object.ID == storedId
+	})).Return(nil).Once()
+
+	target := NewState(storeClient, testServiceKey)
+
+	require.NoError(t, target.Set("counter", []byte("1")))
+	require.NoError(t, target.Set("counter", []byte("2")))
+
+	value, found := target.Get("counter")
+	require.True(t, found)
+	assert.Equal(t, []byte("2"), value)
+	assert.Equal(t, []string{"counter"}, target.Keys())
+
+	require.NoError(t, target.Delete("counter"))
+	_, found = target.Get("counter")
+	assert.False(t, found)
+	assert.Empty(t, target.Keys())
+
+	// Deleting a key which isn't present doesn't touch the Database
+	require.NoError(t, target.Delete("counter"))
+	storeClient.AssertExpectations(t)
+}
+
+func TestState_SetFailure(t *testing.T) {
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("Store", mock.Anything).Return(errors.New("unavailable"))
+
+	target := NewState(storeClient, testServiceKey)
+
+	require.Error(t, target.Set("last", []byte("value")))
+	_, found := target.Get("last")
+	assert.False(t, found, "value not persisted should not be kept")
+
+	assert.Error(t, target.Set("", []byte("value")))
+}
+
+func TestState_Load(t *testing.T) {
+	payload, err := json.Marshal(storedEntry{Key: "last", Value: []byte("value")})
+	require.NoError(t, err)
+	saved := contracts.NewStoredObject(testServiceKey+stateKeySuffix, payload, 0, stateVersion, nil)
+	saved.ID = uuid.New().String()
+
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("RetrieveFromStore", testServiceKey+stateKeySuffix).Return([]contracts.StoredObject{saved}, nil)
+	storeClient.On("Update", mock.MatchedBy(func(object contracts.StoredObject) bool {
+		return object.ID == saved.ID
+	})).Return(nil)
+
+	target := NewState(storeClient, testServiceKey)
+	require.NoError(t, target.Load())
+
+	value, found := target.Get("last")
+	require.True(t, found)
+	assert.Equal(t, []byte("value"), value)
+
+	// Restored values are updated in place rather than stored again
+	require.NoError(t, target.Set("last", []byte("new")))
+	storeClient.AssertExpectations(t)
+}
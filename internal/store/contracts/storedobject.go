@@ -17,6 +17,7 @@ package contracts
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -46,6 +47,23 @@ type StoredObject struct {
 
 	// ContextData is a snapshot of data used by the pipeline at runtime
 	ContextData map[string]string
+
+	// Created is when the data was first stored, in nanoseconds since the epoch. Used to retry in arrival order.
+	Created int64
+
+	// Encrypted indicates the Payload has been encrypted with the Store and Forward encryption key
+	Encrypted bool
+
+	// NextRetryTime is when the data is next due to be retried, in nanoseconds since the epoch.
+	// Zero means the data is retried at the next retry interval.
+	NextRetryTime int64
+
+	// Input is the data originally received by the pipeline, used to replay the data through the whole pipeline.
+	// Encrypted along with the Payload.
+	Input []byte
+
+	// InputContentType is the content type of the Input
+	InputContentType string
 }
 
 // NewStoredObject creates a new instance of StoredObject and is the preferred way to create one.
@@ -58,6 +76,7 @@ func NewStoredObject(appServiceKey string, payload []byte, pipelinePosition int,
 		PipelinePosition: pipelinePosition,
 		Version:          version,
 		ContextData:      contextData,
+		Created:          time.Now().UnixNano(),
 	}
 }
 
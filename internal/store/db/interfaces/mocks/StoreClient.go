@@ -62,6 +62,29 @@ func (_m *StoreClient) RetrieveFromStore(appServiceKey string) ([]contracts.Stor
 	return r0, r1
 }
 
+// RetrieveOldestFromStore provides a mock function with given fields: appServiceKey, count
+func (_m *StoreClient) RetrieveOldestFromStore(appServiceKey string, count int) ([]contracts.StoredObject, error) {
+	ret := _m.Called(appServiceKey, count)
+
+	var r0 []contracts.StoredObject
+	if rf, ok := ret.Get(0).(func(string, int) []contracts.StoredObject); ok {
+		r0 = rf(appServiceKey, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]contracts.StoredObject)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(appServiceKey, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Store provides a mock function with given fields: o
 func (_m *StoreClient) Store(o contracts.StoredObject) (string, error) {
 	ret := _m.Called(o)
@@ -27,6 +27,9 @@ type StoreClient interface {
 	// RetrieveFromStore gets an object from the data store.
 	RetrieveFromStore(appServiceKey string) (objects []contracts.StoredObject, err error)
 
+	// RetrieveOldestFromStore gets up to count of the oldest objects, in the order they were created, from the data store.
+	RetrieveOldestFromStore(appServiceKey string, count int) (objects []contracts.StoredObject, err error)
+
 	// Update replaces the data currently in the store with the provided data.
 	Update(o contracts.StoredObject) error
 
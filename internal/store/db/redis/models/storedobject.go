@@ -46,6 +46,21 @@ type StoredObject struct {
 
 	// ContextData is a snapshot of data used by the pipeline at runtime
 	ContextData map[string]string
+
+	// Created is when the data was first stored, in nanoseconds since the epoch
+	Created int64 `json:"created"`
+
+	// Encrypted indicates the Payload has been encrypted with the Store and Forward encryption key
+	Encrypted bool `json:"encrypted"`
+
+	// NextRetryTime is when the data is next due to be retried, in nanoseconds since the epoch
+	NextRetryTime int64 `json:"nextRetryTime"`
+
+	// Input is the data originally received by the pipeline
+	Input []byte `json:"input"`
+
+	// InputContentType is the content type of the Input
+	InputContentType string `json:"inputContentType"`
 }
 
 // ToContract builds a contract out of the supplied model.
@@ -59,6 +74,11 @@ func (o StoredObject) ToContract() contracts.StoredObject {
 		Version:          o.Version,
 		CorrelationID:    o.CorrelationID,
 		ContextData:      o.ContextData,
+		Created:          o.Created,
+		Encrypted:        o.Encrypted,
+		NextRetryTime:    o.NextRetryTime,
+		Input:            o.Input,
+		InputContentType: o.InputContentType,
 	}
 }
 
@@ -72,6 +92,11 @@ func (o *StoredObject) FromContract(c contracts.StoredObject) {
 	o.Version = c.Version
 	o.CorrelationID = c.CorrelationID
 	o.ContextData = c.ContextData
+	o.Created = c.Created
+	o.Encrypted = c.Encrypted
+	o.NextRetryTime = c.NextRetryTime
+	o.Input = c.Input
+	o.InputContentType = c.InputContentType
 }
 
 // MarshalJSON returns the object as a JSON encoded byte array.
@@ -87,11 +112,20 @@ func (o StoredObject) MarshalJSON() ([]byte, error) {
 		EventID          *string           `json:"eventID,omitempty"`
 		EventChecksum    *string           `json:"eventChecksum,omitempty"`
 		ContextData      map[string]string `json:"contextData,omitempty"`
+		Created          int64             `json:"created,omitempty"`
+		Encrypted        bool              `json:"encrypted,omitempty"`
+		NextRetryTime    int64             `json:"nextRetryTime,omitempty"`
+		Input            []byte            `json:"input,omitempty"`
+		InputContentType *string           `json:"inputContentType,omitempty"`
 	}{
 		Payload:          o.Payload,
 		RetryCount:       o.RetryCount,
 		PipelinePosition: o.PipelinePosition,
 		ContextData:      o.ContextData,
+		Created:          o.Created,
+		Encrypted:        o.Encrypted,
+		NextRetryTime:    o.NextRetryTime,
+		Input:            o.Input,
 	}
 
 	// Empty strings are null
@@ -107,6 +141,9 @@ func (o StoredObject) MarshalJSON() ([]byte, error) {
 	if o.CorrelationID != "" {
 		test.CorrelationID = &o.CorrelationID
 	}
+	if o.InputContentType != "" {
+		test.InputContentType = &o.InputContentType
+	}
 
 	return json.Marshal(test)
 }
@@ -124,6 +161,11 @@ func (o *StoredObject) UnmarshalJSON(data []byte) error {
 		EventID          *string           `json:"eventID"`
 		EventChecksum    *string           `json:"eventChecksum"`
 		ContextData      map[string]string `json:"contextData,omitempty"`
+		Created          int64             `json:"created"`
+		Encrypted        bool              `json:"encrypted"`
+		NextRetryTime    int64             `json:"nextRetryTime"`
+		Input            []byte            `json:"input"`
+		InputContentType *string           `json:"inputContentType"`
 	})
 
 	// Error with unmarshaling
@@ -144,11 +186,18 @@ func (o *StoredObject) UnmarshalJSON(data []byte) error {
 	if alias.CorrelationID != nil {
 		o.CorrelationID = *alias.CorrelationID
 	}
+	if alias.InputContentType != nil {
+		o.InputContentType = *alias.InputContentType
+	}
 
 	o.Payload = alias.Payload
 	o.RetryCount = alias.RetryCount
 	o.PipelinePosition = alias.PipelinePosition
 	o.ContextData = alias.ContextData
+	o.Created = alias.Created
+	o.Encrypted = alias.Encrypted
+	o.NextRetryTime = alias.NextRetryTime
+	o.Input = alias.Input
 
 	return nil
 }
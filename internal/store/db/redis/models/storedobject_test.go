@@ -25,6 +25,7 @@ import (
 var TestUUIDValid = "fb49a277-9edf-4489-a89c-235b365107f7"
 var TestPayload = []byte("brandon wrote this")
 var TestContextData = map[string]string{"test": "data"}
+var TestInput = []byte("original input")
 
 const (
 	TestAppServiceKey    = "apps"
@@ -32,6 +33,9 @@ const (
 	TestPipelinePosition = 1337
 	TestVersion          = "your"
 	TestCorrelationID    = "test"
+	TestCreated          = 1625000000000000000
+	TestNextRetryTime    = 1625000060000000000
+	TestInputContentType = "application/json"
 )
 
 var TestContractValid = contracts.StoredObject{
@@ -43,6 +47,10 @@ var TestContractValid = contracts.StoredObject{
 	Version:          TestVersion,
 	CorrelationID:    TestCorrelationID,
 	ContextData:      TestContextData,
+	Created:          TestCreated,
+	NextRetryTime:    TestNextRetryTime,
+	Input:            TestInput,
+	InputContentType: TestInputContentType,
 }
 
 var TestModelValid = StoredObject{
@@ -54,6 +62,10 @@ var TestModelValid = StoredObject{
 	Version:          TestVersion,
 	CorrelationID:    TestCorrelationID,
 	ContextData:      TestContextData,
+	Created:          TestCreated,
+	NextRetryTime:    TestNextRetryTime,
+	Input:            TestInput,
+	InputContentType: TestInputContentType,
 }
 
 var TestModelEmpty = StoredObject{}
@@ -118,7 +130,7 @@ func TestStoredObject_MarshalJSON(t *testing.T) {
 			"Successful marshalling",
 			TestModelValid,
 			false,
-			`{"id":"fb49a277-9edf-4489-a89c-235b365107f7","appServiceKey":"apps","payload":"YnJhbmRvbiB3cm90ZSB0aGlz","retryCount":2,"pipelinePosition":1337,"version":"your","correlationID":"test","contextData":{"test":"data"}}`,
+			`{"id":"fb49a277-9edf-4489-a89c-235b365107f7","appServiceKey":"apps","payload":"YnJhbmRvbiB3cm90ZSB0aGlz","retryCount":2,"pipelinePosition":1337,"version":"your","correlationID":"test","contextData":{"test":"data"},"created":1625000000000000000,"nextRetryTime":1625000060000000000,"input":"b3JpZ2luYWwgaW5wdXQ=","inputContentType":"application/json"}`,
 		},
 		{
 			"Successful, empty",
@@ -155,7 +167,7 @@ func TestStoredObject_UnmarshalJSON(t *testing.T) {
 		{
 			"Valid",
 			TestModelValid,
-			args{[]byte(`{"id":"fb49a277-9edf-4489-a89c-235b365107f7","appServiceKey":"apps","payload":[98,114,97,110,100,111,110,32,119,114,111,116,101,32,116,104,105,115],"retryCount":2,"pipelinePosition":1337,"version":"your","correlationID":"test","eventID":"probably","eventChecksum":"failed :(","contextData":{"test":"data"}}`)},
+			args{[]byte(`{"id":"fb49a277-9edf-4489-a89c-235b365107f7","appServiceKey":"apps","payload":[98,114,97,110,100,111,110,32,119,114,111,116,101,32,116,104,105,115],"retryCount":2,"pipelinePosition":1337,"version":"your","correlationID":"test","eventID":"probably","eventChecksum":"failed :(","contextData":{"test":"data"},"created":1625000000000000000,"nextRetryTime":1625000060000000000,"input":"b3JpZ2luYWwgaW5wdXQ=","inputContentType":"application/json"}`)},
 			false,
 		},
 		{
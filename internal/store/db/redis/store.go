@@ -49,6 +49,8 @@ type Client struct {
 // * the object AppServiceKey to point to a SET containing all object ids associated with this
 //   app service. Note the key is prefixed to avoid key collisions.
 // * the object id to point to a HASH which contains the object AppServiceKey.
+// The object id is also added to a SORTED SET for the AppServiceKey, scored by when the object was created,
+// so the oldest objects can be retrieved without loading them all.
 func (c Client) Store(o contracts.StoredObject) (string, error) {
 	err := o.ValidateContract(false)
 	if err != nil {
@@ -77,6 +79,7 @@ func (c Client) Store(o contracts.StoredObject) (string, error) {
 	_ = conn.Send("SET", model.ID, json)
 	_ = conn.Send("SADD", nameSpace+":idl:"+model.AppServiceKey, model.ID)
 	_ = conn.Send("HSET", nameSpace+":ask:"+model.ID, "ASK", model.AppServiceKey)
+	_ = conn.Send("ZADD", nameSpace+":created:"+model.AppServiceKey, model.Created, model.ID)
 	_, err = conn.Do("EXEC")
 	if err != nil {
 		return "", err
@@ -108,6 +111,37 @@ func (c Client) RetrieveFromStore(appServiceKey string) (objects []contracts.Sto
 		return nil, nil
 	}
 
+	return retrieveObjects(conn, ids)
+}
+
+// RetrieveOldestFromStore gets up to count of the oldest objects, in the order they were created, from the data store.
+func (c Client) RetrieveOldestFromStore(appServiceKey string, count int) (objects []contracts.StoredObject, err error) {
+	// do not satisfy requests for a blank ASK
+	if appServiceKey == "" {
+		return nil, errors.New("no AppServiceKey provided")
+	}
+
+	if count <= 0 {
+		return nil, nil
+	}
+
+	conn := c.Pool.Get()
+	defer func() { _ = conn.Close() }()
+
+	ids, err := redis.Values(conn.Do("ZRANGE", nameSpace+":created:"+appServiceKey, 0, count-1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return retrieveObjects(conn, ids)
+}
+
+// retrieveObjects gets the objects with the ids from the data store
+func retrieveObjects(conn redis.Conn, ids []interface{}) (objects []contracts.StoredObject, err error) {
 	values, err := redis.ByteSlices(conn.Do("MGET", ids...))
 	if err != nil {
 		return nil, err
@@ -149,6 +183,8 @@ func (c Client) Update(o contracts.StoredObject) error {
 		_ = conn.Send("SREM", nameSpace+":idl:"+currentASK, o.ID)
 		_ = conn.Send("SADD", nameSpace+":idl:"+o.AppServiceKey, o.ID)
 		_ = conn.Send("HSET", nameSpace+":ask:"+o.ID, "ASK", o.AppServiceKey)
+		_ = conn.Send("ZREM", nameSpace+":created:"+currentASK, o.ID)
+		_ = conn.Send("ZADD", nameSpace+":created:"+o.AppServiceKey, o.Created, o.ID)
 	}
 
 	var update models.StoredObject
@@ -184,6 +220,7 @@ func (c Client) RemoveFromStore(o contracts.StoredObject) error {
 	// remove the association with the ASK
 	_ = conn.Send("SREM", nameSpace+":idl:"+o.AppServiceKey, o.ID)
 	_ = conn.Send("UNLINK", nameSpace+":ask:"+o.ID)
+	_ = conn.Send("ZREM", nameSpace+":created:"+o.AppServiceKey, o.ID)
 
 	res, err := redis.Values(conn.Do("EXEC"))
 	if err != nil {
@@ -0,0 +1,134 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package stream
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+const (
+	// DefaultMaxClients is the most clients which can be connected when Stream.MaxClients isn't set
+	DefaultMaxClients = 10
+	// DefaultClientBufferSize is the number of messages buffered for each client when Stream.ClientBufferSize isn't set
+	DefaultClientBufferSize = 100
+)
+
+// ErrTooManyClients is returned by Register when the most clients allowed are already connected
+var ErrTooManyClients = errors.New("too many stream clients connected")
+
+// Client is a connected client of the Hub, which receives the published data from its Messages channel
+type Client struct {
+	messages chan []byte
+	dropped  uint64
+}
+
+// Messages returns the channel of data published to the client, which is closed when the client is unregistered
+func (client *Client) Messages() <-chan []byte {
+	return client.messages
+}
+
+// Dropped returns the number of messages not sent to the client as its buffer was full
+func (client *Client) Dropped() uint64 {
+	return atomic.LoadUint64(&client.dropped)
+}
+
+// Hub fans the data published by the functions pipeline out to the connected clients. It implements the
+// interfaces.Stream interface.
+type Hub struct {
+	mutex      sync.RWMutex
+	clients    map[*Client]struct{}
+	maxClients int
+	bufferSize int
+}
+
+// NewHub creates a new Hub allowing at most maxClients clients, each with a buffer of bufferSize messages
+func NewHub(maxClients int, bufferSize int) *Hub {
+	if maxClients <= 0 {
+		maxClients = DefaultMaxClients
+	}
+
+	if bufferSize <= 0 {
+		bufferSize = DefaultClientBufferSize
+	}
+
+	return &Hub{
+		clients:    make(map[*Client]struct{}),
+		maxClients: maxClients,
+		bufferSize: bufferSize,
+	}
+}
+
+// NewHubFromConfig creates a new Hub from the Stream configuration
+func NewHubFromConfig(config common.StreamInfo) *Hub {
+	return NewHub(config.MaxClients, config.ClientBufferSize)
+}
+
+// Register adds a new client, which receives the data published from now on
+func (hub *Hub) Register() (*Client, error) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if len(hub.clients) >= hub.maxClients {
+		return nil, ErrTooManyClients
+	}
+
+	client := &Client{messages: make(chan []byte, hub.bufferSize)}
+	hub.clients[client] = struct{}{}
+	return client, nil
+}
+
+// Unregister removes the client and closes its Messages channel
+func (hub *Hub) Unregister(client *Client) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if _, found := hub.clients[client]; found {
+		delete(hub.clients, client)
+		close(client.messages)
+	}
+}
+
+// Publish queues the data for every connected client, dropping it for clients whose buffer is full, and returns the
+// number of clients it was queued for
+func (hub *Hub) Publish(data []byte) int {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+
+	queued := 0
+	for client := range hub.clients {
+		select {
+		case client.messages <- data:
+			queued++
+		default:
+			atomic.AddUint64(&client.dropped, 1)
+		}
+	}
+
+	return queued
+}
+
+// Clients returns the number of connected clients
+func (hub *Hub) Clients() int {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+
+	return len(hub.clients)
+}
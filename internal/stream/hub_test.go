@@ -0,0 +1,72 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+func TestNewHubFromConfig(t *testing.T) {
+	hub := NewHubFromConfig(common.StreamInfo{})
+	assert.Equal(t, DefaultMaxClients, hub.maxClients)
+	assert.Equal(t, DefaultClientBufferSize, hub.bufferSize)
+
+	hub = NewHubFromConfig(common.StreamInfo{MaxClients: 2, ClientBufferSize: 5})
+	assert.Equal(t, 2, hub.maxClients)
+	assert.Equal(t, 5, hub.bufferSize)
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub(2, 2)
+	assert.Equal(t, 0, hub.Publish([]byte("no clients")))
+
+	client1, err := hub.Register()
+	require.NoError(t, err)
+	client2, err := hub.Register()
+	require.NoError(t, err)
+	_, err = hub.Register()
+	require.ErrorIs(t, err, ErrTooManyClients)
+	assert.Equal(t, 2, hub.Clients())
+
+	assert.Equal(t, 2, hub.Publish([]byte("1")))
+	assert.Equal(t, 2, hub.Publish([]byte("2")))
+	assert.Equal(t, "1", string(<-client1.Messages()))
+
+	// client2's buffer is full so it misses the data
+	assert.Equal(t, 1, hub.Publish([]byte("3")))
+	assert.Equal(t, uint64(0), client1.Dropped())
+	assert.Equal(t, uint64(1), client2.Dropped())
+
+	hub.Unregister(client2)
+	hub.Unregister(client2)
+	assert.Equal(t, 1, hub.Clients())
+
+	var received []string
+	for data := range client2.Messages() {
+		received = append(received, string(data))
+	}
+	assert.Equal(t, []string{"1", "2"}, received)
+
+	// A client can register once another has unregistered
+	_, err = hub.Register()
+	require.NoError(t, err)
+}
@@ -0,0 +1,74 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package stream
+
+import (
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+// PipelineHubs has a Hub for each functions pipeline, so clients only receive the data of the pipeline they
+// subscribed to. Hubs are created when the first client of the pipeline registers.
+type PipelineHubs struct {
+	mutex      sync.RWMutex
+	hubs       map[string]*Hub
+	maxClients int
+	bufferSize int
+}
+
+// NewPipelineHubs creates a new PipelineHubs whose Hubs each allow at most maxClients clients, each with a buffer of
+// bufferSize messages
+func NewPipelineHubs(maxClients int, bufferSize int) *PipelineHubs {
+	return &PipelineHubs{
+		hubs:       make(map[string]*Hub),
+		maxClients: maxClients,
+		bufferSize: bufferSize,
+	}
+}
+
+// NewPipelineHubsFromConfig creates a new PipelineHubs from the ServerSentEvents configuration
+func NewPipelineHubsFromConfig(config common.ServerSentEventsInfo) *PipelineHubs {
+	return NewPipelineHubs(config.MaxClients, config.ClientBufferSize)
+}
+
+// Hub returns the Hub of the pipeline, creating it if needed
+func (pipelineHubs *PipelineHubs) Hub(pipelineId string) *Hub {
+	pipelineHubs.mutex.Lock()
+	defer pipelineHubs.mutex.Unlock()
+
+	hub, found := pipelineHubs.hubs[pipelineId]
+	if !found {
+		hub = NewHub(pipelineHubs.maxClients, pipelineHubs.bufferSize)
+		pipelineHubs.hubs[pipelineId] = hub
+	}
+
+	return hub
+}
+
+// Publish queues the data for the clients of the pipeline and returns the number of clients it was queued for
+func (pipelineHubs *PipelineHubs) Publish(pipelineId string, data []byte) int {
+	pipelineHubs.mutex.RLock()
+	hub, found := pipelineHubs.hubs[pipelineId]
+	pipelineHubs.mutex.RUnlock()
+
+	if !found {
+		return 0
+	}
+
+	return hub.Publish(data)
+}
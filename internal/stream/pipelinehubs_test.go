@@ -0,0 +1,50 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+func TestNewPipelineHubsFromConfig(t *testing.T) {
+	hubs := NewPipelineHubsFromConfig(common.ServerSentEventsInfo{MaxClients: 2, ClientBufferSize: 5})
+	hub := hubs.Hub("default-pipeline")
+	assert.Equal(t, 2, hub.maxClients)
+	assert.Equal(t, 5, hub.bufferSize)
+}
+
+func TestPipelineHubs(t *testing.T) {
+	hubs := NewPipelineHubs(1, 1)
+	assert.Equal(t, 0, hubs.Publish("pipeline1", []byte("no hub")))
+
+	hub := hubs.Hub("pipeline1")
+	assert.Same(t, hub, hubs.Hub("pipeline1"))
+	assert.NotSame(t, hub, hubs.Hub("pipeline2"))
+
+	client, err := hub.Register()
+	require.NoError(t, err)
+	defer hub.Unregister(client)
+
+	assert.Equal(t, 0, hubs.Publish("pipeline2", []byte("other pipeline")))
+	assert.Equal(t, 1, hubs.Publish("pipeline1", []byte("data")))
+	assert.Equal(t, []byte("data"), <-client.Messages())
+}
@@ -0,0 +1,288 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+)
+
+const (
+	// deviceStatsBucketCount is the number of buckets the rolling rate window is divided into
+	deviceStatsBucketCount = 60
+	// defaultDeviceRateWindow is the rolling window rates are measured over when RateWindow isn't set
+	defaultDeviceRateWindow = time.Minute
+	// minDeviceRateCheckInterval is the shortest interval the device rates are checked against their limits
+	minDeviceRateCheckInterval = time.Second
+)
+
+const (
+	// DeviceRateBelowMin is the alert for a device whose message rate is below its minimum rate
+	DeviceRateBelowMin = "BelowMinRate"
+	// DeviceRateAboveMax is the alert for a device whose message rate is above its maximum rate
+	DeviceRateAboveMax = "AboveMaxRate"
+)
+
+type deviceStatsBucket struct {
+	index int64
+	count uint64
+}
+
+type deviceStats struct {
+	count uint64
+	// since is when the device was first seen or the rate window last changed, whichever is later
+	since    time.Time
+	lastSeen time.Time
+	buckets  [deviceStatsBucketCount]deviceStatsBucket
+	// alert is the alert last logged for the device
+	alert string
+}
+
+// DeviceStatsTracker tracks the count, rate and last-seen time of the Events received from each device
+type DeviceStatsTracker struct {
+	mutex      sync.Mutex
+	bucketSize time.Duration
+	devices    map[string]*deviceStats
+	now        func() time.Time
+}
+
+// DeviceStatsReport describes the Events received from each device
+// swagger:model
+type DeviceStatsReport struct {
+	Enabled    bool                   `json:"enabled"`
+	RateWindow string                 `json:"rateWindow"`
+	Devices    map[string]DeviceStats `json:"devices"`
+}
+
+// DeviceStats describes the Events received from a device
+// swagger:model
+type DeviceStats struct {
+	MessageCount uint64 `json:"messageCount"`
+	// LastSeen is when the last Event was received from the device, in nanoseconds since the epoch
+	LastSeen int64 `json:"lastSeen"`
+	// Rate is the number of messages per second received from the device over the rate window
+	Rate    float64 `json:"rate"`
+	MinRate float64 `json:"minRate"`
+	MaxRate float64 `json:"maxRate"`
+	// Alert is DeviceRateBelowMin or DeviceRateAboveMax when the rate is outside its limits
+	Alert string `json:"alert,omitempty"`
+}
+
+// NewDeviceStatsTracker creates a new DeviceStatsTracker
+func NewDeviceStatsTracker() *DeviceStatsTracker {
+	return &DeviceStatsTracker{
+		devices: make(map[string]*deviceStats),
+		now:     time.Now,
+	}
+}
+
+// parseDeviceRateWindow returns the rolling rate window, defaulting it when not set or invalid
+func parseDeviceRateWindow(config common.DeviceStatisticsInfo) time.Duration {
+	window, err := time.ParseDuration(config.RateWindow)
+	if err != nil || window <= 0 {
+		return defaultDeviceRateWindow
+	}
+
+	return window
+}
+
+// deviceRateLimits returns the minimum and maximum rates for the device
+func deviceRateLimits(config common.DeviceStatisticsInfo, deviceName string) (float64, float64) {
+	if limits, ok := config.Devices[deviceName]; ok {
+		return limits.MinRate, limits.MaxRate
+	}
+
+	return config.MinRate, config.MaxRate
+}
+
+// currentIndex resets the buckets if the window size has changed and returns the index of the current bucket.
+// Must be called with the mutex held.
+func (tracker *DeviceStatsTracker) currentIndex(window time.Duration, now time.Time) int64 {
+	bucketSize := window / deviceStatsBucketCount
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+
+	if bucketSize != tracker.bucketSize {
+		tracker.bucketSize = bucketSize
+		for _, stats := range tracker.devices {
+			stats.buckets = [deviceStatsBucketCount]deviceStatsBucket{}
+			stats.since = now
+		}
+	}
+
+	return now.UnixNano() / int64(bucketSize)
+}
+
+// Record records an Event received from the specified device
+func (tracker *DeviceStatsTracker) Record(config common.DeviceStatisticsInfo, deviceName string) {
+	if !config.Enabled {
+		return
+	}
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	now := tracker.now()
+	index := tracker.currentIndex(parseDeviceRateWindow(config), now)
+
+	stats, ok := tracker.devices[deviceName]
+	if !ok {
+		stats = &deviceStats{since: now}
+		tracker.devices[deviceName] = stats
+	}
+
+	stats.count++
+	stats.lastSeen = now
+
+	bucket := &stats.buckets[index%deviceStatsBucketCount]
+	if bucket.index != index {
+		*bucket = deviceStatsBucket{index: index}
+	}
+	bucket.count++
+}
+
+// Report returns the statistics of the Events received from each device
+func (tracker *DeviceStatsTracker) Report(config common.DeviceStatisticsInfo) DeviceStatsReport {
+	report := DeviceStatsReport{
+		Enabled:    config.Enabled,
+		RateWindow: config.RateWindow,
+		Devices:    make(map[string]DeviceStats),
+	}
+
+	if !config.Enabled {
+		return report
+	}
+
+	window := parseDeviceRateWindow(config)
+	report.RateWindow = window.String()
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	now := tracker.now()
+	index := tracker.currentIndex(window, now)
+
+	for deviceName, stats := range tracker.devices {
+		report.Devices[deviceName] = tracker.deviceReport(config, deviceName, stats, window, index, now)
+	}
+
+	return report
+}
+
+// deviceReport returns the statistics for the device. Must be called with the mutex held.
+func (tracker *DeviceStatsTracker) deviceReport(
+	config common.DeviceStatisticsInfo,
+	deviceName string,
+	stats *deviceStats,
+	window time.Duration,
+	index int64,
+	now time.Time) DeviceStats {
+	var count uint64
+	for _, bucket := range stats.buckets {
+		// Only the buckets within the window are included
+		if bucket.count > 0 && bucket.index > index-deviceStatsBucketCount {
+			count += bucket.count
+		}
+	}
+
+	report := DeviceStats{
+		MessageCount: stats.count,
+		LastSeen:     stats.lastSeen.UnixNano(),
+		Rate:         float64(count) / window.Seconds(),
+	}
+	report.MinRate, report.MaxRate = deviceRateLimits(config, deviceName)
+
+	switch {
+	case report.MaxRate > 0 && report.Rate > report.MaxRate:
+		report.Alert = DeviceRateAboveMax
+	// The rate isn't known to be low until the device has been tracked for a full window
+	case report.MinRate > 0 && report.Rate < report.MinRate && now.Sub(stats.since) >= window:
+		report.Alert = DeviceRateBelowMin
+	}
+
+	return report
+}
+
+// Check checks the rate of each device against its limits, logging a warning when a device's rate goes outside
+// its limits and when it returns within them
+func (tracker *DeviceStatsTracker) Check(config common.DeviceStatisticsInfo, lc logger.LoggingClient) {
+	if !config.Enabled {
+		return
+	}
+
+	window := parseDeviceRateWindow(config)
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	now := tracker.now()
+	index := tracker.currentIndex(window, now)
+
+	for deviceName, stats := range tracker.devices {
+		report := tracker.deviceReport(config, deviceName, stats, window, index, now)
+		if report.Alert == stats.alert {
+			continue
+		}
+
+		switch report.Alert {
+		case DeviceRateBelowMin:
+			lc.Warnf("Device '%s' message rate of %g/s over the last %s is below the minimum of %g/s",
+				deviceName, report.Rate, window, report.MinRate)
+		case DeviceRateAboveMax:
+			lc.Warnf("Device '%s' message rate of %g/s over the last %s is above the maximum of %g/s",
+				deviceName, report.Rate, window, report.MaxRate)
+		default:
+			lc.Infof("Device '%s' message rate of %g/s is back within its limits", deviceName, report.Rate)
+		}
+
+		stats.alert = report.Alert
+	}
+}
+
+// StartMonitor periodically checks the rate of each device against its limits until the context is cancelled
+func (tracker *DeviceStatsTracker) StartMonitor(
+	appWg *sync.WaitGroup,
+	appCtx context.Context,
+	lc logger.LoggingClient,
+	config *common.ConfigurationStruct) {
+	defer appWg.Done()
+
+	lc.Info("Starting Device Statistics monitor loop")
+
+	for {
+		// The configuration is writable so the interval is recalculated each time
+		interval := parseDeviceRateWindow(config.Writable.DeviceStatistics) / 12
+		if interval < minDeviceRateCheckInterval {
+			interval = minDeviceRateCheckInterval
+		}
+
+		select {
+		case <-appCtx.Done():
+			lc.Info("Exiting Device Statistics monitor loop")
+			return
+
+		case <-time.After(interval):
+			tracker.Check(config.Writable.DeviceStatistics, lc)
+		}
+	}
+}
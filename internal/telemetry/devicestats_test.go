@@ -0,0 +1,157 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceStatsTracker(t *testing.T) {
+	config := common.DeviceStatisticsInfo{
+		Enabled:    true,
+		RateWindow: "1m",
+		MinRate:    0.1,
+		MaxRate:    1,
+		Devices: map[string]common.DeviceRateInfo{
+			"fast-device": {MaxRate: 5},
+		},
+	}
+
+	now := time.Date(2021, 7, 4, 10, 0, 0, 0, time.UTC)
+	tracker := NewDeviceStatsTracker()
+	tracker.now = func() time.Time { return now }
+
+	report := tracker.Report(config)
+	assert.True(t, report.Enabled)
+	assert.Equal(t, "1m0s", report.RateWindow)
+	assert.Empty(t, report.Devices)
+
+	for i := 0; i < 30; i++ {
+		tracker.Record(config, "device1")
+	}
+	for i := 0; i < 120; i++ {
+		tracker.Record(config, "fast-device")
+	}
+
+	report = tracker.Report(config)
+	require.Len(t, report.Devices, 2)
+
+	device1 := report.Devices["device1"]
+	assert.Equal(t, uint64(30), device1.MessageCount)
+	assert.Equal(t, now.UnixNano(), device1.LastSeen)
+	assert.Equal(t, 0.5, device1.Rate)
+	assert.Equal(t, 0.1, device1.MinRate)
+	assert.Equal(t, float64(1), device1.MaxRate)
+	assert.Empty(t, device1.Alert)
+
+	fastDevice := report.Devices["fast-device"]
+	assert.Equal(t, float64(2), fastDevice.Rate)
+	assert.Equal(t, float64(0), fastDevice.MinRate, "device specific rates override the defaults")
+	assert.Equal(t, float64(5), fastDevice.MaxRate)
+	assert.Empty(t, fastDevice.Alert)
+
+	// Too many messages from device1
+	for i := 0; i < 60; i++ {
+		tracker.Record(config, "device1")
+	}
+	report = tracker.Report(config)
+	assert.Equal(t, 1.5, report.Devices["device1"].Rate)
+	assert.Equal(t, DeviceRateAboveMax, report.Devices["device1"].Alert)
+
+	// Messages roll out of the window and device1 stops sending
+	now = now.Add(2 * time.Minute)
+	report = tracker.Report(config)
+	assert.Equal(t, float64(0), report.Devices["device1"].Rate)
+	assert.Equal(t, uint64(90), report.Devices["device1"].MessageCount)
+	assert.Equal(t, DeviceRateBelowMin, report.Devices["device1"].Alert)
+	assert.Empty(t, report.Devices["fast-device"].Alert, "no minimum rate for fast-device")
+}
+
+func TestDeviceStatsTrackerMinRateWarmUp(t *testing.T) {
+	config := common.DeviceStatisticsInfo{Enabled: true, RateWindow: "1m", MinRate: 1}
+
+	now := time.Date(2021, 7, 4, 10, 0, 0, 0, time.UTC)
+	tracker := NewDeviceStatsTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Record(config, "device1")
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, tracker.Report(config).Devices["device1"].Alert, "rate not known to be low until tracked for a full window")
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, DeviceRateBelowMin, tracker.Report(config).Devices["device1"].Alert)
+
+	// Changing the window restarts the rate measurement
+	config.RateWindow = "2m"
+	assert.Empty(t, tracker.Report(config).Devices["device1"].Alert)
+}
+
+func TestDeviceStatsTrackerDisabled(t *testing.T) {
+	config := common.DeviceStatisticsInfo{RateWindow: "1m"}
+	tracker := NewDeviceStatsTracker()
+
+	tracker.Record(config, "device1")
+
+	report := tracker.Report(config)
+	assert.False(t, report.Enabled)
+	assert.Empty(t, report.Devices)
+
+	config.Enabled = true
+	assert.Empty(t, tracker.Report(config).Devices, "nothing recorded while disabled")
+}
+
+func TestDeviceStatsTrackerCheck(t *testing.T) {
+	config := common.DeviceStatisticsInfo{Enabled: true, RateWindow: "1m", MaxRate: 1}
+
+	now := time.Date(2021, 7, 4, 10, 0, 0, 0, time.UTC)
+	tracker := NewDeviceStatsTracker()
+	tracker.now = func() time.Time { return now }
+	lc := logger.NewMockClient()
+
+	for i := 0; i < 120; i++ {
+		tracker.Record(config, "device1")
+	}
+
+	tracker.Check(config, lc)
+	assert.Equal(t, DeviceRateAboveMax, tracker.devices["device1"].alert)
+
+	now = now.Add(2 * time.Minute)
+	tracker.Check(config, lc)
+	assert.Empty(t, tracker.devices["device1"].alert)
+}
+
+func TestDeviceStatsTrackerStartMonitor(t *testing.T) {
+	config := &common.ConfigurationStruct{}
+	tracker := NewDeviceStatsTracker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go tracker.StartMonitor(wg, ctx, logger.NewMockClient(), config)
+
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,124 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+// defaultExecutionHistoryTopic is the topic the sampled execution records are published to when PublishTopic isn't set
+const defaultExecutionHistoryTopic = "edgex/telemetry/pipeline-executions"
+
+// ExecutionRecord describes one execution of a functions pipeline, published so a monitoring service can aggregate
+// the behavior of the pipelines across services
+// swagger:model
+type ExecutionRecord struct {
+	ServiceKey    string `json:"serviceKey"`
+	PipelineId    string `json:"pipelineId"`
+	CorrelationId string `json:"correlationId"`
+	// Timestamp is when the execution finished, in nanoseconds since the epoch
+	Timestamp int64  `json:"timestamp"`
+	Duration  string `json:"duration"`
+	// DurationNanos is the Duration in nanoseconds, for aggregating without parsing the Duration
+	DurationNanos int64  `json:"durationNanos"`
+	Outcome       string `json:"outcome"`
+	InputSize     int    `json:"inputSize"`
+	OutputSize    int    `json:"outputSize"`
+}
+
+// PublishFunc publishes the message envelope to the topic
+type PublishFunc func(envelope types.MessageEnvelope, topic string) error
+
+// ExecutionSampler publishes a record of every Nth execution of the functions pipelines to the MessageBus
+type ExecutionSampler struct {
+	serviceKey string
+	count      uint64
+	mutex      sync.RWMutex
+	publish    PublishFunc
+}
+
+// NewExecutionSampler creates a new ExecutionSampler for the service, which publishes nothing until it has a publisher
+func NewExecutionSampler(serviceKey string) *ExecutionSampler {
+	return &ExecutionSampler{serviceKey: serviceKey}
+}
+
+// SetPublisher sets the function the records are published with, i.e. by the MessageBus trigger once connected
+func (sampler *ExecutionSampler) SetPublisher(publish PublishFunc) {
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+	sampler.publish = publish
+}
+
+func (sampler *ExecutionSampler) publisher() PublishFunc {
+	sampler.mutex.RLock()
+	defer sampler.mutex.RUnlock()
+	return sampler.publish
+}
+
+// Record counts an execution of a pipeline and publishes its record when it is the SampleEvery'th one. Failures to
+// publish are only logged so they never affect the processing of messages.
+func (sampler *ExecutionSampler) Record(
+	lc logger.LoggingClient,
+	config sdkCommon.ExecutionHistoryInfo,
+	record ExecutionRecord) {
+	if config.SampleEvery <= 0 {
+		return
+	}
+
+	publish := sampler.publisher()
+	if publish == nil {
+		return
+	}
+
+	if atomic.AddUint64(&sampler.count, 1)%uint64(config.SampleEvery) != 0 {
+		return
+	}
+
+	record.ServiceKey = sampler.serviceKey
+	if record.Timestamp == 0 {
+		record.Timestamp = time.Now().UnixNano()
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		lc.Errorf("Unable to marshal pipeline execution record: %s", err.Error())
+		return
+	}
+
+	topic := config.PublishTopic
+	if len(topic) == 0 {
+		topic = defaultExecutionHistoryTopic
+	}
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: record.CorrelationId,
+		Payload:       payload,
+		ContentType:   common.ContentTypeJSON,
+	}
+	if err := publish(envelope, topic); err != nil {
+		lc.Warnf("Unable to publish pipeline execution record to '%s': %s", topic, err.Error())
+	}
+}
@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionSampler_Record(t *testing.T) {
+	lc := logger.NewMockClient()
+	config := common.ExecutionHistoryInfo{SampleEvery: 3}
+
+	var published []types.MessageEnvelope
+	var topics []string
+	sampler := NewExecutionSampler("app-test")
+
+	// Nothing is published until there is a publisher
+	sampler.Record(lc, config, ExecutionRecord{PipelineId: "default"})
+
+	sampler.SetPublisher(func(envelope types.MessageEnvelope, topic string) error {
+		published = append(published, envelope)
+		topics = append(topics, topic)
+		return nil
+	})
+
+	for i := 0; i < 7; i++ {
+		sampler.Record(lc, config, ExecutionRecord{
+			PipelineId:    "default",
+			CorrelationId: "123",
+			Outcome:       PipelineExported.String(),
+			InputSize:     i,
+		})
+	}
+
+	require.Len(t, published, 2)
+	assert.Equal(t, []string{defaultExecutionHistoryTopic, defaultExecutionHistoryTopic}, topics)
+	assert.Equal(t, "123", published[0].CorrelationID)
+
+	var record ExecutionRecord
+	require.NoError(t, json.Unmarshal(published[0].Payload, &record))
+	assert.Equal(t, "app-test", record.ServiceKey)
+	assert.Equal(t, "default", record.PipelineId)
+	assert.Equal(t, "exported", record.Outcome)
+	assert.Equal(t, 2, record.InputSize)
+	assert.NotZero(t, record.Timestamp)
+
+	config.PublishTopic = "telemetry"
+	config.SampleEvery = 1
+	sampler.Record(lc, config, ExecutionRecord{})
+	assert.Equal(t, "telemetry", topics[len(topics)-1])
+
+	// Disabled
+	config.SampleEvery = 0
+	sampler.Record(lc, config, ExecutionRecord{})
+	assert.Len(t, published, 3)
+}
+
+func TestExecutionSampler_PublishFailure(t *testing.T) {
+	sampler := NewExecutionSampler("app-test")
+	sampler.SetPublisher(func(envelope types.MessageEnvelope, topic string) error {
+		return errors.New("not connected")
+	})
+
+	// Failures are only logged
+	sampler.Record(logger.NewMockClient(), common.ExecutionHistoryInfo{SampleEvery: 1}, ExecutionRecord{})
+}
@@ -0,0 +1,129 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"encoding/base64"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+const (
+	// defaultPreviewMaxPayloadSize is the most bytes of a payload kept when MaxPayloadSize isn't set
+	defaultPreviewMaxPayloadSize = 65536
+	// previewRedacted replaces the values of context values which may be secrets
+	previewRedacted = "<redacted>"
+
+	// PayloadEncodingText indicates the payload preview is the payload as is
+	PayloadEncodingText = "text"
+	// PayloadEncodingBase64 indicates the payload preview is the base64 encoded payload, as it isn't valid UTF-8
+	PayloadEncodingBase64 = "base64"
+)
+
+// sensitiveContextKeys are the parts of context value keys whose values may be secrets, so are redacted in previews
+var sensitiveContextKeys = []string{"secret", "password", "token", "apikey", "credential"}
+
+// PayloadPreview is the serialized payload most recently passed to the last function of a functions pipeline, which
+// is the payload exported
+// swagger:model
+type PayloadPreview struct {
+	PipelineId    string `json:"pipelineId"`
+	Function      string `json:"function"`
+	CorrelationId string `json:"correlationId"`
+	// Timestamp is when the payload was passed to the function, in nanoseconds since the epoch
+	Timestamp   int64  `json:"timestamp"`
+	ContentType string `json:"contentType,omitempty"`
+	// Encoding is 'text' when the Payload is as is, otherwise 'base64' when it is base64 encoded binary data
+	Encoding string `json:"encoding"`
+	Payload  string `json:"payload"`
+	// Size is the size of the whole payload in bytes, which is larger than the Payload when Truncated
+	Size      int  `json:"size"`
+	Truncated bool `json:"truncated"`
+	// Context contains the context values, with the values which may be secrets redacted
+	Context map[string]string `json:"context,omitempty"`
+}
+
+// PayloadPreviewTracker keeps the payload most recently exported by each functions pipeline
+type PayloadPreviewTracker struct {
+	mutex    sync.Mutex
+	previews map[string]PayloadPreview
+}
+
+// NewPayloadPreviewTracker returns a new PayloadPreviewTracker
+func NewPayloadPreviewTracker() *PayloadPreviewTracker {
+	return &PayloadPreviewTracker{
+		previews: make(map[string]PayloadPreview),
+	}
+}
+
+// Record keeps the payload passed to the last function of the pipeline, replacing the pipeline's previous preview.
+// The payload is truncated to the MaxPayloadSize and context values which may be secrets are redacted.
+func (tracker *PayloadPreviewTracker) Record(config common.PayloadPreviewInfo, preview PayloadPreview, payload []byte) {
+	maxSize := config.MaxPayloadSize
+	if maxSize <= 0 {
+		maxSize = defaultPreviewMaxPayloadSize
+	}
+
+	preview.Size = len(payload)
+	if len(payload) > maxSize {
+		payload = payload[:maxSize]
+		preview.Truncated = true
+	}
+
+	if utf8.Valid(payload) {
+		preview.Encoding = PayloadEncodingText
+		preview.Payload = string(payload)
+	} else {
+		preview.Encoding = PayloadEncodingBase64
+		preview.Payload = base64.StdEncoding.EncodeToString(payload)
+	}
+
+	values := make(map[string]string, len(preview.Context))
+	for key, value := range preview.Context {
+		if isSensitiveContextKey(key) {
+			value = previewRedacted
+		}
+		values[key] = value
+	}
+	preview.Context = values
+
+	tracker.mutex.Lock()
+	tracker.previews[preview.PipelineId] = preview
+	tracker.mutex.Unlock()
+}
+
+// Preview returns the most recent payload preview of the pipeline, if any
+func (tracker *PayloadPreviewTracker) Preview(pipelineId string) (PayloadPreview, bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	preview, found := tracker.previews[pipelineId]
+	return preview, found
+}
+
+func isSensitiveContextKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveContextKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
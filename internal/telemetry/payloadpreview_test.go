@@ -0,0 +1,85 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadPreviewTracker(t *testing.T) {
+	binary := []byte{0xff, 0xfe, 0x00, 0x01}
+	large := strings.Repeat("x", defaultPreviewMaxPayloadSize+10)
+
+	tests := []struct {
+		Name              string
+		Config            common.PayloadPreviewInfo
+		Payload           []byte
+		ExpectedPayload   string
+		ExpectedEncoding  string
+		ExpectedTruncated bool
+	}{
+		{"Text", common.PayloadPreviewInfo{Enabled: true}, []byte(`{"id":"1"}`), `{"id":"1"}`, PayloadEncodingText, false},
+		{"Binary", common.PayloadPreviewInfo{Enabled: true}, binary, base64.StdEncoding.EncodeToString(binary), PayloadEncodingBase64, false},
+		{"Truncated", common.PayloadPreviewInfo{Enabled: true, MaxPayloadSize: 4}, []byte("123456"), "1234", PayloadEncodingText, true},
+		{"Default max size", common.PayloadPreviewInfo{Enabled: true}, []byte(large), large[:defaultPreviewMaxPayloadSize], PayloadEncodingText, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			tracker := NewPayloadPreviewTracker()
+			tracker.Record(test.Config, PayloadPreview{PipelineId: "default-pipeline", Function: "HTTPPost"}, test.Payload)
+
+			preview, found := tracker.Preview("default-pipeline")
+			require.True(t, found)
+			assert.Equal(t, test.ExpectedPayload, preview.Payload)
+			assert.Equal(t, test.ExpectedEncoding, preview.Encoding)
+			assert.Equal(t, test.ExpectedTruncated, preview.Truncated)
+			assert.Equal(t, len(test.Payload), preview.Size)
+			assert.Equal(t, "HTTPPost", preview.Function)
+		})
+	}
+}
+
+func TestPayloadPreviewTracker_Redaction(t *testing.T) {
+	tracker := NewPayloadPreviewTracker()
+	values := map[string]string{
+		"deviceName":    "Random-Float-Device",
+		"apiKey":        "key1",
+		"mqtt-password": "password1",
+		"AuthToken":     "token1",
+	}
+	tracker.Record(common.PayloadPreviewInfo{Enabled: true}, PayloadPreview{PipelineId: "default-pipeline", Context: values}, []byte("data"))
+
+	preview, found := tracker.Preview("default-pipeline")
+	require.True(t, found)
+	assert.Equal(t, "Random-Float-Device", preview.Context["deviceName"])
+	assert.Equal(t, previewRedacted, preview.Context["apiKey"])
+	assert.Equal(t, previewRedacted, preview.Context["mqtt-password"])
+	assert.Equal(t, previewRedacted, preview.Context["AuthToken"])
+	// The caller's values aren't changed
+	assert.Equal(t, "key1", values["apiKey"])
+
+	_, found = tracker.Preview("other-pipeline")
+	assert.False(t, found)
+}
@@ -0,0 +1,281 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/db/interfaces"
+)
+
+const (
+	// pipelineStatsKeySuffix is added to the service key for the key the totals are stored with, which keeps them
+	// apart from the data stored for Store and Forward
+	pipelineStatsKeySuffix = "-pipeline-statistics"
+	// pipelineStatsVersion is the version of the stored totals' format
+	pipelineStatsVersion = "1"
+	// defaultPipelineStatsPersistInterval is how often the totals are saved when PersistInterval isn't set
+	defaultPipelineStatsPersistInterval = 30 * time.Second
+)
+
+// PipelineOutcome is the outcome of processing a message with a functions pipeline
+type PipelineOutcome int
+
+const (
+	// PipelineExported is the outcome of a message processed by all the functions of the pipeline
+	PipelineExported PipelineOutcome = iota
+	// PipelineFiltered is the outcome of a message the pipeline stopped processing without an error, i.e. filtered out
+	PipelineFiltered
+	// PipelineFailed is the outcome of a message which resulted in an error
+	PipelineFailed
+)
+
+// String returns the name of the outcome
+func (outcome PipelineOutcome) String() string {
+	switch outcome {
+	case PipelineExported:
+		return "exported"
+	case PipelineFiltered:
+		return "filtered"
+	case PipelineFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// PipelineStats are the lifetime totals of the messages processed by a functions pipeline
+// swagger:model
+type PipelineStats struct {
+	Processed uint64 `json:"processed"`
+	Exported  uint64 `json:"exported"`
+	Failed    uint64 `json:"failed"`
+	Filtered  uint64 `json:"filtered"`
+	// FilteredBy is the number of messages each function marked as filtered out, by function name
+	FilteredBy map[string]uint64 `json:"filteredBy,omitempty"`
+}
+
+// PipelineStatsTracker tracks the lifetime totals of the messages processed by each functions pipeline, which can be
+// saved to and restored from the database so they aren't reset when the service restarts
+type PipelineStatsTracker struct {
+	mutex     sync.Mutex
+	pipelines map[string]*PipelineStats
+	// changed is set when the totals have changed since they were last saved
+	changed bool
+	// storedId is the id of the totals in the database, once stored
+	storedId string
+}
+
+// NewPipelineStatsTracker creates a new PipelineStatsTracker
+func NewPipelineStatsTracker() *PipelineStatsTracker {
+	return &PipelineStatsTracker{
+		pipelines: make(map[string]*PipelineStats),
+	}
+}
+
+// Record counts a message processed by the pipeline with the outcome
+func (tracker *PipelineStatsTracker) Record(pipelineId string, outcome PipelineOutcome) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	stats, found := tracker.pipelines[pipelineId]
+	if !found {
+		stats = &PipelineStats{}
+		tracker.pipelines[pipelineId] = stats
+	}
+
+	stats.Processed++
+	switch outcome {
+	case PipelineExported:
+		stats.Exported++
+	case PipelineFiltered:
+		stats.Filtered++
+	case PipelineFailed:
+		stats.Failed++
+	}
+
+	tracker.changed = true
+}
+
+// RecordFilteredBy counts a message the function of the pipeline marked as filtered out, in addition to the
+// message's outcome counted by Record
+func (tracker *PipelineStatsTracker) RecordFilteredBy(pipelineId string, functionName string) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	stats, found := tracker.pipelines[pipelineId]
+	if !found {
+		stats = &PipelineStats{}
+		tracker.pipelines[pipelineId] = stats
+	}
+
+	if stats.FilteredBy == nil {
+		stats.FilteredBy = make(map[string]uint64)
+	}
+	stats.FilteredBy[functionName]++
+
+	tracker.changed = true
+}
+
+// Report returns the totals of each pipeline, by pipeline id
+func (tracker *PipelineStatsTracker) Report() map[string]PipelineStats {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	report := make(map[string]PipelineStats, len(tracker.pipelines))
+	for pipelineId, stats := range tracker.pipelines {
+		copied := *stats
+		if stats.FilteredBy != nil {
+			copied.FilteredBy = make(map[string]uint64, len(stats.FilteredBy))
+			for functionName, count := range stats.FilteredBy {
+				copied.FilteredBy[functionName] = count
+			}
+		}
+		report[pipelineId] = copied
+	}
+
+	return report
+}
+
+// Load restores the totals saved for the service, which are added to those recorded since the service started
+func (tracker *PipelineStatsTracker) Load(storeClient interfaces.StoreClient, serviceKey string) error {
+	stored, err := storeClient.RetrieveFromStore(serviceKey + pipelineStatsKeySuffix)
+	if err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+
+	var saved map[string]PipelineStats
+	if err := json.Unmarshal(stored[0].Payload, &saved); err != nil {
+		return err
+	}
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.storedId = stored[0].ID
+	for pipelineId, totals := range saved {
+		stats, found := tracker.pipelines[pipelineId]
+		if !found {
+			stats = &PipelineStats{}
+			tracker.pipelines[pipelineId] = stats
+		}
+
+		stats.Processed += totals.Processed
+		stats.Exported += totals.Exported
+		stats.Failed += totals.Failed
+		stats.Filtered += totals.Filtered
+		for functionName, count := range totals.FilteredBy {
+			if stats.FilteredBy == nil {
+				stats.FilteredBy = make(map[string]uint64)
+			}
+			stats.FilteredBy[functionName] += count
+		}
+	}
+
+	return nil
+}
+
+// Save saves the totals for the service, if they have changed since they were last saved
+func (tracker *PipelineStatsTracker) Save(storeClient interfaces.StoreClient, serviceKey string) error {
+	tracker.mutex.Lock()
+	if !tracker.changed {
+		tracker.mutex.Unlock()
+		return nil
+	}
+	storedId := tracker.storedId
+	tracker.changed = false
+	tracker.mutex.Unlock()
+
+	payload, err := json.Marshal(tracker.Report())
+	if err != nil {
+		return err
+	}
+
+	stored := contracts.NewStoredObject(serviceKey+pipelineStatsKeySuffix, payload, 0, pipelineStatsVersion, nil)
+	if len(storedId) > 0 {
+		stored.ID = storedId
+		err = storeClient.Update(stored)
+	} else {
+		storedId, err = storeClient.Store(stored)
+	}
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	if err != nil {
+		// Saved again next time
+		tracker.changed = true
+		return err
+	}
+
+	tracker.storedId = storedId
+	return nil
+}
+
+// parsePipelineStatsPersistInterval returns how often the totals are saved, defaulting it when not set or invalid
+func parsePipelineStatsPersistInterval(config common.PipelineStatisticsInfo) time.Duration {
+	interval, err := time.ParseDuration(config.PersistInterval)
+	if err != nil || interval <= 0 {
+		return defaultPipelineStatsPersistInterval
+	}
+
+	return interval
+}
+
+// StartPersisting saves the totals for the service every PersistInterval, while they're changing, until the service
+// is stopped, when they are saved a last time
+func (tracker *PipelineStatsTracker) StartPersisting(
+	wg *sync.WaitGroup,
+	ctx context.Context,
+	lc logger.LoggingClient,
+	storeClient interfaces.StoreClient,
+	serviceKey string,
+	config common.PipelineStatisticsInfo) {
+	defer wg.Done()
+
+	interval := parsePipelineStatsPersistInterval(config)
+	lc.Infof("Saving the pipeline statistics every %s", interval.String())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := tracker.Save(storeClient, serviceKey); err != nil {
+				lc.Errorf("Unable to save the pipeline statistics: %s", err.Error())
+			}
+			lc.Info("Exiting pipeline statistics saving loop")
+			return
+
+		case <-ticker.C:
+			if err := tracker.Save(storeClient, serviceKey); err != nil {
+				lc.Errorf("Unable to save the pipeline statistics: %s", err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/store/db/interfaces/mocks"
+)
+
+const testStatsServiceKey = "app-test"
+
+func TestPipelineStatsTrackerRecord(t *testing.T) {
+	tracker := NewPipelineStatsTracker()
+	assert.Empty(t, tracker.Report())
+
+	tracker.Record("pipeline1", PipelineExported)
+	tracker.Record("pipeline1", PipelineExported)
+	tracker.Record("pipeline1", PipelineFiltered)
+	tracker.Record("pipeline1", PipelineFailed)
+	tracker.Record("pipeline2", PipelineFailed)
+
+	expected := map[string]PipelineStats{
+		"pipeline1": {Processed: 4, Exported: 2, Filtered: 1, Failed: 1},
+		"pipeline2": {Processed: 1, Failed: 1},
+	}
+	assert.Equal(t, expected, tracker.Report())
+}
+
+func TestPipelineStatsTrackerRecordFilteredBy(t *testing.T) {
+	tracker := NewPipelineStatsTracker()
+
+	tracker.Record("pipeline1", PipelineFiltered)
+	tracker.RecordFilteredBy("pipeline1", "FilterByDeviceName")
+	tracker.Record("pipeline1", PipelineFiltered)
+	tracker.RecordFilteredBy("pipeline1", "FilterByDeviceName")
+	tracker.Record("pipeline1", PipelineFiltered)
+	tracker.RecordFilteredBy("pipeline1", "CELFilter")
+
+	report := tracker.Report()
+	expected := PipelineStats{Processed: 3, Filtered: 3, FilteredBy: map[string]uint64{"FilterByDeviceName": 2, "CELFilter": 1}}
+	assert.Equal(t, expected, report["pipeline1"])
+
+	// The report is a copy
+	report["pipeline1"].FilteredBy["CELFilter"] = 10
+	assert.Equal(t, uint64(1), tracker.Report()["pipeline1"].FilteredBy["CELFilter"])
+
+	storeClient := &mocks.StoreClient{}
+	payload, err := json.Marshal(report)
+	require.NoError(t, err)
+	storeClient.On("RetrieveFromStore", testStatsServiceKey+pipelineStatsKeySuffix).Return([]contracts.StoredObject{{ID: "1", Payload: payload}}, nil)
+
+	restarted := NewPipelineStatsTracker()
+	restarted.RecordFilteredBy("pipeline1", "CELFilter")
+	require.NoError(t, restarted.Load(storeClient, testStatsServiceKey))
+	assert.Equal(t, map[string]uint64{"FilterByDeviceName": 2, "CELFilter": 11}, restarted.Report()["pipeline1"].FilteredBy)
+}
+
+func TestPipelineStatsTrackerSaveAndLoad(t *testing.T) {
+	var saved contracts.StoredObject
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("Store", mock.Anything).Return(func(object contracts.StoredObject) (string, error) {
+		saved = object
+		saved.ID = "b8f8a4a4-2d3f-4b8e-9a7e-6f0b3c2d1e0f"
+		return saved.ID, nil
+	}).Once()
+	storeClient.On("Update", mock.Anything).Return(func(object contracts.StoredObject) error {
+		saved = object
+		return nil
+	})
+
+	tracker := NewPipelineStatsTracker()
+
+	// Nothing to save until a message is recorded
+	require.NoError(t, tracker.Save(storeClient, testStatsServiceKey))
+	storeClient.AssertNotCalled(t, "Store", mock.Anything)
+
+	tracker.Record("pipeline1", PipelineExported)
+	require.NoError(t, tracker.Save(storeClient, testStatsServiceKey))
+	assert.Equal(t, testStatsServiceKey+pipelineStatsKeySuffix, saved.AppServiceKey)
+
+	// Saved again with the same id once changed
+	tracker.Record("pipeline1", PipelineFiltered)
+	require.NoError(t, tracker.Save(storeClient, testStatsServiceKey))
+	storeClient.AssertNumberOfCalls(t, "Store", 1)
+	storeClient.AssertNumberOfCalls(t, "Update", 1)
+	assert.Equal(t, "b8f8a4a4-2d3f-4b8e-9a7e-6f0b3c2d1e0f", saved.ID)
+
+	var totals map[string]PipelineStats
+	require.NoError(t, json.Unmarshal(saved.Payload, &totals))
+	assert.Equal(t, PipelineStats{Processed: 2, Exported: 1, Filtered: 1}, totals["pipeline1"])
+
+	// A restarted service adds the saved totals to those recorded since it started
+	storeClient.On("RetrieveFromStore", testStatsServiceKey+pipelineStatsKeySuffix).Return([]contracts.StoredObject{saved}, nil)
+	restarted := NewPipelineStatsTracker()
+	restarted.Record("pipeline1", PipelineFailed)
+	require.NoError(t, restarted.Load(storeClient, testStatsServiceKey))
+	assert.Equal(t, PipelineStats{Processed: 3, Exported: 1, Filtered: 1, Failed: 1}, restarted.Report()["pipeline1"])
+
+	// Which then updates the saved totals
+	require.NoError(t, restarted.Save(storeClient, testStatsServiceKey))
+	storeClient.AssertNumberOfCalls(t, "Store", 1)
+	storeClient.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestPipelineStatsTrackerLoadNothingSaved(t *testing.T) {
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("RetrieveFromStore", mock.Anything).Return(nil, nil)
+
+	tracker := NewPipelineStatsTracker()
+	require.NoError(t, tracker.Load(storeClient, testStatsServiceKey))
+	assert.Empty(t, tracker.Report())
+}
+
+func TestPipelineStatsTrackerSaveFailed(t *testing.T) {
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("Store", mock.Anything).Return(errors.New("unavailable")).Once()
+	storeClient.On("Store", mock.Anything).Return(func(object contracts.StoredObject) (string, error) {
+		return "b8f8a4a4-2d3f-4b8e-9a7e-6f0b3c2d1e0f", nil
+	}).Once()
+
+	tracker := NewPipelineStatsTracker()
+	tracker.Record("pipeline1", PipelineExported)
+	require.Error(t, tracker.Save(storeClient, testStatsServiceKey))
+
+	// Saved again next time though unchanged since
+	require.NoError(t, tracker.Save(storeClient, testStatsServiceKey))
+	storeClient.AssertNumberOfCalls(t, "Store", 2)
+}
+
+func TestParsePipelineStatsPersistInterval(t *testing.T) {
+	assert.Equal(t, defaultPipelineStatsPersistInterval, parsePipelineStatsPersistInterval(common.PipelineStatisticsInfo{}))
+	assert.Equal(t, defaultPipelineStatsPersistInterval, parsePipelineStatsPersistInterval(common.PipelineStatisticsInfo{PersistInterval: "bogus"}))
+	assert.Equal(t, "1m0s", parsePipelineStatsPersistInterval(common.PipelineStatisticsInfo{PersistInterval: "1m"}).String())
+}
@@ -0,0 +1,126 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// ErrProfileInProgress is returned when a profile is requested while another is being taken
+var ErrProfileInProgress = errors.New("a pipeline profile is already in progress")
+
+// PipelineProfiler takes CPU profiles of the next executions of the functions pipeline, so that hotspots in custom
+// functions can be diagnosed in the field
+type PipelineProfiler struct {
+	mutex     sync.Mutex
+	remaining int
+	completed int
+	done      chan struct{}
+	// startCPUProfile and stopCPUProfile are replaced in unit tests
+	startCPUProfile func(buffer *bytes.Buffer) error
+	stopCPUProfile  func()
+}
+
+// PipelineProfile is the CPU profile of the functions pipeline executions
+type PipelineProfile struct {
+	// Data is the profile in the pprof format, which `go tool pprof -http` shows as a flame graph
+	Data []byte
+	// Executions is the number of pipeline executions completed while profiling, which is fewer than requested
+	// when the timeout elapsed
+	Executions int
+	Duration   time.Duration
+}
+
+// NewPipelineProfiler returns a new PipelineProfiler
+func NewPipelineProfiler() *PipelineProfiler {
+	return &PipelineProfiler{
+		startCPUProfile: func(buffer *bytes.Buffer) error {
+			return pprof.StartCPUProfile(buffer)
+		},
+		stopCPUProfile: pprof.StopCPUProfile,
+	}
+}
+
+// Profile takes a CPU profile until the functions pipeline has executed the number of executions, the timeout
+// elapses or the context is cancelled. Only one profile is taken at a time.
+func (profiler *PipelineProfiler) Profile(ctx context.Context, executions int, timeout time.Duration) (PipelineProfile, error) {
+	if executions < 1 {
+		return PipelineProfile{}, errors.New("executions must be at least 1")
+	}
+
+	profiler.mutex.Lock()
+	if profiler.done != nil {
+		profiler.mutex.Unlock()
+		return PipelineProfile{}, ErrProfileInProgress
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := profiler.startCPUProfile(buffer); err != nil {
+		profiler.mutex.Unlock()
+		return PipelineProfile{}, err
+	}
+
+	done := make(chan struct{})
+	profiler.done = done
+	profiler.remaining = executions
+	profiler.completed = 0
+	profiler.mutex.Unlock()
+
+	started := time.Now()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	profiler.mutex.Lock()
+	profiler.stopCPUProfile()
+	completed := profiler.completed
+	profiler.done = nil
+	profiler.mutex.Unlock()
+
+	return PipelineProfile{
+		Data:       buffer.Bytes(),
+		Executions: completed,
+		Duration:   time.Since(started),
+	}, nil
+}
+
+// ExecutionDone records an execution of the functions pipeline completing, which completes the profile in progress
+// once it has profiled the number of executions requested
+func (profiler *PipelineProfiler) ExecutionDone() {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	if profiler.done == nil || profiler.remaining == 0 {
+		return
+	}
+
+	profiler.completed++
+	profiler.remaining--
+	if profiler.remaining == 0 {
+		close(profiler.done)
+	}
+}
@@ -0,0 +1,117 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPipelineProfiler() *PipelineProfiler {
+	profiler := NewPipelineProfiler()
+	profiler.startCPUProfile = func(buffer *bytes.Buffer) error {
+		buffer.WriteString("profile")
+		return nil
+	}
+	profiler.stopCPUProfile = func() {}
+	return profiler
+}
+
+// waitForProfile waits until the profiler has started the profile
+func waitForProfile(t *testing.T, profiler *PipelineProfiler) {
+	require.Eventually(t, func() bool {
+		profiler.mutex.Lock()
+		defer profiler.mutex.Unlock()
+		return profiler.done != nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestPipelineProfiler_Profile(t *testing.T) {
+	profiler := newTestPipelineProfiler()
+
+	// Executions before the profile starts aren't counted
+	profiler.ExecutionDone()
+
+	result := make(chan PipelineProfile)
+	go func() {
+		profile, err := profiler.Profile(context.Background(), 2, time.Minute)
+		assert.NoError(t, err)
+		result <- profile
+	}()
+
+	waitForProfile(t, profiler)
+
+	_, err := profiler.Profile(context.Background(), 1, time.Minute)
+	assert.Equal(t, ErrProfileInProgress, err)
+
+	profiler.ExecutionDone()
+	profiler.ExecutionDone()
+	// Executions after the requested number are ignored
+	profiler.ExecutionDone()
+
+	profile := <-result
+	assert.Equal(t, 2, profile.Executions)
+	assert.Equal(t, []byte("profile"), profile.Data)
+
+	// Another profile can be taken once complete
+	profile, err = profiler.Profile(context.Background(), 1, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 0, profile.Executions)
+}
+
+func TestPipelineProfiler_ProfileCancelled(t *testing.T) {
+	profiler := newTestPipelineProfiler()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan PipelineProfile)
+	go func() {
+		profile, err := profiler.Profile(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		result <- profile
+	}()
+
+	waitForProfile(t, profiler)
+	profiler.ExecutionDone()
+	cancel()
+
+	profile := <-result
+	assert.Equal(t, 1, profile.Executions)
+}
+
+func TestPipelineProfiler_ProfileErrors(t *testing.T) {
+	profiler := newTestPipelineProfiler()
+
+	_, err := profiler.Profile(context.Background(), 0, time.Minute)
+	assert.Error(t, err)
+
+	profiler.startCPUProfile = func(buffer *bytes.Buffer) error {
+		return errors.New("cpu profiling already in use")
+	}
+	_, err = profiler.Profile(context.Background(), 1, time.Minute)
+	assert.Error(t, err)
+
+	// A failed start doesn't leave a profile in progress
+	profiler.startCPUProfile = func(buffer *bytes.Buffer) error { return nil }
+	_, err = profiler.Profile(context.Background(), 1, time.Millisecond)
+	assert.NoError(t, err)
+}
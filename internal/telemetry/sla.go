@@ -0,0 +1,185 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+// slaBucketCount is the number of buckets the rolling window is divided into
+const slaBucketCount = 60
+
+type slaBucket struct {
+	index         int64
+	total         uint64
+	failed        uint64
+	withinLatency uint64
+	latencySum    time.Duration
+	maxLatency    time.Duration
+}
+
+// SLATracker tracks the delivery success rate and latency of the functions pipeline over a rolling window
+type SLATracker struct {
+	mutex      sync.Mutex
+	bucketSize time.Duration
+	buckets    [slaBucketCount]slaBucket
+	now        func() time.Time
+}
+
+// SLAReport describes the functions pipeline's compliance with its Service Level Objectives
+// swagger:model
+type SLAReport struct {
+	Enabled              bool    `json:"enabled"`
+	Window               string  `json:"window"`
+	Total                uint64  `json:"total"`
+	Failed               uint64  `json:"failed"`
+	SuccessRate          float64 `json:"successRate"`
+	SuccessRateTarget    float64 `json:"successRateTarget"`
+	SuccessRateCompliant bool    `json:"successRateCompliant"`
+	AverageLatency       string  `json:"averageLatency"`
+	MaxLatency           string  `json:"maxLatency"`
+	LatencyTarget        string  `json:"latencyTarget"`
+	// WithinLatencyTarget is the fraction of messages processed within the LatencyTarget
+	WithinLatencyTarget float64 `json:"withinLatencyTarget"`
+	LatencyPercentile   float64 `json:"latencyPercentile"`
+	LatencyCompliant    bool    `json:"latencyCompliant"`
+	Compliant           bool    `json:"compliant"`
+}
+
+// NewSLATracker creates a new SLATracker
+func NewSLATracker() *SLATracker {
+	return &SLATracker{now: time.Now}
+}
+
+// parseSLAConfig returns the window and latency target durations, with a zero window if tracking is disabled
+func parseSLAConfig(config common.SLAInfo) (time.Duration, time.Duration) {
+	window, err := time.ParseDuration(config.Window)
+	if err != nil || window <= 0 {
+		return 0, 0
+	}
+
+	latencyTarget, err := time.ParseDuration(config.LatencyTarget)
+	if err != nil {
+		latencyTarget = 0
+	}
+
+	return window, latencyTarget
+}
+
+// currentIndex resets the buckets if the window size has changed and returns the index of the current bucket.
+// Must be called with the mutex held.
+func (tracker *SLATracker) currentIndex(window time.Duration) int64 {
+	bucketSize := window / slaBucketCount
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+
+	if bucketSize != tracker.bucketSize {
+		tracker.bucketSize = bucketSize
+		tracker.buckets = [slaBucketCount]slaBucket{}
+	}
+
+	return tracker.now().UnixNano() / int64(bucketSize)
+}
+
+// Record records the outcome of processing a message through the functions pipeline
+func (tracker *SLATracker) Record(config common.SLAInfo, latency time.Duration, success bool) {
+	window, latencyTarget := parseSLAConfig(config)
+	if window == 0 {
+		return
+	}
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	index := tracker.currentIndex(window)
+	bucket := &tracker.buckets[index%slaBucketCount]
+	if bucket.index != index {
+		*bucket = slaBucket{index: index}
+	}
+
+	bucket.total++
+	if !success {
+		bucket.failed++
+	}
+	if latencyTarget == 0 || latency <= latencyTarget {
+		bucket.withinLatency++
+	}
+	bucket.latencySum += latency
+	if latency > bucket.maxLatency {
+		bucket.maxLatency = latency
+	}
+}
+
+// Report returns the compliance with the Service Level Objectives over the current rolling window
+func (tracker *SLATracker) Report(config common.SLAInfo) SLAReport {
+	report := SLAReport{
+		Window:               config.Window,
+		SuccessRate:          1,
+		SuccessRateTarget:    config.SuccessRateTarget,
+		LatencyTarget:        config.LatencyTarget,
+		WithinLatencyTarget:  1,
+		LatencyPercentile:    config.LatencyPercentile,
+		SuccessRateCompliant: true,
+		LatencyCompliant:     true,
+		Compliant:            true,
+	}
+
+	window, _ := parseSLAConfig(config)
+	if window == 0 {
+		return report
+	}
+	report.Enabled = true
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	index := tracker.currentIndex(window)
+
+	var withinLatency uint64
+	var latencySum, maxLatency time.Duration
+	for _, bucket := range tracker.buckets {
+		// Only the buckets within the window are included
+		if bucket.total == 0 || bucket.index <= index-slaBucketCount {
+			continue
+		}
+
+		report.Total += bucket.total
+		report.Failed += bucket.failed
+		withinLatency += bucket.withinLatency
+		latencySum += bucket.latencySum
+		if bucket.maxLatency > maxLatency {
+			maxLatency = bucket.maxLatency
+		}
+	}
+
+	if report.Total > 0 {
+		report.SuccessRate = float64(report.Total-report.Failed) / float64(report.Total)
+		report.WithinLatencyTarget = float64(withinLatency) / float64(report.Total)
+		report.AverageLatency = (latencySum / time.Duration(report.Total)).String()
+		report.MaxLatency = maxLatency.String()
+	}
+
+	report.SuccessRateCompliant = report.SuccessRate >= config.SuccessRateTarget
+	report.LatencyCompliant = report.WithinLatencyTarget >= config.LatencyPercentile
+	report.Compliant = report.SuccessRateCompliant && report.LatencyCompliant
+
+	return report
+}
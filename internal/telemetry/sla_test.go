@@ -0,0 +1,102 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLATracker(t *testing.T) {
+	config := common.SLAInfo{
+		Window:            "1m",
+		SuccessRateTarget: 0.9,
+		LatencyTarget:     "100ms",
+		LatencyPercentile: 0.75,
+	}
+
+	now := time.Date(2021, 7, 4, 10, 0, 0, 0, time.UTC)
+	tracker := NewSLATracker()
+	tracker.now = func() time.Time { return now }
+
+	report := tracker.Report(config)
+	assert.True(t, report.Enabled)
+	assert.Equal(t, uint64(0), report.Total)
+	assert.True(t, report.Compliant, "expected compliance with no messages")
+
+	for i := 0; i < 8; i++ {
+		tracker.Record(config, 50*time.Millisecond, true)
+	}
+	tracker.Record(config, 200*time.Millisecond, true)
+	tracker.Record(config, 300*time.Millisecond, false)
+
+	report = tracker.Report(config)
+	assert.Equal(t, uint64(10), report.Total)
+	assert.Equal(t, uint64(1), report.Failed)
+	assert.Equal(t, 0.9, report.SuccessRate)
+	assert.True(t, report.SuccessRateCompliant)
+	assert.Equal(t, 0.8, report.WithinLatencyTarget)
+	assert.True(t, report.LatencyCompliant)
+	assert.True(t, report.Compliant)
+	assert.Equal(t, "90ms", report.AverageLatency)
+	assert.Equal(t, "300ms", report.MaxLatency)
+
+	// Failures later in the window take it out of compliance
+	now = now.Add(30 * time.Second)
+	tracker.Record(config, 500*time.Millisecond, false)
+	tracker.Record(config, 500*time.Millisecond, false)
+
+	report = tracker.Report(config)
+	assert.Equal(t, uint64(12), report.Total)
+	assert.False(t, report.SuccessRateCompliant)
+	assert.False(t, report.LatencyCompliant)
+	assert.False(t, report.Compliant)
+
+	// Earlier messages roll out of the window
+	now = now.Add(45 * time.Second)
+	report = tracker.Report(config)
+	assert.Equal(t, uint64(2), report.Total)
+	assert.Equal(t, float64(0), report.SuccessRate)
+
+	now = now.Add(time.Minute)
+	report = tracker.Report(config)
+	assert.Equal(t, uint64(0), report.Total)
+	assert.True(t, report.Compliant)
+}
+
+func TestSLATracker_Disabled(t *testing.T) {
+	tracker := NewSLATracker()
+	tracker.Record(common.SLAInfo{}, time.Second, false)
+
+	report := tracker.Report(common.SLAInfo{})
+	assert.False(t, report.Enabled)
+	assert.Equal(t, uint64(0), report.Total)
+	assert.True(t, report.Compliant)
+}
+
+func TestSLATracker_WindowChanged(t *testing.T) {
+	tracker := NewSLATracker()
+	tracker.Record(common.SLAInfo{Window: "1h"}, time.Second, false)
+	assert.Equal(t, uint64(1), tracker.Report(common.SLAInfo{Window: "1h"}).Total)
+
+	// Changing the window restarts tracking
+	assert.Equal(t, uint64(0), tracker.Report(common.SLAInfo{Window: "10m"}).Total)
+}
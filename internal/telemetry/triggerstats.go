@@ -0,0 +1,48 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package telemetry
+
+import "sync/atomic"
+
+// TriggerStats are the totals of the messages received by the trigger which never reached a functions pipeline
+// swagger:model
+type TriggerStats struct {
+	// DroppedMessages is the number of messages discarded since the trigger's queues were full
+	DroppedMessages uint64 `json:"droppedMessages"`
+}
+
+// TriggerStatsTracker tracks the totals of the messages received by the trigger which never reached a functions pipeline
+type TriggerStatsTracker struct {
+	droppedMessages uint64
+}
+
+// NewTriggerStatsTracker creates a new TriggerStatsTracker
+func NewTriggerStatsTracker() *TriggerStatsTracker {
+	return &TriggerStatsTracker{}
+}
+
+// MessageDropped counts a message discarded by the trigger and returns the total number discarded
+func (tracker *TriggerStatsTracker) MessageDropped() uint64 {
+	return atomic.AddUint64(&tracker.droppedMessages, 1)
+}
+
+// Report returns the current totals
+func (tracker *TriggerStatsTracker) Report() TriggerStats {
+	return TriggerStats{
+		DroppedMessages: atomic.LoadUint64(&tracker.droppedMessages),
+	}
+}
@@ -0,0 +1,362 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/secure"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/google/uuid"
+	amqpClient "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	defaultConnectTimeout = 30 * time.Second
+	reconnectInterval     = 5 * time.Second
+)
+
+// Trigger implements Trigger to support consuming from a queue on an AMQP 0-9-1 broker, i.e. RabbitMQ
+type Trigger struct {
+	dic        *di.Container
+	lc         logger.LoggingClient
+	runtime    *runtime.GolangRuntime
+	config     sdkCommon.AMQPConfig
+	timeout    time.Duration
+	connection *amqpClient.Connection
+	publisher  *amqpClient.Channel
+	connLock   sync.Mutex
+	// publish sends the pipeline output to the broker. Replaceable for unit testing.
+	publish func(exchange string, routingKey string, message amqpClient.Publishing) error
+}
+
+func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime) *Trigger {
+	trigger := &Trigger{
+		dic:     dic,
+		runtime: runtime,
+		lc:      bootstrapContainer.LoggingClientFrom(dic.Get),
+	}
+	trigger.publish = trigger.publishResponse
+	return trigger
+}
+
+// Initialize initializes the Trigger for an AMQP broker
+func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context, background <-chan interfaces.BackgroundMessage) (bootstrap.Deferred, error) {
+	// Convenience short cuts
+	lc := trigger.lc
+	config := container.ConfigurationFrom(trigger.dic.Get)
+	trigger.config = config.Trigger.AMQP
+
+	lc.Info("Initializing AMQP Trigger")
+
+	if background != nil {
+		return nil, errors.New("background publishing not supported for services using AMQP trigger")
+	}
+
+	if len(strings.TrimSpace(trigger.config.Queue)) == 0 {
+		return nil, errors.New("missing Queue for AMQP Trigger. Must be present in [Trigger.AMQP] section.")
+	}
+
+	if trigger.config.Prefetch < 0 {
+		return nil, fmt.Errorf("invalid AMQP Prefetch %d. Must not be negative", trigger.config.Prefetch)
+	}
+
+	trigger.timeout = defaultConnectTimeout
+	if len(trigger.config.ConnectTimeout) > 0 {
+		var err error
+		trigger.timeout, err = time.ParseDuration(trigger.config.ConnectTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AMQP ConnectTimeout '%s': %s", trigger.config.ConnectTimeout, err.Error())
+		}
+	}
+
+	deliveries, closed, err := trigger.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Infof("Consuming from queue '%s' for AMQP trigger", trigger.config.Queue)
+
+	appWg.Add(1)
+	go func() {
+		defer appWg.Done()
+		trigger.receive(appCtx, deliveries, closed)
+	}()
+
+	deferred := func() {
+		lc.Info("Disconnecting from broker for AMQP trigger")
+		trigger.disconnect()
+	}
+
+	return deferred, nil
+}
+
+// connect connects to the broker, opens the channel used to publish responses and starts consuming from the queue.
+// The deliveries are closed when the consuming channel or the connection is closed, with the reason sent on closed.
+func (trigger *Trigger) connect() (deliveries <-chan amqpClient.Delivery, closed chan *amqpClient.Error, err error) {
+	// Since this factory is shared between the AMQP pipeline function and this trigger we must provide
+	// a dummy AppFunctionContext which will provide access to GetSecret
+	factory := secure.NewAMQPFactory(
+		appfunction.NewContext("", trigger.dic, ""),
+		trigger.config.AuthMode,
+		trigger.config.SecretPath,
+		trigger.config.SkipCertVerify,
+	)
+
+	connection, err := factory.Create(trigger.config.Url, amqpClient.Config{Dial: amqpClient.DefaultDial(trigger.timeout)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not connect to broker for AMQP trigger: %s", err.Error())
+	}
+
+	consumer, err := connection.Channel()
+	if err == nil && trigger.config.Prefetch > 0 {
+		err = consumer.Qos(trigger.config.Prefetch, 0, false)
+	}
+	if err == nil {
+		closed = consumer.NotifyClose(make(chan *amqpClient.Error, 1))
+		deliveries, err = consumer.Consume(trigger.config.Queue, "", false, false, false, false, nil)
+	}
+	if err != nil {
+		_ = connection.Close()
+		return nil, nil, fmt.Errorf("could not consume from queue '%s' for AMQP trigger: %s", trigger.config.Queue, err.Error())
+	}
+
+	var publisher *amqpClient.Channel
+	if len(trigger.config.PublishRoutingKey) > 0 {
+		publisher, err = connection.Channel()
+		if err == nil {
+			err = publisher.Confirm(false)
+		}
+		if err != nil {
+			_ = connection.Close()
+			return nil, nil, fmt.Errorf("could not open channel to publish responses for AMQP trigger: %s", err.Error())
+		}
+	}
+
+	trigger.connLock.Lock()
+	defer trigger.connLock.Unlock()
+	trigger.connection = connection
+	trigger.publisher = publisher
+
+	return deliveries, closed, nil
+}
+
+func (trigger *Trigger) disconnect() {
+	trigger.connLock.Lock()
+	defer trigger.connLock.Unlock()
+	if trigger.connection != nil {
+		_ = trigger.connection.Close()
+	}
+}
+
+// receive processes the messages delivered until the service is stopped, reconnecting if the connection is lost
+func (trigger *Trigger) receive(appCtx context.Context, deliveries <-chan amqpClient.Delivery, closed chan *amqpClient.Error) {
+	lc := trigger.lc
+
+	for {
+		select {
+		case <-appCtx.Done():
+			lc.Info("Exiting waiting for AMQP messages")
+			trigger.disconnect()
+			return
+
+		case delivery, ok := <-deliveries:
+			if !ok {
+				// closed is closed along with the deliveries, so this doesn't block
+				lc.Errorf("Lost connection to broker for AMQP trigger: %v", <-closed)
+				trigger.disconnect()
+
+				if deliveries, closed, ok = trigger.reconnect(appCtx); !ok {
+					lc.Info("Exiting waiting for AMQP messages")
+					return
+				}
+				continue
+			}
+
+			received := time.Now()
+			envelope := types.MessageEnvelope{Payload: delivery.Body, ReceivedTopic: delivery.RoutingKey}
+			trigger.runtime.Dispatch(envelope, func() {
+				acknowledge, requeue := trigger.processDelivery(delivery, received)
+				if acknowledge {
+					if err := delivery.Ack(false); err != nil {
+						lc.Errorf("could not acknowledge message for AMQP trigger: %s", err.Error())
+					}
+					return
+				}
+
+				// Only requeued for retryable errors so a message the pipeline can't process isn't redelivered
+				// indefinitely. Configure a dead letter exchange on the queue to keep these messages.
+				if err := delivery.Nack(false, requeue); err != nil {
+					lc.Errorf("could not reject message for AMQP trigger: %s", err.Error())
+				}
+			})
+		}
+	}
+}
+
+// reconnect repeatedly attempts to reconnect until successful or the service is stopped
+func (trigger *Trigger) reconnect(appCtx context.Context) (<-chan amqpClient.Delivery, chan *amqpClient.Error, bool) {
+	for {
+		select {
+		case <-appCtx.Done():
+			return nil, nil, false
+		case <-time.After(reconnectInterval):
+		}
+
+		deliveries, closed, err := trigger.connect()
+		if err != nil {
+			trigger.lc.Errorf("Unable to reconnect for AMQP trigger: %s", err.Error())
+			continue
+		}
+
+		trigger.lc.Infof("Reconnected and consuming from queue '%s' for AMQP trigger", trigger.config.Queue)
+		return deliveries, closed, true
+	}
+}
+
+// processDelivery passes the delivery to the functions pipeline and returns whether the delivery is acknowledged or,
+// if not, whether it's requeued for redelivery
+func (trigger *Trigger) processDelivery(delivery amqpClient.Delivery, received time.Time) (bool, bool) {
+	lc := trigger.lc
+
+	data := delivery.Body
+	if len(data) == 0 {
+		lc.Warnf("Received empty message from AMQP trigger on queue '%s'", trigger.config.Queue)
+		return false, false
+	}
+
+	contentType := delivery.ContentType
+	if len(contentType) == 0 {
+		contentType = common.ContentTypeJSON
+		if data[0] != byte('{') && data[0] != byte('[') {
+			// If not JSON then assume it is CBOR
+			contentType = common.ContentTypeCBOR
+		}
+	}
+
+	correlationID := delivery.CorrelationId
+	if len(correlationID) == 0 {
+		correlationID = uuid.New().String()
+	}
+
+	appContext := appfunction.NewContext(correlationID, trigger.dic, contentType)
+	appContext.SetReceived(received)
+
+	lc.Debugf("Received message from AMQP Trigger with %d bytes from exchange '%s' with routing key '%s'. Content-Type=%s",
+		len(data),
+		delivery.Exchange,
+		delivery.RoutingKey,
+		contentType)
+	lc.Tracef("%s=%s", common.CorrelationHeader, correlationID)
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: correlationID,
+		ContentType:   contentType,
+		Payload:       data,
+		ReceivedTopic: delivery.RoutingKey,
+	}
+
+	messageError := trigger.runtime.ProcessMessage(appContext, envelope)
+	if messageError != nil {
+		// ProcessMessage logs the error, so no need to log it here.
+		return false, trigger.shouldRequeue(appContext, messageError)
+	}
+
+	routingKey := trigger.config.PublishRoutingKey
+	if responseTopic := appContext.ResponseTopic(); len(responseTopic) > 0 {
+		routingKey = responseTopic
+	}
+	if len(appContext.ResponseData()) == 0 || len(routingKey) == 0 {
+		return true, false
+	}
+
+	formattedKey, err := appContext.ApplyValues(routingKey)
+	if err != nil {
+		lc.Errorf("could not format routing key '%s' for AMQP trigger output: %s", routingKey, err.Error())
+		return true, false
+	}
+
+	message := amqpClient.Publishing{
+		ContentType:   appContext.ResponseContentType(),
+		CorrelationId: correlationID,
+		DeliveryMode:  amqpClient.Persistent,
+		Body:          appContext.ResponseData(),
+	}
+
+	if err := trigger.publish(trigger.config.PublishExchange, formattedKey, message); err != nil {
+		lc.Errorf("could not publish with routing key '%s' for AMQP trigger: %s", formattedKey, err.Error())
+		return true, false
+	}
+
+	lc.Trace("Sent AMQP Trigger response message", common.CorrelationHeader, correlationID)
+	lc.Debugf("Sent AMQP Trigger response message with routing key '%s' and %d bytes", formattedKey, len(appContext.ResponseData()))
+	return true, false
+}
+
+// shouldRequeue returns whether the message which failed with the error is requeued, which is only when the function
+// explicitly returned a retryable error and its retry data wasn't stored by Store and Forward
+func (trigger *Trigger) shouldRequeue(appContext *appfunction.Context, messageError *runtime.MessageError) bool {
+	var pipelineError util.PipelineError
+	if !errors.As(messageError.Err, &pipelineError) || pipelineError.Category != util.ErrorCategoryRetryable {
+		return false
+	}
+
+	config := container.ConfigurationFrom(trigger.dic.Get)
+	return !config.Writable.StoreAndForward.Enabled || appContext.RetryData() == nil
+}
+
+func (trigger *Trigger) publishResponse(exchange string, routingKey string, message amqpClient.Publishing) error {
+	trigger.connLock.Lock()
+	publisher := trigger.publisher
+	trigger.connLock.Unlock()
+
+	if publisher == nil {
+		return amqpClient.ErrClosed
+	}
+
+	confirmation, err := publisher.PublishWithDeferredConfirm(exchange, routingKey, false, false, message)
+	if err != nil {
+		return err
+	}
+	if !confirmation.Wait() {
+		return errors.New("message was not confirmed by the broker")
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package amqp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+
+	amqpClient "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dic *di.Container
+
+func TestMain(m *testing.M) {
+	dic = di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+	m.Run()
+}
+
+func updateConfig(amqpConfig sdkCommon.AMQPConfig) {
+	config := &sdkCommon.ConfigurationStruct{
+		Trigger: sdkCommon.TriggerInfo{
+			Type: "amqp",
+			AMQP: amqpConfig,
+		},
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+}
+
+func TestInitializeErrors(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Config        sdkCommon.AMQPConfig
+		Background    chan interfaces.BackgroundMessage
+		ErrorContains string
+	}{
+		{"Background not supported", sdkCommon.AMQPConfig{Queue: "events"}, make(chan interfaces.BackgroundMessage), "background"},
+		{"Missing queue", sdkCommon.AMQPConfig{Queue: " "}, nil, "Queue"},
+		{"Bad Prefetch", sdkCommon.AMQPConfig{Queue: "events", Prefetch: -1}, nil, "Prefetch"},
+		{"Bad ConnectTimeout", sdkCommon.AMQPConfig{Queue: "events", ConnectTimeout: "bogus"}, nil, "ConnectTimeout"},
+		{"Bad Url", sdkCommon.AMQPConfig{Url: "tcp://localhost:5672", Queue: "events"}, nil, "could not connect"},
+		{"Connect failed", sdkCommon.AMQPConfig{Url: "amqp://127.0.0.1:1", Queue: "events", ConnectTimeout: "1s"}, nil, "could not connect"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			updateConfig(test.Config)
+
+			trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+			_, err := trigger.Initialize(&sync.WaitGroup{}, context.Background(), test.Background)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ErrorContains)
+		})
+	}
+}
+
+type published struct {
+	exchange   string
+	routingKey string
+	message    amqpClient.Publishing
+}
+
+func TestProcessDelivery(t *testing.T) {
+	event := dtos.NewEvent("thermostat", "LivingRoomThermostat", "temperature")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	payload, err := json.Marshal(requests.NewAddEventRequest(event))
+	require.NoError(t, err)
+
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		switch data.(dtos.Event).SourceName {
+		case "fail":
+			return false, errors.New("failed")
+		case "retry":
+			return false, util.NewRetryableError(errors.New("unavailable"))
+		case "ignore":
+			return false, util.NewIgnoreError(errors.New("not applicable"))
+		case "topic":
+			appContext.SetResponseTopic("alerts.{devicename}")
+		}
+		appContext.SetResponseData([]byte("response"))
+		appContext.SetResponseContentType(common.ContentTypeText)
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	failedEvent := event
+	failedEvent.SourceName = "fail"
+	failedPayload, err := json.Marshal(requests.NewAddEventRequest(failedEvent))
+	require.NoError(t, err)
+
+	retryEvent := event
+	retryEvent.SourceName = "retry"
+	retryPayload, err := json.Marshal(requests.NewAddEventRequest(retryEvent))
+	require.NoError(t, err)
+
+	ignoreEvent := event
+	ignoreEvent.SourceName = "ignore"
+	ignorePayload, err := json.Marshal(requests.NewAddEventRequest(ignoreEvent))
+	require.NoError(t, err)
+
+	topicEvent := event
+	topicEvent.SourceName = "topic"
+	topicPayload, err := json.Marshal(requests.NewAddEventRequest(topicEvent))
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name       string
+		RoutingKey string
+		Body       []byte
+		Expected   bool
+		Requeue    bool
+		Published  *published
+	}{
+		{"Empty message", "response.{devicename}", nil, false, false, nil},
+		{"Pipeline error", "response.{devicename}", failedPayload, false, false, nil},
+		{"Retryable pipeline error", "response.{devicename}", retryPayload, false, true, nil},
+		{"Ignored pipeline error", "response.{devicename}", ignorePayload, true, false, nil},
+		{"No response routing key", "", payload, true, false, nil},
+		{"Response published", "response.{devicename}", payload, true, false, &published{
+			exchange:   "amq.topic",
+			routingKey: "response.LivingRoomThermostat",
+			message: amqpClient.Publishing{
+				ContentType:   common.ContentTypeText,
+				CorrelationId: "123-456",
+				DeliveryMode:  amqpClient.Persistent,
+				Body:          []byte("response"),
+			},
+		}},
+		{"Response topic overridden", "response.{devicename}", topicPayload, true, false, &published{
+			exchange:   "amq.topic",
+			routingKey: "alerts.LivingRoomThermostat",
+			message: amqpClient.Publishing{
+				ContentType:   common.ContentTypeText,
+				CorrelationId: "123-456",
+				DeliveryMode:  amqpClient.Persistent,
+				Body:          []byte("response"),
+			},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			trigger := NewTrigger(dic, goRuntime)
+			trigger.config = sdkCommon.AMQPConfig{
+				Queue:             "events",
+				PublishExchange:   "amq.topic",
+				PublishRoutingKey: test.RoutingKey,
+			}
+
+			var actual *published
+			trigger.publish = func(exchange string, routingKey string, message amqpClient.Publishing) error {
+				actual = &published{exchange: exchange, routingKey: routingKey, message: message}
+				return nil
+			}
+
+			result, requeue := trigger.processDelivery(amqpClient.Delivery{
+				CorrelationId: "123-456",
+				RoutingKey:    "events.thermostat",
+				Body:          test.Body,
+			}, time.Now())
+			assert.Equal(t, test.Expected, result)
+			assert.Equal(t, test.Requeue, requeue)
+			assert.Equal(t, test.Published, actual)
+		})
+	}
+}
+
+// fakeAcknowledger records the acknowledgement of each delivery
+type fakeAcknowledger struct {
+	lock         sync.Mutex
+	acknowledged []uint64
+	rejected     []uint64
+	done         chan struct{}
+}
+
+func (acknowledger *fakeAcknowledger) Ack(tag uint64, _ bool) error {
+	acknowledger.lock.Lock()
+	defer acknowledger.lock.Unlock()
+	acknowledger.acknowledged = append(acknowledger.acknowledged, tag)
+	acknowledger.done <- struct{}{}
+	return nil
+}
+
+func (acknowledger *fakeAcknowledger) Nack(tag uint64, _ bool, _ bool) error {
+	acknowledger.lock.Lock()
+	defer acknowledger.lock.Unlock()
+	acknowledger.rejected = append(acknowledger.rejected, tag)
+	acknowledger.done <- struct{}{}
+	return nil
+}
+
+func (acknowledger *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return acknowledger.Nack(tag, false, requeue)
+}
+
+func TestReceive(t *testing.T) {
+	event := dtos.NewEvent("thermostat", "LivingRoomThermostat", "temperature")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	payload, err := json.Marshal(requests.NewAddEventRequest(event))
+	require.NoError(t, err)
+
+	goRuntime := &runtime.GolangRuntime{}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{func(_ interfaces.AppFunctionContext, _ interface{}) (bool, interface{}) {
+		return false, nil
+	}})
+
+	trigger := NewTrigger(dic, goRuntime)
+	trigger.config = sdkCommon.AMQPConfig{Queue: "events"}
+
+	acknowledger := &fakeAcknowledger{done: make(chan struct{}, 2)}
+	deliveries := make(chan amqpClient.Delivery, 2)
+	deliveries <- amqpClient.Delivery{Acknowledger: acknowledger, DeliveryTag: 1, Body: payload}
+	deliveries <- amqpClient.Delivery{Acknowledger: acknowledger, DeliveryTag: 2}
+
+	appCtx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		trigger.receive(appCtx, deliveries, make(chan *amqpClient.Error, 1))
+		close(stopped)
+	}()
+
+	<-acknowledger.done
+	<-acknowledger.done
+	acknowledger.lock.Lock()
+	assert.Equal(t, []uint64{1}, acknowledger.acknowledged)
+	assert.Equal(t, []uint64{2}, acknowledger.rejected, "empty message must be rejected")
+	acknowledger.lock.Unlock()
+
+	cancel()
+	<-stopped
+}
+
+func TestPublishResponseNotConnected(t *testing.T) {
+	trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+	err := trigger.publish("", "response", amqpClient.Publishing{Body: []byte("response")})
+	assert.Equal(t, amqpClient.ErrClosed, err)
+}
@@ -24,6 +24,7 @@ import (
 	"io"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
@@ -70,6 +71,7 @@ func (trigger *Trigger) Initialize(_ *sync.WaitGroup, _ context.Context, backgro
 
 func (trigger *Trigger) requestHandler(writer http.ResponseWriter, r *http.Request) {
 	lc := bootstrapContainer.LoggingClientFrom(trigger.dic.Get)
+	received := time.Now()
 	defer func() { _ = r.Body.Close() }()
 
 	contentType := r.Header.Get(common.ContentType)
@@ -87,6 +89,13 @@ func (trigger *Trigger) requestHandler(writer http.ResponseWriter, r *http.Reque
 	correlationID := r.Header.Get(common.CorrelationHeader)
 
 	appContext := appfunction.NewContext(correlationID, trigger.dic, contentType)
+	// The pipeline stops if the client gives up waiting for the response
+	appContext.SetContext(r.Context())
+	appContext.SetReceived(received)
+
+	if signature := r.Header.Get(internal.SignatureHeader); len(signature) > 0 {
+		appContext.AddValue(interfaces.SIGNATURE, signature)
+	}
 
 	lc.Trace("Received message from http", common.CorrelationHeader, correlationID)
 	lc.Debug("Received message from http", common.ContentType, contentType)
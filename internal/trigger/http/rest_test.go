@@ -18,9 +18,17 @@
 package http
 
 import (
-	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
@@ -42,3 +50,32 @@ func TestTriggerInitializeWitBackgroundChannel(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "background publishing not supported for services using HTTP trigger", err.Error())
 }
+
+func TestTriggerRequestHandlerSignature(t *testing.T) {
+	dic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+
+	var signature string
+	transform := func(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		signature, _ = ctx.GetValue(interfaces.SIGNATURE)
+		return false, util.NewHTTPStatusError(http.StatusUnauthorized, errors.New("signature mismatch"))
+	}
+
+	gr := &runtime.GolangRuntime{TargetType: &[]byte{}}
+	gr.Initialize(nil)
+	gr.SetTransforms([]interfaces.AppFunction{transform})
+	trigger := NewTrigger(dic, gr, nil)
+
+	request := httptest.NewRequest(http.MethodPost, internal.ApiTriggerRoute, strings.NewReader("data"))
+	request.Header.Set(internal.SignatureHeader, "abc123")
+	recorder := httptest.NewRecorder()
+
+	trigger.requestHandler(recorder, request)
+
+	assert.Equal(t, "abc123", signature)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, "signature mismatch", recorder.Body.String())
+}
@@ -0,0 +1,530 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/messaging"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/google/uuid"
+	kafkaGo "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	SaslMechanismPlain       = "plain"
+	SaslMechanismScramSHA256 = "scram-sha-256"
+	SaslMechanismScramSHA512 = "scram-sha-512"
+
+	defaultConnectTimeout = 30 * time.Second
+	// partitionQueueSize is how many fetched messages are queued for each partition while its previous messages are
+	// processed
+	partitionQueueSize = 10
+)
+
+// failedRetryInterval is how long a partition is paused after its message failed before the message is retried
+var failedRetryInterval = 5 * time.Second
+
+// messageReader is the part of kafka-go's Reader used by the trigger, so the consumer group can be faked in tests
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafkaGo.Message, error)
+	CommitMessages(ctx context.Context, messages ...kafkaGo.Message) error
+	Stats() kafkaGo.ReaderStats
+	Close() error
+}
+
+// messageWriter is the part of kafka-go's Writer used by the trigger
+type messageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafkaGo.Message) error
+	Close() error
+}
+
+// fetchedMessage is a message queued for its partition's worker along with when it was fetched
+type fetchedMessage struct {
+	kafkaGo.Message
+	received time.Time
+}
+
+// Trigger implements Trigger to support consuming from Kafka topics as a member of a consumer group
+type Trigger struct {
+	dic         *di.Container
+	lc          logger.LoggingClient
+	runtime     *runtime.GolangRuntime
+	config      sdkCommon.KafkaConfig
+	brokers     []string
+	dialer      *kafkaGo.Dialer
+	readers     []messageReader
+	writers     map[string]messageWriter
+	writersLock sync.Mutex
+	newReader   func(config kafkaGo.ReaderConfig) messageReader
+	newWriter   func(config kafkaGo.WriterConfig) messageWriter
+}
+
+func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime) *Trigger {
+	return &Trigger{
+		dic:     dic,
+		runtime: runtime,
+		lc:      bootstrapContainer.LoggingClientFrom(dic.Get),
+		writers: make(map[string]messageWriter),
+		newReader: func(config kafkaGo.ReaderConfig) messageReader {
+			return kafkaGo.NewReader(config)
+		},
+		newWriter: func(config kafkaGo.WriterConfig) messageWriter {
+			return kafkaGo.NewWriter(config)
+		},
+	}
+}
+
+// Initialize initializes the Trigger for a Kafka consumer group
+func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context, background <-chan interfaces.BackgroundMessage) (bootstrap.Deferred, error) {
+	// Convenience short cuts
+	lc := trigger.lc
+	config := container.ConfigurationFrom(trigger.dic.Get)
+	trigger.config = config.Trigger.Kafka
+
+	lc.Info("Initializing Kafka Trigger")
+
+	if background != nil {
+		return nil, errors.New("background publishing not supported for services using Kafka trigger")
+	}
+
+	trigger.brokers = util.DeleteEmptyAndTrim(strings.FieldsFunc(trigger.config.Brokers, util.SplitComma))
+	if len(trigger.brokers) == 0 {
+		return nil, errors.New("missing Brokers for Kafka Trigger. Must be present in [Trigger.Kafka] section.")
+	}
+
+	if len(strings.TrimSpace(trigger.config.GroupId)) == 0 {
+		return nil, errors.New("missing GroupId for Kafka Trigger. Must be present in [Trigger.Kafka] section.")
+	}
+
+	topics := util.DeleteEmptyAndTrim(strings.FieldsFunc(trigger.config.SubscribeTopics, util.SplitComma))
+	if len(topics) == 0 {
+		return nil, errors.New("missing SubscribeTopics for Kafka Trigger. Must be present in [Trigger.Kafka] section.")
+	}
+
+	dialer, err := trigger.createDialer()
+	if err != nil {
+		return nil, err
+	}
+	trigger.dialer = dialer
+
+	// Each topic has its own reader in the consumer group, which is how kafka-go consumes multiple topics
+	for _, topic := range topics {
+		trigger.readers = append(trigger.readers, trigger.newReader(kafkaGo.ReaderConfig{
+			Brokers: trigger.brokers,
+			GroupID: trigger.config.GroupId,
+			Topic:   topic,
+			Dialer:  trigger.dialer,
+		}))
+	}
+
+	lc.Infof("Subscribed to topic(s) '%s' in consumer group '%s' for Kafka trigger", trigger.config.SubscribeTopics, trigger.config.GroupId)
+
+	if len(trigger.config.PublishTopic) > 0 {
+		lc.Infof("Publishing to topic '%s' for Kafka trigger", trigger.config.PublishTopic)
+	}
+
+	for _, reader := range trigger.readers {
+		appWg.Add(1)
+		go func(reader messageReader) {
+			defer appWg.Done()
+			trigger.consume(appCtx, reader)
+		}(reader)
+	}
+
+	deferred := func() {
+		lc.Info("Disconnecting from Kafka brokers for Kafka trigger")
+		for _, reader := range trigger.readers {
+			_ = reader.Close()
+		}
+
+		trigger.writersLock.Lock()
+		defer trigger.writersLock.Unlock()
+		for _, writer := range trigger.writers {
+			_ = writer.Close()
+		}
+	}
+
+	return deferred, nil
+}
+
+// createDialer creates the dialer used to connect to the brokers with the TLS and SASL settings from the
+// configuration and the secret store
+func (trigger *Trigger) createDialer() (*kafkaGo.Dialer, error) {
+	dialer := &kafkaGo.Dialer{
+		ClientID:  trigger.config.GroupId,
+		Timeout:   defaultConnectTimeout,
+		DualStack: true,
+	}
+
+	if len(trigger.config.ConnectTimeout) > 0 {
+		var err error
+		dialer.Timeout, err = time.ParseDuration(trigger.config.ConnectTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kafka ConnectTimeout '%s': %s", trigger.config.ConnectTimeout, err.Error())
+		}
+	}
+
+	authMode := strings.ToLower(trigger.config.AuthMode)
+	switch authMode {
+	case "":
+		authMode = messaging.AuthModeNone
+	case messaging.AuthModeNone, messaging.AuthModeUsernamePassword, messaging.AuthModeCert, messaging.AuthModeCA:
+	default:
+		return nil, fmt.Errorf("invalid AuthMode '%s' for Kafka trigger. Must be '%s', '%s', '%s' or '%s'",
+			trigger.config.AuthMode,
+			messaging.AuthModeNone,
+			messaging.AuthModeUsernamePassword,
+			messaging.AuthModeCert,
+			messaging.AuthModeCA)
+	}
+
+	var tlsConfig *tls.Config
+	if trigger.config.UseTLS || authMode == messaging.AuthModeCert || authMode == messaging.AuthModeCA {
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: trigger.config.SkipCertVerify,
+		}
+	}
+
+	if authMode == messaging.AuthModeNone {
+		dialer.TLS = tlsConfig
+		return dialer, nil
+	}
+
+	// Must provide a dummy AppFunctionContext which will provide access to GetSecret
+	appContext := appfunction.NewContext("", trigger.dic, "")
+	secretData, err := messaging.GetSecretData(authMode, trigger.config.SecretPath, appContext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get secrets for Kafka trigger from secret path '%s': %s", trigger.config.SecretPath, err.Error())
+	}
+	if err := messaging.ValidateSecretData(authMode, trigger.config.SecretPath, secretData); err != nil {
+		return nil, err
+	}
+
+	switch authMode {
+	case messaging.AuthModeUsernamePassword:
+		mechanism, err := trigger.createSaslMechanism(secretData.Username, secretData.Password)
+		if err != nil {
+			return nil, err
+		}
+		dialer.SASLMechanism = mechanism
+
+	case messaging.AuthModeCert:
+		cert, err := tls.X509KeyPair(secretData.CertPemBlock, secretData.KeyPemBlock)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate for Kafka trigger: %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if tlsConfig != nil && len(secretData.CaPemBlock) > 0 {
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(secretData.CaPemBlock) {
+			return nil, errors.New("error parsing CA PEM block for Kafka trigger")
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	dialer.TLS = tlsConfig
+	return dialer, nil
+}
+
+func (trigger *Trigger) createSaslMechanism(username string, password string) (sasl.Mechanism, error) {
+	switch strings.ToLower(trigger.config.SaslMechanism) {
+	case "", SaslMechanismPlain:
+		return plain.Mechanism{Username: username, Password: password}, nil
+
+	case SaslMechanismScramSHA256:
+		return scram.Mechanism(scram.SHA256, username, password)
+
+	case SaslMechanismScramSHA512:
+		return scram.Mechanism(scram.SHA512, username, password)
+
+	default:
+		return nil, fmt.Errorf("invalid SaslMechanism '%s' for Kafka trigger. Must be '%s', '%s' or '%s'",
+			trigger.config.SaslMechanism,
+			SaslMechanismPlain,
+			SaslMechanismScramSHA256,
+			SaslMechanismScramSHA512)
+	}
+}
+
+// consume fetches the messages assigned to the service by the consumer group until the service is stopped. The
+// messages of each partition are processed in order by the partition's own worker, so the partitions are processed
+// concurrently. The workers belong to the consumer group generation, so when the group rebalances they are stopped,
+// once the messages they are processing are done, and the partitions still assigned get new workers.
+func (trigger *Trigger) consume(appCtx context.Context, reader messageReader) {
+	workers := &sync.WaitGroup{}
+	partitions := make(map[int]chan fetchedMessage)
+
+	var generation context.Context
+	var endGeneration context.CancelFunc
+	startWorkers := func() {
+		generation, endGeneration = context.WithCancel(appCtx)
+	}
+	stopWorkers := func() {
+		endGeneration()
+		for partition, messages := range partitions {
+			close(messages)
+			delete(partitions, partition)
+		}
+		workers.Wait()
+	}
+
+	startWorkers()
+	defer stopWorkers()
+
+	for {
+		message, err := reader.FetchMessage(appCtx)
+		if err != nil {
+			if appCtx.Err() == nil {
+				trigger.lc.Errorf("Unable to fetch Kafka messages, exiting: %s", err.Error())
+			} else {
+				trigger.lc.Info("Exiting waiting for Kafka messages")
+			}
+			return
+		}
+
+		// The partitions may have been revoked, and the offsets of the messages still queued can no longer be
+		// committed, so they are left to the partitions' new owners
+		if reader.Stats().Rebalances > 0 && len(partitions) > 0 {
+			trigger.lc.Infof("Kafka consumer group rebalanced, restarting the workers of %d partition(s)", len(partitions))
+			stopWorkers()
+			startWorkers()
+		}
+
+		messages, found := partitions[message.Partition]
+		if !found {
+			messages = make(chan fetchedMessage, partitionQueueSize)
+			partitions[message.Partition] = messages
+
+			workers.Add(1)
+			go func(generation context.Context) {
+				defer workers.Done()
+				trigger.processPartition(appCtx, generation, reader, messages)
+			}(generation)
+		}
+
+		select {
+		case messages <- fetchedMessage{Message: message, received: time.Now()}:
+		case <-appCtx.Done():
+			trigger.lc.Info("Exiting waiting for Kafka messages")
+			return
+		}
+	}
+}
+
+// processPartition processes the messages of a partition in order, committing the offset of each message once the
+// functions pipeline has processed it successfully. When a message fails the partition is paused and the message is
+// retried every failedRetryInterval until it succeeds, rather than being skipped, or until the generation ends, in
+// which case the message is redelivered to the partition's owner since its offset isn't committed.
+func (trigger *Trigger) processPartition(appCtx context.Context, generation context.Context, reader messageReader, messages <-chan fetchedMessage) {
+	for message := range messages {
+		if generation.Err() != nil {
+			// The messages not yet processed are redelivered since their offsets aren't committed
+			return
+		}
+
+		for attempt := 1; ; attempt++ {
+			err := trigger.dispatch(appCtx, message)
+			if err == nil {
+				if attempt > 1 {
+					trigger.lc.Infof("Resuming partition %d of Kafka topic '%s' after message at offset %d succeeded on attempt %d",
+						message.Partition,
+						message.Topic,
+						message.Offset,
+						attempt)
+				}
+				break
+			}
+
+			if attempt == 1 && generation.Err() == nil {
+				trigger.lc.Errorf("Pausing partition %d of Kafka topic '%s' after message at offset %d failed, retrying every %s",
+					message.Partition,
+					message.Topic,
+					message.Offset,
+					failedRetryInterval.String())
+			}
+
+			select {
+			case <-time.After(failedRetryInterval):
+			case <-generation.Done():
+				// The message is redelivered since its offset isn't committed
+				return
+			}
+		}
+
+		if err := reader.CommitMessages(appCtx, message.Message); err != nil && appCtx.Err() == nil {
+			trigger.lc.Errorf("Unable to commit offset %d for partition %d of Kafka topic '%s': %s",
+				message.Offset,
+				message.Partition,
+				message.Topic,
+				err.Error())
+		}
+	}
+}
+
+// dispatch processes the message on the runtime's worker pool, waiting for it to be processed so that the messages of
+// the partition are processed in order and its offset can be committed
+func (trigger *Trigger) dispatch(appCtx context.Context, message fetchedMessage) error {
+	done := make(chan error, 1)
+	envelope := types.MessageEnvelope{
+		ContentType:   contentType(message.Value),
+		Payload:       message.Value,
+		ReceivedTopic: message.Topic,
+	}
+
+	dispatched := trigger.runtime.Dispatch(envelope, func() {
+		done <- trigger.processMessage(message.Message, message.received)
+	})
+	if !dispatched {
+		return errors.New("message not processed since the service is stopping")
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-appCtx.Done():
+		return appCtx.Err()
+	}
+}
+
+func (trigger *Trigger) processMessage(message kafkaGo.Message, received time.Time) error {
+	lc := trigger.lc
+
+	data := message.Value
+	if len(data) == 0 {
+		lc.Warnf("Received empty message from Kafka trigger on topic '%s'", message.Topic)
+		return nil
+	}
+
+	contentType := contentType(data)
+
+	correlationID := ""
+	for _, header := range message.Headers {
+		if header.Key == common.CorrelationHeader {
+			correlationID = string(header.Value)
+		}
+	}
+	if len(correlationID) == 0 {
+		correlationID = uuid.New().String()
+	}
+
+	appContext := appfunction.NewContext(correlationID, trigger.dic, contentType)
+	appContext.SetReceived(received)
+
+	lc.Debugf("Received message from Kafka Trigger with %d bytes from topic '%s' partition %d offset %d. Content-Type=%s",
+		len(data),
+		message.Topic,
+		message.Partition,
+		message.Offset,
+		contentType)
+	lc.Tracef("%s=%s", common.CorrelationHeader, correlationID)
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: correlationID,
+		ContentType:   contentType,
+		Payload:       data,
+		ReceivedTopic: message.Topic,
+	}
+
+	messageError := trigger.runtime.ProcessMessage(appContext, envelope)
+	if messageError != nil {
+		// ProcessMessage logs the error, so no need to log it here.
+		return messageError.Err
+	}
+
+	topic := trigger.config.PublishTopic
+	if responseTopic := appContext.ResponseTopic(); len(responseTopic) > 0 {
+		topic = responseTopic
+	}
+	if len(appContext.ResponseData()) == 0 || len(topic) == 0 {
+		return nil
+	}
+
+	formattedTopic, err := appContext.ApplyValues(topic)
+	if err != nil {
+		lc.Errorf("could not format topic '%s' for Kafka trigger output: %s", topic, err.Error())
+		return nil
+	}
+
+	response := kafkaGo.Message{
+		Value: appContext.ResponseData(),
+		Headers: []kafkaGo.Header{
+			{Key: common.CorrelationHeader, Value: []byte(correlationID)},
+		},
+	}
+
+	if err := trigger.writer(formattedTopic).WriteMessages(context.Background(), response); err != nil {
+		lc.Errorf("could not publish to topic '%s' for Kafka trigger: %s", formattedTopic, err.Error())
+		return nil
+	}
+
+	lc.Trace("Sent Kafka Trigger response message", common.CorrelationHeader, correlationID)
+	lc.Debugf("Sent Kafka Trigger response message on topic '%s' with %d bytes", formattedTopic, len(appContext.ResponseData()))
+	return nil
+}
+
+// contentType returns the content type of the message payload, which is assumed to be CBOR when it isn't JSON
+func contentType(data []byte) string {
+	if len(data) > 0 && data[0] != byte('{') && data[0] != byte('[') {
+		return common.ContentTypeCBOR
+	}
+	return common.ContentTypeJSON
+}
+
+// writer returns the writer for the topic, which is created on first use since the topic can contain placeholders
+func (trigger *Trigger) writer(topic string) messageWriter {
+	trigger.writersLock.Lock()
+	defer trigger.writersLock.Unlock()
+
+	writer, found := trigger.writers[topic]
+	if !found {
+		writer = trigger.newWriter(kafkaGo.WriterConfig{
+			Brokers: trigger.brokers,
+			Topic:   topic,
+			Dialer:  trigger.dialer,
+		})
+		trigger.writers[topic] = writer
+	}
+
+	return writer
+}
@@ -0,0 +1,422 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+
+	kafkaGo "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dic *di.Container
+
+func TestMain(m *testing.M) {
+	dic = di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+	m.Run()
+}
+
+// fakeReader delivers the queued messages and records the committed ones
+type fakeReader struct {
+	messages   chan kafkaGo.Message
+	committed  chan kafkaGo.Message
+	rebalances int64
+}
+
+func newFakeReader(messages ...kafkaGo.Message) *fakeReader {
+	reader := &fakeReader{
+		messages:  make(chan kafkaGo.Message, len(messages)),
+		committed: make(chan kafkaGo.Message, len(messages)),
+	}
+	for _, message := range messages {
+		reader.messages <- message
+	}
+	return reader
+}
+
+func (reader *fakeReader) FetchMessage(ctx context.Context) (kafkaGo.Message, error) {
+	select {
+	case message := <-reader.messages:
+		return message, nil
+	case <-ctx.Done():
+		return kafkaGo.Message{}, ctx.Err()
+	}
+}
+
+func (reader *fakeReader) CommitMessages(_ context.Context, messages ...kafkaGo.Message) error {
+	for _, message := range messages {
+		reader.committed <- message
+	}
+	return nil
+}
+
+func (reader *fakeReader) Stats() kafkaGo.ReaderStats {
+	return kafkaGo.ReaderStats{Rebalances: atomic.SwapInt64(&reader.rebalances, 0)}
+}
+
+func (reader *fakeReader) Close() error {
+	return nil
+}
+
+// fakeWriter records the messages written to its topic
+type fakeWriter struct {
+	topic   string
+	written chan kafkaGo.Message
+}
+
+func (writer *fakeWriter) WriteMessages(_ context.Context, messages ...kafkaGo.Message) error {
+	for _, message := range messages {
+		writer.written <- message
+	}
+	return nil
+}
+
+func (writer *fakeWriter) Close() error {
+	return nil
+}
+
+func updateConfig(kafkaConfig sdkCommon.KafkaConfig) {
+	config := &sdkCommon.ConfigurationStruct{
+		Trigger: sdkCommon.TriggerInfo{
+			Type:  "kafka",
+			Kafka: kafkaConfig,
+		},
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+}
+
+func TestInitializeErrors(t *testing.T) {
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", "kafka").Return(map[string]string{"username": "user", "password": "secret"}, nil)
+	mockSP.On("GetSecret", "missing").Return(nil, errors.New("not found"))
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	valid := sdkCommon.KafkaConfig{Brokers: "localhost:9092", GroupId: "app", SubscribeTopics: "events"}
+	withChanges := func(change func(config *sdkCommon.KafkaConfig)) sdkCommon.KafkaConfig {
+		config := valid
+		change(&config)
+		return config
+	}
+
+	tests := []struct {
+		Name          string
+		Config        sdkCommon.KafkaConfig
+		Background    chan interfaces.BackgroundMessage
+		ErrorContains string
+	}{
+		{"Background not supported", valid, make(chan interfaces.BackgroundMessage), "background"},
+		{"Missing brokers", withChanges(func(config *sdkCommon.KafkaConfig) { config.Brokers = " , " }), nil, "Brokers"},
+		{"Missing group", withChanges(func(config *sdkCommon.KafkaConfig) { config.GroupId = "" }), nil, "GroupId"},
+		{"Missing topics", withChanges(func(config *sdkCommon.KafkaConfig) { config.SubscribeTopics = "" }), nil, "SubscribeTopics"},
+		{"Bad ConnectTimeout", withChanges(func(config *sdkCommon.KafkaConfig) { config.ConnectTimeout = "bogus" }), nil, "ConnectTimeout"},
+		{"Bad AuthMode", withChanges(func(config *sdkCommon.KafkaConfig) { config.AuthMode = "bogus" }), nil, "AuthMode"},
+		{"Missing secrets", withChanges(func(config *sdkCommon.KafkaConfig) {
+			config.AuthMode = "usernamepassword"
+			config.SecretPath = "missing"
+		}), nil, "unable to get secrets"},
+		{"Bad SaslMechanism", withChanges(func(config *sdkCommon.KafkaConfig) {
+			config.AuthMode = "usernamepassword"
+			config.SecretPath = "kafka"
+			config.SaslMechanism = "bogus"
+		}), nil, "SaslMechanism"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			updateConfig(test.Config)
+
+			trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+			_, err := trigger.Initialize(&sync.WaitGroup{}, context.Background(), test.Background)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ErrorContains)
+		})
+	}
+}
+
+func TestCreateDialer(t *testing.T) {
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", "kafka").Return(map[string]string{"username": "user", "password": "secret"}, nil)
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	tests := []struct {
+		Name              string
+		Config            sdkCommon.KafkaConfig
+		ExpectedMechanism string
+		ExpectTLS         bool
+	}{
+		{"None", sdkCommon.KafkaConfig{}, "", false},
+		{"None with TLS", sdkCommon.KafkaConfig{UseTLS: true}, "", true},
+		{"Plain", sdkCommon.KafkaConfig{AuthMode: "usernamepassword", SecretPath: "kafka"}, "PLAIN", false},
+		{"SCRAM with TLS", sdkCommon.KafkaConfig{AuthMode: "usernamepassword", SecretPath: "kafka", SaslMechanism: SaslMechanismScramSHA512, UseTLS: true}, "SCRAM-SHA-512", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+			trigger.config = test.Config
+
+			dialer, err := trigger.createDialer()
+			require.NoError(t, err)
+
+			if len(test.ExpectedMechanism) > 0 {
+				require.NotNil(t, dialer.SASLMechanism)
+				assert.Equal(t, test.ExpectedMechanism, dialer.SASLMechanism.Name())
+			} else {
+				assert.Nil(t, dialer.SASLMechanism)
+			}
+			assert.Equal(t, test.ExpectTLS, dialer.TLS != nil)
+		})
+	}
+}
+
+func TestInitializeAndConsume(t *testing.T) {
+	updateConfig(sdkCommon.KafkaConfig{
+		Brokers:         "localhost:9092",
+		GroupId:         "app",
+		SubscribeTopics: "edgex-events",
+		PublishTopic:    "edgex-response-{devicename}",
+	})
+
+	event := dtos.NewEvent("thermostat", "LivingRoomThermostat", "temperature")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	payload, err := json.Marshal(requests.NewAddEventRequest(event))
+	require.NoError(t, err)
+
+	failing := dtos.NewEvent("thermostat", "FailingThermostat", "temperature")
+	_ = failing.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	failingPayload, err := json.Marshal(requests.NewAddEventRequest(failing))
+	require.NoError(t, err)
+
+	failures := int32(2)
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		if data.(dtos.Event).DeviceName == failing.DeviceName && atomic.AddInt32(&failures, -1) >= 0 {
+			return false, errors.New("failed")
+		}
+		appContext.SetResponseData([]byte("response"))
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{TargetType: &dtos.Event{}}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	defer func(interval time.Duration) { failedRetryInterval = interval }(failedRetryInterval)
+	failedRetryInterval = 10 * time.Millisecond
+
+	// Partition 0's second message fails twice, pausing the partition until it is retried successfully
+	reader := newFakeReader(
+		kafkaGo.Message{Topic: "edgex-events", Partition: 0, Offset: 1, Value: payload},
+		kafkaGo.Message{Topic: "edgex-events", Partition: 1, Offset: 1, Value: payload},
+		kafkaGo.Message{Topic: "edgex-events", Partition: 0, Offset: 2, Value: failingPayload},
+		kafkaGo.Message{Topic: "edgex-events", Partition: 1, Offset: 2, Value: payload},
+		kafkaGo.Message{Topic: "edgex-events", Partition: 0, Offset: 3, Value: payload},
+	)
+	writer := &fakeWriter{written: make(chan kafkaGo.Message, 10)}
+	var topics []string
+
+	trigger := NewTrigger(dic, goRuntime)
+	trigger.newReader = func(config kafkaGo.ReaderConfig) messageReader {
+		assert.Equal(t, "app", config.GroupID)
+		assert.Equal(t, "edgex-events", config.Topic)
+		return reader
+	}
+	trigger.newWriter = func(config kafkaGo.WriterConfig) messageWriter {
+		topics = append(topics, config.Topic)
+		return writer
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	deferred, err := trigger.Initialize(wg, ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, deferred)
+
+	committed := map[int][]int64{}
+	for len(committed[0])+len(committed[1]) < 5 {
+		select {
+		case message := <-reader.committed:
+			committed[message.Partition] = append(committed[message.Partition], message.Offset)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "messages not committed")
+		}
+	}
+
+	for index := 0; index < 5; index++ {
+		select {
+		case response := <-writer.written:
+			assert.Equal(t, []byte("response"), response.Value)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "response not published")
+		}
+	}
+
+	cancel()
+	wg.Wait()
+	deferred()
+
+	assert.Equal(t, []int64{1, 2, 3}, committed[0], "offsets must be committed in order after the retry")
+	assert.Equal(t, []int64{1, 2}, committed[1])
+	assert.Equal(t, int32(-1), atomic.LoadInt32(&failures), "failed message must be retried until it succeeds")
+	assert.ElementsMatch(t, []string{"edgex-response-LivingRoomThermostat", "edgex-response-FailingThermostat"}, topics)
+}
+
+func TestConsumeRebalance(t *testing.T) {
+	updateConfig(sdkCommon.KafkaConfig{
+		Brokers:         "localhost:9092",
+		GroupId:         "app",
+		SubscribeTopics: "edgex-events",
+	})
+
+	event := dtos.NewEvent("thermostat", "LivingRoomThermostat", "temperature")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	payload, err := json.Marshal(requests.NewAddEventRequest(event))
+	require.NoError(t, err)
+
+	failing := dtos.NewEvent("thermostat", "FailingThermostat", "temperature")
+	_ = failing.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	failingPayload, err := json.Marshal(requests.NewAddEventRequest(failing))
+	require.NoError(t, err)
+
+	failed := make(chan struct{}, 100)
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		if data.(dtos.Event).DeviceName == failing.DeviceName {
+			failed <- struct{}{}
+			return false, errors.New("failed")
+		}
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{TargetType: &dtos.Event{}}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	defer func(interval time.Duration) { failedRetryInterval = interval }(failedRetryInterval)
+	failedRetryInterval = 10 * time.Millisecond
+
+	reader := &fakeReader{
+		messages:  make(chan kafkaGo.Message, 2),
+		committed: make(chan kafkaGo.Message, 2),
+	}
+	reader.messages <- kafkaGo.Message{Topic: "edgex-events", Partition: 0, Offset: 1, Value: failingPayload}
+
+	trigger := NewTrigger(dic, goRuntime)
+	trigger.newReader = func(config kafkaGo.ReaderConfig) messageReader {
+		return reader
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	deferred, err := trigger.Initialize(wg, ctx, nil)
+	require.NoError(t, err)
+
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "message not processed")
+	}
+
+	// Partition 0 is paused retrying its failed message when the group rebalances, after which the partition's
+	// messages must be processed by a new worker rather than queued behind the failed message
+	atomic.StoreInt64(&reader.rebalances, 1)
+	reader.messages <- kafkaGo.Message{Topic: "edgex-events", Partition: 0, Offset: 2, Value: payload}
+
+	select {
+	case message := <-reader.committed:
+		assert.Equal(t, int64(2), message.Offset)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "message not committed after rebalance")
+	}
+
+	cancel()
+	wg.Wait()
+	deferred()
+
+	assert.Len(t, reader.committed, 0, "failed message of the previous generation must not be committed")
+}
+
+func TestProcessMessageResponseTopic(t *testing.T) {
+	updateConfig(sdkCommon.KafkaConfig{PublishTopic: "edgex-response"})
+
+	event := dtos.NewEvent("thermostat", "LivingRoomThermostat", "temperature")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	payload, err := json.Marshal(requests.NewAddEventRequest(event))
+	require.NoError(t, err)
+
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		appContext.SetResponseTopic("alerts-{devicename}")
+		appContext.SetResponseData([]byte("response"))
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{TargetType: &dtos.Event{}}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	writer := &fakeWriter{written: make(chan kafkaGo.Message, 1)}
+
+	trigger := NewTrigger(dic, goRuntime)
+	trigger.config = sdkCommon.KafkaConfig{PublishTopic: "edgex-response"}
+	trigger.newWriter = func(config kafkaGo.WriterConfig) messageWriter {
+		writer.topic = config.Topic
+		return writer
+	}
+
+	err = trigger.processMessage(kafkaGo.Message{Topic: "edgex-events", Value: payload}, time.Now())
+	require.NoError(t, err)
+
+	require.Len(t, writer.written, 1)
+	assert.Equal(t, "alerts-LivingRoomThermostat", writer.topic)
+}
@@ -0,0 +1,211 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package messagebus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+const (
+	defaultFailoverErrorThreshold = 3
+	defaultFailoverRetryInterval  = 5 * time.Second
+	// maxHeldPublishes is the most messages held for resubmission while failing over, after which the oldest are discarded
+	maxHeldPublishes = 1000
+)
+
+// failover tracks the health of the MessageBus connection and the broker endpoints to switch between when it fails
+type failover struct {
+	endpoints     []types.MessageBusConfig
+	current       int
+	threshold     uint64
+	retryInterval time.Duration
+	lastFailover  time.Time
+	// receiveErrors is the number of consecutive errors receiving from the MessageBus
+	receiveErrors uint64
+	heldLock      sync.Mutex
+	held          []heldPublish
+}
+
+// heldPublish is a message which failed to publish and is resubmitted once reconnected
+type heldPublish struct {
+	envelope types.MessageEnvelope
+	topic    string
+}
+
+// newFailover returns the failover for the configured standby hosts. The first endpoint is the configured
+// SubscribeHost and PublishHost, followed by an endpoint for each standby host.
+func newFailover(clientConfig types.MessageBusConfig, config sdkCommon.MessageBusFailoverInfo) (*failover, error) {
+	result := &failover{
+		endpoints:     []types.MessageBusConfig{clientConfig},
+		threshold:     defaultFailoverErrorThreshold,
+		retryInterval: defaultFailoverRetryInterval,
+	}
+
+	if config.ErrorThreshold > 0 {
+		result.threshold = uint64(config.ErrorThreshold)
+	}
+
+	if len(config.RetryInterval) > 0 {
+		var err error
+		result.retryInterval, err = time.ParseDuration(config.RetryInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MessageBus Failover RetryInterval '%s': %s", config.RetryInterval, err.Error())
+		}
+	}
+
+	for _, hostPort := range util.DeleteEmptyAndTrim(strings.FieldsFunc(config.Hosts, util.SplitComma)) {
+		host, portText, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MessageBus Failover host '%s'. Must be host:port: %s", hostPort, err.Error())
+		}
+
+		port, err := strconv.Atoi(portText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port for MessageBus Failover host '%s': %s", hostPort, err.Error())
+		}
+
+		endpoint := clientConfig
+		endpoint.SubscribeHost.Host = host
+		endpoint.SubscribeHost.Port = port
+		if len(endpoint.PublishHost.Host) > 0 {
+			endpoint.PublishHost.Host = host
+			endpoint.PublishHost.Port = port
+		}
+
+		result.endpoints = append(result.endpoints, endpoint)
+	}
+
+	return result, nil
+}
+
+// received resets the count of consecutive receive errors
+func (failover *failover) received() {
+	atomic.StoreUint64(&failover.receiveErrors, 0)
+}
+
+// receiveFailed counts the receive error, returning true once the threshold of consecutive errors is reached
+func (failover *failover) receiveFailed() bool {
+	return atomic.AddUint64(&failover.receiveErrors, 1) >= failover.threshold
+}
+
+// hold keeps the message to resubmit once reconnected, discarding the oldest held message when full
+func (failover *failover) hold(lc logger.LoggingClient, envelope types.MessageEnvelope, topic string) {
+	failover.heldLock.Lock()
+	defer failover.heldLock.Unlock()
+
+	if len(failover.held) >= maxHeldPublishes {
+		oldest := failover.held[0]
+		failover.held = failover.held[1:]
+		lc.Warnf("Too many messages held for MessageBus failover, discarded message with %s=%s",
+			common.CorrelationHeader,
+			oldest.envelope.CorrelationID)
+	}
+
+	failover.held = append(failover.held, heldPublish{envelope: envelope, topic: topic})
+}
+
+// takeHeld removes and returns the held messages
+func (failover *failover) takeHeld() []heldPublish {
+	failover.heldLock.Lock()
+	defer failover.heldLock.Unlock()
+
+	held := failover.held
+	failover.held = nil
+	return held
+}
+
+// failover disconnects from the failed broker and connects to the next endpoint, cycling through the endpoints until
+// connected or the service is stopped. Failovers are at least RetryInterval apart so a broker which accepts
+// connections but then fails isn't retried in a tight loop.
+func (trigger *Trigger) failover(appCtx context.Context, lc logger.LoggingClient) bool {
+	failover := trigger.failoverState
+
+	if err := trigger.getClient().Disconnect(); err != nil {
+		lc.Debugf("Unable to disconnect from failed MessageBus: %s", err.Error())
+	}
+
+	for {
+		select {
+		case <-appCtx.Done():
+			return false
+		case <-time.After(time.Until(failover.lastFailover.Add(failover.retryInterval))):
+		}
+
+		failover.lastFailover = time.Now()
+
+		// The current endpoint is tried last, which also covers the broker restarting when there are no standby hosts
+		for attempt := 1; attempt <= len(failover.endpoints); attempt++ {
+			index := (failover.current + attempt) % len(failover.endpoints)
+			endpoint := failover.endpoints[index]
+
+			client, messageErrors, err := trigger.connect(endpoint)
+			if err != nil {
+				lc.Errorf("Unable to fail over to MessageBus @ %s://%s:%d: %s",
+					endpoint.SubscribeHost.Protocol,
+					endpoint.SubscribeHost.Host,
+					endpoint.SubscribeHost.Port,
+					err.Error())
+				continue
+			}
+
+			failover.current = index
+			failover.received()
+			trigger.setClient(client, messageErrors)
+
+			lc.Infof("Failed over to MessageBus @ %s://%s:%d",
+				endpoint.SubscribeHost.Protocol,
+				endpoint.SubscribeHost.Host,
+				endpoint.SubscribeHost.Port)
+
+			trigger.resubmitHeld(lc)
+			return true
+		}
+	}
+}
+
+// resubmitHeld publishes the messages held while the MessageBus connection was failing
+func (trigger *Trigger) resubmitHeld(lc logger.LoggingClient) {
+	held := trigger.failoverState.takeHeld()
+	if len(held) == 0 {
+		return
+	}
+
+	resubmitted := 0
+	for _, message := range held {
+		if err := trigger.publish(lc, message.envelope, message.topic); err != nil {
+			lc.Errorf("Failed to resubmit held message to bus, %v", err)
+			continue
+		}
+		resubmitted++
+	}
+
+	lc.Infof("Resubmitted %d of %d messages held during MessageBus failover", resubmitted, len(held))
+}
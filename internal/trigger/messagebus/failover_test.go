@@ -0,0 +1,247 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package messagebus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMessageClient records the messages published and allows receive errors to be sent to the trigger
+type fakeMessageClient struct {
+	host          string
+	publishErr    error
+	published     chan types.MessageEnvelope
+	messageErrors chan error
+	subscribed    chan struct{}
+	disconnected  chan struct{}
+}
+
+func newFakeMessageClient(host string) *fakeMessageClient {
+	return &fakeMessageClient{
+		host:         host,
+		published:    make(chan types.MessageEnvelope, 10),
+		subscribed:   make(chan struct{}),
+		disconnected: make(chan struct{}),
+	}
+}
+
+func (client *fakeMessageClient) Connect() error {
+	return nil
+}
+
+func (client *fakeMessageClient) Publish(message types.MessageEnvelope, _ string) error {
+	if client.publishErr != nil {
+		return client.publishErr
+	}
+	client.published <- message
+	return nil
+}
+
+func (client *fakeMessageClient) Subscribe(_ []types.TopicChannel, messageErrors chan error) error {
+	client.messageErrors = messageErrors
+	close(client.subscribed)
+	return nil
+}
+
+func (client *fakeMessageClient) Disconnect() error {
+	close(client.disconnected)
+	return nil
+}
+
+func TestNewFailover(t *testing.T) {
+	clientConfig := types.MessageBusConfig{
+		Type:          "redis",
+		SubscribeHost: types.HostInfo{Host: "redis", Port: 6379, Protocol: "redis"},
+		PublishHost:   types.HostInfo{Host: "redis", Port: 6379, Protocol: "redis"},
+	}
+
+	target, err := newFailover(clientConfig, sdkCommon.MessageBusFailoverInfo{Hosts: "replica-1:6380, replica-2:6381"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(defaultFailoverErrorThreshold), target.threshold)
+	assert.Equal(t, defaultFailoverRetryInterval, target.retryInterval)
+	require.Len(t, target.endpoints, 3)
+	assert.Equal(t, clientConfig, target.endpoints[0])
+	assert.Equal(t, types.HostInfo{Host: "replica-1", Port: 6380, Protocol: "redis"}, target.endpoints[1].SubscribeHost)
+	assert.Equal(t, types.HostInfo{Host: "replica-1", Port: 6380, Protocol: "redis"}, target.endpoints[1].PublishHost)
+	assert.Equal(t, types.HostInfo{Host: "replica-2", Port: 6381, Protocol: "redis"}, target.endpoints[2].SubscribeHost)
+
+	// PublishHost is only switched when set
+	clientConfig.PublishHost = types.HostInfo{}
+	target, err = newFailover(clientConfig, sdkCommon.MessageBusFailoverInfo{Hosts: "replica-1:6380", ErrorThreshold: 5, RetryInterval: "1s"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), target.threshold)
+	assert.Equal(t, time.Second, target.retryInterval)
+	assert.Empty(t, target.endpoints[1].PublishHost.Host)
+
+	tests := []struct {
+		Name          string
+		Config        sdkCommon.MessageBusFailoverInfo
+		ErrorContains string
+	}{
+		{"Missing port", sdkCommon.MessageBusFailoverInfo{Hosts: "replica-1"}, "host:port"},
+		{"Bad port", sdkCommon.MessageBusFailoverInfo{Hosts: "replica-1:bogus"}, "invalid port"},
+		{"Bad RetryInterval", sdkCommon.MessageBusFailoverInfo{RetryInterval: "bogus"}, "RetryInterval"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := newFailover(clientConfig, test.Config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ErrorContains)
+		})
+	}
+}
+
+func TestFailover_ReceiveFailed(t *testing.T) {
+	target, err := newFailover(types.MessageBusConfig{}, sdkCommon.MessageBusFailoverInfo{ErrorThreshold: 2})
+	require.NoError(t, err)
+
+	assert.False(t, target.receiveFailed())
+	target.received()
+	assert.False(t, target.receiveFailed())
+	assert.True(t, target.receiveFailed())
+}
+
+func TestFailover_Hold(t *testing.T) {
+	target, err := newFailover(types.MessageBusConfig{}, sdkCommon.MessageBusFailoverInfo{})
+	require.NoError(t, err)
+
+	for i := 0; i <= maxHeldPublishes; i++ {
+		target.hold(logger.NewMockClient(), types.MessageEnvelope{CorrelationID: string(rune('a' + i%26))}, "topic")
+	}
+
+	held := target.takeHeld()
+	require.Len(t, held, maxHeldPublishes)
+	assert.Equal(t, "b", held[0].envelope.CorrelationID, "oldest message expected to be discarded")
+	assert.Empty(t, target.takeHeld())
+}
+
+func TestInitializeAndFailover(t *testing.T) {
+	config := sdkCommon.ConfigurationStruct{
+		Trigger: sdkCommon.TriggerInfo{
+			Type: TriggerTypeMessageBus,
+			EdgexMessageBus: sdkCommon.MessageBusConfig{
+				Type: "redis",
+				PublishHost: sdkCommon.PublishHostInfo{
+					Host:         "redis",
+					Port:         6379,
+					Protocol:     "redis",
+					PublishTopic: "publish",
+				},
+				SubscribeHost: sdkCommon.SubscribeHostInfo{
+					Host:            "redis",
+					Port:            6379,
+					Protocol:        "redis",
+					SubscribeTopics: "events",
+				},
+				Failover: sdkCommon.MessageBusFailoverInfo{
+					Enabled:        true,
+					Hosts:          "replica:6380",
+					ErrorThreshold: 2,
+					RetryInterval:  "10ms",
+				},
+			},
+		},
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &config
+		},
+	})
+
+	primary := newFakeMessageClient("redis")
+	primary.publishErr = errors.New("connection refused")
+	replica := newFakeMessageClient("replica")
+
+	var lock sync.Mutex
+	var hosts []string
+	goRuntime := &runtime.GolangRuntime{}
+	goRuntime.Initialize(dic)
+	trigger := NewTrigger(dic, goRuntime)
+	trigger.newClient = func(clientConfig types.MessageBusConfig) (messaging.MessageClient, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		hosts = append(hosts, clientConfig.SubscribeHost.Host)
+		assert.Equal(t, clientConfig.SubscribeHost.Host, clientConfig.PublishHost.Host)
+		if clientConfig.SubscribeHost.Host == "replica" {
+			return replica, nil
+		}
+		return primary, nil
+	}
+
+	background := make(chan interfaces.BackgroundMessage)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	deferred, err := trigger.Initialize(wg, ctx, background)
+	require.NoError(t, err)
+
+	// Published while the primary is failing so is held until failed over
+	background <- mockBackgroundMessage{
+		Payload:        types.MessageEnvelope{CorrelationID: "held", ContentType: common.ContentTypeJSON},
+		DeliverToTopic: "background",
+	}
+
+	primary.messageErrors <- errors.New("connection lost")
+	primary.messageErrors <- errors.New("connection lost")
+
+	select {
+	case <-primary.disconnected:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "failed broker not disconnected")
+	}
+
+	select {
+	case <-replica.subscribed:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "standby broker not subscribed")
+	}
+
+	select {
+	case message := <-replica.published:
+		assert.Equal(t, "held", message.CorrelationID)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "held message not resubmitted")
+	}
+
+	lock.Lock()
+	assert.Equal(t, []string{"redis", "replica"}, hosts)
+	lock.Unlock()
+
+	cancel()
+	wg.Wait()
+	deferred()
+}
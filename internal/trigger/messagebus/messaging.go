@@ -21,13 +21,17 @@ import (
 	"errors"
 	"fmt"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
 	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/faultinjection"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
 
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
@@ -40,18 +44,31 @@ import (
 	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
 )
 
+// mqttQoSOption is the MessageBus Optional setting holding the QoS of the MQTT MessageBus client
+const mqttQoSOption = "Qos"
+
 // Trigger implements Trigger to support MessageBusData
 type Trigger struct {
-	dic     *di.Container
-	runtime *runtime.GolangRuntime
-	topics  []types.TopicChannel
-	client  messaging.MessageClient
+	dic           *di.Container
+	runtime       *runtime.GolangRuntime
+	topics        []types.TopicChannel
+	client        messaging.MessageClient
+	messageErrors chan error
+	clientLock    sync.RWMutex
+	injector      *faultinjection.Injector
+	failoverState *failover
+	clientConfig  types.MessageBusConfig
+	qosClients    map[byte]messaging.MessageClient
+	qosLock       sync.Mutex
+	// newClient creates the MessageBus client. Replaceable for unit testing.
+	newClient func(config types.MessageBusConfig) (messaging.MessageClient, error)
 }
 
 func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime) *Trigger {
 	return &Trigger{
-		dic:     dic,
-		runtime: runtime,
+		dic:       dic,
+		runtime:   runtime,
+		newClient: messaging.NewMessageClient,
 	}
 }
 
@@ -69,11 +86,29 @@ func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context
 		return nil, err
 	}
 
-	trigger.client, err = messaging.NewMessageClient(clientConfig)
+	trigger.clientConfig = clientConfig
+
+	if config.FaultInjection.Enabled && config.FaultInjection.PublishFailureRate > 0 {
+		trigger.injector, err = faultinjection.NewInjector(config.FaultInjection)
+		if err != nil {
+			return nil, err
+		}
+		lc.Warnf("FaultInjection enabled. MessageBus publishes will fail at a rate of %v", config.FaultInjection.PublishFailureRate)
+	}
+
+	trigger.client, err = trigger.createClient(clientConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.Trigger.EdgexMessageBus.Failover.Enabled {
+		trigger.failoverState, err = newFailover(clientConfig, config.Trigger.EdgexMessageBus.Failover)
+		if err != nil {
+			return nil, err
+		}
+		lc.Infof("MessageBus failover enabled with %d standby host(s)", len(trigger.failoverState.endpoints)-1)
+	}
+
 	subscribeTopics := strings.TrimSpace(config.Trigger.EdgexMessageBus.SubscribeHost.SubscribeTopics)
 
 	if len(subscribeTopics) == 0 {
@@ -86,7 +121,27 @@ func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context
 		}
 	}
 
-	messageErrors := make(chan error)
+	// Each topic has its own bounded queue when a queue size is configured
+	queues := make([]*messageQueue, len(trigger.topics))
+	if config.Trigger.EdgexMessageBus.QueueSize > 0 {
+		// The messages dropped are reported by the /metrics endpoint
+		stats := container.TriggerStatsTrackerFrom(trigger.dic.Get)
+		if stats == nil {
+			stats = telemetry.NewTriggerStatsTracker()
+		}
+
+		for index := range trigger.topics {
+			queues[index], err = newMessageQueue(
+				config.Trigger.EdgexMessageBus.QueueSize,
+				config.Trigger.EdgexMessageBus.OverflowPolicy,
+				stats)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	trigger.messageErrors = make(chan error)
 
 	err = trigger.client.Connect()
 	if err != nil {
@@ -109,8 +164,20 @@ func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context
 			config.Trigger.EdgexMessageBus.PublishHost.Port)
 	}
 
+	if sampler := container.ExecutionSamplerFrom(trigger.dic.Get); sampler != nil {
+		sampler.SetPublisher(func(envelope types.MessageEnvelope, topic string) error {
+			return trigger.publish(lc, envelope, topic)
+		})
+	}
+
 	// Need to have a go func for each subscription so we know with topic the data was received for.
-	for _, topic := range trigger.topics {
+	for index, topic := range trigger.topics {
+		queue := queues[index]
+		if queue != nil {
+			appWg.Add(1)
+			go trigger.processQueue(appWg, appCtx, lc, topic, queue)
+		}
+
 		appWg.Add(1)
 		go func(triggerTopic types.TopicChannel) {
 			defer appWg.Done()
@@ -122,7 +189,19 @@ func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context
 					lc.Infof("Exiting waiting for MessageBus '%s' topic messages", triggerTopic.Topic)
 					return
 				case msgs := <-triggerTopic.Messages:
-					go trigger.processMessage(lc, triggerTopic, msgs)
+					received := time.Now()
+					if trigger.failoverState != nil {
+						trigger.failoverState.received()
+					}
+
+					if queue != nil {
+						queue.enqueue(appCtx, lc, triggerTopic.Topic, msgs, received)
+						continue
+					}
+
+					trigger.runtime.Dispatch(msgs, func() {
+						trigger.processMessage(lc, triggerTopic, msgs, received)
+					})
 				}
 			}
 		}(topic)
@@ -138,15 +217,28 @@ func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context
 				lc.Info("Exiting waiting for MessageBus errors and background publishing")
 				return
 
-			case msgErr := <-messageErrors:
+			case msgErr := <-trigger.getMessageErrors():
 				lc.Errorf("Failed to receive message from bus, %v", msgErr)
 
+				if trigger.failoverState != nil && trigger.failoverState.receiveFailed() {
+					lc.Warn("MessageBus connection has failed, failing over")
+					if !trigger.failover(appCtx, lc) {
+						lc.Info("Exiting waiting for MessageBus errors and background publishing")
+						return
+					}
+				}
+
 			case bg := <-background:
 				go func() {
 					topic := bg.Topic()
 					msg := bg.Message()
 
-					err := trigger.client.Publish(msg, topic)
+					var err error
+					if qos, set := bg.QoS(); set {
+						err = trigger.publishWithQoS(lc, msg, topic, qos)
+					} else {
+						err = trigger.publish(lc, msg, topic)
+					}
 					if err != nil {
 						lc.Errorf("Failed to publish background Message to bus, %v", err)
 						return
@@ -159,25 +251,189 @@ func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context
 		}
 	}()
 
-	if err := trigger.client.Subscribe(trigger.topics, messageErrors); err != nil {
+	if err := trigger.client.Subscribe(trigger.topics, trigger.messageErrors); err != nil {
 		return nil, fmt.Errorf("failed to subscribe to topic(s) '%s': %s", subscribeTopics, err.Error())
 	}
 
 	deferred := func() {
 		lc.Info("Disconnecting from the message bus")
-		err := trigger.client.Disconnect()
+		err := trigger.getClient().Disconnect()
 		if err != nil {
 			lc.Errorf("Unable to disconnect from the message bus: %s", err.Error())
 		}
+
+		trigger.disconnectQoSClients(lc)
 	}
 	return deferred, nil
 }
 
-func (trigger *Trigger) processMessage(logger logger.LoggingClient, triggerTopic types.TopicChannel, message types.MessageEnvelope) {
+// createClient creates the MessageBus client, wrapped to inject publish failures when FaultInjection is enabled
+func (trigger *Trigger) createClient(clientConfig types.MessageBusConfig) (messaging.MessageClient, error) {
+	client, err := trigger.newClient(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if trigger.injector != nil {
+		client = trigger.injector.WrapMessageClient(client)
+	}
+
+	return client, nil
+}
+
+// connect creates a client for the endpoint, connects and subscribes to the trigger's topics. Messages received are
+// sent to the existing topic channels, so any queued messages are still processed.
+func (trigger *Trigger) connect(clientConfig types.MessageBusConfig) (messaging.MessageClient, chan error, error) {
+	client, err := trigger.createClient(clientConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := client.Connect(); err != nil {
+		return nil, nil, err
+	}
+
+	// Each connection has its own errors channel so errors from the failed client don't count against this one
+	messageErrors := make(chan error)
+	if err := client.Subscribe(trigger.topics, messageErrors); err != nil {
+		_ = client.Disconnect()
+		return nil, nil, err
+	}
+
+	return client, messageErrors, nil
+}
+
+func (trigger *Trigger) getClient() messaging.MessageClient {
+	trigger.clientLock.RLock()
+	defer trigger.clientLock.RUnlock()
+	return trigger.client
+}
+
+func (trigger *Trigger) getMessageErrors() chan error {
+	trigger.clientLock.RLock()
+	defer trigger.clientLock.RUnlock()
+	return trigger.messageErrors
+}
+
+func (trigger *Trigger) setClient(client messaging.MessageClient, messageErrors chan error) {
+	trigger.clientLock.Lock()
+	defer trigger.clientLock.Unlock()
+	trigger.client = client
+	trigger.messageErrors = messageErrors
+}
+
+// publish publishes the message with the current client. When failover is enabled messages which fail to publish
+// are held and resubmitted after the next failover.
+func (trigger *Trigger) publish(lc logger.LoggingClient, envelope types.MessageEnvelope, topic string) error {
+	err := trigger.getClient().Publish(envelope, topic)
+	if err != nil && trigger.failoverState != nil {
+		trigger.failoverState.hold(lc, envelope, topic)
+		return fmt.Errorf("%s. Holding message to resubmit after failover", err.Error())
+	}
+
+	return err
+}
+
+// publishWithQoS publishes the background message with the QoS, which only applies to the MQTT MessageBus. The
+// MessageBus client publishes with the QoS it is configured with, so a separate client is connected for each other QoS
+// used. These clients aren't failed over.
+func (trigger *Trigger) publishWithQoS(lc logger.LoggingClient, envelope types.MessageEnvelope, topic string, qos byte) error {
+	configuredQoS := trigger.clientConfig.Optional[mqttQoSOption]
+	if !strings.EqualFold(trigger.clientConfig.Type, messaging.MQTT) || configuredQoS == strconv.Itoa(int(qos)) {
+		return trigger.publish(lc, envelope, topic)
+	}
+
+	client, err := trigger.qosClient(qos)
+	if err != nil {
+		return fmt.Errorf("unable to connect MessageBus client with QoS %d: %s", qos, err.Error())
+	}
+
+	return client.Publish(envelope, topic)
+}
+
+// qosClient returns the connected client publishing with the QoS, connecting it the first time the QoS is used
+func (trigger *Trigger) qosClient(qos byte) (messaging.MessageClient, error) {
+	trigger.qosLock.Lock()
+	defer trigger.qosLock.Unlock()
+
+	if client, exists := trigger.qosClients[qos]; exists {
+		return client, nil
+	}
+
+	clientConfig := trigger.clientConfig
+	clientConfig.Optional = make(map[string]string, len(trigger.clientConfig.Optional)+1)
+	for key, value := range trigger.clientConfig.Optional {
+		clientConfig.Optional[key] = value
+	}
+	clientConfig.Optional[mqttQoSOption] = strconv.Itoa(int(qos))
+
+	client, err := trigger.createClient(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.Connect(); err != nil {
+		return nil, err
+	}
+
+	if trigger.qosClients == nil {
+		trigger.qosClients = make(map[byte]messaging.MessageClient)
+	}
+	trigger.qosClients[qos] = client
+
+	return client, nil
+}
+
+func (trigger *Trigger) disconnectQoSClients(lc logger.LoggingClient) {
+	trigger.qosLock.Lock()
+	defer trigger.qosLock.Unlock()
+
+	for qos, client := range trigger.qosClients {
+		if err := client.Disconnect(); err != nil {
+			lc.Errorf("Unable to disconnect MessageBus client with QoS %d: %s", qos, err.Error())
+		}
+	}
+	trigger.qosClients = nil
+}
+
+// processQueue dispatches the messages held in the topic's queue for processing until the service is stopped. Without
+// a bounded worker pool, Dispatch would start a goroutine per message and so drain the queue immediately, so the
+// messages are instead processed one at a time for the queue to hold the messages received while the pipeline is busy.
+func (trigger *Trigger) processQueue(
+	appWg *sync.WaitGroup,
+	appCtx context.Context,
+	lc logger.LoggingClient,
+	triggerTopic types.TopicChannel,
+	queue *messageQueue) {
+	defer appWg.Done()
+
+	for {
+		select {
+		case <-appCtx.Done():
+			return
+		case message := <-queue.messages:
+			if trigger.runtime.MaxWorkers <= 0 {
+				trigger.processMessage(lc, triggerTopic, message.MessageEnvelope, message.received)
+				continue
+			}
+
+			trigger.runtime.Dispatch(message.MessageEnvelope, func() {
+				trigger.processMessage(lc, triggerTopic, message.MessageEnvelope, message.received)
+			})
+		}
+	}
+}
+
+func (trigger *Trigger) processMessage(
+	logger logger.LoggingClient,
+	triggerTopic types.TopicChannel,
+	message types.MessageEnvelope,
+	received time.Time) {
 	logger.Debugf("Received message from MessageBus on topic '%s'. Content-Type=%s", triggerTopic.Topic, message.ContentType)
 	logger.Tracef("%s=%s", common.CorrelationHeader, message.CorrelationID)
 
 	appContext := appfunction.NewContext(message.CorrelationID, trigger.dic, message.ContentType)
+	appContext.SetReceived(received)
 
 	messageError := trigger.runtime.ProcessMessage(appContext, message)
 	if messageError != nil {
@@ -204,14 +460,19 @@ func (trigger *Trigger) processMessage(logger logger.LoggingClient, triggerTopic
 		}
 
 		config := container.ConfigurationFrom(trigger.dic.Get)
-		publishTopic, err := appContext.ApplyValues(config.Trigger.EdgexMessageBus.PublishHost.PublishTopic)
+		topic := config.Trigger.EdgexMessageBus.PublishHost.PublishTopic
+		if responseTopic := appContext.ResponseTopic(); len(responseTopic) > 0 {
+			topic = responseTopic
+		}
+
+		publishTopic, err := appContext.ApplyValues(topic)
 
 		if err != nil {
-			logger.Errorf("Unable to format output topic '%s': %s", config.Trigger.EdgexMessageBus.PublishHost.PublishTopic, err.Error())
+			logger.Errorf("Unable to format output topic '%s': %s", topic, err.Error())
 			return
 		}
 
-		err = trigger.client.Publish(outputEnvelope, publishTopic)
+		err = trigger.publish(logger, outputEnvelope, publishTopic)
 		if err != nil {
 			logger.Errorf("Failed to publish Message to bus, %v", err)
 			return
@@ -770,6 +770,14 @@ func TestInitializeAndProcessEventMultipleTopics(t *testing.T) {
 type mockBackgroundMessage struct {
 	DeliverToTopic string
 	Payload        types.MessageEnvelope
+	PublishQoS     *byte
+}
+
+func (bg mockBackgroundMessage) QoS() (byte, bool) {
+	if bg.PublishQoS == nil {
+		return 0, false
+	}
+	return *bg.PublishQoS, true
 }
 
 func (bg mockBackgroundMessage) Topic() string {
@@ -779,3 +787,45 @@ func (bg mockBackgroundMessage) Topic() string {
 func (bg mockBackgroundMessage) Message() types.MessageEnvelope {
 	return bg.Payload
 }
+
+func TestPublishWithQoS(t *testing.T) {
+	lc := logger.NewMockClient()
+	primary := newFakeMessageClient("primary")
+	qosClients := map[string]*fakeMessageClient{}
+
+	trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+	trigger.client = primary
+	trigger.clientConfig = types.MessageBusConfig{Type: "mqtt", Optional: map[string]string{mqttQoSOption: "0", "ClientId": "app"}}
+	trigger.newClient = func(clientConfig types.MessageBusConfig) (messaging.MessageClient, error) {
+		assert.Equal(t, "app", clientConfig.Optional["ClientId"])
+		client := newFakeMessageClient(clientConfig.Optional[mqttQoSOption])
+		qosClients[clientConfig.Optional[mqttQoSOption]] = client
+		return client, nil
+	}
+
+	envelope := types.MessageEnvelope{CorrelationID: "123"}
+
+	// The configured QoS is published with the trigger's client
+	require.NoError(t, trigger.publishWithQoS(lc, envelope, "alerts", 0))
+	assert.Len(t, primary.published, 1)
+	assert.Empty(t, qosClients)
+
+	require.NoError(t, trigger.publishWithQoS(lc, envelope, "alerts", 1))
+	require.NoError(t, trigger.publishWithQoS(lc, envelope, "alerts", 1))
+	require.Len(t, qosClients, 1, "expected a single client for the QoS")
+	assert.Len(t, qosClients["1"].published, 2)
+	assert.Equal(t, "0", trigger.clientConfig.Optional[mqttQoSOption], "trigger's client configuration must not change")
+
+	trigger.disconnectQoSClients(lc)
+	select {
+	case <-qosClients["1"].disconnected:
+	default:
+		assert.Fail(t, "QoS client not disconnected")
+	}
+
+	// QoS only applies to the MQTT MessageBus
+	trigger.clientConfig.Type = "redis"
+	require.NoError(t, trigger.publishWithQoS(lc, envelope, "alerts", 2))
+	assert.Len(t, primary.published, 2)
+	assert.Len(t, qosClients, 1)
+}
@@ -0,0 +1,123 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package messagebus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+)
+
+const (
+	// OverflowPolicyBlock blocks receiving further messages until there is room in the queue
+	OverflowPolicyBlock = "block"
+	// OverflowPolicyDropOldest discards the oldest queued message to make room for the message received
+	OverflowPolicyDropOldest = "drop-oldest"
+	// OverflowPolicyDropNewest discards the message received when the queue is full
+	OverflowPolicyDropNewest = "drop-newest"
+)
+
+// queuedMessage is a message held in the queue along with when it was received
+type queuedMessage struct {
+	types.MessageEnvelope
+	received time.Time
+}
+
+// messageQueue is the bounded queue that messages received from a topic are held in until they are processed
+type messageQueue struct {
+	messages chan queuedMessage
+	policy   string
+	stats    *telemetry.TriggerStatsTracker
+}
+
+func newMessageQueue(size int, policy string, stats *telemetry.TriggerStatsTracker) (*messageQueue, error) {
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	switch policy {
+	case "":
+		policy = OverflowPolicyBlock
+	case OverflowPolicyBlock, OverflowPolicyDropOldest, OverflowPolicyDropNewest:
+	default:
+		return nil, fmt.Errorf("invalid OverflowPolicy '%s'. Must be one of '%s', '%s' or '%s'",
+			policy,
+			OverflowPolicyBlock,
+			OverflowPolicyDropOldest,
+			OverflowPolicyDropNewest)
+	}
+
+	return &messageQueue{
+		messages: make(chan queuedMessage, size),
+		policy:   policy,
+		stats:    stats,
+	}, nil
+}
+
+// enqueue adds the message received to the queue, applying the overflow policy when the queue is full
+func (queue *messageQueue) enqueue(
+	ctx context.Context,
+	lc logger.LoggingClient,
+	topic string,
+	envelope types.MessageEnvelope,
+	received time.Time) {
+	message := queuedMessage{MessageEnvelope: envelope, received: received}
+
+	switch queue.policy {
+	case OverflowPolicyDropNewest:
+		select {
+		case queue.messages <- message:
+		default:
+			queue.drop(lc, topic, message)
+		}
+
+	case OverflowPolicyDropOldest:
+		for {
+			select {
+			case queue.messages <- message:
+				return
+			default:
+			}
+
+			// Queue is full so discard the oldest message and try again
+			select {
+			case oldest := <-queue.messages:
+				queue.drop(lc, topic, oldest)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case queue.messages <- message:
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (queue *messageQueue) drop(lc logger.LoggingClient, topic string, message queuedMessage) {
+	total := queue.stats.MessageDropped()
+	lc.Warnf("MessageBus queue for '%s' topic is full, dropped message with %s=%s. Total messages dropped: %d",
+		topic,
+		common.CorrelationHeader,
+		message.CorrelationID,
+		total)
+}
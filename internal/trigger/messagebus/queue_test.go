@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package messagebus
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/telemetry"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMessageQueue(t *testing.T) {
+	stats := telemetry.NewTriggerStatsTracker()
+
+	queue, err := newMessageQueue(2, "", stats)
+	require.NoError(t, err)
+	assert.Equal(t, OverflowPolicyBlock, queue.policy)
+	assert.Equal(t, 2, cap(queue.messages))
+
+	queue, err = newMessageQueue(2, " Drop-Oldest ", stats)
+	require.NoError(t, err)
+	assert.Equal(t, OverflowPolicyDropOldest, queue.policy)
+
+	_, err = newMessageQueue(2, "bogus", stats)
+	assert.Error(t, err)
+}
+
+func fillQueue(t *testing.T, policy string, stats *telemetry.TriggerStatsTracker) *messageQueue {
+	queue, err := newMessageQueue(2, policy, stats)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3"} {
+		queue.enqueue(context.Background(), logger.NewMockClient(), "topic", types.MessageEnvelope{CorrelationID: id}, time.Now())
+	}
+
+	return queue
+}
+
+func queuedIds(queue *messageQueue) []string {
+	var ids []string
+	for len(queue.messages) > 0 {
+		ids = append(ids, (<-queue.messages).CorrelationID)
+	}
+	return ids
+}
+
+func TestMessageQueue_DropNewest(t *testing.T) {
+	stats := telemetry.NewTriggerStatsTracker()
+	queue := fillQueue(t, OverflowPolicyDropNewest, stats)
+
+	assert.Equal(t, uint64(1), stats.Report().DroppedMessages)
+	assert.Equal(t, []string{"1", "2"}, queuedIds(queue))
+}
+
+func TestMessageQueue_DropOldest(t *testing.T) {
+	stats := telemetry.NewTriggerStatsTracker()
+	queue := fillQueue(t, OverflowPolicyDropOldest, stats)
+
+	assert.Equal(t, uint64(1), stats.Report().DroppedMessages)
+	assert.Equal(t, []string{"2", "3"}, queuedIds(queue))
+}
+
+func TestMessageQueue_Block(t *testing.T) {
+	stats := telemetry.NewTriggerStatsTracker()
+	queue, err := newMessageQueue(1, OverflowPolicyBlock, stats)
+	require.NoError(t, err)
+
+	queue.enqueue(context.Background(), logger.NewMockClient(), "topic", types.MessageEnvelope{CorrelationID: "1"}, time.Now())
+
+	done := make(chan bool)
+	go func() {
+		queue.enqueue(context.Background(), logger.NewMockClient(), "topic", types.MessageEnvelope{CorrelationID: "2"}, time.Now())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		require.Fail(t, "enqueue should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, "1", (<-queue.messages).CorrelationID)
+	<-done
+	assert.Equal(t, "2", (<-queue.messages).CorrelationID)
+	assert.Equal(t, uint64(0), stats.Report().DroppedMessages)
+
+	// Blocked enqueue returns when the service is stopped
+	queue.enqueue(context.Background(), logger.NewMockClient(), "topic", types.MessageEnvelope{CorrelationID: "3"}, time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	queue.enqueue(ctx, logger.NewMockClient(), "topic", types.MessageEnvelope{CorrelationID: "4"}, time.Now())
+	assert.Equal(t, []string{"3"}, queuedIds(queue))
+}
+
+func TestProcessQueue_NoWorkers(t *testing.T) {
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return &sdkCommon.ConfigurationStruct{}
+		},
+	})
+
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		started <- struct{}{}
+		<-release
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+	trigger := NewTrigger(dic, goRuntime)
+
+	queue, err := newMessageQueue(2, OverflowPolicyDropNewest, telemetry.NewTriggerStatsTracker())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go trigger.processQueue(wg, ctx, logger.NewMockClient(), types.TopicChannel{Topic: "topic"}, queue)
+
+	payload, err := json.Marshal(addEventRequest)
+	require.NoError(t, err)
+	message := types.MessageEnvelope{Payload: payload, ContentType: common.ContentTypeJSON}
+
+	queue.enqueue(ctx, logger.NewMockClient(), "topic", message, time.Now())
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "message not processed")
+	}
+
+	// Without workers the queue must hold the messages received while the pipeline is busy
+	queue.enqueue(ctx, logger.NewMockClient(), "topic", message, time.Now())
+	queue.enqueue(ctx, logger.NewMockClient(), "topic", message, time.Now())
+	assert.Len(t, queue.messages, 2)
+	assert.Len(t, started, 0, "queued messages must wait for the previous message to be processed")
+
+	close(release)
+	for index := 0; index < 2; index++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "queued message not processed")
+		}
+	}
+
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,217 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package mqtt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+
+	appCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+// topicMapping is a validated TopicMapping with its topic filter split into levels
+type topicMapping struct {
+	name   string
+	filter []string
+	appCommon.TopicMapping
+}
+
+// topicMapper converts the JSON messages received on the mapped topics into Events
+type topicMapper struct {
+	mappings []topicMapping
+}
+
+// newTopicMapper validates the mappings and returns a topicMapper for them, or nil when there are no mappings
+func newTopicMapper(mappings map[string]appCommon.TopicMapping) (*topicMapper, error) {
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+
+	mapper := &topicMapper{}
+	for name, mapping := range mappings {
+		mapping.Topic = strings.TrimSpace(mapping.Topic)
+		if len(mapping.Topic) == 0 {
+			return nil, fmt.Errorf("missing Topic for MQTT Trigger mapping '%s'", name)
+		}
+
+		filter := strings.Split(mapping.Topic, "/")
+		for index, level := range filter {
+			if (strings.ContainsAny(level, "+#") && len(level) > 1) || (level == "#" && index != len(filter)-1) {
+				return nil, fmt.Errorf("invalid Topic '%s' for MQTT Trigger mapping '%s'", mapping.Topic, name)
+			}
+		}
+
+		if len(mapping.DeviceName) == 0 || len(mapping.ProfileName) == 0 {
+			return nil, fmt.Errorf("missing DeviceName or ProfileName for MQTT Trigger mapping '%s'", name)
+		}
+
+		if len(mapping.SourceName) == 0 {
+			mapping.SourceName = name
+		}
+
+		mapper.mappings = append(mapper.mappings, topicMapping{name: name, filter: filter, TopicMapping: mapping})
+	}
+
+	sort.Slice(mapper.mappings, func(i, j int) bool {
+		return mapper.mappings[i].name < mapper.mappings[j].name
+	})
+
+	return mapper, nil
+}
+
+// match returns the first mapping whose topic filter matches the topic, or nil if none do
+func (mapper *topicMapper) match(topic string) *topicMapping {
+	levels := strings.Split(topic, "/")
+	for index := range mapper.mappings {
+		if matchTopic(mapper.mappings[index].filter, levels) {
+			return &mapper.mappings[index]
+		}
+	}
+
+	return nil
+}
+
+// matchTopic reports whether the topic levels match the topic filter levels, which may contain the + and # wildcards
+func matchTopic(filter []string, levels []string) bool {
+	for index, level := range filter {
+		if level == "#" {
+			return true
+		}
+
+		if index >= len(levels) || (level != "+" && level != levels[index]) {
+			return false
+		}
+	}
+
+	return len(filter) == len(levels)
+}
+
+// toEvent converts the JSON object received on the topic into an AddEventRequest, with a reading for each mapped field
+func (mapping *topicMapping) toEvent(topic string, data []byte) ([]byte, error) {
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("message for MQTT Trigger mapping '%s' is not a JSON object: %s", mapping.name, err.Error())
+	}
+
+	levels := strings.Split(topic, "/")
+	placeholders := make([]string, 0, len(levels)*2)
+	for index, level := range levels {
+		placeholders = append(placeholders, "{"+strconv.Itoa(index+1)+"}", level)
+	}
+	replacer := strings.NewReplacer(placeholders...)
+
+	event := dtos.NewEvent(
+		replacer.Replace(mapping.ProfileName),
+		replacer.Replace(mapping.DeviceName),
+		replacer.Replace(mapping.SourceName))
+
+	resources := mapping.Resources
+	if len(resources) == 0 {
+		resources = make(map[string]string, len(fields))
+		for field := range fields {
+			resources[field] = field
+		}
+	}
+
+	// Sorted so the readings are always in the same order
+	paths := make([]string, 0, len(resources))
+	for path := range resources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		value, found := lookupField(fields, path)
+		if !found || value == nil {
+			continue
+		}
+
+		valueType, readingValue, err := toReadingValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert field '%s' for MQTT Trigger mapping '%s': %s", path, mapping.name, err.Error())
+		}
+
+		if err := event.AddSimpleReading(replacer.Replace(resources[path]), valueType, readingValue); err != nil {
+			return nil, fmt.Errorf("unable to add reading for field '%s' for MQTT Trigger mapping '%s': %s", path, mapping.name, err.Error())
+		}
+	}
+
+	if len(event.Readings) == 0 {
+		return nil, fmt.Errorf("message for MQTT Trigger mapping '%s' has none of the mapped fields", mapping.name)
+	}
+
+	return json.Marshal(requests.NewAddEventRequest(event))
+}
+
+// lookupField returns the value of the field at the path, with the names of nested fields separated by '.'.
+// A field whose name contains a '.' is found before the nested field of the same path.
+func lookupField(fields map[string]interface{}, path string) (interface{}, bool) {
+	if value, found := fields[path]; found {
+		return value, true
+	}
+
+	index := strings.Index(path, ".")
+	if index < 0 {
+		return nil, false
+	}
+
+	nested, ok := fields[path[:index]].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	return lookupField(nested, path[index+1:])
+}
+
+// toReadingValue returns the reading value type and value for the decoded JSON value. Whole numbers are Int64 and
+// other numbers Float64. Objects and arrays are Strings containing their JSON.
+func toReadingValue(value interface{}) (string, interface{}, error) {
+	switch value := value.(type) {
+	case bool:
+		return common.ValueTypeBool, value, nil
+	case string:
+		return common.ValueTypeString, value, nil
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return common.ValueTypeInt64, integer, nil
+		}
+		float, err := value.Float64()
+		if err != nil {
+			return "", nil, err
+		}
+		return common.ValueTypeFloat64, float, nil
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return common.ValueTypeString, string(data), nil
+	default:
+		return "", nil, errors.New("unsupported JSON value")
+	}
+}
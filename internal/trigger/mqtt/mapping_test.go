@@ -0,0 +1,161 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+func TestNewTopicMapper(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Mapping       appCommon.TopicMapping
+		ErrorExpected bool
+	}{
+		{"Valid", appCommon.TopicMapping{Topic: "factory/+/telemetry/#", DeviceName: "{2}", ProfileName: "Press"}, false},
+		{"Missing topic", appCommon.TopicMapping{DeviceName: "press", ProfileName: "Press"}, true},
+		{"Partial wildcard", appCommon.TopicMapping{Topic: "factory/press+", DeviceName: "press", ProfileName: "Press"}, true},
+		{"Multi-level wildcard not last", appCommon.TopicMapping{Topic: "factory/#/telemetry", DeviceName: "press", ProfileName: "Press"}, true},
+		{"Missing device name", appCommon.TopicMapping{Topic: "factory/press", ProfileName: "Press"}, true},
+		{"Missing profile name", appCommon.TopicMapping{Topic: "factory/press", DeviceName: "press"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mapper, err := newTopicMapper(map[string]appCommon.TopicMapping{"telemetry": test.Mapping})
+			if test.ErrorExpected {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, mapper.mappings, 1)
+			assert.Equal(t, "telemetry", mapper.mappings[0].SourceName)
+		})
+	}
+
+	mapper, err := newTopicMapper(nil)
+	require.NoError(t, err)
+	assert.Nil(t, mapper)
+}
+
+func TestTopicMapperMatch(t *testing.T) {
+	mapper, err := newTopicMapper(map[string]appCommon.TopicMapping{
+		"b-all":       {Topic: "factory/#", DeviceName: "factory", ProfileName: "Factory"},
+		"a-telemetry": {Topic: "factory/+/telemetry", DeviceName: "{2}", ProfileName: "Press"},
+		"c-exact":     {Topic: "site/alarm", DeviceName: "alarm", ProfileName: "Alarm"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		Topic    string
+		Expected string
+	}{
+		{"factory/press-01/telemetry", "a-telemetry"},
+		{"factory/press-01/status", "b-all"},
+		{"factory", "b-all"},
+		{"site/alarm", "c-exact"},
+		{"site/alarm/1", ""},
+		{"site", ""},
+		{"other/press-01/telemetry", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Topic, func(t *testing.T) {
+			mapping := mapper.match(test.Topic)
+			if len(test.Expected) == 0 {
+				assert.Nil(t, mapping)
+				return
+			}
+
+			require.NotNil(t, mapping)
+			assert.Equal(t, test.Expected, mapping.name)
+		})
+	}
+}
+
+func TestTopicMappingToEvent(t *testing.T) {
+	payload := []byte(`{"t": 21.5, "count": 3, "ok": true, "state": "run", "env": {"humidity": 40}, "tags": ["a"], "none": null}`)
+
+	tests := []struct {
+		Name             string
+		Resources        map[string]string
+		Payload          []byte
+		ExpectedReadings map[string][2]string
+		ErrorExpected    bool
+	}{
+		{"Mapped fields", map[string]string{"t": "Temperature", "env.humidity": "Humidity", "missing": "Missing"}, payload,
+			map[string][2]string{
+				"Temperature": {common.ValueTypeFloat64, "2.150000e+01"},
+				"Humidity":    {common.ValueTypeInt64, "40"},
+			}, false},
+		{"All top level fields", nil, payload,
+			map[string][2]string{
+				"t":     {common.ValueTypeFloat64, "2.150000e+01"},
+				"count": {common.ValueTypeInt64, "3"},
+				"ok":    {common.ValueTypeBool, "true"},
+				"state": {common.ValueTypeString, "run"},
+				"env":   {common.ValueTypeString, `{"humidity":40}`},
+				"tags":  {common.ValueTypeString, `["a"]`},
+			}, false},
+		{"Topic placeholder in resource", map[string]string{"t": "{3}-Temperature"}, payload,
+			map[string][2]string{"telemetry-Temperature": {common.ValueTypeFloat64, "2.150000e+01"}}, false},
+		{"No mapped fields", map[string]string{"missing": "Missing"}, payload, nil, true},
+		{"Not an object", nil, []byte(`[1, 2]`), nil, true},
+		{"Not JSON", nil, []byte(`21.5`), nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mapper, err := newTopicMapper(map[string]appCommon.TopicMapping{
+				"telemetry": {Topic: "factory/+/telemetry", DeviceName: "{2}", ProfileName: "Press", Resources: test.Resources},
+			})
+			require.NoError(t, err)
+
+			data, err := mapper.mappings[0].toEvent("factory/press-01/telemetry", test.Payload)
+			if test.ErrorExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			request := requests.AddEventRequest{}
+			require.NoError(t, json.Unmarshal(data, &request))
+			require.NoError(t, request.Validate())
+
+			event := request.Event
+			assert.Equal(t, "press-01", event.DeviceName)
+			assert.Equal(t, "Press", event.ProfileName)
+			assert.Equal(t, "telemetry", event.SourceName)
+
+			actual := make(map[string][2]string)
+			for _, reading := range event.Readings {
+				assert.Equal(t, "press-01", reading.DeviceName)
+				actual[reading.ResourceName] = [2]string{reading.ValueType, reading.Value}
+			}
+			assert.Equal(t, test.ExpectedReadings, actual)
+		})
+	}
+}
@@ -50,6 +50,7 @@ type Trigger struct {
 	lc         logger.LoggingClient
 	mqttClient pahoMqtt.Client
 	runtime    *runtime.GolangRuntime
+	mapper     *topicMapper
 }
 
 func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime) *Trigger {
@@ -78,6 +79,16 @@ func (trigger *Trigger) Initialize(_ *sync.WaitGroup, _ context.Context, backgro
 		return nil, fmt.Errorf("missing SubscribeTopics for MQTT Trigger. Must be present in [Trigger.ExternalMqtt] section.")
 	}
 
+	if _, err := parseSubscriptions(topics, brokerConfig.QoS, brokerConfig.SubscribeQoS, brokerConfig.SharedSubscriptionGroup); err != nil {
+		return nil, err
+	}
+
+	mapper, err := newTopicMapper(brokerConfig.Mappings)
+	if err != nil {
+		return nil, err
+	}
+	trigger.mapper = mapper
+
 	brokerUrl, err := url.Parse(brokerConfig.Url)
 	if err != nil {
 		return nil, fmt.Errorf("invalid MQTT Broker Url '%s': %s", config.Trigger.ExternalMqtt.Url, err.Error())
@@ -133,30 +144,95 @@ func (trigger *Trigger) onConnectHandler(mqttClient pahoMqtt.Client) {
 	// Convenience short cuts
 	lc := trigger.lc
 	config := container.ConfigurationFrom(trigger.dic.Get)
-	topics := util.DeleteEmptyAndTrim(strings.FieldsFunc(config.Trigger.ExternalMqtt.SubscribeTopics, util.SplitComma))
-	qos := config.Trigger.ExternalMqtt.QoS
+	brokerConfig := config.Trigger.ExternalMqtt
+
+	// Subscriptions have already been validated during Initialize
+	subscriptions, _ := parseSubscriptions(brokerConfig.SubscribeTopics, brokerConfig.QoS, brokerConfig.SubscribeQoS,
+		brokerConfig.SharedSubscriptionGroup)
 
-	for _, topic := range topics {
-		if token := mqttClient.Subscribe(topic, qos, trigger.messageHandler); token.Wait() && token.Error() != nil {
+	for _, subscription := range subscriptions {
+		if token := mqttClient.Subscribe(subscription.topic, subscription.qos, trigger.messageHandler); token.Wait() && token.Error() != nil {
 			mqttClient.Disconnect(0)
 			lc.Errorf("could not subscribe to topic '%s' for MQTT trigger: %s",
-				topic, token.Error().Error())
+				subscription.topic, token.Error().Error())
 			return
 		}
+
+		lc.Debugf("Subscribed to topic '%s' with QoS %d for MQTT trigger", subscription.topic, subscription.qos)
 	}
 
-	lc.Infof("Subscribed to topic(s) '%s' for MQTT trigger", config.Trigger.ExternalMqtt.SubscribeTopics)
+	lc.Infof("Subscribed to topic(s) '%s' for MQTT trigger", brokerConfig.SubscribeTopics)
+}
+
+// subscription is a topic to subscribe to and its QoS
+type subscription struct {
+	topic string
+	qos   byte
+}
+
+// parseSubscriptions parses the comma separated list of topics. Topics found in topicQos are subscribed with that QoS,
+// the others with the default QoS. When a shared subscription group is specified each topic is prefixed with
+// $share/<group>/ unless it is already a shared subscription.
+func parseSubscriptions(topics string, defaultQos byte, topicQos map[string]byte, sharedGroup string) ([]subscription, error) {
+	var subscriptions []subscription
+
+	sharedGroup = strings.TrimSpace(sharedGroup)
+	if strings.ContainsAny(sharedGroup, "/+#") {
+		return nil, fmt.Errorf("invalid SharedSubscriptionGroup '%s' for MQTT Trigger. Must not contain '/', '+' or '#'", sharedGroup)
+	}
+
+	subscribed := make(map[string]bool)
+	for _, topic := range util.DeleteEmptyAndTrim(strings.FieldsFunc(topics, util.SplitComma)) {
+		subscribed[topic] = true
+
+		qos, found := topicQos[topic]
+		if !found {
+			qos = defaultQos
+		}
+
+		if len(sharedGroup) > 0 && !strings.HasPrefix(topic, "$share/") {
+			topic = fmt.Sprintf("$share/%s/%s", sharedGroup, topic)
+		}
+
+		subscriptions = append(subscriptions, subscription{topic: topic, qos: qos})
+	}
+
+	for topic, qos := range topicQos {
+		if !subscribed[topic] {
+			return nil, fmt.Errorf("SubscribeQoS topic '%s' for MQTT Trigger is not in SubscribeTopics", topic)
+		}
+		if qos > 2 {
+			return nil, fmt.Errorf("invalid QoS %d for topic '%s' for MQTT Trigger. Must be 0, 1 or 2", qos, topic)
+		}
+	}
+
+	return subscriptions, nil
 }
 
 func (trigger *Trigger) messageHandler(client pahoMqtt.Client, message pahoMqtt.Message) {
 	// Convenience short cuts
 	lc := trigger.lc
+	received := time.Now()
 	config := container.ConfigurationFrom(trigger.dic.Get)
 	brokerConfig := config.Trigger.ExternalMqtt
 	topic := config.Trigger.ExternalMqtt.PublishTopic
 
 	data := message.Payload()
 	contentType := common.ContentTypeJSON
+
+	if trigger.mapper != nil {
+		if mapping := trigger.mapper.match(message.Topic()); mapping != nil {
+			event, err := mapping.toEvent(message.Topic(), data)
+			if err != nil {
+				lc.Errorf("could not map message from topic '%s' for MQTT trigger: %s", message.Topic(), err.Error())
+				return
+			}
+
+			lc.Debugf("Mapped message from topic '%s' to an Event using mapping '%s'", message.Topic(), mapping.name)
+			data = event
+		}
+	}
+
 	if data[0] != byte('{') && data[0] != byte('[') {
 		// If not JSON then assume it is CBOR
 		contentType = common.ContentTypeCBOR
@@ -165,6 +241,7 @@ func (trigger *Trigger) messageHandler(client pahoMqtt.Client, message pahoMqtt.
 	correlationID := uuid.New().String()
 
 	appContext := appfunction.NewContext(correlationID, trigger.dic, contentType)
+	appContext.SetReceived(received)
 
 	lc.Debugf("Received message from MQTT Trigger with %d bytes from topic '%s'. Content-Type=%s", len(data), message.Topic(), contentType)
 	lc.Tracef("%s=%s", common.CorrelationHeader, correlationID)
@@ -183,6 +260,10 @@ func (trigger *Trigger) messageHandler(client pahoMqtt.Client, message pahoMqtt.
 		return
 	}
 
+	if responseTopic := appContext.ResponseTopic(); len(responseTopic) > 0 {
+		topic = responseTopic
+	}
+
 	if len(appContext.ResponseData()) > 0 && len(topic) > 0 {
 		formattedTopic, err := appContext.ApplyValues(topic)
 
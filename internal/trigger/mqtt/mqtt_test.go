@@ -0,0 +1,66 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSubscriptions(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Topics        string
+		TopicQos      map[string]byte
+		SharedGroup   string
+		Expected      []subscription
+		ErrorExpected bool
+	}{
+		{"Single topic", "sensors/#", nil, "", []subscription{{"sensors/#", 1}}, false},
+		{"Multiple topics", "sensors/#, alarms/+/critical", nil, "",
+			[]subscription{{"sensors/#", 1}, {"alarms/+/critical", 1}}, false},
+		{"Per-topic QoS", "sensors/#, alarms/#, events", map[string]byte{"sensors/#": 0, "alarms/#": 2}, "",
+			[]subscription{{"sensors/#", 0}, {"alarms/#", 2}, {"events", 1}}, false},
+		{"Colon in topic", "plant:1/line:a", nil, "", []subscription{{"plant:1/line:a", 1}}, false},
+		{"Colon digit suffix in topic", "plant/line:1", nil, "", []subscription{{"plant/line:1", 1}}, false},
+		{"Colon digit suffix in topic with QoS", "plant/line:1", map[string]byte{"plant/line:1": 2}, "",
+			[]subscription{{"plant/line:1", 2}}, false},
+		{"Shared subscription", "sensors/#, alarms/#", map[string]byte{"sensors/#": 2}, "group1",
+			[]subscription{{"$share/group1/sensors/#", 2}, {"$share/group1/alarms/#", 1}}, false},
+		{"Already shared", "$share/other/sensors/#", nil, "group1", []subscription{{"$share/other/sensors/#", 1}}, false},
+		{"Explicit shared without group", "$share/group1/sensors/#", map[string]byte{"$share/group1/sensors/#": 0}, "",
+			[]subscription{{"$share/group1/sensors/#", 0}}, false},
+		{"Bad QoS", "sensors/#", map[string]byte{"sensors/#": 3}, "", nil, true},
+		{"QoS for unknown topic", "sensors/#", map[string]byte{"alarms/#": 2}, "", nil, true},
+		{"Bad shared group", "sensors/#", nil, "group/1", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := parseSubscriptions(test.Topics, 1, test.TopicQos, test.SharedGroup)
+			if test.ErrorExpected {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
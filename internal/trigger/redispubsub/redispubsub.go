@@ -0,0 +1,369 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package redispubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	AuthModeNone             = "none"
+	AuthModeUsernamePassword = "usernamepassword"
+
+	defaultConnectTimeout = 30 * time.Second
+	reconnectInterval     = 5 * time.Second
+)
+
+// Trigger implements Trigger to support Redis Pub/Sub pattern subscriptions
+type Trigger struct {
+	dic         *di.Container
+	lc          logger.LoggingClient
+	runtime     *runtime.GolangRuntime
+	config      sdkCommon.RedisPubSubConfig
+	patterns    []interface{}
+	credentials map[string]string
+	timeout     time.Duration
+	publishPool *redis.Pool
+	conn        redis.Conn
+	connLock    sync.Mutex
+}
+
+func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime) *Trigger {
+	return &Trigger{
+		dic:     dic,
+		runtime: runtime,
+		lc:      bootstrapContainer.LoggingClientFrom(dic.Get),
+	}
+}
+
+// Initialize initializes the Trigger for a Redis server using Pub/Sub
+func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context, background <-chan interfaces.BackgroundMessage) (bootstrap.Deferred, error) {
+	// Convenience short cuts
+	lc := trigger.lc
+	config := container.ConfigurationFrom(trigger.dic.Get)
+	trigger.config = config.Trigger.RedisPubSub
+
+	lc.Info("Initializing Redis Pub/Sub Trigger")
+
+	if background != nil {
+		return nil, errors.New("background publishing not supported for services using Redis Pub/Sub trigger")
+	}
+
+	patterns := util.DeleteEmptyAndTrim(strings.FieldsFunc(trigger.config.SubscribePatterns, util.SplitComma))
+	if len(patterns) == 0 {
+		return nil, errors.New("missing SubscribePatterns for Redis Pub/Sub Trigger. Must be present in [Trigger.RedisPubSub] section.")
+	}
+
+	trigger.patterns = make([]interface{}, len(patterns))
+	for index, pattern := range patterns {
+		trigger.patterns[index] = pattern
+	}
+
+	trigger.timeout = defaultConnectTimeout
+	if len(trigger.config.ConnectTimeout) > 0 {
+		var err error
+		trigger.timeout, err = time.ParseDuration(trigger.config.ConnectTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis Pub/Sub ConnectTimeout '%s': %s", trigger.config.ConnectTimeout, err.Error())
+		}
+	}
+
+	if err := trigger.loadCredentials(); err != nil {
+		return nil, err
+	}
+
+	conn, err := trigger.subscribe()
+	if err != nil {
+		return nil, err
+	}
+	trigger.setConn(conn)
+
+	lc.Infof("Subscribed to pattern(s) '%s' for Redis Pub/Sub trigger", trigger.config.SubscribePatterns)
+
+	// The pool is always created since a pipeline can set a response topic when no PublishChannel is configured.
+	// Connections are only made once a response is published.
+	trigger.publishPool = &redis.Pool{
+		MaxIdle:     1,
+		IdleTimeout: time.Minute,
+		Dial:        trigger.dial,
+	}
+
+	if len(trigger.config.PublishChannel) > 0 {
+		lc.Infof("Publishing to channel '%s' for Redis Pub/Sub trigger", trigger.config.PublishChannel)
+	}
+
+	appWg.Add(1)
+	go func() {
+		defer appWg.Done()
+		trigger.receive(appCtx)
+	}()
+
+	// Closing the connection unblocks the receive loop when the service is stopped
+	go func() {
+		<-appCtx.Done()
+		trigger.closeConn()
+	}()
+
+	deferred := func() {
+		lc.Info("Disconnecting from Redis server for Redis Pub/Sub trigger")
+		trigger.closeConn()
+		_ = trigger.publishPool.Close()
+	}
+
+	return deferred, nil
+}
+
+func (trigger *Trigger) loadCredentials() error {
+	switch strings.ToLower(trigger.config.AuthMode) {
+	case "", AuthModeNone:
+		return nil
+
+	case AuthModeUsernamePassword:
+		// Must provide a dummy AppFunctionContext which will provide access to GetSecret
+		appContext := appfunction.NewContext("", trigger.dic, "")
+		secrets, err := appContext.GetSecret(trigger.config.SecretPath)
+		if err != nil {
+			return fmt.Errorf("unable to get credentials for Redis Pub/Sub trigger from secret path '%s': %s",
+				trigger.config.SecretPath,
+				err.Error())
+		}
+
+		if len(secrets["password"]) == 0 {
+			return fmt.Errorf("password not found in secret path '%s' for Redis Pub/Sub trigger", trigger.config.SecretPath)
+		}
+
+		trigger.credentials = secrets
+		return nil
+
+	default:
+		return fmt.Errorf("invalid AuthMode '%s' for Redis Pub/Sub trigger. Must be '%s' or '%s'",
+			trigger.config.AuthMode,
+			AuthModeNone,
+			AuthModeUsernamePassword)
+	}
+}
+
+// dial connects to the Redis server and authenticates when credentials have been configured
+func (trigger *Trigger) dial() (redis.Conn, error) {
+	address := net.JoinHostPort(trigger.config.Host, strconv.Itoa(trigger.config.Port))
+	conn, err := redis.Dial("tcp", address, redis.DialConnectTimeout(trigger.timeout))
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to Redis server at %s for Redis Pub/Sub trigger: %s", address, err.Error())
+	}
+
+	if trigger.credentials != nil {
+		args := []interface{}{trigger.credentials["password"]}
+		if username := trigger.credentials["username"]; len(username) > 0 {
+			args = []interface{}{username, trigger.credentials["password"]}
+		}
+
+		if _, err := conn.Do("AUTH", args...); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("could not authenticate with Redis server at %s for Redis Pub/Sub trigger: %s", address, err.Error())
+		}
+	}
+
+	return conn, nil
+}
+
+// subscribe connects to the Redis server and subscribes to the configured patterns
+func (trigger *Trigger) subscribe() (redis.Conn, error) {
+	conn, err := trigger.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	pubSubConn := redis.PubSubConn{Conn: conn}
+	if err := pubSubConn.PSubscribe(trigger.patterns...); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not subscribe to pattern(s) '%s' for Redis Pub/Sub trigger: %s",
+			trigger.config.SubscribePatterns,
+			err.Error())
+	}
+
+	return conn, nil
+}
+
+// receive processes messages received until the service is stopped, re-subscribing if the connection is lost
+func (trigger *Trigger) receive(appCtx context.Context) {
+	lc := trigger.lc
+
+	for {
+		pubSubConn := redis.PubSubConn{Conn: trigger.getConn()}
+
+		switch reply := pubSubConn.Receive().(type) {
+		case redis.Message:
+			message := reply
+			received := time.Now()
+			envelope := types.MessageEnvelope{Payload: message.Data, ReceivedTopic: message.Channel}
+			trigger.runtime.Dispatch(envelope, func() {
+				trigger.processMessage(message, received)
+			})
+
+		case redis.Subscription:
+			lc.Debugf("Redis Pub/Sub trigger %s to '%s'", reply.Kind, reply.Channel)
+
+		case error:
+			select {
+			case <-appCtx.Done():
+				lc.Info("Exiting waiting for Redis Pub/Sub messages")
+				return
+			default:
+			}
+
+			lc.Errorf("Lost connection to Redis server for Redis Pub/Sub trigger: %s", reply.Error())
+			trigger.closeConn()
+
+			if !trigger.resubscribe(appCtx) {
+				lc.Info("Exiting waiting for Redis Pub/Sub messages")
+				return
+			}
+		}
+	}
+}
+
+// resubscribe repeatedly attempts to re-subscribe until successful or the service is stopped
+func (trigger *Trigger) resubscribe(appCtx context.Context) bool {
+	for {
+		select {
+		case <-appCtx.Done():
+			return false
+		case <-time.After(reconnectInterval):
+		}
+
+		conn, err := trigger.subscribe()
+		if err != nil {
+			trigger.lc.Errorf("Unable to re-subscribe for Redis Pub/Sub trigger: %s", err.Error())
+			continue
+		}
+
+		trigger.setConn(conn)
+		trigger.lc.Infof("Re-subscribed to pattern(s) '%s' for Redis Pub/Sub trigger", trigger.config.SubscribePatterns)
+		return true
+	}
+}
+
+func (trigger *Trigger) getConn() redis.Conn {
+	trigger.connLock.Lock()
+	defer trigger.connLock.Unlock()
+	return trigger.conn
+}
+
+func (trigger *Trigger) setConn(conn redis.Conn) {
+	trigger.connLock.Lock()
+	defer trigger.connLock.Unlock()
+	trigger.conn = conn
+}
+
+func (trigger *Trigger) closeConn() {
+	trigger.connLock.Lock()
+	defer trigger.connLock.Unlock()
+	if trigger.conn != nil {
+		_ = trigger.conn.Close()
+	}
+}
+
+func (trigger *Trigger) processMessage(message redis.Message, received time.Time) {
+	lc := trigger.lc
+
+	data := message.Data
+	if len(data) == 0 {
+		lc.Warnf("Received empty message from Redis Pub/Sub trigger on channel '%s'", message.Channel)
+		return
+	}
+
+	contentType := common.ContentTypeJSON
+	if data[0] != byte('{') && data[0] != byte('[') {
+		// If not JSON then assume it is CBOR
+		contentType = common.ContentTypeCBOR
+	}
+
+	correlationID := uuid.New().String()
+
+	appContext := appfunction.NewContext(correlationID, trigger.dic, contentType)
+	appContext.SetReceived(received)
+
+	lc.Debugf("Received message from Redis Pub/Sub Trigger with %d bytes from channel '%s' matching pattern '%s'. Content-Type=%s",
+		len(data),
+		message.Channel,
+		message.Pattern,
+		contentType)
+	lc.Tracef("%s=%s", common.CorrelationHeader, correlationID)
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: correlationID,
+		ContentType:   contentType,
+		Payload:       data,
+		ReceivedTopic: message.Channel,
+	}
+
+	messageError := trigger.runtime.ProcessMessage(appContext, envelope)
+	if messageError != nil {
+		// ProcessMessage logs the error, so no need to log it here.
+		return
+	}
+
+	channel := trigger.config.PublishChannel
+	if responseTopic := appContext.ResponseTopic(); len(responseTopic) > 0 {
+		channel = responseTopic
+	}
+	if len(appContext.ResponseData()) == 0 || len(channel) == 0 {
+		return
+	}
+
+	formattedChannel, err := appContext.ApplyValues(channel)
+	if err != nil {
+		lc.Errorf("could not format channel '%s' for Redis Pub/Sub trigger output: %s", channel, err.Error())
+		return
+	}
+
+	conn := trigger.publishPool.Get()
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Do("PUBLISH", formattedChannel, appContext.ResponseData()); err != nil {
+		lc.Errorf("could not publish to channel '%s' for Redis Pub/Sub trigger: %s", formattedChannel, err.Error())
+		return
+	}
+
+	lc.Trace("Sent Redis Pub/Sub Trigger response message", common.CorrelationHeader, correlationID)
+	lc.Debugf("Sent Redis Pub/Sub Trigger response message on channel '%s' with %d bytes", formattedChannel, len(appContext.ResponseData()))
+}
@@ -0,0 +1,317 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package redispubsub
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dic *di.Container
+
+func TestMain(m *testing.M) {
+	dic = di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+	m.Run()
+}
+
+// fakeRedisServer is a minimal RESP server supporting the AUTH, PSUBSCRIBE and PUBLISH commands
+type fakeRedisServer struct {
+	listener  net.Listener
+	commands  chan []string
+	subscribe chan net.Conn
+}
+
+func startFakeRedisServer(t *testing.T) *fakeRedisServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeRedisServer{
+		listener:  listener,
+		commands:  make(chan []string, 10),
+		subscribe: make(chan net.Conn, 1),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn)
+		}
+	}()
+
+	return server
+}
+
+func (server *fakeRedisServer) port() int {
+	return server.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (server *fakeRedisServer) close() {
+	_ = server.listener.Close()
+}
+
+func (server *fakeRedisServer) handle(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	for {
+		command, err := readCommand(reader)
+		if err != nil {
+			_ = conn.Close()
+			return
+		}
+
+		server.commands <- command
+
+		switch strings.ToUpper(command[0]) {
+		case "AUTH":
+			_, _ = io.WriteString(conn, "+OK\r\n")
+		case "PUBLISH":
+			_, _ = io.WriteString(conn, ":1\r\n")
+		case "PSUBSCRIBE":
+			for index, pattern := range command[1:] {
+				_, _ = io.WriteString(conn, fmt.Sprintf("*3\r\n%s%s:%d\r\n", bulkString("psubscribe"), bulkString(pattern), index+1))
+			}
+			server.subscribe <- conn
+		default:
+			_, _ = io.WriteString(conn, "-ERR unknown command\r\n")
+		}
+	}
+}
+
+func (server *fakeRedisServer) publish(conn net.Conn, pattern string, channel string, data string) {
+	_, _ = io.WriteString(conn, "*4\r\n"+bulkString("pmessage")+bulkString(pattern)+bulkString(channel)+bulkString(data))
+}
+
+func bulkString(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	command := make([]string, count)
+	for index := range command {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		value, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		command[index] = strings.TrimSuffix(value, "\r\n")
+	}
+
+	return command, nil
+}
+
+func updateConfig(redisConfig sdkCommon.RedisPubSubConfig) {
+	config := &sdkCommon.ConfigurationStruct{
+		Trigger: sdkCommon.TriggerInfo{
+			Type:        "redis-pubsub",
+			RedisPubSub: redisConfig,
+		},
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+}
+
+func TestInitializeErrors(t *testing.T) {
+	server := startFakeRedisServer(t)
+	server.close()
+
+	tests := []struct {
+		Name          string
+		Config        sdkCommon.RedisPubSubConfig
+		Background    chan interfaces.BackgroundMessage
+		ErrorContains string
+	}{
+		{"Background not supported", sdkCommon.RedisPubSubConfig{SubscribePatterns: "edgex.*"}, make(chan interfaces.BackgroundMessage), "background"},
+		{"Missing patterns", sdkCommon.RedisPubSubConfig{SubscribePatterns: " , "}, nil, "SubscribePatterns"},
+		{"Bad ConnectTimeout", sdkCommon.RedisPubSubConfig{SubscribePatterns: "edgex.*", ConnectTimeout: "bogus"}, nil, "ConnectTimeout"},
+		{"Bad AuthMode", sdkCommon.RedisPubSubConfig{SubscribePatterns: "edgex.*", AuthMode: "bogus"}, nil, "AuthMode"},
+		{"Connect failed", sdkCommon.RedisPubSubConfig{Host: "127.0.0.1", Port: server.port(), SubscribePatterns: "edgex.*", ConnectTimeout: "1s"}, nil, "could not connect"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			updateConfig(test.Config)
+
+			trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+			_, err := trigger.Initialize(&sync.WaitGroup{}, context.Background(), test.Background)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ErrorContains)
+		})
+	}
+}
+
+func TestInitializeAndReceive(t *testing.T) {
+	server := startFakeRedisServer(t)
+	defer server.close()
+
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", "redis").Return(map[string]string{"username": "user", "password": "secret"}, nil)
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	updateConfig(sdkCommon.RedisPubSubConfig{
+		Host:              "127.0.0.1",
+		Port:              server.port(),
+		SubscribePatterns: "edgex.events.*, edgex.other.*",
+		PublishChannel:    "edgex.response",
+		AuthMode:          AuthModeUsernamePassword,
+		SecretPath:        "redis",
+	})
+
+	event := dtos.NewEvent("thermostat", "LivingRoomThermostat", "temperature")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	payload, err := json.Marshal(requests.NewAddEventRequest(event))
+	require.NoError(t, err)
+
+	received := make(chan dtos.Event, 1)
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		received <- data.(dtos.Event)
+		appContext.SetResponseData([]byte("response"))
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	trigger := NewTrigger(dic, goRuntime)
+	deferred, err := trigger.Initialize(wg, ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, deferred)
+
+	assert.Equal(t, []string{"AUTH", "user", "secret"}, <-server.commands)
+	assert.Equal(t, []string{"PSUBSCRIBE", "edgex.events.*", "edgex.other.*"}, <-server.commands)
+
+	subscribeConn := <-server.subscribe
+	server.publish(subscribeConn, "edgex.events.*", "edgex.events.thermostat", string(payload))
+
+	select {
+	case actual := <-received:
+		assert.Equal(t, event.DeviceName, actual.DeviceName)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "message not received")
+	}
+
+	assert.Equal(t, []string{"AUTH", "user", "secret"}, <-server.commands)
+	assert.Equal(t, []string{"PUBLISH", "edgex.response", "response"}, <-server.commands)
+
+	cancel()
+	wg.Wait()
+	deferred()
+}
+
+func TestReceiveWithResponseTopic(t *testing.T) {
+	server := startFakeRedisServer(t)
+	defer server.close()
+
+	// No PublishChannel is configured, so responses are only published to the topic set by the pipeline
+	updateConfig(sdkCommon.RedisPubSubConfig{
+		Host:              "127.0.0.1",
+		Port:              server.port(),
+		SubscribePatterns: "edgex.events.*",
+	})
+
+	event := dtos.NewEvent("thermostat", "LivingRoomThermostat", "temperature")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	payload, err := json.Marshal(requests.NewAddEventRequest(event))
+	require.NoError(t, err)
+
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		appContext.SetResponseTopic("edgex.alerts.{devicename}")
+		appContext.SetResponseData([]byte("response"))
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	trigger := NewTrigger(dic, goRuntime)
+	deferred, err := trigger.Initialize(wg, ctx, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"PSUBSCRIBE", "edgex.events.*"}, <-server.commands)
+
+	subscribeConn := <-server.subscribe
+	server.publish(subscribeConn, "edgex.events.*", "edgex.events.thermostat", string(payload))
+
+	select {
+	case command := <-server.commands:
+		assert.Equal(t, []string{"PUBLISH", "edgex.alerts.LivingRoomThermostat", "response"}, command)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "response not published")
+	}
+
+	cancel()
+	wg.Wait()
+	deferred()
+}
@@ -0,0 +1,61 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package serial
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// splitFrames returns a bufio.SplitFunc which splits the data read into frames terminated by the delimiter,
+// or into fixed length frames when no delimiter is specified. Empty delimited frames are skipped and an
+// incomplete frame remaining when the port is closed is discarded.
+func splitFrames(delimiter []byte, frameLength int) bufio.SplitFunc {
+	if len(delimiter) == 0 {
+		return func(data []byte, atEOF bool) (int, []byte, error) {
+			if len(data) >= frameLength {
+				return frameLength, data[:frameLength], nil
+			}
+			if atEOF {
+				return len(data), nil, nil
+			}
+			return 0, nil, nil
+		}
+	}
+
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		advance := 0
+		for {
+			index := bytes.Index(data[advance:], delimiter)
+			if index < 0 {
+				break
+			}
+
+			if index > 0 {
+				return advance + index + len(delimiter), data[advance : advance+index], nil
+			}
+
+			// Skip empty frame
+			advance += len(delimiter)
+		}
+
+		if atEOF {
+			return len(data), nil, nil
+		}
+		return advance, nil, nil
+	}
+}
@@ -0,0 +1,120 @@
+//go:build linux
+// +build linux
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package serial
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// baudRates maps the supported baud rates to their termios speed
+var baudRates = map[int]uint32{
+	1200:    unix.B1200,
+	2400:    unix.B2400,
+	4800:    unix.B4800,
+	9600:    unix.B9600,
+	19200:   unix.B19200,
+	38400:   unix.B38400,
+	57600:   unix.B57600,
+	115200:  unix.B115200,
+	230400:  unix.B230400,
+	460800:  unix.B460800,
+	921600:  unix.B921600,
+	1000000: unix.B1000000,
+}
+
+var dataBits = map[int]uint32{
+	5: unix.CS5,
+	6: unix.CS6,
+	7: unix.CS7,
+	8: unix.CS8,
+}
+
+// openPort opens the serial device in raw mode with the specified settings
+func openPort(settings portSettings) (io.ReadCloser, error) {
+	// Opened non-blocking so that reads are handled by the runtime poller and are unblocked by Close
+	file, err := os.OpenFile(settings.device, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configurePort(file, settings); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func configurePort(file *os.File, settings portSettings) error {
+	rawConn, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var termiosErr error
+	err = rawConn.Control(func(fd uintptr) {
+		var termios *unix.Termios
+		termios, termiosErr = unix.IoctlGetTermios(int(fd), unix.TCGETS)
+		if termiosErr != nil {
+			return
+		}
+
+		// Raw mode, equivalent to cfmakeraw
+		termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+		termios.Oflag &^= unix.OPOST
+		termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+
+		termios.Cflag &^= unix.CBAUD | unix.CSIZE | unix.PARENB | unix.PARODD | unix.CSTOPB | unix.CRTSCTS
+		termios.Cflag |= baudRates[settings.baudRate] | dataBits[settings.dataBits] | unix.CREAD | unix.CLOCAL
+
+		switch settings.parity {
+		case ParityOdd:
+			termios.Cflag |= unix.PARENB | unix.PARODD
+			termios.Iflag |= unix.INPCK
+		case ParityEven:
+			termios.Cflag |= unix.PARENB
+			termios.Iflag |= unix.INPCK
+		default:
+			termios.Iflag &^= unix.INPCK
+		}
+
+		if settings.stopBits == 2 {
+			termios.Cflag |= unix.CSTOPB
+		}
+
+		// Return as soon as any data is available
+		termios.Cc[unix.VMIN] = 1
+		termios.Cc[unix.VTIME] = 0
+
+		termiosErr = unix.IoctlSetTermios(int(fd), unix.TCSETS, termios)
+	})
+	if err != nil {
+		return err
+	}
+	if termiosErr != nil {
+		return fmt.Errorf("unable to configure serial port: %s", termiosErr.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+//go:build linux
+// +build linux
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package serial
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// openPseudoTerminal returns the master side of a new pseudo terminal and the path of its slave device,
+// which stands in for the serial port
+func openPseudoTerminal(t *testing.T) (*os.File, string) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo terminals not available: %s", err.Error())
+	}
+
+	fd := int(master.Fd())
+	require.NoError(t, unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0))
+	number, err := unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+	require.NoError(t, err)
+
+	return master, fmt.Sprintf("/dev/pts/%d", number)
+}
+
+func TestInitializeAndReceive(t *testing.T) {
+	master, device := openPseudoTerminal(t)
+	defer func() { _ = master.Close() }()
+
+	updateConfig(sdkCommon.SerialConfig{Device: device, BaudRate: 19200, Parity: ParityEven, Delimiter: `\r\n`})
+
+	received := make(chan string, 2)
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		received <- string(data.([]byte))
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{TargetType: &[]byte{}}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	trigger := NewTrigger(dic, goRuntime)
+	deferred, err := trigger.Initialize(wg, ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, deferred)
+
+	_, err = master.Write([]byte("T=21.5\r\nT=21.6\r\n"))
+	require.NoError(t, err)
+
+	// Frames are processed concurrently so may be received in any order
+	var actual []string
+	for len(actual) < 2 {
+		select {
+		case frame := <-received:
+			actual = append(actual, frame)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "frame not received")
+		}
+	}
+	assert.ElementsMatch(t, []string{"T=21.5", "T=21.6"}, actual)
+
+	cancel()
+	wg.Wait()
+	deferred()
+}
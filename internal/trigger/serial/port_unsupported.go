@@ -0,0 +1,45 @@
+//go:build !linux
+// +build !linux
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package serial
+
+import (
+	"errors"
+	"io"
+)
+
+// baudRates lists the baud rates accepted in the configuration
+var baudRates = map[int]uint32{
+	1200:    0,
+	2400:    0,
+	4800:    0,
+	9600:    0,
+	19200:   0,
+	38400:   0,
+	57600:   0,
+	115200:  0,
+	230400:  0,
+	460800:  0,
+	921600:  0,
+	1000000: 0,
+}
+
+func openPort(_ portSettings) (io.ReadCloser, error) {
+	return nil, errors.New("serial ports are only supported on Linux")
+}
@@ -0,0 +1,330 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package serial
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ParityNone = "none"
+	ParityOdd  = "odd"
+	ParityEven = "even"
+
+	defaultBaudRate     = 9600
+	defaultDataBits     = 8
+	defaultStopBits     = 1
+	defaultDelimiter    = `\n`
+	defaultMaxFrameSize = 65536
+	reopenInterval      = 5 * time.Second
+)
+
+// portSettings are the validated serial port settings used to open the device
+type portSettings struct {
+	device   string
+	baudRate int
+	dataBits int
+	parity   string
+	stopBits int
+}
+
+// Trigger implements Trigger to support receiving framed data from a serial port
+type Trigger struct {
+	dic          *di.Container
+	lc           logger.LoggingClient
+	runtime      *runtime.GolangRuntime
+	settings     portSettings
+	delimiter    []byte
+	frameLength  int
+	maxFrameSize int
+	port         io.ReadCloser
+	portLock     sync.Mutex
+}
+
+func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime) *Trigger {
+	return &Trigger{
+		dic:     dic,
+		runtime: runtime,
+		lc:      bootstrapContainer.LoggingClientFrom(dic.Get),
+	}
+}
+
+// Initialize initializes the Trigger to read frames from the configured serial port
+func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context, background <-chan interfaces.BackgroundMessage) (bootstrap.Deferred, error) {
+	// Convenience short cuts
+	lc := trigger.lc
+	config := container.ConfigurationFrom(trigger.dic.Get)
+
+	lc.Info("Initializing Serial Trigger")
+
+	if background != nil {
+		return nil, errors.New("background publishing not supported for services using Serial trigger")
+	}
+
+	if err := trigger.configure(config.Trigger.Serial); err != nil {
+		return nil, err
+	}
+
+	port, err := openPort(trigger.settings)
+	if err != nil {
+		return nil, fmt.Errorf("could not open serial port '%s' for Serial trigger: %s", trigger.settings.device, err.Error())
+	}
+	trigger.setPort(port)
+
+	lc.Infof("Reading from serial port '%s' at %d baud for Serial trigger", trigger.settings.device, trigger.settings.baudRate)
+
+	appWg.Add(1)
+	go func() {
+		defer appWg.Done()
+		trigger.receive(appCtx)
+	}()
+
+	// Closing the port unblocks the receive loop when the service is stopped
+	go func() {
+		<-appCtx.Done()
+		trigger.closePort()
+	}()
+
+	deferred := func() {
+		lc.Info("Closing serial port for Serial trigger")
+		trigger.closePort()
+	}
+
+	return deferred, nil
+}
+
+// configure validates the configuration and applies the defaults
+func (trigger *Trigger) configure(config sdkCommon.SerialConfig) error {
+	settings := portSettings{
+		device:   strings.TrimSpace(config.Device),
+		baudRate: config.BaudRate,
+		dataBits: config.DataBits,
+		parity:   strings.ToLower(strings.TrimSpace(config.Parity)),
+		stopBits: config.StopBits,
+	}
+
+	if len(settings.device) == 0 {
+		return errors.New("missing Device for Serial Trigger. Must be present in [Trigger.Serial] section.")
+	}
+
+	if settings.baudRate == 0 {
+		settings.baudRate = defaultBaudRate
+	}
+	if _, supported := baudRates[settings.baudRate]; !supported {
+		return fmt.Errorf("unsupported BaudRate %d for Serial trigger", settings.baudRate)
+	}
+
+	if settings.dataBits == 0 {
+		settings.dataBits = defaultDataBits
+	}
+	if settings.dataBits < 5 || settings.dataBits > 8 {
+		return fmt.Errorf("invalid DataBits %d for Serial trigger. Must be 5 to 8", settings.dataBits)
+	}
+
+	switch settings.parity {
+	case "":
+		settings.parity = ParityNone
+	case ParityNone, ParityOdd, ParityEven:
+	default:
+		return fmt.Errorf("invalid Parity '%s' for Serial trigger. Must be one of '%s', '%s' or '%s'",
+			config.Parity,
+			ParityNone,
+			ParityOdd,
+			ParityEven)
+	}
+
+	if settings.stopBits == 0 {
+		settings.stopBits = defaultStopBits
+	}
+	if settings.stopBits != 1 && settings.stopBits != 2 {
+		return fmt.Errorf("invalid StopBits %d for Serial trigger. Must be 1 or 2", settings.stopBits)
+	}
+
+	if config.FrameLength < 0 {
+		return fmt.Errorf("invalid FrameLength %d for Serial trigger", config.FrameLength)
+	}
+
+	if config.FrameLength > 0 && len(config.Delimiter) > 0 {
+		return errors.New("only one of Delimiter or FrameLength can be set for Serial trigger")
+	}
+
+	delimiter := config.Delimiter
+	if config.FrameLength == 0 && len(delimiter) == 0 {
+		delimiter = defaultDelimiter
+	}
+
+	if len(delimiter) > 0 {
+		// Allows escape sequences such as \r\n or \x03 to be used for the delimiter
+		unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(delimiter, `"`, `\"`) + `"`)
+		if err != nil {
+			return fmt.Errorf("invalid Delimiter '%s' for Serial trigger: %s", config.Delimiter, err.Error())
+		}
+		trigger.delimiter = []byte(unquoted)
+	}
+
+	trigger.maxFrameSize = config.MaxFrameSize
+	if trigger.maxFrameSize <= 0 {
+		trigger.maxFrameSize = defaultMaxFrameSize
+	}
+
+	trigger.frameLength = config.FrameLength
+	trigger.settings = settings
+
+	return nil
+}
+
+// receive reads frames and dispatches them for processing until the service is stopped,
+// re-opening the serial port if reading fails, i.e. a USB adapter is unplugged
+func (trigger *Trigger) receive(appCtx context.Context) {
+	lc := trigger.lc
+
+	for {
+		scanner := bufio.NewScanner(trigger.getPort())
+		scanner.Buffer(make([]byte, 0, 4096), trigger.maxFrameSize)
+		scanner.Split(splitFrames(trigger.delimiter, trigger.frameLength))
+
+		for scanner.Scan() {
+			received := time.Now()
+
+			// Scanner reuses its buffer for the next frame so must make a copy
+			frame := make([]byte, len(scanner.Bytes()))
+			copy(frame, scanner.Bytes())
+
+			envelope := trigger.createEnvelope(frame)
+			trigger.runtime.Dispatch(envelope, func() {
+				trigger.processMessage(envelope, received)
+			})
+		}
+
+		select {
+		case <-appCtx.Done():
+			lc.Info("Exiting waiting for serial port data")
+			return
+		default:
+		}
+
+		err := scanner.Err()
+		if err == nil {
+			err = io.EOF
+		}
+
+		lc.Errorf("Unable to read from serial port '%s' for Serial trigger: %s", trigger.settings.device, err.Error())
+		trigger.closePort()
+
+		if !trigger.reopen(appCtx) {
+			lc.Info("Exiting waiting for serial port data")
+			return
+		}
+	}
+}
+
+// reopen repeatedly attempts to re-open the serial port until successful or the service is stopped
+func (trigger *Trigger) reopen(appCtx context.Context) bool {
+	for {
+		select {
+		case <-appCtx.Done():
+			return false
+		case <-time.After(reopenInterval):
+		}
+
+		port, err := openPort(trigger.settings)
+		if err != nil {
+			trigger.lc.Errorf("Unable to re-open serial port '%s' for Serial trigger: %s", trigger.settings.device, err.Error())
+			continue
+		}
+
+		trigger.setPort(port)
+		trigger.lc.Infof("Re-opened serial port '%s' for Serial trigger", trigger.settings.device)
+		return true
+	}
+}
+
+func (trigger *Trigger) getPort() io.ReadCloser {
+	trigger.portLock.Lock()
+	defer trigger.portLock.Unlock()
+	return trigger.port
+}
+
+func (trigger *Trigger) setPort(port io.ReadCloser) {
+	trigger.portLock.Lock()
+	defer trigger.portLock.Unlock()
+	trigger.port = port
+}
+
+func (trigger *Trigger) closePort() {
+	trigger.portLock.Lock()
+	defer trigger.portLock.Unlock()
+	if trigger.port != nil {
+		_ = trigger.port.Close()
+	}
+}
+
+// createEnvelope returns the envelope for the frame. The device path is used as the received topic.
+func (trigger *Trigger) createEnvelope(frame []byte) types.MessageEnvelope {
+	contentType := common.ContentTypeJSON
+	if len(frame) == 0 || (frame[0] != byte('{') && frame[0] != byte('[')) {
+		// Instruments typically send text, so if not JSON then assume it is text
+		contentType = common.ContentTypeText
+	}
+
+	return types.MessageEnvelope{
+		CorrelationID: uuid.New().String(),
+		Payload:       frame,
+		ContentType:   contentType,
+		ReceivedTopic: trigger.settings.device,
+	}
+}
+
+func (trigger *Trigger) processMessage(envelope types.MessageEnvelope, received time.Time) {
+	lc := trigger.lc
+
+	lc.Debugf("Received frame from Serial Trigger with %d bytes from '%s'. Content-Type=%s",
+		len(envelope.Payload),
+		envelope.ReceivedTopic,
+		envelope.ContentType)
+	lc.Tracef("%s=%s", common.CorrelationHeader, envelope.CorrelationID)
+
+	appContext := appfunction.NewContext(envelope.CorrelationID, trigger.dic, envelope.ContentType)
+	appContext.SetReceived(received)
+
+	// ProcessMessage logs any error, so no need to log it here. There is no response to send for a frame.
+	_ = trigger.runtime.ProcessMessage(appContext, envelope)
+}
@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package serial
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dic *di.Container
+
+func TestMain(m *testing.M) {
+	dic = di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+	m.Run()
+}
+
+func updateConfig(serialConfig sdkCommon.SerialConfig) {
+	config := &sdkCommon.ConfigurationStruct{
+		Trigger: sdkCommon.TriggerInfo{
+			Type:   "serial",
+			Serial: serialConfig,
+		},
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+}
+
+func TestInitializeErrors(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Config     sdkCommon.SerialConfig
+		Background chan interfaces.BackgroundMessage
+	}{
+		{"Background not supported", sdkCommon.SerialConfig{Device: "/dev/ttyS0"}, make(chan interfaces.BackgroundMessage)},
+		{"Missing Device", sdkCommon.SerialConfig{}, nil},
+		{"Bad BaudRate", sdkCommon.SerialConfig{Device: "/dev/ttyS0", BaudRate: 1234}, nil},
+		{"Bad DataBits", sdkCommon.SerialConfig{Device: "/dev/ttyS0", DataBits: 9}, nil},
+		{"Bad Parity", sdkCommon.SerialConfig{Device: "/dev/ttyS0", Parity: "mark"}, nil},
+		{"Bad StopBits", sdkCommon.SerialConfig{Device: "/dev/ttyS0", StopBits: 3}, nil},
+		{"Bad FrameLength", sdkCommon.SerialConfig{Device: "/dev/ttyS0", FrameLength: -1}, nil},
+		{"Delimiter and FrameLength", sdkCommon.SerialConfig{Device: "/dev/ttyS0", Delimiter: `\n`, FrameLength: 8}, nil},
+		{"Bad Delimiter", sdkCommon.SerialConfig{Device: "/dev/ttyS0", Delimiter: `\x`}, nil},
+		{"Missing device file", sdkCommon.SerialConfig{Device: "/dev/does-not-exist"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			updateConfig(test.Config)
+
+			trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+			_, err := trigger.Initialize(&sync.WaitGroup{}, context.Background(), test.Background)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	trigger := Trigger{}
+
+	require.NoError(t, trigger.configure(sdkCommon.SerialConfig{Device: " /dev/ttyUSB0 "}))
+	assert.Equal(t, portSettings{device: "/dev/ttyUSB0", baudRate: 9600, dataBits: 8, parity: ParityNone, stopBits: 1}, trigger.settings)
+	assert.Equal(t, []byte("\n"), trigger.delimiter)
+	assert.Equal(t, defaultMaxFrameSize, trigger.maxFrameSize)
+
+	require.NoError(t, trigger.configure(sdkCommon.SerialConfig{Device: "/dev/ttyUSB0", BaudRate: 115200, Parity: "Even", StopBits: 2, Delimiter: `\r\n`}))
+	assert.Equal(t, portSettings{device: "/dev/ttyUSB0", baudRate: 115200, dataBits: 8, parity: ParityEven, stopBits: 2}, trigger.settings)
+	assert.Equal(t, []byte("\r\n"), trigger.delimiter)
+
+	trigger = Trigger{}
+	require.NoError(t, trigger.configure(sdkCommon.SerialConfig{Device: "/dev/ttyUSB0", FrameLength: 4}))
+	assert.Empty(t, trigger.delimiter)
+	assert.Equal(t, 4, trigger.frameLength)
+}
+
+func scanFrames(t *testing.T, data string, delimiter []byte, frameLength int) []string {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Split(splitFrames(delimiter, frameLength))
+
+	var frames []string
+	for scanner.Scan() {
+		frames = append(frames, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+
+	return frames
+}
+
+func TestSplitFrames(t *testing.T) {
+	assert.Equal(t, []string{"T=21.5", "T=21.6"}, scanFrames(t, "T=21.5\r\n\r\nT=21.6\r\npartial", []byte("\r\n"), 0))
+	assert.Equal(t, []string{"\x02ABC", "\x02DEF"}, scanFrames(t, "\x02ABC\x03\x02DEF\x03", []byte{0x03}, 0))
+	assert.Equal(t, []string{"ABCD", "EFGH"}, scanFrames(t, "ABCDEFGHIJ", nil, 4))
+	assert.Empty(t, scanFrames(t, "\n\n", []byte("\n"), 0))
+}
+
+func TestCreateEnvelope(t *testing.T) {
+	trigger := Trigger{settings: portSettings{device: "/dev/ttyUSB0"}}
+
+	envelope := trigger.createEnvelope([]byte(`{"a":1}`))
+	assert.Equal(t, common.ContentTypeJSON, envelope.ContentType)
+	assert.Equal(t, "/dev/ttyUSB0", envelope.ReceivedTopic)
+	assert.NotEmpty(t, envelope.CorrelationID)
+
+	envelope = trigger.createEnvelope([]byte("T=21.5"))
+	assert.Equal(t, common.ContentTypeText, envelope.ContentType)
+}
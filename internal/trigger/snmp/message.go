@@ -0,0 +1,293 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package snmp
+
+import (
+	"crypto/subtle"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	versionV2c = 1
+	versionV3  = 3
+
+	flagAuth = 0x01
+	flagPriv = 0x02
+
+	// privParamsLength is the length of the salt sent in the privacy parameters for both DES and AES
+	privParamsLength = 8
+
+	oidSysUpTime   = "1.3.6.1.2.1.1.3.0"
+	oidSnmpTrapOID = "1.3.6.1.6.3.1.1.4.1.0"
+)
+
+// TrapMessage is the JSON form of a received SNMPv2c or SNMPv3 trap
+type TrapMessage struct {
+	Version     string     `json:"version"`
+	Source      string     `json:"source"`
+	User        string     `json:"user,omitempty"`
+	EngineID    string     `json:"engineId,omitempty"`
+	ContextName string     `json:"contextName,omitempty"`
+	RequestID   int64      `json:"requestId"`
+	Uptime      uint64     `json:"uptime"`
+	TrapOID     string     `json:"trapOid"`
+	Variables   []Variable `json:"variables,omitempty"`
+}
+
+// Variable is a variable binding sent with a trap
+type Variable struct {
+	OID   string      `json:"oid"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// v3Message is the header of an SNMPv3 message. It is checked against the configured user before the
+// message is decoded by gosnmp, which neither enforces the security level nor validates the lengths of the
+// authentication and privacy parameters. It only compares as much of the digest as the message contains.
+// The integers which aren't checked are left raw as agents, gosnmp included, don't always encode them
+// minimally, which encoding/asn1 rejects.
+type v3Message struct {
+	Version    int
+	GlobalData struct {
+		MsgID         asn1.RawValue
+		MaxSize       asn1.RawValue
+		Flags         []byte
+		SecurityModel int
+	}
+	SecurityParameters []byte
+	ScopedPDU          asn1.RawValue
+}
+
+// usmSecurityParameters are the User-based Security Model parameters of an SNMPv3 message
+type usmSecurityParameters struct {
+	EngineID                 []byte
+	EngineBoots              asn1.RawValue
+	EngineTime               asn1.RawValue
+	UserName                 []byte
+	AuthenticationParameters []byte
+	PrivacyParameters        []byte
+}
+
+// parseMessage decodes the trap, verifying the community for SNMPv2c or the user's
+// authentication and decrypting the PDU for SNMPv3
+func (trigger *Trigger) parseMessage(data []byte, source string) (trap TrapMessage, err error) {
+	trap = TrapMessage{Source: source}
+
+	// A malformed trap must not stop the receive loop should gosnmp fail to decode it safely
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("invalid SNMP message: %v", recovered)
+		}
+	}()
+
+	var message asn1.RawValue
+	if _, err := asn1.Unmarshal(data, &message); err != nil {
+		return trap, fmt.Errorf("invalid SNMP message: %s", err.Error())
+	}
+
+	var version int
+	if _, err := asn1.Unmarshal(message.Bytes, &version); err != nil {
+		return trap, fmt.Errorf("invalid SNMP version: %s", err.Error())
+	}
+
+	var packet *gosnmp.SnmpPacket
+
+	switch version {
+	case versionV2c:
+		trap.Version = "v2c"
+		packet, err = trigger.decodeV2c(data)
+
+	case versionV3:
+		trap.Version = "v3"
+		packet, err = trigger.decodeV3(data, &trap)
+
+	default:
+		return trap, fmt.Errorf("unsupported SNMP version %d. Only SNMPv2c and SNMPv3 traps are supported", version)
+	}
+
+	if err != nil {
+		return trap, err
+	}
+
+	if err := parsePDU(packet, &trap); err != nil {
+		return trap, err
+	}
+
+	return trap, nil
+}
+
+// decodeV2c decodes the SNMPv2c message and verifies its community
+func (trigger *Trigger) decodeV2c(data []byte) (*gosnmp.SnmpPacket, error) {
+	decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c}
+	packet, err := decoder.SnmpDecodePacket(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(trigger.community) > 0 &&
+		subtle.ConstantTimeCompare([]byte(packet.Community), []byte(trigger.community)) != 1 {
+		return nil, errors.New("community does not match")
+	}
+
+	return packet, nil
+}
+
+// decodeV3 verifies the security parameters of the SNMPv3 message match the configured user before
+// authenticating, decrypting and decoding it
+func (trigger *Trigger) decodeV3(data []byte, trap *TrapMessage) (*gosnmp.SnmpPacket, error) {
+	user := trigger.user
+	if user == nil {
+		return nil, errors.New("SNMPv3 traps are not accepted as no username has been configured")
+	}
+
+	var message v3Message
+	if _, err := asn1.Unmarshal(data, &message); err != nil {
+		return nil, fmt.Errorf("invalid SNMPv3 message: %s", err.Error())
+	}
+
+	if len(message.GlobalData.Flags) != 1 {
+		return nil, errors.New("invalid SNMPv3 message flags")
+	}
+	flags := message.GlobalData.Flags[0]
+
+	if message.GlobalData.SecurityModel != int(gosnmp.UserSecurityModel) {
+		return nil, fmt.Errorf("unsupported SNMPv3 security model %d", message.GlobalData.SecurityModel)
+	}
+
+	var security usmSecurityParameters
+	if _, err := asn1.Unmarshal(message.SecurityParameters, &security); err != nil {
+		return nil, fmt.Errorf("invalid SNMPv3 security parameters: %s", err.Error())
+	}
+
+	trap.EngineID = hex.EncodeToString(security.EngineID)
+	trap.User = string(security.UserName)
+
+	if trap.User != user.name {
+		return nil, fmt.Errorf("unknown SNMPv3 user '%s'", trap.User)
+	}
+
+	hasAuth := flags&flagAuth != 0
+	hasPriv := flags&flagPriv != 0
+	encrypted := message.ScopedPDU.Class == asn1.ClassUniversal && message.ScopedPDU.Tag == asn1.TagOctetString
+
+	switch {
+	case hasPriv && !hasAuth:
+		return nil, errors.New("invalid SNMPv3 message flags: privacy requires authentication")
+	case len(user.authPassword) > 0 && !hasAuth:
+		return nil, errors.New("SNMPv3 trap is not authenticated as required for the user")
+	case len(user.privPassword) > 0 && !hasPriv:
+		return nil, errors.New("SNMPv3 trap is not encrypted as required for the user")
+	case len(user.authPassword) == 0 && hasAuth:
+		return nil, errors.New("SNMPv3 trap is authenticated but no authpassword has been configured for the user")
+	case len(user.privPassword) == 0 && hasPriv:
+		return nil, errors.New("SNMPv3 trap is encrypted but no privpassword has been configured for the user")
+	case hasAuth && len(security.AuthenticationParameters) != user.authParamsLength():
+		return nil, fmt.Errorf("invalid authentication parameters length %d", len(security.AuthenticationParameters))
+	case hasPriv && len(security.PrivacyParameters) != privParamsLength:
+		return nil, fmt.Errorf("invalid privacy parameters length %d", len(security.PrivacyParameters))
+	case hasPriv != encrypted:
+		return nil, errors.New("SNMPv3 scoped PDU does not match the message flags")
+	}
+
+	// Uses the security parameters from the message, with the user's keys localized to its engine ID
+	packet := user.decoder(string(security.EngineID)).UnmarshalTrap(data, true)
+	if packet == nil {
+		return nil, errors.New("SNMPv3 trap authentication failed or it could not be decoded")
+	}
+
+	trap.ContextName = packet.ContextName
+
+	return packet, nil
+}
+
+// parsePDU copies the SNMPv2-Trap PDU into the trap
+func parsePDU(packet *gosnmp.SnmpPacket, trap *TrapMessage) error {
+	switch packet.PDUType {
+	case gosnmp.SNMPv2Trap:
+	case gosnmp.InformRequest:
+		return errors.New("InformRequest PDUs are not supported, only SNMPv2-Trap PDUs")
+	default:
+		return fmt.Errorf("unexpected PDU type 0x%02x, only SNMPv2-Trap PDUs are supported", byte(packet.PDUType))
+	}
+
+	trap.RequestID = int64(packet.RequestID)
+
+	for _, variable := range packet.Variables {
+		oid := strings.TrimPrefix(variable.Name, ".")
+		value := variableValue(variable)
+
+		switch oid {
+		case oidSysUpTime:
+			ticks, _ := value.(uint32)
+			trap.Uptime = uint64(ticks)
+		case oidSnmpTrapOID:
+			trap.TrapOID, _ = value.(string)
+		default:
+			trap.Variables = append(trap.Variables, Variable{OID: oid, Type: variable.Type.String(), Value: value})
+		}
+	}
+
+	if len(trap.TrapOID) == 0 {
+		return errors.New("trap is missing snmpTrapOID.0")
+	}
+
+	return nil
+}
+
+// variableValue returns the value decoded by gosnmp in the form sent in the JSON
+func variableValue(variable gosnmp.SnmpPDU) interface{} {
+	switch value := variable.Value.(type) {
+	case []byte:
+		if variable.Type == gosnmp.OctetString {
+			return octetString(value)
+		}
+		return hex.EncodeToString(value)
+
+	case string:
+		if variable.Type == gosnmp.ObjectIdentifier {
+			return strings.TrimPrefix(value, ".")
+		}
+	}
+
+	return variable.Value
+}
+
+// octetString returns the value as text when it is printable, otherwise as hex
+func octetString(value []byte) string {
+	if utf8.Valid(value) {
+		printable := true
+		for _, r := range string(value) {
+			if r < ' ' && r != '\t' && r != '\r' && r != '\n' {
+				printable = false
+				break
+			}
+		}
+
+		if printable {
+			return string(value)
+		}
+	}
+
+	return hex.EncodeToString(value)
+}
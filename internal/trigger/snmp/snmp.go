@@ -0,0 +1,244 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package snmp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPort = 162
+
+	// maxDatagramSize is the largest UDP payload possible over IPv4
+	maxDatagramSize = 65507
+)
+
+// Trigger implements Trigger to support receiving SNMPv2c and SNMPv3 traps
+type Trigger struct {
+	dic       *di.Container
+	lc        logger.LoggingClient
+	runtime   *runtime.GolangRuntime
+	community string
+	user      *usmUser
+	conn      net.PacketConn
+}
+
+func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime) *Trigger {
+	return &Trigger{
+		dic:     dic,
+		runtime: runtime,
+		lc:      bootstrapContainer.LoggingClientFrom(dic.Get),
+	}
+}
+
+// Initialize initializes the Trigger to listen for SNMP traps
+func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context, background <-chan interfaces.BackgroundMessage) (bootstrap.Deferred, error) {
+	// Convenience short cuts
+	lc := trigger.lc
+	config := container.ConfigurationFrom(trigger.dic.Get)
+	snmpConfig := config.Trigger.SNMP
+
+	lc.Info("Initializing SNMP Trigger")
+
+	if background != nil {
+		return nil, errors.New("background publishing not supported for services using SNMP trigger")
+	}
+
+	if err := trigger.loadCredentials(snmpConfig); err != nil {
+		return nil, err
+	}
+
+	port := snmpConfig.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	address := net.JoinHostPort(snmpConfig.Host, strconv.Itoa(port))
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s for SNMP trigger: %s", address, err.Error())
+	}
+	trigger.conn = conn
+
+	lc.Infof("Listening for SNMP traps on %s for SNMP trigger", conn.LocalAddr().String())
+
+	appWg.Add(1)
+	go func() {
+		defer appWg.Done()
+		trigger.receive(appCtx)
+	}()
+
+	// Closing the connection unblocks the receive loop when the service is stopped
+	go func() {
+		<-appCtx.Done()
+		_ = conn.Close()
+	}()
+
+	deferred := func() {
+		lc.Info("Closing listener for SNMP trigger")
+		_ = conn.Close()
+	}
+
+	return deferred, nil
+}
+
+// loadCredentials loads the SNMPv2c community and the SNMPv3 user from the secret path.
+// When no secret path is configured SNMPv2c traps are accepted with any community and SNMPv3 traps are rejected.
+func (trigger *Trigger) loadCredentials(config sdkCommon.SNMPConfig) error {
+	if len(config.SecretPath) == 0 {
+		return nil
+	}
+
+	// Must provide a dummy AppFunctionContext which will provide access to GetSecret
+	appContext := appfunction.NewContext("", trigger.dic, "")
+	secrets, err := appContext.GetSecret(config.SecretPath)
+	if err != nil {
+		return fmt.Errorf("unable to get credentials for SNMP trigger from secret path '%s': %s",
+			config.SecretPath,
+			err.Error())
+	}
+
+	trigger.community = secrets["community"]
+
+	if len(secrets["username"]) > 0 {
+		trigger.user, err = newUSMUser(
+			secrets["username"],
+			config.AuthProtocol,
+			secrets["authpassword"],
+			config.PrivProtocol,
+			secrets["privpassword"])
+		if err != nil {
+			return fmt.Errorf("invalid SNMPv3 credentials in secret path '%s' for SNMP trigger: %s",
+				config.SecretPath,
+				err.Error())
+		}
+	}
+
+	return nil
+}
+
+// receive reads traps and dispatches them for processing until the service is stopped
+func (trigger *Trigger) receive(appCtx context.Context) {
+	lc := trigger.lc
+	buffer := make([]byte, maxDatagramSize)
+
+	for {
+		count, sender, err := trigger.conn.ReadFrom(buffer)
+		if err != nil {
+			select {
+			case <-appCtx.Done():
+				lc.Info("Exiting waiting for SNMP traps")
+				return
+			default:
+			}
+
+			if errors.Is(err, net.ErrClosed) {
+				lc.Info("Exiting waiting for SNMP traps")
+				return
+			}
+
+			lc.Errorf("Unable to receive trap for SNMP trigger: %s", err.Error())
+			continue
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		received := time.Now()
+
+		// Buffer is reused for the next trap so must make a copy
+		data := make([]byte, count)
+		copy(data, buffer[:count])
+
+		envelope, err := trigger.createEnvelope(data, sender)
+		if err != nil {
+			lc.Errorf("Unable to parse trap from %s for SNMP trigger: %s", sender.String(), err.Error())
+			continue
+		}
+
+		trigger.runtime.Dispatch(envelope, func() {
+			trigger.processMessage(envelope, received)
+		})
+	}
+}
+
+// createEnvelope parses the trap and returns the envelope to process. The sender's IP address is used as
+// the received topic.
+func (trigger *Trigger) createEnvelope(data []byte, sender net.Addr) (types.MessageEnvelope, error) {
+	source := sender.String()
+	if udpAddr, ok := sender.(*net.UDPAddr); ok {
+		source = udpAddr.IP.String()
+	}
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: uuid.New().String(),
+		ReceivedTopic: source,
+		ContentType:   common.ContentTypeJSON,
+	}
+
+	trap, err := trigger.parseMessage(data, source)
+	if err != nil {
+		return envelope, err
+	}
+
+	envelope.Payload, err = json.Marshal(trap)
+	if err != nil {
+		return envelope, err
+	}
+
+	return envelope, nil
+}
+
+func (trigger *Trigger) processMessage(envelope types.MessageEnvelope, received time.Time) {
+	lc := trigger.lc
+
+	lc.Debugf("Received trap from SNMP Trigger with %d bytes from '%s'. Content-Type=%s",
+		len(envelope.Payload),
+		envelope.ReceivedTopic,
+		envelope.ContentType)
+	lc.Tracef("%s=%s", common.CorrelationHeader, envelope.CorrelationID)
+
+	appContext := appfunction.NewContext(envelope.CorrelationID, trigger.dic, envelope.ContentType)
+	appContext.SetReceived(received)
+
+	// ProcessMessage logs any error, so no need to log it here. There is no response to send for a trap.
+	_ = trigger.runtime.ProcessMessage(appContext, envelope)
+}
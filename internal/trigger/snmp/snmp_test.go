@@ -0,0 +1,371 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package snmp
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dic *di.Container
+
+func TestMain(m *testing.M) {
+	dic = di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+	m.Run()
+}
+
+func updateConfig(snmpConfig sdkCommon.SNMPConfig) {
+	config := &sdkCommon.ConfigurationStruct{
+		Trigger: sdkCommon.TriggerInfo{
+			Type: "snmp",
+			SNMP: snmpConfig,
+		},
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+}
+
+func updateSecrets(secrets map[string]string, err error) {
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", "snmp").Return(secrets, err)
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+}
+
+var trapVariables = []gosnmp.SnmpPDU{
+	{Name: "." + oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(10000000)},
+	{Name: "." + oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+	{Name: ".1.3.6.1.2.1.2.2.1.1.2", Type: gosnmp.Integer, Value: 2},
+	{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: gosnmp.OctetString, Value: "eth1"},
+	{Name: ".1.3.6.1.2.1.4.20.1.1.10", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+	{Name: ".1.3.6.1.2.1.31.1.1.1.6.2", Type: gosnmp.Counter64, Value: uint64(4294967296)},
+	{Name: ".1.3.6.1.4.1.9.9.1.0", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1b, 0x2c}},
+}
+
+var expectedVariables = []Variable{
+	{OID: "1.3.6.1.2.1.2.2.1.1.2", Type: "Integer", Value: 2},
+	{OID: "1.3.6.1.2.1.2.2.1.2.2", Type: "OctetString", Value: "eth1"},
+	{OID: "1.3.6.1.2.1.4.20.1.1.10", Type: "IPAddress", Value: "10.0.0.1"},
+	{OID: "1.3.6.1.2.1.31.1.1.1.6.2", Type: "Counter64", Value: uint64(4294967296)},
+	{OID: "1.3.6.1.4.1.9.9.1.0", Type: "OctetString", Value: "001b2c"},
+}
+
+func marshalTrap(t *testing.T, version gosnmp.SnmpVersion, community string, pduType gosnmp.PDUType) []byte {
+	packet := &gosnmp.SnmpPacket{
+		Version:   version,
+		Community: community,
+		PDUType:   pduType,
+		RequestID: 1234,
+		Variables: trapVariables,
+	}
+	// Only used by SNMPv1 traps
+	packet.Enterprise = ".1.3.6.1.4.1.9"
+	packet.AgentAddress = "10.0.0.1"
+
+	data, err := packet.MarshalMsg()
+	require.NoError(t, err)
+	return data
+}
+
+func v2cTrap(t *testing.T, community string) []byte {
+	return marshalTrap(t, gosnmp.Version2c, community, gosnmp.SNMPv2Trap)
+}
+
+var engineID = []byte{0x80, 0x00, 0x1f, 0x88, 0x80, 0x01, 0x02, 0x03, 0x04}
+
+// v3Trap sends an SNMPv3 trap from the user with gosnmp, authenticating and encrypting it when the user
+// has the passwords, and returns the datagram sent
+func v3Trap(t *testing.T, user *usmUser) []byte {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	sender := &gosnmp.GoSNMP{
+		Target:             "127.0.0.1",
+		Port:               uint16(listener.LocalAddr().(*net.UDPAddr).Port),
+		Version:            gosnmp.Version3,
+		Timeout:            time.Second,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           user.msgFlags(),
+		SecurityParameters: user.securityParameters(string(engineID)),
+		ContextName:        "context",
+	}
+	require.NoError(t, sender.Connect())
+	defer func() { _ = sender.Conn.Close() }()
+
+	_, err = sender.SendTrap(gosnmp.SnmpTrap{Variables: trapVariables})
+	require.NoError(t, err)
+
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buffer := make([]byte, maxDatagramSize)
+	count, _, err := listener.ReadFrom(buffer)
+	require.NoError(t, err)
+
+	return buffer[:count]
+}
+
+func TestNewUSMUser(t *testing.T) {
+	user, err := newUSMUser("user", "", "authpassword", "", "privpassword")
+	require.NoError(t, err)
+	assert.Equal(t, AuthProtocolSHA, user.authProtocol)
+	assert.Equal(t, PrivProtocolAES, user.privProtocol)
+	assert.Equal(t, gosnmp.AuthPriv, user.msgFlags())
+
+	_, err = newUSMUser("user", "", "", "", "privpassword")
+	assert.Error(t, err, "privacy requires authentication")
+	_, err = newUSMUser("user", "SHA512", "authpassword", "", "")
+	assert.Error(t, err, "unsupported auth protocol")
+	_, err = newUSMUser("user", "", "authpassword", "3DES", "privpassword")
+	assert.Error(t, err, "unsupported priv protocol")
+	_, err = newUSMUser("user", "", "short", "", "")
+	assert.Error(t, err, "auth password too short")
+}
+
+func TestParseMessage_V2c(t *testing.T) {
+	trigger := Trigger{}
+
+	trap, err := trigger.parseMessage(v2cTrap(t, "public"), "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "v2c", trap.Version)
+	assert.Equal(t, "10.0.0.1", trap.Source)
+	assert.Equal(t, int64(1234), trap.RequestID)
+	assert.Equal(t, uint64(10000000), trap.Uptime)
+	assert.Equal(t, "1.3.6.1.6.3.1.1.5.3", trap.TrapOID)
+	assert.Equal(t, expectedVariables, trap.Variables)
+
+	trigger.community = "private"
+	_, err = trigger.parseMessage(v2cTrap(t, "public"), "10.0.0.1")
+	assert.Error(t, err, "community mismatch")
+
+	_, err = trigger.parseMessage(v2cTrap(t, "private"), "10.0.0.1")
+	assert.NoError(t, err)
+
+	_, err = trigger.parseMessage(marshalTrap(t, gosnmp.Version1, "private", gosnmp.Trap), "10.0.0.1")
+	assert.Error(t, err, "SNMPv1 not supported")
+
+	_, err = trigger.parseMessage(marshalTrap(t, gosnmp.Version2c, "private", gosnmp.InformRequest), "10.0.0.1")
+	assert.Error(t, err, "InformRequest not supported")
+
+	_, err = trigger.parseMessage(v2cTrap(t, "private")[:20], "10.0.0.1")
+	assert.Error(t, err, "truncated")
+}
+
+func TestParseMessage_V3(t *testing.T) {
+	tests := []struct {
+		Name         string
+		AuthProtocol string
+		AuthPassword string
+		PrivProtocol string
+		PrivPassword string
+	}{
+		{"noAuthNoPriv", "", "", "", ""},
+		{"authNoPriv SHA", AuthProtocolSHA, "authpassword", "", ""},
+		{"authPriv MD5 DES", AuthProtocolMD5, "authpassword", PrivProtocolDES, "privpassword"},
+		{"authPriv SHA256 AES", AuthProtocolSHA256, "authpassword", PrivProtocolAES, "privpassword"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			user, err := newUSMUser("user", test.AuthProtocol, test.AuthPassword, test.PrivProtocol, test.PrivPassword)
+			require.NoError(t, err)
+			trigger := Trigger{user: user}
+
+			trap, err := trigger.parseMessage(v3Trap(t, user), "10.0.0.1")
+			require.NoError(t, err)
+			assert.Equal(t, "v3", trap.Version)
+			assert.Equal(t, "user", trap.User)
+			assert.Equal(t, hex.EncodeToString(engineID), trap.EngineID)
+			assert.Equal(t, "context", trap.ContextName)
+			assert.Equal(t, uint64(10000000), trap.Uptime)
+			assert.Equal(t, "1.3.6.1.6.3.1.1.5.3", trap.TrapOID)
+			assert.Equal(t, expectedVariables, trap.Variables)
+		})
+	}
+}
+
+func TestParseMessage_V3Errors(t *testing.T) {
+	authUser, err := newUSMUser("user", "", "authpassword", "", "")
+	require.NoError(t, err)
+	privUser, err := newUSMUser("user", "", "authpassword", "", "privpassword")
+	require.NoError(t, err)
+	noAuthUser, err := newUSMUser("user", "", "", "", "")
+	require.NoError(t, err)
+	wrongPassword, err := newUSMUser("user", "", "wrongpassword", "", "")
+	require.NoError(t, err)
+	otherUser, err := newUSMUser("other", "", "authpassword", "", "")
+	require.NoError(t, err)
+
+	tampered := v3Trap(t, authUser)
+	tampered[len(tampered)-1] ^= 0xff
+
+	// Claims to be authenticated by setting the auth flag, which is followed by the USM security model,
+	// without any authentication parameters. gosnmp always sets the reportable flag.
+	unauthenticated := v3Trap(t, noAuthUser)
+	flags := byte(gosnmp.Reportable)
+	flagsOffset := bytes.Index(unauthenticated, []byte{0x04, 0x01, flags, 0x02, 0x01, 0x03})
+	require.True(t, flagsOffset > 0)
+	unauthenticated[flagsOffset+2] = flags | flagAuth
+
+	tests := []struct {
+		Name          string
+		Configured    *usmUser
+		Message       []byte
+		ErrorContains string
+	}{
+		{"No user configured", nil, v3Trap(t, authUser), "no username"},
+		{"Unknown user", authUser, v3Trap(t, otherUser), "unknown SNMPv3 user"},
+		{"Wrong password", authUser, v3Trap(t, wrongPassword), "authentication failed"},
+		{"Tampered", authUser, tampered, "authentication failed"},
+		{"Missing authentication parameters", authUser, unauthenticated, "invalid authentication parameters"},
+		{"Not authenticated", authUser, v3Trap(t, noAuthUser), "not authenticated"},
+		{"Not encrypted", privUser, v3Trap(t, authUser), "not encrypted"},
+		{"Authenticated without password", noAuthUser, v3Trap(t, authUser), "no authpassword"},
+		{"Encrypted without password", authUser, v3Trap(t, privUser), "no privpassword"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			trigger := Trigger{user: test.Configured}
+			_, err := trigger.parseMessage(test.Message, "10.0.0.1")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ErrorContains)
+		})
+	}
+}
+
+func TestInitializeErrors(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Secrets    map[string]string
+		SecretErr  error
+		Background chan interfaces.BackgroundMessage
+	}{
+		{"Background not supported", nil, nil, make(chan interfaces.BackgroundMessage)},
+		{"Secrets not found", nil, errors.New("not found"), nil},
+		{"Invalid SNMPv3 user", map[string]string{"username": "user", "authpassword": "short"}, nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			updateSecrets(test.Secrets, test.SecretErr)
+			updateConfig(sdkCommon.SNMPConfig{Host: "127.0.0.1", SecretPath: "snmp"})
+
+			trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+			_, err := trigger.Initialize(&sync.WaitGroup{}, context.Background(), test.Background)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestInitializeAndReceive(t *testing.T) {
+	// Find a free port since port 0 selects the default SNMP trap port
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.LocalAddr().(*net.UDPAddr).Port
+	require.NoError(t, listener.Close())
+
+	updateSecrets(map[string]string{"community": "private"}, nil)
+	updateConfig(sdkCommon.SNMPConfig{Host: "127.0.0.1", Port: port, SecretPath: "snmp"})
+
+	received := make(chan []byte, 1)
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		received <- data.([]byte)
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{TargetType: &[]byte{}}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	trigger := NewTrigger(dic, goRuntime)
+	deferred, err := trigger.Initialize(wg, ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, deferred)
+
+	conn, err := net.Dial("udp", trigger.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	// Traps with the wrong community are skipped
+	_, err = conn.Write(v2cTrap(t, "public"))
+	require.NoError(t, err)
+	_, err = conn.Write(v2cTrap(t, "private"))
+	require.NoError(t, err)
+
+	select {
+	case data := <-received:
+		var trap TrapMessage
+		require.NoError(t, json.Unmarshal(data, &trap))
+		assert.Equal(t, "v2c", trap.Version)
+		assert.Equal(t, "127.0.0.1", trap.Source)
+		assert.Equal(t, "1.3.6.1.6.3.1.1.5.3", trap.TrapOID)
+		assert.Len(t, trap.Variables, len(expectedVariables))
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "trap not received")
+	}
+
+	cancel()
+	wg.Wait()
+	deferred()
+}
+
+func TestCreateEnvelope(t *testing.T) {
+	trigger := Trigger{}
+
+	envelope, err := trigger.createEnvelope(v2cTrap(t, "public"), &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000})
+	require.NoError(t, err)
+	assert.Equal(t, common.ContentTypeJSON, envelope.ContentType)
+	assert.Equal(t, "10.0.0.1", envelope.ReceivedTopic)
+	assert.NotEmpty(t, envelope.CorrelationID)
+	assert.Contains(t, string(envelope.Payload), `"trapOid":"1.3.6.1.6.3.1.1.5.3"`)
+}
@@ -0,0 +1,178 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	AuthProtocolMD5    = "MD5"
+	AuthProtocolSHA    = "SHA"
+	AuthProtocolSHA256 = "SHA256"
+
+	PrivProtocolDES = "DES"
+	PrivProtocolAES = "AES"
+
+	// minPasswordLength is the shortest password permitted by RFC 3414
+	minPasswordLength = 8
+	// maxCachedEngines limits the number of engines decoders, with their localized keys, are cached for
+	maxCachedEngines = 256
+)
+
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	AuthProtocolMD5:    gosnmp.MD5,
+	AuthProtocolSHA:    gosnmp.SHA,
+	AuthProtocolSHA256: gosnmp.SHA256,
+}
+
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	PrivProtocolDES: gosnmp.DES,
+	PrivProtocolAES: gosnmp.AES,
+}
+
+// usmUser is the SNMPv3 User-based Security Model user which traps must be sent by
+type usmUser struct {
+	name         string
+	authProtocol string
+	authPassword string
+	privProtocol string
+	privPassword string
+	// decoders are the trap decoders for each engine ID. Only accessed from the receive loop so needs no lock.
+	decoders map[string]*gosnmp.GoSNMP
+}
+
+func newUSMUser(name, authProtocol, authPassword, privProtocol, privPassword string) (*usmUser, error) {
+	user := &usmUser{
+		name:         name,
+		authProtocol: strings.ToUpper(strings.TrimSpace(authProtocol)),
+		authPassword: authPassword,
+		privProtocol: strings.ToUpper(strings.TrimSpace(privProtocol)),
+		privPassword: privPassword,
+		decoders:     make(map[string]*gosnmp.GoSNMP),
+	}
+
+	if len(user.privPassword) > 0 && len(user.authPassword) == 0 {
+		return nil, errors.New("authpassword is required when privpassword is specified")
+	}
+
+	if len(user.authPassword) > 0 {
+		if len(user.authProtocol) == 0 {
+			user.authProtocol = AuthProtocolSHA
+		}
+
+		if _, ok := authProtocols[user.authProtocol]; !ok {
+			return nil, fmt.Errorf("invalid AuthProtocol '%s'. Must be one of '%s', '%s' or '%s'",
+				authProtocol,
+				AuthProtocolMD5,
+				AuthProtocolSHA,
+				AuthProtocolSHA256)
+		}
+
+		if len(user.authPassword) < minPasswordLength {
+			return nil, fmt.Errorf("authpassword must be at least %d characters", minPasswordLength)
+		}
+	}
+
+	if len(user.privPassword) > 0 {
+		if len(user.privProtocol) == 0 {
+			user.privProtocol = PrivProtocolAES
+		}
+
+		if _, ok := privProtocols[user.privProtocol]; !ok {
+			return nil, fmt.Errorf("invalid PrivProtocol '%s'. Must be '%s' or '%s'",
+				privProtocol,
+				PrivProtocolDES,
+				PrivProtocolAES)
+		}
+
+		if len(user.privPassword) < minPasswordLength {
+			return nil, fmt.Errorf("privpassword must be at least %d characters", minPasswordLength)
+		}
+	}
+
+	return user, nil
+}
+
+// securityParameters returns the user's USM security parameters for the engine ID
+func (user *usmUser) securityParameters(engineID string) *gosnmp.UsmSecurityParameters {
+	parameters := &gosnmp.UsmSecurityParameters{
+		AuthoritativeEngineID:  engineID,
+		UserName:               user.name,
+		AuthenticationProtocol: gosnmp.NoAuth,
+		PrivacyProtocol:        gosnmp.NoPriv,
+	}
+
+	if len(user.authPassword) > 0 {
+		parameters.AuthenticationProtocol = authProtocols[user.authProtocol]
+		parameters.AuthenticationPassphrase = user.authPassword
+	}
+
+	if len(user.privPassword) > 0 {
+		parameters.PrivacyProtocol = privProtocols[user.privProtocol]
+		parameters.PrivacyPassphrase = user.privPassword
+	}
+
+	return parameters
+}
+
+// msgFlags returns the security level traps from the user must be sent with
+func (user *usmUser) msgFlags() gosnmp.SnmpV3MsgFlags {
+	switch {
+	case len(user.privPassword) > 0:
+		return gosnmp.AuthPriv
+	case len(user.authPassword) > 0:
+		return gosnmp.AuthNoPriv
+	default:
+		return gosnmp.NoAuthNoPriv
+	}
+}
+
+// authParamsLength is the length of the truncated HMAC sent in the authentication parameters
+func (user *usmUser) authParamsLength() int {
+	if user.authProtocol == AuthProtocolSHA256 {
+		// RFC 7860
+		return 24
+	}
+	return 12
+}
+
+// decoder returns the trap decoder for the engine ID. Its keys are localized to the engine ID, as described
+// in RFC 3414 section 2.6, when it is created, so it is cached to avoid repeating this for every trap.
+func (user *usmUser) decoder(engineID string) *gosnmp.GoSNMP {
+	if decoder, found := user.decoders[engineID]; found {
+		return decoder
+	}
+
+	if len(user.decoders) >= maxCachedEngines {
+		user.decoders = make(map[string]*gosnmp.GoSNMP)
+	}
+
+	decoder := &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           user.msgFlags(),
+		SecurityParameters: user.securityParameters(engineID),
+	}
+
+	user.decoders[engineID] = decoder
+	return decoder
+}
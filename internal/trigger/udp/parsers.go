@@ -0,0 +1,255 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package udp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// syslogNilValue is used in RFC 5424 messages for fields that have no value
+const syslogNilValue = "-"
+
+// SyslogMessage is the JSON form of a parsed RFC 5424 or RFC 3164 syslog message
+type SyslogMessage struct {
+	Facility       int    `json:"facility"`
+	Severity       int    `json:"severity"`
+	Version        int    `json:"version,omitempty"`
+	Timestamp      string `json:"timestamp,omitempty"`
+	Hostname       string `json:"hostname,omitempty"`
+	AppName        string `json:"appName,omitempty"`
+	ProcID         string `json:"procId,omitempty"`
+	MsgID          string `json:"msgId,omitempty"`
+	StructuredData string `json:"structuredData,omitempty"`
+	Message        string `json:"message"`
+}
+
+// StatsdMetric is the JSON form of a parsed statsd metric
+type StatsdMetric struct {
+	Name       string            `json:"name"`
+	Value      float64           `json:"value"`
+	Type       string            `json:"type"`
+	SampleRate float64           `json:"sampleRate,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+}
+
+// parseSyslog parses a syslog message in either the RFC 5424 or the traditional RFC 3164 format
+func parseSyslog(data []byte) (SyslogMessage, error) {
+	text := strings.TrimRight(string(data), "\r\n\x00")
+	result := SyslogMessage{}
+
+	if !strings.HasPrefix(text, "<") {
+		return result, errors.New("syslog message must start with a priority, i.e. <34>")
+	}
+
+	end := strings.Index(text, ">")
+	if end < 2 || end > 4 {
+		return result, errors.New("invalid syslog priority")
+	}
+
+	priority, err := strconv.Atoi(text[1:end])
+	if err != nil || priority > 191 {
+		return result, fmt.Errorf("invalid syslog priority '%s'", text[1:end])
+	}
+
+	result.Facility = priority / 8
+	result.Severity = priority % 8
+	text = text[end+1:]
+
+	// RFC 5424 messages have a version immediately following the priority
+	if version, remainder, found := cutField(text); found && len(version) > 0 && version[0] >= '1' && version[0] <= '9' {
+		if result.Version, err = strconv.Atoi(version); err == nil {
+			return parseSyslog5424(result, remainder)
+		}
+	}
+
+	return parseSyslog3164(result, text), nil
+}
+
+func parseSyslog5424(result SyslogMessage, text string) (SyslogMessage, error) {
+	fields := make([]string, 5)
+	for index := range fields {
+		var found bool
+		fields[index], text, found = cutField(text)
+		if !found && index < len(fields)-1 {
+			return result, errors.New("incomplete RFC 5424 syslog header")
+		}
+	}
+
+	result.Timestamp = nilValue(fields[0])
+	result.Hostname = nilValue(fields[1])
+	result.AppName = nilValue(fields[2])
+	result.ProcID = nilValue(fields[3])
+	result.MsgID = nilValue(fields[4])
+
+	if strings.HasPrefix(text, syslogNilValue) {
+		text = strings.TrimPrefix(text[1:], " ")
+	} else if strings.HasPrefix(text, "[") {
+		end := structuredDataEnd(text)
+		result.StructuredData = text[:end]
+		text = strings.TrimPrefix(text[end:], " ")
+	}
+
+	// Remove the optional UTF-8 byte order mark
+	result.Message = strings.TrimPrefix(text, "\ufeff")
+	return result, nil
+}
+
+func parseSyslog3164(result SyslogMessage, text string) SyslogMessage {
+	// Timestamp is always 15 characters, i.e. "Oct 11 22:14:15"
+	if len(text) >= 16 && text[15] == ' ' && text[3] == ' ' && text[9] == ':' {
+		result.Timestamp = text[:15]
+		text = text[16:]
+
+		if hostname, remainder, found := cutField(text); found {
+			result.Hostname = hostname
+			text = remainder
+		}
+	}
+
+	// Tag is terminated by a colon, optionally preceded by the process id, i.e. "sshd[1234]: "
+	if colon := strings.Index(text, ":"); colon > 0 && !strings.ContainsAny(text[:colon], " ") {
+		tag := text[:colon]
+		if open := strings.Index(tag, "["); open > 0 && strings.HasSuffix(tag, "]") {
+			result.ProcID = tag[open+1 : len(tag)-1]
+			tag = tag[:open]
+		}
+		result.AppName = tag
+		text = strings.TrimPrefix(text[colon+1:], " ")
+	}
+
+	result.Message = text
+	return result
+}
+
+// structuredDataEnd returns the index following the last structured data element at the start of the text
+func structuredDataEnd(text string) int {
+	inElement := false
+	escaped := false
+	inQuotes := false
+
+	for index, char := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case char == '[':
+			inElement = true
+		case char == ']':
+			inElement = false
+		case char == ' ' && !inElement:
+			return index
+		}
+	}
+
+	return len(text)
+}
+
+func cutField(text string) (string, string, bool) {
+	index := strings.Index(text, " ")
+	if index < 0 {
+		return text, "", false
+	}
+	return text[:index], text[index+1:], true
+}
+
+func nilValue(value string) string {
+	if value == syslogNilValue {
+		return ""
+	}
+	return value
+}
+
+// parseStatsd parses one or more newline separated statsd metrics in the form name:value|type[|@rate][|#tag:value,...]
+func parseStatsd(data []byte) ([]StatsdMetric, error) {
+	var metrics []StatsdMetric
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		metric, err := parseStatsdMetric(line)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	if len(metrics) == 0 {
+		return nil, errors.New("no statsd metrics found")
+	}
+
+	return metrics, nil
+}
+
+func parseStatsdMetric(line string) (StatsdMetric, error) {
+	metric := StatsdMetric{}
+
+	colon := strings.LastIndex(strings.SplitN(line, "|", 2)[0], ":")
+	if colon <= 0 {
+		return metric, fmt.Errorf("invalid statsd metric '%s': missing name or value", line)
+	}
+
+	metric.Name = line[:colon]
+	sections := strings.Split(line[colon+1:], "|")
+	if len(sections) < 2 {
+		return metric, fmt.Errorf("invalid statsd metric '%s': missing type", line)
+	}
+
+	var err error
+	if metric.Value, err = strconv.ParseFloat(sections[0], 64); err != nil {
+		return metric, fmt.Errorf("invalid statsd metric '%s': value is not a number", line)
+	}
+
+	metric.Type = sections[1]
+	switch metric.Type {
+	case "c", "g", "ms", "h", "s", "d":
+	default:
+		return metric, fmt.Errorf("invalid statsd metric '%s': unknown type '%s'", line, metric.Type)
+	}
+
+	for _, section := range sections[2:] {
+		switch {
+		case strings.HasPrefix(section, "@"):
+			if metric.SampleRate, err = strconv.ParseFloat(section[1:], 64); err != nil {
+				return metric, fmt.Errorf("invalid statsd metric '%s': sample rate is not a number", line)
+			}
+
+		case strings.HasPrefix(section, "#"):
+			metric.Tags = make(map[string]string)
+			for _, tag := range strings.Split(section[1:], ",") {
+				parts := strings.SplitN(tag, ":", 2)
+				value := ""
+				if len(parts) == 2 {
+					value = parts[1]
+				}
+				metric.Tags[parts[0]] = value
+			}
+		}
+	}
+
+	return metric, nil
+}
@@ -0,0 +1,231 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package udp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FormatRaw    = "raw"
+	FormatSyslog = "syslog"
+	FormatStatsd = "statsd"
+
+	// defaultMaxDatagramSize is the largest UDP payload possible over IPv4
+	defaultMaxDatagramSize = 65507
+)
+
+// Trigger implements Trigger to support receiving UDP datagrams
+type Trigger struct {
+	dic     *di.Container
+	lc      logger.LoggingClient
+	runtime *runtime.GolangRuntime
+	format  string
+	conn    net.PacketConn
+}
+
+func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime) *Trigger {
+	return &Trigger{
+		dic:     dic,
+		runtime: runtime,
+		lc:      bootstrapContainer.LoggingClientFrom(dic.Get),
+	}
+}
+
+// Initialize initializes the Trigger to listen for UDP datagrams
+func (trigger *Trigger) Initialize(appWg *sync.WaitGroup, appCtx context.Context, background <-chan interfaces.BackgroundMessage) (bootstrap.Deferred, error) {
+	// Convenience short cuts
+	lc := trigger.lc
+	config := container.ConfigurationFrom(trigger.dic.Get)
+	udpConfig := config.Trigger.UDP
+
+	lc.Info("Initializing UDP Trigger")
+
+	if background != nil {
+		return nil, errors.New("background publishing not supported for services using UDP trigger")
+	}
+
+	trigger.format = strings.ToLower(strings.TrimSpace(udpConfig.Format))
+	switch trigger.format {
+	case "":
+		trigger.format = FormatRaw
+	case FormatRaw, FormatSyslog, FormatStatsd:
+	default:
+		return nil, fmt.Errorf("invalid Format '%s' for UDP trigger. Must be one of '%s', '%s' or '%s'",
+			udpConfig.Format,
+			FormatRaw,
+			FormatSyslog,
+			FormatStatsd)
+	}
+
+	maxDatagramSize := udpConfig.MaxDatagramSize
+	if maxDatagramSize <= 0 {
+		maxDatagramSize = defaultMaxDatagramSize
+	}
+
+	address := net.JoinHostPort(udpConfig.Host, strconv.Itoa(udpConfig.Port))
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s for UDP trigger: %s", address, err.Error())
+	}
+	trigger.conn = conn
+
+	lc.Infof("Listening for %s datagrams on %s for UDP trigger", trigger.format, conn.LocalAddr().String())
+
+	appWg.Add(1)
+	go func() {
+		defer appWg.Done()
+		trigger.receive(appCtx, maxDatagramSize)
+	}()
+
+	// Closing the connection unblocks the receive loop when the service is stopped
+	go func() {
+		<-appCtx.Done()
+		_ = conn.Close()
+	}()
+
+	deferred := func() {
+		lc.Info("Closing listener for UDP trigger")
+		_ = conn.Close()
+	}
+
+	return deferred, nil
+}
+
+// receive reads datagrams and dispatches them for processing until the service is stopped
+func (trigger *Trigger) receive(appCtx context.Context, maxDatagramSize int) {
+	lc := trigger.lc
+	buffer := make([]byte, maxDatagramSize)
+
+	for {
+		count, sender, err := trigger.conn.ReadFrom(buffer)
+		if err != nil {
+			select {
+			case <-appCtx.Done():
+				lc.Info("Exiting waiting for UDP datagrams")
+				return
+			default:
+			}
+
+			if errors.Is(err, net.ErrClosed) {
+				lc.Info("Exiting waiting for UDP datagrams")
+				return
+			}
+
+			lc.Errorf("Unable to receive datagram for UDP trigger: %s", err.Error())
+			continue
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		received := time.Now()
+
+		// Buffer is reused for the next datagram so must make a copy
+		data := make([]byte, count)
+		copy(data, buffer[:count])
+
+		envelope, err := trigger.createEnvelope(data, sender.String())
+		if err != nil {
+			lc.Errorf("Unable to parse datagram from %s for UDP trigger: %s", sender.String(), err.Error())
+			continue
+		}
+
+		trigger.runtime.Dispatch(envelope, func() {
+			trigger.processMessage(envelope, received)
+		})
+	}
+}
+
+// createEnvelope parses the datagram according to the configured format and returns the envelope to process.
+// The sender's address is used as the received topic.
+func (trigger *Trigger) createEnvelope(data []byte, sender string) (types.MessageEnvelope, error) {
+	envelope := types.MessageEnvelope{
+		CorrelationID: uuid.New().String(),
+		ReceivedTopic: sender,
+	}
+
+	var parsed interface{}
+	var err error
+
+	switch trigger.format {
+	case FormatSyslog:
+		parsed, err = parseSyslog(data)
+	case FormatStatsd:
+		parsed, err = parseStatsd(data)
+	default:
+		envelope.Payload = data
+		envelope.ContentType = common.ContentTypeJSON
+		if data[0] != byte('{') && data[0] != byte('[') {
+			// If not JSON then assume it is CBOR
+			envelope.ContentType = common.ContentTypeCBOR
+		}
+		return envelope, nil
+	}
+
+	if err != nil {
+		return envelope, err
+	}
+
+	envelope.Payload, err = json.Marshal(parsed)
+	if err != nil {
+		return envelope, err
+	}
+	envelope.ContentType = common.ContentTypeJSON
+
+	return envelope, nil
+}
+
+func (trigger *Trigger) processMessage(envelope types.MessageEnvelope, received time.Time) {
+	lc := trigger.lc
+
+	lc.Debugf("Received datagram from UDP Trigger with %d bytes from '%s'. Content-Type=%s",
+		len(envelope.Payload),
+		envelope.ReceivedTopic,
+		envelope.ContentType)
+	lc.Tracef("%s=%s", common.CorrelationHeader, envelope.CorrelationID)
+
+	appContext := appfunction.NewContext(envelope.CorrelationID, trigger.dic, envelope.ContentType)
+	appContext.SetReceived(received)
+
+	// ProcessMessage logs any error, so no need to log it here. There is no response to send for a datagram.
+	_ = trigger.runtime.ProcessMessage(appContext, envelope)
+}
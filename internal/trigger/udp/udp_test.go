@@ -0,0 +1,222 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package udp
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dic *di.Container
+
+func TestMain(m *testing.M) {
+	dic = di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+	})
+	m.Run()
+}
+
+func updateConfig(udpConfig sdkCommon.UDPConfig) {
+	config := &sdkCommon.ConfigurationStruct{
+		Trigger: sdkCommon.TriggerInfo{
+			Type: "udp",
+			UDP:  udpConfig,
+		},
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+}
+
+func TestInitializeErrors(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Config     sdkCommon.UDPConfig
+		Background chan interfaces.BackgroundMessage
+	}{
+		{"Background not supported", sdkCommon.UDPConfig{Host: "127.0.0.1"}, make(chan interfaces.BackgroundMessage)},
+		{"Bad Format", sdkCommon.UDPConfig{Host: "127.0.0.1", Format: "bogus"}, nil},
+		{"Bad Port", sdkCommon.UDPConfig{Host: "127.0.0.1", Port: -1}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			updateConfig(test.Config)
+
+			trigger := NewTrigger(dic, &runtime.GolangRuntime{})
+			_, err := trigger.Initialize(&sync.WaitGroup{}, context.Background(), test.Background)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestInitializeAndReceive(t *testing.T) {
+	updateConfig(sdkCommon.UDPConfig{Host: "127.0.0.1", Format: FormatStatsd})
+
+	received := make(chan []byte, 1)
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		received <- data.([]byte)
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{TargetType: &[]byte{}}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	trigger := NewTrigger(dic, goRuntime)
+	deferred, err := trigger.Initialize(wg, ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, deferred)
+
+	conn, err := net.Dial("udp", trigger.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	// Invalid datagrams are skipped
+	_, err = conn.Write([]byte("not statsd"))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("plc.temperature:72.5|g|#line:1"))
+	require.NoError(t, err)
+
+	select {
+	case data := <-received:
+		var metrics []StatsdMetric
+		require.NoError(t, json.Unmarshal(data, &metrics))
+		require.Len(t, metrics, 1)
+		assert.Equal(t, "plc.temperature", metrics[0].Name)
+		assert.Equal(t, 72.5, metrics[0].Value)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "datagram not received")
+	}
+
+	cancel()
+	wg.Wait()
+	deferred()
+}
+
+func TestCreateEnvelope(t *testing.T) {
+	trigger := Trigger{format: FormatRaw}
+
+	envelope, err := trigger.createEnvelope([]byte(`{"a":1}`), "10.0.0.1:5000")
+	require.NoError(t, err)
+	assert.Equal(t, common.ContentTypeJSON, envelope.ContentType)
+	assert.Equal(t, "10.0.0.1:5000", envelope.ReceivedTopic)
+	assert.NotEmpty(t, envelope.CorrelationID)
+
+	envelope, err = trigger.createEnvelope([]byte{0xa1, 0x01}, "10.0.0.1:5000")
+	require.NoError(t, err)
+	assert.Equal(t, common.ContentTypeCBOR, envelope.ContentType)
+
+	trigger.format = FormatSyslog
+	envelope, err = trigger.createEnvelope([]byte("<34>Oct 11 22:14:15 plc1 controller: fault"), "10.0.0.1:5000")
+	require.NoError(t, err)
+	assert.Equal(t, common.ContentTypeJSON, envelope.ContentType)
+	assert.JSONEq(t,
+		`{"facility":4,"severity":2,"timestamp":"Oct 11 22:14:15","hostname":"plc1","appName":"controller","message":"fault"}`,
+		string(envelope.Payload))
+
+	_, err = trigger.createEnvelope([]byte("no priority"), "10.0.0.1:5000")
+	assert.Error(t, err)
+}
+
+func TestParseSyslog(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Data          string
+		Expected      SyslogMessage
+		ErrorExpected bool
+	}{
+		{
+			"RFC 5424",
+			`<165>1 2003-10-11T22:14:15.003Z plc1.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application"] An application event`,
+			SyslogMessage{Facility: 20, Severity: 5, Version: 1, Timestamp: "2003-10-11T22:14:15.003Z", Hostname: "plc1.example.com",
+				AppName: "evntslog", ProcID: "1234", MsgID: "ID47", StructuredData: `[exampleSDID@32473 iut="3" eventSource="Application"]`,
+				Message: "An application event"},
+			false,
+		},
+		{
+			"RFC 5424 nil values",
+			"<34>1 - - - - - - \ufeffmessage\n",
+			SyslogMessage{Facility: 4, Severity: 2, Version: 1, Message: "message"},
+			false,
+		},
+		{
+			"RFC 3164",
+			"<13>Feb  5 17:32:18 10.0.0.99 sshd[1234]: Accepted login",
+			SyslogMessage{Facility: 1, Severity: 5, Timestamp: "Feb  5 17:32:18", Hostname: "10.0.0.99", AppName: "sshd", ProcID: "1234", Message: "Accepted login"},
+			false,
+		},
+		{"No header", "<13>just a message", SyslogMessage{Facility: 1, Severity: 5, Message: "just a message"}, false},
+		{"Incomplete RFC 5424", "<13>1 2003-10-11T22:14:15Z host", SyslogMessage{}, true},
+		{"No priority", "message", SyslogMessage{}, true},
+		{"Bad priority", "<999>message", SyslogMessage{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := parseSyslog([]byte(test.Data))
+			if test.ErrorExpected {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestParseStatsd(t *testing.T) {
+	actual, err := parseStatsd([]byte("plc.count:1|c|@0.5\nplc.latency:320|ms|#line:1,site\n"))
+	require.NoError(t, err)
+
+	expected := []StatsdMetric{
+		{Name: "plc.count", Value: 1, Type: "c", SampleRate: 0.5},
+		{Name: "plc.latency", Value: 320, Type: "ms", Tags: map[string]string{"line": "1", "site": ""}},
+	}
+	assert.Equal(t, expected, actual)
+
+	for _, invalid := range []string{"", "novalue|c", "name:abc|c", "name:1", "name:1|x", "name:1|c|@abc"} {
+		_, err := parseStatsd([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
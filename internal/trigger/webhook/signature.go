@@ -0,0 +1,209 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SchemeNone   = "none"
+	SchemeGitHub = "github"
+	SchemeStripe = "stripe"
+	SchemeHMAC   = "hmac"
+
+	gitHubSignatureHeader = "X-Hub-Signature-256"
+	gitHubSignaturePrefix = "sha256="
+	stripeSignatureHeader = "Stripe-Signature"
+)
+
+// signatureValidator validates the signature of the request, returning the key used to detect replays of the request.
+// The key is built from the verified MAC, rather than unsigned headers or the signature's text, so that replays can't
+// avoid detection by changing a header or the case of the hex encoded signature.
+type signatureValidator func(header http.Header, body []byte, secret []byte) (string, error)
+
+// validateGitHub validates the X-Hub-Signature-256 header sent by GitHub. The MAC of the body is used to detect
+// replays since GitHub signatures have no timestamp and the X-GitHub-Delivery header isn't signed.
+func validateGitHub(header http.Header, body []byte, secret []byte) (string, error) {
+	signature := header.Get(gitHubSignatureHeader)
+	if !strings.HasPrefix(signature, gitHubSignaturePrefix) {
+		return "", fmt.Errorf("missing or invalid %s header", gitHubSignatureHeader)
+	}
+
+	mac, err := verifySignature(strings.TrimPrefix(signature, gitHubSignaturePrefix), body, secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(mac), nil
+}
+
+// validateStripe returns the validator for the Stripe-Signature header, i.e. t=1492774577,v1=5257a869...,
+// which signs the timestamp and body joined by a '.'. Any of the v1 signatures may match.
+func validateStripe(tolerance time.Duration, now func() time.Time) signatureValidator {
+	return func(header http.Header, body []byte, secret []byte) (string, error) {
+		var timestamp string
+		var signatures []string
+
+		for _, item := range strings.Split(header.Get(stripeSignatureHeader), ",") {
+			parts := strings.SplitN(strings.TrimSpace(item), "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			switch parts[0] {
+			case "t":
+				timestamp = parts[1]
+			case "v1":
+				signatures = append(signatures, parts[1])
+			}
+		}
+
+		if len(timestamp) == 0 || len(signatures) == 0 {
+			return "", fmt.Errorf("missing or invalid %s header", stripeSignatureHeader)
+		}
+
+		if err := verifyTimestamp(timestamp, tolerance, now()); err != nil {
+			return "", err
+		}
+
+		signed := append([]byte(timestamp+"."), body...)
+		for _, signature := range signatures {
+			if mac, err := verifySignature(signature, signed, secret); err == nil {
+				return timestamp + "." + hex.EncodeToString(mac), nil
+			}
+		}
+
+		return "", errors.New("signature does not match")
+	}
+}
+
+// validateHMAC returns the validator for a hex encoded HMAC-SHA256 signature sent in the configured header.
+// When a timestamp header is configured the signature is of the timestamp and body joined by a '.'.
+func validateHMAC(signatureHeader, signaturePrefix, timestampHeader string, tolerance time.Duration, now func() time.Time) signatureValidator {
+	return func(header http.Header, body []byte, secret []byte) (string, error) {
+		signature := header.Get(signatureHeader)
+		if len(signature) == 0 || !strings.HasPrefix(signature, signaturePrefix) {
+			return "", fmt.Errorf("missing or invalid %s header", signatureHeader)
+		}
+		signature = strings.TrimPrefix(signature, signaturePrefix)
+
+		signed := body
+		replayPrefix := ""
+
+		if len(timestampHeader) > 0 {
+			timestamp := header.Get(timestampHeader)
+			if err := verifyTimestamp(timestamp, tolerance, now()); err != nil {
+				return "", err
+			}
+
+			signed = append([]byte(timestamp+"."), body...)
+			replayPrefix = timestamp + "."
+		}
+
+		mac, err := verifySignature(signature, signed, secret)
+		if err != nil {
+			return "", err
+		}
+
+		return replayPrefix + hex.EncodeToString(mac), nil
+	}
+}
+
+// verifySignature verifies the hex encoded signature is the HMAC-SHA256 of the data, returning the MAC
+func verifySignature(signature string, data []byte, secret []byte) ([]byte, error) {
+	actual, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return nil, errors.New("signature is not hex encoded")
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(data)
+
+	expected := mac.Sum(nil)
+	if !hmac.Equal(expected, actual) {
+		return nil, errors.New("signature does not match")
+	}
+
+	return expected, nil
+}
+
+// verifyTimestamp verifies the Unix time is within the tolerance of now
+func verifyTimestamp(timestamp string, tolerance time.Duration, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp '%s'", timestamp)
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > tolerance || age < -tolerance {
+		return fmt.Errorf("timestamp '%s' is outside the tolerance of %s", timestamp, tolerance.String())
+	}
+
+	return nil
+}
+
+// replayCache records the keys of the requests received within the tolerance so that replays can be rejected
+type replayCache struct {
+	lock      sync.Mutex
+	tolerance time.Duration
+	seen      map[string]time.Time
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func newReplayCache(tolerance time.Duration, now func() time.Time) *replayCache {
+	return &replayCache{
+		tolerance: tolerance,
+		seen:      make(map[string]time.Time),
+		lastPrune: now(),
+		now:       now,
+	}
+}
+
+// add records the key, returning false if it has already been received within the tolerance
+func (cache *replayCache) add(key string) bool {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	now := cache.now()
+
+	if now.Sub(cache.lastPrune) > cache.tolerance {
+		for seenKey, received := range cache.seen {
+			if now.Sub(received) > cache.tolerance {
+				delete(cache.seen, seenKey)
+			}
+		}
+		cache.lastPrune = now
+	}
+
+	if received, found := cache.seen[key]; found && now.Sub(received) <= cache.tolerance {
+		return false
+	}
+
+	cache.seen[key] = now
+	return true
+}
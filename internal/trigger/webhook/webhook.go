@@ -0,0 +1,230 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/webserver"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTolerance   = 5 * time.Minute
+	defaultMaxBodySize = 1048576
+
+	secretKey = "secret"
+)
+
+// Trigger implements Trigger to support receiving webhooks from external services. Webhooks are acknowledged
+// once their signature has been validated, without waiting for the pipeline to complete.
+type Trigger struct {
+	dic         *di.Container
+	lc          logger.LoggingClient
+	runtime     *runtime.GolangRuntime
+	webserver   *webserver.WebServer
+	config      sdkCommon.WebhookConfig
+	route       string
+	maxBodySize int64
+	validate    signatureValidator
+	replays     *replayCache
+}
+
+func NewTrigger(dic *di.Container, runtime *runtime.GolangRuntime, webserver *webserver.WebServer) *Trigger {
+	return &Trigger{
+		dic:       dic,
+		runtime:   runtime,
+		webserver: webserver,
+		lc:        bootstrapContainer.LoggingClientFrom(dic.Get),
+	}
+}
+
+// Initialize initializes the Trigger to receive webhooks on the configured route
+func (trigger *Trigger) Initialize(_ *sync.WaitGroup, _ context.Context, background <-chan interfaces.BackgroundMessage) (bootstrap.Deferred, error) {
+	lc := trigger.lc
+	config := container.ConfigurationFrom(trigger.dic.Get)
+
+	lc.Info("Initializing Webhook Trigger")
+
+	if background != nil {
+		return nil, errors.New("background publishing not supported for services using Webhook trigger")
+	}
+
+	if err := trigger.configure(config.Trigger.Webhook); err != nil {
+		return nil, err
+	}
+
+	if err := trigger.webserver.AddRoute(trigger.route, trigger.requestHandler, http.MethodPost); err != nil {
+		return nil, fmt.Errorf("unable to add route '%s' for Webhook trigger: %s", trigger.route, err.Error())
+	}
+
+	lc.Infof("Receiving webhooks on '%s' with '%s' signature validation for Webhook trigger", trigger.route, trigger.config.Scheme)
+
+	return nil, nil
+}
+
+// configure validates the configuration and applies the defaults
+func (trigger *Trigger) configure(config sdkCommon.WebhookConfig) error {
+	tolerance := defaultTolerance
+	if len(config.Tolerance) > 0 {
+		var err error
+		tolerance, err = time.ParseDuration(config.Tolerance)
+		if err != nil || tolerance <= 0 {
+			return fmt.Errorf("invalid Tolerance '%s' for Webhook trigger. Must be a positive duration", config.Tolerance)
+		}
+	}
+
+	config.Scheme = strings.ToLower(strings.TrimSpace(config.Scheme))
+	switch config.Scheme {
+	case "", SchemeNone:
+		config.Scheme = SchemeNone
+		trigger.validate = nil
+
+	case SchemeGitHub:
+		trigger.validate = validateGitHub
+
+	case SchemeStripe:
+		trigger.validate = validateStripe(tolerance, time.Now)
+
+	case SchemeHMAC:
+		if len(config.SignatureHeader) == 0 {
+			return errors.New("missing SignatureHeader for Webhook trigger 'hmac' scheme")
+		}
+		trigger.validate = validateHMAC(config.SignatureHeader, config.SignaturePrefix, config.TimestampHeader, tolerance, time.Now)
+
+	default:
+		return fmt.Errorf("invalid Scheme '%s' for Webhook trigger. Must be one of '%s', '%s', '%s' or '%s'",
+			config.Scheme,
+			SchemeNone,
+			SchemeGitHub,
+			SchemeStripe,
+			SchemeHMAC)
+	}
+
+	if trigger.validate != nil && len(config.SecretPath) == 0 {
+		return fmt.Errorf("missing SecretPath for Webhook trigger '%s' scheme", config.Scheme)
+	}
+
+	trigger.route = config.Route
+	if len(trigger.route) == 0 {
+		trigger.route = internal.ApiWebhookRoute
+	}
+
+	trigger.maxBodySize = config.MaxBodySize
+	if trigger.maxBodySize <= 0 {
+		trigger.maxBodySize = defaultMaxBodySize
+	}
+
+	trigger.replays = newReplayCache(tolerance, time.Now)
+	trigger.config = config
+
+	return nil
+}
+
+func (trigger *Trigger) requestHandler(writer http.ResponseWriter, request *http.Request) {
+	lc := trigger.lc
+	received := time.Now()
+	defer func() { _ = request.Body.Close() }()
+
+	body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, trigger.maxBodySize))
+	if err != nil {
+		lc.Errorf("Unable to read webhook body for Webhook trigger: %s", err.Error())
+		http.Error(writer, fmt.Sprintf("unable to read body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if trigger.validate != nil {
+		// Secret is retrieved for each request so that rotated secrets are used. The secret provider caches the secrets.
+		appContext := appfunction.NewContext("", trigger.dic, "")
+		secrets, err := appContext.GetSecret(trigger.config.SecretPath, secretKey)
+		if err != nil || len(secrets[secretKey]) == 0 {
+			lc.Errorf("Unable to get webhook secret from secret path '%s' for Webhook trigger", trigger.config.SecretPath)
+			http.Error(writer, "unable to validate signature", http.StatusInternalServerError)
+			return
+		}
+
+		replayKey, err := trigger.validate(request.Header, body, []byte(secrets[secretKey]))
+		if err != nil {
+			lc.Warnf("Rejected webhook from %s for Webhook trigger: %s", request.RemoteAddr, err.Error())
+			http.Error(writer, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		if !trigger.replays.add(replayKey) {
+			lc.Warnf("Rejected replayed webhook from %s for Webhook trigger", request.RemoteAddr)
+			http.Error(writer, "webhook already received", http.StatusConflict)
+			return
+		}
+	}
+
+	correlationID := request.Header.Get(common.CorrelationHeader)
+	if len(correlationID) == 0 {
+		correlationID = uuid.New().String()
+	}
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: correlationID,
+		ContentType:   request.Header.Get(common.ContentType),
+		Payload:       body,
+		ReceivedTopic: request.URL.Path,
+	}
+
+	// Webhook is acknowledged before the pipeline runs so that slow pipelines do not cause the sender to retry
+	writer.WriteHeader(http.StatusAccepted)
+
+	trigger.runtime.Dispatch(envelope, func() {
+		trigger.processMessage(envelope, received)
+	})
+}
+
+func (trigger *Trigger) processMessage(envelope types.MessageEnvelope, received time.Time) {
+	lc := trigger.lc
+
+	lc.Debugf("Received webhook from Webhook Trigger with %d bytes on '%s'. Content-Type=%s",
+		len(envelope.Payload),
+		envelope.ReceivedTopic,
+		envelope.ContentType)
+	lc.Tracef("%s=%s", common.CorrelationHeader, envelope.CorrelationID)
+
+	appContext := appfunction.NewContext(envelope.CorrelationID, trigger.dic, envelope.ContentType)
+	appContext.SetReceived(received)
+
+	// ProcessMessage logs any error, so no need to log it here. Webhook has already been acknowledged.
+	_ = trigger.runtime.ProcessMessage(appContext, envelope)
+}
@@ -0,0 +1,283 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	sdkCommon "github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/webserver"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "webhook-secret"
+
+var dic *di.Container
+
+func TestMain(m *testing.M) {
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", "webhook", secretKey).Return(map[string]string{secretKey: testSecret}, nil)
+	mockSP.On("GetSecret", "missing", secretKey).Return(nil, errors.New("not found"))
+
+	dic = di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+	m.Run()
+}
+
+func updateConfig(webhookConfig sdkCommon.WebhookConfig) {
+	config := &sdkCommon.ConfigurationStruct{
+		Trigger: sdkCommon.TriggerInfo{
+			Type:    "webhook",
+			Webhook: webhookConfig,
+		},
+	}
+
+	dic.Update(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+}
+
+func sign(data string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	_, _ = mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidateGitHub(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+
+	header := http.Header{}
+	header.Set(gitHubSignatureHeader, "sha256="+sign(string(body)))
+	header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+
+	key, err := validateGitHub(header, body, []byte(testSecret))
+	require.NoError(t, err)
+	assert.Equal(t, sign(string(body)), key)
+
+	header.Set(gitHubSignatureHeader, "sha256="+strings.ToUpper(sign(string(body))))
+	header.Set("X-GitHub-Delivery", "other")
+	key, err = validateGitHub(header, body, []byte(testSecret))
+	require.NoError(t, err)
+	assert.Equal(t, sign(string(body)), key, "key must not depend on the delivery id or the signature's case")
+
+	_, err = validateGitHub(header, []byte(`{"action":"closed"}`), []byte(testSecret))
+	assert.Error(t, err, "body changed")
+
+	header.Set(gitHubSignatureHeader, sign(string(body)))
+	_, err = validateGitHub(header, body, []byte(testSecret))
+	assert.Error(t, err, "missing prefix")
+}
+
+func TestValidateStripe(t *testing.T) {
+	now := time.Unix(1492774577, 0)
+	validate := validateStripe(5*time.Minute, func() time.Time { return now })
+	body := `{"id":"evt_1"}`
+
+	tests := []struct {
+		Name          string
+		Signature     string
+		ErrorExpected bool
+	}{
+		{"Valid", "t=1492774577,v1=" + sign("1492774577."+body), false},
+		{"Valid with multiple signatures", "t=1492774577,v1=" + sign("other") + ",v1=" + sign("1492774577."+body) + ",v0=abc", false},
+		{"Within tolerance", "t=1492774400,v1=" + sign("1492774400."+body), false},
+		{"Too old", "t=1492774000,v1=" + sign("1492774000."+body), true},
+		{"Wrong signature", "t=1492774577,v1=" + sign(body), true},
+		{"Missing timestamp", "v1=" + sign("1492774577."+body), true},
+		{"Missing header", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(stripeSignatureHeader, test.Signature)
+
+			_, err := validate(header, []byte(body), []byte(testSecret))
+			if test.ErrorExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	header := http.Header{}
+	header.Set(stripeSignatureHeader, "t=1492774577,v1="+strings.ToUpper(sign("1492774577."+body)))
+	key, err := validate(header, []byte(body), []byte(testSecret))
+	require.NoError(t, err)
+	assert.Equal(t, "1492774577."+sign("1492774577."+body), key, "key must not depend on the signature's case")
+}
+
+func TestValidateHMAC(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	body := `{"value":1}`
+
+	validate := validateHMAC("X-Signature", "sha256=", "", time.Minute, func() time.Time { return now })
+	header := http.Header{}
+	header.Set("X-Signature", "sha256="+sign(body))
+	key, err := validate(header, []byte(body), []byte(testSecret))
+	require.NoError(t, err)
+	assert.Equal(t, sign(body), key)
+
+	header.Set("X-Signature", "sha256="+strings.ToUpper(sign(body)))
+	key, err = validate(header, []byte(body), []byte(testSecret))
+	require.NoError(t, err)
+	assert.Equal(t, sign(body), key, "key must not depend on the signature's case")
+
+	_, err = validate(http.Header{}, []byte(body), []byte(testSecret))
+	assert.Error(t, err, "missing header")
+
+	validate = validateHMAC("X-Signature", "", "X-Timestamp", time.Minute, func() time.Time { return now })
+	header = http.Header{}
+	header.Set("X-Timestamp", "1600000000")
+	header.Set("X-Signature", sign("1600000000."+body))
+	key, err = validate(header, []byte(body), []byte(testSecret))
+	require.NoError(t, err)
+	assert.Equal(t, "1600000000."+sign("1600000000."+body), key)
+
+	header.Set("X-Timestamp", "1599999000")
+	header.Set("X-Signature", sign("1599999000."+body))
+	_, err = validate(header, []byte(body), []byte(testSecret))
+	assert.Error(t, err, "timestamp outside tolerance")
+}
+
+func TestReplayCache(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	cache := newReplayCache(time.Minute, func() time.Time { return now })
+
+	assert.True(t, cache.add("a"))
+	assert.False(t, cache.add("a"))
+	assert.True(t, cache.add("b"))
+
+	now = now.Add(2 * time.Minute)
+	assert.True(t, cache.add("a"), "expired keys can be added again")
+	assert.Len(t, cache.seen, 1, "expired keys are pruned")
+}
+
+func TestInitializeErrors(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Config     sdkCommon.WebhookConfig
+		Background chan interfaces.BackgroundMessage
+	}{
+		{"Background not supported", sdkCommon.WebhookConfig{}, make(chan interfaces.BackgroundMessage)},
+		{"Bad Scheme", sdkCommon.WebhookConfig{Scheme: "bogus"}, nil},
+		{"Bad Tolerance", sdkCommon.WebhookConfig{Tolerance: "bogus"}, nil},
+		{"Missing SecretPath", sdkCommon.WebhookConfig{Scheme: SchemeGitHub}, nil},
+		{"Missing SignatureHeader", sdkCommon.WebhookConfig{Scheme: SchemeHMAC, SecretPath: "webhook"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			updateConfig(test.Config)
+
+			trigger := NewTrigger(dic, &runtime.GolangRuntime{}, nil)
+			_, err := trigger.Initialize(&sync.WaitGroup{}, context.Background(), test.Background)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestRequestHandler(t *testing.T) {
+	updateConfig(sdkCommon.WebhookConfig{Scheme: SchemeGitHub, SecretPath: "webhook", MaxBodySize: 64})
+
+	received := make(chan []byte, 1)
+	transform := func(appContext interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		received <- data.([]byte)
+		return false, nil
+	}
+
+	goRuntime := &runtime.GolangRuntime{TargetType: &[]byte{}}
+	goRuntime.Initialize(dic)
+	goRuntime.SetTransforms([]interfaces.AppFunction{transform})
+
+	router := mux.NewRouter()
+	trigger := NewTrigger(dic, goRuntime, webserver.NewWebServer(dic, router))
+	_, err := trigger.Initialize(&sync.WaitGroup{}, context.Background(), nil)
+	require.NoError(t, err)
+
+	send := func(body string, signature string, delivery string) int {
+		request := httptest.NewRequest(http.MethodPost, internal.ApiWebhookRoute, bytes.NewReader([]byte(body)))
+		request.Header.Set(gitHubSignatureHeader, signature)
+		request.Header.Set("X-GitHub-Delivery", delivery)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	body := `{"action":"opened"}`
+	assert.Equal(t, http.StatusAccepted, send(body, "sha256="+sign(body), "1"))
+
+	select {
+	case data := <-received:
+		assert.Equal(t, body, string(data))
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "webhook not processed")
+	}
+
+	assert.Equal(t, http.StatusConflict, send(body, "sha256="+sign(body), "1"), "replay")
+	assert.Equal(t, http.StatusConflict, send(body, "sha256="+strings.ToUpper(sign(body)), "5"), "replay with new delivery id and signature case")
+	assert.Equal(t, http.StatusUnauthorized, send(body, "sha256="+sign("other"), "2"), "bad signature")
+
+	large := `{"data":"` + strings.Repeat("x", 64) + `"}`
+	assert.Equal(t, http.StatusBadRequest, send(large, "sha256="+sign(large), "3"), "too large")
+
+	trigger.config.SecretPath = "missing"
+	assert.Equal(t, http.StatusInternalServerError, send(body, "sha256="+sign(body), "4"), "secret missing")
+
+	select {
+	case <-received:
+		require.Fail(t, "rejected webhooks should not be processed")
+	default:
+	}
+
+	// Route only accepts POST
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, internal.ApiWebhookRoute, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
@@ -17,8 +17,10 @@
 package webserver
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
+	"net/http/pprof"
 	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
@@ -41,6 +43,9 @@ type WebServer struct {
 	lc         logger.LoggingClient
 	router     *mux.Router
 	controller *rest.Controller
+	// longLivedRoutes are the routes whose responses are held open for streaming and so aren't subject to the
+	// request timeout
+	longLivedRoutes []*mux.Route
 }
 
 // swagger:model
@@ -82,11 +87,63 @@ func (webserver *WebServer) ConfigureStandardRoutes() {
 	router.HandleFunc(common.ApiMetricsRoute, controller.Metrics).Methods(http.MethodGet)
 	router.HandleFunc(common.ApiConfigRoute, controller.Config).Methods(http.MethodGet)
 	router.HandleFunc(internal.ApiAddSecretRoute, controller.AddSecret).Methods(http.MethodPost)
+	router.HandleFunc(internal.ApiSLARoute, controller.SLA).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiDeviceStatsRoute, controller.DeviceStats).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiPipelineSnapshotRoute, controller.ExportPipelineSnapshot).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiPipelineSnapshotRoute, controller.ImportPipelineSnapshot).Methods(http.MethodPost)
+	router.HandleFunc(internal.ApiPipelineFunctionsRoute, controller.ConfigurableFunctions).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiPipelineStatsRoute, controller.PipelineStats).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiPipelineFlushRoute, controller.FlushPipelineData).Methods(http.MethodPost)
+	router.HandleFunc(internal.ApiPipelineFlushByNameRoute, controller.FlushNamedPipelineData).Methods(http.MethodPost)
+	router.HandleFunc(internal.ApiPipelineTestRoute, controller.TestPipeline).Methods(http.MethodPost)
+	router.HandleFunc(internal.ApiPipelinesRoute, controller.Pipelines).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiPipelineByIdRoute, controller.PipelineById).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiPipelineByIdRoute, controller.UpdatePipeline).Methods(http.MethodPut)
+	router.HandleFunc(internal.ApiPipelineFunctionByNameRoute, controller.UpdatePipelineFunction).Methods(http.MethodPut)
+	router.HandleFunc(internal.ApiPipelineFunctionByNameRoute, controller.DeletePipelineFunction).Methods(http.MethodDelete)
+	router.HandleFunc(internal.ApiPipelinePreviewRoute, controller.PipelinePreview).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiStoreReplayRoute, controller.ReplayStoredData).Methods(http.MethodPost)
+
+	if webserver.config.Profiling.Enabled {
+		webserver.configureProfilingRoutes()
+	}
+
+	if webserver.config.Stream.Enabled {
+		route := router.HandleFunc(internal.ApiStreamRoute, controller.Stream).Methods(http.MethodGet)
+		webserver.longLivedRoutes = append(webserver.longLivedRoutes, route)
+	}
+
+	if webserver.config.ServerSentEvents.Enabled {
+		route := router.HandleFunc(internal.ApiPipelineEventsRoute, controller.PipelineEvents).Methods(http.MethodGet)
+		webserver.longLivedRoutes = append(webserver.longLivedRoutes, route)
+	}
 
 	/// Trigger is not considered a standard route. Trigger route (when configured) is setup by the HTTP Trigger
 	//  in internal/trigger/http/rest.go
 }
 
+// configureProfilingRoutes adds the Go runtime's pprof endpoints and the endpoint profiling the functions pipeline,
+// all of which require the profiling token
+func (webserver *WebServer) configureProfilingRoutes() {
+	router := webserver.router
+	controller := webserver.controller
+
+	if len(webserver.config.Profiling.SecretPath) == 0 {
+		webserver.lc.Error("Profiling enabled without a SecretPath for the profiling token, profiling routes not registered")
+		return
+	}
+
+	webserver.lc.Warn("Profiling enabled. Profiling routes registered, which should only be used for diagnostics")
+
+	router.HandleFunc(internal.ApiProfilePipelineRoute, controller.ProfilingAuth(controller.ProfilePipeline)).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiDebugPprofRoute+"cmdline", controller.ProfilingAuth(pprof.Cmdline)).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiDebugPprofRoute+"profile", controller.ProfilingAuth(pprof.Profile)).Methods(http.MethodGet)
+	router.HandleFunc(internal.ApiDebugPprofRoute+"symbol", controller.ProfilingAuth(pprof.Symbol)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc(internal.ApiDebugPprofRoute+"trace", controller.ProfilingAuth(pprof.Trace)).Methods(http.MethodGet)
+	// The index also serves the named profiles, i.e. heap and goroutine
+	router.PathPrefix(internal.ApiDebugPprofRoute).HandlerFunc(controller.ProfilingAuth(pprof.Index)).Methods(http.MethodGet)
+}
+
 // SetupTriggerRoute adds a route to handle trigger pipeline from REST request
 func (webserver *WebServer) SetupTriggerRoute(path string, handlerForTrigger func(http.ResponseWriter, *http.Request)) {
 	webserver.router.HandleFunc(path, handlerForTrigger)
@@ -119,32 +176,44 @@ func (webserver *WebServer) listenAndServe(serviceTimeout time.Duration, errChan
 
 	if config.HttpServer.Protocol == "https" {
 		provider := bootstrapContainer.SecretProviderFrom(webserver.dic.Get)
-		httpsSecretData, err := provider.GetSecret(config.HttpServer.SecretName)
-		if err != nil {
-			lc.Errorf("unable to find HTTPS Secret %s in Secret Store: %w", config.HttpServer.SecretName, err)
+		loader := newCertificateLoader(lc, provider, config.HttpServer)
+		if err := loader.load(); err != nil {
+			lc.Error(err.Error())
 			errChannel <- err
 			return
 		}
 
-		httpsCert, ok := httpsSecretData[config.HttpServer.HTTPSCertName]
-		if !ok {
-			lc.Errorf("unable to find HTTPS Cert in Secret Data as %s. Check configuration", config.HttpServer.HTTPSCertName, err)
-			errChannel <- err
-			return
-		}
-
-		httpsKey, ok := httpsSecretData[config.HttpServer.HTTPSKeyName]
-		if !ok {
-			lc.Errorf("unable to find HTTPS Key in Secret Data as %s. Check configuration.", config.HttpServer.HTTPSKeyName, err)
-			errChannel <- err
-			return
+		server := &http.Server{
+			Addr:      addr,
+			Handler:   webserver.handler(serviceTimeout),
+			TLSConfig: &tls.Config{GetCertificate: loader.getCertificate},
 		}
 
 		lc.Infof("Starting HTTPS Web Server on address %s", addr)
 
-		errChannel <- http.ListenAndServeTLS(addr, httpsCert, httpsKey, http.TimeoutHandler(webserver.router, serviceTimeout, "Request timed out"))
+		// Cert and Key files are empty since the certificate is provided by the TLSConfig
+		errChannel <- server.ListenAndServeTLS("", "")
 	} else {
 		lc.Infof("Starting HTTP Web Server on address %s", addr)
-		errChannel <- http.ListenAndServe(addr, http.TimeoutHandler(webserver.router, serviceTimeout, "Request timed out"))
+		errChannel <- http.ListenAndServe(addr, webserver.handler(serviceTimeout))
 	}
 }
+
+// handler returns the router wrapped so that requests time out after the serviceTimeout, except for the long lived
+// WebSocket connections and Server-Sent Events streams, which the timeout would cut off and which can be neither
+// upgraded nor flushed through it
+func (webserver *WebServer) handler(serviceTimeout time.Duration) http.Handler {
+	timeoutHandler := http.TimeoutHandler(webserver.router, serviceTimeout, "Request timed out")
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var match mux.RouteMatch
+		for _, route := range webserver.longLivedRoutes {
+			if route.Match(request, &match) {
+				webserver.router.ServeHTTP(writer, request)
+				return
+			}
+		}
+
+		timeoutHandler.ServeHTTP(writer, request)
+	})
+}
@@ -17,22 +17,28 @@
 package webserver
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/stream"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 )
 
 var dic *di.Container
@@ -89,3 +95,126 @@ func TestSetupTriggerRoute(t *testing.T) {
 	assert.Equal(t, "test", body)
 	assert.False(t, handlerFunctionNotCalled, "expected handler function to be called")
 }
+
+func TestConfigureStandardRoutes_Profiling(t *testing.T) {
+	config := &common.ConfigurationStruct{}
+	mockProvider := &mocks.SecretProvider{}
+	mockProvider.On("GetSecret", "profiling", "token").Return(nil, errors.New("not found"))
+	profilingDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockProvider
+		},
+	})
+
+	routeStatus := func(webserver *WebServer, route string) int {
+		req, _ := http.NewRequest(http.MethodGet, route, nil)
+		rr := httptest.NewRecorder()
+		webserver.router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// Not registered when disabled
+	webserver := NewWebServer(profilingDic, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	assert.Equal(t, http.StatusNotFound, routeStatus(webserver, internal.ApiProfilePipelineRoute))
+	assert.Equal(t, http.StatusNotFound, routeStatus(webserver, internal.ApiDebugPprofRoute+"heap"))
+
+	// Not registered without a SecretPath for the token
+	config.Profiling.Enabled = true
+	webserver = NewWebServer(profilingDic, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	assert.Equal(t, http.StatusNotFound, routeStatus(webserver, internal.ApiProfilePipelineRoute))
+
+	// Registered and requiring the token
+	config.Profiling.SecretPath = "profiling"
+	webserver = NewWebServer(profilingDic, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	assert.Equal(t, http.StatusForbidden, routeStatus(webserver, internal.ApiProfilePipelineRoute))
+	assert.Equal(t, http.StatusForbidden, routeStatus(webserver, internal.ApiDebugPprofRoute+"heap"))
+}
+
+func TestConfigureStandardRoutes_Stream(t *testing.T) {
+	config := &common.ConfigurationStruct{}
+	hub := stream.NewHub(1, 1)
+	streamDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+		container.StreamHubName: func(get di.Get) interface{} {
+			return hub
+		},
+	})
+
+	// Not registered when disabled
+	webserver := NewWebServer(streamDic, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	req, _ := http.NewRequest(http.MethodGet, internal.ApiStreamRoute, nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Registered and not subject to the request timeout, which would prevent the upgrade
+	config.Stream.Enabled = true
+	webserver = NewWebServer(streamDic, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+
+	server := httptest.NewServer(webserver.handler(time.Second))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+internal.ApiStreamRoute, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool { return hub.Clients() == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestConfigureStandardRoutes_ServerSentEvents(t *testing.T) {
+	config := &common.ConfigurationStruct{}
+	hubs := stream.NewPipelineHubs(1, 1)
+	eventsDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+		container.PipelineHubsName: func(get di.Get) interface{} {
+			return hubs
+		},
+	})
+
+	eventsRoute := strings.Replace(internal.ApiPipelineEventsRoute, "{id}", interfaces.DefaultPipelineId, 1)
+
+	// Not registered when disabled
+	webserver := NewWebServer(eventsDic, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	req, _ := http.NewRequest(http.MethodGet, eventsRoute, nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Registered and not subject to the request timeout, which would buffer the events
+	config.ServerSentEvents.Enabled = true
+	webserver = NewWebServer(eventsDic, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+
+	server := httptest.NewServer(webserver.handler(time.Second))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + eventsRoute)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool { return hubs.Hub(interfaces.DefaultPipelineId).Clients() == 1 }, 5*time.Second, 10*time.Millisecond)
+}
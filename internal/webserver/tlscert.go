@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	bootstrapInterfaces "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+// certificateLoader provides the HTTPS certificate from the Secret Provider and reloads it when the secrets
+// have been updated, so that rotated certificates are picked up without restarting the service.
+type certificateLoader struct {
+	mutex       sync.Mutex
+	lc          logger.LoggingClient
+	provider    bootstrapInterfaces.SecretProvider
+	config      common.HttpConfig
+	certificate *tls.Certificate
+	lastUpdated time.Time
+}
+
+func newCertificateLoader(lc logger.LoggingClient, provider bootstrapInterfaces.SecretProvider, config common.HttpConfig) *certificateLoader {
+	return &certificateLoader{
+		lc:       lc,
+		provider: provider,
+		config:   config,
+	}
+}
+
+// load retrieves the HTTPS cert and key from the Secret Provider and parses them into the certificate to serve
+func (loader *certificateLoader) load() error {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	return loader.reload(loader.provider.SecretsLastUpdated())
+}
+
+func (loader *certificateLoader) reload(lastUpdated time.Time) error {
+	secretData, err := loader.provider.GetSecret(loader.config.SecretName)
+	if err != nil {
+		return fmt.Errorf("unable to find HTTPS Secret %s in Secret Store: %w", loader.config.SecretName, err)
+	}
+
+	httpsCert, ok := secretData[loader.config.HTTPSCertName]
+	if !ok {
+		return fmt.Errorf("unable to find HTTPS Cert in Secret Data as %s. Check configuration", loader.config.HTTPSCertName)
+	}
+
+	httpsKey, ok := secretData[loader.config.HTTPSKeyName]
+	if !ok {
+		return fmt.Errorf("unable to find HTTPS Key in Secret Data as %s. Check configuration", loader.config.HTTPSKeyName)
+	}
+
+	certificate, err := tls.X509KeyPair([]byte(httpsCert), []byte(httpsKey))
+	if err != nil {
+		return fmt.Errorf("unable to parse HTTPS Cert and Key from Secret %s: %w", loader.config.SecretName, err)
+	}
+
+	loader.certificate = &certificate
+	loader.lastUpdated = lastUpdated
+	return nil
+}
+
+// getCertificate is used as the tls.Config GetCertificate callback. The certificate is reloaded when the secrets
+// have been updated since it was last loaded. The previous certificate continues to be served if the reload fails.
+func (loader *certificateLoader) getCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	lastUpdated := loader.provider.SecretsLastUpdated()
+	if loader.certificate == nil || lastUpdated.After(loader.lastUpdated) {
+		if err := loader.reload(lastUpdated); err != nil {
+			if loader.certificate == nil {
+				return nil, err
+			}
+
+			// Avoid retrying on every handshake until the secrets are updated again
+			loader.lastUpdated = lastUpdated
+			loader.lc.Errorf("unable to reload HTTPS certificate, continuing with previous certificate: %s", err.Error())
+		} else {
+			loader.lc.Infof("HTTPS certificate reloaded from Secret %s", loader.config.SecretName)
+		}
+	}
+
+	return loader.certificate, nil
+}
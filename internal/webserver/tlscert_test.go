@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/common"
+)
+
+var httpsConfig = common.HttpConfig{
+	Protocol:      "https",
+	SecretName:    "https",
+	HTTPSCertName: "cert",
+	HTTPSKeyName:  "key",
+}
+
+func generateCertificate(t *testing.T, commonName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func commonNameOf(t *testing.T, certificate *tls.Certificate) string {
+	require.NotNil(t, certificate)
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+	return parsed.Subject.CommonName
+}
+
+func TestCertificateLoaderLoad(t *testing.T) {
+	cert, key := generateCertificate(t, "first")
+
+	tests := []struct {
+		Name        string
+		SecretData  map[string]string
+		SecretError error
+		ExpectError bool
+	}{
+		{"Valid", map[string]string{"cert": cert, "key": key}, nil, false},
+		{"Secret not found", nil, errors.New("not found"), true},
+		{"Missing cert", map[string]string{"key": key}, nil, true},
+		{"Missing key", map[string]string{"cert": cert}, nil, true},
+		{"Invalid PEM", map[string]string{"cert": "bogus", "key": key}, nil, true},
+		{"Mismatched key", map[string]string{"cert": cert, "key": "bogus"}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			provider := &mocks.SecretProvider{}
+			provider.On("SecretsLastUpdated").Return(time.Now())
+			provider.On("GetSecret", "https").Return(test.SecretData, test.SecretError)
+
+			loader := newCertificateLoader(logger.NewMockClient(), provider, httpsConfig)
+			err := loader.load()
+
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			certificate, err := loader.getCertificate(nil)
+			require.NoError(t, err)
+			assert.Equal(t, "first", commonNameOf(t, certificate))
+		})
+	}
+}
+
+func TestCertificateLoaderReloadsOnRotation(t *testing.T) {
+	firstCert, firstKey := generateCertificate(t, "first")
+	secondCert, secondKey := generateCertificate(t, "second")
+
+	loadedAt := time.Now()
+	provider := &mocks.SecretProvider{}
+	provider.On("SecretsLastUpdated").Return(loadedAt).Once()
+	provider.On("GetSecret", "https").Return(map[string]string{"cert": firstCert, "key": firstKey}, nil).Once()
+
+	loader := newCertificateLoader(logger.NewMockClient(), provider, httpsConfig)
+	require.NoError(t, loader.load())
+
+	// Secrets not updated, so the loaded certificate is used without going back to the Secret Provider
+	provider.On("SecretsLastUpdated").Return(loadedAt).Once()
+	certificate, err := loader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonNameOf(t, certificate))
+
+	// Invalid rotated secret keeps the previous certificate
+	provider.On("SecretsLastUpdated").Return(loadedAt.Add(time.Second)).Once()
+	provider.On("GetSecret", "https").Return(map[string]string{"cert": "bogus", "key": firstKey}, nil).Once()
+	certificate, err = loader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonNameOf(t, certificate))
+
+	// Valid rotated secret is picked up
+	provider.On("SecretsLastUpdated").Return(loadedAt.Add(2 * time.Second)).Once()
+	provider.On("GetSecret", "https").Return(map[string]string{"cert": secondCert, "key": secondKey}, nil).Once()
+	certificate, err = loader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", commonNameOf(t, certificate))
+
+	provider.AssertExpectations(t)
+	provider.AssertNumberOfCalls(t, "GetSecret", 3)
+}
+
+func TestCertificateLoaderNoCertificate(t *testing.T) {
+	provider := &mocks.SecretProvider{}
+	provider.On("SecretsLastUpdated").Return(time.Now())
+	provider.On("GetSecret", mock.Anything).Return(nil, errors.New("not found"))
+
+	loader := newCertificateLoader(logger.NewMockClient(), provider, httpsConfig)
+	certificate, err := loader.getCertificate(nil)
+	require.Error(t, err)
+	assert.Nil(t, certificate)
+}
@@ -23,4 +23,6 @@ import (
 type BackgroundMessage interface {
 	Message() types.MessageEnvelope
 	Topic() string
+	// QoS returns the QoS the message is published with and whether it was set, otherwise the client's QoS is used
+	QoS() (byte, bool)
 }
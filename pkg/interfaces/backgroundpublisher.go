@@ -22,4 +22,27 @@ package interfaces
 type BackgroundPublisher interface {
 	// Publish provided message through the configured MessageBus output
 	Publish(payload []byte, context AppFunctionContext) error
+	// PublishWithOptions publishes the provided message through the configured MessageBus output, overriding the
+	// publisher's topic, content type or QoS with those set in the options
+	PublishWithOptions(payload []byte, context AppFunctionContext, options BackgroundPublishOptions) error
+}
+
+// BackgroundPublisherOptions contains the settings of a BackgroundPublisher added by AddNamedBackgroundPublisher
+type BackgroundPublisherOptions struct {
+	// Capacity is the number of messages the publisher queues before Publish blocks
+	Capacity int
+	// Topic the messages are published to, which may contain placeholders for the context values, i.e.
+	// 'alerts/{devicename}'. Defaults to the MessageBus PublishHost.PublishTopic.
+	Topic string
+	// ContentType of the messages. Defaults to the input content type of the context passed to Publish.
+	ContentType string
+	// QoS of the messages for the MQTT MessageBus. Defaults to the MessageBus client's QoS when nil.
+	QoS *byte
+}
+
+// BackgroundPublishOptions overrides the publisher's settings for a single message. Empty values use the publisher's.
+type BackgroundPublishOptions struct {
+	Topic       string
+	ContentType string
+	QoS         *byte
 }
@@ -0,0 +1,34 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+import "time"
+
+// Cache is a bounded key-value cache shared by all executions of the functions pipeline, i.e. for caching
+// enrichment data looked up by pipeline functions. Entries expire after their time to live and the least recently
+// used entries are evicted when the cache is full. It is safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for the key, if present and not expired
+	Get(key string) ([]byte, bool)
+	// Set stores the value for the key, replacing any existing value. The entry expires after ttl, or after the
+	// configured Cache.DefaultTTL when ttl is zero. A negative ttl stores an entry that doesn't expire.
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes the entry for the key, if present
+	Delete(key string)
+	// Len returns the number of entries in the cache, which may include expired entries not yet purged
+	Len() int
+}
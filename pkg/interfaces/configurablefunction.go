@@ -0,0 +1,58 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+// Types of the configurable function parameters, which are all specified as strings in the pipeline configuration
+const (
+	ParameterTypeString = "string"
+	ParameterTypeBool   = "bool"
+	ParameterTypeInt    = "int"
+	// ParameterTypeDuration is a Go duration string, i.e. "5s"
+	ParameterTypeDuration = "duration"
+	// ParameterTypeList is a comma separated list of strings
+	ParameterTypeList = "list"
+)
+
+// ConfigurableFunction describes a built in function which can be used in the configurable functions pipeline, so
+// that tools such as pipeline editors can render configuration forms for it
+type ConfigurableFunction struct {
+	// Name is the function name as used in the pipeline's ExecutionOrder
+	Name string `json:"name"`
+	// Description is a summary of what the function does
+	Description string `json:"description,omitempty"`
+	// Parameters describes the parameters the function is configured with
+	Parameters []ConfigurableFunctionParameter `json:"parameters"`
+}
+
+// ConfigurableFunctionParameter describes a parameter of a ConfigurableFunction
+type ConfigurableFunctionParameter struct {
+	// Name is the parameter name, which is case insensitive in the pipeline configuration
+	Name string `json:"name"`
+	// Type is one of the ParameterType values
+	Type string `json:"type"`
+	// Required indicates the parameter must be specified. Parameters which are only required in combination with the
+	// values of other parameters are not required.
+	Required bool `json:"required"`
+	// Default is the value used when the optional parameter isn't specified, if any
+	Default string `json:"default,omitempty"`
+	// Values are the allowed values, if restricted
+	Values []string `json:"values,omitempty"`
+	// Secret indicates the value is a secret, so is redacted in pipeline snapshots
+	Secret bool `json:"secret,omitempty"`
+	// Description is a summary of what the parameter is used for
+	Description string `json:"description,omitempty"`
+}
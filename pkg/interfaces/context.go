@@ -16,6 +16,7 @@
 package interfaces
 
 import (
+	"context"
 	"time"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces"
@@ -29,6 +30,29 @@ const PROFILENAME = "profilename"
 const SOURCENAME = "sourcename"
 const RECEIVEDTOPIC = "receivedtopic"
 
+// SIGNATURE is the context value key set to the signature of the payload received, i.e. from the HTTP trigger's
+// X-Signature request header, which is verified by the VerifySignature transform
+const SIGNATURE = "signature"
+
+// ENQUEUED is the context value key set to when the message was enqueued, in nanoseconds since the epoch. This is
+// when the trigger received the message, otherwise when the functions pipeline started processing it.
+const ENQUEUED = "enqueued"
+
+// PIPELINESTART is the context value key set to when the functions pipeline started processing the message, in
+// nanoseconds since the epoch. For retries this is when the retry started.
+const PIPELINESTART = "pipelinestart"
+
+// QUEUETIME is the context value key set to the milliseconds from when the message was enqueued until the functions
+// pipeline started processing it, which for retries includes the time spent in Store and Forward
+const QUEUETIME = "queuetime"
+
+// PROCESSINGTIME is the context value key set by export functions, once the export completes, to the milliseconds
+// from when the functions pipeline started processing the message
+const PROCESSINGTIME = "processingtime"
+
+// DefaultPipelineId is the ID of the functions pipeline
+const DefaultPipelineId = "default-pipeline"
+
 // AppFunction is a type alias for a application pipeline function.
 // appCtx is a reference to the AppFunctionContext below.
 // data is the data to be operated on by the function.
@@ -42,11 +66,24 @@ type AppFunction = func(appCxt AppFunctionContext, data interface{}) (bool, inte
 type AppFunctionContext interface {
 	// CorrelationID returns the correlation ID associated with the context.
 	CorrelationID() string
+	// PipelineId returns the ID of the pipeline that is executing
+	PipelineId() string
+	// Context returns the context.Context of the message being processed, which is done when Pipeline.Timeout
+	// has elapsed or, for the HTTP trigger, the request is cancelled. Functions making requests, i.e. exports,
+	// should pass it on so a stuck request can't hold the message forever.
+	Context() context.Context
 	// InputContentType returns the content type of the data that initiated the pipeline execution. Only useful when
 	// the TargetType for the pipeline is []byte, otherwise the data with be the type specified by TargetType.
 	InputContentType() string
-	// SetResponseData sets the response data that will be returned to the trigger when pipeline execution is complete.
+	// SetResponseData sets the response data that will be returned to the trigger when pipeline execution is complete,
+	// replacing any response data previously set or added.
 	SetResponseData(data []byte)
+	// AddResponseData adds a part to the response data that will be returned to the trigger when pipeline execution
+	// is complete. contentType is the content type of the part, or empty to use the response content type. Unlike
+	// SetResponseData, it is safe to call from functions executing concurrently, i.e. fanned out branches.
+	// When the response has multiple parts, they are returned as a JSON array if all the parts are JSON, otherwise
+	// as a multipart/mixed document, and the response content type is set to match.
+	AddResponseData(data []byte, contentType string)
 	// ResponseData returns the data that will be returned to the trigger when pipeline execution is complete.
 	ResponseData() []byte
 	// SetResponseContentType sets the content type that will be returned to the trigger when pipeline
@@ -55,6 +92,17 @@ type AppFunctionContext interface {
 	// ResponseContentType returns the content type that will be returned to the trigger when pipeline
 	// execution is complete.
 	ResponseContentType() string
+	// SetResponseTopic overrides the topic, i.e. MessageBus topic, MQTT topic, AMQP routing key or Redis channel,
+	// the response data is published to by the trigger, which may contain placeholders for the context values,
+	// i.e. 'results/{devicename}'.
+	SetResponseTopic(topic string)
+	// ResponseTopic returns the topic set by SetResponseTopic, or the pipeline's configured ResponseTopic when not set.
+	// The trigger's configured publish topic is used when empty.
+	ResponseTopic() string
+	// SetFiltered marks the data as filtered out, for the reason specified, by the function stopping the pipeline.
+	// Filtered data is counted as filtered rather than failed or exported, even by the last function of the pipeline,
+	// any error the function returns is only logged at debug level and the data is never stored for retry.
+	SetFiltered(reason string)
 	// SetRetryData set the data that is to be retried later as part of the Store and Forward capability.
 	// Used when there was failure sending the data to an external source.
 	SetRetryData(data []byte)
@@ -67,6 +115,9 @@ type AppFunctionContext interface {
 	SecretsLastUpdated() time.Time
 	// LoggingClient returns the Logger client
 	LoggingClient() logger.LoggingClient
+	// Logger returns a Logger client that adds the correlation ID, pipeline ID and name of the executing pipeline
+	// function to every log message, so messages logged by pipeline functions can be traced.
+	Logger() logger.LoggingClient
 	// EventClient returns the Event client. Note if Core Data is not specified in the Clients configuration,
 	// this will return nil.
 	EventClient() interfaces.EventClient
@@ -94,7 +145,9 @@ type AppFunctionContext interface {
 	// Resources retrieved are cached so multiple calls for same profileName and resourceName don't result in multiple
 	// unneeded HTTP calls to Core Metadata
 	GetDeviceResource(profileName string, resourceName string) (dtos.DeviceResource, error)
-	// AddValue stores a value for access within other functions in pipeline
+	// AddValue stores a value for access within other functions in pipeline, i.e. to pass metadata such as extracted
+	// ids or computed topic names to later functions. Keys aren't case sensitive. The values are kept with the data
+	// saved for Store and Forward and are restored when it is retried.
 	AddValue(key string, value string)
 	// RemoveValue deletes a value stored in the context at the given key
 	RemoveValue(key string)
@@ -107,4 +160,17 @@ type AppFunctionContext interface {
 	// the key in context storage.  An error will be returned if any placeholders
 	// are not matched to a value in the context.
 	ApplyValues(format string) (string, error)
+	// Cache returns the key-value cache shared by all executions of the functions pipeline, so functions can keep
+	// data, i.e. enrichment data looked up from other services, between executions. See the Cache configuration.
+	Cache() Cache
+	// State returns the key-value state persisted to the Database, so stateful functions, i.e. ones removing
+	// duplicates or keeping counters, keep their state across restarts of the service. Returns nil unless
+	// State.Enabled is set.
+	State() State
+	// Stream returns the stream pushing data to the WebSocket clients connected to the /stream endpoint, or nil when
+	// the endpoint isn't enabled. See the Stream configuration.
+	Stream() Stream
+	// Location returns the time zone configured for the service by Timezone, which time based functions use in place
+	// of UTC, i.e. when naming files by date. UTC is returned when no Timezone is configured.
+	Location() *time.Location
 }
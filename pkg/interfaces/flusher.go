@@ -0,0 +1,24 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+// Flusher is implemented by functions which accumulate data before passing it on in the functions pipeline, such as
+// the Batch transform, so the data can be passed on before its threshold is reached, i.e. when the service stops.
+type Flusher interface {
+	// Flush passes on the accumulated data, returning whether there was any data to pass on.
+	Flush() bool
+}
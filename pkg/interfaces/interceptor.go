@@ -0,0 +1,46 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+import "time"
+
+// FunctionExecution describes the execution of a function in the functions pipeline
+type FunctionExecution struct {
+	// FunctionName is the name of the pipeline function being executed
+	FunctionName string
+	// Position is the index of the function in the functions pipeline
+	Position int
+	// Data is the data passed to the function
+	Data interface{}
+	// Completed is false when the interceptor is called before the function executes and true after it has executed
+	Completed bool
+	// Duration is how long the function took to execute. Only set when Completed is true.
+	Duration time.Duration
+	// ContinuePipeline and Result are the values returned by the function. Only set when Completed is true.
+	ContinuePipeline bool
+	Result           interface{}
+	// Filtered is set when the function stopped the pipeline having marked the data as filtered out with
+	// SetFiltered, for the FilteredReason. Only set when Completed is true.
+	Filtered       bool
+	FilteredReason string
+}
+
+// PipelineInterceptor is called before and after every function in the functions pipeline executes, allowing
+// auditing, metrics or fault injection without modifying the functions. Returning an error from the call made before
+// the function executes prevents the function from executing. Returning an error from either call stops the
+// pipeline with that error.
+type PipelineInterceptor func(appContext AppFunctionContext, execution FunctionExecution) error
@@ -3,11 +3,15 @@
 package mocks
 
 import (
+	context "context"
+
 	clientsinterfaces "github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces"
 	common "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
 
 	dtos "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 
+	interfaces "github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
 	logger "github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
 
 	mock "github.com/stretchr/testify/mock"
@@ -25,6 +29,11 @@ func (_m *AppFunctionContext) AddValue(key string, value string) {
 	_m.Called(key, value)
 }
 
+// AddResponseData provides a mock function with given fields: data, contentType
+func (_m *AppFunctionContext) AddResponseData(data []byte, contentType string) {
+	_m.Called(data, contentType)
+}
+
 // ApplyValues provides a mock function with given fields: format
 func (_m *AppFunctionContext) ApplyValues(format string) (string, error) {
 	ret := _m.Called(format)
@@ -46,6 +55,22 @@ func (_m *AppFunctionContext) ApplyValues(format string) (string, error) {
 	return r0, r1
 }
 
+// Cache provides a mock function with given fields:
+func (_m *AppFunctionContext) Cache() interfaces.Cache {
+	ret := _m.Called()
+
+	var r0 interfaces.Cache
+	if rf, ok := ret.Get(0).(func() interfaces.Cache); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.Cache)
+		}
+	}
+
+	return r0
+}
+
 // CommandClient provides a mock function with given fields:
 func (_m *AppFunctionContext) CommandClient() clientsinterfaces.CommandClient {
 	ret := _m.Called()
@@ -62,6 +87,22 @@ func (_m *AppFunctionContext) CommandClient() clientsinterfaces.CommandClient {
 	return r0
 }
 
+// Context provides a mock function with given fields:
+func (_m *AppFunctionContext) Context() context.Context {
+	ret := _m.Called()
+
+	var r0 context.Context
+	if rf, ok := ret.Get(0).(func() context.Context); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(context.Context)
+		}
+	}
+
+	return r0
+}
+
 // CorrelationID provides a mock function with given fields:
 func (_m *AppFunctionContext) CorrelationID() string {
 	ret := _m.Called()
@@ -242,6 +283,22 @@ func (_m *AppFunctionContext) InputContentType() string {
 	return r0
 }
 
+// Location provides a mock function with given fields:
+func (_m *AppFunctionContext) Location() *time.Location {
+	ret := _m.Called()
+
+	var r0 *time.Location
+	if rf, ok := ret.Get(0).(func() *time.Location); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*time.Location)
+		}
+	}
+
+	return r0
+}
+
 // LoggingClient provides a mock function with given fields:
 func (_m *AppFunctionContext) LoggingClient() logger.LoggingClient {
 	ret := _m.Called()
@@ -258,6 +315,22 @@ func (_m *AppFunctionContext) LoggingClient() logger.LoggingClient {
 	return r0
 }
 
+// Logger provides a mock function with given fields:
+func (_m *AppFunctionContext) Logger() logger.LoggingClient {
+	ret := _m.Called()
+
+	var r0 logger.LoggingClient
+	if rf, ok := ret.Get(0).(func() logger.LoggingClient); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(logger.LoggingClient)
+		}
+	}
+
+	return r0
+}
+
 // NotificationClient provides a mock function with given fields:
 func (_m *AppFunctionContext) NotificationClient() clientsinterfaces.NotificationClient {
 	ret := _m.Called()
@@ -274,6 +347,20 @@ func (_m *AppFunctionContext) NotificationClient() clientsinterfaces.Notificatio
 	return r0
 }
 
+// PipelineId provides a mock function with given fields:
+func (_m *AppFunctionContext) PipelineId() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // PushToCore provides a mock function with given fields: event
 func (_m *AppFunctionContext) PushToCore(event dtos.Event) (common.BaseWithIdResponse, error) {
 	ret := _m.Called(event)
@@ -330,6 +417,20 @@ func (_m *AppFunctionContext) ResponseData() []byte {
 	return r0
 }
 
+// ResponseTopic provides a mock function with given fields:
+func (_m *AppFunctionContext) ResponseTopic() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // SecretsLastUpdated provides a mock function with given fields:
 func (_m *AppFunctionContext) SecretsLastUpdated() time.Time {
 	ret := _m.Called()
@@ -344,6 +445,11 @@ func (_m *AppFunctionContext) SecretsLastUpdated() time.Time {
 	return r0
 }
 
+// SetFiltered provides a mock function with given fields: reason
+func (_m *AppFunctionContext) SetFiltered(reason string) {
+	_m.Called(reason)
+}
+
 // SetResponseContentType provides a mock function with given fields: _a0
 func (_m *AppFunctionContext) SetResponseContentType(_a0 string) {
 	_m.Called(_a0)
@@ -354,11 +460,48 @@ func (_m *AppFunctionContext) SetResponseData(data []byte) {
 	_m.Called(data)
 }
 
+// SetResponseTopic provides a mock function with given fields: topic
+func (_m *AppFunctionContext) SetResponseTopic(topic string) {
+	_m.Called(topic)
+}
+
 // SetRetryData provides a mock function with given fields: data
 func (_m *AppFunctionContext) SetRetryData(data []byte) {
 	_m.Called(data)
 }
 
+// State provides a mock function with given fields:
+func (_m *AppFunctionContext) State() interfaces.State {
+	ret := _m.Called()
+
+	var r0 interfaces.State
+	if rf, ok := ret.Get(0).(func() interfaces.State); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.State)
+		}
+	}
+
+	return r0
+}
+
+// Stream provides a mock function with given fields:
+func (_m *AppFunctionContext) Stream() interfaces.Stream {
+	ret := _m.Called()
+
+	var r0 interfaces.Stream
+	if rf, ok := ret.Get(0).(func() interfaces.Stream); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.Stream)
+		}
+	}
+
+	return r0
+}
+
 // SubscriptionClient provides a mock function with given fields:
 func (_m *AppFunctionContext) SubscriptionClient() clientsinterfaces.SubscriptionClient {
 	ret := _m.Called()
@@ -71,6 +71,29 @@ func (_m *ApplicationService) AddBackgroundPublisherWithTopic(capacity int, topi
 	return r0, r1
 }
 
+// AddNamedBackgroundPublisher provides a mock function with given fields: name, options
+func (_m *ApplicationService) AddNamedBackgroundPublisher(name string, options interfaces.BackgroundPublisherOptions) (interfaces.BackgroundPublisher, error) {
+	ret := _m.Called(name, options)
+
+	var r0 interfaces.BackgroundPublisher
+	if rf, ok := ret.Get(0).(func(string, interfaces.BackgroundPublisherOptions) interfaces.BackgroundPublisher); ok {
+		r0 = rf(name, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.BackgroundPublisher)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, interfaces.BackgroundPublisherOptions) error); ok {
+		r1 = rf(name, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddBackgroundPublisher provides a mock function with given fields: correlationId, contentType
 func (_m *ApplicationService) BuildContext(correlationId string, contentType string) interfaces.AppFunctionContext {
 	ret := _m.Called(correlationId, contentType)
@@ -140,6 +163,22 @@ func (_m *ApplicationService) CommandClient() clientsinterfaces.CommandClient {
 	return r0
 }
 
+// ConfigurableFunctions provides a mock function with given fields:
+func (_m *ApplicationService) ConfigurableFunctions() []interfaces.ConfigurableFunction {
+	ret := _m.Called()
+
+	var r0 []interfaces.ConfigurableFunction
+	if rf, ok := ret.Get(0).(func() []interfaces.ConfigurableFunction); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]interfaces.ConfigurableFunction)
+		}
+	}
+
+	return r0
+}
+
 // DeviceClient provides a mock function with given fields:
 func (_m *ApplicationService) DeviceClient() clientsinterfaces.DeviceClient {
 	ret := _m.Called()
@@ -204,6 +243,55 @@ func (_m *ApplicationService) EventClient() clientsinterfaces.EventClient {
 	return r0
 }
 
+// ExportPipelineSnapshot provides a mock function with given fields:
+func (_m *ApplicationService) ExportPipelineSnapshot() interfaces.PipelineSnapshot {
+	ret := _m.Called()
+
+	var r0 interfaces.PipelineSnapshot
+	if rf, ok := ret.Get(0).(func() interfaces.PipelineSnapshot); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(interfaces.PipelineSnapshot)
+	}
+
+	return r0
+}
+
+// FlushNamedPipelineData provides a mock function with given fields: name
+func (_m *ApplicationService) FlushNamedPipelineData(name string) (bool, error) {
+	ret := _m.Called(name)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FlushPipelineData provides a mock function with given fields:
+func (_m *ApplicationService) FlushPipelineData() int {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
 // GetAppSetting provides a mock function with given fields: setting
 func (_m *ApplicationService) GetAppSetting(setting string) (string, error) {
 	ret := _m.Called(setting)
@@ -278,6 +366,20 @@ func (_m *ApplicationService) GetSecret(path string, keys ...string) (map[string
 	return r0, r1
 }
 
+// ImportPipelineSnapshot provides a mock function with given fields: snapshot
+func (_m *ApplicationService) ImportPipelineSnapshot(snapshot interfaces.PipelineSnapshot) error {
+	ret := _m.Called(snapshot)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interfaces.PipelineSnapshot) error); ok {
+		r0 = rf(snapshot)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ListenForCustomConfigChanges provides a mock function with given fields: configToWatch, sectionName, changedCallback
 func (_m *ApplicationService) ListenForCustomConfigChanges(configToWatch interface{}, sectionName string, changedCallback func(interface{})) error {
 	ret := _m.Called(configToWatch, sectionName, changedCallback)
@@ -364,6 +466,29 @@ func (_m *ApplicationService) MakeItStop() {
 	_m.Called()
 }
 
+// NamedBackgroundPublisher provides a mock function with given fields: name
+func (_m *ApplicationService) NamedBackgroundPublisher(name string) (interfaces.BackgroundPublisher, bool) {
+	ret := _m.Called(name)
+
+	var r0 interfaces.BackgroundPublisher
+	if rf, ok := ret.Get(0).(func(string) interfaces.BackgroundPublisher); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.BackgroundPublisher)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // NotificationClient provides a mock function with given fields:
 func (_m *ApplicationService) NotificationClient() clientsinterfaces.NotificationClient {
 	ret := _m.Called()
@@ -394,6 +519,62 @@ func (_m *ApplicationService) RegisterCustomTriggerFactory(name string, factory
 	return r0
 }
 
+// RegisterFlusher provides a mock function with given fields: flusher
+func (_m *ApplicationService) RegisterFlusher(flusher interfaces.Flusher) error {
+	ret := _m.Called(flusher)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interfaces.Flusher) error); ok {
+		r0 = rf(flusher)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RegisterNamedFlusher provides a mock function with given fields: name, flusher
+func (_m *ApplicationService) RegisterNamedFlusher(name string, flusher interfaces.Flusher) error {
+	ret := _m.Called(name, flusher)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, interfaces.Flusher) error); ok {
+		r0 = rf(name, flusher)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RegisterPipelineInterceptor provides a mock function with given fields: interceptor
+func (_m *ApplicationService) RegisterPipelineInterceptor(interceptor interfaces.PipelineInterceptor) error {
+	ret := _m.Called(interceptor)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interfaces.PipelineInterceptor) error); ok {
+		r0 = rf(interceptor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RegisterSecretUpdatedCallback provides a mock function with given fields: path, callback
+func (_m *ApplicationService) RegisterSecretUpdatedCallback(path string, callback func(string)) error {
+	ret := _m.Called(path, callback)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, func(string)) error); ok {
+		r0 = rf(path, callback)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RegistryClient provides a mock function with given fields:
 func (_m *ApplicationService) RegistryClient() registry.Client {
 	ret := _m.Called()
@@ -410,6 +591,27 @@ func (_m *ApplicationService) RegistryClient() registry.Client {
 	return r0
 }
 
+// ReplayStoredData provides a mock function with given fields: filter
+func (_m *ApplicationService) ReplayStoredData(filter interfaces.StoreReplayFilter) (interfaces.StoreReplayResult, error) {
+	ret := _m.Called(filter)
+
+	var r0 interfaces.StoreReplayResult
+	if rf, ok := ret.Get(0).(func(interfaces.StoreReplayFilter) interfaces.StoreReplayResult); ok {
+		r0 = rf(filter)
+	} else {
+		r0 = ret.Get(0).(interfaces.StoreReplayResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(interfaces.StoreReplayFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetFunctionsPipeline provides a mock function with given fields: transforms
 func (_m *ApplicationService) SetFunctionsPipeline(transforms ...func(interfaces.AppFunctionContext, interface{}) (bool, interface{})) error {
 	_va := make([]interface{}, len(transforms))
@@ -459,3 +661,24 @@ func (_m *ApplicationService) SubscriptionClient() clientsinterfaces.Subscriptio
 
 	return r0
 }
+
+// TestPipeline provides a mock function with given fields: test
+func (_m *ApplicationService) TestPipeline(test interfaces.PipelineTest) (interfaces.PipelineTestResult, error) {
+	ret := _m.Called(test)
+
+	var r0 interfaces.PipelineTestResult
+	if rf, ok := ret.Get(0).(func(interfaces.PipelineTest) interfaces.PipelineTestResult); ok {
+		r0 = rf(test)
+	} else {
+		r0 = ret.Get(0).(interfaces.PipelineTestResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(interfaces.PipelineTest) error); ok {
+		r1 = rf(test)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
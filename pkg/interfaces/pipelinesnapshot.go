@@ -0,0 +1,55 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+// PipelineSnapshotRedacted is exported in place of the values of function parameters which are secrets, i.e. an
+// encryption key. Importing a snapshot keeps the importing service's current value for redacted parameters.
+const PipelineSnapshotRedacted = "<redacted>"
+
+// PipelineSnapshot is a portable document of a service's effective configurable functions pipeline, used to back up
+// the pipeline or to roll it out to other instances. Secrets are never included: parameters referencing the Secret
+// Store, i.e. SecretPath and SecretName, are exported as is and secret values are redacted.
+type PipelineSnapshot struct {
+	// ServiceKey is the key of the service the snapshot was taken from
+	ServiceKey string `json:"serviceKey"`
+	// Created is the time the snapshot was taken in nanoseconds since the epoch
+	Created int64 `json:"created"`
+	// ExecutionOrder is the comma separated list of functions executed by the pipeline
+	ExecutionOrder string `json:"executionOrder"`
+	// UseTargetTypeOfByteArray indicates the pipeline receives the data as a []byte rather than an Event
+	UseTargetTypeOfByteArray bool `json:"useTargetTypeOfByteArray"`
+	// Functions contains the parameters of each of the pipeline functions
+	Functions map[string]PipelineSnapshotFunction `json:"functions"`
+	// Topics contains the topics the trigger subscribes and publishes to. Only set for the edgex-messagebus,
+	// external-mqtt and redis-pubsub triggers.
+	Topics PipelineSnapshotTopics `json:"topics"`
+}
+
+// PipelineSnapshotFunction contains the parameters of a function in a PipelineSnapshot
+type PipelineSnapshotFunction struct {
+	Parameters map[string]string `json:"parameters"`
+	// Disabled indicates the function is skipped, passing the data through to the next function
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// PipelineSnapshotTopics contains the trigger topics in a PipelineSnapshot
+type PipelineSnapshotTopics struct {
+	// SubscribeTopics is the comma separated list of topics, or Redis channel patterns, subscribed to
+	SubscribeTopics string `json:"subscribeTopics,omitempty"`
+	// PublishTopic is the topic, or Redis channel, the pipeline output is published to
+	PublishTopic string `json:"publishTopic,omitempty"`
+}
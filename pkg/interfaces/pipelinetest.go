@@ -0,0 +1,63 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+// PipelineTestEncodingText and PipelineTestEncodingBase64 are the encodings of the payloads of a PipelineTest
+const (
+	PipelineTestEncodingText   = "text"
+	PipelineTestEncodingBase64 = "base64"
+)
+
+// PipelineTest is a payload to run through a functions pipeline in a sandbox, so changes to the pipeline can be
+// validated before they are applied. The export functions aren't executed, so no data is sent.
+type PipelineTest struct {
+	// Pipeline is the pipeline to test, i.e. with the changes to be validated. The current pipeline is tested when
+	// not specified. Redacted secret parameter values keep their current value.
+	Pipeline *PipelineSnapshot `json:"pipeline,omitempty"`
+	// ContentType is the content type of the Payload, i.e. application/json
+	ContentType string `json:"contentType"`
+	// Encoding is 'text' when the Payload is as is, otherwise 'base64' when it is base64 encoded binary data
+	Encoding string `json:"encoding,omitempty"`
+	// Payload is the data received by the pipeline, i.e. an Event
+	Payload string `json:"payload"`
+}
+
+// PipelineTestResult contains the outcome of a PipelineTest
+type PipelineTestResult struct {
+	// Completed indicates the payload was processed by all the functions of the pipeline
+	Completed bool `json:"completed"`
+	// Steps contains the result of each function executed, in execution order
+	Steps []PipelineTestStep `json:"steps"`
+}
+
+// PipelineTestStep contains the result of a function executed by a PipelineTest
+type PipelineTestStep struct {
+	// Function is the function name as used in the pipeline's ExecutionOrder
+	Function string `json:"function"`
+	// Mocked indicates the function is an export which wasn't executed, so the data was passed through
+	Mocked bool `json:"mocked,omitempty"`
+	// Continued indicates the function passed its output to the next function rather than stopping the pipeline
+	Continued bool `json:"continued"`
+	// Error is the error the function stopped the pipeline with, if any
+	Error string `json:"error,omitempty"`
+	// Filtered is the reason the function filtered out the data, if it did
+	Filtered string `json:"filtered,omitempty"`
+	// Encoding is 'text' when the Output is as is, otherwise 'base64' when it is base64 encoded binary data
+	Encoding string `json:"encoding,omitempty"`
+	// Output is the data output by the function
+	Output string `json:"output,omitempty"`
+}
@@ -80,6 +80,49 @@ type ApplicationService interface {
 	MakeItStop()
 	// RegisterCustomTriggerFactory registers a trigger factory for a custom trigger to be used.
 	RegisterCustomTriggerFactory(name string, factory func(TriggerConfig) (Trigger, error)) error
+	// RegisterSecretUpdatedCallback registers a callback which is called with the path when the secret data at that
+	// path changes, i.e. from the /secret endpoint, InsecureSecrets configuration changes or the Secret Store. Allows
+	// functions which cache secrets or connections created with them to refresh without restarting the service.
+	// Callbacks must be registered before MakeItRun is called.
+	RegisterSecretUpdatedCallback(path string, callback func(path string)) error
+	// RegisterPipelineInterceptor registers an interceptor which is called before and after every function in the
+	// functions pipeline executes. Interceptors are called in the order registered and must be registered before
+	// MakeItRun is called.
+	RegisterPipelineInterceptor(interceptor PipelineInterceptor) error
+	// RegisterFlusher registers a function which accumulates data, such as the Batch transform, so its data is flushed
+	// when the service stops or FlushPipelineData is called. Functions in the configurable functions pipeline are
+	// registered automatically.
+	RegisterFlusher(flusher Flusher) error
+	// RegisterNamedFlusher registers a function which accumulates data, as RegisterFlusher does, which can also be
+	// flushed on its own by its name with FlushNamedPipelineData, i.e. when a custom REST route or command requests it.
+	// The name must not be used by another Flusher. Batches in the configurable functions pipeline are named with
+	// their FlushName parameter.
+	RegisterNamedFlusher(name string, flusher Flusher) error
+	// FlushPipelineData flushes the data accumulated by the registered Flushers, rather than waiting for their
+	// thresholds, and returns how many had data to flush.
+	FlushPipelineData() int
+	// FlushNamedPipelineData flushes the data accumulated by the Flusher with the name, rather than waiting for its
+	// thresholds, and returns whether it had data to flush. An error is returned if no Flusher has the name.
+	FlushNamedPipelineData(name string) (bool, error)
+	// ExportPipelineSnapshot returns a portable document of the effective configurable functions pipeline, its
+	// parameters and the trigger topics, with secret parameter values redacted.
+	ExportPipelineSnapshot() PipelineSnapshot
+	// ImportPipelineSnapshot validates the snapshot's functions pipeline and applies it to the running service, then
+	// persists it to the Configuration Provider, if used. Changes to the trigger topics take effect when the service
+	// is restarted. The service must be using the configurable functions pipeline.
+	ImportPipelineSnapshot(snapshot PipelineSnapshot) error
+	// ConfigurableFunctions returns the descriptions of the built in functions available to the configurable functions
+	// pipeline and their parameters, i.e. for rendering pipeline configuration forms.
+	ConfigurableFunctions() []ConfigurableFunction
+	// ReplayStoredData re-runs the Store and Forward items matching the filter through the full functions pipeline,
+	// rather than only from the position that failed, which is useful after correcting a mis-configured function.
+	// Replayed items are removed from the store and are stored again if they fail to export. Only available once
+	// MakeItRun has been called and Store and Forward is enabled.
+	ReplayStoredData(filter StoreReplayFilter) (StoreReplayResult, error)
+	// TestPipeline runs the test's payload through the configurable functions pipeline in a sandbox and returns the
+	// output of each function, so changes to the pipeline can be validated before they are applied. The export
+	// functions aren't executed and the payload isn't counted in the pipeline's statistics.
+	TestPipeline(test PipelineTest) (PipelineTestResult, error)
 	// AddBackgroundPublisher Adds and returns a BackgroundPublisher which is used to publish
 	// asynchronously to the Edgex MessageBus.
 	// Not valid for use with the HTTP or External MQTT triggers
@@ -88,6 +131,13 @@ type ApplicationService interface {
 	// asynchronously to the Edgex MessageBus on the specified topic.
 	// Not valid for use with the HTTP or External MQTT triggers
 	AddBackgroundPublisherWithTopic(capacity int, topic string) (BackgroundPublisher, error)
+	// AddNamedBackgroundPublisher Adds and returns a BackgroundPublisher with the specified name and options, so
+	// services publishing different kinds of asynchronous messages each have their own queue, topic, content type
+	// and QoS. An error is returned if a publisher with the name has already been added.
+	// Not valid for use with the HTTP or External MQTT triggers
+	AddNamedBackgroundPublisher(name string, options BackgroundPublisherOptions) (BackgroundPublisher, error)
+	// NamedBackgroundPublisher returns the BackgroundPublisher added with the specified name, if any
+	NamedBackgroundPublisher(name string) (BackgroundPublisher, bool)
 	// GetSecret returns the secret data from the secret store (secure or insecure) for the specified path.
 	// An error is returned if the path is not found or any of the keys (if specified) are not found.
 	// Omit keys if all secret data for the specified path is required.
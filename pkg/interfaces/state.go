@@ -0,0 +1,32 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+// State is a key-value store persisted to the Database, so functions can keep state, i.e. the last value seen or
+// the IDs already processed, across restarts of the service. It is shared by all executions of the functions
+// pipeline and is safe for concurrent use.
+type State interface {
+	// Get returns the value stored for the key, if present
+	Get(key string) ([]byte, bool)
+	// Set stores the value for the key, replacing any existing value. The value has been persisted when no error is
+	// returned.
+	Set(key string, value []byte) error
+	// Delete removes the value stored for the key, if present
+	Delete(key string) error
+	// Keys returns the keys which have a value stored, in sorted order
+	Keys() []string
+}
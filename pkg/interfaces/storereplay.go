@@ -0,0 +1,42 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+// StoreReplayFilter selects the Store and Forward items to be replayed. Empty fields match all items.
+type StoreReplayFilter struct {
+	// Start matches items stored at or after this time, in nanoseconds since the epoch
+	Start int64 `json:"start,omitempty"`
+	// End matches items stored at or before this time, in nanoseconds since the epoch
+	End int64 `json:"end,omitempty"`
+	// CorrelationID matches items with this correlation ID
+	CorrelationID string `json:"correlationId,omitempty"`
+	// PipelinePosition matches items which failed at this position in the functions pipeline
+	PipelinePosition *int `json:"pipelinePosition,omitempty"`
+}
+
+// StoreReplayResult contains the outcome of replaying Store and Forward items
+type StoreReplayResult struct {
+	// Matched is the number of stored items which matched the filter
+	Matched int `json:"matched"`
+	// Replayed is the number of matched items which were replayed through the functions pipeline without error
+	Replayed int `json:"replayed"`
+	// Failed is the number of matched items which resulted in an error when replayed. Items which fail to export
+	// again are stored for later retry as usual.
+	Failed int `json:"failed"`
+	// Skipped is the number of matched items which can't be replayed as they were stored without their pipeline input
+	Skipped int `json:"skipped"`
+}
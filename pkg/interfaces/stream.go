@@ -0,0 +1,27 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+// Stream pushes data to the WebSocket clients connected to the service's /stream endpoint, i.e. local dashboards
+// live-streaming the pipeline's output. It is safe for concurrent use.
+type Stream interface {
+	// Publish sends the data to every connected client and returns the number of clients it was queued for. Data
+	// isn't queued for clients whose buffer is full, so slow clients don't hold up the functions pipeline.
+	Publish(data []byte) int
+	// Clients returns the number of connected clients
+	Clients() int
+}
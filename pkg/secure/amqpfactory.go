@@ -0,0 +1,125 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package secure
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/messaging"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+// AMQPFactory creates AMQP connections authenticated with the secrets for the configured AuthMode
+type AMQPFactory struct {
+	appContext     interfaces.AppFunctionContext
+	logger         logger.LoggingClient
+	authMode       string
+	secretPath     string
+	config         *amqp.Config
+	skipCertVerify bool
+}
+
+func NewAMQPFactory(appContext interfaces.AppFunctionContext, mode string, path string, skipVerify bool) AMQPFactory {
+	return AMQPFactory{
+		appContext:     appContext,
+		logger:         appContext.LoggingClient(),
+		authMode:       mode,
+		secretPath:     path,
+		skipCertVerify: skipVerify,
+	}
+}
+
+// defaultAMQPHeartbeat is the heartbeat interval amqp.Dial uses, which DialConfig doesn't default to
+const defaultAMQPHeartbeat = 10 * time.Second
+
+// Create dials the broker at the URL using the specified config after applying the credentials and TLS settings for
+// the AuthMode. The heartbeat interval defaults to 10 seconds when not set.
+func (factory AMQPFactory) Create(url string, config amqp.Config) (*amqp.Connection, error) {
+	if factory.authMode == "" {
+		factory.authMode = messaging.AuthModeNone
+		factory.logger.Warn("AuthMode not set, defaulting to \"" + messaging.AuthModeNone + "\"")
+	}
+
+	factory.config = &config
+	if factory.config.Heartbeat == 0 {
+		factory.config.Heartbeat = defaultAMQPHeartbeat
+	}
+	factory.config.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: factory.skipCertVerify,
+	}
+
+	//get the secrets from the secret provider and populate the struct
+	secretData, err := messaging.GetSecretData(factory.authMode, factory.secretPath, factory.appContext)
+	if err != nil {
+		return nil, err
+	}
+	//ensure that the authmode selected has the required secret values
+	if secretData != nil {
+		err = messaging.ValidateSecretData(factory.authMode, factory.secretPath, secretData)
+		if err != nil {
+			return nil, err
+		}
+		// configure the connection with the retrieved secret values
+		err = factory.configureAMQPForAuth(secretData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return amqp.DialConfig(url, *factory.config)
+}
+
+func (factory AMQPFactory) configureAMQPForAuth(secretData *messaging.SecretData) error {
+	switch factory.authMode {
+	case messaging.AuthModeUsernamePassword:
+		factory.config.SASL = []amqp.Authentication{&amqp.PlainAuth{
+			Username: secretData.Username,
+			Password: secretData.Password,
+		}}
+	case messaging.AuthModeCert:
+		cert, err := tls.X509KeyPair(secretData.CertPemBlock, secretData.KeyPemBlock)
+		if err != nil {
+			return err
+		}
+		factory.config.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		factory.config.SASL = []amqp.Authentication{&amqp.ExternalAuth{}}
+	case messaging.AuthModeCA:
+		// Nothing to do here for this option
+	case messaging.AuthModeNone:
+		return nil
+	}
+
+	if len(secretData.CaPemBlock) > 0 {
+		caCertPool := x509.NewCertPool()
+		ok := caCertPool.AppendCertsFromPEM(secretData.CaPemBlock)
+		if !ok {
+			return errors.New("Error parsing CA PEM block")
+		}
+		factory.config.TLSClientConfig.RootCAs = caCertPool
+	}
+
+	return nil
+}
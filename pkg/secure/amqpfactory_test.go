@@ -0,0 +1,76 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package secure
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAMQPFactory(authMode string) AMQPFactory {
+	target := NewAMQPFactory(context, authMode, "", false)
+	target.config = &amqp.Config{TLSClientConfig: &tls.Config{}}
+	return target
+}
+
+func TestConfigureAMQPForAuthWithUsernamePassword(t *testing.T) {
+	target := newTestAMQPFactory(messaging.AuthModeUsernamePassword)
+	err := target.configureAMQPForAuth(&messaging.SecretData{
+		Username:   "Username",
+		Password:   "Password",
+		CaPemBlock: []byte(testCACert),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []amqp.Authentication{&amqp.PlainAuth{Username: "Username", Password: "Password"}}, target.config.SASL)
+	assert.Nil(t, target.config.TLSClientConfig.Certificates)
+	assert.NotNil(t, target.config.TLSClientConfig.RootCAs)
+}
+
+func TestConfigureAMQPForAuthWithClientCert(t *testing.T) {
+	target := newTestAMQPFactory(messaging.AuthModeCert)
+	err := target.configureAMQPForAuth(&messaging.SecretData{
+		CertPemBlock: []byte(testClientCert),
+		KeyPemBlock:  []byte(testClientKey),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []amqp.Authentication{&amqp.ExternalAuth{}}, target.config.SASL)
+	assert.Len(t, target.config.TLSClientConfig.Certificates, 1)
+	assert.Nil(t, target.config.TLSClientConfig.RootCAs)
+}
+
+func TestConfigureAMQPForAuthWithBadCA(t *testing.T) {
+	target := newTestAMQPFactory(messaging.AuthModeCA)
+	err := target.configureAMQPForAuth(&messaging.SecretData{
+		CaPemBlock: []byte("bogus"),
+	})
+	require.Error(t, err)
+}
+
+func TestAMQPFactoryCreateInvalidURL(t *testing.T) {
+	target := NewAMQPFactory(context, "", "", false)
+	_, err := target.Create("mqtt://localhost", amqp.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AMQP scheme")
+}
@@ -173,3 +173,11 @@ func TestConfigureMQTTClientForAuthWithNone(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestCreateDefaultsAuthMode(t *testing.T) {
+	target := NewMqttFactory(context, "", "", false)
+
+	client, err := target.Create(mqtt.NewClientOptions())
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+}
@@ -0,0 +1,244 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/secure"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+// AMQPSender publishes to an exchange on an AMQP 0-9-1 broker, i.e. RabbitMQ
+type AMQPSender struct {
+	lock                 sync.Mutex
+	config               AMQPSenderConfig
+	persistOnError       bool
+	publisher            amqpPublisher
+	secretsLastRetrieved time.Time
+	// dial connects to the broker. Replaceable for unit testing.
+	dial func(ctx interfaces.AppFunctionContext) (amqpPublisher, error)
+}
+
+// AMQPSenderConfig contains the broker configuration for the AMQPSender
+type AMQPSenderConfig struct {
+	// BrokerAddress should be set to the complete broker address i.e. amqps://rabbitmq:5671/myvhost
+	BrokerAddress string
+	// Exchange is the name of the exchange to publish to. Empty is the default exchange, which routes to the
+	// queue named by the routing key.
+	Exchange string
+	// RoutingKey to publish with. May contain placeholders, i.e. edgex.{devicename}, which are replaced with
+	// the values from the context.
+	RoutingKey string
+	// Persistent indicates whether messages are stored to disk by the broker so they survive a broker restart
+	Persistent bool
+	// ContentType, when set, is the content type property of the messages published
+	ContentType string
+	// ConnectTimeout is the duration for timing out on connecting to the broker
+	ConnectTimeout string
+	// The name of the path in secret provider to retrieve your secrets
+	SecretPath string
+	// SkipCertVerify
+	SkipCertVerify bool
+	// AuthMode indicates what to use when connecting to the broker. Options are "none", "cacert" , "usernamepassword", "clientcert".
+	// If a CA Cert exists in the SecretPath then it will be used for all modes except "none".
+	AuthMode string
+}
+
+// amqpPublisher publishes messages on a channel with publisher confirms enabled
+type amqpPublisher interface {
+	Publish(ctx context.Context, exchange string, routingKey string, message amqp.Publishing) error
+	Close() error
+}
+
+// amqpConnectionPublisher publishes on a channel of its own connection, which is closed along with it
+type amqpConnectionPublisher struct {
+	channel    *amqp.Channel
+	connection *amqp.Connection
+}
+
+// Publish publishes the message and waits for the broker to confirm it, or the context to be done
+func (publisher amqpConnectionPublisher) Publish(ctx context.Context, exchange string, routingKey string, message amqp.Publishing) error {
+	confirmation, err := publisher.channel.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, message)
+	if err != nil {
+		return err
+	}
+
+	acknowledged, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acknowledged {
+		return errors.New("message was not confirmed by the broker")
+	}
+
+	return nil
+}
+
+func (publisher amqpConnectionPublisher) Close() error {
+	return publisher.connection.Close()
+}
+
+// NewAMQPSender creates, initializes and returns a new instance of AMQPSender
+func NewAMQPSender(config AMQPSenderConfig, persistOnError bool) *AMQPSender {
+	//avoid casing issues
+	config.AuthMode = strings.ToLower(config.AuthMode)
+
+	sender := &AMQPSender{
+		config:         config,
+		persistOnError: persistOnError,
+	}
+	sender.dial = sender.connect
+
+	return sender
+}
+
+func (sender *AMQPSender) connect(ctx interfaces.AppFunctionContext) (amqpPublisher, error) {
+	config := amqp.Config{}
+
+	if len(sender.config.ConnectTimeout) > 0 {
+		timeout, err := time.ParseDuration(sender.config.ConnectTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to parse ConnectTimeout value of '%s': %w", sender.config.ConnectTimeout, err)
+		}
+		config.Dial = amqp.DefaultDial(timeout)
+	}
+
+	factory := secure.NewAMQPFactory(ctx, sender.config.AuthMode, sender.config.SecretPath, sender.config.SkipCertVerify)
+	connection, err := factory.Create(sender.config.BrokerAddress, config)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := connection.Channel()
+	if err == nil {
+		err = channel.Confirm(false)
+	}
+	if err != nil {
+		_ = connection.Close()
+		return nil, err
+	}
+
+	return amqpConnectionPublisher{channel: channel, connection: connection}, nil
+}
+
+// getPublisher returns the current publisher, connecting to the broker if not yet connected, the previous connection
+// failed or the secrets have been updated since connecting
+func (sender *AMQPSender) getPublisher(ctx interfaces.AppFunctionContext) (amqpPublisher, error) {
+	sender.lock.Lock()
+	defer sender.lock.Unlock()
+
+	if sender.publisher != nil && !sender.secretsLastRetrieved.Before(ctx.SecretsLastUpdated()) {
+		return sender.publisher, nil
+	}
+
+	if sender.publisher != nil {
+		_ = sender.publisher.Close()
+		sender.publisher = nil
+	}
+
+	ctx.LoggingClient().Info("Connecting to AMQP broker for export")
+	publisher, err := sender.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx.LoggingClient().Info("Connected to AMQP broker for export")
+
+	sender.publisher = publisher
+	sender.secretsLastRetrieved = time.Now()
+
+	return publisher, nil
+}
+
+// resetPublisher closes the publisher so the next export reconnects, unless another export has already replaced it
+func (sender *AMQPSender) resetPublisher(publisher amqpPublisher) {
+	sender.lock.Lock()
+	defer sender.lock.Unlock()
+
+	if sender.publisher == publisher {
+		_ = sender.publisher.Close()
+		sender.publisher = nil
+	}
+}
+
+// AMQPSend publishes data from the previous function to the configured exchange and routing key, waiting for the
+// broker to confirm the message has been received.
+// If no previous function exists, then the event that triggered the pipeline will be used.
+func (sender *AMQPSender) AMQPSend(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+
+	exportData, err := util.CoerceType(data)
+	if err != nil {
+		return false, err
+	}
+
+	routingKey, err := ctx.ApplyValues(sender.config.RoutingKey)
+	if err != nil {
+		return false, fmt.Errorf("AMQP routing key formatting failed: %s", err.Error())
+	}
+
+	publisher, err := sender.getPublisher(ctx)
+	if err != nil {
+		sender.setRetryData(ctx, exportData)
+		subMessage := "dropping event"
+		if sender.persistOnError {
+			subMessage = "persisting Event for later retry"
+		}
+		return false, fmt.Errorf("Could not connect to AMQP broker for export, %s. Error: %s", subMessage, err.Error())
+	}
+
+	message := amqp.Publishing{
+		ContentType:   sender.config.ContentType,
+		CorrelationId: ctx.CorrelationID(),
+		Body:          exportData,
+	}
+	if sender.config.Persistent {
+		message.DeliveryMode = amqp.Persistent
+	}
+
+	if err := publisher.Publish(ctx.Context(), sender.config.Exchange, routingKey, message); err != nil {
+		sender.resetPublisher(publisher)
+		sender.setRetryData(ctx, exportData)
+		return false, fmt.Errorf("Could not publish to AMQP broker for export: %s", err.Error())
+	}
+
+	ctx.LoggingClient().Debugf("Sent data to AMQP broker with routing key '%s'", routingKey)
+	ctx.LoggingClient().Trace("Data exported", "Transport", "AMQP", common.CorrelationHeader, ctx.CorrelationID())
+
+	return true, nil
+}
+
+func (sender *AMQPSender) setRetryData(ctx interfaces.AppFunctionContext, exportData []byte) {
+	if sender.persistOnError {
+		ctx.SetRetryData(exportData)
+	}
+}
@@ -0,0 +1,185 @@
+//go:build !no_amqp
+// +build !no_amqp
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+// fakeAMQPPublisher records the messages published
+type fakeAMQPPublisher struct {
+	exchange   string
+	routingKey string
+	message    amqp.Publishing
+	err        error
+	closed     bool
+}
+
+func (publisher *fakeAMQPPublisher) Publish(_ context.Context, exchange string, routingKey string, message amqp.Publishing) error {
+	publisher.exchange = exchange
+	publisher.routingKey = routingKey
+	publisher.message = message
+	return publisher.err
+}
+
+func (publisher *fakeAMQPPublisher) Close() error {
+	publisher.closed = true
+	return nil
+}
+
+func newTestAMQPSender(persistOnError bool, publisher *fakeAMQPPublisher, dialErr error) (*AMQPSender, *int) {
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("SecretsLastUpdated").Return(time.Time{})
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	sender := NewAMQPSender(AMQPSenderConfig{
+		BrokerAddress: "amqp://localhost:5672/",
+		Exchange:      "amq.topic",
+		RoutingKey:    "edgex.{devicename}",
+		Persistent:    true,
+		ContentType:   common.ContentTypeJSON,
+	}, persistOnError)
+
+	dials := 0
+	sender.dial = func(_ interfaces.AppFunctionContext) (amqpPublisher, error) {
+		dials++
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		return publisher, nil
+	}
+
+	return sender, &dials
+}
+
+func TestAMQPSender_AMQPSend(t *testing.T) {
+	publisher := &fakeAMQPPublisher{}
+	sender, dials := newTestAMQPSender(false, publisher, nil)
+
+	appContext := appfunction.NewContext("123-456", dic, "")
+	appContext.AddValue(interfaces.DEVICENAME, "thermostat")
+
+	continuePipeline, result := sender.AMQPSend(appContext, `{"temperature":38}`)
+	require.True(t, continuePipeline, result)
+	assert.Nil(t, result)
+	assert.Equal(t, "amq.topic", publisher.exchange)
+	assert.Equal(t, "edgex.thermostat", publisher.routingKey)
+	assert.Equal(t, amqp.Publishing{
+		ContentType:   common.ContentTypeJSON,
+		CorrelationId: "123-456",
+		DeliveryMode:  amqp.Persistent,
+		Body:          []byte(`{"temperature":38}`),
+	}, publisher.message)
+
+	// The connection is reused
+	continuePipeline, _ = sender.AMQPSend(appContext, []byte("data"))
+	require.True(t, continuePipeline)
+	assert.Equal(t, 1, *dials)
+}
+
+func TestAMQPSender_AMQPSendNoData(t *testing.T) {
+	sender, _ := newTestAMQPSender(false, &fakeAMQPPublisher{}, nil)
+	continuePipeline, result := sender.AMQPSend(ctx, nil)
+	require.False(t, continuePipeline)
+	require.Error(t, result.(error))
+}
+
+func TestAMQPSender_AMQPSendMissingPlaceholder(t *testing.T) {
+	sender, dials := newTestAMQPSender(false, &fakeAMQPPublisher{}, nil)
+	continuePipeline, result := sender.AMQPSend(appfunction.NewContext("123", dic, ""), []byte("data"))
+	require.False(t, continuePipeline)
+	require.Error(t, result.(error))
+	assert.Contains(t, result.(error).Error(), "routing key")
+	assert.Equal(t, 0, *dials)
+}
+
+func TestAMQPSender_AMQPSendConnectFailed(t *testing.T) {
+	tests := []struct {
+		Name           string
+		PersistOnError bool
+	}{
+		{"Persist on error", true},
+		{"Drop on error", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			sender, _ := newTestAMQPSender(test.PersistOnError, nil, errors.New("connection refused"))
+
+			appContext := appfunction.NewContext("123", dic, "")
+			appContext.AddValue(interfaces.DEVICENAME, "thermostat")
+
+			continuePipeline, result := sender.AMQPSend(appContext, []byte("data"))
+			require.False(t, continuePipeline)
+			require.Error(t, result.(error))
+			assert.Contains(t, result.(error).Error(), "connection refused")
+			if test.PersistOnError {
+				assert.Equal(t, []byte("data"), appContext.RetryData())
+			} else {
+				assert.Nil(t, appContext.RetryData())
+			}
+		})
+	}
+}
+
+func TestAMQPSender_AMQPSendPublishFailed(t *testing.T) {
+	publisher := &fakeAMQPPublisher{err: errors.New("message was not confirmed")}
+	sender, dials := newTestAMQPSender(true, publisher, nil)
+
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.AddValue(interfaces.DEVICENAME, "thermostat")
+
+	continuePipeline, result := sender.AMQPSend(appContext, []byte("data"))
+	require.False(t, continuePipeline)
+	require.Error(t, result.(error))
+	assert.Equal(t, []byte("data"), appContext.RetryData())
+	assert.True(t, publisher.closed)
+
+	// Reconnects on the next export
+	publisher.err = nil
+	continuePipeline, _ = sender.AMQPSend(appContext, []byte("data"))
+	require.True(t, continuePipeline)
+	assert.Equal(t, 2, *dials)
+}
+
+func TestAMQPSender_connectBadTimeout(t *testing.T) {
+	sender := NewAMQPSender(AMQPSenderConfig{BrokerAddress: "amqp://localhost:5672/", ConnectTimeout: "bogus"}, false)
+	_, err := sender.connect(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ConnectTimeout")
+}
@@ -33,17 +33,20 @@ const (
 	BatchByCountOnly = iota
 	BatchByTimeOnly
 	BatchByTimeAndCount
+	BatchByBytesOnly
 )
 
 type atomicBatchData struct {
 	mutex sync.Mutex
 	data  [][]byte
+	size  int
 }
 
 func (d *atomicBatchData) append(toBeAdded []byte) [][]byte {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 	d.data = append(d.data, toBeAdded)
+	d.size += len(toBeAdded)
 	result := d.data
 	return result
 }
@@ -55,10 +58,20 @@ func (d *atomicBatchData) all() [][]byte {
 	return result
 }
 
-func (d *atomicBatchData) removeAll() {
+// takeAll returns the data and removes it, so data appended meanwhile isn't removed without being returned
+func (d *atomicBatchData) takeAll() [][]byte {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
+	result := d.data
 	d.data = nil
+	d.size = 0
+	return result
+}
+
+func (d *atomicBatchData) bytes() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.size
 }
 
 func (d *atomicBatchData) length() int {
@@ -73,11 +86,13 @@ type BatchConfig struct {
 	timeInterval                string
 	parsedDuration              time.Duration
 	batchThreshold              int
+	byteThreshold               int
 	batchMode                   BatchMode
 	batchData                   atomicBatchData
 	continuedPipelineTransforms []interfaces.AppFunction
 	timerActive                 common.AtomicBool
 	done                        chan bool
+	flush                       chan struct{}
 }
 
 // NewBatchByTime create, initializes  and returns a new instance for BatchConfig
@@ -92,6 +107,7 @@ func NewBatchByTime(timeInterval string) (*BatchConfig, error) {
 		return nil, err
 	}
 	config.done = make(chan bool)
+	config.flush = make(chan struct{}, 1)
 
 	return &config, nil
 }
@@ -106,6 +122,21 @@ func NewBatchByCount(batchThreshold int) (*BatchConfig, error) {
 	return &config, nil
 }
 
+// NewBatchByBytes create, initializes  and returns a new instance for BatchConfig, which forwards the batched data
+// once its accumulated size reaches the byteThreshold
+func NewBatchByBytes(byteThreshold int) (*BatchConfig, error) {
+	if byteThreshold <= 0 {
+		return nil, errors.New("batch byte threshold must be greater than zero")
+	}
+
+	config := BatchConfig{
+		byteThreshold: byteThreshold,
+		batchMode:     BatchByBytesOnly,
+	}
+
+	return &config, nil
+}
+
 // NewBatchByTimeAndCount create, initializes  and returns a new instance for BatchConfig
 func NewBatchByTimeAndCount(timeInterval string, batchThreshold int) (*BatchConfig, error) {
 	config := BatchConfig{
@@ -119,6 +150,7 @@ func NewBatchByTimeAndCount(timeInterval string, batchThreshold int) (*BatchConf
 		return nil, err
 	}
 	config.done = make(chan bool)
+	config.flush = make(chan struct{}, 1)
 
 	return &config, nil
 }
@@ -138,30 +170,50 @@ func (batch *BatchConfig) Batch(ctx interfaces.AppFunctionContext, data interfac
 	// always append data
 	batch.batchData.append(byteData)
 
+	if batch.batchMode == BatchByBytesOnly && !batch.bytesReached() {
+		return false, nil
+	}
+
 	// If its time only or time and count
-	if batch.batchMode != BatchByCountOnly {
+	if batch.batchMode == BatchByTimeOnly || batch.batchMode == BatchByTimeAndCount {
 		if !batch.timerActive.Value() {
-			batch.timerActive.Set(true)
+			// Discard a flush requested as the previous timer elapsed, since that batch has already been forwarded
 			select {
-			case <-batch.done:
-				ctx.LoggingClient().Debug("Batch count has been reached")
-			case <-time.After(batch.parsedDuration):
-				ctx.LoggingClient().Debug("Timer has elapsed")
+			case <-batch.flush:
+			default:
+			}
+			batch.timerActive.Set(true)
+			if batch.bytesReached() {
+				ctx.LoggingClient().Debug("Batch byte threshold has been reached")
+			} else {
+				select {
+				case <-batch.done:
+					ctx.LoggingClient().Debug("Batch count has been reached")
+				case <-time.After(batch.parsedDuration):
+					ctx.LoggingClient().Debug("Timer has elapsed")
+				case <-batch.flush:
+					ctx.LoggingClient().Debug("Batch flush requested")
+				}
 			}
 			batch.timerActive.Set(false)
 		} else {
+			if batch.bytesReached() {
+				// The pipeline execution waiting for the timer forwards the batched data
+				batch.Flush()
+				return false, nil
+			}
 			if batch.batchMode == BatchByTimeOnly {
 				return false, nil
 			}
 		}
 	}
 
-	if batch.batchMode != BatchByTimeOnly {
+	if batch.batchMode == BatchByCountOnly || batch.batchMode == BatchByTimeAndCount {
 		//Only want to check the threshold if the timer is running and in TimeAndCount mode OR if we are
 		// in CountOnly mode
 		if batch.batchMode == BatchByCountOnly || (batch.timerActive.Value() && batch.batchMode == BatchByTimeAndCount) {
 			// if we have not reached the threshold, then stop pipeline and continue batching
-			if batch.batchData.length() < batch.batchThreshold {
+			if batch.batchData.length() < batch.batchThreshold && !batch.bytesReached() {
 				return false, nil
 			}
 			// if in BatchByCountOnly mode, there are no listeners so this would hang indefinitely
@@ -174,9 +226,38 @@ func (batch *BatchConfig) Batch(ctx interfaces.AppFunctionContext, data interfac
 	ctx.LoggingClient().Debug("Forwarding Batched Data...")
 	// we've met the threshold, lets clear out the buffer and send it forward in the pipeline
 	if batch.batchData.length() > 0 {
-		copyOfData := batch.batchData.all()
-		batch.batchData.removeAll()
-		return true, copyOfData
+		return true, batch.batchData.takeAll()
 	}
 	return false, nil
 }
+
+// SetByteThreshold sets the size in bytes at which the batched data is forwarded, in addition to the count and time
+// thresholds of the batch mode, i.e. to keep the batches within the request size limit of the destination. As the
+// batch is forwarded once the threshold is reached, the threshold should allow for the size of the last data added.
+// Zero doesn't limit the size.
+func (batch *BatchConfig) SetByteThreshold(byteThreshold int) {
+	batch.byteThreshold = byteThreshold
+}
+
+// bytesReached returns whether the size of the batched data has reached the byte threshold, when set
+func (batch *BatchConfig) bytesReached() bool {
+	return batch.byteThreshold > 0 && batch.batchData.bytes() >= batch.byteThreshold
+}
+
+// Flush forwards the data batched by time without waiting for the time interval to elapse or the count to be
+// reached, i.e. when the service is stopping, and returns whether there was batched data to forward. The data
+// batched by count only isn't forwarded until the count is reached, as there is no pipeline execution waiting to
+// forward it.
+func (batch *BatchConfig) Flush() bool {
+	if batch.flush == nil || !batch.timerActive.Value() {
+		return false
+	}
+
+	// The pipeline execution waiting for the timer receives the flush, which is discarded if the timer elapses first
+	select {
+	case batch.flush <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var dataToBatch = [3]string{"Test1", "Test2", "Test3"}
@@ -179,3 +180,125 @@ func TestBatchInTimeMode(t *testing.T) {
 	}()
 	wgAll.Wait()
 }
+
+func TestBatchFlush(t *testing.T) {
+	modes := []struct {
+		Name  string
+		Batch func() (*BatchConfig, error)
+	}{
+		{"Time", func() (*BatchConfig, error) { return NewBatchByTime("1m") }},
+		{"Time and count", func() (*BatchConfig, error) { return NewBatchByTimeAndCount("1m", 10) }},
+	}
+
+	for _, mode := range modes {
+		t.Run(mode.Name, func(t *testing.T) {
+			bs, err := mode.Batch()
+			require.NoError(t, err)
+
+			// Nothing batched to flush
+			assert.False(t, bs.Flush())
+
+			results := make(chan interface{})
+			go func() {
+				continuePipeline, result := bs.Batch(ctx, []byte(dataToBatch[0]))
+				assert.True(t, continuePipeline)
+				results <- result
+			}()
+
+			require.Eventually(t, bs.timerActive.Value, time.Second, time.Millisecond)
+			continuePipeline, result := bs.Batch(ctx, []byte(dataToBatch[1]))
+			assert.False(t, continuePipeline)
+			assert.Nil(t, result)
+
+			assert.True(t, bs.Flush())
+
+			select {
+			case result := <-results:
+				assert.Equal(t, [][]byte{[]byte(dataToBatch[0]), []byte(dataToBatch[1])}, result)
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "batch not flushed")
+			}
+
+			// The next batch waits for the time interval again
+			require.Eventually(t, func() bool { return !bs.timerActive.Value() }, time.Second, time.Millisecond)
+			assert.False(t, bs.Flush())
+			assert.Len(t, bs.flush, 0)
+		})
+	}
+}
+
+func TestBatchFlushInCountMode(t *testing.T) {
+	bs, _ := NewBatchByCount(3)
+
+	continuePipeline, _ := bs.Batch(ctx, []byte(dataToBatch[0]))
+	assert.False(t, continuePipeline)
+	assert.False(t, bs.Flush())
+	assert.Len(t, bs.batchData.all(), 1)
+}
+
+func TestBatchInBytesMode(t *testing.T) {
+	_, err := NewBatchByBytes(0)
+	require.Error(t, err)
+
+	bs, err := NewBatchByBytes(10)
+	require.NoError(t, err)
+
+	continuePipeline, result := bs.Batch(ctx, []byte(dataToBatch[0]))
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+
+	continuePipeline, result = bs.Batch(ctx, []byte(dataToBatch[1]))
+	require.True(t, continuePipeline)
+	assert.Equal(t, [][]byte{[]byte(dataToBatch[0]), []byte(dataToBatch[1])}, result)
+	assert.Len(t, bs.batchData.all(), 0, "Records should have been cleared")
+	assert.Equal(t, 0, bs.batchData.bytes())
+
+	// Data larger than the threshold is forwarded by itself
+	continuePipeline, result = bs.Batch(ctx, []byte("TestTestTest"))
+	require.True(t, continuePipeline)
+	assert.Equal(t, [][]byte{[]byte("TestTestTest")}, result)
+}
+
+func TestBatchInCountModeWithByteThreshold(t *testing.T) {
+	bs, _ := NewBatchByCount(10)
+	bs.SetByteThreshold(10)
+
+	continuePipeline, _ := bs.Batch(ctx, []byte(dataToBatch[0]))
+	assert.False(t, continuePipeline)
+
+	continuePipeline, result := bs.Batch(ctx, []byte(dataToBatch[1]))
+	require.True(t, continuePipeline)
+	assert.Len(t, result, 2)
+}
+
+func TestBatchInTimeModeWithByteThreshold(t *testing.T) {
+	bs, _ := NewBatchByTime("1m")
+	bs.SetByteThreshold(10)
+
+	results := make(chan interface{})
+	go func() {
+		continuePipeline, result := bs.Batch(ctx, []byte(dataToBatch[0]))
+		assert.True(t, continuePipeline)
+		results <- result
+	}()
+
+	require.Eventually(t, bs.timerActive.Value, time.Second, time.Millisecond)
+
+	// Reaching the threshold forwards the batch from the pipeline execution waiting for the timer
+	continuePipeline, result := bs.Batch(ctx, []byte(dataToBatch[1]))
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+
+	select {
+	case result := <-results:
+		assert.Equal(t, [][]byte{[]byte(dataToBatch[0]), []byte(dataToBatch[1])}, result)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "batch not forwarded once byte threshold reached")
+	}
+
+	// Data reaching the threshold by itself doesn't wait for the timer
+	require.Eventually(t, func() bool { return !bs.timerActive.Value() }, time.Second, time.Millisecond)
+	continuePipeline, result = bs.Batch(ctx, []byte("TestTestTest"))
+	require.True(t, continuePipeline)
+	assert.Equal(t, [][]byte{[]byte("TestTestTest")}, result)
+}
@@ -0,0 +1,77 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+)
+
+// BinaryPayload houses the transform for extracting the raw payload of an Event's binary reading, i.e. an image or
+// audio clip, so it can be exported as is rather than wrapped in the Event
+type BinaryPayload struct {
+	resourceName string
+}
+
+// NewBinaryPayload creates, initializes and returns a new instance of BinaryPayload. The payload is extracted from
+// the binary reading for the specified resource, or the first binary reading when the resource name is empty.
+func NewBinaryPayload(resourceName string) BinaryPayload {
+	return BinaryPayload{
+		resourceName: resourceName,
+	}
+}
+
+// ExtractBinaryPayload returns the raw binary value of the Event's binary reading and sets the response content type
+// to the reading's media type, which is used by HTTPExport when its MimeType isn't set.
+// If the Event has no matching binary reading the pipeline execution stops.
+// It will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
+func (f BinaryPayload) ExtractBinaryPayload(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("type received is not an Event")
+	}
+
+	for _, reading := range event.Readings {
+		if reading.ValueType != common.ValueTypeBinary {
+			continue
+		}
+
+		if len(f.resourceName) > 0 && reading.ResourceName != f.resourceName {
+			continue
+		}
+
+		ctx.LoggingClient().Debugf("Extracted %d byte binary payload of '%s' reading", len(reading.BinaryValue), reading.ResourceName)
+
+		if len(reading.MediaType) > 0 {
+			ctx.SetResponseContentType(reading.MediaType)
+		}
+
+		return true, reading.BinaryValue
+	}
+
+	ctx.LoggingClient().Debugf("Event from device '%s' has no matching binary reading. Stopping pipeline", event.DeviceName)
+	ctx.SetFiltered("no matching binary reading")
+	return false, nil
+}
@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractBinaryPayload(t *testing.T) {
+	image := []byte{0xff, 0xd8, 0xff, 0xe0, 0x00}
+	audio := []byte{0x52, 0x49, 0x46, 0x46, 0x00}
+
+	event := dtos.NewEvent("profile1", "dev1", "source1")
+	require.NoError(t, event.AddSimpleReading("temperature", common.ValueTypeInt32, int32(21)))
+	event.AddBinaryReading("image", image, "image/jpeg")
+	event.AddBinaryReading("audio", audio, "audio/wav")
+
+	simpleEvent := dtos.NewEvent("profile1", "dev1", "source1")
+	require.NoError(t, simpleEvent.AddSimpleReading("temperature", common.ValueTypeInt32, int32(21)))
+
+	tests := []struct {
+		Name                string
+		ResourceName        string
+		Data                interface{}
+		ExpectedContinue    bool
+		ExpectedPayload     []byte
+		ExpectedContentType string
+		ExpectError         bool
+	}{
+		{"First binary reading", "", event, true, image, "image/jpeg", false},
+		{"Named binary reading", "audio", event, true, audio, "audio/wav", false},
+		{"Named reading not binary", "temperature", event, false, nil, "", false},
+		{"No binary reading", "", simpleEvent, false, nil, "", false},
+		{"No data", "", nil, false, nil, "", true},
+		{"Not an Event", "", "bogus", false, nil, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx.SetResponseContentType("")
+			target := NewBinaryPayload(test.ResourceName)
+
+			continuePipeline, result := target.ExtractBinaryPayload(ctx, test.Data)
+			require.Equal(t, test.ExpectedContinue, continuePipeline)
+
+			if test.ExpectError {
+				_, ok := result.(error)
+				assert.True(t, ok, "expected error result")
+				return
+			}
+
+			if !test.ExpectedContinue {
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.Equal(t, test.ExpectedPayload, result)
+			assert.Equal(t, test.ExpectedContentType, ctx.ResponseContentType())
+		})
+	}
+
+	ctx.SetResponseContentType("")
+}
@@ -0,0 +1,111 @@
+//go:build !no_cel
+// +build !no_cel
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/google/cel-go/cel"
+)
+
+// CELEventVariable is the name of the variable holding the Event a CEL expression is evaluated against.
+// The Event's fields are accessed by their JSON names, i.e. event.deviceName or event.readings[0].value
+const CELEventVariable = "event"
+
+// CELFilter houses the Common Expression Language (CEL) program which decides whether Events continue through the pipeline
+type CELFilter struct {
+	program cel.Program
+}
+
+// NewCELFilter compiles the specified CEL expression, i.e. `event.deviceName == "Random-Float-Device" &&
+// event.readings.exists(r, r.resourceName == "Float64" && double(r.value) > 100.0)`, and returns a new instance of CELFilter.
+// The Event is the only variable declared and its JSON numbers are CEL doubles.
+// An error is returned if the expression fails to compile.
+func NewCELFilter(expression string) (*CELFilter, error) {
+	env, err := cel.NewEnv(cel.Variable(CELEventVariable, cel.DynType))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create CEL environment: %s", err.Error())
+	}
+
+	compiled, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid CEL expression '%s': %s", expression, issues.Err().Error())
+	}
+
+	program, err := env.Program(compiled)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEL expression '%s': %s", expression, err.Error())
+	}
+
+	return &CELFilter{
+		program: program,
+	}, nil
+}
+
+// Evaluate evaluates the CEL expression against the Event received. The Event continues through the pipeline when the
+// expression evaluates to true and the pipeline is stopped when it evaluates to false.
+// It will return an error and stop the pipeline if a non-edgex event is received, no data is received or the
+// expression doesn't evaluate to a bool.
+func (f *CELFilter) Evaluate(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("FilterByCEL: no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("FilterByCEL: type received is not an Event")
+	}
+
+	// The Event is evaluated in its JSON form so that fields are accessed by the same names used on the wire
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return false, fmt.Errorf("unable to marshal Event for CEL evaluation: %s", err.Error())
+	}
+
+	var eventValue interface{}
+	if err := json.Unmarshal(eventJSON, &eventValue); err != nil {
+		return false, fmt.Errorf("unable to unmarshal Event for CEL evaluation: %s", err.Error())
+	}
+
+	result, _, err := f.program.Eval(map[string]interface{}{CELEventVariable: eventValue})
+	if err != nil {
+		return false, fmt.Errorf("unable to evaluate CEL expression: %s", err.Error())
+	}
+
+	accepted, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL expression must evaluate to a bool, got %s", result.Type().TypeName())
+	}
+
+	if !accepted {
+		ctx.LoggingClient().Debug("Event not accepted by CEL expression")
+		ctx.SetFiltered("not accepted by CEL expression")
+		return false, nil
+	}
+
+	ctx.LoggingClient().Debug("Event accepted by CEL expression")
+	return true, event
+}
@@ -0,0 +1,145 @@
+//go:build !no_cel
+// +build !no_cel
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCELTestEvent() dtos.Event {
+	event := dtos.NewEvent("thermostat", "Thermostat-01", "status")
+	event.Tags = map[string]string{"building": "B3"}
+	_ = event.AddSimpleReading("temperature", common.ValueTypeFloat64, 21.5)
+	_ = event.AddSimpleReading("humidity", common.ValueTypeInt32, int32(45))
+	return event
+}
+
+func TestNewCELFilter(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Expression  string
+		ExpectError bool
+	}{
+		{"Valid comparison", `event.deviceName == "Thermostat-01"`, false},
+		{"Valid macro", `event.readings.exists(r, r.resourceName.startsWith("temp") && double(r.value) > 20.0)`, false},
+		{"Valid has", `has(event.tags.building) && size(event.readings) > 1`, false},
+		{"Syntax error", `event.deviceName ==`, true},
+		{"Undeclared reference", `device.name == "x"`, true},
+		{"Macro variable out of scope", `event.readings.exists(r, true) && r.value == "1"`, true},
+		{"Single quoted string", `event.deviceName == 'T'`, false},
+		{"In and conditional operators", `("building" in event.tags ? event.tags.building : "") == "B3"`, false},
+		{"Unknown function", `lower(event.deviceName) == "x"`, true},
+		{"Unknown method", `event.deviceName.lower() == "x"`, true},
+		{"Wrong argument count", `size(event.readings, 1) == 2`, true},
+		{"Bad macro variable", `event.readings.exists("r", true)`, true},
+		{"Bad has argument", `has(event)`, true},
+		{"Unsupported operator", `event.origin & 1`, true},
+		{"Unsupported expression", `func() {}`, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := NewCELFilter(testCase.Expression)
+			if testCase.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCELFilterEvaluate(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Expression     string
+		ExpectAccepted bool
+	}{
+		{"Field equals", `event.deviceName == "Thermostat-01"`, true},
+		{"Field not equals", `event.deviceName != "Thermostat-01"`, false},
+		{"Tag index", `event.tags["building"] == "B3"`, true},
+		{"Has tag", `has(event.tags.building)`, true},
+		{"Has missing tag guards access", `has(event.tags.floor) && event.tags.floor == "2"`, false},
+		{"Exists reading", `event.readings.exists(r, r.resourceName == "humidity" && int(r.value) >= 45)`, true},
+		{"All readings", `event.readings.all(r, double(r.value) > 30.0)`, false},
+		{"Exists one", `event.readings.exists_one(r, r.valueType == "Float64")`, true},
+		{"Filter size", `size(event.readings.filter(r, r.resourceName.endsWith("ity"))) == 1`, true},
+		{"Arithmetic", `double(event.readings[0].value) * 2.0 + 1.0 == 44.0`, true},
+		{"String functions", `event.profileName.contains("stat") && event.sourceName.matches("^st.*s$")`, true},
+		{"Method size", `event.sourceName.size() == 6 && !(event.tags.size() > 1)`, true},
+		{"String conversion", `string(int(event.readings[1].value)) + "%" == "45%"`, true},
+		{"Map keys macro", `event.tags.exists(k, k == "building")`, true},
+		{"Or", `event.deviceName == "other" || event.sourceName < "t"`, true},
+		{"In", `"building" in event.tags && "floor" in event.tags`, false},
+		{"Map macro", `event.readings.map(r, r.resourceName)[1] == "humidity"`, true},
+		{"Number field", `event.origin > 0.0`, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			filter, err := NewCELFilter(testCase.Expression)
+			require.NoError(t, err)
+
+			event := newCELTestEvent()
+			continuePipeline, result := filter.Evaluate(ctx, event)
+			assert.Equal(t, testCase.ExpectAccepted, continuePipeline)
+			if testCase.ExpectAccepted {
+				assert.Equal(t, event, result)
+				return
+			}
+			assert.Nil(t, result)
+		})
+	}
+}
+
+func TestCELFilterEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Expression string
+		Data       interface{}
+	}{
+		{"No data", `true`, nil},
+		{"Not an Event", `true`, "not an event"},
+		{"Not a bool", `event.deviceName`, newCELTestEvent()},
+		{"Missing key", `event.tags.floor == "2"`, newCELTestEvent()},
+		{"Index out of range", `event.readings[5].value == "1"`, newCELTestEvent()},
+		{"Type mismatch", `event.deviceName > 1`, newCELTestEvent()},
+		{"Bad conversion", `double(event.deviceName) > 1.0`, newCELTestEvent()},
+		{"Division by zero", `event.origin / 0 > 1`, newCELTestEvent()},
+		{"Bad regular expression", `event.deviceName.matches("(")`, newCELTestEvent()},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			filter, err := NewCELFilter(testCase.Expression)
+			require.NoError(t, err)
+
+			continuePipeline, result := filter.Evaluate(ctx, testCase.Data)
+			assert.False(t, continuePipeline)
+			assert.Error(t, result.(error))
+		})
+	}
+}
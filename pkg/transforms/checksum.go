@@ -0,0 +1,76 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+)
+
+const (
+	// ChecksumCRC32 is the CRC-32 checksum, using the IEEE polynomial
+	ChecksumCRC32 = "crc32"
+	// ChecksumSHA256 is the SHA-256 checksum
+	ChecksumSHA256 = "sha256"
+	// ChecksumHeaderPrefix is the prefix of the HTTP export header the checksum is sent in, followed by the
+	// upper-cased algorithm, i.e. X-Checksum-SHA256
+	ChecksumHeaderPrefix = "X-Checksum-"
+)
+
+// newChecksumHash returns the hash computing the checksum with the algorithm, which is case insensitive
+func newChecksumHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case ChecksumCRC32:
+		return crc32.NewIEEE(), nil
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("invalid checksum algorithm '%s'. Must be '%s' or '%s'", algorithm, ChecksumCRC32, ChecksumSHA256)
+	}
+}
+
+// computeChecksum returns the hex encoded checksum of the data computed with the algorithm
+func computeChecksum(algorithm string, data []byte) (string, error) {
+	checksum, err := newChecksumHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	_, _ = checksum.Write(data)
+	return hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+// checksumHeader returns the name of the HTTP header the checksum computed with the algorithm is sent in
+func checksumHeader(algorithm string) string {
+	return ChecksumHeaderPrefix + strings.ToUpper(algorithm)
+}
+
+// appendChecksumTrailer returns a copy of the data followed by a line with the algorithm and the checksum of the data,
+// i.e. '\nsha256=<hex>', so the receiver can split the checksum from the data at the last newline
+func appendChecksumTrailer(algorithm string, data []byte) ([]byte, error) {
+	checksum, err := computeChecksum(algorithm, data)
+	if err != nil {
+		return nil, err
+	}
+
+	trailer := "\n" + strings.ToLower(algorithm) + "=" + checksum
+	return append(append(make([]byte, 0, len(data)+len(trailer)), data...), trailer...), nil
+}
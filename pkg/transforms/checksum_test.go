@@ -0,0 +1,58 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeChecksum(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Algorithm string
+		Expected  string
+	}{
+		{"CRC32", ChecksumCRC32, "cbf43926"},
+		{"SHA256", "SHA256", "15e2b0d3c33891ebb0f1ef609ec419420c20e320ce94c65fbc8c3312448eb225"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			checksum, err := computeChecksum(test.Algorithm, []byte("123456789"))
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, checksum)
+		})
+	}
+
+	_, err := computeChecksum("md5", []byte("123456789"))
+	assert.Error(t, err)
+}
+
+func TestAppendChecksumTrailer(t *testing.T) {
+	data := []byte("123456789")
+	result, err := appendChecksumTrailer("CRC32", data)
+	require.NoError(t, err)
+	assert.Equal(t, "123456789\ncrc32=cbf43926", string(result))
+	assert.Equal(t, "123456789", string(data), "data should not be modified")
+}
+
+func TestChecksumHeader(t *testing.T) {
+	assert.Equal(t, "X-Checksum-SHA256", checksumHeader(ChecksumSHA256))
+}
@@ -0,0 +1,204 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+)
+
+const (
+	// ClockSkewOffsetTag is the Event tag that is set to the offset applied when timestamps have been corrected
+	ClockSkewOffsetTag = "ClockSkewOffset"
+
+	defaultNTPRefreshInterval = time.Hour
+	defaultNTPTimeout         = 5 * time.Second
+	ntpPort                   = "123"
+	// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the Unix epoch (1970)
+	ntpEpochOffset = 2208988800
+)
+
+// ClockSkew houses the configuration for correcting the Origin timestamps of Events and Readings
+type ClockSkew struct {
+	offset          time.Duration
+	ntpServer       string
+	refreshInterval time.Duration
+	lastRefreshed   time.Time
+	lock            sync.Mutex
+}
+
+// NewClockSkewWithOffset creates, initializes and returns a new instance of ClockSkew which
+// corrects timestamps by the specified fixed offset
+func NewClockSkewWithOffset(offset time.Duration) *ClockSkew {
+	return &ClockSkew{
+		offset: offset,
+	}
+}
+
+// NewClockSkewWithNTP creates, initializes and returns a new instance of ClockSkew which
+// corrects timestamps by the offset between the local clock and the specified NTP server.
+// The offset is re-queried from the NTP server once the refresh interval has elapsed.
+// A zero refresh interval defaults to one hour.
+func NewClockSkewWithNTP(ntpServer string, refreshInterval time.Duration) *ClockSkew {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultNTPRefreshInterval
+	}
+
+	return &ClockSkew{
+		ntpServer:       ntpServer,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// CorrectTimestamps adjusts the Origin timestamp of the Event and all of its Readings by the configured or
+// NTP derived offset and tags the Event with the offset that was applied.
+// It will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
+func (cs *ClockSkew) CorrectTimestamps(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("CorrectTimestamps: no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("CorrectTimestamps: type received is not an Event")
+	}
+
+	offset, err := cs.getOffset(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	ctx.LoggingClient().Debugf("Correcting Event and Reading timestamps by %s", offset.String())
+
+	event.Origin += offset.Nanoseconds()
+
+	// Readings slice is shared with the original Event so must make a copy before modifying
+	readings := make([]dtos.BaseReading, len(event.Readings))
+	for index, reading := range event.Readings {
+		reading.Origin += offset.Nanoseconds()
+		readings[index] = reading
+	}
+	event.Readings = readings
+
+	tags := make(map[string]string, len(event.Tags)+1)
+	for tag, value := range event.Tags {
+		tags[tag] = value
+	}
+	tags[ClockSkewOffsetTag] = offset.String()
+	event.Tags = tags
+
+	return true, event
+}
+
+func (cs *ClockSkew) getOffset(ctx interfaces.AppFunctionContext) (time.Duration, error) {
+	if len(cs.ntpServer) == 0 {
+		return cs.offset, nil
+	}
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	if !cs.lastRefreshed.IsZero() && time.Since(cs.lastRefreshed) < cs.refreshInterval {
+		return cs.offset, nil
+	}
+
+	offset, err := queryNTPOffset(cs.ntpServer, defaultNTPTimeout)
+	if err != nil {
+		if cs.lastRefreshed.IsZero() {
+			return 0, fmt.Errorf("unable to determine clock offset from NTP server '%s': %s", cs.ntpServer, err.Error())
+		}
+
+		ctx.LoggingClient().Warnf(
+			"unable to refresh clock offset from NTP server '%s', using previous offset of %s: %s",
+			cs.ntpServer,
+			cs.offset.String(),
+			err.Error())
+		return cs.offset, nil
+	}
+
+	ctx.LoggingClient().Debugf("Clock offset from NTP server '%s' is %s", cs.ntpServer, offset.String())
+
+	cs.offset = offset
+	cs.lastRefreshed = time.Now()
+
+	return cs.offset, nil
+}
+
+// queryNTPOffset sends a SNTP (RFC 4330) request to the specified server and returns the offset
+// between the server's clock and the local clock.
+func queryNTPOffset(server string, timeout time.Duration) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, ntpPort)
+	}
+
+	conn, err := net.DialTimeout("udp", server, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	request := make([]byte, 48)
+	// LI = 0 (no warning), VN = 4, Mode = 3 (client)
+	request[0] = 0x23
+
+	sent := time.Now()
+	binary.BigEndian.PutUint64(request[40:], toNTPTime(sent))
+
+	if _, err := conn.Write(request); err != nil {
+		return 0, err
+	}
+
+	response := make([]byte, 48)
+	count, err := conn.Read(response)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+
+	if count < 48 {
+		return 0, fmt.Errorf("invalid NTP response of %d bytes", count)
+	}
+
+	serverReceived := fromNTPTime(binary.BigEndian.Uint64(response[32:]))
+	serverTransmitted := fromNTPTime(binary.BigEndian.Uint64(response[40:]))
+
+	return (serverReceived.Sub(sent) + serverTransmitted.Sub(received)) / 2, nil
+}
+
+func toNTPTime(t time.Time) uint64 {
+	seconds := uint64(t.Unix()) + ntpEpochOffset
+	fraction := (uint64(t.Nanosecond()) << 32) / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+func fromNTPTime(ntpTime uint64) time.Time {
+	seconds := int64(ntpTime>>32) - ntpEpochOffset
+	nanoseconds := int64(((ntpTime & 0xffffffff) * uint64(time.Second)) >> 32)
+	return time.Unix(seconds, nanoseconds)
+}
@@ -0,0 +1,146 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClockSkewTestEvent() dtos.Event {
+	event := dtos.Event{
+		Origin: 1000,
+		Tags:   map[string]string{"Tag1": "Value1"},
+		Readings: []dtos.BaseReading{
+			{Origin: 2000},
+			{Origin: 3000},
+		},
+	}
+	return event
+}
+
+// startFakeNTPServer starts a UDP server which responds to SNTP requests with its clock ahead of the local clock
+// by the specified skew. The returned function closes the server.
+func startFakeNTPServer(t *testing.T, skew time.Duration) (string, func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		buffer := make([]byte, 48)
+		for {
+			_, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+
+			response := make([]byte, 48)
+			response[0] = 0x24 // VN = 4, Mode = 4 (server)
+			now := toNTPTime(time.Now().Add(skew))
+			binary.BigEndian.PutUint64(response[32:], now)
+			binary.BigEndian.PutUint64(response[40:], now)
+			_, _ = conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String(), func() { _ = conn.Close() }
+}
+
+func TestClockSkew_CorrectTimestampsWithOffset(t *testing.T) {
+	original := newClockSkewTestEvent()
+	target := NewClockSkewWithOffset(500 * time.Nanosecond)
+
+	continuePipeline, result := target.CorrectTimestamps(ctx, original)
+	require.True(t, continuePipeline)
+
+	actual, ok := result.(dtos.Event)
+	require.True(t, ok, "Result not an Event")
+	assert.Equal(t, int64(1500), actual.Origin)
+	assert.Equal(t, int64(2500), actual.Readings[0].Origin)
+	assert.Equal(t, int64(3500), actual.Readings[1].Origin)
+	assert.Equal(t, "Value1", actual.Tags["Tag1"])
+	assert.Equal(t, "500ns", actual.Tags[ClockSkewOffsetTag])
+
+	// Original Event must not be modified
+	assert.Equal(t, int64(2000), original.Readings[0].Origin)
+	assert.NotContains(t, original.Tags, ClockSkewOffsetTag)
+}
+
+func TestClockSkew_CorrectTimestampsWithNTP(t *testing.T) {
+	skew := time.Hour
+	address, closeServer := startFakeNTPServer(t, skew)
+
+	target := NewClockSkewWithNTP(address, time.Minute)
+
+	continuePipeline, result := target.CorrectTimestamps(ctx, newClockSkewTestEvent())
+	require.True(t, continuePipeline, result)
+
+	actual, ok := result.(dtos.Event)
+	require.True(t, ok, "Result not an Event")
+	assert.InDelta(t, int64(1000)+skew.Nanoseconds(), actual.Origin, float64(time.Second))
+	firstOffset := target.offset
+
+	// Offset is cached until the refresh interval elapses, so no error even though the server is gone.
+	closeServer()
+	continuePipeline, _ = target.CorrectTimestamps(ctx, newClockSkewTestEvent())
+	require.True(t, continuePipeline)
+	assert.Equal(t, firstOffset, target.offset)
+
+	// Failed refresh uses the previous offset
+	target.refreshInterval = 0
+	continuePipeline, _ = target.CorrectTimestamps(ctx, newClockSkewTestEvent())
+	require.True(t, continuePipeline)
+	assert.Equal(t, firstOffset, target.offset)
+}
+
+func TestClockSkew_CorrectTimestampsErrors(t *testing.T) {
+	address, closeServer := startFakeNTPServer(t, 0)
+	closeServer()
+
+	tests := []struct {
+		Name          string
+		Target        *ClockSkew
+		Data          interface{}
+		ErrorContains string
+	}{
+		{"No Data", NewClockSkewWithOffset(time.Second), nil, "no Event Received"},
+		{"Not an Event", NewClockSkewWithOffset(time.Second), "Not an Event", "not an Event"},
+		{"NTP unreachable", NewClockSkewWithNTP(address, time.Minute), newClockSkewTestEvent(), "NTP server"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := test.Target.CorrectTimestamps(ctx, test.Data)
+			require.False(t, continuePipeline)
+			err, ok := result.(error)
+			require.True(t, ok)
+			assert.Contains(t, err.Error(), test.ErrorContains)
+		})
+	}
+}
+
+func TestNTPTimeConversion(t *testing.T) {
+	expected := time.Unix(1625000000, 123456789)
+	actual := fromNTPTime(toNTPTime(expected))
+	assert.InDelta(t, expected.UnixNano(), actual.UnixNano(), 2)
+}
@@ -34,6 +34,9 @@ import (
 type Compression struct {
 	gzipWriter *gzip.Writer
 	zlibWriter *zlib.Writer
+	level      int
+	levelSet   bool
+	dictionary []byte
 }
 
 // NewCompression creates, initializes and returns a new instance of Compression
@@ -41,6 +44,29 @@ func NewCompression() Compression {
 	return Compression{}
 }
 
+// NewCompressionWithOptions creates, initializes and returns a new instance of Compression which compresses at the
+// specified level, from gzip.HuffmanOnly (-2) to gzip.BestCompression (9), with gzip.DefaultCompression (-1) being
+// the default trade-off between speed and ratio. The optional preset dictionary, which contains byte sequences
+// likely to be found in the data, improves the ratio for small payloads. Only ZLIB supports a preset dictionary.
+func NewCompressionWithOptions(level int, dictionary []byte) (Compression, error) {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return Compression{}, fmt.Errorf("invalid compression level %d, must be from %d to %d", level, gzip.HuffmanOnly, gzip.BestCompression)
+	}
+
+	return Compression{
+		level:      level,
+		levelSet:   true,
+		dictionary: dictionary,
+	}, nil
+}
+
+func (compression *Compression) compressionLevel() int {
+	if !compression.levelSet {
+		return gzip.DefaultCompression
+	}
+	return compression.level
+}
+
 // CompressWithGZIP compresses data received as either a string,[]byte, or json.Marshaller using gzip algorithm
 // and returns a base64 encoded string as a []byte.
 func (compression *Compression) CompressWithGZIP(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
@@ -49,6 +75,9 @@ func (compression *Compression) CompressWithGZIP(ctx interfaces.AppFunctionConte
 		return false, errors.New("No Data Received")
 	}
 	ctx.LoggingClient().Debug("Compression with GZIP")
+	if len(compression.dictionary) > 0 {
+		return false, errors.New("GZIP does not support a preset dictionary")
+	}
 	rawData, err := util.CoerceType(data)
 	if err != nil {
 		return false, err
@@ -56,7 +85,10 @@ func (compression *Compression) CompressWithGZIP(ctx interfaces.AppFunctionConte
 	var buf bytes.Buffer
 
 	if compression.gzipWriter == nil {
-		compression.gzipWriter = gzip.NewWriter(&buf)
+		compression.gzipWriter, err = gzip.NewWriterLevel(&buf, compression.compressionLevel())
+		if err != nil {
+			return false, fmt.Errorf("unable to create GZIP writer: %s", err.Error())
+		}
 	} else {
 		compression.gzipWriter.Reset(&buf)
 	}
@@ -93,8 +125,12 @@ func (compression *Compression) CompressWithZLIB(ctx interfaces.AppFunctionConte
 	var buf bytes.Buffer
 
 	if compression.zlibWriter == nil {
-		compression.zlibWriter = zlib.NewWriter(&buf)
+		compression.zlibWriter, err = zlib.NewWriterLevelDict(&buf, compression.compressionLevel(), compression.dictionary)
+		if err != nil {
+			return false, fmt.Errorf("unable to create ZLIB writer: %s", err.Error())
+		}
 	} else {
+		// Reset keeps the level and preset dictionary the writer was created with
 		compression.zlibWriter.Reset(&buf)
 	}
 
@@ -86,6 +86,73 @@ func TestZlib(t *testing.T) {
 	assert.Equal(t, ctx.ResponseContentType(), common.ContentTypeText)
 }
 
+func TestNewCompressionWithOptions(t *testing.T) {
+	_, err := NewCompressionWithOptions(gzip.BestSpeed, nil)
+	require.NoError(t, err)
+
+	_, err = NewCompressionWithOptions(10, nil)
+	assert.Error(t, err)
+
+	_, err = NewCompressionWithOptions(-3, nil)
+	assert.Error(t, err)
+}
+
+func TestGzipWithLevel(t *testing.T) {
+	comp, err := NewCompressionWithOptions(gzip.NoCompression, nil)
+	require.NoError(t, err)
+
+	continuePipeline, result := comp.CompressWithGZIP(ctx, []byte(clearString))
+	require.True(t, continuePipeline)
+
+	compressed, err := base64.StdEncoding.DecodeString(string(result.([]byte)))
+	require.NoError(t, err)
+	// Stored rather than compressed so the clear text is in the output
+	assert.Contains(t, string(compressed), clearString)
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, clearString, string(decoded))
+
+	comp, err = NewCompressionWithOptions(gzip.BestCompression, []byte("test string"))
+	require.NoError(t, err)
+	continuePipeline, result = comp.CompressWithGZIP(ctx, []byte(clearString))
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+}
+
+func TestZlibWithDictionary(t *testing.T) {
+	dictionary := []byte("used for testing This is the test string")
+
+	comp, err := NewCompressionWithOptions(gzip.BestCompression, dictionary)
+	require.NoError(t, err)
+
+	continuePipeline, result := comp.CompressWithZLIB(ctx, []byte(clearString))
+	require.True(t, continuePipeline)
+
+	compressed, err := base64.StdEncoding.DecodeString(string(result.([]byte)))
+	require.NoError(t, err)
+
+	plain := NewCompression()
+	_, plainResult := plain.CompressWithZLIB(ctx, []byte(clearString))
+	assert.Less(t, len(result.([]byte)), len(plainResult.([]byte)))
+
+	// The dictionary is required to decompress
+	_, err = zlib.NewReader(bytes.NewReader(compressed))
+	assert.Error(t, err)
+
+	zr, err := zlib.NewReaderDict(bytes.NewReader(compressed), dictionary)
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, clearString, string(decoded))
+
+	// The dictionary is kept when the writer is reused
+	_, result2 := comp.CompressWithZLIB(ctx, []byte(clearString))
+	assert.Equal(t, result.([]byte), result2.([]byte))
+}
+
 var result []byte
 
 func BenchmarkGzip(b *testing.B) {
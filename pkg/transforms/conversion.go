@@ -17,17 +17,19 @@
 package transforms
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 )
 
-// Conversion houses various built in conversion transforms (XML, JSON, CSV)
+// Conversion houses various built in conversion transforms (XML, JSON, MsgPack, CSV)
 type Conversion struct {
 }
 
@@ -36,7 +38,7 @@ func NewConversion() Conversion {
 	return Conversion{}
 }
 
-// TransformToXML transforms an EdgeX event to XML.
+// TransformToXML transforms an EdgeX event to XML. Binary reading values are base64 encoded, as they are in JSON.
 // It will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
 func (f Conversion) TransformToXML(ctx interfaces.AppFunctionContext, data interface{}) (continuePipeline bool, stringType interface{}) {
 	if data == nil {
@@ -45,6 +47,7 @@ func (f Conversion) TransformToXML(ctx interfaces.AppFunctionContext, data inter
 
 	ctx.LoggingClient().Debug("Transforming to XML")
 	if event, ok := data.(dtos.Event); ok {
+		event = encodeBinaryReadings(event)
 		xml, err := event.ToXML()
 		if err != nil {
 			return false, fmt.Errorf("unable to marshal Event to XML: %s", err.Error())
@@ -76,3 +79,39 @@ func (f Conversion) TransformToJSON(ctx interfaces.AppFunctionContext, data inte
 	}
 	return false, errors.New("Unexpected type received")
 }
+
+// TransformToMsgPack transforms an EdgeX event to MessagePack, using the same field names as JSON.
+// It will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
+func (f Conversion) TransformToMsgPack(ctx interfaces.AppFunctionContext, data interface{}) (continuePipeline bool, result interface{}) {
+	if data == nil {
+		return false, errors.New("No Event Received")
+	}
+
+	ctx.LoggingClient().Debug("Transforming to MsgPack")
+	if event, ok := data.(dtos.Event); ok {
+		msgPack, err := util.MarshalMsgPack(event)
+		if err != nil {
+			return false, fmt.Errorf("unable to marshal Event to MsgPack: %s", err.Error())
+		}
+
+		ctx.SetResponseContentType(util.ContentTypeMsgPack)
+		return true, msgPack
+	}
+
+	return false, errors.New("Unexpected type received")
+}
+
+// encodeBinaryReadings returns a copy of the event with the values of its binary readings base64 encoded, since
+// raw binary is not valid XML character data.
+func encodeBinaryReadings(event dtos.Event) dtos.Event {
+	readings := make([]dtos.BaseReading, len(event.Readings))
+	for index, reading := range event.Readings {
+		if reading.ValueType == common.ValueTypeBinary {
+			reading.BinaryValue = []byte(base64.StdEncoding.EncodeToString(reading.BinaryValue))
+		}
+		readings[index] = reading
+	}
+
+	event.Readings = readings
+	return event
+}
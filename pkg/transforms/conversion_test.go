@@ -19,6 +19,8 @@ package transforms
 import (
 	"testing"
 
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 
@@ -41,6 +43,19 @@ func TestTransformToXML(t *testing.T) {
 	assert.Equal(t, expectedResult, result.(string))
 }
 
+func TestTransformToXMLBinaryReading(t *testing.T) {
+	image := []byte{0xff, 0xd8, 0x00, 0x80}
+	eventIn := dtos.NewEvent("profile1", "dev1", "source1")
+	eventIn.AddBinaryReading("image", image, "image/jpeg")
+	conv := NewConversion()
+
+	continuePipeline, result := conv.TransformToXML(ctx, eventIn)
+	require.True(t, continuePipeline)
+
+	assert.Contains(t, result.(string), "<BinaryValue>/9gAgA==</BinaryValue>")
+	assert.Equal(t, image, eventIn.Readings[0].BinaryValue, "input Event should not be modified")
+}
+
 func TestTransformToXMLNoData(t *testing.T) {
 	conv := NewConversion()
 	continuePipeline, result := conv.TransformToXML(ctx, nil)
@@ -73,6 +88,36 @@ func TestTransformToJSON(t *testing.T) {
 	assert.Equal(t, expectedResult, result.(string))
 }
 
+func TestTransformToMsgPack(t *testing.T) {
+	eventIn := dtos.NewEvent("profile1", deviceName1, "source1")
+	eventIn.AddBinaryReading("image", []byte{0xff, 0xd8, 0x00, 0x80}, "image/jpeg")
+	conv := NewConversion()
+
+	continuePipeline, result := conv.TransformToMsgPack(ctx, eventIn)
+	require.True(t, continuePipeline)
+	assert.Equal(t, util.ContentTypeMsgPack, ctx.ResponseContentType())
+
+	var eventOut dtos.Event
+	require.NoError(t, util.UnmarshalMsgPack(result.([]byte), &eventOut))
+	assert.Equal(t, eventIn, eventOut)
+}
+
+func TestTransformToMsgPackNoEvent(t *testing.T) {
+	conv := NewConversion()
+	continuePipeline, result := conv.TransformToMsgPack(ctx, nil)
+
+	assert.Equal(t, "No Event Received", result.(error).Error())
+	assert.False(t, continuePipeline)
+}
+
+func TestTransformToMsgPackNotAnEvent(t *testing.T) {
+	conv := NewConversion()
+	continuePipeline, result := conv.TransformToMsgPack(ctx, "")
+
+	assert.Equal(t, "Unexpected type received", result.(error).Error())
+	assert.False(t, continuePipeline)
+}
+
 func TestTransformToJSONNoEvent(t *testing.T) {
 	conv := NewConversion()
 	continuePipeline, result := conv.TransformToJSON(ctx, nil)
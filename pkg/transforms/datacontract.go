@@ -0,0 +1,171 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+const (
+	// DataContractName is the context value key set to the name of the data contract the output was validated against
+	DataContractName = "datacontractname"
+	// DataContractVersion is the context value key set to the version of the data contract the output was validated against
+	DataContractVersion = "datacontractversion"
+)
+
+type registeredSchema struct {
+	text   string
+	schema *jsonSchema
+}
+
+var (
+	dataContracts     = make(map[string]registeredSchema)
+	dataContractsLock sync.RWMutex
+)
+
+// RegisterDataContract registers the JSON Schema for the specified data contract name and version.
+// A registered version is immutable, so an error is returned if the same name and version is already
+// registered with a different schema. Breaking changes to the output must be registered as a new version.
+// Only a subset of JSON Schema (draft 7) is supported: type, properties, required, additionalProperties, items, enum,
+// const, minimum, maximum, minLength, maxLength, pattern, minItems and maxItems, along with annotations such as title
+// and description. An error is returned for schemas using any other keyword.
+func RegisterDataContract(name string, version string, schema string) error {
+	if len(name) == 0 || len(version) == 0 {
+		return errors.New("data contract name and version must be specified")
+	}
+
+	parsed, err := parseJSONSchema(schema)
+	if err != nil {
+		return fmt.Errorf("invalid schema for data contract '%s' version '%s': %s", name, version, err.Error())
+	}
+
+	key := dataContractKey(name, version)
+
+	dataContractsLock.Lock()
+	defer dataContractsLock.Unlock()
+
+	if existing, found := dataContracts[key]; found {
+		if strings.TrimSpace(existing.text) != strings.TrimSpace(schema) {
+			return fmt.Errorf("data contract '%s' version '%s' is already registered with a different schema", name, version)
+		}
+		return nil
+	}
+
+	dataContracts[key] = registeredSchema{text: schema, schema: parsed}
+	return nil
+}
+
+func dataContractKey(name string, version string) string {
+	return name + "@" + version
+}
+
+// DataContractMetrics contains the counts of outputs validated against a data contract
+type DataContractMetrics struct {
+	// Validated is the number of outputs that have been validated
+	Validated uint64
+	// Violations is the number of outputs that did not conform to the data contract
+	Violations uint64
+}
+
+// DataContract houses the data contract an export's output is validated against
+type DataContract struct {
+	name            string
+	version         string
+	schema          *jsonSchema
+	stopOnViolation bool
+	validated       uint64
+	violations      uint64
+}
+
+// NewDataContract creates, initializes and returns a new instance of DataContract for the specified registered
+// data contract name and version. When stopOnViolation is true, output that doesn't conform to the data contract
+// stops the pipeline with an error, otherwise the violation is logged and the pipeline continues.
+// An error is returned if the data contract version has not been registered.
+func NewDataContract(name string, version string, stopOnViolation bool) (*DataContract, error) {
+	dataContractsLock.RLock()
+	registered, found := dataContracts[dataContractKey(name, version)]
+	dataContractsLock.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("data contract '%s' version '%s' is not registered", name, version)
+	}
+
+	return &DataContract{
+		name:            name,
+		version:         version,
+		schema:          registered.schema,
+		stopOnViolation: stopOnViolation,
+	}, nil
+}
+
+// Enforce validates the serialized form of the data received against the data contract's schema so that
+// breaking changes to the output are detected before they reach consumers. The data received is passed
+// through unchanged and the data contract name and version are stored in the context, i.e. for use in
+// an export URL or topic via {datacontractversion}.
+// It will return an error and stop the pipeline if no data is received or, when configured to do so,
+// if the output does not conform to the data contract.
+func (dc *DataContract) Enforce(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Enforce: no data received")
+	}
+
+	serialized, err := util.CoerceType(data)
+	if err != nil {
+		return false, err
+	}
+
+	ctx.LoggingClient().Debugf("Validating output against data contract '%s' version '%s'", dc.name, dc.version)
+
+	atomic.AddUint64(&dc.validated, 1)
+
+	violations := dc.schema.validateBytes(serialized)
+	if len(violations) > 0 {
+		atomic.AddUint64(&dc.violations, 1)
+
+		err := fmt.Errorf(
+			"output does not conform to data contract '%s' version '%s': %s",
+			dc.name,
+			dc.version,
+			strings.Join(violations, "; "))
+
+		if dc.stopOnViolation {
+			return false, err
+		}
+
+		ctx.LoggingClient().Error(err.Error())
+	}
+
+	ctx.AddValue(DataContractName, dc.name)
+	ctx.AddValue(DataContractVersion, dc.version)
+
+	return true, data
+}
+
+// Metrics returns the current counts of outputs validated against the data contract and the violations found
+func (dc *DataContract) Metrics() DataContractMetrics {
+	return DataContractMetrics{
+		Validated:  atomic.LoadUint64(&dc.validated),
+		Violations: atomic.LoadUint64(&dc.violations),
+	}
+}
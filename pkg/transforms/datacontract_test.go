@@ -0,0 +1,177 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDataContractSchema = `{
+	"type": "object",
+	"required": ["deviceName", "readings"],
+	"properties": {
+		"deviceName": {"type": "string", "minLength": 1},
+		"readings": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"required": ["resourceName", "value"],
+				"properties": {
+					"resourceName": {"type": "string", "enum": ["temperature", "humidity"]},
+					"value": {"type": "string", "pattern": "^[0-9]+$"}
+				}
+			}
+		}
+	}
+}`
+
+func TestRegisterDataContract(t *testing.T) {
+	require.NoError(t, RegisterDataContract("register", "v1", testDataContractSchema))
+	// Registering the same schema again is allowed
+	require.NoError(t, RegisterDataContract("register", "v1", testDataContractSchema))
+
+	err := RegisterDataContract("register", "v1", `{"type": "object"}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already registered")
+
+	require.NoError(t, RegisterDataContract("register", "v2", `{"type": "object"}`))
+
+	assert.Error(t, RegisterDataContract("", "v1", testDataContractSchema))
+	assert.Error(t, RegisterDataContract("register", "v3", `{"type": 1}`))
+	assert.Error(t, RegisterDataContract("register", "v3", `{"pattern": "["}`))
+}
+
+func TestNewDataContract(t *testing.T) {
+	require.NoError(t, RegisterDataContract("new", "v1", testDataContractSchema))
+
+	_, err := NewDataContract("new", "v1", true)
+	assert.NoError(t, err)
+
+	_, err = NewDataContract("new", "v2", true)
+	assert.Error(t, err)
+}
+
+func TestDataContract_Enforce(t *testing.T) {
+	require.NoError(t, RegisterDataContract("enforce", "1.0", testDataContractSchema))
+
+	validEvent := dtos.Event{
+		DeviceName: "device1",
+		Readings: []dtos.BaseReading{
+			{ResourceName: "temperature", SimpleReading: dtos.SimpleReading{Value: "72"}},
+		},
+	}
+
+	tests := []struct {
+		Name            string
+		Data            interface{}
+		StopOnViolation bool
+		ExpectContinue  bool
+		ExpectViolation bool
+	}{
+		{"Valid Event", validEvent, true, true, false},
+		{"Valid JSON", `{"deviceName":"device1","readings":[{"resourceName":"humidity","value":"40"}]}`, true, true, false},
+		{"Missing required", []byte(`{"deviceName":"device1"}`), true, false, true},
+		{"Wrong type", `{"deviceName":1,"readings":[{"resourceName":"humidity","value":"40"}]}`, true, false, true},
+		{"Bad enum", `{"deviceName":"d","readings":[{"resourceName":"pressure","value":"40"}]}`, true, false, true},
+		{"Bad pattern", `{"deviceName":"d","readings":[{"resourceName":"humidity","value":"4.0"}]}`, true, false, true},
+		{"Not JSON", "not json", true, false, true},
+		{"Violation continues", `{"deviceName":"device1"}`, false, true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := NewDataContract("enforce", "1.0", test.StopOnViolation)
+			require.NoError(t, err)
+
+			continuePipeline, result := target.Enforce(ctx, test.Data)
+			assert.Equal(t, test.ExpectContinue, continuePipeline, result)
+
+			if test.ExpectContinue {
+				assert.Equal(t, test.Data, result)
+				actualName, _ := ctx.GetValue(DataContractName)
+				actualVersion, _ := ctx.GetValue(DataContractVersion)
+				assert.Equal(t, "enforce", actualName)
+				assert.Equal(t, "1.0", actualVersion)
+			} else {
+				_, ok := result.(error)
+				assert.True(t, ok)
+			}
+
+			metrics := target.Metrics()
+			assert.Equal(t, uint64(1), metrics.Validated)
+			if test.ExpectViolation {
+				assert.Equal(t, uint64(1), metrics.Violations)
+			} else {
+				assert.Equal(t, uint64(0), metrics.Violations)
+			}
+		})
+	}
+}
+
+func TestDataContract_EnforceNoData(t *testing.T) {
+	require.NoError(t, RegisterDataContract("nodata", "v1", testDataContractSchema))
+	target, err := NewDataContract("nodata", "v1", true)
+	require.NoError(t, err)
+
+	continuePipeline, result := target.Enforce(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+}
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema, err := parseJSONSchema(`{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"count": {"type": "integer", "minimum": 0, "maximum": 10},
+			"ratio": {"type": ["number", "null"]},
+			"kind": {"const": "sensor"},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string", "maxLength": 3}}
+		}
+	}`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name       string
+		Data       string
+		Violations int
+	}{
+		{"Valid", `{"count":5,"ratio":null,"kind":"sensor","tags":["a","b"]}`, 0},
+		{"Not integer", `{"count":5.5}`, 1},
+		{"Below minimum", `{"count":-1}`, 1},
+		{"Above maximum", `{"count":11}`, 1},
+		{"Wrong const", `{"kind":"actuator"}`, 1},
+		{"Additional property", `{"other":true}`, 1},
+		{"Too many items", `{"tags":["a","b","c"]}`, 1},
+		{"Item too long", `{"tags":["abcd"]}`, 1},
+		{"Multiple", `{"count":11,"other":true}`, 2},
+		{"Not object", `[]`, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			violations := schema.validateBytes([]byte(test.Data))
+			assert.Len(t, violations, test.Violations, violations)
+		})
+	}
+}
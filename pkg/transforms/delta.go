@@ -0,0 +1,359 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+)
+
+const (
+	// DefaultDeltaBlockSize is the block size used to match data against the baseline when none is specified
+	DefaultDeltaBlockSize = 32
+
+	deltaOpCopy   = byte(0)
+	deltaOpInsert = byte(1)
+)
+
+// DeltaPayload is the document exported by EncodeDelta. When BaselineId is empty Payload holds the full payload,
+// otherwise Delta holds the changes to apply to the previous payload with the BaselineId to re-create it.
+type DeltaPayload struct {
+	// Key is the device name the payload is for, which is empty when the device name is not known
+	Key string `json:"key"`
+	// PayloadId identifies the re-created payload, which becomes the baseline for the next delta
+	PayloadId  string `json:"payloadId"`
+	BaselineId string `json:"baselineId,omitempty"`
+	Delta      []byte `json:"delta,omitempty"`
+	Payload    []byte `json:"payload,omitempty"`
+}
+
+type deltaBaseline struct {
+	id      string
+	payload []byte
+	count   int
+}
+
+// DeltaEncoder houses the previously exported payload per device which the next payload is diffed against
+type DeltaEncoder struct {
+	blockSize           int
+	fullPayloadInterval int
+	baselines           map[string]*deltaBaseline
+	mutex               sync.Mutex
+}
+
+// NewDeltaEncoder creates, initializes and returns a new instance of DeltaEncoder. The block size is the size of the
+// chunks matched against the baseline, which defaults to DefaultDeltaBlockSize when zero. When fullPayloadInterval
+// is greater than zero every Nth payload per device is sent in full, so receivers that missed a payload recover.
+func NewDeltaEncoder(blockSize int, fullPayloadInterval int) (*DeltaEncoder, error) {
+	if blockSize < 0 {
+		return nil, fmt.Errorf("delta block size must not be negative, got %d", blockSize)
+	}
+
+	if fullPayloadInterval < 0 {
+		return nil, fmt.Errorf("delta full payload interval must not be negative, got %d", fullPayloadInterval)
+	}
+
+	if blockSize == 0 {
+		blockSize = DefaultDeltaBlockSize
+	}
+
+	return &DeltaEncoder{
+		blockSize:           blockSize,
+		fullPayloadInterval: fullPayloadInterval,
+		baselines:           make(map[string]*deltaBaseline),
+	}, nil
+}
+
+// EncodeDelta diffs the data received, coerced to []byte, against the previous payload for the same device and
+// passes on the JSON encoded DeltaPayload holding only the changes along with the baseline's identifier. The first
+// payload for a device, and those the delta would not make smaller, are sent in full. The device name is taken
+// from the context. Note the data received becomes the baseline for the next payload even if the export fails,
+// so use a full payload interval when the export may fail and receivers must resynchronize.
+// This function will return an error and stop the pipeline if no data is received or it can not be coerced to []byte.
+func (encoder *DeltaEncoder) EncodeDelta(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("EncodeDelta: no data received")
+	}
+
+	payload, err := util.CoerceType(data)
+	if err != nil {
+		return false, fmt.Errorf("EncodeDelta: %s", err.Error())
+	}
+
+	key, _ := ctx.GetValue(interfaces.DEVICENAME)
+
+	result := DeltaPayload{
+		Key:       key,
+		PayloadId: deltaPayloadId(payload),
+	}
+
+	encoder.mutex.Lock()
+	baseline, exists := encoder.baselines[key]
+	if !exists {
+		baseline = &deltaBaseline{}
+		encoder.baselines[key] = baseline
+	}
+
+	sendFull := !exists || (encoder.fullPayloadInterval > 0 && baseline.count%encoder.fullPayloadInterval == 0)
+	if !sendFull {
+		result.Delta = ComputeDelta(baseline.payload, payload, encoder.blockSize)
+		result.BaselineId = baseline.id
+		if len(result.Delta) >= len(payload) {
+			sendFull = true
+		}
+	}
+
+	baseline.id = result.PayloadId
+	baseline.payload = make([]byte, len(payload))
+	copy(baseline.payload, payload)
+	baseline.count++
+	encoder.mutex.Unlock()
+
+	if sendFull {
+		result.BaselineId = ""
+		result.Delta = nil
+		result.Payload = payload
+		ctx.LoggingClient().Debugf("EncodeDelta: sending full payload of %d bytes for '%s'", len(payload), key)
+	} else {
+		ctx.LoggingClient().Debugf("EncodeDelta: sending delta of %d bytes for payload of %d bytes for '%s'", len(result.Delta), len(payload), key)
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return false, fmt.Errorf("EncodeDelta: unable to marshal delta payload: %s", err.Error())
+	}
+
+	ctx.SetResponseContentType(common.ContentTypeJSON)
+
+	return true, encoded
+}
+
+// DeltaReassembler re-creates the payloads exported by EncodeDelta on the receiving side. It holds the last
+// re-created payload per key which the next delta is applied to.
+type DeltaReassembler struct {
+	baselines map[string]deltaBaseline
+	mutex     sync.Mutex
+}
+
+// NewDeltaReassembler creates, initializes and returns a new instance of DeltaReassembler
+func NewDeltaReassembler() *DeltaReassembler {
+	return &DeltaReassembler{
+		baselines: make(map[string]deltaBaseline),
+	}
+}
+
+// Reassemble decodes the JSON encoded DeltaPayload received and returns the full payload it represents.
+// An error is returned if the delta's baseline is not the last payload re-created for its key, in which case
+// the next full payload must be waited for, or if the re-created payload doesn't match its identifier.
+func (reassembler *DeltaReassembler) Reassemble(data []byte) ([]byte, error) {
+	var received DeltaPayload
+	if err := json.Unmarshal(data, &received); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal delta payload: %s", err.Error())
+	}
+
+	reassembler.mutex.Lock()
+	defer reassembler.mutex.Unlock()
+
+	payload := received.Payload
+	if len(received.BaselineId) > 0 {
+		baseline, exists := reassembler.baselines[received.Key]
+		if !exists || baseline.id != received.BaselineId {
+			return nil, fmt.Errorf("baseline '%s' for '%s' is not available", received.BaselineId, received.Key)
+		}
+
+		var err error
+		payload, err = ApplyDelta(baseline.payload, received.Delta)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if deltaPayloadId(payload) != received.PayloadId {
+		return nil, fmt.Errorf("re-created payload for '%s' does not match payload id '%s'", received.Key, received.PayloadId)
+	}
+
+	reassembler.baselines[received.Key] = deltaBaseline{id: received.PayloadId, payload: payload}
+
+	return payload, nil
+}
+
+// ComputeDelta returns the instructions to re-create target from baseline. Blocks of baseline of the specified size
+// found in target are copied from baseline, everything else is inserted from target.
+func ComputeDelta(baseline []byte, target []byte, blockSize int) []byte {
+	if blockSize <= 0 {
+		blockSize = DefaultDeltaBlockSize
+	}
+
+	index := make(map[uint32][]int)
+	for offset := 0; offset+blockSize <= len(baseline); offset += blockSize {
+		digest := newRollingChecksum(baseline[offset : offset+blockSize]).digest()
+		index[digest] = append(index[digest], offset)
+	}
+
+	var delta []byte
+	literalStart := 0
+	position := 0
+	var checksum rollingChecksum
+	if len(target) >= blockSize {
+		checksum = newRollingChecksum(target[:blockSize])
+	}
+
+	for position+blockSize <= len(target) {
+		if offset, found := matchBlock(index[checksum.digest()], baseline, target[position:position+blockSize]); found {
+			length := blockSize
+			for position+length < len(target) && offset+length < len(baseline) &&
+				target[position+length] == baseline[offset+length] {
+				length++
+			}
+
+			delta = appendInsert(delta, target[literalStart:position])
+			delta = appendCopy(delta, offset, length)
+
+			position += length
+			literalStart = position
+			if position+blockSize <= len(target) {
+				checksum = newRollingChecksum(target[position : position+blockSize])
+			}
+			continue
+		}
+
+		if position+blockSize < len(target) {
+			checksum.roll(target[position], target[position+blockSize])
+		}
+		position++
+	}
+
+	return appendInsert(delta, target[literalStart:])
+}
+
+// ApplyDelta re-creates the target payload by applying the delta returned by ComputeDelta to baseline
+func ApplyDelta(baseline []byte, delta []byte) ([]byte, error) {
+	result := make([]byte, 0, len(baseline))
+
+	for position := 0; position < len(delta); {
+		op := delta[position]
+		position++
+
+		switch op {
+		case deltaOpCopy:
+			offset, read := binary.Uvarint(delta[position:])
+			if read <= 0 {
+				return nil, errors.New("invalid delta: malformed copy offset")
+			}
+			position += read
+
+			length, read := binary.Uvarint(delta[position:])
+			if read <= 0 {
+				return nil, errors.New("invalid delta: malformed copy length")
+			}
+			position += read
+
+			if offset > uint64(len(baseline)) || length > uint64(len(baseline))-offset {
+				return nil, fmt.Errorf("invalid delta: copy of %d bytes at %d is outside the baseline of %d bytes", length, offset, len(baseline))
+			}
+			result = append(result, baseline[offset:offset+length]...)
+
+		case deltaOpInsert:
+			length, read := binary.Uvarint(delta[position:])
+			if read <= 0 {
+				return nil, errors.New("invalid delta: malformed insert length")
+			}
+			position += read
+
+			if length > uint64(len(delta)-position) {
+				return nil, fmt.Errorf("invalid delta: insert of %d bytes exceeds the delta", length)
+			}
+			result = append(result, delta[position:position+int(length)]...)
+			position += int(length)
+
+		default:
+			return nil, fmt.Errorf("invalid delta: unknown operation %d", op)
+		}
+	}
+
+	return result, nil
+}
+
+func matchBlock(candidates []int, baseline []byte, block []byte) (int, bool) {
+	for _, offset := range candidates {
+		if string(baseline[offset:offset+len(block)]) == string(block) {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+func appendCopy(delta []byte, offset int, length int) []byte {
+	delta = append(delta, deltaOpCopy)
+	delta = appendUvarint(delta, uint64(offset))
+	return appendUvarint(delta, uint64(length))
+}
+
+func appendInsert(delta []byte, literal []byte) []byte {
+	if len(literal) == 0 {
+		return delta
+	}
+	delta = append(delta, deltaOpInsert)
+	delta = appendUvarint(delta, uint64(len(literal)))
+	return append(delta, literal...)
+}
+
+func appendUvarint(data []byte, value uint64) []byte {
+	var buffer [binary.MaxVarintLen64]byte
+	length := binary.PutUvarint(buffer[:], value)
+	return append(data, buffer[:length]...)
+}
+
+func deltaPayloadId(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:16])
+}
+
+// rollingChecksum is the rsync weak checksum which can be moved along the data one byte at a time
+type rollingChecksum struct {
+	a    uint32
+	b    uint32
+	size uint32
+}
+
+func newRollingChecksum(block []byte) rollingChecksum {
+	checksum := rollingChecksum{size: uint32(len(block))}
+	for index, value := range block {
+		checksum.a += uint32(value)
+		checksum.b += uint32(len(block)-index) * uint32(value)
+	}
+	return checksum
+}
+
+func (checksum *rollingChecksum) roll(out byte, in byte) {
+	checksum.a = checksum.a - uint32(out) + uint32(in)
+	checksum.b = checksum.b - checksum.size*uint32(out) + checksum.a
+}
+
+func (checksum rollingChecksum) digest() uint32 {
+	return checksum.b<<16 | checksum.a&0xffff
+}
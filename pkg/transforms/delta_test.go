@@ -0,0 +1,220 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDeltaTestPayload(size int, seed int64) []byte {
+	payload := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(payload)
+	return payload
+}
+
+func encodeTestDelta(t *testing.T, encoder *DeltaEncoder, deviceName string, payload []byte) ([]byte, DeltaPayload) {
+	ctx.AddValue(interfaces.DEVICENAME, deviceName)
+	defer ctx.RemoveValue(interfaces.DEVICENAME)
+
+	continuePipeline, result := encoder.EncodeDelta(ctx, payload)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	encoded, ok := result.([]byte)
+	require.True(t, ok)
+	assert.Equal(t, common.ContentTypeJSON, ctx.ResponseContentType())
+
+	var decoded DeltaPayload
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	return encoded, decoded
+}
+
+func TestNewDeltaEncoder(t *testing.T) {
+	encoder, err := NewDeltaEncoder(0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultDeltaBlockSize, encoder.blockSize)
+
+	_, err = NewDeltaEncoder(-1, 0)
+	assert.Error(t, err)
+
+	_, err = NewDeltaEncoder(16, -1)
+	assert.Error(t, err)
+}
+
+func TestComputeAndApplyDelta(t *testing.T) {
+	baseline := newDeltaTestPayload(4096, 1)
+
+	modified := append([]byte{}, baseline...)
+	copy(modified[1000:], []byte("changed"))
+
+	inserted := append([]byte("header"), baseline[:2048]...)
+	inserted = append(inserted, []byte("middle")...)
+	inserted = append(inserted, baseline[2048:]...)
+
+	tests := []struct {
+		Name     string
+		Baseline []byte
+		Target   []byte
+		MaxDelta int
+	}{
+		{"Identical", baseline, baseline, 16},
+		{"Bytes changed", baseline, modified, 128},
+		{"Bytes inserted", baseline, inserted, 128},
+		{"Truncated", baseline, baseline[:3000], 16},
+		{"Unrelated", baseline, newDeltaTestPayload(512, 2), 520},
+		{"Empty baseline", nil, baseline[:100], 110},
+		{"Empty target", baseline, []byte{}, 0},
+		{"Shorter than block", baseline, baseline[:10], 16},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			delta := ComputeDelta(test.Baseline, test.Target, 32)
+			assert.LessOrEqual(t, len(delta), test.MaxDelta)
+
+			actual, err := ApplyDelta(test.Baseline, delta)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(test.Target, actual))
+		})
+	}
+}
+
+func TestApplyDeltaInvalid(t *testing.T) {
+	baseline := []byte("baseline")
+
+	tests := []struct {
+		Name  string
+		Delta []byte
+	}{
+		{"Unknown operation", []byte{9}},
+		{"Copy outside baseline", appendCopy(nil, 4, 10)},
+		{"Truncated copy", []byte{deltaOpCopy}},
+		{"Insert exceeds delta", []byte{deltaOpInsert, 10, 'a'}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := ApplyDelta(baseline, test.Delta)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEncodeDeltaAndReassemble(t *testing.T) {
+	encoder, err := NewDeltaEncoder(0, 0)
+	require.NoError(t, err)
+	reassembler := NewDeltaReassembler()
+
+	first := newDeltaTestPayload(8192, 1)
+	second := append([]byte{}, first...)
+	copy(second[4000:], []byte("updated configuration"))
+	third := append(second[:6000:6000], []byte("appended")...)
+	other := newDeltaTestPayload(1024, 3)
+
+	steps := []struct {
+		DeviceName string
+		Payload    []byte
+		ExpectFull bool
+	}{
+		{"camera1", first, true},
+		{"camera1", second, false},
+		{"camera2", other, true},
+		{"camera1", third, false},
+		{"camera2", other, false},
+	}
+
+	for _, step := range steps {
+		encoded, decoded := encodeTestDelta(t, encoder, step.DeviceName, step.Payload)
+		assert.Equal(t, step.DeviceName, decoded.Key)
+
+		if step.ExpectFull {
+			assert.Empty(t, decoded.BaselineId)
+			assert.Equal(t, step.Payload, decoded.Payload)
+		} else {
+			assert.NotEmpty(t, decoded.BaselineId)
+			assert.Empty(t, decoded.Payload)
+			assert.Less(t, len(decoded.Delta), len(step.Payload)/10)
+		}
+
+		actual, err := reassembler.Reassemble(encoded)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(step.Payload, actual))
+	}
+}
+
+func TestEncodeDeltaFullPayload(t *testing.T) {
+	encoder, err := NewDeltaEncoder(0, 3)
+	require.NoError(t, err)
+
+	payload := newDeltaTestPayload(2048, 1)
+	var full []bool
+	for index := 0; index < 7; index++ {
+		_, decoded := encodeTestDelta(t, encoder, "camera1", payload)
+		full = append(full, len(decoded.BaselineId) == 0)
+	}
+	assert.Equal(t, []bool{true, false, false, true, false, false, true}, full)
+
+	// A delta which is not smaller than the payload is sent in full
+	_, decoded := encodeTestDelta(t, encoder, "camera1", newDeltaTestPayload(64, 2))
+	assert.Empty(t, decoded.BaselineId)
+}
+
+func TestReassembleErrors(t *testing.T) {
+	encoder, err := NewDeltaEncoder(0, 0)
+	require.NoError(t, err)
+
+	payload := newDeltaTestPayload(2048, 1)
+	_, _ = encodeTestDelta(t, encoder, "camera1", payload)
+	delta, _ := encodeTestDelta(t, encoder, "camera1", payload)
+
+	corrupted := DeltaPayload{Key: "camera1", PayloadId: "bogus", Payload: payload}
+	corruptedPayload, err := json.Marshal(corrupted)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name string
+		Data []byte
+	}{
+		{"Not a delta payload", []byte("bogus")},
+		{"Missing baseline", delta},
+		{"Payload id mismatch", corruptedPayload},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := NewDeltaReassembler().Reassemble(test.Data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEncodeDeltaNoData(t *testing.T) {
+	encoder, err := NewDeltaEncoder(0, 0)
+	require.NoError(t, err)
+
+	continuePipeline, result := encoder.EncodeDelta(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+}
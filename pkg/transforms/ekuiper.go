@@ -0,0 +1,317 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/secure"
+)
+
+const (
+	defaultEKuiperCorrelationField = "id"
+	defaultEKuiperTimeout          = 5 * time.Second
+)
+
+// EKuiperConfig contains the settings for forwarding Events to an eKuiper rules engine instance
+type EKuiperConfig struct {
+	// IngestURL is the URL of the eKuiper REST (httppush) source the Events are posted to,
+	// i.e. http://ekuiper:10081/edgex. When not set the Events are published to IngestTopic.
+	IngestURL string
+	// BrokerAddress is the MQTT broker, i.e. tcp://mqtt-broker:1883, used to publish the Events to IngestTopic
+	// and to receive the rule results from ResultTopic
+	BrokerAddress string
+	// ClientId to connect with the broker with
+	ClientId string
+	// AuthMode indicates what to use when connecting to the broker. Options are "none", "cacert" , "usernamepassword", "clientcert".
+	AuthMode string
+	// SecretPath is the path in the secret provider of the broker's credentials
+	SecretPath string
+	// IngestTopic is the topic of the eKuiper MQTT source the Events are published to
+	IngestTopic string
+	// ResultTopic, when set, is the topic the rule's MQTT sink publishes its results to. The pipeline then waits
+	// for the rule result for each Event and continues with it.
+	ResultTopic string
+	// CorrelationField is the field of the rule results which holds the Id of the Event the result is for.
+	// The rule must select it, i.e. SELECT id, ... Defaults to "id".
+	CorrelationField string
+	// Timeout is how long to wait for the ingest request and the rule result. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// EKuiper forwards Events to an eKuiper rules engine instance and optionally waits for the rule result
+type EKuiper struct {
+	config     EKuiperConfig
+	httpClient *http.Client
+	opts       *MQTT.ClientOptions
+	lock       sync.Mutex
+	client     MQTT.Client
+	// pending holds the channels of the Events waiting for their rule result, keyed by Event Id
+	pending     map[string]chan []byte
+	pendingLock sync.Mutex
+}
+
+// NewEKuiper creates, initializes and returns a new instance of EKuiper. Either the IngestURL or the
+// BrokerAddress and IngestTopic must be set, and the BrokerAddress must be set when a ResultTopic is set.
+func NewEKuiper(config EKuiperConfig) (*EKuiper, error) {
+	useMQTT := len(config.IngestURL) == 0
+	if useMQTT && (len(config.BrokerAddress) == 0 || len(config.IngestTopic) == 0) {
+		return nil, errors.New("eKuiper ingest URL or broker address and ingest topic must be specified")
+	}
+
+	if len(config.ResultTopic) > 0 && len(config.BrokerAddress) == 0 {
+		return nil, errors.New("eKuiper broker address must be specified to receive the rule results")
+	}
+
+	if config.Timeout < 0 {
+		return nil, fmt.Errorf("eKuiper timeout must not be negative, got %s", config.Timeout.String())
+	}
+
+	if len(config.CorrelationField) == 0 {
+		config.CorrelationField = defaultEKuiperCorrelationField
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = defaultEKuiperTimeout
+	}
+
+	ekuiper := &EKuiper{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		pending:    make(map[string]chan []byte),
+	}
+
+	if useMQTT || len(config.ResultTopic) > 0 {
+		ekuiper.opts = MQTT.NewClientOptions()
+		ekuiper.opts.AddBroker(config.BrokerAddress)
+		ekuiper.opts.SetClientID(config.ClientId)
+		ekuiper.opts.SetAutoReconnect(true)
+		ekuiper.opts.SetConnectTimeout(config.Timeout)
+	}
+
+	return ekuiper, nil
+}
+
+// Forward sends the Event received to eKuiper. When a ResultTopic is configured the pipeline continues with the
+// JSON encoded rule result for the Event, or is stopped if there is no result within the Timeout, i.e. the
+// rule's WHERE clause didn't match the Event. Otherwise the pipeline continues with the Event. Sending the Event and
+// waiting for the rule result also stop when the pipeline's deadline elapses, which returns an error.
+// This function will return an error and stop the pipeline if a non-edgex event is received, if no data is
+// received or if the Event can not be sent to eKuiper.
+func (ekuiper *EKuiper) Forward(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Forward: no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("Forward: type received is not an Event")
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return false, fmt.Errorf("Forward: unable to marshal Event: %s", err.Error())
+	}
+
+	if ekuiper.opts != nil {
+		if err := ekuiper.connect(ctx); err != nil {
+			return false, fmt.Errorf("Forward: unable to connect to MQTT broker for eKuiper: %s", err.Error())
+		}
+	}
+
+	var result chan []byte
+	if len(ekuiper.config.ResultTopic) > 0 {
+		result = ekuiper.addPending(event.Id)
+		defer ekuiper.removePending(event.Id)
+	}
+
+	if len(ekuiper.config.IngestURL) > 0 {
+		err = ekuiper.post(ctx.Context(), payload)
+	} else {
+		err = ekuiper.publish(ctx.Context(), payload)
+	}
+	if err != nil {
+		return false, fmt.Errorf("Forward: unable to send Event to eKuiper: %s", err.Error())
+	}
+
+	ctx.LoggingClient().Debugf("Forwarded Event '%s' to eKuiper", event.Id)
+
+	if result == nil {
+		return true, event
+	}
+
+	select {
+	case ruleResult := <-result:
+		ctx.SetResponseContentType(common.ContentTypeJSON)
+		return true, ruleResult
+
+	case <-time.After(ekuiper.config.Timeout):
+		ctx.LoggingClient().Debugf("No eKuiper rule result received for Event '%s', stopping pipeline", event.Id)
+		return false, nil
+
+	case <-ctx.Context().Done():
+		return false, fmt.Errorf("Forward: no eKuiper rule result received for Event '%s': %w", event.Id, ctx.Context().Err())
+	}
+}
+
+func (ekuiper *EKuiper) post(ctx context.Context, payload []byte) error {
+	requestCtx, cancel := context.WithTimeout(ctx, ekuiper.config.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(requestCtx, http.MethodPost, ekuiper.config.IngestURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set(common.ContentType, common.ContentTypeJSON)
+
+	response, err := ekuiper.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		body, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("eKuiper ingest returned status %d: %s", response.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+func (ekuiper *EKuiper) publish(ctx context.Context, payload []byte) error {
+	publishCtx, cancel := context.WithTimeout(ctx, ekuiper.config.Timeout)
+	defer cancel()
+
+	return waitForToken(publishCtx, ekuiper.client.Publish(ekuiper.config.IngestTopic, 0, false, payload))
+}
+
+func (ekuiper *EKuiper) connect(ctx interfaces.AppFunctionContext) error {
+	ekuiper.lock.Lock()
+	defer ekuiper.lock.Unlock()
+
+	if ekuiper.client == nil {
+		if len(ekuiper.config.ResultTopic) > 0 {
+			ekuiper.opts.SetOnConnectHandler(ekuiper.onConnected(ctx.LoggingClient()))
+		}
+
+		mqttFactory := secure.NewMqttFactory(ctx, ekuiper.config.AuthMode, ekuiper.config.SecretPath, false)
+		client, err := mqttFactory.Create(ekuiper.opts)
+		if err != nil {
+			return err
+		}
+		ekuiper.client = client
+	}
+
+	if ekuiper.client.IsConnected() {
+		return nil
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx.Context(), ekuiper.config.Timeout)
+	defer cancel()
+
+	return waitForToken(connectCtx, ekuiper.client.Connect())
+}
+
+// onConnected returns the handler which subscribes to the rule results each time the client connects,
+// including when it automatically reconnects
+func (ekuiper *EKuiper) onConnected(lc logger.LoggingClient) MQTT.OnConnectHandler {
+	return func(client MQTT.Client) {
+		token := client.Subscribe(ekuiper.config.ResultTopic, 0, func(_ MQTT.Client, message MQTT.Message) {
+			ekuiper.receiveResult(lc, message.Payload())
+		})
+		token.Wait()
+		if token.Error() != nil {
+			lc.Errorf("Unable to subscribe to '%s' topic for eKuiper rule results: %s", ekuiper.config.ResultTopic, token.Error().Error())
+			return
+		}
+
+		lc.Debugf("Subscribed to '%s' topic for eKuiper rule results", ekuiper.config.ResultTopic)
+	}
+}
+
+// receiveResult passes the rule results to the Events waiting for them. eKuiper sinks publish either a single
+// result or, by default, an array of results.
+func (ekuiper *EKuiper) receiveResult(lc logger.LoggingClient, payload []byte) {
+	var results []map[string]interface{}
+	if err := json.Unmarshal(payload, &results); err != nil {
+		var result map[string]interface{}
+		if err := json.Unmarshal(payload, &result); err != nil {
+			lc.Warnf("Unable to unmarshal eKuiper rule result: %s", err.Error())
+			return
+		}
+		results = append(results, result)
+	}
+
+	for _, result := range results {
+		correlation, found := result[ekuiper.config.CorrelationField]
+		if !found {
+			lc.Warnf("eKuiper rule result does not contain the '%s' correlation field", ekuiper.config.CorrelationField)
+			continue
+		}
+
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			lc.Warnf("Unable to marshal eKuiper rule result: %s", err.Error())
+			continue
+		}
+
+		ekuiper.pendingLock.Lock()
+		waiting, found := ekuiper.pending[fmt.Sprint(correlation)]
+		ekuiper.pendingLock.Unlock()
+
+		if !found {
+			lc.Debugf("Ignoring eKuiper rule result for '%v' which isn't waited for", correlation)
+			continue
+		}
+
+		// Only the first result is used when the rule produces several for an Event
+		select {
+		case waiting <- encoded:
+		default:
+		}
+	}
+}
+
+func (ekuiper *EKuiper) addPending(id string) chan []byte {
+	ekuiper.pendingLock.Lock()
+	defer ekuiper.pendingLock.Unlock()
+
+	result := make(chan []byte, 1)
+	ekuiper.pending[id] = result
+	return result
+}
+
+func (ekuiper *EKuiper) removePending(id string) {
+	ekuiper.pendingLock.Lock()
+	defer ekuiper.pendingLock.Unlock()
+
+	delete(ekuiper.pending, id)
+}
@@ -0,0 +1,253 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMessage is a received MQTT message
+type fakeMessage struct {
+	MQTT.Message
+	payload []byte
+}
+
+func (message *fakeMessage) Payload() []byte {
+	return message.payload
+}
+
+// fakeRulesEngine is a connected MQTT client which simulates an eKuiper rule by publishing the result
+// for each Event published to it
+type fakeRulesEngine struct {
+	MQTT.Client
+	handler   MQTT.MessageHandler
+	published [][]byte
+	rule      func(event dtos.Event) []byte
+}
+
+func (engine *fakeRulesEngine) IsConnected() bool {
+	return true
+}
+
+func (engine *fakeRulesEngine) Subscribe(_ string, _ byte, handler MQTT.MessageHandler) MQTT.Token {
+	engine.handler = handler
+	return &fakeToken{}
+}
+
+func (engine *fakeRulesEngine) Publish(_ string, _ byte, _ bool, payload interface{}) MQTT.Token {
+	data := payload.([]byte)
+	engine.published = append(engine.published, data)
+	engine.process(data)
+	return &fakeToken{}
+}
+
+func (engine *fakeRulesEngine) process(data []byte) {
+	var event dtos.Event
+	if err := json.Unmarshal(data, &event); err != nil || engine.rule == nil || engine.handler == nil {
+		return
+	}
+
+	if result := engine.rule(event); result != nil {
+		go engine.handler(engine, &fakeMessage{payload: result})
+	}
+}
+
+func newEKuiperTestEvent(value string) dtos.Event {
+	event := dtos.NewEvent("profile", "device", "temperature")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeString, value)
+	return event
+}
+
+// averageRule returns the result eKuiper's MQTT sink publishes for the rule "SELECT id, deviceName, readings[0]->value AS value"
+func averageRule(event dtos.Event) []byte {
+	if event.Readings[0].Value == "filtered" {
+		return nil
+	}
+	return []byte(fmt.Sprintf(`[{"id":"%s","deviceName":"%s","value":"%s"}]`, event.Id, event.DeviceName, event.Readings[0].Value))
+}
+
+func TestNewEKuiper(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Config      EKuiperConfig
+		ExpectMQTT  bool
+		ExpectError bool
+	}{
+		{"REST", EKuiperConfig{IngestURL: "http://ekuiper:10081/edgex"}, false, false},
+		{"REST with results", EKuiperConfig{IngestURL: "http://ekuiper:10081/edgex", BrokerAddress: "tcp://broker:1883", ResultTopic: "result"}, true, false},
+		{"MQTT", EKuiperConfig{BrokerAddress: "tcp://broker:1883", IngestTopic: "events"}, true, false},
+		{"Nothing to send to", EKuiperConfig{}, false, true},
+		{"MQTT missing topic", EKuiperConfig{BrokerAddress: "tcp://broker:1883"}, false, true},
+		{"Results missing broker", EKuiperConfig{IngestURL: "http://ekuiper:10081/edgex", ResultTopic: "result"}, false, true},
+		{"Negative timeout", EKuiperConfig{IngestURL: "http://ekuiper:10081/edgex", Timeout: -time.Second}, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := NewEKuiper(test.Config)
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectMQTT, target.opts != nil)
+			assert.Equal(t, defaultEKuiperCorrelationField, target.config.CorrelationField)
+			assert.Equal(t, defaultEKuiperTimeout, target.config.Timeout)
+		})
+	}
+}
+
+func TestEKuiperForwardREST(t *testing.T) {
+	var received []dtos.Event
+	handler := func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		if request.URL.Path == "/bogus" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var event dtos.Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, common.ContentTypeJSON, request.Header.Get(common.ContentType))
+		received = append(received, event)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	target, err := NewEKuiper(EKuiperConfig{IngestURL: server.URL + "/edgex"})
+	require.NoError(t, err)
+
+	event := newEKuiperTestEvent("21")
+	continuePipeline, result := target.Forward(ctx, event)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Equal(t, event, result)
+	require.Len(t, received, 1)
+	assert.Equal(t, event.Id, received[0].Id)
+
+	target, err = NewEKuiper(EKuiperConfig{IngestURL: server.URL + "/bogus"})
+	require.NoError(t, err)
+
+	continuePipeline, result = target.Forward(ctx, event)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "status 404")
+}
+
+func TestEKuiperForwardWithResult(t *testing.T) {
+	engine := &fakeRulesEngine{rule: averageRule}
+
+	target, err := NewEKuiper(EKuiperConfig{
+		BrokerAddress: "tcp://broker:1883",
+		IngestTopic:   "events",
+		ResultTopic:   "result",
+		Timeout:       200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	target.client = engine
+	target.onConnected(lc)(engine)
+
+	ctx.SetResponseContentType("")
+	event := newEKuiperTestEvent("21")
+	continuePipeline, result := target.Forward(ctx, event)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.JSONEq(t, fmt.Sprintf(`{"id":"%s","deviceName":"device","value":"21"}`, event.Id), string(result.([]byte)))
+	assert.Equal(t, common.ContentTypeJSON, ctx.ResponseContentType())
+	assert.Len(t, engine.published, 1)
+
+	// The rule's WHERE clause doesn't match so there is no result
+	continuePipeline, result = target.Forward(ctx, newEKuiperTestEvent("filtered"))
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+	assert.Empty(t, target.pending, "pending results must be removed")
+
+	ctx.SetResponseContentType("")
+}
+
+func TestEKuiperForwardDeadline(t *testing.T) {
+	engine := &fakeRulesEngine{rule: averageRule}
+
+	target, err := NewEKuiper(EKuiperConfig{
+		BrokerAddress: "tcp://broker:1883",
+		IngestTopic:   "events",
+		ResultTopic:   "result",
+	})
+	require.NoError(t, err)
+	target.client = engine
+	target.onConnected(lc)(engine)
+
+	// The pipeline deadline elapses before the default timeout for the rule result
+	timeout, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.SetContext(timeout)
+
+	continuePipeline, result := target.Forward(appContext, newEKuiperTestEvent("filtered"))
+	require.False(t, continuePipeline)
+	err, ok := result.(error)
+	require.True(t, ok)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Empty(t, target.pending, "pending results must be removed")
+}
+
+func TestEKuiperReceiveResult(t *testing.T) {
+	target, err := NewEKuiper(EKuiperConfig{IngestURL: "http://ekuiper", BrokerAddress: "tcp://broker:1883", ResultTopic: "result", CorrelationField: "eventId"})
+	require.NoError(t, err)
+
+	first := target.addPending("1")
+	second := target.addPending("2")
+
+	target.receiveResult(lc, []byte(`bogus`))
+	target.receiveResult(lc, []byte(`{"value":1}`))
+	target.receiveResult(lc, []byte(`{"eventId":"3","value":3}`))
+	target.receiveResult(lc, []byte(`{"eventId":"1","value":1}`))
+	target.receiveResult(lc, []byte(`[{"eventId":"2","value":2},{"eventId":"2","value":22}]`))
+
+	assert.JSONEq(t, `{"eventId":"1","value":1}`, string(<-first))
+	assert.JSONEq(t, `{"eventId":"2","value":2}`, string(<-second))
+	assert.Len(t, second, 0, "only the first result is used")
+}
+
+func TestEKuiperForwardNoEvent(t *testing.T) {
+	target, err := NewEKuiper(EKuiperConfig{IngestURL: "http://ekuiper"})
+	require.NoError(t, err)
+
+	continuePipeline, result := target.Forward(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+
+	continuePipeline, result = target.Forward(ctx, "bogus")
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+}
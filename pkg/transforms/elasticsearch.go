@@ -0,0 +1,460 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+const (
+	// ElasticsearchAuthModeNone sends requests without authentication
+	ElasticsearchAuthModeNone = "none"
+	// ElasticsearchAuthModeBasic authenticates with the "username" and "password" secrets
+	ElasticsearchAuthModeBasic = "basic"
+	// ElasticsearchAuthModeAPIKey authenticates with the base64 encoded "apikey" secret
+	ElasticsearchAuthModeAPIKey = "apikey"
+
+	// DefaultElasticsearchIndex is the default index name, which is per device profile and day
+	DefaultElasticsearchIndex = "edgex-{profilename}-{date}"
+	// ElasticsearchDatePlaceholder is replaced in the index name with the date of the Event, or the current date for
+	// documents which aren't Events, in the service's Timezone, UTC by default, formatted as yyyy.MM.dd
+	ElasticsearchDatePlaceholder = "{date}"
+	// ContentTypeNDJSON is the content type of Bulk API requests
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	elasticsearchBulkPath         = "/_bulk"
+	elasticsearchDateFormat       = "2006.01.02"
+	defaultElasticsearchTimeout   = 30 * time.Second
+	defaultElasticsearchRetries   = 3
+	defaultElasticsearchBackoff   = time.Second
+	maxElasticsearchBackoff       = 30 * time.Second
+	maxElasticsearchErrorBodySize = 512
+)
+
+// ElasticsearchConfig contains the settings for bulk indexing documents in Elasticsearch or OpenSearch
+type ElasticsearchConfig struct {
+	// URL is the base URL of the cluster, i.e. https://elasticsearch:9200
+	URL string
+	// Index is the name of the index documents are added to, which may contain {devicename}, {profilename},
+	// {sourcename}, {date} and other context value placeholders. Index names are lowercased. Defaults to
+	// DefaultElasticsearchIndex.
+	Index string
+	// AuthMode is how to authenticate with the cluster using the secrets at SecretPath. Options are "none" (default),
+	// "basic" and "apikey".
+	AuthMode string
+	// SecretPath to search for the "username" and "password" secrets, or the "apikey" secret
+	SecretPath string
+	// SkipCertVerify indicates whether to skip verification of the cluster's TLS certificate
+	SkipCertVerify bool
+	// Timeout is the duration to wait for each Bulk API request. Defaults to 30s.
+	Timeout string
+	// MaxRetries is the number of times documents rejected with 429 Too Many Requests are retried. Defaults to 3.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry, which doubles with each retry up to 30s unless the cluster
+	// specifies the delay with a Retry-After header. Defaults to 1s.
+	InitialBackoff string
+	// PersistOnError enables use of store & forward loop if true
+	PersistOnError bool
+}
+
+// ElasticsearchSender indexes documents in Elasticsearch or OpenSearch using the Bulk API
+type ElasticsearchSender struct {
+	config         ElasticsearchConfig
+	client         *http.Client
+	initialBackoff time.Duration
+}
+
+// elasticsearchDocument is a document and the index it is added to
+type elasticsearchDocument struct {
+	index  string
+	source []byte
+}
+
+// elasticsearchBulkResponse is the result of a Bulk API request, with the result of each action in request order
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// NewElasticsearchSender creates, initializes and returns a new instance of ElasticsearchSender
+func NewElasticsearchSender(config ElasticsearchConfig) (*ElasticsearchSender, error) {
+	if len(config.URL) == 0 {
+		return nil, errors.New("Elasticsearch URL must be specified")
+	}
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("Elasticsearch URL is invalid: %s", err.Error())
+	}
+
+	config.AuthMode = strings.ToLower(strings.TrimSpace(config.AuthMode))
+	switch config.AuthMode {
+	case "":
+		config.AuthMode = ElasticsearchAuthModeNone
+	case ElasticsearchAuthModeNone:
+	case ElasticsearchAuthModeBasic, ElasticsearchAuthModeAPIKey:
+		if len(config.SecretPath) == 0 {
+			return nil, fmt.Errorf("Elasticsearch SecretPath must be specified for '%s' AuthMode", config.AuthMode)
+		}
+	default:
+		return nil, fmt.Errorf("invalid Elasticsearch AuthMode '%s'. Must be '%s', '%s' or '%s'",
+			config.AuthMode,
+			ElasticsearchAuthModeNone,
+			ElasticsearchAuthModeBasic,
+			ElasticsearchAuthModeAPIKey)
+	}
+
+	timeout := defaultElasticsearchTimeout
+	if len(config.Timeout) > 0 {
+		var err error
+		timeout, err = time.ParseDuration(config.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("Elasticsearch Timeout '%s' is invalid: %s", config.Timeout, err.Error())
+		}
+	}
+
+	initialBackoff := defaultElasticsearchBackoff
+	if len(config.InitialBackoff) > 0 {
+		var err error
+		initialBackoff, err = time.ParseDuration(config.InitialBackoff)
+		if err != nil {
+			return nil, fmt.Errorf("Elasticsearch InitialBackoff '%s' is invalid: %s", config.InitialBackoff, err.Error())
+		}
+	}
+
+	if config.MaxRetries < 0 {
+		return nil, fmt.Errorf("Elasticsearch MaxRetries %d is invalid. Must not be negative", config.MaxRetries)
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultElasticsearchRetries
+	}
+
+	if len(config.Index) == 0 {
+		config.Index = DefaultElasticsearchIndex
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+
+	return &ElasticsearchSender{
+		config: config,
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.SkipCertVerify},
+			},
+		},
+		initialBackoff: initialBackoff,
+	}, nil
+}
+
+// BulkIndex adds the documents received to their index using the Bulk API. The data received is an Event, the
+// batch of documents from the Batch functions, a JSON document or, when retried from Store and Forward, a JSON
+// array of documents. Requests and documents rejected with 429 Too Many Requests are retried with backoff.
+// When PersistOnError is set, the documents which couldn't be indexed are stored for retry.
+// This function will return an error and stop the pipeline if no data is received, if any of the documents
+// aren't JSON objects or if any of the documents can not be indexed. Otherwise the data received is returned so
+// that further export functions can be chained.
+func (sender *ElasticsearchSender) BulkIndex(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("BulkIndex: No Data Received")
+	}
+
+	sources, err := toElasticsearchSources(data)
+	if err != nil {
+		return false, fmt.Errorf("BulkIndex: %s", err.Error())
+	}
+
+	documents := make([]elasticsearchDocument, len(sources))
+	for index, source := range sources {
+		documents[index].source = source
+		documents[index].index, err = sender.indexName(ctx, source)
+		if err != nil {
+			return false, fmt.Errorf("BulkIndex: unable to format index name: %s", err.Error())
+		}
+	}
+
+	failed, err := sender.bulk(ctx, documents)
+	if err != nil {
+		sender.setRetryData(ctx, failed)
+		return false, fmt.Errorf("BulkIndex: %s", err.Error())
+	}
+
+	ctx.LoggingClient().Debugf("Indexed %d documents in Elasticsearch", len(documents))
+	ctx.LoggingClient().Trace("Data exported", "Transport", "Elasticsearch", common.CorrelationHeader, ctx.CorrelationID())
+
+	return true, data
+}
+
+// bulk sends the documents, retrying those rejected with 429 Too Many Requests, and returns the documents which
+// couldn't be indexed
+func (sender *ElasticsearchSender) bulk(ctx interfaces.AppFunctionContext, documents []elasticsearchDocument) ([]elasticsearchDocument, error) {
+	lc := ctx.LoggingClient()
+	pending := documents
+	var failed []elasticsearchDocument
+	var reasons []string
+
+	for retry := 0; ; retry++ {
+		response, retryAfter, err := sender.post(ctx, pending)
+		if err != nil {
+			return append(failed, pending...), err
+		}
+
+		var rejected []elasticsearchDocument
+		if response == nil {
+			// The whole request was rejected with 429 Too Many Requests
+			rejected = pending
+		} else if response.Errors {
+			if len(response.Items) != len(pending) {
+				return append(failed, pending...), fmt.Errorf("bulk response has %d items for %d documents", len(response.Items), len(pending))
+			}
+
+			for index, item := range response.Items {
+				for _, result := range item {
+					switch {
+					case result.Status == http.StatusTooManyRequests:
+						rejected = append(rejected, pending[index])
+					case result.Error != nil:
+						failed = append(failed, pending[index])
+						reasons = append(reasons, fmt.Sprintf("%s: %s", result.Error.Type, result.Error.Reason))
+					}
+				}
+			}
+		}
+
+		if len(rejected) > 0 && retry < sender.config.MaxRetries {
+			delay := sender.backoff(retry, retryAfter)
+			lc.Warnf("Elasticsearch rejected %d documents with 429 Too Many Requests, retrying in %s", len(rejected), delay)
+			time.Sleep(delay)
+			pending = rejected
+			continue
+		}
+
+		if len(rejected) > 0 {
+			failed = append(failed, rejected...)
+			reasons = append(reasons, fmt.Sprintf("rejected with 429 Too Many Requests after %d retries", sender.config.MaxRetries))
+		}
+
+		if len(failed) > 0 {
+			return failed, fmt.Errorf("%d of %d documents could not be indexed, first error is %s", len(failed), len(documents), reasons[0])
+		}
+
+		return nil, nil
+	}
+}
+
+// post sends the Bulk API request for the documents. A nil response is returned when the request was rejected with
+// 429 Too Many Requests, along with the delay requested by the cluster, if any.
+func (sender *ElasticsearchSender) post(ctx interfaces.AppFunctionContext, documents []elasticsearchDocument) (*elasticsearchBulkResponse, time.Duration, error) {
+	var body bytes.Buffer
+	for _, document := range documents {
+		action, err := json.Marshal(map[string]map[string]string{"index": {"_index": document.index}})
+		if err != nil {
+			return nil, 0, err
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(document.source)
+		body.WriteByte('\n')
+	}
+
+	request, err := http.NewRequestWithContext(ctx.Context(), http.MethodPost, sender.config.URL+elasticsearchBulkPath, &body)
+	if err != nil {
+		return nil, 0, err
+	}
+	request.Header.Set("Content-Type", ContentTypeNDJSON)
+
+	if err := sender.setAuthorization(ctx, request); err != nil {
+		return nil, 0, err
+	}
+
+	response, err := sender.client.Do(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bulk request failed: %s", err.Error())
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, retryAfter, nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, maxElasticsearchErrorBodySize))
+		return nil, 0, fmt.Errorf("bulk request failed with %d HTTP status code: %s", response.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	result := &elasticsearchBulkResponse{}
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return nil, 0, fmt.Errorf("unable to decode bulk response: %s", err.Error())
+	}
+
+	return result, 0, nil
+}
+
+func (sender *ElasticsearchSender) setAuthorization(ctx interfaces.AppFunctionContext, request *http.Request) error {
+	switch sender.config.AuthMode {
+	case ElasticsearchAuthModeBasic:
+		secrets, err := ctx.GetSecret(sender.config.SecretPath, "username", "password")
+		if err != nil {
+			return err
+		}
+		request.SetBasicAuth(secrets["username"], secrets["password"])
+
+	case ElasticsearchAuthModeAPIKey:
+		secrets, err := ctx.GetSecret(sender.config.SecretPath, "apikey")
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Authorization", "ApiKey "+secrets["apikey"])
+	}
+
+	return nil
+}
+
+// backoff returns the delay before the retry, which is the delay requested by the cluster or the initial backoff
+// doubled for each previous retry, up to maxElasticsearchBackoff
+func (sender *ElasticsearchSender) backoff(retry int, retryAfter time.Duration) time.Duration {
+	delay := retryAfter
+	if delay == 0 {
+		delay = sender.initialBackoff
+		for count := 0; count < retry && delay < maxElasticsearchBackoff; count++ {
+			delay *= 2
+		}
+	}
+
+	if delay > maxElasticsearchBackoff {
+		return maxElasticsearchBackoff
+	}
+	return delay
+}
+
+// indexName formats the index for the document, using the Event's values when the document is an Event
+func (sender *ElasticsearchSender) indexName(ctx interfaces.AppFunctionContext, source []byte) (string, error) {
+	date := time.Now()
+	format := sender.config.Index
+
+	var event dtos.Event
+	if err := json.Unmarshal(source, &event); err == nil && len(event.DeviceName) > 0 {
+		format = strings.NewReplacer(
+			"{"+interfaces.DEVICENAME+"}", event.DeviceName,
+			"{"+interfaces.PROFILENAME+"}", event.ProfileName,
+			"{"+interfaces.SOURCENAME+"}", event.SourceName).Replace(format)
+		if event.Origin > 0 {
+			date = time.Unix(0, event.Origin)
+		}
+	}
+
+	format = strings.ReplaceAll(format, ElasticsearchDatePlaceholder, date.In(ctx.Location()).Format(elasticsearchDateFormat))
+
+	index, err := ctx.ApplyValues(format)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(index), nil
+}
+
+func (sender *ElasticsearchSender) setRetryData(ctx interfaces.AppFunctionContext, failed []elasticsearchDocument) {
+	if !sender.config.PersistOnError || len(failed) == 0 {
+		return
+	}
+
+	sources := make([]json.RawMessage, len(failed))
+	for index, document := range failed {
+		sources[index] = document.source
+	}
+
+	retryData, err := json.Marshal(sources)
+	if err != nil {
+		ctx.LoggingClient().Errorf("Unable to persist documents which failed to index: %s", err.Error())
+		return
+	}
+
+	ctx.SetRetryData(retryData)
+}
+
+// toElasticsearchSources returns the documents received, each compacted to a single line as required by the Bulk API
+func toElasticsearchSources(data interface{}) ([][]byte, error) {
+	var documents [][]byte
+
+	switch value := data.(type) {
+	case [][]byte:
+		documents = value
+	case dtos.Event:
+		document, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal Event: %s", err.Error())
+		}
+		documents = [][]byte{document}
+	default:
+		document, err := util.CoerceType(data)
+		if err != nil {
+			return nil, err
+		}
+
+		// A JSON array is a list of documents, i.e. retried from Store and Forward, since documents must be objects
+		document = bytes.TrimSpace(document)
+		if len(document) > 0 && document[0] == '[' {
+			var array []json.RawMessage
+			if err := json.Unmarshal(document, &array); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal array of documents: %s", err.Error())
+			}
+			for _, item := range array {
+				documents = append(documents, item)
+			}
+		} else {
+			documents = [][]byte{document}
+		}
+	}
+
+	sources := make([][]byte, len(documents))
+	for index, document := range documents {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, document); err != nil {
+			return nil, fmt.Errorf("document %d is not valid JSON: %s", index, err.Error())
+		}
+		if compacted.Len() == 0 || compacted.Bytes()[0] != '{' {
+			return nil, fmt.Errorf("document %d is not a JSON object", index)
+		}
+		sources[index] = compacted.Bytes()
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,282 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+// bulkRequest is an action and document received by the fake Bulk API
+type bulkRequest struct {
+	index    string
+	document string
+}
+
+// fakeElasticsearch is a Bulk API which responds to each request with the next of the handlers
+type fakeElasticsearch struct {
+	server        *httptest.Server
+	lock          sync.Mutex
+	requests      [][]bulkRequest
+	authorization []string
+	handlers      []func(writer http.ResponseWriter, documents []bulkRequest)
+}
+
+func newFakeElasticsearch(t *testing.T, handlers ...func(writer http.ResponseWriter, documents []bulkRequest)) *fakeElasticsearch {
+	fake := &fakeElasticsearch{handlers: handlers}
+	fake.server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/_bulk", request.URL.Path)
+		assert.Equal(t, ContentTypeNDJSON, request.Header.Get("Content-Type"))
+
+		var documents []bulkRequest
+		scanner := bufio.NewScanner(request.Body)
+		for scanner.Scan() {
+			var action map[string]map[string]string
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &action))
+			require.True(t, scanner.Scan(), "document expected after action")
+			documents = append(documents, bulkRequest{index: action["index"]["_index"], document: scanner.Text()})
+		}
+
+		fake.lock.Lock()
+		handler := fake.handlers[len(fake.requests)%len(fake.handlers)]
+		fake.requests = append(fake.requests, documents)
+		fake.authorization = append(fake.authorization, request.Header.Get("Authorization"))
+		fake.lock.Unlock()
+
+		handler(writer, documents)
+	}))
+	return fake
+}
+
+// bulkResponse responds with the statuses of each document
+func bulkResponse(statuses ...int) func(writer http.ResponseWriter, documents []bulkRequest) {
+	return func(writer http.ResponseWriter, documents []bulkRequest) {
+		response := map[string]interface{}{"errors": false}
+		var items []interface{}
+		for index := range documents {
+			status := http.StatusCreated
+			if index < len(statuses) {
+				status = statuses[index]
+			}
+			result := map[string]interface{}{"status": status}
+			if status >= 300 {
+				response["errors"] = true
+				result["error"] = map[string]string{"type": "mapper_parsing_exception", "reason": "failed to parse"}
+			}
+			items = append(items, map[string]interface{}{"index": result})
+		}
+		response["items"] = items
+		_ = json.NewEncoder(writer).Encode(response)
+	}
+}
+
+// newElasticsearchTestContext returns a context with the placeholder values set by the Event which triggered the pipeline
+func newElasticsearchTestContext() *appfunction.Context {
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.AddValue(interfaces.PROFILENAME, "Trigger-Profile")
+	return appContext
+}
+
+func newElasticsearchTestEvent(deviceName string, origin int64) dtos.Event {
+	event := dtos.NewEvent("Thermostat", deviceName, "temperature")
+	event.Origin = origin
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt64, int64(38))
+	return event
+}
+
+func TestNewElasticsearchSender(t *testing.T) {
+	sender, err := NewElasticsearchSender(ElasticsearchConfig{URL: "http://elasticsearch:9200/"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://elasticsearch:9200", sender.config.URL)
+	assert.Equal(t, DefaultElasticsearchIndex, sender.config.Index)
+	assert.Equal(t, ElasticsearchAuthModeNone, sender.config.AuthMode)
+	assert.Equal(t, defaultElasticsearchRetries, sender.config.MaxRetries)
+
+	tests := []struct {
+		Name          string
+		Config        ElasticsearchConfig
+		ErrorContains string
+	}{
+		{"Missing URL", ElasticsearchConfig{}, "URL must be specified"},
+		{"Bad URL", ElasticsearchConfig{URL: "://bogus"}, "URL is invalid"},
+		{"Bad AuthMode", ElasticsearchConfig{URL: "http://es:9200", AuthMode: "bogus"}, "AuthMode"},
+		{"Missing SecretPath", ElasticsearchConfig{URL: "http://es:9200", AuthMode: "Basic"}, "SecretPath"},
+		{"Bad Timeout", ElasticsearchConfig{URL: "http://es:9200", Timeout: "bogus"}, "Timeout"},
+		{"Bad InitialBackoff", ElasticsearchConfig{URL: "http://es:9200", InitialBackoff: "bogus"}, "InitialBackoff"},
+		{"Bad MaxRetries", ElasticsearchConfig{URL: "http://es:9200", MaxRetries: -1}, "MaxRetries"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := NewElasticsearchSender(test.Config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ErrorContains)
+		})
+	}
+}
+
+func TestElasticsearchSender_BulkIndex(t *testing.T) {
+	fake := newFakeElasticsearch(t, bulkResponse())
+	defer fake.server.Close()
+
+	sender, err := NewElasticsearchSender(ElasticsearchConfig{URL: fake.server.URL})
+	require.NoError(t, err)
+
+	event := newElasticsearchTestEvent("Room-1", 1600000000000000000)
+	appContext := newElasticsearchTestContext()
+	continuePipeline, result := sender.BulkIndex(appContext, event)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Equal(t, event, result)
+
+	// A batch of Event JSON and other documents, which use the current date
+	batch := [][]byte{[]byte(`{"deviceName":"Room-2","profileName":"Thermostat","origin":1600100000000000000}`), []byte("{\n  \"other\": true\n}")}
+	continuePipeline, result = sender.BulkIndex(appContext, batch)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+
+	require.Len(t, fake.requests, 2)
+	require.Len(t, fake.requests[0], 1)
+	assert.Equal(t, "edgex-thermostat-2020.09.13", fake.requests[0][0].index)
+	require.Len(t, fake.requests[1], 2)
+	assert.Equal(t, "edgex-thermostat-2020.09.14", fake.requests[1][0].index)
+	assert.True(t, strings.HasPrefix(fake.requests[1][1].index, "edgex-trigger-profile-"))
+	assert.Equal(t, `{"other":true}`, fake.requests[1][1].document)
+	assert.Empty(t, fake.authorization[0])
+}
+
+func TestElasticsearchSender_BulkIndexWithSecrets(t *testing.T) {
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", "elastic", "username", "password").Return(map[string]string{"username": "user", "password": "pass"}, nil)
+	mockSP.On("GetSecret", "opensearch", "apikey").Return(map[string]string{"apikey": "a2V5"}, nil)
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	fake := newFakeElasticsearch(t, bulkResponse())
+	defer fake.server.Close()
+
+	sender, err := NewElasticsearchSender(ElasticsearchConfig{URL: fake.server.URL, AuthMode: ElasticsearchAuthModeBasic, SecretPath: "elastic"})
+	require.NoError(t, err)
+	appContext := newElasticsearchTestContext()
+	continuePipeline, result := sender.BulkIndex(appContext, `{"a":1}`)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+
+	sender, err = NewElasticsearchSender(ElasticsearchConfig{URL: fake.server.URL, AuthMode: ElasticsearchAuthModeAPIKey, SecretPath: "opensearch"})
+	require.NoError(t, err)
+	continuePipeline, result = sender.BulkIndex(appContext, `{"a":1}`)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+
+	assert.Equal(t, []string{"Basic dXNlcjpwYXNz", "ApiKey a2V5"}, fake.authorization)
+}
+
+func TestElasticsearchSender_BulkIndexRetriesTooManyRequests(t *testing.T) {
+	tooManyRequests := func(writer http.ResponseWriter, _ []bulkRequest) {
+		writer.Header().Set("Retry-After", "0")
+		writer.WriteHeader(http.StatusTooManyRequests)
+	}
+	// The first request is rejected, then the second document is rejected, then all are indexed
+	fake := newFakeElasticsearch(t, tooManyRequests, bulkResponse(http.StatusCreated, http.StatusTooManyRequests), bulkResponse())
+	defer fake.server.Close()
+
+	sender, err := NewElasticsearchSender(ElasticsearchConfig{URL: fake.server.URL, InitialBackoff: "1ms"})
+	require.NoError(t, err)
+
+	continuePipeline, result := sender.BulkIndex(newElasticsearchTestContext(), `[{"id":1},{"id":2}]`)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+
+	require.Len(t, fake.requests, 3)
+	assert.Len(t, fake.requests[0], 2)
+	assert.Len(t, fake.requests[1], 2)
+	require.Len(t, fake.requests[2], 1)
+	assert.Equal(t, `{"id":2}`, fake.requests[2][0].document)
+}
+
+func TestElasticsearchSender_BulkIndexFailed(t *testing.T) {
+	fake := newFakeElasticsearch(t, bulkResponse(http.StatusCreated, http.StatusBadRequest, http.StatusTooManyRequests))
+	defer fake.server.Close()
+
+	sender, err := NewElasticsearchSender(ElasticsearchConfig{URL: fake.server.URL, MaxRetries: 1, InitialBackoff: "1ms", PersistOnError: true})
+	require.NoError(t, err)
+
+	appContext := newElasticsearchTestContext()
+	continuePipeline, result := sender.BulkIndex(appContext, `[{"id":1},{"id":2},{"id":3}]`)
+	require.False(t, continuePipeline)
+	require.Error(t, result.(error))
+	assert.Contains(t, result.(error).Error(), "1 of 3 documents could not be indexed")
+	assert.Contains(t, result.(error).Error(), "mapper_parsing_exception")
+
+	// The document rejected with 429 is retried and indexed, so only the document which failed to parse is stored
+	require.Len(t, fake.requests, 2)
+	assert.JSONEq(t, `[{"id":2}]`, string(appContext.RetryData()))
+
+	// Retried data is an array of the documents
+	fake.lock.Lock()
+	fake.handlers = []func(writer http.ResponseWriter, documents []bulkRequest){bulkResponse()}
+	fake.lock.Unlock()
+	continuePipeline, result = sender.BulkIndex(appContext, appContext.RetryData())
+	require.True(t, continuePipeline, "unexpected error %v", result)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	sender, err = NewElasticsearchSender(ElasticsearchConfig{URL: server.URL, PersistOnError: true})
+	require.NoError(t, err)
+	continuePipeline, result = sender.BulkIndex(appContext, `{"id":4}`)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "404")
+	assert.JSONEq(t, `[{"id":4}]`, string(appContext.RetryData()))
+}
+
+func TestElasticsearchSender_BulkIndexBadData(t *testing.T) {
+	sender, err := NewElasticsearchSender(ElasticsearchConfig{URL: "http://elasticsearch:9200"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name          string
+		Data          interface{}
+		ErrorContains string
+	}{
+		{"No data", nil, "No Data Received"},
+		{"Not JSON", "bogus", "not valid JSON"},
+		{"Not an object", `"text"`, "not a JSON object"},
+		{"Array of non objects", `[1, 2]`, "not a JSON object"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := sender.BulkIndex(ctx, test.Data)
+			require.False(t, continuePipeline)
+			assert.Contains(t, result.(error).Error(), test.ErrorContains)
+		})
+	}
+}
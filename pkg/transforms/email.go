@@ -0,0 +1,192 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+)
+
+const (
+	// EmailAuthModeNone indicates no authentication is used when connecting to the SMTP server
+	EmailAuthModeNone = "none"
+	// EmailAuthModeUsernamePassword indicates the username & password from the Secret Store are used
+	// to authenticate with the SMTP server
+	EmailAuthModeUsernamePassword = "usernamepassword"
+
+	emailSecretUsername = "username"
+	emailSecretPassword = "password"
+)
+
+// EmailSender houses the configuration for sending the pipeline data via SMTP
+type EmailSender struct {
+	config         EmailConfig
+	persistOnError bool
+	// sendMail is the function used to send the message. Defaults to smtp.SendMail and is replaced in unit tests.
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// EmailConfig contains all the settings available to the EmailSender
+type EmailConfig struct {
+	// Host of the SMTP server
+	Host string
+	// Port of the SMTP server
+	Port int
+	// From is the address the email is sent from
+	From string
+	// To is the list of recipient addresses
+	To []string
+	// Subject of the email. Placeholders in the form '{some-context-key}' are replaced with
+	// the values found in the context storage.
+	Subject string
+	// MessageTemplate is optional. When set it is used as the body of the email rather than the pipeline data.
+	// Placeholders in the form '{some-context-key}' are replaced with the values found in the context storage.
+	MessageTemplate string
+	// AuthMode indicates what to use when connecting to the SMTP server. Options are "none" and "usernamepassword".
+	AuthMode string
+	// SecretPath is the path in the Secret Store to retrieve the username & password from when
+	// AuthMode is "usernamepassword"
+	SecretPath string
+}
+
+// NewEmailSender creates, initializes and returns a new instance of EmailSender
+func NewEmailSender(config EmailConfig, persistOnError bool) *EmailSender {
+	config.AuthMode = strings.ToLower(strings.TrimSpace(config.AuthMode))
+	if len(config.AuthMode) == 0 {
+		config.AuthMode = EmailAuthModeNone
+	}
+
+	return &EmailSender{
+		config:         config,
+		persistOnError: persistOnError,
+		sendMail:       smtp.SendMail,
+	}
+}
+
+// SendEmail sends the data from the previous function, or the configured message template, as an email
+// to the configured recipients via SMTP. If no previous function exists, then the event that triggered
+// the pipeline will be used.
+func (sender *EmailSender) SendEmail(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+
+	ctx.LoggingClient().Debug("Email Exporting")
+
+	if len(sender.config.To) == 0 {
+		return false, errors.New("no recipients specified for Email Export")
+	}
+
+	body, err := sender.buildBody(ctx, data)
+	if err != nil {
+		return false, err
+	}
+
+	subject, err := ctx.ApplyValues(sender.config.Subject)
+	if err != nil {
+		return false, fmt.Errorf("email subject formatting failed: %s", err.Error())
+	}
+
+	auth, err := sender.getAuth(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	message := sender.buildMessage(subject, body)
+	address := fmt.Sprintf("%s:%d", sender.config.Host, sender.config.Port)
+
+	if err := sender.sendMail(address, auth, sender.config.From, sender.config.To, message); err != nil {
+		sender.setRetryData(ctx, body)
+		return false, fmt.Errorf("email export failed: %w", err)
+	}
+
+	ctx.LoggingClient().Debugf("Sent email to %d recipient(s) via %s", len(sender.config.To), address)
+	ctx.LoggingClient().Trace("Data exported", "Transport", "SMTP", common.CorrelationHeader, ctx.CorrelationID())
+
+	return true, nil
+}
+
+func (sender *EmailSender) buildBody(ctx interfaces.AppFunctionContext, data interface{}) ([]byte, error) {
+	if len(sender.config.MessageTemplate) == 0 {
+		return util.CoerceType(data)
+	}
+
+	body, err := ctx.ApplyValues(sender.config.MessageTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("email message template formatting failed: %s", err.Error())
+	}
+
+	return []byte(body), nil
+}
+
+func (sender *EmailSender) buildMessage(subject string, body []byte) []byte {
+	var message bytes.Buffer
+
+	message.WriteString(fmt.Sprintf("From: %s\r\n", sender.config.From))
+	message.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(sender.config.To, ", ")))
+	// The subject can contain values from the context, so it is encoded to prevent any line breaks in those values
+	// from injecting additional headers
+	message.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject)))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	message.WriteString("\r\n")
+	message.Write(body)
+
+	return message.Bytes()
+}
+
+func (sender *EmailSender) getAuth(ctx interfaces.AppFunctionContext) (smtp.Auth, error) {
+	switch sender.config.AuthMode {
+	case EmailAuthModeNone:
+		return nil, nil
+
+	case EmailAuthModeUsernamePassword:
+		if len(sender.config.SecretPath) == 0 {
+			return nil, fmt.Errorf("secretPath must be specified when AuthMode is '%s'", EmailAuthModeUsernamePassword)
+		}
+
+		secrets, err := ctx.GetSecret(sender.config.SecretPath, emailSecretUsername, emailSecretPassword)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve SMTP credentials at secret path=%s: %s", sender.config.SecretPath, err.Error())
+		}
+
+		return smtp.PlainAuth("", secrets[emailSecretUsername], secrets[emailSecretPassword], sender.config.Host), nil
+
+	default:
+		return nil, fmt.Errorf(
+			"invalid AuthMode '%s' for Email Export. Must be '%s' or '%s'",
+			sender.config.AuthMode,
+			EmailAuthModeNone,
+			EmailAuthModeUsernamePassword)
+	}
+}
+
+func (sender *EmailSender) setRetryData(ctx interfaces.AppFunctionContext, exportData []byte) {
+	if sender.persistOnError {
+		ctx.SetRetryData(exportData)
+	}
+}
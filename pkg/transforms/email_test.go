@@ -0,0 +1,173 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentEmail struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+	msg  string
+}
+
+func newTestEmailSender(config EmailConfig, persistOnError bool, sendErr error) (*EmailSender, *sentEmail) {
+	sent := &sentEmail{}
+	sender := NewEmailSender(config, persistOnError)
+	sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sent.addr = addr
+		sent.auth = a
+		sent.from = from
+		sent.to = to
+		sent.msg = string(msg)
+		return sendErr
+	}
+
+	return sender, sent
+}
+
+func TestEmailSender_SendEmail(t *testing.T) {
+	config := EmailConfig{
+		Host:    "localhost",
+		Port:    25,
+		From:    "edgex@example.com",
+		To:      []string{"ops@example.com", "oncall@example.com"},
+		Subject: "Alert from {test}",
+	}
+
+	ctx.AddValue("test", "foo")
+	sender, sent := newTestEmailSender(config, false, nil)
+
+	continuePipeline, result := sender.SendEmail(ctx, msgStr)
+	require.True(t, continuePipeline)
+	assert.Nil(t, result)
+
+	assert.Equal(t, "localhost:25", sent.addr)
+	assert.Nil(t, sent.auth)
+	assert.Equal(t, config.From, sent.from)
+	assert.Equal(t, config.To, sent.to)
+	assert.Contains(t, sent.msg, "To: ops@example.com, oncall@example.com\r\n")
+	assert.Contains(t, sent.msg, "Subject: Alert from foo\r\n")
+	assert.True(t, strings.HasSuffix(sent.msg, "\r\n\r\n"+msgStr))
+}
+
+func TestEmailSender_SendEmailSubjectHeaderInjection(t *testing.T) {
+	config := EmailConfig{
+		To:      []string{"ops@example.com"},
+		Subject: "Alert from {test}",
+	}
+
+	ctx.AddValue("test", "foo\r\nBcc: attacker@example.com")
+	sender, sent := newTestEmailSender(config, false, nil)
+
+	continuePipeline, _ := sender.SendEmail(ctx, msgStr)
+	require.True(t, continuePipeline)
+	assert.Contains(t, sent.msg, "Subject: =?utf-8?q?Alert_from_foo=0D=0ABcc:_attacker@example.com?=\r\n")
+	assert.NotContains(t, sent.msg, "\r\nBcc:")
+}
+
+func TestEmailSender_SendEmailTemplate(t *testing.T) {
+	config := EmailConfig{
+		To:              []string{"ops@example.com"},
+		Subject:         "Alert",
+		MessageTemplate: "Device {test} is over temperature",
+	}
+
+	ctx.AddValue("test", "foo")
+	sender, sent := newTestEmailSender(config, false, nil)
+
+	continuePipeline, _ := sender.SendEmail(ctx, msgStr)
+	require.True(t, continuePipeline)
+	assert.True(t, strings.HasSuffix(sent.msg, "\r\n\r\nDevice foo is over temperature"))
+}
+
+func TestEmailSender_SendEmailWithSecrets(t *testing.T) {
+	secretPath := "smtp"
+
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", secretPath, emailSecretUsername, emailSecretPassword).
+		Return(map[string]string{emailSecretUsername: "user", emailSecretPassword: "pass"}, nil)
+
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	config := EmailConfig{
+		Host:       "localhost",
+		To:         []string{"ops@example.com"},
+		AuthMode:   "UsernamePassword",
+		SecretPath: secretPath,
+	}
+
+	sender, sent := newTestEmailSender(config, false, nil)
+
+	continuePipeline, _ := sender.SendEmail(ctx, msgStr)
+	require.True(t, continuePipeline)
+	assert.NotNil(t, sent.auth)
+}
+
+func TestEmailSender_SendEmailErrors(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Config         EmailConfig
+		Data           interface{}
+		SendErr        error
+		PersistOnError bool
+		ExpectRetry    bool
+	}{
+		{"No Data", EmailConfig{To: []string{"ops@example.com"}}, nil, nil, false, false},
+		{"No Recipients", EmailConfig{}, msgStr, nil, false, false},
+		{"Bad Subject", EmailConfig{To: []string{"ops@example.com"}, Subject: "{missing}"}, msgStr, nil, false, false},
+		{"Bad Template", EmailConfig{To: []string{"ops@example.com"}, MessageTemplate: "{missing}"}, msgStr, nil, false, false},
+		{"Bad AuthMode", EmailConfig{To: []string{"ops@example.com"}, AuthMode: "bogus"}, msgStr, nil, false, false},
+		{"Missing SecretPath", EmailConfig{To: []string{"ops@example.com"}, AuthMode: EmailAuthModeUsernamePassword}, msgStr, nil, false, false},
+		{"Send Failed no persist", EmailConfig{To: []string{"ops@example.com"}}, msgStr, errors.New("failed"), false, false},
+		{"Send Failed with persist", EmailConfig{To: []string{"ops@example.com"}}, msgStr, errors.New("failed"), true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx.SetRetryData(nil)
+			sender, _ := newTestEmailSender(test.Config, test.PersistOnError, test.SendErr)
+
+			continuePipeline, result := sender.SendEmail(ctx, test.Data)
+			require.False(t, continuePipeline)
+			require.Error(t, result.(error))
+
+			if test.ExpectRetry {
+				assert.Equal(t, []byte(msgStr), ctx.RetryData())
+			} else {
+				assert.Nil(t, ctx.RetryData())
+			}
+		})
+	}
+}
@@ -23,13 +23,16 @@ import (
 	"crypto/cipher"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 )
 
 type Encryption struct {
@@ -37,6 +40,11 @@ type Encryption struct {
 	SecretName           string
 	EncryptionKey        string
 	InitializationVector string
+	// ResourceNames limits EncryptReadingValuesWithAES to the Readings for these resource names.
+	// All Readings are encrypted if empty.
+	ResourceNames []string
+	// Fields is the list of dot separated JSON field paths encrypted by EncryptFieldsWithAES
+	Fields []string
 }
 
 // NewEncryption creates, initializes and returns a new instance of Encryption
@@ -80,18 +88,181 @@ func (aesData Encryption) EncryptWithAES(ctx interfaces.AppFunctionContext, data
 		return false, err
 	}
 
-	iv := make([]byte, blockSize)
-	copy(iv, aesData.InitializationVector)
+	block, err := aesData.newCipher(ctx)
+	if err != nil {
+		return false, err
+	}
 
-	hash := sha1.New()
+	encodedData := aesData.encrypt(block, byteData)
+
+	// Set response "content-type" header to "text/plain"
+	ctx.SetResponseContentType(common.ContentTypeText)
+
+	return true, encodedData
+}
+
+// EncryptReadingValuesWithAES encrypts the values of the Event's Readings using AES encryption while leaving the rest
+// of the Event, i.e. the routing metadata such as device name and tags, in plaintext.
+// Only Readings for the resource names listed in ResourceNames are encrypted. All Readings are encrypted if
+// ResourceNames is empty. The encrypted Value of a Reading is Base64 encoded. For binary Readings the BinaryValue is
+// replaced with the raw encrypted bytes, which are Base64 encoded like any BinaryValue when the Event is marshaled to JSON.
+// It will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
+func (aesData Encryption) EncryptReadingValuesWithAES(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("no data received to encrypt")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("type received is not an Event")
+	}
+
+	ctx.LoggingClient().Debug("Encrypting Reading values with AES")
+
+	block, err := aesData.newCipher(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// Readings slice is shared with the original Event so must make a copy before modifying
+	readings := make([]dtos.BaseReading, len(event.Readings))
+	for index, reading := range event.Readings {
+		if aesData.isResourceToEncrypt(reading.ResourceName) {
+			if reading.ValueType == common.ValueTypeBinary {
+				reading.BinaryValue = aesData.encryptBytes(block, reading.BinaryValue)
+			} else {
+				reading.Value = string(aesData.encrypt(block, []byte(reading.Value)))
+			}
+		}
+
+		readings[index] = reading
+	}
+	event.Readings = readings
+
+	return true, event
+}
+
+// EncryptFieldsWithAES encrypts the values of the fields listed in Fields within the JSON representation of the
+// received data using AES encryption while leaving all other fields in plaintext.
+// Fields are specified as a dot separated path, i.e. 'readings.value', and are applied to every element when the path
+// traverses an array. Non-string values are encrypted as their JSON representation. The encrypted values are Base64 encoded.
+// It will return a []byte of the resulting JSON.
+func (aesData Encryption) EncryptFieldsWithAES(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("no data received to encrypt")
+	}
+
+	if len(aesData.Fields) == 0 {
+		return false, errors.New("no fields specified to encrypt")
+	}
+
+	ctx.LoggingClient().Debugf("Encrypting fields %v with AES", aesData.Fields)
+
+	byteData, err := util.CoerceType(data)
+	if err != nil {
+		return false, err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(byteData, &document); err != nil {
+		return false, fmt.Errorf("unable to encrypt fields, data received is not JSON: %s", err.Error())
+	}
+
+	block, err := aesData.newCipher(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, field := range aesData.Fields {
+		document, err = aesData.encryptField(block, document, strings.Split(field, "."))
+		if err != nil {
+			return false, fmt.Errorf("unable to encrypt field '%s': %s", field, err.Error())
+		}
+	}
+
+	result, err := json.Marshal(document)
+	if err != nil {
+		return false, err
+	}
+
+	ctx.SetResponseContentType(common.ContentTypeJSON)
+
+	return true, result
+}
+
+func (aesData Encryption) encryptField(block cipher.Block, node interface{}, path []string) (interface{}, error) {
+	switch value := node.(type) {
+	case []interface{}:
+		for index, element := range value {
+			encrypted, err := aesData.encryptField(block, element, path)
+			if err != nil {
+				return nil, err
+			}
+			value[index] = encrypted
+		}
+		return value, nil
+
+	case map[string]interface{}:
+		if len(path) == 0 {
+			break
+		}
+
+		child, found := value[path[0]]
+		if !found {
+			// Field not present in this element, nothing to encrypt
+			return value, nil
+		}
+
+		encrypted, err := aesData.encryptField(block, child, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		value[path[0]] = encrypted
+		return value, nil
+	}
+
+	if len(path) > 0 {
+		// Path goes deeper than the document, so nothing to encrypt
+		return node, nil
+	}
+
+	var plainData []byte
+	if text, ok := node.(string); ok {
+		plainData = []byte(text)
+	} else {
+		var err error
+		plainData, err = json.Marshal(node)
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	return string(aesData.encrypt(block, plainData)), nil
+}
+
+func (aesData Encryption) isResourceToEncrypt(resourceName string) bool {
+	if len(aesData.ResourceNames) == 0 {
+		return true
+	}
+
+	for _, name := range aesData.ResourceNames {
+		if name == resourceName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newCipher creates the AES cipher block using the encryption key from configuration or from the Secret Store
+func (aesData Encryption) newCipher(ctx interfaces.AppFunctionContext) (cipher.Block, error) {
 	// If using Secret Store for the encryption key
 	if len(aesData.SecretPath) != 0 && len(aesData.SecretName) != 0 {
 		// Note secrets are cached so this call doesn't result in unneeded calls to SecretStore Service and
 		// the cache is invalidated when StoreSecrets is used.
 		secretData, err := ctx.GetSecret(aesData.SecretPath, aesData.SecretName)
 		if err != nil {
-			return false, fmt.Errorf(
+			return nil, fmt.Errorf(
 				"unable to retieve encryption key at secret path=%s and name=%s",
 				aesData.SecretPath,
 				aesData.SecretName)
@@ -99,7 +270,7 @@ func (aesData Encryption) EncryptWithAES(ctx interfaces.AppFunctionContext, data
 
 		key, ok := secretData[aesData.SecretName]
 		if !ok {
-			return false, fmt.Errorf("unable find encryption key in secret data for name=%s", aesData.SecretName)
+			return nil, fmt.Errorf("unable find encryption key in secret data for name=%s", aesData.SecretName)
 		}
 
 		ctx.LoggingClient().Debugf(
@@ -111,27 +282,31 @@ func (aesData Encryption) EncryptWithAES(ctx interfaces.AppFunctionContext, data
 	}
 
 	if len(aesData.EncryptionKey) == 0 {
-		return false, fmt.Errorf("AES encryption key not set")
+		return nil, fmt.Errorf("AES encryption key not set")
 	}
 
+	hash := sha1.New()
 	hash.Write([]byte((aesData.EncryptionKey)))
 	key := hash.Sum(nil)
 	key = key[:blockSize]
 
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return false, err
-	}
+	return aes.NewCipher(key)
+}
+
+// encrypt encrypts the data with the cipher block and returns the Base64 encoded result
+func (aesData Encryption) encrypt(block cipher.Block, data []byte) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(aesData.encryptBytes(block, data)))
+}
+
+// encryptBytes encrypts the data with the cipher block and returns the raw encrypted bytes
+func (aesData Encryption) encryptBytes(block cipher.Block, data []byte) []byte {
+	iv := make([]byte, blockSize)
+	copy(iv, aesData.InitializationVector)
 
 	ecb := cipher.NewCBCEncrypter(block, iv)
-	content := pkcs5Padding(byteData, block.BlockSize())
+	content := pkcs5Padding(data, block.BlockSize())
 	encrypted := make([]byte, len(content))
 	ecb.CryptBlocks(encrypted, content)
 
-	encodedData := []byte(base64.StdEncoding.EncodeToString(encrypted))
-
-	// Set response "content-type" header to "text/plain"
-	ctx.SetResponseContentType(common.ContentTypeText)
-
-	return true, encodedData
+	return encrypted
 }
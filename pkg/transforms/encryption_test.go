@@ -22,13 +22,16 @@ import (
 	"crypto/cipher"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/json"
 	"testing"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -129,3 +132,120 @@ func TestAESNoData(t *testing.T) {
 	assert.False(t, continuePipeline)
 	assert.Error(t, result.(error), "expect an error")
 }
+
+func TestEncryptReadingValuesWithAES(t *testing.T) {
+	original := dtos.Event{
+		DeviceName: "device1",
+		Tags:       map[string]string{"GatewayId": "gateway1"},
+		Readings: []dtos.BaseReading{
+			{ResourceName: "temperature", ValueType: common.ValueTypeInt64, SimpleReading: dtos.SimpleReading{Value: "72"}},
+			{ResourceName: "humidity", ValueType: common.ValueTypeInt64, SimpleReading: dtos.SimpleReading{Value: "40"}},
+			{ResourceName: "image", ValueType: common.ValueTypeBinary, BinaryReading: dtos.BinaryReading{BinaryValue: []byte(plainString)}},
+		},
+	}
+
+	tests := []struct {
+		Name          string
+		ResourceNames []string
+		ExpectedPlain []bool
+	}{
+		{"All readings", nil, []bool{false, false, false}},
+		{"Specific readings", []string{"temperature", "image"}, []bool{false, true, false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			enc := NewEncryption(aesData.Key, aesData.InitVector)
+			enc.ResourceNames = test.ResourceNames
+
+			continuePipeline, result := enc.EncryptReadingValuesWithAES(ctx, original)
+			require.True(t, continuePipeline)
+
+			actual, ok := result.(dtos.Event)
+			require.True(t, ok, "Result not an Event")
+			assert.Equal(t, original.DeviceName, actual.DeviceName)
+			assert.Equal(t, original.Tags, actual.Tags)
+
+			for index, reading := range actual.Readings {
+				expected := original.Readings[index]
+				if test.ExpectedPlain[index] {
+					assert.Equal(t, expected, reading)
+					continue
+				}
+
+				if reading.ValueType == common.ValueTypeBinary {
+					// BinaryValue holds the raw encrypted bytes, so is only Base64 encoded once when marshaled to JSON
+					require.Zero(t, len(reading.BinaryValue)%blockSize)
+					encoded := base64.StdEncoding.EncodeToString(reading.BinaryValue)
+					assert.Equal(t, expected.BinaryValue, aesDecrypt([]byte(encoded), aesData))
+
+					marshaled, err := json.Marshal(reading)
+					require.NoError(t, err)
+					assert.Contains(t, string(marshaled), `"binaryValue":"`+encoded+`"`)
+				} else {
+					assert.Equal(t, expected.Value, string(aesDecrypt([]byte(reading.Value), aesData)))
+				}
+			}
+
+			// Original Event must not be modified
+			assert.Equal(t, "72", original.Readings[0].Value)
+		})
+	}
+}
+
+func TestEncryptReadingValuesWithAESErrors(t *testing.T) {
+	enc := NewEncryption(aesData.Key, aesData.InitVector)
+
+	continuePipeline, result := enc.EncryptReadingValuesWithAES(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+
+	continuePipeline, result = enc.EncryptReadingValuesWithAES(ctx, "not an event")
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+
+	enc = NewEncryption("", aesData.InitVector)
+	continuePipeline, result = enc.EncryptReadingValuesWithAES(ctx, dtos.Event{})
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+}
+
+func TestEncryptFieldsWithAES(t *testing.T) {
+	input := `{"deviceName":"device1","count":5,"readings":[{"resourceName":"temperature","value":"72"},{"resourceName":"humidity"}]}`
+
+	enc := NewEncryption(aesData.Key, aesData.InitVector)
+	enc.Fields = []string{"readings.value", "count", "missing.field"}
+
+	continuePipeline, result := enc.EncryptFieldsWithAES(ctx, input)
+	require.True(t, continuePipeline, result)
+
+	actual := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(result.([]byte), &actual))
+
+	assert.Equal(t, "device1", actual["deviceName"])
+	assert.Equal(t, "5", string(aesDecrypt([]byte(actual["count"].(string)), aesData)))
+
+	readings := actual["readings"].([]interface{})
+	first := readings[0].(map[string]interface{})
+	assert.Equal(t, "temperature", first["resourceName"])
+	assert.Equal(t, "72", string(aesDecrypt([]byte(first["value"].(string)), aesData)))
+	assert.NotContains(t, readings[1].(map[string]interface{}), "value")
+	assert.Equal(t, common.ContentTypeJSON, ctx.ResponseContentType())
+}
+
+func TestEncryptFieldsWithAESErrors(t *testing.T) {
+	enc := NewEncryption(aesData.Key, aesData.InitVector)
+
+	continuePipeline, result := enc.EncryptFieldsWithAES(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+
+	continuePipeline, result = enc.EncryptFieldsWithAES(ctx, `{"value":"72"}`)
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error), "expected error when no fields specified")
+
+	enc.Fields = []string{"value"}
+	continuePipeline, result = enc.EncryptFieldsWithAES(ctx, "not JSON")
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+}
@@ -0,0 +1,215 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+)
+
+const (
+	// SystemEventTypeDevice is the type of the system events published by Core Metadata for changes to devices
+	SystemEventTypeDevice = "device"
+	// SystemEventActionDelete is the action of the system events published by Core Metadata for removed devices
+	SystemEventActionDelete = "delete"
+
+	eventSourcingStateKeyPrefix = "eventsourcing/revision/"
+)
+
+// SystemEvent is a system event published by Core Metadata, i.e. on the 'edgex/system-events/core-metadata/device/#'
+// topics, when a device is added, updated or removed. Details holds the device DTO for device system events.
+type SystemEvent struct {
+	Type      string          `json:"type"`
+	Action    string          `json:"action"`
+	Source    string          `json:"source"`
+	Owner     string          `json:"owner"`
+	Details   json.RawMessage `json:"details"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// EventSourcingRecord is the document exported by AddRevision. Downstream stores apply the records for a device in
+// Revision order to rebuild its state, removing the device when the record is a Tombstone.
+type EventSourcingRecord struct {
+	Device string `json:"device"`
+	// Revision increases by one with each record for the device, so gaps and reordering can be detected
+	Revision uint64 `json:"revision"`
+	// Tombstone is set when the device has been removed, in which case Event is empty
+	Tombstone bool `json:"tombstone,omitempty"`
+	// Origin is the Event's origin or the time the device was removed
+	Origin int64       `json:"origin"`
+	Event  *dtos.Event `json:"event,omitempty"`
+}
+
+// EventSourcer houses the last revision number per device, which are persisted to the function context's State when
+// State is enabled so the revisions keep increasing across restarts of the service
+type EventSourcer struct {
+	revisions map[string]uint64
+	mutex     sync.Mutex
+}
+
+// NewEventSourcer creates, initializes and returns a new instance of EventSourcer
+func NewEventSourcer() *EventSourcer {
+	return &EventSourcer{
+		revisions: make(map[string]uint64),
+	}
+}
+
+// AddRevision wraps the Event received in the JSON encoded EventSourcingRecord with the next revision number for its
+// device. Device removals, received as the Core Metadata device delete SystemEvent, are passed on as tombstone records,
+// so the pipeline's trigger must also subscribe to those system events and the pipeline should use the byte array
+// target type. Other system events are ignored and stop the pipeline.
+// This function will return an error and stop the pipeline if the data received is not an Event or SystemEvent, or the
+// revision can not be persisted to State.
+func (sourcer *EventSourcer) AddRevision(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("AddRevision: no data received")
+	}
+
+	record, err := toEventSourcingRecord(data)
+	if err != nil {
+		return false, fmt.Errorf("AddRevision: %s", err.Error())
+	}
+
+	if record == nil {
+		ctx.LoggingClient().Debug("AddRevision: ignoring system event which isn't a device removal")
+		ctx.SetFiltered("system event isn't a device removal")
+		return false, nil
+	}
+
+	record.Revision, err = sourcer.nextRevision(ctx.State(), record.Device)
+	if err != nil {
+		return false, fmt.Errorf("AddRevision: unable to persist revision for '%s': %s", record.Device, err.Error())
+	}
+
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return false, fmt.Errorf("AddRevision: unable to marshal record: %s", err.Error())
+	}
+
+	ctx.LoggingClient().Debugf("AddRevision: revision %d for '%s', tombstone=%t", record.Revision, record.Device, record.Tombstone)
+	ctx.SetResponseContentType(common.ContentTypeJSON)
+
+	return true, encoded
+}
+
+// nextRevision increments and returns the revision of the device, loading the last revision from state the first
+// time the device is seen and persisting the new revision when state is available
+func (sourcer *EventSourcer) nextRevision(state interfaces.State, device string) (uint64, error) {
+	sourcer.mutex.Lock()
+	defer sourcer.mutex.Unlock()
+
+	key := eventSourcingStateKeyPrefix + device
+
+	revision, exists := sourcer.revisions[device]
+	if !exists && state != nil {
+		if stored, found := state.Get(key); found {
+			var err error
+			revision, err = strconv.ParseUint(string(stored), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid stored revision '%s': %s", string(stored), err.Error())
+			}
+		}
+	}
+
+	revision++
+
+	if state != nil {
+		if err := state.Set(key, []byte(strconv.FormatUint(revision, 10))); err != nil {
+			return 0, err
+		}
+	}
+
+	sourcer.revisions[device] = revision
+
+	return revision, nil
+}
+
+// toEventSourcingRecord returns the record, without its revision, for the Event or device removal SystemEvent, or nil
+// for other system events. Data of type []byte is decoded as a SystemEvent, AddEventRequest or Event.
+func toEventSourcingRecord(data interface{}) (*EventSourcingRecord, error) {
+	switch value := data.(type) {
+	case dtos.Event:
+		return eventRecord(value), nil
+	case *dtos.Event:
+		return eventRecord(*value), nil
+	case SystemEvent:
+		return systemEventRecord(value)
+	case *SystemEvent:
+		return systemEventRecord(*value)
+	case string:
+		return decodeEventSourcingRecord([]byte(value))
+	case []byte:
+		return decodeEventSourcingRecord(value)
+	}
+
+	return nil, fmt.Errorf("type received is not an Event or SystemEvent, got %T", data)
+}
+
+func decodeEventSourcingRecord(data []byte) (*EventSourcingRecord, error) {
+	var systemEvent SystemEvent
+	if err := json.Unmarshal(data, &systemEvent); err == nil && len(systemEvent.Type) > 0 && len(systemEvent.Action) > 0 {
+		return systemEventRecord(systemEvent)
+	}
+
+	var request requests.AddEventRequest
+	if err := json.Unmarshal(data, &request); err == nil && len(request.Event.DeviceName) > 0 {
+		return eventRecord(request.Event), nil
+	}
+
+	var event dtos.Event
+	if err := json.Unmarshal(data, &event); err == nil && len(event.DeviceName) > 0 {
+		return eventRecord(event), nil
+	}
+
+	return nil, errors.New("data received is not an Event or SystemEvent")
+}
+
+func eventRecord(event dtos.Event) *EventSourcingRecord {
+	return &EventSourcingRecord{
+		Device: event.DeviceName,
+		Origin: event.Origin,
+		Event:  &event,
+	}
+}
+
+func systemEventRecord(systemEvent SystemEvent) (*EventSourcingRecord, error) {
+	if systemEvent.Type != SystemEventTypeDevice || systemEvent.Action != SystemEventActionDelete {
+		return nil, nil
+	}
+
+	var device struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(systemEvent.Details, &device); err != nil || len(device.Name) == 0 {
+		return nil, errors.New("device system event doesn't contain the device name")
+	}
+
+	return &EventSourcingRecord{
+		Device:    device.Name,
+		Tombstone: true,
+		Origin:    systemEvent.Timestamp,
+	}, nil
+}
@@ -0,0 +1,174 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces/mocks"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryState struct {
+	values map[string][]byte
+	err    error
+}
+
+func (s *memoryState) Get(key string) ([]byte, bool) {
+	value, found := s.values[key]
+	return value, found
+}
+
+func (s *memoryState) Set(key string, value []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryState) Delete(key string) error {
+	delete(s.values, key)
+	return nil
+}
+
+func (s *memoryState) Keys() []string {
+	return nil
+}
+
+func addTestRevision(t *testing.T, sourcer *EventSourcer, data interface{}) EventSourcingRecord {
+	continuePipeline, result := sourcer.AddRevision(ctx, data)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+
+	var record EventSourcingRecord
+	require.NoError(t, json.Unmarshal(result.([]byte), &record))
+	return record
+}
+
+func deviceDeleteSystemEvent(t *testing.T, deviceName string) []byte {
+	systemEvent := SystemEvent{
+		Type:      SystemEventTypeDevice,
+		Action:    SystemEventActionDelete,
+		Source:    "core-metadata",
+		Owner:     "device-virtual",
+		Details:   json.RawMessage(`{"name":"` + deviceName + `","profileName":"Random-Integer-Device"}`),
+		Timestamp: 1600000000,
+	}
+
+	encoded, err := json.Marshal(systemEvent)
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestAddRevision(t *testing.T) {
+	sourcer := NewEventSourcer()
+
+	first := dtos.NewEvent("Random-Integer-Device", deviceName1, "Int8")
+	second := dtos.NewEvent("Random-Integer-Device", deviceName2, "Int8")
+	require.NoError(t, first.AddSimpleReading("Int8", common.ValueTypeInt8, int8(42)))
+	requestBytes, err := json.Marshal(requests.NewAddEventRequest(first))
+	require.NoError(t, err)
+
+	record := addTestRevision(t, sourcer, first)
+	assert.Equal(t, deviceName1, record.Device)
+	assert.Equal(t, uint64(1), record.Revision)
+	assert.False(t, record.Tombstone)
+	assert.Equal(t, first.Origin, record.Origin)
+	require.NotNil(t, record.Event)
+	assert.Equal(t, first.Id, record.Event.Id)
+
+	assert.Equal(t, uint64(1), addTestRevision(t, sourcer, &second).Revision)
+	assert.Equal(t, uint64(2), addTestRevision(t, sourcer, requestBytes).Revision)
+
+	record = addTestRevision(t, sourcer, deviceDeleteSystemEvent(t, deviceName1))
+	assert.Equal(t, deviceName1, record.Device)
+	assert.Equal(t, uint64(3), record.Revision)
+	assert.True(t, record.Tombstone)
+	assert.Nil(t, record.Event)
+	assert.Equal(t, int64(1600000000), record.Origin)
+
+	// A device re-added with the same name continues from the tombstone's revision
+	assert.Equal(t, uint64(4), addTestRevision(t, sourcer, first).Revision)
+}
+
+func TestAddRevisionIgnoredSystemEvent(t *testing.T) {
+	sourcer := NewEventSourcer()
+
+	systemEvent := SystemEvent{Type: SystemEventTypeDevice, Action: "add", Details: json.RawMessage(`{"name":"` + deviceName1 + `"}`)}
+
+	continuePipeline, result := sourcer.AddRevision(ctx, systemEvent)
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+	assert.Empty(t, sourcer.revisions)
+}
+
+func TestAddRevisionErrors(t *testing.T) {
+	tests := []struct {
+		Name string
+		Data interface{}
+	}{
+		{"No data", nil},
+		{"Bad type", 42},
+		{"Not an Event", []byte(`{"value":1}`)},
+		{"Device system event without name", SystemEvent{Type: SystemEventTypeDevice, Action: SystemEventActionDelete}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := NewEventSourcer().AddRevision(ctx, test.Data)
+			assert.False(t, continuePipeline)
+			require.IsType(t, errors.New(""), result)
+			assert.Contains(t, result.(error).Error(), "AddRevision")
+		})
+	}
+}
+
+func TestAddRevisionState(t *testing.T) {
+	state := &memoryState{values: map[string][]byte{eventSourcingStateKeyPrefix + deviceName1: []byte("41")}}
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("LoggingClient").Return(logger.NewMockClient())
+	mockContext.On("State").Return(state)
+	mockContext.On("SetResponseContentType", mock.Anything).Return()
+
+	event := dtos.NewEvent("Random-Integer-Device", deviceName1, "Int8")
+
+	// The revision continues from that persisted before a restart
+	continuePipeline, result := NewEventSourcer().AddRevision(mockContext, event)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+
+	var record EventSourcingRecord
+	require.NoError(t, json.Unmarshal(result.([]byte), &record))
+	assert.Equal(t, uint64(42), record.Revision)
+	assert.Equal(t, []byte("42"), state.values[eventSourcingStateKeyPrefix+deviceName1])
+
+	state.err = errors.New("database unavailable")
+	continuePipeline, result = NewEventSourcer().AddRevision(mockContext, event)
+	assert.False(t, continuePipeline)
+	require.Error(t, result.(error))
+	assert.Contains(t, result.(error).Error(), "database unavailable")
+}
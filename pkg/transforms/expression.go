@@ -0,0 +1,309 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+)
+
+// ExpressionValueVariable is the name of the variable that holds the value of the reading the expression is evaluated for
+const ExpressionValueVariable = "value"
+
+// expressionFunctions are the functions that can be called from an expression
+var expressionFunctions = map[string]struct {
+	argCount int
+	function func(args []float64) float64
+}{
+	"abs":   {1, func(args []float64) float64 { return math.Abs(args[0]) }},
+	"ceil":  {1, func(args []float64) float64 { return math.Ceil(args[0]) }},
+	"floor": {1, func(args []float64) float64 { return math.Floor(args[0]) }},
+	"round": {1, func(args []float64) float64 { return math.Round(args[0]) }},
+	"sqrt":  {1, func(args []float64) float64 { return math.Sqrt(args[0]) }},
+	"exp":   {1, func(args []float64) float64 { return math.Exp(args[0]) }},
+	"log":   {1, func(args []float64) float64 { return math.Log(args[0]) }},
+	"log10": {1, func(args []float64) float64 { return math.Log10(args[0]) }},
+	"pow":   {2, func(args []float64) float64 { return math.Pow(args[0], args[1]) }},
+	"min":   {2, func(args []float64) float64 { return math.Min(args[0], args[1]) }},
+	"max":   {2, func(args []float64) float64 { return math.Max(args[0], args[1]) }},
+}
+
+// Expression houses the arithmetic expression evaluated for the numeric readings of an Event, i.e. to calibrate or scale them
+type Expression struct {
+	expression         ast.Expr
+	variables          map[string]float64
+	resourceNames      []string
+	outputResourceName string
+}
+
+// NewExpression parses the specified arithmetic expression, i.e. "value * 0.1 + offset", and returns a new instance of
+// Expression. The expression supports the + - * / % and ^ (power) operators, parentheses, numeric literals, the reading's
+// value, the specified variables and the abs, ceil, floor, round, sqrt, exp, log, log10, pow, min and max functions.
+// The expression is evaluated for the numeric readings with one of the specified resource names, or all numeric
+// readings if none are specified. The result replaces the reading's value unless an output resource name is specified,
+// in which case a new reading with that name is added to the Event.
+// An error is returned if the expression fails to parse or references an unknown variable or function.
+func NewExpression(expression string, variables map[string]float64, resourceNames []string, outputResourceName string) (*Expression, error) {
+	parsed, err := parser.ParseExpr(expression)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse expression '%s': %s", expression, err.Error())
+	}
+
+	transform := &Expression{
+		expression:         parsed,
+		variables:          variables,
+		resourceNames:      resourceNames,
+		outputResourceName: outputResourceName,
+	}
+
+	if err := transform.validate(parsed); err != nil {
+		return nil, fmt.Errorf("invalid expression '%s': %s", expression, err.Error())
+	}
+
+	return transform, nil
+}
+
+// Evaluate evaluates the expression for each of the Event's matching numeric readings and either replaces the reading's
+// value with the result or adds a new reading holding the result. Results are Float64 readings.
+// It will return an error and stop the pipeline if a non-edgex event is received, no data is received or a
+// reading's value can't be evaluated.
+func (e *Expression) Evaluate(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Evaluate: no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("Evaluate: type received is not an Event")
+	}
+
+	ctx.LoggingClient().Debug("Evaluating expression for Event readings")
+
+	// Readings slice is shared with the original Event so must make a copy before modifying
+	readings := make([]dtos.BaseReading, 0, len(event.Readings))
+	for _, reading := range event.Readings {
+		if !e.appliesTo(reading) {
+			readings = append(readings, reading)
+			continue
+		}
+
+		value, err := strconv.ParseFloat(reading.Value, 64)
+		if err != nil {
+			return false, fmt.Errorf("unable to parse value '%s' of reading '%s': %s", reading.Value, reading.ResourceName, err.Error())
+		}
+
+		result, err := e.evaluate(e.expression, value)
+		if err == nil && (math.IsNaN(result) || math.IsInf(result, 0)) {
+			err = fmt.Errorf("result %v is not a finite number", result)
+		}
+		if err != nil {
+			return false, fmt.Errorf("unable to evaluate expression for reading '%s': %s", reading.ResourceName, err.Error())
+		}
+
+		resourceName := reading.ResourceName
+		if len(e.outputResourceName) > 0 {
+			resourceName = e.outputResourceName
+		}
+
+		resultReading, err := dtos.NewSimpleReading(reading.ProfileName, reading.DeviceName, resourceName, common.ValueTypeFloat64, result)
+		if err != nil {
+			return false, fmt.Errorf("unable to create reading for expression result: %s", err.Error())
+		}
+		resultReading.Origin = reading.Origin
+
+		if len(e.outputResourceName) > 0 {
+			readings = append(readings, reading, resultReading)
+			continue
+		}
+
+		reading.ValueType = resultReading.ValueType
+		reading.Value = resultReading.Value
+		readings = append(readings, reading)
+	}
+	event.Readings = readings
+
+	return true, event
+}
+
+// validate checks that the expression only uses the supported literals, operators, variables and functions
+func (e *Expression) validate(node ast.Expr) error {
+	switch node := node.(type) {
+	case *ast.BasicLit:
+		if node.Kind != token.INT && node.Kind != token.FLOAT {
+			return fmt.Errorf("unsupported literal '%s'", node.Value)
+		}
+		return nil
+
+	case *ast.Ident:
+		if _, ok := e.variables[node.Name]; !ok && node.Name != ExpressionValueVariable {
+			return fmt.Errorf("unknown variable '%s'", node.Name)
+		}
+		return nil
+
+	case *ast.ParenExpr:
+		return e.validate(node.X)
+
+	case *ast.UnaryExpr:
+		if node.Op != token.SUB && node.Op != token.ADD {
+			return fmt.Errorf("unsupported operator '%s'", node.Op.String())
+		}
+		return e.validate(node.X)
+
+	case *ast.BinaryExpr:
+		switch node.Op {
+		case token.ADD, token.SUB, token.MUL, token.QUO, token.REM, token.XOR:
+		default:
+			return fmt.Errorf("unsupported operator '%s'", node.Op.String())
+		}
+		if err := e.validate(node.X); err != nil {
+			return err
+		}
+		return e.validate(node.Y)
+
+	case *ast.CallExpr:
+		name, ok := node.Fun.(*ast.Ident)
+		if !ok {
+			return errors.New("unsupported function call")
+		}
+		function, ok := expressionFunctions[name.Name]
+		if !ok {
+			return fmt.Errorf("unknown function '%s'", name.Name)
+		}
+		if len(node.Args) != function.argCount {
+			return fmt.Errorf("function '%s' expects %d argument(s), got %d", name.Name, function.argCount, len(node.Args))
+		}
+		for _, arg := range node.Args {
+			if err := e.validate(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported expression '%T'", node)
+}
+
+// evaluate computes the value of the previously validated expression for the specified reading value
+func (e *Expression) evaluate(node ast.Expr, value float64) (float64, error) {
+	switch node := node.(type) {
+	case *ast.BasicLit:
+		return strconv.ParseFloat(node.Value, 64)
+
+	case *ast.Ident:
+		if node.Name == ExpressionValueVariable {
+			return value, nil
+		}
+		return e.variables[node.Name], nil
+
+	case *ast.ParenExpr:
+		return e.evaluate(node.X, value)
+
+	case *ast.UnaryExpr:
+		operand, err := e.evaluate(node.X, value)
+		if err != nil {
+			return 0, err
+		}
+		if node.Op == token.SUB {
+			return -operand, nil
+		}
+		return operand, nil
+
+	case *ast.BinaryExpr:
+		left, err := e.evaluate(node.X, value)
+		if err != nil {
+			return 0, err
+		}
+		right, err := e.evaluate(node.Y, value)
+		if err != nil {
+			return 0, err
+		}
+		switch node.Op {
+		case token.ADD:
+			return left + right, nil
+		case token.SUB:
+			return left - right, nil
+		case token.MUL:
+			return left * right, nil
+		case token.QUO:
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return left / right, nil
+		case token.REM:
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return math.Mod(left, right), nil
+		case token.XOR:
+			return math.Pow(left, right), nil
+		}
+		return 0, fmt.Errorf("unsupported operator '%s'", node.Op.String())
+
+	case *ast.CallExpr:
+		function := expressionFunctions[node.Fun.(*ast.Ident).Name]
+		args := make([]float64, len(node.Args))
+		for index, arg := range node.Args {
+			result, err := e.evaluate(arg, value)
+			if err != nil {
+				return 0, err
+			}
+			args[index] = result
+		}
+		return function.function(args), nil
+	}
+
+	return 0, fmt.Errorf("unsupported expression '%T'", node)
+}
+
+// appliesTo returns true if the reading is numeric and has one of the resource names, if any, the expression is evaluated for
+func (e *Expression) appliesTo(reading dtos.BaseReading) bool {
+	if !isNumericValueType(reading.ValueType) {
+		return false
+	}
+
+	if len(e.resourceNames) == 0 {
+		return true
+	}
+
+	for _, name := range e.resourceNames {
+		if name == reading.ResourceName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNumericValueType(valueType string) bool {
+	switch valueType {
+	case common.ValueTypeUint8, common.ValueTypeUint16, common.ValueTypeUint32, common.ValueTypeUint64,
+		common.ValueTypeInt8, common.ValueTypeInt16, common.ValueTypeInt32, common.ValueTypeInt64,
+		common.ValueTypeFloat32, common.ValueTypeFloat64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExpressionTestEvent() dtos.Event {
+	event := dtos.NewEvent("profile", "device", "source")
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt32, int32(215))
+	_ = event.AddSimpleReading("humidity", common.ValueTypeFloat64, 40.5)
+	_ = event.AddSimpleReading("status", common.ValueTypeString, "ok")
+	return event
+}
+
+func TestNewExpression(t *testing.T) {
+	variables := map[string]float64{"offset": 2.5}
+
+	tests := []struct {
+		Name        string
+		Expression  string
+		ExpectError bool
+	}{
+		{"Valid arithmetic", "value * 0.1 + offset", false},
+		{"Valid functions", "round(max(value, 0) ^ 2) % 10 - abs(-offset)", false},
+		{"Valid division by expression", "value / (value - 1)", false},
+		{"Syntax error", "value * ", true},
+		{"Unknown variable", "value * gain", true},
+		{"Unknown function", "cos(value)", true},
+		{"Wrong argument count", "pow(value)", true},
+		{"Unsupported operator", "value == 1", true},
+		{"Unsupported literal", `value + "1"`, true},
+		{"Unsupported expression", "value[0]", true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := NewExpression(testCase.Expression, variables, nil, "")
+			if testCase.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExpressionEvaluate(t *testing.T) {
+	tests := []struct {
+		Name               string
+		Expression         string
+		ResourceNames      []string
+		OutputResourceName string
+		ExpectedNames      []string
+		ExpectedValues     []string
+	}{
+		{"Replace all numeric", "value * 0.1 + offset", nil, "",
+			[]string{"temperature", "humidity", "status"}, []string{"24", "6.55", "ok"}},
+		{"Replace matching", "value ^ 2", []string{"humidity"}, "",
+			[]string{"temperature", "humidity", "status"}, []string{"215", "1640.25", "ok"}},
+		{"Create reading", "round((value - 32) * 5 / 9)", []string{"temperature"}, "temperatureCelsius",
+			[]string{"temperature", "temperatureCelsius", "humidity", "status"}, []string{"215", "102", "40.5", "ok"}},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform, err := NewExpression(testCase.Expression, map[string]float64{"offset": 2.5}, testCase.ResourceNames, testCase.OutputResourceName)
+			require.NoError(t, err)
+
+			event := newExpressionTestEvent()
+			originalValue := event.Readings[0].Value
+
+			continuePipeline, result := transform.Evaluate(ctx, event)
+			require.True(t, continuePipeline)
+			actual, ok := result.(dtos.Event)
+			require.True(t, ok)
+
+			require.Len(t, actual.Readings, len(testCase.ExpectedNames))
+			for index, reading := range actual.Readings {
+				assert.Equal(t, testCase.ExpectedNames[index], reading.ResourceName)
+
+				switch reading.ValueType {
+				case common.ValueTypeString, common.ValueTypeInt32:
+					assert.Equal(t, testCase.ExpectedValues[index], reading.Value)
+				default:
+					assert.Equal(t, common.ValueTypeFloat64, reading.ValueType)
+					expected, err := strconv.ParseFloat(testCase.ExpectedValues[index], 64)
+					require.NoError(t, err)
+					value, err := strconv.ParseFloat(reading.Value, 64)
+					require.NoError(t, err)
+					assert.InDelta(t, expected, value, 0.0001)
+				}
+			}
+
+			// Readings of the Event received must not be modified
+			assert.Equal(t, originalValue, event.Readings[0].Value)
+		})
+	}
+}
+
+func TestExpressionEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Expression string
+		Data       interface{}
+	}{
+		{"No data", "value", nil},
+		{"Not an Event", "value", "not an event"},
+		{"Division by zero", "value / 0", newExpressionTestEvent()},
+		{"Not a finite number", "sqrt(-value)", newExpressionTestEvent()},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			transform, err := NewExpression(testCase.Expression, nil, nil, "")
+			require.NoError(t, err)
+
+			continuePipeline, result := transform.Evaluate(ctx, testCase.Data)
+			assert.False(t, continuePipeline)
+			assert.IsType(t, errors.New(""), result)
+		})
+	}
+}
@@ -18,6 +18,8 @@ package transforms
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 
@@ -25,10 +27,21 @@ import (
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 )
 
+const (
+	// FilterMatchExact matches names equal to one of the FilterValues
+	FilterMatchExact = "exact"
+	// FilterMatchRegex treats the FilterValues as regular expressions which must match the whole name
+	FilterMatchRegex = "regex"
+	// FilterMatchGlob treats the FilterValues as glob patterns where * matches any characters and ? matches one
+	FilterMatchGlob = "glob"
+)
+
 // Filter houses various the parameters for which filter transforms filter on
 type Filter struct {
 	FilterValues []string
 	FilterOut    bool
+	// patterns are the compiled FilterValues when matching by regular expression or glob pattern
+	patterns []*regexp.Regexp
 }
 
 // NewFilterFor creates, initializes and returns a new instance of Filter
@@ -43,6 +56,41 @@ func NewFilterOut(filterValues []string) Filter {
 	return Filter{FilterValues: filterValues, FilterOut: true}
 }
 
+// NewPatternFilter creates, initializes and returns a new instance of Filter which matches names using the specified
+// match mode, i.e. FilterMatchRegex, so FilterValues such as "building-3-floor-.*" match multiple names.
+// An error is returned if the match mode is unknown or one of the FilterValues isn't a valid pattern.
+func NewPatternFilter(filterValues []string, filterOut bool, matchMode string) (Filter, error) {
+	filter := Filter{FilterValues: filterValues, FilterOut: filterOut}
+
+	switch strings.ToLower(matchMode) {
+	case FilterMatchExact, "":
+		return filter, nil
+
+	case FilterMatchRegex:
+		for _, value := range filterValues {
+			pattern, err := regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return Filter{}, fmt.Errorf("invalid filter regular expression '%s': %s", value, err.Error())
+			}
+			filter.patterns = append(filter.patterns, pattern)
+		}
+
+	case FilterMatchGlob:
+		for _, value := range filterValues {
+			expression := regexp.QuoteMeta(value)
+			expression = strings.ReplaceAll(expression, `\*`, ".*")
+			expression = strings.ReplaceAll(expression, `\?`, ".")
+			filter.patterns = append(filter.patterns, regexp.MustCompile("^"+expression+"$"))
+		}
+
+	default:
+		return Filter{}, fmt.Errorf("invalid filter match mode '%s'. Must be '%s', '%s' or '%s'",
+			matchMode, FilterMatchExact, FilterMatchRegex, FilterMatchGlob)
+	}
+
+	return filter, nil
+}
+
 // FilterByProfileName filters based on the specified Device Profile, aka Class of Device.
 // If FilterOut is false, it filters out those Events not associated with the specified Device Profile listed in FilterValues.
 // If FilterOut is true, it out those Events that are associated with the specified Device Profile listed in FilterValues.
@@ -57,6 +105,7 @@ func (f Filter) FilterByProfileName(ctx interfaces.AppFunctionContext, data inte
 		return true, *event
 	}
 
+	ctx.SetFiltered(fmt.Sprintf("ProfileName '%s' not accepted", event.ProfileName))
 	return false, nil
 
 }
@@ -75,6 +124,7 @@ func (f Filter) FilterByDeviceName(ctx interfaces.AppFunctionContext, data inter
 		return true, *event
 	}
 
+	ctx.SetFiltered(fmt.Sprintf("DeviceName '%s' not accepted", event.DeviceName))
 	return false, nil
 }
 
@@ -92,6 +142,7 @@ func (f Filter) FilterBySourceName(ctx interfaces.AppFunctionContext, data inter
 		return true, *event
 	}
 
+	ctx.SetFiltered(fmt.Sprintf("SourceName '%s' not accepted", event.SourceName))
 	return false, nil
 }
 
@@ -118,15 +169,7 @@ func (f Filter) FilterByResourceName(ctx interfaces.AppFunctionContext, data int
 
 	if f.FilterOut {
 		for _, reading := range existingEvent.Readings {
-			readingFilteredOut := false
-			for _, name := range f.FilterValues {
-				if reading.ResourceName == name {
-					readingFilteredOut = true
-					break
-				}
-			}
-
-			if !readingFilteredOut {
+			if !f.matches(reading.ResourceName) {
 				ctx.LoggingClient().Debugf("Reading accepted: %s", reading.ResourceName)
 				auxEvent.Readings = append(auxEvent.Readings, reading)
 			} else {
@@ -135,15 +178,7 @@ func (f Filter) FilterByResourceName(ctx interfaces.AppFunctionContext, data int
 		}
 	} else {
 		for _, reading := range existingEvent.Readings {
-			readingFilteredFor := false
-			for _, name := range f.FilterValues {
-				if reading.ResourceName == name {
-					readingFilteredFor = true
-					break
-				}
-			}
-
-			if readingFilteredFor {
+			if f.matches(reading.ResourceName) {
 				ctx.LoggingClient().Debugf("Reading accepted: %s", reading.ResourceName)
 				auxEvent.Readings = append(auxEvent.Readings, reading)
 			} else {
@@ -158,6 +193,7 @@ func (f Filter) FilterByResourceName(ctx interfaces.AppFunctionContext, data int
 	}
 
 	ctx.LoggingClient().Debug("Event not accepted: 0 remaining readings")
+	ctx.SetFiltered("no readings remaining")
 	return false, nil
 }
 
@@ -186,19 +222,17 @@ func (f Filter) doEventFilter(filterProperty string, value string, lc logger.Log
 		return true
 	}
 
-	for _, name := range f.FilterValues {
-		if value == name {
-			if f.FilterOut {
-				lc.Debugf("Event not accepted for %s=%s", filterProperty, value)
-				return false
-			} else {
-				lc.Debugf("Event accepted for %s=%s", filterProperty, value)
-				return true
-			}
+	if f.matches(value) {
+		if f.FilterOut {
+			lc.Debugf("Event not accepted for %s=%s", filterProperty, value)
+			return false
+		} else {
+			lc.Debugf("Event accepted for %s=%s", filterProperty, value)
+			return true
 		}
 	}
 
-	// Will only get here if Event's SourceName didn't match any names in FilterValues
+	// Will only get here if value didn't match any names in FilterValues
 	if f.FilterOut {
 		lc.Debugf("Event accepted for %s=%s", filterProperty, value)
 		return true
@@ -207,3 +241,23 @@ func (f Filter) doEventFilter(filterProperty string, value string, lc logger.Log
 		return false
 	}
 }
+
+// matches returns true if the value matches one of the FilterValues
+func (f Filter) matches(value string) bool {
+	if f.patterns != nil {
+		for _, pattern := range f.patterns {
+			if pattern.MatchString(value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, name := range f.FilterValues {
+		if value == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -92,6 +92,24 @@ func TestFilter_FilterByProfileName(t *testing.T) {
 	}
 }
 
+func TestFilter_MarksFiltered(t *testing.T) {
+	filter := NewFilterFor([]string{deviceName1})
+	ctx.ClearFiltered()
+	defer ctx.ClearFiltered()
+
+	continuePipeline, _ := filter.FilterByDeviceName(ctx, dtos.NewEvent(profileName1, deviceName1, sourceName1))
+	require.True(t, continuePipeline)
+	_, _, filtered := ctx.Filtered()
+	assert.False(t, filtered)
+
+	continuePipeline, result := filter.FilterByDeviceName(ctx, dtos.NewEvent(profileName1, deviceName2, sourceName1))
+	require.False(t, continuePipeline)
+	assert.Nil(t, result)
+	_, reason, filtered := ctx.Filtered()
+	assert.True(t, filtered)
+	assert.Contains(t, reason, deviceName2)
+}
+
 func TestFilter_FilterByDeviceName(t *testing.T) {
 	device1Event := dtos.NewEvent(profileName1, deviceName1, sourceName1)
 
@@ -278,3 +296,93 @@ func TestFilter_FilterByResourceName(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPatternFilter(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Values        []string
+		MatchMode     string
+		ErrorContains string
+	}{
+		{"exact", []string{"device1"}, FilterMatchExact, ""},
+		{"default", []string{"device1"}, "", ""},
+		{"regex", []string{"building-3-floor-.*"}, "Regex", ""},
+		{"glob", []string{"building-?-floor-*"}, FilterMatchGlob, ""},
+		{"invalid regex", []string{"building-[3"}, FilterMatchRegex, "building-[3"},
+		{"invalid match mode", []string{"device1"}, "fuzzy", "fuzzy"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := NewPatternFilter(test.Values, false, test.MatchMode)
+			if len(test.ErrorContains) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ErrorContains)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFilter_Patterns(t *testing.T) {
+	floorEvent := dtos.NewEvent(profileName1, "building-3-floor-12", sourceName1)
+	roofEvent := dtos.NewEvent(profileName1, "building-3-roof", sourceName1)
+	literalEvent := dtos.NewEvent(profileName1, "building-3-floor-.*", sourceName1)
+
+	tests := []struct {
+		Name      string
+		Filters   []string
+		MatchMode string
+		FilterOut bool
+		EventIn   dtos.Event
+		Expected  bool
+	}{
+		{"regex for - match", []string{"building-3-floor-.*"}, FilterMatchRegex, false, floorEvent, true},
+		{"regex for - no match", []string{"building-3-floor-.*"}, FilterMatchRegex, false, roofEvent, false},
+		{"regex for - partial match not accepted", []string{"floor"}, FilterMatchRegex, false, floorEvent, false},
+		{"regex for - alternation", []string{"building-3-(roof|basement)"}, FilterMatchRegex, false, roofEvent, true},
+		{"regex out - match", []string{"building-3-floor-[0-9]+"}, FilterMatchRegex, true, floorEvent, false},
+		{"regex out - no match", []string{"building-3-floor-[0-9]+"}, FilterMatchRegex, true, roofEvent, true},
+		{"glob for - match", []string{"building-?-floor-*"}, FilterMatchGlob, false, floorEvent, true},
+		{"glob for - no match", []string{"building-?-floor-*"}, FilterMatchGlob, false, roofEvent, false},
+		{"glob for - dot is literal", []string{"building.3*"}, FilterMatchGlob, false, floorEvent, false},
+		{"exact for - pattern is literal", []string{"building-3-floor-.*"}, FilterMatchExact, false, floorEvent, false},
+		{"exact for - literal match", []string{"building-3-floor-.*"}, FilterMatchExact, false, literalEvent, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			filter, err := NewPatternFilter(test.Filters, test.FilterOut, test.MatchMode)
+			require.NoError(t, err)
+
+			continuePipeline, _ := filter.FilterByDeviceName(ctx, test.EventIn)
+			assert.Equal(t, test.Expected, continuePipeline)
+		})
+	}
+}
+
+func TestFilter_PatternsByResourceName(t *testing.T) {
+	event := dtos.NewEvent(profileName1, deviceName1, sourceName1)
+	_ = event.AddSimpleReading("temperature-inlet", common.ValueTypeInt32, int32(1))
+	_ = event.AddSimpleReading("temperature-outlet", common.ValueTypeInt32, int32(2))
+	_ = event.AddSimpleReading("humidity", common.ValueTypeInt32, int32(3))
+
+	filter, err := NewPatternFilter([]string{"temperature-*"}, false, FilterMatchGlob)
+	require.NoError(t, err)
+	continuePipeline, result := filter.FilterByResourceName(ctx, event)
+	require.True(t, continuePipeline)
+	readings := result.(dtos.Event).Readings
+	require.Len(t, readings, 2)
+	assert.Equal(t, "temperature-inlet", readings[0].ResourceName)
+	assert.Equal(t, "temperature-outlet", readings[1].ResourceName)
+
+	filter, err = NewPatternFilter([]string{"temperature-.*"}, true, FilterMatchRegex)
+	require.NoError(t, err)
+	continuePipeline, result = filter.FilterByResourceName(ctx, event)
+	require.True(t, continuePipeline)
+	readings = result.(dtos.Event).Readings
+	require.Len(t, readings, 1)
+	assert.Equal(t, "humidity", readings[0].ResourceName)
+}
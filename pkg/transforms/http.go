@@ -23,6 +23,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
@@ -41,8 +42,18 @@ type HTTPSender struct {
 	secretName          string
 	secretPath          string
 	urlFormatter        StringValuesFormatter
+	latencyHeaders      bool
+	headers             map[string]string
+	verifyURL           string
+	verifyContains      string
+	checksum            string
+	limiter             *sendLimiter
 }
 
+// SecretHeaderPrefix is the prefix of Headers values which are references to secrets in the form
+// 'secret://<path>#<name>', which are replaced by the secret value when sending
+const SecretHeaderPrefix = "secret://"
+
 // NewHTTPSender creates, initializes and returns a new instance of HTTPSender
 func NewHTTPSender(url string, mimeType string, persistOnError bool) HTTPSender {
 	return NewHTTPSenderWithOptions(HTTPSenderOptions{
@@ -76,6 +87,12 @@ func NewHTTPSenderWithOptions(options HTTPSenderOptions) HTTPSender {
 		secretName:          options.SecretName,
 		secretPath:          options.SecretPath,
 		urlFormatter:        options.URLFormatter,
+		latencyHeaders:      options.LatencyHeaders,
+		headers:             options.Headers,
+		verifyURL:           options.VerifyURL,
+		verifyContains:      options.VerifyContains,
+		checksum:            options.Checksum,
+		limiter:             newSendLimiter(options.MaxConcurrency),
 	}
 }
 
@@ -101,6 +118,25 @@ type HTTPSenderOptions struct {
 	ContinueOnSendError bool
 	// ReturnInputData enables chaining multiple HTTP senders if true
 	ReturnInputData bool
+	// LatencyHeaders adds the X-Queue-Time-Ms and X-Processing-Time-Ms headers, so the receiver can compute the
+	// end-to-end latency including the time spent on the bus and in Store and Forward
+	LatencyHeaders bool
+	// MaxConcurrency, when positive, is the most requests in flight to the destination from concurrently executing
+	// pipelines. Further requests wait and are sent in the order they were made. Zero doesn't limit the requests.
+	MaxConcurrency int
+	// Headers are additional headers to send with the request. Values in the form 'secret://<path>#<name>' are
+	// replaced with the value of the named secret at the path in the Secret Store each time the data is sent.
+	Headers map[string]string
+	// VerifyURL, when specified, is requested with GET after the data is sent to confirm the destination accepted it.
+	// The data is only considered delivered, and removed from Store and Forward, when the response status is 2xx.
+	// The URL is formatted as for URL and the request has the same headers as the export.
+	VerifyURL string
+	// VerifyContains, when specified, must also be contained in the body of the VerifyURL response. It is formatted
+	// as for URL, so may contain placeholders such as '{correlationid}'.
+	VerifyContains string
+	// Checksum, when specified, is the algorithm, crc32 or sha256, of the checksum of the data sent in the
+	// X-Checksum-CRC32 or X-Checksum-SHA256 header, so the receiver can detect truncated or corrupted data
+	Checksum string
 }
 
 // HTTPPost will send data from the previous function to the specified Endpoint via http POST.
@@ -135,6 +171,11 @@ func (sender HTTPSender) httpSend(ctx interfaces.AppFunctionContext, data interf
 		return false, errors.New("continueOnSendError can only be used in conjunction returnInputData for multiple HTTP Export")
 	}
 
+	if sender.mimeType == "" {
+		// i.e. the media type of a binary payload or XML set by a previous function
+		sender.mimeType = ctx.ResponseContentType()
+	}
+
 	if sender.mimeType == "" {
 		sender.mimeType = "application/json"
 	}
@@ -162,10 +203,14 @@ func (sender HTTPSender) httpSend(ctx interfaces.AppFunctionContext, data interf
 	}
 
 	client := &http.Client{}
-	req, err := http.NewRequest(method, parsedUrl.String(), bytes.NewReader(exportData))
+	req, err := http.NewRequestWithContext(ctx.Context(), method, parsedUrl.String(), bytes.NewReader(exportData))
 	if err != nil {
 		return false, err
 	}
+	if err := sender.setHeaders(ctx, req); err != nil {
+		return false, err
+	}
+
 	var theSecrets map[string]string
 	if usingSecrets {
 		theSecrets, err = ctx.GetSecret(sender.secretPath, sender.secretName)
@@ -183,8 +228,33 @@ func (sender HTTPSender) httpSend(ctx interfaces.AppFunctionContext, data interf
 
 	req.Header.Set("Content-Type", sender.mimeType)
 
+	if len(sender.checksum) > 0 {
+		checksum, err := computeChecksum(sender.checksum, exportData)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set(checksumHeader(sender.checksum), checksum)
+	}
+
+	if sender.latencyHeaders {
+		queueTime, processingTime := latencyAnnotations(ctx)
+		if len(queueTime) > 0 {
+			req.Header.Set(QueueTimeHeader, queueTime)
+		}
+		if len(processingTime) > 0 {
+			req.Header.Set(ProcessingTimeHeader, processingTime)
+		}
+	}
+
 	ctx.LoggingClient().Debugf("POSTing data to %s", sender.url)
 
+	// The slot is held until the response body is read, as the request is in flight until then
+	release, err := sender.limiter.acquire(ctx.Context())
+	if err != nil {
+		return sender.sendFailed(ctx, data, exportData, fmt.Errorf("export failed: %w", err))
+	}
+	defer release()
+
 	response, err := client.Do(req)
 	// Pipeline continues if we get a 2xx response, non-2xx response may stop pipeline
 	if err != nil || response.StatusCode < 200 || response.StatusCode >= 300 {
@@ -194,21 +264,18 @@ func (sender HTTPSender) httpSend(ctx interfaces.AppFunctionContext, data interf
 			err = fmt.Errorf("export failed: %w", err)
 		}
 
-		// If continuing on send error then can't be persisting on error since Store and Forward retries starting
-		// with the function that failed and stopped the execution of the pipeline.
-		if !sender.continueOnSendError {
-			sender.setRetryData(ctx, exportData)
-			return false, err
-		}
-
-		// Continuing pipeline on error
-		// This is in support of sending to multiple export destinations by chaining export functions in the pipeline.
-		ctx.LoggingClient().Errorf("Continuing pipeline on error: %s", err.Error())
+		return sender.sendFailed(ctx, data, exportData, err)
+	}
 
-		// Return the input data since must have some data for the next function to operate on.
-		return true, data
+	if len(sender.verifyURL) > 0 {
+		if err := sender.verify(ctx, client, req.Header, data); err != nil {
+			_ = response.Body.Close()
+			return sender.sendFailed(ctx, data, exportData, err)
+		}
 	}
 
+	recordProcessingTime(ctx)
+
 	ctx.LoggingClient().Debugf("Sent %s bytes of data. Response status is %s", len(exportData), response.Status)
 	ctx.LoggingClient().Trace("Data exported", "Transport", "HTTP", common.CorrelationHeader, ctx.CorrelationID())
 
@@ -229,6 +296,72 @@ func (sender HTTPSender) httpSend(ctx interfaces.AppFunctionContext, data interf
 	return true, responseData
 }
 
+// sendFailed handles the export failing, either stopping the pipeline with the data to retry or continuing it
+func (sender HTTPSender) sendFailed(ctx interfaces.AppFunctionContext, data interface{}, exportData []byte, err error) (bool, interface{}) {
+	// If continuing on send error then can't be persisting on error since Store and Forward retries starting
+	// with the function that failed and stopped the execution of the pipeline.
+	if !sender.continueOnSendError {
+		sender.setRetryData(ctx, exportData)
+		return false, err
+	}
+
+	// Continuing pipeline on error
+	// This is in support of sending to multiple export destinations by chaining export functions in the pipeline.
+	ctx.LoggingClient().Errorf("Continuing pipeline on error: %s", err.Error())
+
+	// Return the input data since must have some data for the next function to operate on.
+	return true, data
+}
+
+// verify reads back from the VerifyURL to confirm the destination accepted the exported data
+func (sender HTTPSender) verify(ctx interfaces.AppFunctionContext, client *http.Client, header http.Header, data interface{}) error {
+	verifyUrl, err := sender.urlFormatter.invoke(sender.verifyURL, ctx, data)
+	if err != nil {
+		return err
+	}
+
+	parsedUrl, err := url.Parse(verifyUrl)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx.Context(), http.MethodGet, parsedUrl.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header.Clone()
+	req.Header.Del("Content-Type")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("export verification failed: %w", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("export verification failed with %d HTTP status code", response.StatusCode)
+	}
+
+	if len(sender.verifyContains) > 0 {
+		expected, err := sender.urlFormatter.invoke(sender.verifyContains, ctx, data)
+		if err != nil {
+			return err
+		}
+
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return fmt.Errorf("export verification failed reading response: %w", err)
+		}
+
+		if !strings.Contains(string(body), expected) {
+			return fmt.Errorf("export verification failed: response from %s does not contain '%s'", verifyUrl, expected)
+		}
+	}
+
+	ctx.LoggingClient().Debugf("Export verified with %s", verifyUrl)
+	return nil
+}
+
 func (sender HTTPSender) determineIfUsingSecrets() (bool, error) {
 	// not using secrets if both are empty
 	if len(sender.secretPath) == 0 && len(sender.secretName) == 0 {
@@ -255,6 +388,38 @@ func (sender HTTPSender) determineIfUsingSecrets() (bool, error) {
 	return true, nil
 }
 
+// setHeaders sets the configured Headers on the request, retrieving the values which reference secrets
+func (sender HTTPSender) setHeaders(ctx interfaces.AppFunctionContext, req *http.Request) error {
+	for name, value := range sender.headers {
+		if strings.HasPrefix(value, SecretHeaderPrefix) {
+			reference := strings.TrimPrefix(value, SecretHeaderPrefix)
+			separator := strings.LastIndex(reference, "#")
+			if separator <= 0 || separator == len(reference)-1 {
+				return fmt.Errorf("HTTP Header '%s' secret reference '%s' must be in the form '%s<path>#<name>'",
+					name, value, SecretHeaderPrefix)
+			}
+
+			secretPath := reference[:separator]
+			secretName := reference[separator+1:]
+			secrets, err := ctx.GetSecret(secretPath, secretName)
+			if err != nil {
+				return err
+			}
+
+			ctx.LoggingClient().Debugf("Setting HTTP Header '%s' with secret value from SecretStore at path='%s' & name='%s'",
+				name,
+				secretPath,
+				secretName)
+
+			value = secrets[secretName]
+		}
+
+		req.Header.Set(name, value)
+	}
+
+	return nil
+}
+
 func (sender HTTPSender) setRetryData(ctx interfaces.AppFunctionContext, exportData []byte) {
 	if sender.persistOnError {
 		ctx.SetRetryData(exportData)
@@ -17,14 +17,19 @@
 package transforms
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
@@ -32,6 +37,9 @@ import (
 	mocks2 "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 )
 
 const (
@@ -46,6 +54,9 @@ const (
 func TestHTTPPostPut(t *testing.T) {
 	var methodUsed string
 
+	// Content type set by previous functions is used when the mime type isn't set
+	ctx.SetResponseContentType("")
+
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		methodUsed = r.Method
 
@@ -139,6 +150,297 @@ func TestHTTPPostPut(t *testing.T) {
 	}
 }
 
+func TestHTTPPostLatencyHeaders(t *testing.T) {
+	var headers http.Header
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.WriteHeader(http.StatusOK)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	ctx.AddValue(interfaces.QUEUETIME, "1500")
+	ctx.AddValue(interfaces.PIPELINESTART, strconv.FormatInt(time.Now().Add(-250*time.Millisecond).UnixNano(), 10))
+	defer ctx.RemoveValue(interfaces.QUEUETIME)
+	defer ctx.RemoveValue(interfaces.PIPELINESTART)
+	defer ctx.RemoveValue(interfaces.PROCESSINGTIME)
+
+	// Not added unless enabled
+	sender := NewHTTPSender(ts.URL, "", false)
+	continuePipeline, _ := sender.HTTPPost(ctx, msgStr)
+	require.True(t, continuePipeline)
+	assert.Empty(t, headers.Get(QueueTimeHeader))
+	assert.Empty(t, headers.Get(ProcessingTimeHeader))
+
+	sender = NewHTTPSenderWithOptions(HTTPSenderOptions{URL: ts.URL, LatencyHeaders: true})
+	continuePipeline, _ = sender.HTTPPost(ctx, msgStr)
+	require.True(t, continuePipeline)
+	assert.Equal(t, "1500", headers.Get(QueueTimeHeader))
+	processingTime, err := strconv.ParseInt(headers.Get(ProcessingTimeHeader), 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, processingTime, int64(250))
+
+	// The processing time is recorded once the export completes
+	value, found := ctx.GetValue(interfaces.PROCESSINGTIME)
+	require.True(t, found)
+	recorded, err := strconv.ParseInt(value, 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, recorded, processingTime)
+}
+
+func TestHTTPPostChecksum(t *testing.T) {
+	var headers http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	sender := NewHTTPSenderWithOptions(HTTPSenderOptions{URL: ts.URL, Checksum: ChecksumCRC32})
+	continuePipeline, _ := sender.HTTPPost(ctx, "123456789")
+	require.True(t, continuePipeline)
+	assert.Equal(t, "cbf43926", headers.Get("X-Checksum-CRC32"))
+
+	headers = nil
+	sender = NewHTTPSenderWithOptions(HTTPSenderOptions{URL: ts.URL, Checksum: "md5"})
+	continuePipeline, result := sender.HTTPPost(ctx, "123456789")
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+	assert.Nil(t, headers, "request should not have been sent")
+}
+
+func TestHTTPPostDeadline(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Stuck until the test completes
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	timeout, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.SetContext(timeout)
+
+	sender := NewHTTPSenderWithOptions(HTTPSenderOptions{URL: ts.URL, PersistOnError: true})
+	continuePipeline, result := sender.HTTPPost(appContext, "data")
+	require.False(t, continuePipeline)
+	err, ok := result.(error)
+	require.True(t, ok)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, []byte("data"), appContext.RetryData())
+}
+
+func TestHTTPPostHeaders(t *testing.T) {
+	var headers http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mockSP := &mocks2.SecretProvider{}
+	mockSP.On("GetSecret", "cloud", "apikey").Return(map[string]string{"apikey": "my-API-key"}, nil)
+	mockSP.On("GetSecret", "bogus", "apikey").Return(nil, errors.New("FAKE NOT FOUND ERROR"))
+
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	tests := []struct {
+		Name            string
+		Headers         map[string]string
+		ExpectedHeaders map[string]string
+		ExpectError     bool
+	}{
+		{"Static", map[string]string{"X-Api-Version": "2"}, map[string]string{"X-Api-Version": "2"}, false},
+		{"Static and secret",
+			map[string]string{"X-Api-Version": "2", "X-Api-Key": "secret://cloud#apikey"},
+			map[string]string{"X-Api-Version": "2", "X-Api-Key": "my-API-key"},
+			false},
+		{"Secret not found", map[string]string{"X-Api-Key": "secret://bogus#apikey"}, nil, true},
+		{"Secret name missing", map[string]string{"X-Api-Key": "secret://cloud"}, nil, true},
+		{"Secret path missing", map[string]string{"X-Api-Key": "secret://#apikey"}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			headers = nil
+			sender := NewHTTPSenderWithOptions(HTTPSenderOptions{URL: ts.URL, Headers: test.Headers})
+			continuePipeline, result := sender.HTTPPost(ctx, msgStr)
+
+			if test.ExpectError {
+				assert.False(t, continuePipeline)
+				assert.Error(t, result.(error))
+				assert.Nil(t, headers, "request should not have been sent")
+				return
+			}
+
+			require.True(t, continuePipeline)
+			for name, value := range test.ExpectedHeaders {
+				assert.Equal(t, value, headers.Get(name))
+			}
+		})
+	}
+}
+
+func TestHTTPPostVerify(t *testing.T) {
+	var stored string
+	var verifyHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			stored = string(body)
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/stored":
+			verifyHeader = r.Header.Get("X-Api-Version")
+			_, _ = w.Write([]byte(stored))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	ctx.AddValue("expected", msgStr)
+	defer ctx.RemoveValue("expected")
+
+	tests := []struct {
+		Name                string
+		VerifyURL           string
+		VerifyContains      string
+		ContinueOnSendError bool
+		ExpectVerified      bool
+	}{
+		{"Verified", ts.URL + "/stored", "", false, true},
+		{"Verified - contains", ts.URL + "/stored", msgStr, false, true},
+		{"Verified - contains placeholder", ts.URL + "/stored", "{expected}", false, true},
+		{"Not found", ts.URL + "/missing", "", false, false},
+		{"Not contained", ts.URL + "/stored", "bogus", false, false},
+		{"Not verified - continue on send error", ts.URL + "/missing", "", true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stored = ""
+			verifyHeader = ""
+			ctx.SetRetryData(nil)
+
+			sender := NewHTTPSenderWithOptions(HTTPSenderOptions{
+				URL:                 ts.URL,
+				PersistOnError:      !test.ContinueOnSendError,
+				ContinueOnSendError: test.ContinueOnSendError,
+				ReturnInputData:     true,
+				Headers:             map[string]string{"X-Api-Version": "2"},
+				VerifyURL:           test.VerifyURL,
+				VerifyContains:      test.VerifyContains,
+			})
+			continuePipeline, result := sender.HTTPPost(ctx, msgStr)
+
+			if test.ExpectVerified {
+				require.True(t, continuePipeline)
+				assert.Equal(t, msgStr, result)
+				assert.Equal(t, "2", verifyHeader)
+				assert.Nil(t, ctx.RetryData())
+				return
+			}
+
+			if test.ContinueOnSendError {
+				assert.True(t, continuePipeline)
+				assert.Equal(t, msgStr, result)
+				assert.Nil(t, ctx.RetryData())
+				return
+			}
+
+			assert.False(t, continuePipeline)
+			require.Error(t, result.(error))
+			assert.Contains(t, result.(error).Error(), "verification failed")
+			assert.NotNil(t, ctx.RetryData())
+		})
+	}
+}
+
+func TestHTTPPostMaxConcurrency(t *testing.T) {
+	var inFlight, mostInFlight int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			most := atomic.LoadInt32(&mostInFlight)
+			if current <= most || atomic.CompareAndSwapInt32(&mostInFlight, most, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	sender := NewHTTPSenderWithOptions(HTTPSenderOptions{URL: ts.URL, MaxConcurrency: 2, ReturnInputData: true})
+
+	var wg sync.WaitGroup
+	for index := 0; index < 6; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			continuePipeline, result := sender.HTTPPost(appfunction.NewContext("123", dic, ""), msgStr)
+			assert.True(t, continuePipeline, result)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&mostInFlight))
+}
+
+func TestHTTPPostContentType(t *testing.T) {
+	image := []byte{0xff, 0xd8, 0x00, 0x80}
+	var contentTypeReceived string
+	var dataReceived []byte
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		contentTypeReceived = r.Header.Get("Content-Type")
+		dataReceived, _ = io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		w.WriteHeader(http.StatusOK)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	tests := []struct {
+		Name                string
+		MimeType            string
+		ResponseContentType string
+		Expected            string
+	}{
+		{"Default", "", "", "application/json"},
+		{"Response content type", "", "image/jpeg", "image/jpeg"},
+		{"Configured mime type", "application/octet-stream", "image/jpeg", "application/octet-stream"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx.SetResponseContentType(test.ResponseContentType)
+			sender := NewHTTPSender(ts.URL, test.MimeType, false)
+
+			continuePipeline, _ := sender.HTTPPost(ctx, image)
+			require.True(t, continuePipeline)
+
+			assert.Equal(t, test.Expected, contentTypeReceived)
+			assert.Equal(t, image, dataReceived, "binary payload should be sent as is")
+		})
+	}
+
+	ctx.SetResponseContentType("")
+}
+
 func TestHTTPPostPutWithSecrets(t *testing.T) {
 	var methodUsed string
 
@@ -0,0 +1,305 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+)
+
+const (
+	// ImageFormatJPEG re-encodes images as JPEG
+	ImageFormatJPEG = "jpeg"
+	// ImageFormatPNG re-encodes images as PNG
+	ImageFormatPNG = "png"
+
+	imageMediaTypeJPEG = "image/jpeg"
+	imageMediaTypePNG  = "image/png"
+
+	// minImageQuality is the lowest JPEG quality used when capping the size of an image
+	minImageQuality = 10
+	// imageQualityStep is how much the JPEG quality is reduced by at a time when capping the size of an image
+	imageQualityStep = 10
+	// imageShrinkFactor is how much the dimensions are reduced by at a time when capping the size of an image
+	// once the minimum JPEG quality has been reached
+	imageShrinkFactor = 0.75
+	// minImageDimension is the smallest width or height an image is shrunk to when capping its size
+	minImageDimension = 16
+)
+
+// ImageOptions contains all options available to the Image transforms
+type ImageOptions struct {
+	// MaxWidth and MaxHeight are the dimensions in pixels images are resized to fit within, preserving the aspect
+	// ratio. Images are never enlarged. Zero is unlimited.
+	MaxWidth  int
+	MaxHeight int
+	// Format is the format, jpeg or png, images are re-encoded to. Images keep their original format when empty.
+	Format string
+	// Quality is the JPEG quality, from 1 to 100. Defaults to 75.
+	Quality int
+	// MaxBytes caps the encoded size of images by reducing the JPEG quality and then the dimensions. Zero is unlimited.
+	MaxBytes int
+}
+
+// Image houses the transforms for shrinking the images in Events' binary readings, i.e. camera frames, so that
+// bandwidth limited pipelines can reduce them before export
+type Image struct {
+	options ImageOptions
+}
+
+// NewImage creates, initializes and returns a new instance of Image
+func NewImage(options ImageOptions) (*Image, error) {
+	if options.MaxWidth < 0 || options.MaxHeight < 0 {
+		return nil, errors.New("image MaxWidth and MaxHeight must not be negative")
+	}
+
+	if options.MaxBytes < 0 {
+		return nil, errors.New("image MaxBytes must not be negative")
+	}
+
+	if options.Quality == 0 {
+		options.Quality = jpeg.DefaultQuality
+	} else if options.Quality < 1 || options.Quality > 100 {
+		return nil, fmt.Errorf("image Quality of %d is invalid, must be from 1 to 100", options.Quality)
+	}
+
+	options.Format = strings.ToLower(options.Format)
+	switch options.Format {
+	case "", ImageFormatJPEG, ImageFormatPNG:
+	case "jpg":
+		options.Format = ImageFormatJPEG
+	default:
+		return nil, fmt.Errorf("image Format '%s' is invalid, must be '%s' or '%s'", options.Format, ImageFormatJPEG, ImageFormatPNG)
+	}
+
+	return &Image{options: options}, nil
+}
+
+// ProcessImages resizes, re-encodes and caps the size of the JPEG and PNG images in the Event's binary readings, or
+// of the image bytes received, i.e. from ExtractBinaryPayload, according to the configured options.
+// Binary readings which aren't JPEG or PNG images are left as is.
+// It will return an error and stop the pipeline if an image can not be processed, if the data received is not an
+// Event or []byte, or if no data is received.
+func (f *Image) ProcessImages(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("no Event Received")
+	}
+
+	switch value := data.(type) {
+	case []byte:
+		processed, mediaType, err := f.processImage(value)
+		if err != nil {
+			return false, err
+		}
+		ctx.SetResponseContentType(mediaType)
+		return true, processed
+
+	case dtos.Event:
+		readings := make([]dtos.BaseReading, len(value.Readings))
+		for index, reading := range value.Readings {
+			if reading.ValueType == common.ValueTypeBinary && isProcessableImage(reading.MediaType) {
+				originalSize := len(reading.BinaryValue)
+
+				var err error
+				reading.BinaryValue, reading.MediaType, err = f.processImage(reading.BinaryValue)
+				if err != nil {
+					return false, fmt.Errorf("unable to process image of '%s' reading: %s", reading.ResourceName, err.Error())
+				}
+
+				ctx.LoggingClient().Debugf("Processed image of '%s' reading from %d to %d bytes",
+					reading.ResourceName, originalSize, len(reading.BinaryValue))
+			}
+			readings[index] = reading
+		}
+
+		// The Readings are copied so that the images in the received Event aren't modified
+		value.Readings = readings
+		return true, value
+
+	default:
+		return false, errors.New("type received is not an Event or []byte")
+	}
+}
+
+// processImage returns the image resized, re-encoded and capped in size and the media type of its format
+func (f *Image) processImage(data []byte) ([]byte, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to decode image: %s", err.Error())
+	}
+
+	if len(f.options.Format) > 0 {
+		format = f.options.Format
+	}
+
+	if format != ImageFormatJPEG && format != ImageFormatPNG {
+		return nil, "", fmt.Errorf("unable to encode image format '%s'", format)
+	}
+
+	bounds := img.Bounds()
+	width, height := fitImage(bounds.Dx(), bounds.Dy(), f.options.MaxWidth, f.options.MaxHeight)
+	if width != bounds.Dx() || height != bounds.Dy() {
+		img = resizeImage(img, width, height)
+	}
+
+	quality := f.options.Quality
+	for {
+		encoded, err := encodeImage(img, format, quality)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if f.options.MaxBytes == 0 || len(encoded) <= f.options.MaxBytes {
+			return encoded, imageMediaType(format), nil
+		}
+
+		// Reduce the quality first, then the dimensions, until the image fits
+		if format == ImageFormatJPEG && quality > minImageQuality {
+			quality -= imageQualityStep
+			if quality < minImageQuality {
+				quality = minImageQuality
+			}
+			continue
+		}
+
+		bounds = img.Bounds()
+		width = int(float64(bounds.Dx()) * imageShrinkFactor)
+		height = int(float64(bounds.Dy()) * imageShrinkFactor)
+		if width < minImageDimension || height < minImageDimension {
+			return nil, "", fmt.Errorf("unable to reduce image to within %d bytes", f.options.MaxBytes)
+		}
+
+		img = resizeImage(img, width, height)
+	}
+}
+
+// isProcessableImage returns true if the media type is a format which can be processed
+func isProcessableImage(mediaType string) bool {
+	switch strings.ToLower(mediaType) {
+	case imageMediaTypeJPEG, "image/jpg", imageMediaTypePNG:
+		return true
+	}
+
+	return false
+}
+
+func imageMediaType(format string) string {
+	if format == ImageFormatPNG {
+		return imageMediaTypePNG
+	}
+
+	return imageMediaTypeJPEG
+}
+
+func encodeImage(img image.Image, format string, quality int) ([]byte, error) {
+	var buffer bytes.Buffer
+	var err error
+
+	if format == ImageFormatPNG {
+		err = png.Encode(&buffer, img)
+	} else {
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: quality})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode image as %s: %s", format, err.Error())
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// fitImage returns the dimensions which fit within the maximum dimensions while preserving the aspect ratio.
+// The dimensions are never enlarged and a maximum of zero is unlimited.
+func fitImage(width int, height int, maxWidth int, maxHeight int) (int, int) {
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight && float64(maxHeight)/float64(height) < scale {
+		scale = float64(maxHeight) / float64(height)
+	}
+
+	if scale == 1.0 {
+		return width, height
+	}
+
+	newWidth := int(float64(width) * scale)
+	newHeight := int(float64(height) * scale)
+	if newWidth < 1 {
+		newWidth = 1
+	}
+	if newHeight < 1 {
+		newHeight = 1
+	}
+
+	return newWidth, newHeight
+}
+
+// resizeImage scales the image to the dimensions, averaging the source pixels covered by each destination pixel
+func resizeImage(src image.Image, width int, height int) image.Image {
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/height
+		y1 := bounds.Min.Y + (y+1)*srcHeight/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcWidth/width
+			x1 := bounds.Min.X + (x+1)*srcWidth/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					count++
+				}
+			}
+
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+
+	return dst
+}
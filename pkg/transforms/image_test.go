@@ -0,0 +1,236 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestImage returns a noisy image, which doesn't compress well, of the specified dimensions
+func newTestImage(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	random := rand.New(rand.NewSource(1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(random.Intn(256)), G: uint8(x), B: uint8(y), A: 255})
+		}
+	}
+	return img
+}
+
+func encodeTestImage(t *testing.T, img image.Image, format string) []byte {
+	var buffer bytes.Buffer
+	if format == ImageFormatPNG {
+		require.NoError(t, png.Encode(&buffer, img))
+	} else {
+		require.NoError(t, jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 95}))
+	}
+	return buffer.Bytes()
+}
+
+func decodeTestImage(t *testing.T, data []byte) (image.Image, string) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img, format
+}
+
+func TestNewImage(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Options        ImageOptions
+		ExpectedFormat string
+		ExpectError    bool
+	}{
+		{"Defaults", ImageOptions{}, "", false},
+		{"JPEG", ImageOptions{Format: "JPEG", Quality: 50}, ImageFormatJPEG, false},
+		{"JPG", ImageOptions{Format: "jpg"}, ImageFormatJPEG, false},
+		{"PNG", ImageOptions{Format: "png", MaxWidth: 100, MaxHeight: 100, MaxBytes: 1000}, ImageFormatPNG, false},
+		{"Bad format", ImageOptions{Format: "gif"}, "", true},
+		{"Bad quality", ImageOptions{Quality: 101}, "", true},
+		{"Negative width", ImageOptions{MaxWidth: -1}, "", true},
+		{"Negative bytes", ImageOptions{MaxBytes: -1}, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := NewImage(test.Options)
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedFormat, target.options.Format)
+			assert.NotZero(t, target.options.Quality)
+		})
+	}
+}
+
+func TestProcessImagesEvent(t *testing.T) {
+	jpegImage := encodeTestImage(t, newTestImage(200, 100), ImageFormatJPEG)
+	pngImage := encodeTestImage(t, newTestImage(50, 100), ImageFormatPNG)
+	other := []byte{0x01, 0x02, 0x03}
+
+	event := dtos.NewEvent("camera", "camera1", "frames")
+	event.AddBinaryReading("jpeg", jpegImage, "image/jpeg")
+	event.AddBinaryReading("png", pngImage, "image/png")
+	event.AddBinaryReading("other", other, "application/octet-stream")
+	require.NoError(t, event.AddSimpleReading("temperature", common.ValueTypeInt32, int32(21)))
+
+	tests := []struct {
+		Name            string
+		Options         ImageOptions
+		ExpectedJPEGDim image.Point
+		ExpectedPNGDim  image.Point
+		ExpectedFormat  string
+	}{
+		{"Resize keeping format", ImageOptions{MaxWidth: 100, MaxHeight: 100}, image.Pt(100, 50), image.Pt(50, 100), ""},
+		{"Resize by height", ImageOptions{MaxHeight: 40}, image.Pt(80, 40), image.Pt(20, 40), ""},
+		{"Never enlarged", ImageOptions{MaxWidth: 1000, MaxHeight: 1000}, image.Pt(200, 100), image.Pt(50, 100), ""},
+		{"Re-encode as PNG", ImageOptions{Format: ImageFormatPNG}, image.Pt(200, 100), image.Pt(50, 100), ImageFormatPNG},
+		{"Re-encode as JPEG", ImageOptions{Format: ImageFormatJPEG, Quality: 50}, image.Pt(200, 100), image.Pt(50, 100), ImageFormatJPEG},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := NewImage(test.Options)
+			require.NoError(t, err)
+
+			continuePipeline, result := target.ProcessImages(ctx, event)
+			require.True(t, continuePipeline)
+			actual, ok := result.(dtos.Event)
+			require.True(t, ok)
+			require.Len(t, actual.Readings, 4)
+
+			jpegResult, jpegFormat := decodeTestImage(t, actual.Readings[0].BinaryValue)
+			pngResult, pngFormat := decodeTestImage(t, actual.Readings[1].BinaryValue)
+			assert.Equal(t, test.ExpectedJPEGDim, jpegResult.Bounds().Size())
+			assert.Equal(t, test.ExpectedPNGDim, pngResult.Bounds().Size())
+
+			expectedJPEGFormat, expectedPNGFormat := ImageFormatJPEG, ImageFormatPNG
+			if len(test.ExpectedFormat) > 0 {
+				expectedJPEGFormat, expectedPNGFormat = test.ExpectedFormat, test.ExpectedFormat
+			}
+			assert.Equal(t, expectedJPEGFormat, jpegFormat)
+			assert.Equal(t, expectedPNGFormat, pngFormat)
+			assert.Equal(t, imageMediaType(expectedJPEGFormat), actual.Readings[0].MediaType)
+			assert.Equal(t, imageMediaType(expectedPNGFormat), actual.Readings[1].MediaType)
+
+			assert.Equal(t, other, actual.Readings[2].BinaryValue, "non-image binary reading should not be modified")
+			assert.Equal(t, event.Readings[3], actual.Readings[3], "simple reading should not be modified")
+			assert.Equal(t, jpegImage, event.Readings[0].BinaryValue, "received Event should not be modified")
+		})
+	}
+}
+
+func TestProcessImagesMaxBytes(t *testing.T) {
+	original := encodeTestImage(t, newTestImage(320, 240), ImageFormatJPEG)
+
+	tests := []struct {
+		Name     string
+		Format   string
+		MaxBytes int
+	}{
+		{"JPEG quality reduced", ImageFormatJPEG, len(original) / 2},
+		{"JPEG dimensions reduced", ImageFormatJPEG, 4000},
+		{"PNG dimensions reduced", ImageFormatPNG, 20000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := NewImage(ImageOptions{Format: test.Format, MaxBytes: test.MaxBytes})
+			require.NoError(t, err)
+
+			continuePipeline, result := target.ProcessImages(ctx, original)
+			require.True(t, continuePipeline, "unexpected error %v", result)
+			actual, ok := result.([]byte)
+			require.True(t, ok)
+
+			assert.LessOrEqual(t, len(actual), test.MaxBytes)
+			_, format := decodeTestImage(t, actual)
+			assert.Equal(t, test.Format, format)
+			assert.Equal(t, imageMediaType(test.Format), ctx.ResponseContentType())
+		})
+	}
+
+	ctx.SetResponseContentType("")
+}
+
+func TestProcessImagesErrors(t *testing.T) {
+	target, err := NewImage(ImageOptions{MaxBytes: 10})
+	require.NoError(t, err)
+
+	corrupt := dtos.NewEvent("camera", "camera1", "frames")
+	corrupt.AddBinaryReading("jpeg", []byte{0xff, 0xd8, 0x00}, "image/jpeg")
+
+	tests := []struct {
+		Name string
+		Data interface{}
+	}{
+		{"No data", nil},
+		{"Not an Event", "bogus"},
+		{"Not an image", []byte{0x01, 0x02}},
+		{"Corrupt image", corrupt},
+		{"Can not reach MaxBytes", encodeTestImage(t, newTestImage(64, 64), ImageFormatPNG)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := target.ProcessImages(ctx, test.Data)
+			require.False(t, continuePipeline)
+			_, ok := result.(error)
+			assert.True(t, ok, "expected error result")
+		})
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Width     int
+		Height    int
+		MaxWidth  int
+		MaxHeight int
+		Expected  image.Point
+	}{
+		{"Unlimited", 640, 480, 0, 0, image.Pt(640, 480)},
+		{"Within", 640, 480, 800, 600, image.Pt(640, 480)},
+		{"Width limited", 640, 480, 320, 0, image.Pt(320, 240)},
+		{"Height limited", 640, 480, 0, 120, image.Pt(160, 120)},
+		{"Both limited", 640, 480, 320, 100, image.Pt(133, 100)},
+		{"Never zero", 1000, 1, 10, 0, image.Pt(10, 1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			width, height := fitImage(test.Width, test.Height, test.MaxWidth, test.MaxHeight)
+			assert.Equal(t, test.Expected, image.Pt(width, height))
+		})
+	}
+}
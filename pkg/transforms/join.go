@@ -0,0 +1,318 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+const (
+	// JoinPolicyAll requires every branch to complete successfully before the pipeline continues
+	JoinPolicyAll = "all"
+	// JoinPolicyPartial continues the pipeline with the results of the branches that completed successfully
+	// within the timeout, as long as at least one did
+	JoinPolicyPartial = "partial"
+)
+
+// JoinBranch is a named sequence of functions executed by Join
+type JoinBranch struct {
+	Name      string
+	Functions []interfaces.AppFunction
+}
+
+// Join scatters the pipeline data to multiple branches, waits for their results and merges them into
+// a single object before continuing the pipeline.
+type Join struct {
+	branches []JoinBranch
+	timeout  time.Duration
+	policy   string
+}
+
+type branchResult struct {
+	name   string
+	result interface{}
+	err    error
+}
+
+// NewJoin creates, initializes and returns a new instance of Join. timeout is how long to wait for the branches
+// to complete and policy is one of JoinPolicyAll or JoinPolicyPartial.
+func NewJoin(timeout time.Duration, policy string, branches ...JoinBranch) (*Join, error) {
+	if timeout <= 0 {
+		return nil, errors.New("join timeout must be greater than zero")
+	}
+
+	switch strings.ToLower(policy) {
+	case JoinPolicyAll, JoinPolicyPartial:
+	default:
+		return nil, fmt.Errorf("invalid join policy '%s'. Must be '%s' or '%s'", policy, JoinPolicyAll, JoinPolicyPartial)
+	}
+
+	if len(branches) == 0 {
+		return nil, errors.New("join requires at least one branch")
+	}
+
+	names := make(map[string]bool)
+	for _, branch := range branches {
+		if len(branch.Name) == 0 {
+			return nil, errors.New("join branch name must not be empty")
+		}
+		if names[branch.Name] {
+			return nil, fmt.Errorf("duplicate join branch name '%s'", branch.Name)
+		}
+		if len(branch.Functions) == 0 {
+			return nil, fmt.Errorf("join branch '%s' has no functions", branch.Name)
+		}
+		names[branch.Name] = true
+	}
+
+	return &Join{
+		branches: branches,
+		timeout:  timeout,
+		policy:   strings.ToLower(policy),
+	}, nil
+}
+
+// Execute runs each branch concurrently with the data passed in and merges the branch results into a
+// map keyed by branch name. Branches that stop the pipeline without an error are left out of the merged
+// result. Branches still running when the timeout expires are abandoned and their results discarded.
+// The branches share the pipeline context, access to which is serialized while they run.
+func (join *Join) Execute(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("No Data Received")
+	}
+
+	lc := ctx.LoggingClient()
+	lc.Debugf("Joining %d branches", len(join.branches))
+
+	branchCtx := &lockedContext{AppFunctionContext: ctx}
+
+	// Buffered so abandoned branches can always deliver their result and exit
+	results := make(chan branchResult, len(join.branches))
+	for _, branch := range join.branches {
+		go func(branch JoinBranch) {
+			results <- runBranch(branchCtx, branch, data)
+		}(branch)
+	}
+
+	merged := make(map[string]interface{})
+	var failures []string
+	timer := time.NewTimer(join.timeout)
+	defer timer.Stop()
+
+	pending := make(map[string]bool)
+	for _, branch := range join.branches {
+		pending[branch.Name] = true
+	}
+
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			delete(pending, result.name)
+			if result.err != nil {
+				failures = append(failures, fmt.Sprintf("branch '%s': %s", result.name, result.err.Error()))
+				continue
+			}
+			if result.result != nil {
+				merged[result.name] = result.result
+			}
+
+		case <-timer.C:
+			for name := range pending {
+				failures = append(failures, fmt.Sprintf("branch '%s': timed out after %s", name, join.timeout))
+			}
+			pending = nil
+		}
+	}
+
+	// Abandoned branches may still be using the context, so block them from touching it from here on
+	branchCtx.detach()
+
+	if len(failures) > 0 {
+		if join.policy == JoinPolicyAll || len(merged) == 0 {
+			return false, fmt.Errorf("join failed: %s", strings.Join(failures, "; "))
+		}
+
+		lc.Warnf("Join continuing with partial results: %s", strings.Join(failures, "; "))
+	}
+
+	if len(merged) == 0 {
+		lc.Debug("No join branch produced a result. Pipeline execution terminating")
+		ctx.SetFiltered("no join branch produced a result")
+		return false, nil
+	}
+
+	return true, merged
+}
+
+func runBranch(ctx interfaces.AppFunctionContext, branch JoinBranch, data interface{}) branchResult {
+	result := data
+	for _, function := range branch.Functions {
+		var continuePipeline bool
+		continuePipeline, result = function(ctx, result)
+		if !continuePipeline {
+			if err, ok := result.(error); ok {
+				return branchResult{name: branch.Name, err: err}
+			}
+			return branchResult{name: branch.Name}
+		}
+	}
+
+	// JSON byte results are kept as raw JSON so the merged result marshals naturally
+	if bytes, ok := result.([]byte); ok && json.Valid(bytes) {
+		result = json.RawMessage(bytes)
+	}
+
+	return branchResult{name: branch.Name, result: result}
+}
+
+// lockedContext serializes access to the pipeline context's mutable state so it can be shared by
+// concurrently executing branches.
+type lockedContext struct {
+	interfaces.AppFunctionContext
+	mutex    sync.Mutex
+	detached bool
+}
+
+func (c *lockedContext) detach() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.detached = true
+}
+
+func (c *lockedContext) InputContentType() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.AppFunctionContext.InputContentType()
+}
+
+func (c *lockedContext) SetResponseData(data []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.detached {
+		c.AppFunctionContext.SetResponseData(data)
+	}
+}
+
+func (c *lockedContext) AddResponseData(data []byte, contentType string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.detached {
+		c.AppFunctionContext.AddResponseData(data, contentType)
+	}
+}
+
+func (c *lockedContext) ResponseData() []byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.detached {
+		return nil
+	}
+	return c.AppFunctionContext.ResponseData()
+}
+
+func (c *lockedContext) SetResponseContentType(contentType string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.detached {
+		c.AppFunctionContext.SetResponseContentType(contentType)
+	}
+}
+
+func (c *lockedContext) ResponseContentType() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.detached {
+		return ""
+	}
+	return c.AppFunctionContext.ResponseContentType()
+}
+
+func (c *lockedContext) SetResponseTopic(topic string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.detached {
+		c.AppFunctionContext.SetResponseTopic(topic)
+	}
+}
+
+func (c *lockedContext) ResponseTopic() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.AppFunctionContext.ResponseTopic()
+}
+
+// SetFiltered isn't passed on, since a branch filtering out the data only ends that branch rather than the pipeline
+func (c *lockedContext) SetFiltered(_ string) {
+}
+
+func (c *lockedContext) SetRetryData(data []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.detached {
+		c.AppFunctionContext.SetRetryData(data)
+	}
+}
+
+func (c *lockedContext) AddValue(key string, value string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.detached {
+		c.AppFunctionContext.AddValue(key, value)
+	}
+}
+
+func (c *lockedContext) RemoveValue(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.detached {
+		c.AppFunctionContext.RemoveValue(key)
+	}
+}
+
+func (c *lockedContext) GetValue(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.detached {
+		return "", false
+	}
+	return c.AppFunctionContext.GetValue(key)
+}
+
+func (c *lockedContext) GetAllValues() map[string]string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.detached {
+		return map[string]string{}
+	}
+	return c.AppFunctionContext.GetAllValues()
+}
+
+func (c *lockedContext) ApplyValues(format string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.detached {
+		return "", errors.New("context no longer available to timed out join branch")
+	}
+	return c.AppFunctionContext.ApplyValues(format)
+}
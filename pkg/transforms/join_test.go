@@ -0,0 +1,228 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func returnData(result interface{}) interfaces.AppFunction {
+	return func(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return true, result
+	}
+}
+
+func stopPipeline(result interface{}) interfaces.AppFunction {
+	return func(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, result
+	}
+}
+
+func delay(duration time.Duration) interfaces.AppFunction {
+	return func(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		time.Sleep(duration)
+		return true, data
+	}
+}
+
+func TestNewJoin(t *testing.T) {
+	branch := JoinBranch{Name: "a", Functions: []interfaces.AppFunction{returnData(1)}}
+
+	tests := []struct {
+		Name          string
+		Timeout       time.Duration
+		Policy        string
+		Branches      []JoinBranch
+		ErrorContains string
+	}{
+		{"Valid all", time.Second, JoinPolicyAll, []JoinBranch{branch}, ""},
+		{"Valid partial mixed case", time.Second, "Partial", []JoinBranch{branch}, ""},
+		{"Zero timeout", 0, JoinPolicyAll, []JoinBranch{branch}, "timeout"},
+		{"Bad policy", time.Second, "bogus", []JoinBranch{branch}, "policy"},
+		{"No branches", time.Second, JoinPolicyAll, nil, "at least one branch"},
+		{"Empty name", time.Second, JoinPolicyAll, []JoinBranch{{Functions: branch.Functions}}, "name must not be empty"},
+		{"Duplicate name", time.Second, JoinPolicyAll, []JoinBranch{branch, branch}, "duplicate"},
+		{"No functions", time.Second, JoinPolicyAll, []JoinBranch{{Name: "a"}}, "no functions"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			join, err := NewJoin(test.Timeout, test.Policy, test.Branches...)
+			if len(test.ErrorContains) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ErrorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, join)
+		})
+	}
+}
+
+func TestJoin_Execute(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Policy         string
+		Branches       []JoinBranch
+		ExpectContinue bool
+		Expected       map[string]interface{}
+		ErrorContains  string
+	}{
+		{
+			Name:   "All succeed",
+			Policy: JoinPolicyAll,
+			Branches: []JoinBranch{
+				{Name: "a", Functions: []interfaces.AppFunction{returnData("one")}},
+				{Name: "b", Functions: []interfaces.AppFunction{delay(10 * time.Millisecond), returnData(2)}},
+				{Name: "c", Functions: []interfaces.AppFunction{returnData([]byte(`{"x":3}`))}},
+				{Name: "d", Functions: []interfaces.AppFunction{returnData([]byte("not json"))}},
+			},
+			ExpectContinue: true,
+			Expected: map[string]interface{}{
+				"a": "one",
+				"b": 2,
+				"c": json.RawMessage(`{"x":3}`),
+				"d": []byte("not json"),
+			},
+		},
+		{
+			Name:   "Filtered branch excluded",
+			Policy: JoinPolicyAll,
+			Branches: []JoinBranch{
+				{Name: "a", Functions: []interfaces.AppFunction{returnData("one")}},
+				{Name: "b", Functions: []interfaces.AppFunction{stopPipeline(nil), returnData(2)}},
+			},
+			ExpectContinue: true,
+			Expected:       map[string]interface{}{"a": "one"},
+		},
+		{
+			Name:   "All filtered",
+			Policy: JoinPolicyAll,
+			Branches: []JoinBranch{
+				{Name: "a", Functions: []interfaces.AppFunction{stopPipeline(nil)}},
+			},
+		},
+		{
+			Name:   "All with error",
+			Policy: JoinPolicyAll,
+			Branches: []JoinBranch{
+				{Name: "a", Functions: []interfaces.AppFunction{returnData("one")}},
+				{Name: "b", Functions: []interfaces.AppFunction{stopPipeline(errors.New("boom"))}},
+			},
+			ErrorContains: "branch 'b': boom",
+		},
+		{
+			Name:   "All with timeout",
+			Policy: JoinPolicyAll,
+			Branches: []JoinBranch{
+				{Name: "a", Functions: []interfaces.AppFunction{returnData("one")}},
+				{Name: "b", Functions: []interfaces.AppFunction{delay(time.Second)}},
+			},
+			ErrorContains: "branch 'b': timed out",
+		},
+		{
+			Name:   "Partial with error and timeout",
+			Policy: JoinPolicyPartial,
+			Branches: []JoinBranch{
+				{Name: "a", Functions: []interfaces.AppFunction{returnData("one")}},
+				{Name: "b", Functions: []interfaces.AppFunction{stopPipeline(errors.New("boom"))}},
+				{Name: "c", Functions: []interfaces.AppFunction{delay(time.Second)}},
+			},
+			ExpectContinue: true,
+			Expected:       map[string]interface{}{"a": "one"},
+		},
+		{
+			Name:   "Partial with no successes",
+			Policy: JoinPolicyPartial,
+			Branches: []JoinBranch{
+				{Name: "a", Functions: []interfaces.AppFunction{stopPipeline(errors.New("boom"))}},
+			},
+			ErrorContains: "branch 'a': boom",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			join, err := NewJoin(100*time.Millisecond, test.Policy, test.Branches...)
+			require.NoError(t, err)
+
+			continuePipeline, result := join.Execute(ctx, "input")
+			assert.Equal(t, test.ExpectContinue, continuePipeline)
+
+			if len(test.ErrorContains) > 0 {
+				require.IsType(t, errors.New(""), result)
+				assert.Contains(t, result.(error).Error(), test.ErrorContains)
+				return
+			}
+
+			if test.Expected == nil {
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.Equal(t, test.Expected, result)
+		})
+	}
+}
+
+func TestJoin_ExecuteNoData(t *testing.T) {
+	join, err := NewJoin(time.Second, JoinPolicyAll, JoinBranch{Name: "a", Functions: []interfaces.AppFunction{returnData(1)}})
+	require.NoError(t, err)
+
+	continuePipeline, result := join.Execute(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.Equal(t, "No Data Received", result.(error).Error())
+}
+
+func TestJoin_ExecuteSharedContext(t *testing.T) {
+	addValues := func(prefix string) interfaces.AppFunction {
+		return func(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+			for i := 0; i < 100; i++ {
+				ctx.AddValue(fmt.Sprintf("%s-%d", prefix, i), "value")
+				_, _ = ctx.GetValue(fmt.Sprintf("%s-%d", prefix, i))
+			}
+			return true, prefix
+		}
+	}
+
+	join, err := NewJoin(time.Second, JoinPolicyAll,
+		JoinBranch{Name: "a", Functions: []interfaces.AppFunction{addValues("a")}},
+		JoinBranch{Name: "b", Functions: []interfaces.AppFunction{addValues("b")}},
+	)
+	require.NoError(t, err)
+
+	appContext := appfunction.NewContext("123", dic, "")
+	continuePipeline, result := join.Execute(appContext, "input")
+	require.True(t, continuePipeline)
+	assert.Equal(t, map[string]interface{}{"a": "a", "b": "b"}, result)
+
+	_, found := appContext.GetValue("a-99")
+	assert.True(t, found)
+	_, found = appContext.GetValue("b-99")
+	assert.True(t, found)
+}
@@ -0,0 +1,289 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is the subset of JSON Schema (draft 7) supported by the SDK. Supported keywords are
+// type, properties, required, additionalProperties, items, enum, const, minimum, maximum,
+// minLength, maxLength, pattern, minItems and maxItems. Schemas using any other keyword, i.e. format, oneOf or $ref,
+// are rejected rather than the keyword being ignored. The annotations $schema, $id, $comment, title, description,
+// default and examples are allowed since they don't affect validation.
+type jsonSchema struct {
+	Type                 jsonSchemaTypes        `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []interface{}          `json:"enum"`
+	Const                interface{}            `json:"const"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+
+	pattern *regexp.Regexp
+}
+
+// jsonSchemaKeywords are the keywords a schema may contain, which are those of the supported subset and annotations
+var jsonSchemaKeywords = map[string]bool{
+	"type":                 true,
+	"properties":           true,
+	"required":             true,
+	"additionalProperties": true,
+	"items":                true,
+	"enum":                 true,
+	"const":                true,
+	"minimum":              true,
+	"maximum":              true,
+	"minLength":            true,
+	"maxLength":            true,
+	"pattern":              true,
+	"minItems":             true,
+	"maxItems":             true,
+	"$schema":              true,
+	"$id":                  true,
+	"$comment":             true,
+	"title":                true,
+	"description":          true,
+	"default":              true,
+	"examples":             true,
+}
+
+// UnmarshalJSON rejects the keywords outside the supported subset, which would otherwise be silently ignored so the
+// data would be validated against a different schema than the one intended
+func (schema *jsonSchema) UnmarshalJSON(data []byte) error {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return fmt.Errorf("schema must be an object: %s", err.Error())
+	}
+
+	var unsupported []string
+	for keyword := range keywords {
+		if !jsonSchemaKeywords[keyword] {
+			unsupported = append(unsupported, keyword)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return fmt.Errorf("unsupported keyword(s) '%s'", strings.Join(unsupported, "', '"))
+	}
+
+	// The alias has the same fields without this method, so is unmarshaled as usual
+	type schemaFields jsonSchema
+	return json.Unmarshal(data, (*schemaFields)(schema))
+}
+
+// jsonSchemaTypes allows the type keyword to be either a single type or a list of types
+type jsonSchemaTypes []string
+
+func (types *jsonSchemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*types = []string{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("type must be a string or list of strings: %s", err.Error())
+	}
+
+	*types = multiple
+	return nil
+}
+
+// parseJSONSchema parses the JSON Schema text and compiles any patterns it contains
+func parseJSONSchema(text string) (*jsonSchema, error) {
+	schema := &jsonSchema{}
+	if err := json.Unmarshal([]byte(text), schema); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON schema: %s", err.Error())
+	}
+
+	if err := schema.compile(); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON schema: %s", err.Error())
+	}
+
+	return schema, nil
+}
+
+func (schema *jsonSchema) compile() error {
+	if len(schema.Pattern) > 0 {
+		var err error
+		if schema.pattern, err = regexp.Compile(schema.Pattern); err != nil {
+			return err
+		}
+	}
+
+	for _, property := range schema.Properties {
+		if err := property.compile(); err != nil {
+			return err
+		}
+	}
+
+	if schema.Items != nil {
+		return schema.Items.compile()
+	}
+
+	return nil
+}
+
+// validateBytes unmarshals the JSON data and validates it against the schema
+func (schema *jsonSchema) validateBytes(data []byte) []string {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return []string{fmt.Sprintf("data is not valid JSON: %s", err.Error())}
+	}
+
+	return schema.validate("$", document)
+}
+
+// validate validates the unmarshaled JSON value against the schema and returns the list of violations found
+func (schema *jsonSchema) validate(path string, value interface{}) []string {
+	var violations []string
+
+	if len(schema.Type) > 0 && !schema.matchesType(value) {
+		return []string{fmt.Sprintf("%s: expected type %s but got %s", path, strings.Join(schema.Type, " or "), jsonTypeOf(value))}
+	}
+
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, allowed := range schema.Enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s: value %v is not one of the allowed values %v", path, value, schema.Enum))
+		}
+	}
+
+	if schema.Const != nil && !reflect.DeepEqual(schema.Const, value) {
+		violations = append(violations, fmt.Sprintf("%s: value %v must be %v", path, value, schema.Const))
+	}
+
+	switch typed := value.(type) {
+	case float64:
+		if schema.Minimum != nil && typed < *schema.Minimum {
+			violations = append(violations, fmt.Sprintf("%s: value %v is less than minimum %v", path, typed, *schema.Minimum))
+		}
+		if schema.Maximum != nil && typed > *schema.Maximum {
+			violations = append(violations, fmt.Sprintf("%s: value %v is greater than maximum %v", path, typed, *schema.Maximum))
+		}
+
+	case string:
+		length := utf8.RuneCountInString(typed)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			violations = append(violations, fmt.Sprintf("%s: length %d is less than minLength %d", path, length, *schema.MinLength))
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			violations = append(violations, fmt.Sprintf("%s: length %d is greater than maxLength %d", path, length, *schema.MaxLength))
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(typed) {
+			violations = append(violations, fmt.Sprintf("%s: value '%s' does not match pattern '%s'", path, typed, schema.Pattern))
+		}
+
+	case []interface{}:
+		if schema.MinItems != nil && len(typed) < *schema.MinItems {
+			violations = append(violations, fmt.Sprintf("%s: %d items is less than minItems %d", path, len(typed), *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(typed) > *schema.MaxItems {
+			violations = append(violations, fmt.Sprintf("%s: %d items is greater than maxItems %d", path, len(typed), *schema.MaxItems))
+		}
+		if schema.Items != nil {
+			for index, item := range typed {
+				violations = append(violations, schema.Items.validate(fmt.Sprintf("%s[%d]", path, index), item)...)
+			}
+		}
+
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, found := typed[name]; !found {
+				violations = append(violations, fmt.Sprintf("%s: missing required property '%s'", path, name))
+			}
+		}
+
+		// Sort the names so violations are reported in a consistent order
+		names := make([]string, 0, len(typed))
+		for name := range typed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			property, found := schema.Properties[name]
+			if !found {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					violations = append(violations, fmt.Sprintf("%s: additional property '%s' is not allowed", path, name))
+				}
+				continue
+			}
+
+			violations = append(violations, property.validate(path+"."+name, typed[name])...)
+		}
+	}
+
+	return violations
+}
+
+func (schema *jsonSchema) matchesType(value interface{}) bool {
+	actual := jsonTypeOf(value)
+	for _, expected := range schema.Type {
+		if expected == actual {
+			return true
+		}
+
+		// Integers are numbers without a fractional part
+		if expected == "integer" && actual == "number" && value.(float64) == math.Trunc(value.(float64)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
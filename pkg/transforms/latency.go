@@ -0,0 +1,72 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+const (
+	// QueueTimeHeader is the export header set to the milliseconds the message was queued before the functions
+	// pipeline started processing it
+	QueueTimeHeader = "X-Queue-Time-Ms"
+	// ProcessingTimeHeader is the export header set to the milliseconds the functions pipeline has been processing
+	// the message for
+	ProcessingTimeHeader = "X-Processing-Time-Ms"
+	// QueueTimeTag is the Event tag set to the milliseconds the message was queued before the functions pipeline
+	// started processing it
+	QueueTimeTag = "queueTimeMs"
+	// ProcessingTimeTag is the Event tag set to the milliseconds the functions pipeline had been processing the
+	// message for when the tag was added
+	ProcessingTimeTag = "processingTimeMs"
+)
+
+// latencyAnnotations returns the milliseconds the message was queued for and has been processed for, which are
+// empty when the functions pipeline didn't record when the message was enqueued or processing started
+func latencyAnnotations(ctx interfaces.AppFunctionContext) (queueTime string, processingTime string) {
+	queueTime, _ = ctx.GetValue(interfaces.QUEUETIME)
+
+	if processing, ok := processingDuration(ctx); ok {
+		processingTime = strconv.FormatInt(processing.Milliseconds(), 10)
+	}
+
+	return queueTime, processingTime
+}
+
+// recordProcessingTime sets the PROCESSINGTIME context value once an export has completed
+func recordProcessingTime(ctx interfaces.AppFunctionContext) {
+	if processing, ok := processingDuration(ctx); ok {
+		ctx.AddValue(interfaces.PROCESSINGTIME, strconv.FormatInt(processing.Milliseconds(), 10))
+	}
+}
+
+func processingDuration(ctx interfaces.AppFunctionContext) (time.Duration, bool) {
+	value, ok := ctx.GetValue(interfaces.PIPELINESTART)
+	if !ok {
+		return 0, false
+	}
+
+	started, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Since(time.Unix(0, started)), true
+}
@@ -0,0 +1,165 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultMaxBufferedMessages = 10000
+	bufferFileExtension        = ".msg"
+)
+
+// bufferedMessage is a message persisted to the buffer directory until it can be published
+type bufferedMessage struct {
+	Topic   string
+	Payload []byte
+}
+
+// diskBuffer is a persistent first in, first out queue of the messages which could not be published. Each message
+// is stored in its own file named with its sequence number so that the order is preserved across restarts.
+type diskBuffer struct {
+	lock      sync.Mutex
+	directory string
+	maxSize   int
+	files     []string
+	nextSeq   uint64
+}
+
+// newDiskBuffer creates the buffer directory if needed and loads any messages buffered before the service restarted
+func newDiskBuffer(directory string, maxSize int) (*diskBuffer, error) {
+	if maxSize <= 0 {
+		maxSize = defaultMaxBufferedMessages
+	}
+
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create buffer directory '%s': %s", directory, err.Error())
+	}
+
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read buffer directory '%s': %s", directory, err.Error())
+	}
+
+	buffer := &diskBuffer{
+		directory: directory,
+		maxSize:   maxSize,
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, bufferFileExtension) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, bufferFileExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		buffer.files = append(buffer.files, name)
+		if seq >= buffer.nextSeq {
+			buffer.nextSeq = seq + 1
+		}
+	}
+
+	// Names are zero padded so sort in sequence order
+	sort.Strings(buffer.files)
+
+	return buffer, nil
+}
+
+// len returns the number of buffered messages
+func (buffer *diskBuffer) len() int {
+	buffer.lock.Lock()
+	defer buffer.lock.Unlock()
+	return len(buffer.files)
+}
+
+// add persists the message to the end of the buffer. When the buffer is full the oldest message is discarded
+// and true is returned.
+func (buffer *diskBuffer) add(topic string, payload []byte) (bool, error) {
+	buffer.lock.Lock()
+	defer buffer.lock.Unlock()
+
+	data, err := json.Marshal(bufferedMessage{Topic: topic, Payload: payload})
+	if err != nil {
+		return false, err
+	}
+
+	name := fmt.Sprintf("%020d%s", buffer.nextSeq, bufferFileExtension)
+	path := filepath.Join(buffer.directory, name)
+
+	// Written to a temporary file first so a partially written message is never published after a crash
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		return false, fmt.Errorf("unable to write buffered message: %s", err.Error())
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return false, fmt.Errorf("unable to write buffered message: %s", err.Error())
+	}
+
+	buffer.nextSeq++
+	buffer.files = append(buffer.files, name)
+
+	dropped := false
+	for len(buffer.files) > buffer.maxSize {
+		_ = os.Remove(filepath.Join(buffer.directory, buffer.files[0]))
+		buffer.files = buffer.files[1:]
+		dropped = true
+	}
+
+	return dropped, nil
+}
+
+// flush publishes the buffered messages in order, removing each once published. Flushing stops at the first
+// message which fails to publish. Returns the number of messages published.
+func (buffer *diskBuffer) flush(publish func(topic string, payload []byte) error) (int, error) {
+	buffer.lock.Lock()
+	defer buffer.lock.Unlock()
+
+	published := 0
+	for len(buffer.files) > 0 {
+		path := filepath.Join(buffer.directory, buffer.files[0])
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return published, fmt.Errorf("unable to read buffered message: %s", err.Error())
+		}
+
+		message := bufferedMessage{}
+		if err := json.Unmarshal(data, &message); err == nil {
+			if err := publish(message.Topic, message.Payload); err != nil {
+				return published, err
+			}
+			published++
+		}
+		// Corrupt messages can never be published so are discarded
+
+		_ = os.Remove(path)
+		buffer.files = buffer.files[1:]
+	}
+
+	return published, nil
+}
@@ -0,0 +1,125 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type published struct {
+	topic   string
+	payload string
+}
+
+func flushAll(t *testing.T, buffer *diskBuffer) []published {
+	var messages []published
+	count, err := buffer.flush(func(topic string, payload []byte) error {
+		messages = append(messages, published{topic, string(payload)})
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, len(messages), count)
+	return messages
+}
+
+func TestDiskBuffer_FlushInOrder(t *testing.T) {
+	directory := t.TempDir()
+	buffer, err := newDiskBuffer(directory, 0)
+	require.NoError(t, err)
+	assert.Equal(t, defaultMaxBufferedMessages, buffer.maxSize)
+
+	for _, payload := range []string{"1", "2", "3"} {
+		dropped, err := buffer.add("topic/"+payload, []byte(payload))
+		require.NoError(t, err)
+		assert.False(t, dropped)
+	}
+	assert.Equal(t, 3, buffer.len())
+
+	expected := []published{{"topic/1", "1"}, {"topic/2", "2"}, {"topic/3", "3"}}
+	assert.Equal(t, expected, flushAll(t, buffer))
+	assert.Zero(t, buffer.len())
+
+	files, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestDiskBuffer_Reload(t *testing.T) {
+	directory := t.TempDir()
+	buffer, err := newDiskBuffer(directory, 0)
+	require.NoError(t, err)
+
+	for _, payload := range []string{"1", "2"} {
+		_, err := buffer.add("topic", []byte(payload))
+		require.NoError(t, err)
+	}
+
+	// Unrelated and corrupt files are ignored or discarded
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "other.txt"), []byte("x"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "00000000000000000005.msg"), []byte("corrupt"), 0600))
+
+	// Messages buffered before a restart are published first
+	reloaded, err := newDiskBuffer(directory, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reloaded.len())
+	assert.Equal(t, uint64(6), reloaded.nextSeq)
+
+	_, err = reloaded.add("topic", []byte("3"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []published{{"topic", "1"}, {"topic", "2"}, {"topic", "3"}}, flushAll(t, reloaded))
+}
+
+func TestDiskBuffer_Full(t *testing.T) {
+	buffer, err := newDiskBuffer(t.TempDir(), 2)
+	require.NoError(t, err)
+
+	_, _ = buffer.add("topic", []byte("1"))
+	_, _ = buffer.add("topic", []byte("2"))
+	dropped, err := buffer.add("topic", []byte("3"))
+	require.NoError(t, err)
+	assert.True(t, dropped)
+
+	assert.Equal(t, []published{{"topic", "2"}, {"topic", "3"}}, flushAll(t, buffer))
+}
+
+func TestDiskBuffer_FlushStopsOnError(t *testing.T) {
+	buffer, err := newDiskBuffer(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	for _, payload := range []string{"1", "2", "3"} {
+		_, _ = buffer.add("topic", []byte(payload))
+	}
+
+	count, err := buffer.flush(func(topic string, payload []byte) error {
+		if string(payload) == "2" {
+			return errors.New("publish failed")
+		}
+		return nil
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 2, buffer.len())
+
+	assert.Equal(t, []published{{"topic", "2"}, {"topic", "3"}}, flushAll(t, buffer))
+}
@@ -17,13 +17,18 @@
 package transforms
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
 
 	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
@@ -31,6 +36,14 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
 )
 
+const (
+	defaultOnlinePayload  = "online"
+	defaultOfflinePayload = "offline"
+
+	defaultPrimaryCheckInterval = 30 * time.Second
+	maxPrimaryProbeTimeout      = 5 * time.Second
+)
+
 // MQTTSecretSender ...
 type MQTTSecretSender struct {
 	lock                 sync.Mutex
@@ -40,12 +53,26 @@ type MQTTSecretSender struct {
 	opts                 *MQTT.ClientOptions
 	secretsLastRetrieved time.Time
 	topicFormatter       StringValuesFormatter
+	buffer               *diskBuffer
+	brokers              []string
+	attemptedBroker      string
+	connectedBroker      string
+	lastPrimaryCheck     time.Time
+	brokerLock           sync.Mutex
+	limiter              *sendLimiter
+	// probe checks whether the broker is reachable, which is replaced in unit tests
+	probe func(broker string, timeout time.Duration) error
 }
 
 // MQTTSecretConfig ...
 type MQTTSecretConfig struct {
 	// BrokerAddress should be set to the complete broker address i.e. mqtts://mosquitto:8883/mybroker
+	// It may be a comma separated list of broker addresses, which are tried in order when connecting. The first is
+	// the primary broker and the others are standby brokers used while the primary is unreachable.
 	BrokerAddress string
+	// PrimaryCheckInterval is how often, while connected to a standby broker, the primary broker is checked.
+	// Once the primary broker is reachable the client reconnects to it. Defaults to 30s.
+	PrimaryCheckInterval string
 	// ClientId to connect with the broker with.
 	ClientId string
 	// The name of the path in secret provider to retrieve your secrets
@@ -67,23 +94,76 @@ type MQTTSecretConfig struct {
 	// AuthMode indicates what to use when connecting to the broker. Options are "none", "cacert" , "usernamepassword", "clientcert".
 	// If a CA Cert exists in the SecretPath then it will be used for all modes except "none".
 	AuthMode string
+	// StatusTopic, when set, is the topic the connection status is published to. OnlinePayload is published each time
+	// the connection is made and OfflinePayload is registered as the Last Will and Testament, which the broker
+	// publishes when the connection is lost. Both are published with QoS and retained.
+	StatusTopic string
+	// OnlinePayload is the status published once connected. Defaults to "online".
+	OnlinePayload string
+	// OfflinePayload is the status published by the broker when the connection is lost. Defaults to "offline".
+	OfflinePayload string
+	// BufferDirectory, when set, is the directory messages are persisted to while the broker is unreachable.
+	// Buffered messages are published in order once reconnected, before any new messages. Messages are buffered
+	// rather than using Store and Forward, which does not preserve the order.
+	BufferDirectory string
+	// MaxBufferedMessages is the most messages buffered, after which the oldest are discarded. Defaults to 10000.
+	MaxBufferedMessages int
+	// MaxConcurrency, when positive, is the most publishes in flight to the broker from concurrently executing
+	// pipelines. Further publishes wait and are made in the order they were requested. Zero doesn't limit them.
+	MaxConcurrency int
+	// Checksum, when specified, is the algorithm, crc32 or sha256, of the checksum of the data appended to each message
+	// as a trailing line, i.e. '\nsha256=<hex>', so the receiver can detect truncated or corrupted data. A trailer is
+	// used as MQTT 3.1.1 messages have no properties.
+	Checksum string
 }
 
 // NewMQTTSecretSender ...
 func NewMQTTSecretSender(mqttConfig MQTTSecretConfig, persistOnError bool) *MQTTSecretSender {
 	opts := MQTT.NewClientOptions()
 
-	opts.AddBroker(mqttConfig.BrokerAddress)
+	var brokers []string
+	for _, broker := range strings.Split(mqttConfig.BrokerAddress, ",") {
+		broker = strings.TrimSpace(broker)
+		if len(broker) > 0 {
+			brokers = append(brokers, broker)
+			opts.AddBroker(broker)
+		}
+	}
+
 	opts.SetClientID(mqttConfig.ClientId)
 	opts.SetAutoReconnect(mqttConfig.AutoReconnect)
 
 	//avoid casing issues
 	mqttConfig.AuthMode = strings.ToLower(mqttConfig.AuthMode)
+
+	if len(mqttConfig.StatusTopic) > 0 {
+		if len(mqttConfig.OnlinePayload) == 0 {
+			mqttConfig.OnlinePayload = defaultOnlinePayload
+		}
+		if len(mqttConfig.OfflinePayload) == 0 {
+			mqttConfig.OfflinePayload = defaultOfflinePayload
+		}
+
+		opts.SetWill(mqttConfig.StatusTopic, mqttConfig.OfflinePayload, mqttConfig.QoS, true)
+	}
 	sender := &MQTTSecretSender{
 		client:         nil,
 		mqttConfig:     mqttConfig,
 		persistOnError: persistOnError,
 		opts:           opts,
+		brokers:        brokers,
+		probe:          probeBroker,
+		limiter:        newSendLimiter(mqttConfig.MaxConcurrency),
+	}
+
+	if len(brokers) > 1 {
+		// The brokers are attempted in order, so the last attempted is the one connected to
+		opts.SetConnectionAttemptHandler(func(broker *url.URL, tlsCfg *tls.Config) *tls.Config {
+			sender.brokerLock.Lock()
+			sender.attemptedBroker = broker.String()
+			sender.brokerLock.Unlock()
+			return tlsCfg
+		})
 	}
 
 	return sender
@@ -128,6 +208,18 @@ func (sender *MQTTSecretSender) initializeMQTTClient(ctx interfaces.AppFunctionC
 		sender.opts.SetConnectTimeout(timeout)
 	}
 
+	if len(sender.mqttConfig.BufferDirectory) > 0 && sender.buffer == nil {
+		buffer, err := newDiskBuffer(sender.mqttConfig.BufferDirectory, sender.mqttConfig.MaxBufferedMessages)
+		if err != nil {
+			return err
+		}
+		sender.buffer = buffer
+	}
+
+	if len(sender.mqttConfig.StatusTopic) > 0 || sender.buffer != nil || len(sender.brokers) > 1 {
+		sender.opts.SetOnConnectHandler(sender.onConnected(ctx.LoggingClient()))
+	}
+
 	client, err := mqttFactory.Create(sender.opts)
 	if err != nil {
 		return err
@@ -150,18 +242,84 @@ func (sender *MQTTSecretSender) connectToBroker(ctx interfaces.AppFunctionContex
 	}
 
 	ctx.LoggingClient().Info("Connecting to mqtt server for export")
-	if token := sender.client.Connect(); token.Wait() && token.Error() != nil {
+	if err := waitForToken(ctx.Context(), sender.client.Connect()); err != nil {
 		sender.setRetryData(ctx, exportData)
 		subMessage := "dropping event"
-		if sender.persistOnError {
+		if sender.buffer != nil {
+			subMessage = "buffering Event for later publishing"
+		} else if sender.persistOnError {
 			subMessage = "persisting Event for later retry"
 		}
-		return fmt.Errorf("Could not connect to mqtt server for export, %s. Error: %s", subMessage, token.Error().Error())
+		return fmt.Errorf("Could not connect to mqtt server for export, %s. Error: %s", subMessage, err.Error())
 	}
 	ctx.LoggingClient().Info("Connected to mqtt server for export")
 	return nil
 }
 
+// onConnected returns the handler which publishes the online status and flushes the buffered messages each time
+// the client connects, including when it automatically reconnects
+func (sender *MQTTSecretSender) onConnected(lc logger.LoggingClient) MQTT.OnConnectHandler {
+	return func(client MQTT.Client) {
+		config := sender.mqttConfig
+
+		if len(sender.brokers) > 1 {
+			sender.brokerLock.Lock()
+			sender.connectedBroker = sender.attemptedBroker
+			sender.brokerLock.Unlock()
+			lc.Infof("Connected to '%s' MQTT Broker for export", sender.connectedBroker)
+		}
+
+		if len(config.StatusTopic) > 0 {
+			token := client.Publish(config.StatusTopic, config.QoS, true, config.OnlinePayload)
+			token.Wait()
+			if token.Error() != nil {
+				lc.Errorf("Unable to publish online status to '%s' topic for MQTT export: %s", config.StatusTopic, token.Error().Error())
+			} else {
+				lc.Debugf("Published online status to '%s' topic for MQTT export", config.StatusTopic)
+			}
+		}
+
+		if sender.buffer != nil {
+			sender.flushBuffer(lc, client)
+		}
+	}
+}
+
+// flushBuffer publishes the buffered messages in order, returning false if any remain buffered
+func (sender *MQTTSecretSender) flushBuffer(lc logger.LoggingClient, client MQTT.Client) bool {
+	published, err := sender.buffer.flush(func(topic string, payload []byte) error {
+		token := client.Publish(topic, sender.mqttConfig.QoS, sender.mqttConfig.Retain, payload)
+		token.Wait()
+		return token.Error()
+	})
+
+	if published > 0 {
+		lc.Infof("Published %d buffered messages to MQTT Broker", published)
+	}
+
+	if err != nil {
+		lc.Errorf("Unable to publish buffered messages to MQTT Broker, %d remain buffered: %s", sender.buffer.len(), err.Error())
+		return false
+	}
+
+	return true
+}
+
+// bufferData persists the data to be published once the broker is reachable
+func (sender *MQTTSecretSender) bufferData(ctx interfaces.AppFunctionContext, topic string, exportData []byte, cause error) (bool, interface{}) {
+	dropped, err := sender.buffer.add(topic, exportData)
+	if err != nil {
+		return false, fmt.Errorf("unable to buffer data for MQTT export after error '%s': %s", cause.Error(), err.Error())
+	}
+
+	if dropped {
+		ctx.LoggingClient().Warnf("MQTT export buffer is full, discarded oldest buffered message")
+	}
+
+	ctx.LoggingClient().Warnf("Buffered data for MQTT export, %d messages buffered: %s", sender.buffer.len(), cause.Error())
+	return true, nil
+}
+
 // MQTTSend sends data from the previous function to the specified MQTT broker.
 // If no previous function exists, then the event that triggered the pipeline will be used.
 func (sender *MQTTSecretSender) MQTTSend(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
@@ -174,6 +332,15 @@ func (sender *MQTTSecretSender) MQTTSend(ctx interfaces.AppFunctionContext, data
 	if err != nil {
 		return false, err
 	}
+	// The data is retried without the trailer, which is added again when it is published
+	publishData := exportData
+	if len(sender.mqttConfig.Checksum) > 0 {
+		publishData, err = appendChecksumTrailer(sender.mqttConfig.Checksum, exportData)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	// if we haven't initialized the client yet OR the cache has been invalidated (due to new/updated secrets) we need to (re)initialize the client
 	if sender.client == nil || sender.secretsLastRetrieved.Before(ctx.SecretsLastUpdated()) {
 		err := sender.initializeMQTTClient(ctx)
@@ -181,34 +348,147 @@ func (sender *MQTTSecretSender) MQTTSend(ctx interfaces.AppFunctionContext, data
 			return false, err
 		}
 	}
+
+	publishTopic, err := sender.topicFormatter.invoke(sender.mqttConfig.Topic, ctx, data)
+
+	if err != nil {
+		return false, fmt.Errorf("MQTT topic formatting failed: %s", err.Error())
+	}
+
+	sender.checkPrimaryBroker(ctx)
+
 	if !sender.client.IsConnected() {
 		err := sender.connectToBroker(ctx, exportData)
 		if err != nil {
+			if sender.buffer != nil {
+				return sender.bufferData(ctx, publishTopic, publishData, err)
+			}
 			return false, err
 		}
 	}
 
-	publishTopic, err := sender.topicFormatter.invoke(sender.mqttConfig.Topic, ctx, data)
-
-	if err != nil {
-		return false, fmt.Errorf("MQTT topic formatting failed: %s", err.Error())
+	if sender.buffer != nil && sender.buffer.len() > 0 {
+		// Buffered messages must be published first to preserve the order
+		if !sender.flushBuffer(ctx.LoggingClient(), sender.client) {
+			return sender.bufferData(ctx, publishTopic, publishData, errors.New("earlier messages remain buffered"))
+		}
 	}
 
-	token := sender.client.Publish(publishTopic, sender.mqttConfig.QoS, sender.mqttConfig.Retain, exportData)
-	token.Wait()
-	if token.Error() != nil {
+	release, err := sender.limiter.acquire(ctx.Context())
+	if err == nil {
+		err = waitForToken(ctx.Context(), sender.client.Publish(publishTopic, sender.mqttConfig.QoS, sender.mqttConfig.Retain, publishData))
+		release()
+	}
+	if err != nil {
+		if sender.buffer != nil {
+			return sender.bufferData(ctx, publishTopic, publishData, err)
+		}
 		sender.setRetryData(ctx, exportData)
-		return false, token.Error()
+		return false, err
 	}
 
+	recordProcessingTime(ctx)
+
 	ctx.LoggingClient().Debug("Sent data to MQTT Broker")
 	ctx.LoggingClient().Trace("Data exported", "Transport", "MQTT", common.CorrelationHeader, ctx.CorrelationID())
 
 	return true, nil
 }
 
+// waitForToken waits for the MQTT operation to complete, returning its error, or for the context to be done, i.e.
+// when the pipeline deadline has elapsed, so a stuck broker can't hold the message forever
+func waitForToken(ctx context.Context, token MQTT.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return fmt.Errorf("MQTT operation not completed: %w", ctx.Err())
+	}
+}
+
+// checkPrimaryBroker disconnects from the standby broker once the primary broker is reachable again, so the following
+// connect switches back to the primary broker. The primary broker is checked at most once every PrimaryCheckInterval.
+func (sender *MQTTSecretSender) checkPrimaryBroker(ctx interfaces.AppFunctionContext) {
+	if len(sender.brokers) < 2 || !sender.client.IsConnected() {
+		return
+	}
+
+	primary := sender.brokers[0]
+
+	sender.brokerLock.Lock()
+	if sender.connectedBroker == primary || time.Since(sender.lastPrimaryCheck) < sender.primaryCheckInterval(ctx.LoggingClient()) {
+		sender.brokerLock.Unlock()
+		return
+	}
+	sender.lastPrimaryCheck = time.Now()
+	standby := sender.connectedBroker
+	sender.brokerLock.Unlock()
+
+	timeout := sender.opts.ConnectTimeout
+	if timeout <= 0 || timeout > maxPrimaryProbeTimeout {
+		timeout = maxPrimaryProbeTimeout
+	}
+
+	if err := sender.probe(primary, timeout); err != nil {
+		ctx.LoggingClient().Debugf("Primary MQTT Broker '%s' for export remains unreachable: %s", primary, err.Error())
+		return
+	}
+
+	ctx.LoggingClient().Infof("Primary MQTT Broker '%s' for export is reachable, switching back from '%s'", primary, standby)
+	// The brokers are attempted in order when reconnecting, so a standby broker is used again if the primary
+	// broker can't be connected to
+	sender.lock.Lock()
+	sender.client.Disconnect(250)
+	sender.lock.Unlock()
+}
+
+func (sender *MQTTSecretSender) primaryCheckInterval(lc logger.LoggingClient) time.Duration {
+	if len(sender.mqttConfig.PrimaryCheckInterval) == 0 {
+		return defaultPrimaryCheckInterval
+	}
+
+	interval, err := time.ParseDuration(sender.mqttConfig.PrimaryCheckInterval)
+	if err != nil {
+		lc.Warnf("Unable to parse PrimaryCheckInterval value of '%s', using default of %s: %s",
+			sender.mqttConfig.PrimaryCheckInterval, defaultPrimaryCheckInterval, err.Error())
+		return defaultPrimaryCheckInterval
+	}
+
+	return interval
+}
+
+// probeBroker checks whether a network connection can be made to the broker
+func probeBroker(broker string, timeout time.Duration) error {
+	brokerUrl, err := url.Parse(broker)
+	if err != nil {
+		return err
+	}
+
+	port := brokerUrl.Port()
+	if len(port) == 0 {
+		switch strings.ToLower(brokerUrl.Scheme) {
+		case "ssl", "tls", "mqtts", "tcps":
+			port = "8883"
+		case "ws":
+			port = "80"
+		case "wss":
+			port = "443"
+		default:
+			port = "1883"
+		}
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(brokerUrl.Hostname(), port), timeout)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
 func (sender *MQTTSecretSender) setRetryData(ctx interfaces.AppFunctionContext, exportData []byte) {
-	if sender.persistOnError {
+	// Buffered data is published by the sender, so is not also persisted for Store and Forward to retry
+	if sender.persistOnError && sender.buffer == nil {
 		ctx.SetRetryData(exportData)
 	}
 }
@@ -20,7 +20,16 @@
 package transforms
 
 import (
+	"context"
+	"errors"
+	"net"
 	"testing"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,3 +58,259 @@ func TestMQTTSecretSender_MQTTSendNodata(t *testing.T) {
 	require.False(t, continuePipeline)
 	require.Error(t, result.(error))
 }
+
+// fakeToken is a completed MQTT token
+type fakeToken struct {
+	err error
+}
+
+func (token *fakeToken) Wait() bool                       { return true }
+func (token *fakeToken) WaitTimeout(_ time.Duration) bool { return true }
+func (token *fakeToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+func (token *fakeToken) Error() error { return token.err }
+
+// fakeClient records the messages published
+type fakeClient struct {
+	MQTT.Client
+	topic     string
+	qos       byte
+	retained  bool
+	payload   interface{}
+	published []string
+	connected bool
+	err       error
+}
+
+func (client *fakeClient) Publish(topic string, qos byte, retained bool, payload interface{}) MQTT.Token {
+	if client.err != nil {
+		return &fakeToken{err: client.err}
+	}
+
+	client.topic = topic
+	client.qos = qos
+	client.retained = retained
+	client.payload = payload
+	if data, ok := payload.([]byte); ok {
+		client.published = append(client.published, string(data))
+	}
+	return &fakeToken{}
+}
+
+func (client *fakeClient) IsConnected() bool {
+	return client.connected
+}
+
+func (client *fakeClient) Connect() MQTT.Token {
+	if !client.connected {
+		return &fakeToken{err: errors.New("broker unreachable")}
+	}
+	return &fakeToken{}
+}
+
+func (client *fakeClient) Disconnect(_ uint) {
+	client.connected = false
+}
+
+func TestNewMQTTSecretSender_Status(t *testing.T) {
+	sender := NewMQTTSecretSender(MQTTSecretConfig{StatusTopic: "status", QoS: 1}, false)
+	assert.Equal(t, "online", sender.mqttConfig.OnlinePayload)
+	assert.Equal(t, "offline", sender.mqttConfig.OfflinePayload)
+	assert.True(t, sender.opts.WillEnabled)
+	assert.Equal(t, "status", sender.opts.WillTopic)
+	assert.Equal(t, []byte("offline"), sender.opts.WillPayload)
+	assert.Equal(t, byte(1), sender.opts.WillQos)
+	assert.True(t, sender.opts.WillRetained)
+
+	sender = NewMQTTSecretSender(MQTTSecretConfig{StatusTopic: "status", OnlinePayload: "up", OfflinePayload: "down"}, false)
+	assert.Equal(t, "up", sender.mqttConfig.OnlinePayload)
+	assert.Equal(t, []byte("down"), sender.opts.WillPayload)
+
+	sender = NewMQTTSecretSender(MQTTSecretConfig{}, false)
+	assert.False(t, sender.opts.WillEnabled)
+}
+
+func TestMQTTSecretSender_onConnected(t *testing.T) {
+	sender := NewMQTTSecretSender(MQTTSecretConfig{StatusTopic: "status", QoS: 2}, false)
+	client := &fakeClient{}
+
+	sender.onConnected(lc)(client)
+	assert.Equal(t, "status", client.topic)
+	assert.Equal(t, byte(2), client.qos)
+	assert.True(t, client.retained)
+	assert.Equal(t, "online", client.payload)
+
+	// Failure to publish is only logged
+	client = &fakeClient{err: errors.New("publish failed")}
+	sender.onConnected(lc)(client)
+	assert.Nil(t, client.payload)
+}
+
+func TestMQTTSecretSender_MQTTSendBuffered(t *testing.T) {
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("SecretsLastUpdated").Return(time.Time{})
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	directory := t.TempDir()
+	sender := NewMQTTSecretSender(MQTTSecretConfig{Topic: "topic", BufferDirectory: directory}, true)
+	buffer, err := newDiskBuffer(directory, 0)
+	require.NoError(t, err)
+	client := &fakeClient{}
+	sender.buffer = buffer
+	sender.client = client
+	sender.secretsLastRetrieved = time.Now()
+
+	// Broker unreachable so data is buffered rather than persisted for Store and Forward
+	ctx.SetRetryData(nil)
+	for _, data := range []string{"1", "2"} {
+		continuePipeline, result := sender.MQTTSend(ctx, data)
+		require.True(t, continuePipeline)
+		require.Nil(t, result)
+	}
+	assert.Nil(t, ctx.RetryData())
+	assert.Equal(t, 2, buffer.len())
+	assert.Empty(t, client.published)
+
+	// Buffered data is published in order before new data
+	client.connected = true
+	continuePipeline, result := sender.MQTTSend(ctx, "3")
+	require.True(t, continuePipeline)
+	require.Nil(t, result)
+	assert.Equal(t, []string{"1", "2", "3"}, client.published)
+	assert.Zero(t, buffer.len())
+
+	// Publish failure is buffered and flushed when reconnected
+	client.err = errors.New("publish failed")
+	continuePipeline, _ = sender.MQTTSend(ctx, "4")
+	require.True(t, continuePipeline)
+	assert.Equal(t, 1, buffer.len())
+
+	client.err = nil
+	sender.onConnected(lc)(client)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, client.published)
+	assert.Zero(t, buffer.len())
+}
+
+func TestMQTTSecretSender_MQTTSendChecksum(t *testing.T) {
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("SecretsLastUpdated").Return(time.Time{})
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	sender := NewMQTTSecretSender(MQTTSecretConfig{Topic: "topic", Checksum: ChecksumCRC32}, true)
+	client := &fakeClient{connected: true}
+	sender.client = client
+	sender.secretsLastRetrieved = time.Now()
+
+	continuePipeline, result := sender.MQTTSend(ctx, "123456789")
+	require.True(t, continuePipeline)
+	require.Nil(t, result)
+	assert.Equal(t, []string{"123456789\ncrc32=cbf43926"}, client.published)
+
+	// Data is retried without the trailer, which is added again when published
+	ctx.SetRetryData(nil)
+	client.err = errors.New("publish failed")
+	continuePipeline, _ = sender.MQTTSend(ctx, "123456789")
+	require.False(t, continuePipeline)
+	assert.Equal(t, []byte("123456789"), ctx.RetryData())
+}
+
+// pendingToken is an MQTT token which never completes
+type pendingToken struct {
+	fakeToken
+}
+
+func (token *pendingToken) Done() <-chan struct{} {
+	return make(chan struct{})
+}
+
+func TestWaitForToken(t *testing.T) {
+	assert.NoError(t, waitForToken(context.Background(), &fakeToken{}))
+	assert.EqualError(t, waitForToken(context.Background(), &fakeToken{err: errors.New("failed")}), "failed")
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := waitForToken(cancelled, &pendingToken{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestNewMQTTSecretSender_Brokers(t *testing.T) {
+	sender := NewMQTTSecretSender(MQTTSecretConfig{BrokerAddress: "tcp://primary:1883, tcp://standby:1883,"}, false)
+	assert.Equal(t, []string{"tcp://primary:1883", "tcp://standby:1883"}, sender.brokers)
+	require.Len(t, sender.opts.Servers, 2)
+	assert.Equal(t, "primary:1883", sender.opts.Servers[0].Host)
+	assert.Equal(t, "standby:1883", sender.opts.Servers[1].Host)
+	require.NotNil(t, sender.opts.OnConnectAttempt)
+
+	// The broker connected to is the last attempted
+	sender.opts.OnConnectAttempt(sender.opts.Servers[1], nil)
+	sender.onConnected(lc)(&fakeClient{})
+	assert.Equal(t, "tcp://standby:1883", sender.connectedBroker)
+
+	sender = NewMQTTSecretSender(MQTTSecretConfig{BrokerAddress: "tcp://primary:1883"}, false)
+	assert.Len(t, sender.opts.Servers, 1)
+	assert.Nil(t, sender.opts.OnConnectAttempt)
+}
+
+func TestMQTTSecretSender_checkPrimaryBroker(t *testing.T) {
+	sender := NewMQTTSecretSender(MQTTSecretConfig{BrokerAddress: "tcp://primary:1883,tcp://standby:1883", PrimaryCheckInterval: "1h"}, false)
+	client := &fakeClient{connected: true}
+	sender.client = client
+
+	probes := 0
+	var probeErr error
+	sender.probe = func(broker string, timeout time.Duration) error {
+		probes++
+		assert.Equal(t, "tcp://primary:1883", broker)
+		assert.Equal(t, maxPrimaryProbeTimeout, timeout)
+		return probeErr
+	}
+
+	// Connected to the primary so it isn't checked
+	sender.connectedBroker = "tcp://primary:1883"
+	sender.checkPrimaryBroker(ctx)
+	assert.Zero(t, probes)
+	assert.True(t, client.connected)
+
+	// Connected to the standby while the primary is unreachable
+	sender.connectedBroker = "tcp://standby:1883"
+	probeErr = errors.New("unreachable")
+	sender.checkPrimaryBroker(ctx)
+	assert.Equal(t, 1, probes)
+	assert.True(t, client.connected)
+
+	// Not checked again until the interval has elapsed
+	probeErr = nil
+	sender.checkPrimaryBroker(ctx)
+	assert.Equal(t, 1, probes)
+	assert.True(t, client.connected)
+
+	// Disconnected once the primary is reachable so the next connect switches back to it
+	sender.lastPrimaryCheck = time.Time{}
+	sender.checkPrimaryBroker(ctx)
+	assert.Equal(t, 2, probes)
+	assert.False(t, client.connected)
+}
+
+func TestProbeBroker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+
+	assert.NoError(t, probeBroker("tcp://"+address, time.Second))
+
+	require.NoError(t, listener.Close())
+	assert.Error(t, probeBroker("tcp://"+address, time.Second))
+	assert.Error(t, probeBroker("://bogus", time.Second))
+}
@@ -0,0 +1,278 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+const (
+	// ContentTypeJSONLD is the content type of NGSI-LD entities which include their @context
+	ContentTypeJSONLD = "application/ld+json"
+	// NGSILDCoreContext is the default JSON-LD @context of the NGSI-LD entities
+	NGSILDCoreContext = "https://uri.etsi.org/ngsi-ld/v1/ngsi-ld-core-context.jsonld"
+	// DefaultNGSILDEntityType is the default entity type, which is the Event's device profile name
+	DefaultNGSILDEntityType = "{profilename}"
+	// DefaultNGSILDEntityId is the default entity id, which identifies the Event's device
+	DefaultNGSILDEntityId = "urn:ngsi-ld:{profilename}:{devicename}"
+
+	ngsiLDUpsertPath = "/ngsi-ld/v1/entityOperations/upsert"
+	// ngsiLDTimeFormat is the ISO 8601 format of the observedAt property, in UTC with millisecond precision
+	ngsiLDTimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// ngsiLDReservedNames are the entity members which readings can't be converted to
+var ngsiLDReservedNames = map[string]bool{"id": true, "type": true, "@context": true}
+
+// NGSILDConfig contains the settings for converting Events to NGSI-LD entities and exporting them to a context broker
+type NGSILDConfig struct {
+	// EntityType is the entity type, which may contain {devicename}, {profilename}, {sourcename} and other context
+	// value placeholders. Defaults to DefaultNGSILDEntityType.
+	EntityType string
+	// EntityId is the entity id URI, which may contain the same placeholders as EntityType. Defaults to
+	// DefaultNGSILDEntityId.
+	EntityId string
+	// Context is the JSON-LD @context URL of the entities. Defaults to NGSILDCoreContext.
+	Context string
+	// BrokerURL is the base URL of the NGSI-LD context broker, i.e. http://orion-ld:1026
+	BrokerURL string
+	// PersistOnError enables use of store & forward loop if true
+	PersistOnError bool
+	// HTTPHeaderName to use for passing configured secret
+	HTTPHeaderName string
+	// SecretPath to search for configured secret
+	SecretPath string
+	// SecretName for configured secret
+	SecretName string
+}
+
+// NGSILD converts Events to NGSI-LD entities, with a Property for each reading, and exports them to an NGSI-LD
+// context broker such as FIWARE Orion-LD
+type NGSILD struct {
+	config NGSILDConfig
+	sender HTTPSender
+}
+
+// NewNGSILD creates, initializes and returns a new instance of NGSILD
+func NewNGSILD(config NGSILDConfig) *NGSILD {
+	if len(config.EntityType) == 0 {
+		config.EntityType = DefaultNGSILDEntityType
+	}
+	if len(config.EntityId) == 0 {
+		config.EntityId = DefaultNGSILDEntityId
+	}
+	if len(config.Context) == 0 {
+		config.Context = NGSILDCoreContext
+	}
+
+	return &NGSILD{
+		config: config,
+		sender: NewHTTPSenderWithOptions(HTTPSenderOptions{
+			URL:            strings.TrimSuffix(config.BrokerURL, "/") + ngsiLDUpsertPath,
+			MimeType:       ContentTypeJSONLD,
+			PersistOnError: config.PersistOnError,
+			HTTPHeaderName: config.HTTPHeaderName,
+			SecretPath:     config.SecretPath,
+			SecretName:     config.SecretName,
+			// The URL is static so its placeholders, if any, are not replaced
+			URLFormatter: func(format string, _ interfaces.AppFunctionContext, _ interface{}) (string, error) {
+				return format, nil
+			},
+		}),
+	}
+}
+
+// ConvertToEntity converts the Event received to an NGSI-LD entity in JSON-LD.
+// It will return an error and stop the pipeline if a non-edgex event is received, if no data is received or if
+// the Event can not be converted.
+func (ngsi *NGSILD) ConvertToEntity(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("ConvertToEntity: No Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("ConvertToEntity: type received is not an Event")
+	}
+
+	entity, err := ngsi.toEntity(ctx, event)
+	if err != nil {
+		return false, fmt.Errorf("ConvertToEntity: %s", err.Error())
+	}
+
+	result, err := json.Marshal(entity)
+	if err != nil {
+		return false, fmt.Errorf("ConvertToEntity: unable to marshal entity: %s", err.Error())
+	}
+
+	ctx.LoggingClient().Debugf("Converted Event to NGSI-LD entity '%s'", entity["id"])
+	ctx.SetResponseContentType(ContentTypeJSONLD)
+	return true, result
+}
+
+// ExportToContextBroker creates or updates the entity in the context broker using the NGSI-LD batch upsert
+// operation. The data received is either an Event, which is converted to an entity, or an entity or array of
+// entities in JSON-LD, i.e. from ConvertToEntity.
+// It will return an error and stop the pipeline if no data is received, if the data can not be converted or if
+// the broker can not be reached or responds with a non 2xx status.
+func (ngsi *NGSILD) ExportToContextBroker(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("ExportToContextBroker: No Data Received")
+	}
+
+	if event, ok := data.(dtos.Event); ok {
+		continuePipeline, result := ngsi.ConvertToEntity(ctx, event)
+		if !continuePipeline {
+			return false, result
+		}
+		data = result
+	}
+
+	entities, err := toNGSILDEntityArray(data)
+	if err != nil {
+		return false, fmt.Errorf("ExportToContextBroker: %s", err.Error())
+	}
+
+	return ngsi.sender.HTTPPost(ctx, entities)
+}
+
+// toEntity builds the entity for the Event, keeping the latest reading of each resource
+func (ngsi *NGSILD) toEntity(ctx interfaces.AppFunctionContext, event dtos.Event) (map[string]interface{}, error) {
+	entityType, err := ngsi.applyEventValues(ctx, event, ngsi.config.EntityType)
+	if err != nil {
+		return nil, fmt.Errorf("unable to format entity type: %s", err.Error())
+	}
+	entityId, err := ngsi.applyEventValues(ctx, event, ngsi.config.EntityId)
+	if err != nil {
+		return nil, fmt.Errorf("unable to format entity id: %s", err.Error())
+	}
+
+	entity := map[string]interface{}{
+		"id":       entityId,
+		"type":     entityType,
+		"@context": ngsi.config.Context,
+	}
+
+	observed := make(map[string]int64)
+	for _, reading := range event.Readings {
+		if ngsiLDReservedNames[reading.ResourceName] {
+			return nil, fmt.Errorf("reading '%s' can not be converted as it is a reserved entity member name", reading.ResourceName)
+		}
+
+		if origin, found := observed[reading.ResourceName]; found && origin > reading.Origin {
+			continue
+		}
+
+		value, err := readingJSONValue(reading)
+		if err != nil {
+			return nil, err
+		}
+
+		entity[reading.ResourceName] = map[string]interface{}{
+			"type":       "Property",
+			"value":      value,
+			"observedAt": time.Unix(0, reading.Origin).UTC().Format(ngsiLDTimeFormat),
+		}
+		observed[reading.ResourceName] = reading.Origin
+	}
+
+	return entity, nil
+}
+
+// applyEventValues replaces the Event's placeholders, which may not have been set in the context, i.e. when the
+// Event isn't the data which triggered the pipeline, followed by the other context values
+func (ngsi *NGSILD) applyEventValues(ctx interfaces.AppFunctionContext, event dtos.Event, format string) (string, error) {
+	replacer := strings.NewReplacer(
+		"{"+interfaces.DEVICENAME+"}", event.DeviceName,
+		"{"+interfaces.PROFILENAME+"}", event.ProfileName,
+		"{"+interfaces.SOURCENAME+"}", event.SourceName)
+
+	return ctx.ApplyValues(replacer.Replace(format))
+}
+
+// readingJSONValue converts the reading value to its JSON type. Binary values are base64 encoded, as they are in JSON.
+func readingJSONValue(reading dtos.BaseReading) (interface{}, error) {
+	var err error
+	switch reading.ValueType {
+	case common.ValueTypeBool:
+		var value bool
+		if value, err = strconv.ParseBool(reading.Value); err == nil {
+			return value, nil
+		}
+	case common.ValueTypeInt8, common.ValueTypeInt16, common.ValueTypeInt32, common.ValueTypeInt64:
+		if _, err = strconv.ParseInt(reading.Value, 10, 64); err == nil {
+			return json.Number(reading.Value), nil
+		}
+	case common.ValueTypeUint8, common.ValueTypeUint16, common.ValueTypeUint32, common.ValueTypeUint64:
+		if _, err = strconv.ParseUint(reading.Value, 10, 64); err == nil {
+			return json.Number(reading.Value), nil
+		}
+	case common.ValueTypeFloat32, common.ValueTypeFloat64:
+		var value float64
+		if value, err = strconv.ParseFloat(reading.Value, 64); err == nil {
+			if !math.IsNaN(value) && !math.IsInf(value, 0) {
+				return value, nil
+			}
+			err = errors.New("NaN and infinite values are not valid JSON")
+		}
+	case common.ValueTypeBinary:
+		return base64.StdEncoding.EncodeToString(reading.BinaryValue), nil
+	default:
+		// Array values are JSON arrays
+		if strings.HasSuffix(reading.ValueType, "Array") && json.Valid([]byte(reading.Value)) {
+			return json.RawMessage(reading.Value), nil
+		}
+		return reading.Value, nil
+	}
+
+	return nil, fmt.Errorf("unable to convert '%s' reading value '%s' to %s: %s", reading.ResourceName, reading.Value, reading.ValueType, err.Error())
+}
+
+// toNGSILDEntityArray returns the JSON-LD entity, or array of entities, as an array as required by batch operations
+func toNGSILDEntityArray(data interface{}) ([]byte, error) {
+	var entities []byte
+	switch value := data.(type) {
+	case []byte:
+		entities = value
+	case string:
+		entities = []byte(value)
+	default:
+		return nil, errors.New("type received is not an Event or NGSI-LD entity")
+	}
+
+	trimmed := strings.TrimSpace(string(entities))
+	if !json.Valid([]byte(trimmed)) {
+		return nil, errors.New("data received is not a valid NGSI-LD entity")
+	}
+
+	if strings.HasPrefix(trimmed, "[") {
+		return []byte(trimmed), nil
+	}
+	return []byte("[" + trimmed + "]"), nil
+}
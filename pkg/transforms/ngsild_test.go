@@ -0,0 +1,167 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newNGSILDTestEvent(readings ...dtos.BaseReading) dtos.Event {
+	event := dtos.NewEvent("thermostat", "room-1", "status")
+	event.Readings = readings
+	return event
+}
+
+func newNGSILDTestReading(name string, valueType string, value string, origin int64) dtos.BaseReading {
+	return dtos.BaseReading{
+		DeviceName:    "room-1",
+		ResourceName:  name,
+		ProfileName:   "thermostat",
+		Origin:        origin,
+		ValueType:     valueType,
+		SimpleReading: dtos.SimpleReading{Value: value},
+	}
+}
+
+func TestNGSILDConvertToEntity(t *testing.T) {
+	event := newNGSILDTestEvent(
+		newNGSILDTestReading("temperature", common.ValueTypeFloat64, "2.15e+01", 1600000000000000000),
+		newNGSILDTestReading("temperature", common.ValueTypeFloat64, "20", 1599999999000000000),
+		newNGSILDTestReading("count", common.ValueTypeUint64, "18446744073709551615", 1600000000000000000),
+		newNGSILDTestReading("on", common.ValueTypeBool, "true", 1600000000000000000),
+		newNGSILDTestReading("mode", common.ValueTypeString, "heat", 1600000000000000000),
+		newNGSILDTestReading("setpoints", common.ValueTypeInt16Array, "[18, 21]", 1600000000000000000),
+	)
+
+	expected := `{
+		"id": "urn:ngsi-ld:thermostat:room-1",
+		"type": "thermostat",
+		"@context": "https://uri.etsi.org/ngsi-ld/v1/ngsi-ld-core-context.jsonld",
+		"temperature": {"type": "Property", "value": 21.5, "observedAt": "2020-09-13T12:26:40.000Z"},
+		"count": {"type": "Property", "value": 18446744073709551615, "observedAt": "2020-09-13T12:26:40.000Z"},
+		"on": {"type": "Property", "value": true, "observedAt": "2020-09-13T12:26:40.000Z"},
+		"mode": {"type": "Property", "value": "heat", "observedAt": "2020-09-13T12:26:40.000Z"},
+		"setpoints": {"type": "Property", "value": [18, 21], "observedAt": "2020-09-13T12:26:40.000Z"}
+	}`
+
+	ctx.SetResponseContentType("")
+	continuePipeline, result := NewNGSILD(NGSILDConfig{}).ConvertToEntity(ctx, event)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.JSONEq(t, expected, string(result.([]byte)))
+	assert.Equal(t, ContentTypeJSONLD, ctx.ResponseContentType())
+	ctx.SetResponseContentType("")
+}
+
+func TestNGSILDConvertToEntityMapping(t *testing.T) {
+	target := NewNGSILD(NGSILDConfig{
+		EntityType: "Thermostat",
+		EntityId:   "urn:ngsi-ld:Thermostat:{sourcename}:{devicename}",
+		Context:    "https://example.com/context.jsonld",
+	})
+
+	continuePipeline, result := target.ConvertToEntity(ctx, newNGSILDTestEvent())
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.JSONEq(t, `{"id":"urn:ngsi-ld:Thermostat:status:room-1","type":"Thermostat","@context":"https://example.com/context.jsonld"}`, string(result.([]byte)))
+
+	target = NewNGSILD(NGSILDConfig{EntityId: "urn:ngsi-ld:{bogus}"})
+	continuePipeline, result = target.ConvertToEntity(ctx, newNGSILDTestEvent())
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "unable to format entity id")
+	ctx.SetResponseContentType("")
+}
+
+func TestNGSILDConvertToEntityErrors(t *testing.T) {
+	target := NewNGSILD(NGSILDConfig{})
+
+	tests := []struct {
+		Name          string
+		Data          interface{}
+		ExpectedError string
+	}{
+		{"No data", nil, "No Event Received"},
+		{"Not an Event", "bogus", "not an Event"},
+		{"Reserved name", newNGSILDTestEvent(newNGSILDTestReading("type", common.ValueTypeString, "x", 1)), "reserved entity member name"},
+		{"Invalid int", newNGSILDTestEvent(newNGSILDTestReading("count", common.ValueTypeInt8, "x", 1)), "unable to convert 'count'"},
+		{"NaN", newNGSILDTestEvent(newNGSILDTestReading("level", common.ValueTypeFloat32, "NaN", 1)), "unable to convert 'level'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := target.ConvertToEntity(ctx, test.Data)
+			require.False(t, continuePipeline)
+			assert.Contains(t, result.(error).Error(), test.ExpectedError)
+		})
+	}
+}
+
+func TestNGSILDExportToContextBroker(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/ngsi-ld/v1/entityOperations/upsert", request.URL.Path)
+		assert.Equal(t, ContentTypeJSONLD, request.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(request.Body)
+		received = append(received, string(body))
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	target := NewNGSILD(NGSILDConfig{BrokerURL: server.URL + "/"})
+	event := newNGSILDTestEvent(newNGSILDTestReading("on", common.ValueTypeBool, "false", 1600000000000000000))
+
+	continuePipeline, result := target.ExportToContextBroker(ctx, event)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	require.Len(t, received, 1)
+	assert.JSONEq(t, `[{
+		"id": "urn:ngsi-ld:thermostat:room-1",
+		"type": "thermostat",
+		"@context": "https://uri.etsi.org/ngsi-ld/v1/ngsi-ld-core-context.jsonld",
+		"on": {"type": "Property", "value": false, "observedAt": "2020-09-13T12:26:40.000Z"}
+	}]`, received[0])
+
+	// Entities converted by a previous function are sent as they are
+	continuePipeline, result = target.ExportToContextBroker(ctx, []byte(` [{"id":"urn:ngsi-ld:a","type":"A"}]`))
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Equal(t, `[{"id":"urn:ngsi-ld:a","type":"A"}]`, received[1])
+
+	continuePipeline, result = target.ExportToContextBroker(ctx, `{"id":"urn:ngsi-ld:b","type":"B"}`)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Equal(t, `[{"id":"urn:ngsi-ld:b","type":"B"}]`, received[2])
+
+	continuePipeline, result = target.ExportToContextBroker(ctx, []byte("{bogus"))
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "not a valid NGSI-LD entity")
+
+	continuePipeline, result = target.ExportToContextBroker(ctx, nil)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "No Data Received")
+
+	target = NewNGSILD(NGSILDConfig{BrokerURL: server.URL + "/bogus"})
+	server.Config.Handler = http.NotFoundHandler()
+	continuePipeline, result = target.ExportToContextBroker(ctx, event)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "404")
+	ctx.SetResponseContentType("")
+}
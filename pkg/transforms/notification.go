@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+)
+
+// NotificationConfig contains the settings for the Support Notifications created by NotificationSender.
+// All the string settings, including each label, may contain placeholders, i.e. {devicename}, which
+// are replaced with the matching values from the context.
+type NotificationConfig struct {
+	// Sender identifies the sender of the notification
+	Sender string
+	// Category is the category of the notification. Category and/or Labels must be specified.
+	Category string
+	// Labels are the labels of the notification. Category and/or Labels must be specified.
+	Labels []string
+	// Severity of the notification, one of MINOR, NORMAL or CRITICAL
+	Severity string
+	// Content is the content of the notification. The data received is used as the content when not specified.
+	Content string
+	// ContentType is the content type of the Content. Defaults to text/plain or application/json when
+	// the data received is used as the content.
+	ContentType string
+	// Description is the optional description of the notification
+	Description string
+}
+
+// NotificationSender houses the configuration for creating Support Notifications
+type NotificationSender struct {
+	config NotificationConfig
+}
+
+// NewNotificationSender creates, initializes and returns a new instance of NotificationSender
+func NewNotificationSender(config NotificationConfig) NotificationSender {
+	return NotificationSender{
+		config: config,
+	}
+}
+
+// SendNotification creates a Support Notification from the configured settings and the data received using the
+// Notification client, so that subscribers are alerted via their configured channels. The data received is passed
+// on to the next function.
+// It will return an error and stop the pipeline if no data is received, the Notification client is not configured
+// or the Notification can not be created.
+func (sender NotificationSender) SendNotification(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("SendNotification: no data received")
+	}
+
+	client := ctx.NotificationClient()
+	if client == nil {
+		return false, errors.New("NotificationClient not initialized. Support Notifications is missing from clients configuration")
+	}
+
+	notification, err := sender.createNotification(ctx, data)
+	if err != nil {
+		return false, err
+	}
+
+	ctx.LoggingClient().Debugf("Sending %s notification with category '%s' and labels '%s'",
+		notification.Severity,
+		notification.Category,
+		strings.Join(notification.Labels, ","))
+
+	request := requests.NewAddNotificationRequest(notification)
+	responses, edgexErr := client.SendNotification(context.Background(), []requests.AddNotificationRequest{request})
+	if edgexErr != nil {
+		return false, fmt.Errorf("unable to send notification: %s", edgexErr.Error())
+	}
+
+	for _, response := range responses {
+		if response.StatusCode >= 300 {
+			return false, fmt.Errorf("unable to send notification: %s", response.Message)
+		}
+	}
+
+	ctx.LoggingClient().Trace("Data exported", "Transport", "Notification", common.CorrelationHeader, ctx.CorrelationID())
+
+	return true, data
+}
+
+func (sender NotificationSender) createNotification(ctx interfaces.AppFunctionContext, data interface{}) (dtos.Notification, error) {
+	var err error
+	config := sender.config
+
+	for _, setting := range []*string{&config.Sender, &config.Category, &config.Severity, &config.Content, &config.Description} {
+		if *setting, err = ctx.ApplyValues(*setting); err != nil {
+			return dtos.Notification{}, fmt.Errorf("unable to format notification: %s", err.Error())
+		}
+	}
+
+	labels := make([]string, 0, len(config.Labels))
+	for _, label := range config.Labels {
+		label, err = ctx.ApplyValues(label)
+		if err != nil {
+			return dtos.Notification{}, fmt.Errorf("unable to format notification label: %s", err.Error())
+		}
+		labels = append(labels, label)
+	}
+
+	severity := strings.ToUpper(config.Severity)
+	if severity != models.Minor && severity != models.Normal && severity != models.Critical {
+		return dtos.Notification{}, fmt.Errorf(
+			"invalid notification severity '%s'. Must be one of %s, %s or %s",
+			config.Severity,
+			models.Minor,
+			models.Normal,
+			models.Critical)
+	}
+
+	contentType := config.ContentType
+	if len(config.Content) == 0 {
+		content, err := util.CoerceType(data)
+		if err != nil {
+			return dtos.Notification{}, err
+		}
+
+		config.Content = string(content)
+		if len(contentType) == 0 {
+			contentType = common.ContentTypeText
+			if json.Valid(content) {
+				contentType = common.ContentTypeJSON
+			}
+		}
+	}
+
+	notification := dtos.NewNotification(labels, config.Category, config.Content, config.Sender, severity)
+	notification.ContentType = contentType
+	notification.Description = config.Description
+
+	return notification, nil
+}
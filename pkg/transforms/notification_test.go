@@ -0,0 +1,150 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	dtoCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+	edgexErrors "github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newNotificationTestContext(client *clientMocks.NotificationClient) *appfunction.Context {
+	notificationDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return lc
+		},
+	})
+
+	if client != nil {
+		notificationDic.Update(di.ServiceConstructorMap{
+			container.NotificationClientName: func(get di.Get) interface{} {
+				return client
+			},
+		})
+	}
+
+	notificationCtx := appfunction.NewContext("123", notificationDic, "")
+	notificationCtx.AddValue(interfaces.DEVICENAME, "device1")
+	return notificationCtx
+}
+
+func TestNotificationSender_SendNotification(t *testing.T) {
+	var actual requests.AddNotificationRequest
+	client := &clientMocks.NotificationClient{}
+	client.On("SendNotification", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			actual = args.Get(1).([]requests.AddNotificationRequest)[0]
+		}).
+		Return([]dtoCommon.BaseWithIdResponse{dtoCommon.NewBaseWithIdResponse("", "", http.StatusCreated, "id")}, nil)
+
+	target := NewNotificationSender(NotificationConfig{
+		Sender:      "app-{devicename}",
+		Category:    "alerts",
+		Labels:      []string{"temperature", "{devicename}"},
+		Severity:    "critical",
+		Description: "Alert from {devicename}",
+	})
+
+	data := []byte(`{"temperature":100}`)
+	continuePipeline, result := target.SendNotification(newNotificationTestContext(client), data)
+	require.True(t, continuePipeline, result)
+	assert.Equal(t, data, result)
+
+	notification := actual.Notification
+	assert.Equal(t, "app-device1", notification.Sender)
+	assert.Equal(t, "alerts", notification.Category)
+	assert.Equal(t, []string{"temperature", "device1"}, notification.Labels)
+	assert.Equal(t, "CRITICAL", notification.Severity)
+	assert.Equal(t, `{"temperature":100}`, notification.Content)
+	assert.Equal(t, common.ContentTypeJSON, notification.ContentType)
+	assert.Equal(t, "Alert from device1", notification.Description)
+}
+
+func TestNotificationSender_SendNotificationContent(t *testing.T) {
+	var actual requests.AddNotificationRequest
+	client := &clientMocks.NotificationClient{}
+	client.On("SendNotification", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			actual = args.Get(1).([]requests.AddNotificationRequest)[0]
+		}).
+		Return([]dtoCommon.BaseWithIdResponse{dtoCommon.NewBaseWithIdResponse("", "", http.StatusCreated, "id")}, nil)
+
+	target := NewNotificationSender(NotificationConfig{
+		Sender:   "app",
+		Category: "alerts",
+		Severity: "NORMAL",
+		Content:  "Threshold exceeded on {devicename}",
+	})
+
+	continuePipeline, result := target.SendNotification(newNotificationTestContext(client), "not used")
+	require.True(t, continuePipeline, result)
+	assert.Equal(t, "Threshold exceeded on device1", actual.Notification.Content)
+	assert.Empty(t, actual.Notification.ContentType)
+}
+
+func TestNotificationSender_SendNotificationErrors(t *testing.T) {
+	validConfig := NotificationConfig{Sender: "app", Category: "alerts", Severity: "MINOR"}
+
+	failedClient := &clientMocks.NotificationClient{}
+	failedClient.On("SendNotification", mock.Anything, mock.Anything).
+		Return(nil, edgexErrors.NewCommonEdgeX(edgexErrors.KindServerError, "failed", nil))
+
+	rejectedClient := &clientMocks.NotificationClient{}
+	rejectedClient.On("SendNotification", mock.Anything, mock.Anything).
+		Return([]dtoCommon.BaseWithIdResponse{dtoCommon.NewBaseWithIdResponse("", "invalid", http.StatusBadRequest, "")}, nil)
+
+	tests := []struct {
+		Name          string
+		Config        NotificationConfig
+		Client        *clientMocks.NotificationClient
+		Data          interface{}
+		ErrorContains string
+	}{
+		{"No data", validConfig, rejectedClient, nil, "no data received"},
+		{"No client", validConfig, nil, "data", "NotificationClient not initialized"},
+		{"Bad severity", NotificationConfig{Sender: "app", Category: "alerts", Severity: "HIGH"}, rejectedClient, "data", "severity"},
+		{"Missing value", NotificationConfig{Sender: "{missing}", Category: "alerts", Severity: "MINOR"}, rejectedClient, "data", "unable to format"},
+		{"Send failed", validConfig, failedClient, "data", "failed"},
+		{"Send rejected", validConfig, rejectedClient, "data", "invalid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := NewNotificationSender(test.Config)
+			continuePipeline, result := target.SendNotification(newNotificationTestContext(test.Client), test.Data)
+			require.False(t, continuePipeline)
+			err, ok := result.(error)
+			require.True(t, ok)
+			assert.Contains(t, err.Error(), test.ErrorContains)
+		})
+	}
+}
@@ -0,0 +1,279 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	edgexErrors "github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+)
+
+// ReadingTypeViolationsTag is the Event tag set to the readings whose values can't be parsed as their declared
+// ValueType when invalid readings are flagged rather than dropped
+const ReadingTypeViolationsTag = "ReadingTypeViolations"
+
+// ReadingTypeCoercion houses the Device Resource ValueTypes, retrieved from Core Metadata, that reading values are
+// validated and coerced against
+type ReadingTypeCoercion struct {
+	dropInvalid bool
+	// valueTypes caches the declared ValueType by profile and resource name. An empty ValueType means the
+	// resource isn't declared in the profile, in which case the reading's own ValueType is used.
+	valueTypes map[string]string
+	lock       sync.RWMutex
+}
+
+// NewReadingTypeCoercion creates, initializes and returns a new instance of ReadingTypeCoercion.
+// When dropInvalid is true, readings whose values can't be parsed as their declared ValueType are dropped from the Event,
+// otherwise they are passed on unchanged and the Event is tagged with the violations.
+func NewReadingTypeCoercion(dropInvalid bool) *ReadingTypeCoercion {
+	return &ReadingTypeCoercion{
+		dropInvalid: dropInvalid,
+		valueTypes:  make(map[string]string),
+	}
+}
+
+// Coerce validates each reading's Value against the ValueType declared for its resource in the Device Profile, which is
+// retrieved from Core Metadata and cached, so that downstream typed stores only receive values they can parse.
+// Valid values are normalized to the EdgeX format for the declared ValueType, i.e. " 42 " becomes "42" and "1.5" becomes
+// "1.500000e+00" for a Float64, and the reading's ValueType is set to the declared ValueType.
+// Binary readings and resources not declared in the profile are validated against the reading's own ValueType.
+// This function will return an error and stop the pipeline if a non-edgex event is received, if no data is received or
+// if Core Metadata is not configured or can't be reached. The pipeline is stopped if all readings are dropped.
+func (c *ReadingTypeCoercion) Coerce(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Coerce: no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("Coerce: type received is not an Event")
+	}
+
+	ctx.LoggingClient().Debugf("Coercing reading values for Event from device '%s'", event.DeviceName)
+
+	readings := make([]dtos.BaseReading, 0, len(event.Readings))
+	var violations []string
+
+	for _, reading := range event.Readings {
+		valueType, err := c.valueType(ctx, reading)
+		if err != nil {
+			return false, err
+		}
+
+		if valueType == common.ValueTypeBinary {
+			readings = append(readings, reading)
+			continue
+		}
+
+		value, err := coerceReadingValue(valueType, reading.Value)
+		if err != nil {
+			violation := fmt.Sprintf("reading '%s' value '%s' is not a valid %s: %s",
+				reading.ResourceName, reading.Value, valueType, err.Error())
+			if c.dropInvalid {
+				ctx.LoggingClient().Warnf("Dropping %s", violation)
+				continue
+			}
+
+			ctx.LoggingClient().Warnf("Flagging %s", violation)
+			violations = append(violations, violation)
+			readings = append(readings, reading)
+			continue
+		}
+
+		reading.ValueType = valueType
+		reading.Value = value
+		readings = append(readings, reading)
+	}
+
+	if len(readings) == 0 {
+		ctx.LoggingClient().Debug("Event not accepted: 0 remaining readings")
+		ctx.SetFiltered("no readings remaining")
+		return false, nil
+	}
+
+	event.Readings = readings
+
+	if len(violations) > 0 {
+		tags := make(map[string]string, len(event.Tags)+1)
+		for tag, value := range event.Tags {
+			tags[tag] = value
+		}
+		tags[ReadingTypeViolationsTag] = strings.Join(violations, "; ")
+		event.Tags = tags
+	}
+
+	return true, event
+}
+
+// valueType returns the ValueType declared in the Device Profile for the reading's resource
+func (c *ReadingTypeCoercion) valueType(ctx interfaces.AppFunctionContext, reading dtos.BaseReading) (string, error) {
+	if reading.ValueType == common.ValueTypeBinary {
+		return reading.ValueType, nil
+	}
+
+	key := reading.ProfileName + "/" + reading.ResourceName
+
+	c.lock.RLock()
+	valueType, found := c.valueTypes[key]
+	c.lock.RUnlock()
+
+	if !found {
+		client := ctx.DeviceProfileClient()
+		if client == nil {
+			return "", errors.New("DeviceProfileClient not initialized. Core Metadata is missing from clients configuration")
+		}
+
+		response, err := client.DeviceResourceByProfileNameAndResourceName(context.Background(), reading.ProfileName, reading.ResourceName)
+		switch {
+		case err == nil:
+			valueType = response.Resource.Properties.ValueType
+		case edgexErrors.Kind(err) == edgexErrors.KindEntityDoesNotExist:
+			ctx.LoggingClient().Warnf("Resource '%s' not found in profile '%s', using the reading's ValueType",
+				reading.ResourceName, reading.ProfileName)
+		default:
+			return "", fmt.Errorf("unable to get resource '%s' of profile '%s' from Core Metadata: %s",
+				reading.ResourceName, reading.ProfileName, err.Error())
+		}
+
+		c.lock.Lock()
+		c.valueTypes[key] = valueType
+		c.lock.Unlock()
+	}
+
+	if len(valueType) == 0 {
+		return reading.ValueType, nil
+	}
+
+	return valueType, nil
+}
+
+// coerceReadingValue parses the value as the specified ValueType and returns it in the format EdgeX uses for that type
+func coerceReadingValue(valueType string, value string) (string, error) {
+	if strings.HasSuffix(valueType, "Array") {
+		return coerceReadingArrayValue(strings.TrimSuffix(valueType, "Array"), value)
+	}
+
+	trimmed := strings.TrimSpace(value)
+
+	switch valueType {
+	case common.ValueTypeString:
+		return value, nil
+
+	case common.ValueTypeBool:
+		parsed, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return "", errors.New("not a bool")
+		}
+		return strconv.FormatBool(parsed), nil
+
+	case common.ValueTypeUint8, common.ValueTypeUint16, common.ValueTypeUint32, common.ValueTypeUint64:
+		bitSize, _ := strconv.Atoi(strings.TrimPrefix(valueType, "Uint"))
+		parsed, err := strconv.ParseUint(trimmed, 10, bitSize)
+		if err != nil {
+			// Integers are sometimes sent in floating point notation, i.e. 4.2e+01, so accept those without a fraction
+			float, floatErr := parseIntegralFloat(trimmed)
+			if floatErr != nil || float < 0 || float >= math.Ldexp(1, bitSize) {
+				return "", fmt.Errorf("not an unsigned %d bit integer", bitSize)
+			}
+			parsed = uint64(float)
+		}
+		return strconv.FormatUint(parsed, 10), nil
+
+	case common.ValueTypeInt8, common.ValueTypeInt16, common.ValueTypeInt32, common.ValueTypeInt64:
+		bitSize, _ := strconv.Atoi(strings.TrimPrefix(valueType, "Int"))
+		parsed, err := strconv.ParseInt(trimmed, 10, bitSize)
+		if err != nil {
+			float, floatErr := parseIntegralFloat(trimmed)
+			limit := math.Ldexp(1, bitSize-1)
+			if floatErr != nil || float < -limit || float >= limit {
+				return "", fmt.Errorf("not a %d bit integer", bitSize)
+			}
+			parsed = int64(float)
+		}
+		return strconv.FormatInt(parsed, 10), nil
+
+	case common.ValueTypeFloat32:
+		parsed, err := strconv.ParseFloat(trimmed, 32)
+		if err != nil {
+			return "", errors.New("not a 32 bit float")
+		}
+		return fmt.Sprintf("%e", float32(parsed)), nil
+
+	case common.ValueTypeFloat64:
+		parsed, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return "", errors.New("not a 64 bit float")
+		}
+		return fmt.Sprintf("%e", parsed), nil
+	}
+
+	return "", errors.New("unsupported ValueType")
+}
+
+// coerceReadingArrayValue parses the value as an array, i.e. "[1, 2, 3]", of the specified element ValueType
+func coerceReadingArrayValue(elementType string, value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return "", errors.New("not an array")
+	}
+
+	trimmed = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+	if len(trimmed) == 0 {
+		return "[]", nil
+	}
+
+	elements := strings.Split(trimmed, ",")
+	for index, element := range elements {
+		element = strings.TrimSpace(element)
+		if elementType == common.ValueTypeString {
+			elements[index] = element
+			continue
+		}
+
+		coerced, err := coerceReadingValue(elementType, element)
+		if err != nil {
+			return "", fmt.Errorf("element %d is %s", index, err.Error())
+		}
+		elements[index] = coerced
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]", nil
+}
+
+// parseIntegralFloat parses the value as a float which must not have a fractional part
+func parseIntegralFloat(value string) (float64, error) {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if math.IsInf(parsed, 0) || math.IsNaN(parsed) || parsed != math.Trunc(parsed) {
+		return 0, errors.New("not an integral value")
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,250 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/responses"
+	edgexErrors "github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newCoercionTestContext(client *clientMocks.DeviceProfileClient) *appfunction.Context {
+	coercionDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return lc
+		},
+	})
+
+	if client != nil {
+		coercionDic.Update(di.ServiceConstructorMap{
+			container.DeviceProfileClientName: func(get di.Get) interface{} {
+				return client
+			},
+		})
+	}
+
+	return appfunction.NewContext("123", coercionDic, "")
+}
+
+func newCoercionProfileClient(valueTypes map[string]string) *clientMocks.DeviceProfileClient {
+	client := &clientMocks.DeviceProfileClient{}
+	for resourceName, valueType := range valueTypes {
+		response := responses.DeviceResourceResponse{
+			Resource: dtos.DeviceResource{
+				Name:       resourceName,
+				Properties: dtos.ResourceProperties{ValueType: valueType},
+			},
+		}
+		client.On("DeviceResourceByProfileNameAndResourceName", mock.Anything, "profile1", resourceName).Return(response, nil)
+	}
+
+	client.On("DeviceResourceByProfileNameAndResourceName", mock.Anything, mock.Anything, mock.Anything).
+		Return(responses.DeviceResourceResponse{}, edgexErrors.NewCommonEdgeX(edgexErrors.KindEntityDoesNotExist, "not found", nil))
+
+	return client
+}
+
+func newCoercionTestEvent(values map[string]string) dtos.Event {
+	event := dtos.NewEvent("profile1", "device1", "source1")
+	for _, resourceName := range []string{"temperature", "count", "enabled", "levels", "label", "unknown"} {
+		value, ok := values[resourceName]
+		if !ok {
+			continue
+		}
+		_ = event.AddSimpleReading(resourceName, common.ValueTypeString, value)
+	}
+	return event
+}
+
+func TestReadingTypeCoercion_Coerce(t *testing.T) {
+	client := newCoercionProfileClient(map[string]string{
+		"temperature": common.ValueTypeFloat64,
+		"count":       common.ValueTypeUint8,
+		"enabled":     common.ValueTypeBool,
+		"levels":      common.ValueTypeInt16Array,
+		"label":       common.ValueTypeString,
+	})
+
+	event := newCoercionTestEvent(map[string]string{
+		"temperature": "21.5",
+		"count":       " 4.2e+01 ",
+		"enabled":     "TRUE",
+		"levels":      "[1,-2, 3]",
+		"label":       " kitchen ",
+		"unknown":     "anything",
+	})
+
+	target := NewReadingTypeCoercion(true)
+	continuePipeline, result := target.Coerce(newCoercionTestContext(client), event)
+	require.True(t, continuePipeline, result)
+
+	actual, ok := result.(dtos.Event)
+	require.True(t, ok)
+	require.Len(t, actual.Readings, 6)
+
+	expected := []struct {
+		valueType string
+		value     string
+	}{
+		{common.ValueTypeFloat64, "2.150000e+01"},
+		{common.ValueTypeUint8, "42"},
+		{common.ValueTypeBool, "true"},
+		{common.ValueTypeInt16Array, "[1, -2, 3]"},
+		{common.ValueTypeString, " kitchen "},
+		{common.ValueTypeString, "anything"},
+	}
+
+	for index, reading := range actual.Readings {
+		assert.Equal(t, expected[index].valueType, reading.ValueType, reading.ResourceName)
+		assert.Equal(t, expected[index].value, reading.Value, reading.ResourceName)
+	}
+	assert.NotContains(t, actual.Tags, ReadingTypeViolationsTag)
+
+	// Device Resources are cached so Core Metadata is only called once per resource
+	continuePipeline, result = target.Coerce(newCoercionTestContext(client), event)
+	require.True(t, continuePipeline, result)
+	client.AssertNumberOfCalls(t, "DeviceResourceByProfileNameAndResourceName", 6)
+}
+
+func TestReadingTypeCoercion_CoerceInvalid(t *testing.T) {
+	client := newCoercionProfileClient(map[string]string{
+		"temperature": common.ValueTypeFloat32,
+		"count":       common.ValueTypeUint8,
+		"enabled":     common.ValueTypeBool,
+		"levels":      common.ValueTypeInt8Array,
+	})
+
+	event := newCoercionTestEvent(map[string]string{
+		"temperature": "warm",
+		"count":       "256",
+		"enabled":     "yes",
+		"levels":      "[1, 2, 300]",
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		target := NewReadingTypeCoercion(true)
+		continuePipeline, result := target.Coerce(newCoercionTestContext(client), event)
+		assert.False(t, continuePipeline)
+		assert.Nil(t, result)
+	})
+
+	t.Run("drop some", func(t *testing.T) {
+		partial := newCoercionTestEvent(map[string]string{"temperature": "warm", "count": "7"})
+		target := NewReadingTypeCoercion(true)
+		continuePipeline, result := target.Coerce(newCoercionTestContext(client), partial)
+		require.True(t, continuePipeline, result)
+		actual := result.(dtos.Event)
+		require.Len(t, actual.Readings, 1)
+		assert.Equal(t, "count", actual.Readings[0].ResourceName)
+		assert.Equal(t, "7", actual.Readings[0].Value)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		target := NewReadingTypeCoercion(false)
+		continuePipeline, result := target.Coerce(newCoercionTestContext(client), event)
+		require.True(t, continuePipeline, result)
+		actual := result.(dtos.Event)
+		require.Len(t, actual.Readings, 4)
+		assert.Equal(t, event.Readings, actual.Readings)
+
+		violations := actual.Tags[ReadingTypeViolationsTag]
+		assert.Contains(t, violations, "reading 'temperature' value 'warm' is not a valid Float32")
+		assert.Contains(t, violations, "reading 'count' value '256' is not a valid Uint8")
+		assert.Contains(t, violations, "reading 'enabled' value 'yes' is not a valid Bool")
+		assert.Contains(t, violations, "reading 'levels' value '[1, 2, 300]' is not a valid Int8Array")
+		assert.Nil(t, event.Tags, "original Event must not be modified")
+	})
+}
+
+func TestReadingTypeCoercion_CoerceErrors(t *testing.T) {
+	event := newCoercionTestEvent(map[string]string{"count": "1"})
+
+	failingClient := &clientMocks.DeviceProfileClient{}
+	failingClient.On("DeviceResourceByProfileNameAndResourceName", mock.Anything, mock.Anything, mock.Anything).
+		Return(responses.DeviceResourceResponse{}, edgexErrors.NewCommonEdgeX(edgexErrors.KindServiceUnavailable, "unavailable", nil))
+
+	tests := []struct {
+		Name          string
+		Client        *clientMocks.DeviceProfileClient
+		Data          interface{}
+		ExpectedError string
+	}{
+		{"No Data", newCoercionProfileClient(nil), nil, "no Event Received"},
+		{"Not Event", newCoercionProfileClient(nil), "not an event", "type received is not an Event"},
+		{"No Client", nil, event, "Core Metadata is missing"},
+		{"Metadata Unavailable", failingClient, event, "unable to get resource 'count' of profile 'profile1'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := NewReadingTypeCoercion(true)
+			continuePipeline, result := target.Coerce(newCoercionTestContext(test.Client), test.Data)
+			assert.False(t, continuePipeline)
+			err, ok := result.(error)
+			require.True(t, ok)
+			assert.Contains(t, err.Error(), test.ExpectedError)
+		})
+	}
+}
+
+func TestCoerceReadingValue(t *testing.T) {
+	tests := []struct {
+		ValueType   string
+		Value       string
+		Expected    string
+		ExpectError bool
+	}{
+		{common.ValueTypeInt8, "-128", "-128", false},
+		{common.ValueTypeInt8, "128", "", true},
+		{common.ValueTypeInt64, "-1e3", "-1000", false},
+		{common.ValueTypeInt32, "1.5", "", true},
+		{common.ValueTypeUint16, "-1", "", true},
+		{common.ValueTypeUint64, "18446744073709551615", "18446744073709551615", false},
+		{common.ValueTypeFloat32, "3.4e39", "", true},
+		{common.ValueTypeFloat32, "1", "1.000000e+00", false},
+		{common.ValueTypeBoolArray, "[true, 0]", "[true, false]", false},
+		{common.ValueTypeStringArray, "[a,b]", "[a, b]", false},
+		{common.ValueTypeFloat64Array, "[]", "[]", false},
+		{common.ValueTypeUint8Array, "1, 2", "", true},
+		{"Object", "{}", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ValueType+" "+test.Value, func(t *testing.T) {
+			actual, err := coerceReadingValue(test.ValueType, test.Value)
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+)
+
+const (
+	// RedactModeHash replaces the values with their hex encoded SHA-256 hash, or HMAC-SHA256 when a hash key is set,
+	// so they can still be correlated without being revealed
+	RedactModeHash = "hash"
+	// RedactModeMask replaces the values with RedactMask
+	RedactModeMask = "mask"
+	// RedactModeDrop removes the readings and tags
+	RedactModeDrop = "drop"
+	// RedactMask is the value masked readings and tags are set to
+	RedactMask = "********"
+)
+
+// Redact houses the resource names and tag keys of the personally identifiable or sensitive values, i.e. serial
+// numbers or GPS coordinates, which are redacted before the Event leaves the site
+type Redact struct {
+	mode          string
+	resourceNames []string
+	tagKeys       []string
+	hashKey       string
+	secretPath    string
+	secretName    string
+}
+
+// NewRedact creates, initializes and returns a new instance of Redact which redacts the readings for the specified
+// resource names and the tags with the specified keys using the specified mode, i.e. RedactModeHash.
+// hashKey is optional and, when set, is used to hash with HMAC-SHA256 so that hashes of values with few possible
+// values, i.e. serial numbers, can't be reversed by hashing every possible value.
+// An error is returned if the mode is unknown or no resource names or tag keys are specified.
+func NewRedact(mode string, resourceNames []string, tagKeys []string, hashKey string) (*Redact, error) {
+	return newRedact(mode, resourceNames, tagKeys, hashKey, "", "")
+}
+
+// NewRedactWithSecrets creates, initializes and returns a new instance of Redact the same as NewRedact, but configured
+// to retrieve the HMAC-SHA256 hash key from the Secret Store
+func NewRedactWithSecrets(mode string, resourceNames []string, tagKeys []string, secretPath string, secretName string) (*Redact, error) {
+	if len(secretPath) == 0 || len(secretName) == 0 {
+		return nil, errors.New("secret path and secret name must both be specified")
+	}
+
+	return newRedact(mode, resourceNames, tagKeys, "", secretPath, secretName)
+}
+
+func newRedact(mode string, resourceNames []string, tagKeys []string, hashKey string, secretPath string, secretName string) (*Redact, error) {
+	mode = strings.ToLower(mode)
+	switch mode {
+	case RedactModeHash, RedactModeMask, RedactModeDrop:
+	default:
+		return nil, fmt.Errorf("invalid redact mode '%s'. Must be '%s', '%s' or '%s'",
+			mode, RedactModeHash, RedactModeMask, RedactModeDrop)
+	}
+
+	if len(resourceNames) == 0 && len(tagKeys) == 0 {
+		return nil, errors.New("redact requires at least one resource name or tag key")
+	}
+
+	return &Redact{
+		mode:          mode,
+		resourceNames: resourceNames,
+		tagKeys:       tagKeys,
+		hashKey:       hashKey,
+		secretPath:    secretPath,
+		secretName:    secretName,
+	}, nil
+}
+
+// Redact hashes, masks or drops the Event's readings for the configured resource names and its tags with the
+// configured keys. Hashed and masked readings are converted to String readings, including Binary readings whose
+// binary value is removed. The Event received is not modified, a redacted copy is passed on.
+// This function will return an error and stop the pipeline if a non-edgex event is received, if no data is received
+// or if the hash key can't be retrieved from the Secret Store.
+func (r *Redact) Redact(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Redact: no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("Redact: type received is not an Event")
+	}
+
+	ctx.LoggingClient().Debugf("Redacting (%s) resources '%v' and tags '%v'", r.mode, r.resourceNames, r.tagKeys)
+
+	hashKey, err := r.getHashKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if len(r.resourceNames) > 0 {
+		readings := make([]dtos.BaseReading, 0, len(event.Readings))
+		for _, reading := range event.Readings {
+			if !redactContains(r.resourceNames, reading.ResourceName) {
+				readings = append(readings, reading)
+				continue
+			}
+
+			if r.mode == RedactModeDrop {
+				continue
+			}
+
+			value := reading.Value
+			if reading.ValueType == common.ValueTypeBinary {
+				value = string(reading.BinaryValue)
+				reading.BinaryReading = dtos.BinaryReading{}
+			}
+
+			reading.ValueType = common.ValueTypeString
+			reading.SimpleReading = dtos.SimpleReading{Value: r.redactValue(value, hashKey)}
+			readings = append(readings, reading)
+		}
+		event.Readings = readings
+	}
+
+	if len(r.tagKeys) > 0 && len(event.Tags) > 0 {
+		tags := make(map[string]string, len(event.Tags))
+		for key, value := range event.Tags {
+			if !redactContains(r.tagKeys, key) {
+				tags[key] = value
+				continue
+			}
+
+			if r.mode != RedactModeDrop {
+				tags[key] = r.redactValue(value, hashKey)
+			}
+		}
+		event.Tags = tags
+	}
+
+	return true, event
+}
+
+func (r *Redact) redactValue(value string, hashKey string) string {
+	if r.mode == RedactModeMask {
+		return RedactMask
+	}
+
+	if len(hashKey) > 0 {
+		hash := hmac.New(sha256.New, []byte(hashKey))
+		_, _ = hash.Write([]byte(value))
+		return hex.EncodeToString(hash.Sum(nil))
+	}
+
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// getHashKey returns the hash key from configuration or from the Secret Store
+func (r *Redact) getHashKey(ctx interfaces.AppFunctionContext) (string, error) {
+	if r.mode != RedactModeHash || len(r.secretPath) == 0 {
+		return r.hashKey, nil
+	}
+
+	// Note secrets are cached so this call doesn't result in unneeded calls to SecretStore Service
+	secretData, err := ctx.GetSecret(r.secretPath, r.secretName)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve hash key at secret path=%s and name=%s", r.secretPath, r.secretName)
+	}
+
+	key, ok := secretData[r.secretName]
+	if !ok || len(key) == 0 {
+		return "", fmt.Errorf("unable to find hash key in secret data for name=%s", r.secretName)
+	}
+
+	return key, nil
+}
+
+func redactContains(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedactTestEvent() dtos.Event {
+	event := dtos.NewEvent("profile1", "device1", "source1")
+	_ = event.AddSimpleReading("serialNumber", common.ValueTypeString, "SN-12345")
+	_ = event.AddSimpleReading("latitude", common.ValueTypeFloat64, 45.5)
+	_ = event.AddSimpleReading("temperature", common.ValueTypeInt32, int32(21))
+	event.AddBinaryReading("photo", []byte("jpeg bytes"), "image/jpeg")
+	event.Tags = map[string]string{"owner": "jane", "site": "plant-1"}
+	return event
+}
+
+func redactTestHash(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestNewRedact(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Mode          string
+		ResourceNames []string
+		TagKeys       []string
+		ExpectError   bool
+	}{
+		{"Hash", "hash", []string{"serialNumber"}, nil, false},
+		{"Mask upper case", "MASK", nil, []string{"owner"}, false},
+		{"Drop", "drop", []string{"serialNumber"}, []string{"owner"}, false},
+		{"Bad mode", "scramble", []string{"serialNumber"}, nil, true},
+		{"Nothing to redact", "hash", nil, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := NewRedact(test.Mode, test.ResourceNames, test.TagKeys, "")
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	_, err := NewRedactWithSecrets(RedactModeHash, []string{"serialNumber"}, nil, "redact", "")
+	require.Error(t, err)
+}
+
+func TestRedact_Redact(t *testing.T) {
+	resourceNames := []string{"serialNumber", "latitude", "photo"}
+	tagKeys := []string{"owner"}
+
+	t.Run("hash", func(t *testing.T) {
+		event := newRedactTestEvent()
+		target, err := NewRedact(RedactModeHash, resourceNames, tagKeys, "")
+		require.NoError(t, err)
+
+		continuePipeline, result := target.Redact(ctx, event)
+		require.True(t, continuePipeline, result)
+		actual := result.(dtos.Event)
+
+		require.Len(t, actual.Readings, 4)
+		assert.Equal(t, redactTestHash("SN-12345"), actual.Readings[0].Value)
+		assert.Equal(t, common.ValueTypeString, actual.Readings[0].ValueType)
+		assert.Equal(t, redactTestHash(event.Readings[1].Value), actual.Readings[1].Value)
+		assert.Equal(t, common.ValueTypeString, actual.Readings[1].ValueType)
+		assert.Equal(t, event.Readings[2], actual.Readings[2])
+		assert.Equal(t, redactTestHash("jpeg bytes"), actual.Readings[3].Value)
+		assert.Equal(t, common.ValueTypeString, actual.Readings[3].ValueType)
+		assert.Nil(t, actual.Readings[3].BinaryValue)
+		assert.Empty(t, actual.Readings[3].MediaType)
+		assert.Equal(t, map[string]string{"owner": redactTestHash("jane"), "site": "plant-1"}, actual.Tags)
+
+		// The Event received must not be modified
+		assert.Equal(t, newRedactTestEvent().Readings[0].Value, event.Readings[0].Value)
+		assert.Equal(t, "jane", event.Tags["owner"])
+	})
+
+	t.Run("hash with key", func(t *testing.T) {
+		target, err := NewRedact(RedactModeHash, resourceNames, tagKeys, "site-key")
+		require.NoError(t, err)
+
+		continuePipeline, result := target.Redact(ctx, newRedactTestEvent())
+		require.True(t, continuePipeline, result)
+
+		hash := hmac.New(sha256.New, []byte("site-key"))
+		_, _ = hash.Write([]byte("SN-12345"))
+		assert.Equal(t, hex.EncodeToString(hash.Sum(nil)), result.(dtos.Event).Readings[0].Value)
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		target, err := NewRedact(RedactModeMask, resourceNames, tagKeys, "")
+		require.NoError(t, err)
+
+		continuePipeline, result := target.Redact(ctx, newRedactTestEvent())
+		require.True(t, continuePipeline, result)
+		actual := result.(dtos.Event)
+
+		require.Len(t, actual.Readings, 4)
+		assert.Equal(t, RedactMask, actual.Readings[0].Value)
+		assert.Equal(t, RedactMask, actual.Readings[1].Value)
+		assert.Equal(t, "21", actual.Readings[2].Value)
+		assert.Equal(t, RedactMask, actual.Readings[3].Value)
+		assert.Equal(t, map[string]string{"owner": RedactMask, "site": "plant-1"}, actual.Tags)
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		target, err := NewRedact(RedactModeDrop, resourceNames, tagKeys, "")
+		require.NoError(t, err)
+
+		continuePipeline, result := target.Redact(ctx, newRedactTestEvent())
+		require.True(t, continuePipeline, result)
+		actual := result.(dtos.Event)
+
+		require.Len(t, actual.Readings, 1)
+		assert.Equal(t, "temperature", actual.Readings[0].ResourceName)
+		assert.Equal(t, map[string]string{"site": "plant-1"}, actual.Tags)
+	})
+}
+
+func TestRedact_RedactWithSecrets(t *testing.T) {
+	secretPath := "redact"
+	secretName := "hashKey"
+
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", secretPath, secretName).Return(map[string]string{secretName: "secret-key"}, nil)
+
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	target, err := NewRedactWithSecrets(RedactModeHash, []string{"serialNumber"}, nil, secretPath, secretName)
+	require.NoError(t, err)
+
+	continuePipeline, result := target.Redact(ctx, newRedactTestEvent())
+	require.True(t, continuePipeline, result)
+
+	hash := hmac.New(sha256.New, []byte("secret-key"))
+	_, _ = hash.Write([]byte("SN-12345"))
+	assert.Equal(t, hex.EncodeToString(hash.Sum(nil)), result.(dtos.Event).Readings[0].Value)
+
+	target, err = NewRedactWithSecrets(RedactModeHash, []string{"serialNumber"}, nil, secretPath, "missing")
+	require.NoError(t, err)
+	mockSP.On("GetSecret", secretPath, "missing").Return(map[string]string{}, nil)
+
+	continuePipeline, result = target.Redact(ctx, newRedactTestEvent())
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "unable to find hash key in secret data for name=missing")
+}
+
+func TestRedact_RedactBadData(t *testing.T) {
+	target, err := NewRedact(RedactModeDrop, []string{"serialNumber"}, nil, "")
+	require.NoError(t, err)
+
+	continuePipeline, result := target.Redact(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Redact: no Event Received")
+
+	continuePipeline, result = target.Redact(ctx, "not an event")
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Redact: type received is not an Event")
+}
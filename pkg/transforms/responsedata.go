@@ -17,13 +17,38 @@
 package transforms
 
 import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	// ResponseSerializationJSON marshals the response data to JSON, which is the default
+	ResponseSerializationJSON = "json"
+	// ResponseSerializationXML marshals the response data to XML
+	ResponseSerializationXML = "xml"
+	// ResponseSerializationCBOR marshals the response data to CBOR
+	ResponseSerializationCBOR = "cbor"
 )
 
 // ResponseData houses transform for outputting data to configured trigger response, i.e. message bus
 type ResponseData struct {
 	ResponseContentType string
+	// Serialization is the format data other than []byte or string is marshaled to, one of the ResponseSerialization
+	// values. Defaults to JSON when empty.
+	Serialization string
+	// Append adds the data as a part of the response rather than replacing it, so multiple functions, including
+	// concurrently executing branches, can contribute to the response
+	Append bool
+	// ResponseTopic overrides the topic the trigger publishes the response data to and may contain placeholders for
+	// the context values, i.e. 'results/{devicename}'. The pipeline's or trigger's configured topic is used when empty.
+	ResponseTopic string
 }
 
 // NewResponseData creates, initializes and returns a new instance of ResponseData
@@ -31,8 +56,25 @@ func NewResponseData() ResponseData {
 	return ResponseData{}
 }
 
+// NewResponseDataWithSerialization creates, initializes and returns a new instance of ResponseData which marshals
+// the data to the specified serialization format, i.e. json, xml or cbor
+func NewResponseDataWithSerialization(serialization string) (ResponseData, error) {
+	switch strings.ToLower(serialization) {
+	case ResponseSerializationJSON, ResponseSerializationXML, ResponseSerializationCBOR:
+	default:
+		return ResponseData{}, fmt.Errorf("invalid response serialization '%s', must be '%s', '%s' or '%s'",
+			serialization, ResponseSerializationJSON, ResponseSerializationXML, ResponseSerializationCBOR)
+	}
+
+	return ResponseData{Serialization: strings.ToLower(serialization)}, nil
+}
+
 // SetResponseData sets the response data to that passed in from the previous function.
-// It will return an error and stop the pipeline if the input data is not of type []byte, string or json.Marshaller
+// Data of type []byte or string is assumed to already be serialized and is used as is, otherwise the data is marshaled
+// to the configured serialization format, JSON by default, and the response content type set to match unless
+// ResponseContentType is set. When Append is set the data is added as a part of the response with that content type.
+// When ResponseTopic is set the response is published to that topic rather than the configured one.
+// It will return an error and stop the pipeline if the input data can not be marshaled
 func (f ResponseData) SetResponseData(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
 
 	ctx.LoggingClient().Debug("Setting response data")
@@ -42,13 +84,26 @@ func (f ResponseData) SetResponseData(ctx interfaces.AppFunctionContext, data in
 		return false, nil
 	}
 
-	byteData, err := util.CoerceType(data)
+	byteData, contentType, err := f.serialize(data)
 	if err != nil {
 		return false, err
 	}
 
 	if len(f.ResponseContentType) > 0 {
-		ctx.SetResponseContentType(f.ResponseContentType)
+		contentType = f.ResponseContentType
+	}
+
+	if len(f.ResponseTopic) > 0 {
+		ctx.SetResponseTopic(f.ResponseTopic)
+	}
+
+	if f.Append {
+		ctx.AddResponseData(byteData, contentType)
+		return true, data
+	}
+
+	if len(contentType) > 0 {
+		ctx.SetResponseContentType(contentType)
 	}
 
 	// By setting this the data will be posted back to to configured trigger response, i.e. message bus
@@ -56,3 +111,55 @@ func (f ResponseData) SetResponseData(ctx interfaces.AppFunctionContext, data in
 
 	return true, data
 }
+
+// serialize returns the data marshaled to the configured serialization format and the matching content type,
+// which is empty when the format is not explicitly configured
+func (f ResponseData) serialize(data interface{}) ([]byte, string, error) {
+	switch strings.ToLower(f.Serialization) {
+	case "":
+		byteData, err := util.CoerceType(data)
+		return byteData, "", err
+
+	case ResponseSerializationJSON:
+		byteData, err := util.CoerceType(data)
+		return byteData, common.ContentTypeJSON, err
+
+	case ResponseSerializationXML:
+		switch value := data.(type) {
+		case []byte:
+			return value, common.ContentTypeXML, nil
+		case string:
+			return []byte(value), common.ContentTypeXML, nil
+		case interface{ ToXML() (string, error) }:
+			// i.e. Event DTO which has its own XML representation
+			xmlString, err := value.ToXML()
+			if err != nil {
+				return nil, "", fmt.Errorf("marshaling input data to XML failed: %s", err.Error())
+			}
+			return []byte(xmlString), common.ContentTypeXML, nil
+		}
+
+		byteData, err := xml.Marshal(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("marshaling input data to XML failed: %s", err.Error())
+		}
+		return byteData, common.ContentTypeXML, nil
+
+	case ResponseSerializationCBOR:
+		switch value := data.(type) {
+		case []byte:
+			return value, common.ContentTypeCBOR, nil
+		case string:
+			return []byte(value), common.ContentTypeCBOR, nil
+		}
+
+		byteData, err := cbor.Marshal(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("marshaling input data to CBOR failed: %s", err.Error())
+		}
+		return byteData, common.ContentTypeCBOR, nil
+
+	default:
+		return nil, "", fmt.Errorf("invalid response serialization '%s'", f.Serialization)
+	}
+}
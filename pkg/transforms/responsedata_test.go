@@ -18,10 +18,12 @@ package transforms
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"testing"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/fxamacker/cbor/v2"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,6 +71,37 @@ func TestSetResponseDataEvent(t *testing.T) {
 	assert.Equal(t, string(expected), actual)
 }
 
+func TestSetResponseDataAppend(t *testing.T) {
+	ctx.SetResponseData(nil)
+	defer ctx.SetResponseData(nil)
+
+	first := ResponseData{Append: true}
+	second := ResponseData{Append: true, ResponseContentType: common.ContentTypeJSON}
+
+	continuePipeline, _ := first.SetResponseData(ctx, dtos.Event{DeviceName: deviceName1})
+	require.True(t, continuePipeline)
+	continuePipeline, _ = second.SetResponseData(ctx, []byte(`{"value":1}`))
+	require.True(t, continuePipeline)
+
+	var actual []map[string]interface{}
+	require.NoError(t, json.Unmarshal(ctx.ResponseData(), &actual))
+	require.Len(t, actual, 2)
+	assert.Equal(t, deviceName1, actual[0]["deviceName"])
+	assert.Equal(t, float64(1), actual[1]["value"])
+	assert.Equal(t, common.ContentTypeJSON, ctx.ResponseContentType())
+}
+
+func TestSetResponseDataResponseTopic(t *testing.T) {
+	ctx.SetResponseTopic("")
+	defer ctx.SetResponseTopic("")
+
+	target := ResponseData{ResponseTopic: "results/{devicename}"}
+
+	continuePipeline, _ := target.SetResponseData(ctx, []byte(`{"value":1}`))
+	require.True(t, continuePipeline)
+	assert.Equal(t, "results/{devicename}", ctx.ResponseTopic())
+}
+
 func TestSetResponseDataNoData(t *testing.T) {
 	target := NewResponseData()
 	continuePipeline, result := target.SetResponseData(ctx, nil)
@@ -86,6 +119,122 @@ func TestSetResponseDataBadType(t *testing.T) {
 	assert.Contains(t, result.(error).Error(), "passed in data must be of type")
 }
 
+func TestNewResponseDataWithSerialization(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Serialization string
+		Expected      string
+		ExpectError   bool
+	}{
+		{"JSON", "json", ResponseSerializationJSON, false},
+		{"XML", "XML", ResponseSerializationXML, false},
+		{"CBOR", "Cbor", ResponseSerializationCBOR, false},
+		{"Empty", "", "", true},
+		{"Invalid", "yaml", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := NewResponseDataWithSerialization(test.Serialization)
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, target.Serialization)
+		})
+	}
+}
+
+func TestSetResponseDataSerialization(t *testing.T) {
+	eventIn := dtos.NewEvent("profile1", "dev1", "source1")
+	eventIn.AddBinaryReading("resource1", []byte{0x01, 0x02, 0x03}, "application/octet-stream")
+
+	type custom struct {
+		Name  string `xml:"name"`
+		Value int    `xml:"value"`
+	}
+	customIn := custom{Name: "test", Value: 42}
+
+	expectedEventXml, err := eventIn.ToXML()
+	require.NoError(t, err)
+	expectedCustomXml, err := xml.Marshal(customIn)
+	require.NoError(t, err)
+	expectedJSON, err := json.Marshal(eventIn)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name                string
+		Serialization       string
+		ResponseContentType string
+		Data                interface{}
+		ExpectedData        []byte
+		ExpectedContentType string
+	}{
+		{"JSON", ResponseSerializationJSON, "", eventIn, expectedJSON, common.ContentTypeJSON},
+		{"XML Event", ResponseSerializationXML, "", eventIn, []byte(expectedEventXml), common.ContentTypeXML},
+		{"XML custom type", ResponseSerializationXML, "", customIn, expectedCustomXml, common.ContentTypeXML},
+		{"XML bytes", ResponseSerializationXML, "", []byte("<a/>"), []byte("<a/>"), common.ContentTypeXML},
+		{"CBOR bytes", ResponseSerializationCBOR, "", []byte{0xa0}, []byte{0xa0}, common.ContentTypeCBOR},
+		{"CBOR content type override", ResponseSerializationCBOR, "application/x-custom", "abc", []byte("abc"), "application/x-custom"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := ResponseData{Serialization: test.Serialization, ResponseContentType: test.ResponseContentType}
+			ctx.SetResponseContentType("")
+
+			continuePipeline, result := target.SetResponseData(ctx, test.Data)
+			require.True(t, continuePipeline)
+			assert.Equal(t, test.Data, result)
+			assert.Equal(t, test.ExpectedData, ctx.ResponseData())
+			assert.Equal(t, test.ExpectedContentType, ctx.ResponseContentType())
+		})
+	}
+}
+
+func TestSetResponseDataCBOREvent(t *testing.T) {
+	eventIn := dtos.NewEvent("profile1", "dev1", "source1")
+	eventIn.AddBinaryReading("resource1", []byte{0x01, 0x02, 0x03}, "application/octet-stream")
+	target := ResponseData{Serialization: ResponseSerializationCBOR}
+
+	continuePipeline, result := target.SetResponseData(ctx, eventIn)
+	require.True(t, continuePipeline)
+	require.NotNil(t, result)
+	assert.Equal(t, common.ContentTypeCBOR, ctx.ResponseContentType())
+
+	actual := dtos.Event{}
+	err := cbor.Unmarshal(ctx.ResponseData(), &actual)
+	require.NoError(t, err)
+	assert.Equal(t, eventIn, actual)
+}
+
+func TestSetResponseDataSerializationErrors(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Serialization string
+		Data          interface{}
+		ExpectedError string
+	}{
+		{"Invalid serialization", "yaml", dtos.Event{}, "invalid response serialization"},
+		{"XML unsupported type", ResponseSerializationXML, map[string]string{"a": "b"}, "marshaling input data to XML failed"},
+		{"CBOR unsupported type", ResponseSerializationCBOR, make(chan int), "marshaling input data to CBOR failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := ResponseData{Serialization: test.Serialization}
+
+			continuePipeline, result := target.SetResponseData(ctx, test.Data)
+			require.False(t, continuePipeline)
+			err, ok := result.(error)
+			require.True(t, ok)
+			assert.Contains(t, err.Error(), test.ExpectedError)
+		})
+	}
+}
+
 func getExpectedEventXml(t *testing.T) string {
 	event := dtos.NewEvent("profile1", "dev1", "source1")
 	event.AddSimpleReading("resource1", common.ValueTypeInt32, int32(32))
@@ -0,0 +1,554 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+)
+
+const (
+	// partialFileSuffix is appended to the names of files still being written so readers only see finalized files
+	partialFileSuffix = ".partial"
+	uploadTimeout     = 5 * time.Minute
+)
+
+var filePlaceholderSpec = regexp.MustCompile(`{[^{}]+}`)
+
+// timePartitions are the supported time placeholders, from the finest granularity to the coarsest
+var timePartitions = []struct {
+	placeholder string
+	layout      string
+}{
+	{"{mm}", "04"},
+	{"{HH}", "15"},
+	{"{dd}", "02"},
+	{"{MM}", "01"},
+	{"{yyyy}", "2006"},
+}
+
+// RollingFileConfig contains all the settings available to the RollingFileWriter
+type RollingFileConfig struct {
+	// Directory is the local directory the files are written to
+	Directory string
+	// FilePattern is the path of the files relative to Directory, i.e. 'site/{devicename}/{yyyy}/{MM}/{dd}/{HH}.ndjson.gz'.
+	// The time placeholders {yyyy}, {MM}, {dd}, {HH} and {mm} are replaced with the time the data is written in the
+	// service's Timezone, UTC by default, and at least one is required. Other placeholders in the form '{some-context-key}' are replaced with the values found
+	// in the context storage. A file is finalized once the finest time placeholder it uses rolls over.
+	// Files ending in '.gz' are gzip compressed.
+	FilePattern string
+	// UploadURL is optional. When set each finalized file is uploaded with an HTTP PUT to UploadURL joined with the
+	// file's path relative to Directory.
+	UploadURL string
+	// HTTPHeaderName, SecretPath and SecretName optionally specify a header set on uploads to the secret value found
+	// in the Secret Store, i.e. an Authorization header.
+	HTTPHeaderName string
+	SecretPath     string
+	SecretName     string
+	// RemoveAfterUpload removes the local file once it has been uploaded successfully
+	RemoveAfterUpload bool
+	// Finalized is optional and, when set, is called in place of the upload to UploadURL with the local path and
+	// the relative path of each finalized file.
+	Finalized func(localPath string, relativePath string) error
+	// Checksum is optional and, when set to crc32 or sha256, a sidecar file named after the finalized file with the
+	// algorithm added as an extension, i.e. '10.ndjson.gz.sha256', is written with the checksum of the file in the
+	// format of the sha256sum tool. The sidecar file is uploaded after the file and removed along with it.
+	Checksum string
+}
+
+// RollingFileWriter writes the pipeline data as lines to files partitioned by time, finalizing each file once its
+// time partition has passed so the resulting files form a dataset ready for analytics.
+type RollingFileWriter struct {
+	config     RollingFileConfig
+	unit       string
+	mutex      sync.Mutex
+	files      map[string]*rollingFile
+	timer      *time.Timer
+	uploads    sync.WaitGroup
+	lc         logger.LoggingClient
+	getSecret  func(path string, keys ...string) (map[string]string, error)
+	now        func() time.Time
+	location   *time.Location
+	httpClient *http.Client
+}
+
+type rollingFile struct {
+	relativePath string
+	period       time.Time
+	file         *os.File
+	gzip         *gzip.Writer
+}
+
+// NewRollingFileWriter creates, initializes and returns a new instance of RollingFileWriter
+func NewRollingFileWriter(config RollingFileConfig) (*RollingFileWriter, error) {
+	if len(config.Directory) == 0 {
+		return nil, errors.New("rolling file directory must be specified")
+	}
+
+	unit := ""
+	for _, partition := range timePartitions {
+		if strings.Contains(config.FilePattern, partition.placeholder) {
+			unit = partition.placeholder
+			break
+		}
+	}
+
+	if len(unit) == 0 {
+		return nil, fmt.Errorf("rolling file pattern '%s' must contain at least one time placeholder", config.FilePattern)
+	}
+
+	if len(config.Checksum) > 0 {
+		if _, err := newChecksumHash(config.Checksum); err != nil {
+			return nil, err
+		}
+		config.Checksum = strings.ToLower(config.Checksum)
+	}
+
+	return &RollingFileWriter{
+		config:     config,
+		unit:       unit,
+		files:      make(map[string]*rollingFile),
+		now:        time.Now,
+		location:   time.UTC,
+		httpClient: &http.Client{Timeout: uploadTimeout},
+	}, nil
+}
+
+// Write writes the data from the previous function as a line to the file for the current time partition,
+// finalizing the files of earlier time partitions. The data is passed on to the next function.
+// If no previous function exists, then the event that triggered the pipeline will be used.
+func (writer *RollingFileWriter) Write(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("no data received to write to file")
+	}
+
+	exportData, err := util.CoerceType(data)
+	if err != nil {
+		return false, err
+	}
+
+	now := writer.now().In(ctx.Location())
+	relativePath, err := writer.renderPath(ctx, now)
+	if err != nil {
+		return false, err
+	}
+
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	writer.lc = ctx.LoggingClient()
+	writer.getSecret = ctx.GetSecret
+	writer.location = now.Location()
+
+	period := writer.periodStart(now)
+	writer.finalizeBefore(period)
+
+	file, err := writer.open(relativePath, period)
+	if err != nil {
+		return false, err
+	}
+
+	if err := file.write(exportData); err != nil {
+		return false, fmt.Errorf("unable to write to file '%s': %s", relativePath, err.Error())
+	}
+
+	writer.scheduleRollover(now)
+
+	ctx.LoggingClient().Debugf("Wrote %d bytes to rolling file '%s'", len(exportData), relativePath)
+
+	return true, data
+}
+
+// Close finalizes all open files and waits for their uploads to complete. Used when the service is stopping.
+func (writer *RollingFileWriter) Close() {
+	writer.mutex.Lock()
+	if writer.timer != nil {
+		writer.timer.Stop()
+		writer.timer = nil
+	}
+	writer.finalizeBefore(time.Time{})
+	writer.mutex.Unlock()
+
+	writer.uploads.Wait()
+}
+
+// renderPath replaces the placeholders in the file pattern. Context values are sanitized so they can't
+// change the directory structure.
+func (writer *RollingFileWriter) renderPath(ctx interfaces.AppFunctionContext, now time.Time) (string, error) {
+	var missing []string
+	rendered := filePlaceholderSpec.ReplaceAllStringFunc(writer.config.FilePattern, func(placeholder string) string {
+		for _, partition := range timePartitions {
+			if placeholder == partition.placeholder {
+				return now.Format(partition.layout)
+			}
+		}
+
+		value, found := ctx.GetValue(strings.Trim(placeholder, "{}"))
+		if !found {
+			missing = append(missing, placeholder)
+			return placeholder
+		}
+
+		value = strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(value)
+		if len(value) == 0 {
+			value = "_"
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("failed to replace placeholders %v in rolling file pattern", missing)
+	}
+
+	// Cleaning as an absolute path removes any leading '..' so the file can't be outside the directory
+	rendered = filepath.ToSlash(filepath.Clean(string(filepath.Separator) + filepath.FromSlash(rendered)))
+	rendered = strings.TrimLeft(rendered, "/")
+	if len(rendered) == 0 {
+		return "", fmt.Errorf("rolling file pattern '%s' resulted in an empty file name", writer.config.FilePattern)
+	}
+
+	return rendered, nil
+}
+
+// periodStart returns the start of the time partition the specified time falls in
+func (writer *RollingFileWriter) periodStart(now time.Time) time.Time {
+	// Calculated from the wall clock in the time's location, as truncating would be relative to UTC and not align with
+	// the partitions of time zones offset by part of an hour
+	switch writer.unit {
+	case "{mm}":
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, now.Location())
+	case "{HH}":
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+	case "{dd}":
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	case "{MM}":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	}
+}
+
+// nextPeriodStart returns the start of the time partition following the one the specified time falls in
+func (writer *RollingFileWriter) nextPeriodStart(now time.Time) time.Time {
+	start := writer.periodStart(now)
+	switch writer.unit {
+	case "{mm}":
+		return start.Add(time.Minute)
+	case "{HH}":
+		return start.Add(time.Hour)
+	case "{dd}":
+		return start.AddDate(0, 0, 1)
+	case "{MM}":
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(1, 0, 0)
+	}
+}
+
+// scheduleRollover makes sure the open files are finalized when the current time partition ends,
+// even if no more data is received. Must be called with the mutex held.
+func (writer *RollingFileWriter) scheduleRollover(now time.Time) {
+	if writer.timer != nil || len(writer.files) == 0 {
+		return
+	}
+
+	writer.timer = time.AfterFunc(writer.nextPeriodStart(now).Sub(now), func() {
+		writer.mutex.Lock()
+		defer writer.mutex.Unlock()
+
+		writer.timer = nil
+		now := writer.now().In(writer.location)
+		writer.finalizeBefore(writer.periodStart(now))
+		writer.scheduleRollover(now)
+	})
+}
+
+// open returns the open file for the relative path, creating it if needed. Must be called with the mutex held.
+func (writer *RollingFileWriter) open(relativePath string, period time.Time) (*rollingFile, error) {
+	if file, found := writer.files[relativePath]; found {
+		return file, nil
+	}
+
+	localPath := filepath.Join(writer.config.Directory, filepath.FromSlash(relativePath))
+	if err := os.MkdirAll(filepath.Dir(localPath), 0750); err != nil {
+		return nil, fmt.Errorf("unable to create directory for file '%s': %s", relativePath, err.Error())
+	}
+
+	// Appending allows a partial file left by a restart to be continued. Concatenated gzip streams are still valid.
+	osFile, err := os.OpenFile(localPath+partialFileSuffix, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file '%s': %s", relativePath, err.Error())
+	}
+
+	file := &rollingFile{
+		relativePath: relativePath,
+		period:       period,
+		file:         osFile,
+	}
+
+	if strings.HasSuffix(relativePath, ".gz") {
+		file.gzip = gzip.NewWriter(osFile)
+	}
+
+	writer.files[relativePath] = file
+	return file, nil
+}
+
+// finalizeBefore finalizes all files for time partitions before the specified time and starts their uploads.
+// Must be called with the mutex held.
+func (writer *RollingFileWriter) finalizeBefore(period time.Time) {
+	for relativePath, file := range writer.files {
+		if !period.IsZero() && !file.period.Before(period) {
+			continue
+		}
+
+		delete(writer.files, relativePath)
+
+		localPath, err := file.finalize(writer.config.Directory)
+		if err != nil {
+			writer.lc.Errorf("Unable to finalize rolling file '%s': %s", relativePath, err.Error())
+			continue
+		}
+
+		writer.lc.Debugf("Finalized rolling file '%s'", localPath)
+
+		if len(writer.config.Checksum) > 0 {
+			// The file is still uploaded, without its checksum, so the data isn't held back
+			if err := writeChecksumFile(writer.config.Checksum, localPath); err != nil {
+				writer.lc.Errorf("Unable to write checksum of rolling file '%s': %s", localPath, err.Error())
+			}
+		}
+
+		writer.uploads.Add(1)
+		go func(lc logger.LoggingClient, getSecret func(path string, keys ...string) (map[string]string, error), localPath string) {
+			defer writer.uploads.Done()
+			// Uploads continue after the pipeline that finalized the file has executed, so aren't bound to the
+			// context of its message. They are limited by the upload timeout instead.
+			if err := writer.upload(context.Background(), lc, getSecret, localPath); err != nil {
+				lc.Errorf("Unable to upload rolling file '%s': %s", localPath, err.Error())
+			}
+		}(writer.lc, writer.getSecret, localPath)
+	}
+}
+
+// upload hands the finalized file to the Finalized function or uploads it, followed by its checksum sidecar file if
+// any, to UploadURL if either is configured
+func (writer *RollingFileWriter) upload(
+	ctx context.Context,
+	lc logger.LoggingClient,
+	getSecret func(path string, keys ...string) (map[string]string, error),
+	localPath string) error {
+	relativePath, err := filepath.Rel(writer.config.Directory, localPath)
+	if err != nil {
+		return err
+	}
+	relativePath = filepath.ToSlash(relativePath)
+
+	if writer.config.Finalized != nil {
+		return writer.config.Finalized(localPath, relativePath)
+	}
+
+	if len(writer.config.UploadURL) == 0 {
+		return nil
+	}
+
+	contentType := "application/x-ndjson"
+	if strings.HasSuffix(relativePath, ".gz") {
+		contentType = "application/gzip"
+	}
+
+	if err := writer.put(ctx, lc, getSecret, localPath, relativePath, contentType); err != nil {
+		return err
+	}
+
+	checksumPath := ""
+	if len(writer.config.Checksum) > 0 {
+		checksumPath = localPath + "." + writer.config.Checksum
+		if _, err := os.Stat(checksumPath); err != nil {
+			// Not written when computing the checksum failed, which has been logged
+			checksumPath = ""
+		} else if err := writer.put(ctx, lc, getSecret, checksumPath, relativePath+"."+writer.config.Checksum, "text/plain"); err != nil {
+			return err
+		}
+	}
+
+	if writer.config.RemoveAfterUpload {
+		if len(checksumPath) > 0 {
+			if err := os.Remove(checksumPath); err != nil {
+				return err
+			}
+		}
+		return os.Remove(localPath)
+	}
+
+	return nil
+}
+
+// put uploads the local file with an HTTP PUT to UploadURL joined with the file's relative path
+func (writer *RollingFileWriter) put(
+	ctx context.Context,
+	lc logger.LoggingClient,
+	getSecret func(path string, keys ...string) (map[string]string, error),
+	localPath string,
+	relativePath string,
+	contentType string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	// Must be closed before the file is removed on some platforms
+	defer func() { _ = file.Close() }()
+
+	segments := strings.Split(relativePath, "/")
+	for index, segment := range segments {
+		segments[index] = url.PathEscape(segment)
+	}
+	uploadURL := strings.TrimSuffix(writer.config.UploadURL, "/") + "/" + strings.Join(segments, "/")
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, file)
+	if err != nil {
+		return err
+	}
+
+	if info, err := file.Stat(); err == nil {
+		request.ContentLength = info.Size()
+	}
+
+	request.Header.Set("Content-Type", contentType)
+
+	if len(writer.config.HTTPHeaderName) > 0 && len(writer.config.SecretPath) > 0 && len(writer.config.SecretName) > 0 {
+		secrets, err := getSecret(writer.config.SecretPath, writer.config.SecretName)
+		if err != nil {
+			return err
+		}
+		request.Header.Set(writer.config.HTTPHeaderName, secrets[writer.config.SecretName])
+	}
+
+	response, err := writer.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("upload to %s failed with status %s", uploadURL, response.Status)
+	}
+
+	lc.Debugf("Uploaded rolling file '%s' to %s", relativePath, uploadURL)
+	return nil
+}
+
+// writeChecksumFile writes the checksum of the file, computed with the algorithm, to the sidecar file named after the
+// file with the algorithm added as an extension. The format is that of the sha256sum tool, '<hex>  <file name>'.
+func writeChecksumFile(algorithm string, localPath string) error {
+	checksum, err := newChecksumHash(algorithm)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := io.Copy(checksum, file); err != nil {
+		return err
+	}
+
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(checksum.Sum(nil)), filepath.Base(localPath))
+	return os.WriteFile(localPath+"."+algorithm, []byte(line), 0640)
+}
+
+func (file *rollingFile) write(data []byte) error {
+	var writer io.Writer = file.file
+	if file.gzip != nil {
+		writer = file.gzip
+	}
+
+	if len(data) == 0 || data[len(data)-1] != '\n' {
+		data = append(data[:len(data):len(data)], '\n')
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+
+	// Flush the compressed data so a partial file left by a crash contains everything written
+	if file.gzip != nil {
+		return file.gzip.Flush()
+	}
+
+	return nil
+}
+
+// finalize closes the file and renames it to its final name, returning the final local path
+func (file *rollingFile) finalize(directory string) (string, error) {
+	if file.gzip != nil {
+		if err := file.gzip.Close(); err != nil {
+			_ = file.file.Close()
+			return "", err
+		}
+	}
+
+	if err := file.file.Close(); err != nil {
+		return "", err
+	}
+
+	partialPath := filepath.Join(directory, filepath.FromSlash(file.relativePath)) + partialFileSuffix
+	localPath := uniqueFilePath(strings.TrimSuffix(partialPath, partialFileSuffix))
+	if err := os.Rename(partialPath, localPath); err != nil {
+		return "", err
+	}
+
+	return localPath, nil
+}
+
+// uniqueFilePath returns the path, or when a file already exists at it, i.e. one finalized when the service was
+// stopped part way through a time partition, the path with a counter added ahead of the extensions.
+func uniqueFilePath(localPath string) string {
+	directory, name := filepath.Split(localPath)
+	base, extensions := name, ""
+	if index := strings.Index(name, "."); index > 0 {
+		base, extensions = name[:index], name[index:]
+	}
+
+	for counter := 1; ; counter++ {
+		if _, err := os.Stat(localPath); os.IsNotExist(err) {
+			return localPath
+		}
+		localPath = filepath.Join(directory, fmt.Sprintf("%s-%d%s", base, counter, extensions))
+	}
+}
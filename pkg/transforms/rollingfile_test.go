@@ -0,0 +1,358 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	mocks2 "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRollingFileWriter(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Config        RollingFileConfig
+		ExpectedUnit  string
+		ErrorContains string
+	}{
+		{"Hourly", RollingFileConfig{Directory: "out", FilePattern: "{yyyy}/{MM}/{dd}/{HH}.ndjson"}, "{HH}", ""},
+		{"Monthly", RollingFileConfig{Directory: "out", FilePattern: "{devicename}/{yyyy}-{MM}.ndjson"}, "{MM}", ""},
+		{"No directory", RollingFileConfig{FilePattern: "{HH}.ndjson"}, "", "directory"},
+		{"No time placeholder", RollingFileConfig{Directory: "out", FilePattern: "{devicename}.ndjson"}, "", "time placeholder"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			writer, err := NewRollingFileWriter(test.Config)
+			if len(test.ErrorContains) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ErrorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedUnit, writer.unit)
+		})
+	}
+}
+
+func TestRollingFileWriter_RenderPath(t *testing.T) {
+	now := time.Date(2021, 7, 4, 9, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name          string
+		Pattern       string
+		DeviceName    string
+		Expected      string
+		ErrorContains string
+	}{
+		{"Time and device", "site/{devicename}/{yyyy}/{MM}/{dd}/{HH}{mm}.ndjson.gz", "Thermostat", "site/Thermostat/2021/07/04/0905.ndjson.gz", ""},
+		{"Separators sanitized", "{devicename}/{HH}.ndjson", "../../etc/passwd", "____etc_passwd/09.ndjson", ""},
+		{"Leading parent removed", "../{HH}.ndjson", "", "09.ndjson", ""},
+		{"Missing value", "{bogus}/{HH}.ndjson", "", "", "{bogus}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			writer, err := NewRollingFileWriter(RollingFileConfig{Directory: "out", FilePattern: test.Pattern})
+			require.NoError(t, err)
+
+			appContext := appfunction.NewContext("123", dic, "")
+			appContext.AddValue("devicename", test.DeviceName)
+
+			actual, err := writer.renderPath(appContext, now)
+			if len(test.ErrorContains) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ErrorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestRollingFileWriter_PeriodBoundaries(t *testing.T) {
+	now := time.Date(2021, 12, 31, 23, 59, 30, 0, time.UTC)
+
+	tests := []struct {
+		Pattern       string
+		ExpectedStart time.Time
+		ExpectedNext  time.Time
+	}{
+		{"{mm}", time.Date(2021, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"{HH}", time.Date(2021, 12, 31, 23, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"{dd}", time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"{MM}", time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"{yyyy}", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Pattern, func(t *testing.T) {
+			writer, err := NewRollingFileWriter(RollingFileConfig{Directory: "out", FilePattern: test.Pattern})
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedStart, writer.periodStart(now))
+			assert.Equal(t, test.ExpectedNext, writer.nextPeriodStart(now))
+		})
+	}
+}
+
+func TestRollingFileWriter_Timezone(t *testing.T) {
+	directory := t.TempDir()
+
+	// Offset by part of an hour, so the hourly partitions don't align with those of UTC
+	location := time.FixedZone("IST", 5*60*60+30*60)
+	timezoneDic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return lc
+		},
+		container.LocationName: func(get di.Get) interface{} {
+			return location
+		},
+	})
+
+	writer, err := NewRollingFileWriter(RollingFileConfig{Directory: directory, FilePattern: "{yyyy}/{MM}/{dd}/{HH}.ndjson"})
+	require.NoError(t, err)
+
+	now := time.Date(2021, 7, 4, 23, 45, 0, 0, time.UTC)
+	writer.now = func() time.Time { return now }
+
+	continuePipeline, _ := writer.Write(appfunction.NewContext("123", timezoneDic, ""), []byte(`{"value":1}`))
+	require.True(t, continuePipeline)
+
+	// 23:45 UTC is 05:15 the next day in the time zone
+	assert.FileExists(t, filepath.Join(directory, "2021", "07", "05", "05.ndjson"+partialFileSuffix))
+
+	localNow := now.In(location)
+	assert.Equal(t, time.Date(2021, 7, 5, 5, 0, 0, 0, location), writer.periodStart(localNow))
+	assert.Equal(t, time.Date(2021, 7, 4, 23, 30, 0, 0, time.UTC), writer.periodStart(localNow).UTC())
+	assert.Equal(t, time.Date(2021, 7, 5, 6, 0, 0, 0, location), writer.nextPeriodStart(localNow))
+
+	writer.Close()
+}
+
+func readGzipFile(t *testing.T, localPath string) string {
+	file, err := os.Open(localPath)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	// Partial files have been flushed but have no gzip footer until finalized
+	if err != io.ErrUnexpectedEOF || !strings.HasSuffix(localPath, partialFileSuffix) {
+		require.NoError(t, err)
+	}
+	return string(data)
+}
+
+func TestRollingFileWriter_WriteAndRollover(t *testing.T) {
+	directory := t.TempDir()
+
+	var finalizedMutex sync.Mutex
+	var finalized []string
+
+	writer, err := NewRollingFileWriter(RollingFileConfig{
+		Directory:   directory,
+		FilePattern: "site/{devicename}/{yyyy}/{MM}/{dd}/{HH}.ndjson.gz",
+		Finalized: func(localPath string, relativePath string) error {
+			finalizedMutex.Lock()
+			defer finalizedMutex.Unlock()
+			finalized = append(finalized, relativePath)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2021, 7, 4, 10, 15, 0, 0, time.UTC)
+	writer.now = func() time.Time { return now }
+
+	write := func(deviceName string, data interface{}) {
+		appContext := appfunction.NewContext("123", dic, "")
+		appContext.AddValue("devicename", deviceName)
+		continuePipeline, result := writer.Write(appContext, data)
+		require.True(t, continuePipeline)
+		assert.Equal(t, data, result)
+	}
+
+	write("Thermostat", []byte(`{"value":1}`))
+	write("Thermostat", map[string]int{"value": 2})
+	write("Pump", "{\"value\":3}\n")
+
+	thermostatPath := filepath.Join(directory, "site", "Thermostat", "2021", "07", "04", "10.ndjson.gz")
+	assert.FileExists(t, thermostatPath+partialFileSuffix)
+	assert.NoFileExists(t, thermostatPath)
+	assert.Equal(t, "{\"value\":1}\n{\"value\":2}\n", readGzipFile(t, thermostatPath+partialFileSuffix), "expected partial file to be readable")
+
+	// Rolling over to the next hour finalizes the files for the previous hour
+	now = now.Add(time.Hour)
+	write("Thermostat", []byte(`{"value":4}`))
+	writer.uploads.Wait()
+
+	assert.FileExists(t, thermostatPath)
+	assert.NoFileExists(t, thermostatPath+partialFileSuffix)
+	assert.Equal(t, "{\"value\":1}\n{\"value\":2}\n", readGzipFile(t, thermostatPath))
+	assert.Equal(t, "{\"value\":3}\n", readGzipFile(t, filepath.Join(directory, "site", "Pump", "2021", "07", "04", "10.ndjson.gz")))
+
+	writer.Close()
+
+	finalizedMutex.Lock()
+	defer finalizedMutex.Unlock()
+	sort.Strings(finalized)
+	assert.Equal(t, []string{
+		"site/Pump/2021/07/04/10.ndjson.gz",
+		"site/Thermostat/2021/07/04/10.ndjson.gz",
+		"site/Thermostat/2021/07/04/11.ndjson.gz",
+	}, finalized)
+}
+
+func TestRollingFileWriter_FinalizeExisting(t *testing.T) {
+	directory := t.TempDir()
+	localPath := filepath.Join(directory, "10.ndjson")
+	require.NoError(t, os.WriteFile(localPath, []byte("earlier\n"), 0640))
+
+	writer, err := NewRollingFileWriter(RollingFileConfig{Directory: directory, FilePattern: "{HH}.ndjson"})
+	require.NoError(t, err)
+	writer.now = func() time.Time { return time.Date(2021, 7, 4, 10, 15, 0, 0, time.UTC) }
+
+	continuePipeline, _ := writer.Write(ctx, []byte("later"))
+	require.True(t, continuePipeline)
+	writer.Close()
+
+	earlier, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, "earlier\n", string(earlier))
+
+	later, err := os.ReadFile(filepath.Join(directory, "10-1.ndjson"))
+	require.NoError(t, err)
+	assert.Equal(t, "later\n", string(later))
+}
+
+func TestRollingFileWriter_Upload(t *testing.T) {
+	type upload struct {
+		Path          string
+		Body          string
+		ContentType   string
+		Authorization string
+	}
+	uploads := make(chan upload, 1)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, http.MethodPut, r.Method)
+		uploads <- upload{r.URL.Path, string(body), r.Header.Get("Content-Type"), r.Header.Get("Authorization")}
+		w.WriteHeader(http.StatusCreated)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	mockSP := &mocks2.SecretProvider{}
+	mockSP.On("GetSecret", "uploads", "token").Return(map[string]string{"token": "Bearer abc"}, nil)
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	directory := t.TempDir()
+	writer, err := NewRollingFileWriter(RollingFileConfig{
+		Directory:         directory,
+		FilePattern:       "{devicename}/{HH}.ndjson",
+		UploadURL:         server.URL + "/bucket/",
+		HTTPHeaderName:    "Authorization",
+		SecretPath:        "uploads",
+		SecretName:        "token",
+		RemoveAfterUpload: true,
+	})
+	require.NoError(t, err)
+	writer.now = func() time.Time { return time.Date(2021, 7, 4, 10, 15, 0, 0, time.UTC) }
+
+	appContext := appfunction.NewContext("123", dic, "")
+	appContext.AddValue("devicename", "Random Device")
+	continuePipeline, _ := writer.Write(appContext, []byte(`{"value":1}`))
+	require.True(t, continuePipeline)
+	writer.Close()
+
+	select {
+	case actual := <-uploads:
+		assert.Equal(t, "/bucket/Random Device/10.ndjson", actual.Path)
+		assert.Equal(t, "{\"value\":1}\n", actual.Body)
+		assert.Equal(t, "application/x-ndjson", actual.ContentType)
+		assert.Equal(t, "Bearer abc", actual.Authorization)
+	default:
+		require.Fail(t, "file not uploaded")
+	}
+
+	assert.NoFileExists(t, filepath.Join(directory, "Random Device", "10.ndjson"))
+}
+
+func TestRollingFileWriter_Checksum(t *testing.T) {
+	uploads := make(chan string, 2)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		uploads <- r.URL.Path + " " + r.Header.Get("Content-Type") + " " + string(body)
+		w.WriteHeader(http.StatusCreated)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	_, err := NewRollingFileWriter(RollingFileConfig{Directory: t.TempDir(), FilePattern: "{HH}.ndjson", Checksum: "md5"})
+	require.Error(t, err)
+
+	directory := t.TempDir()
+	writer, err := NewRollingFileWriter(RollingFileConfig{
+		Directory:   directory,
+		FilePattern: "{HH}.ndjson",
+		UploadURL:   server.URL,
+		Checksum:    "CRC32",
+	})
+	require.NoError(t, err)
+	writer.now = func() time.Time { return time.Date(2021, 7, 4, 10, 15, 0, 0, time.UTC) }
+
+	continuePipeline, _ := writer.Write(ctx, []byte("12345678"))
+	require.True(t, continuePipeline)
+	writer.Close()
+
+	// The file contains '12345678\n', whose CRC-32 is 74245830
+	expected := "74245830  10.ndjson\n"
+	content, err := os.ReadFile(filepath.Join(directory, "10.ndjson.crc32"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+
+	require.Len(t, uploads, 2)
+	assert.Equal(t, "/10.ndjson application/x-ndjson 12345678\n", <-uploads)
+	assert.Equal(t, "/10.ndjson.crc32 text/plain "+expected, <-uploads)
+}
@@ -0,0 +1,147 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+)
+
+const (
+	// SchemaViolationsTag is the Event tag set to the schema violations found when invalid Events are tagged rather than stopped
+	SchemaViolationsTag = "SchemaViolations"
+	// SchemaViolations is the context value key set to the schema violations found when invalid data is passed on rather than stopped
+	SchemaViolations = "schemaviolations"
+)
+
+// SchemaValidator houses the JSON Schema or XML Schema (XSD) that data is validated against
+type SchemaValidator struct {
+	schemaType      string
+	validate        func(data []byte) []string
+	serialize       func(data interface{}) ([]byte, error)
+	stopOnViolation bool
+}
+
+// NewJSONSchemaValidator parses the specified JSON Schema and returns a new instance of SchemaValidator which validates
+// JSON data against it. The same subset of JSON Schema used for data contracts is supported.
+// When stopOnViolation is true, data that doesn't conform to the schema stops the pipeline with an error, otherwise
+// Events are tagged with the violations and other data is passed on with the violations stored in the context.
+// An error is returned if the schema fails to parse or uses keywords outside the subset.
+func NewJSONSchemaValidator(schema string, stopOnViolation bool) (*SchemaValidator, error) {
+	parsed, err := parseJSONSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SchemaValidator{
+		schemaType:      "JSON",
+		validate:        parsed.validateBytes,
+		serialize:       util.CoerceType,
+		stopOnViolation: stopOnViolation,
+	}, nil
+}
+
+// NewXMLSchemaValidator parses the specified XML Schema (XSD) and returns a new instance of SchemaValidator which
+// validates XML data against it. Only a subset of XSD 1.0 is supported: element declarations and references, named
+// and anonymous complex and simple types, a single sequence, all or choice of elements, attributes, simpleContent
+// extensions and simple type restrictions with the enumeration, pattern, length and range facets. Namespaces are
+// ignored. Any other construct, e.g. import, include, group, any, complexContent, list, union, mixed or nillable, is
+// rejected rather than ignored.
+// When stopOnViolation is true, data that doesn't conform to the schema stops the pipeline with an error, otherwise
+// Events are tagged with the violations and other data is passed on with the violations stored in the context.
+// An error is returned if the schema fails to parse or uses unsupported constructs.
+func NewXMLSchemaValidator(schema string, stopOnViolation bool) (*SchemaValidator, error) {
+	parsed, err := parseXMLSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SchemaValidator{
+		schemaType:      "XML",
+		validate:        parsed.validateBytes,
+		serialize:       coerceXML,
+		stopOnViolation: stopOnViolation,
+	}, nil
+}
+
+// Validate validates the data received, i.e. a raw payload from the HTTP or MQTT trigger, against the schema
+// so that malformed payloads are rejected early. Data that conforms to the schema is passed through unchanged.
+// It will return an error and stop the pipeline if no data is received or, when configured to do so,
+// if the data does not conform to the schema.
+func (v *SchemaValidator) Validate(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Validate: no data received")
+	}
+
+	serialized, err := v.serialize(data)
+	if err != nil {
+		return false, err
+	}
+
+	ctx.LoggingClient().Debugf("Validating data against %s schema", v.schemaType)
+
+	violations := v.validate(serialized)
+	if len(violations) == 0 {
+		return true, data
+	}
+
+	message := strings.Join(violations, "; ")
+	if v.stopOnViolation {
+		return false, fmt.Errorf("data does not conform to %s schema: %s", v.schemaType, message)
+	}
+
+	ctx.LoggingClient().Warnf("data does not conform to %s schema: %s", v.schemaType, message)
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		ctx.AddValue(SchemaViolations, message)
+		return true, data
+	}
+
+	tags := make(map[string]string, len(event.Tags)+1)
+	for tag, value := range event.Tags {
+		tags[tag] = value
+	}
+	tags[SchemaViolationsTag] = message
+	event.Tags = tags
+
+	return true, event
+}
+
+// coerceXML returns the data as bytes, marshaling it to XML if it isn't already a string or []byte
+func coerceXML(data interface{}) ([]byte, error) {
+	switch typed := data.(type) {
+	case []byte:
+		return typed, nil
+	case string:
+		return []byte(typed), nil
+	}
+
+	serialized, err := xml.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal data to XML: %s", err.Error())
+	}
+
+	return serialized, nil
+}
@@ -0,0 +1,266 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testValidationJSONSchema = `{
+	"type": "object",
+	"required": ["id", "temperature"],
+	"properties": {
+		"id": {"type": "string"},
+		"temperature": {"type": "number", "maximum": 100}
+	}
+}`
+
+const testValidationXMLSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+	<xs:annotation><xs:documentation>Sensor report</xs:documentation></xs:annotation>
+	<xs:simpleType name="status">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="ok"/>
+			<xs:enumeration value="fault"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="percent">
+		<xs:restriction base="xs:decimal">
+			<xs:minInclusive value="0"/>
+			<xs:maxInclusive value="100"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:complexType name="measurement">
+		<xs:simpleContent>
+			<xs:extension base="xs:double">
+				<xs:attribute name="unit" type="xs:string" use="required"/>
+			</xs:extension>
+		</xs:simpleContent>
+	</xs:complexType>
+	<xs:element name="report">
+		<xs:complexType>
+			<xs:sequence>
+				<xs:element name="device">
+					<xs:simpleType>
+						<xs:restriction base="xs:string">
+							<xs:pattern value="[A-Z][a-z]+-[0-9]{2}"/>
+						</xs:restriction>
+					</xs:simpleType>
+				</xs:element>
+				<xs:element name="status" type="status"/>
+				<xs:element name="humidity" type="percent" minOccurs="0"/>
+				<xs:element name="reading" type="measurement" maxOccurs="unbounded"/>
+				<xs:element name="location" minOccurs="0">
+					<xs:complexType>
+						<xs:all>
+							<xs:element name="building" type="xs:string"/>
+							<xs:element name="floor" type="xs:int" minOccurs="0"/>
+						</xs:all>
+					</xs:complexType>
+				</xs:element>
+				<xs:element ref="note" minOccurs="0"/>
+			</xs:sequence>
+			<xs:attribute name="id" type="xs:unsignedInt" use="required"/>
+			<xs:attribute name="created" type="xs:dateTime"/>
+		</xs:complexType>
+	</xs:element>
+	<xs:element name="note">
+		<xs:complexType>
+			<xs:choice>
+				<xs:element name="text" type="xs:string"/>
+				<xs:element name="code" type="xs:int"/>
+			</xs:choice>
+		</xs:complexType>
+	</xs:element>
+</xs:schema>`
+
+func TestNewXMLSchemaValidator(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Schema      string
+		ExpectError bool
+	}{
+		{"Valid", testValidationXMLSchema, false},
+		{"Not XML", `{"type": "object"}`, true},
+		{"No global element", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`, true},
+		{"Unknown type", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a" type="xs:duration"/></xs:schema>`, true},
+		{"Unknown reference", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:sequence><xs:element ref="b"/></xs:sequence></xs:complexType></xs:element></xs:schema>`, true},
+		{"Unsupported construct", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:import namespace="urn:x"/><xs:element name="a"/></xs:schema>`, true},
+		{"Unsupported nested group", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:sequence><xs:choice/></xs:sequence></xs:complexType></xs:element></xs:schema>`, true},
+		{"Unsupported schema attribute", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" blockDefault="#all"><xs:element name="a"/></xs:schema>`, true},
+		{"Unsupported element attribute", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a" type="xs:string" nillable="true"/></xs:schema>`, true},
+		{"Unsupported mixed content", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType mixed="true"><xs:sequence/></xs:complexType></xs:element></xs:schema>`, true},
+		{"Unsupported group occurs", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:sequence maxOccurs="2"/></xs:complexType></xs:element></xs:schema>`, true},
+		{"Unsupported complex content", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:complexType name="b"/><xs:element name="a"><xs:complexType><xs:complexContent><xs:extension base="b"/></xs:complexContent></xs:complexType></xs:element></xs:schema>`, true},
+		{"Unsupported list", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:simpleType><xs:list itemType="xs:int"/></xs:simpleType></xs:element></xs:schema>`, true},
+		{"Unsupported facet", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:simpleType><xs:restriction base="xs:decimal"><xs:totalDigits value="3"/></xs:restriction></xs:simpleType></xs:element></xs:schema>`, true},
+		{"Unsupported fixed attribute", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:attribute name="b" fixed="1"/></xs:complexType></xs:element></xs:schema>`, true},
+		{"Unsupported attribute reference", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:attribute ref="xml:lang"/></xs:complexType></xs:element></xs:schema>`, true},
+		{"Unsupported simple content restriction", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:simpleContent><xs:restriction base="xs:string"/></xs:simpleContent></xs:complexType></xs:element></xs:schema>`, true},
+		{"Multiple content models", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:sequence/><xs:choice/></xs:complexType></xs:element></xs:schema>`, true},
+		{"Type and inline type", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a" type="xs:string"><xs:complexType/></xs:element></xs:schema>`, true},
+		{"Repeated all element", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:all><xs:element name="b" maxOccurs="2"/></xs:all></xs:complexType></xs:element></xs:schema>`, true},
+		{"Ignored namespace attributes", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t" elementFormDefault="qualified"><xs:element name="a" id="a1" t:note="x"/></xs:schema>`, false},
+		{"Bad occurs", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:complexType><xs:sequence><xs:element name="b" minOccurs="2" maxOccurs="1"/></xs:sequence></xs:complexType></xs:element></xs:schema>`, true},
+		{"Bad pattern", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"><xs:simpleType><xs:restriction base="xs:string"><xs:pattern value="("/></xs:restriction></xs:simpleType></xs:element></xs:schema>`, true},
+		{"Derived from itself", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:simpleType name="a"><xs:restriction base="b"/></xs:simpleType><xs:simpleType name="b"><xs:restriction base="a"/></xs:simpleType><xs:element name="c" type="a"/></xs:schema>`, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := NewXMLSchemaValidator(testCase.Schema, true)
+			if testCase.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSchemaValidatorValidateXML(t *testing.T) {
+	validator, err := NewXMLSchemaValidator(testValidationXMLSchema, true)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name              string
+		Data              string
+		ExpectedViolation string
+	}{
+		{"Valid", `<report id="7" created="2021-06-01T10:00:00Z"><device>Thermostat-01</device><status>ok</status>` +
+			`<humidity> 45.5 </humidity><reading unit="C">21.5</reading><reading unit="C">22</reading>` +
+			`<location><floor>3</floor><building>B3</building></location><note><code>12</code></note></report>`, ""},
+		{"Valid without optional elements", `<report xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" id="7"><device>Thermostat-01</device>` +
+			`<status>fault</status><reading unit="F">70</reading></report>`, ""},
+		{"Not XML", `not xml`, "data is not valid XML"},
+		{"Unknown root", `<other/>`, "/other: no global element declaration"},
+		{"Missing required attribute", `<report><device>Thermostat-01</device><status>ok</status><reading unit="C">1</reading></report>`,
+			"missing required attribute 'id'"},
+		{"Undeclared attribute", `<report id="7" extra="x"><device>Thermostat-01</device><status>ok</status><reading unit="C">1</reading></report>`,
+			"attribute 'extra' is not allowed"},
+		{"Bad attribute value", `<report id="-7"><device>Thermostat-01</device><status>ok</status><reading unit="C">1</reading></report>`,
+			"/report/@id: value '-7' is not a valid unsignedInt"},
+		{"Pattern mismatch", `<report id="7"><device>thermostat</device><status>ok</status><reading unit="C">1</reading></report>`,
+			"/report/device[1]: value 'thermostat' does not match the required pattern"},
+		{"Enumeration mismatch", `<report id="7"><device>Thermostat-01</device><status>bad</status><reading unit="C">1</reading></report>`,
+			"value 'bad' is not one of the allowed values"},
+		{"Range violation", `<report id="7"><device>Thermostat-01</device><status>ok</status><humidity>101</humidity><reading unit="C">1</reading></report>`,
+			"value 101 is greater than maxInclusive 100"},
+		{"Missing required element", `<report id="7"><device>Thermostat-01</device><status>ok</status></report>`,
+			"expected at least 1 'reading' element(s) but found 0"},
+		{"Out of order element", `<report id="7"><status>ok</status><device>Thermostat-01</device><reading unit="C">1</reading></report>`,
+			"expected at least 1 'device' element(s) but found 0"},
+		{"Unexpected element", `<report id="7"><device>Thermostat-01</device><status>ok</status><reading unit="C">1</reading><extra/></report>`,
+			"/report: unexpected element 'extra'"},
+		{"Bad simple content", `<report id="7"><device>Thermostat-01</device><status>ok</status><reading unit="C">warm</reading></report>`,
+			"/report/reading[1]: value 'warm' is not a valid double"},
+		{"Duplicate all element", `<report id="7"><device>Thermostat-01</device><status>ok</status><reading unit="C">1</reading>` +
+			`<location><building>B3</building><building>B4</building></location></report>`, "element 'building' may only occur once"},
+		{"Missing all element", `<report id="7"><device>Thermostat-01</device><status>ok</status><reading unit="C">1</reading>` +
+			`<location><floor>1</floor></location></report>`, "missing required element 'building'"},
+		{"Multiple choices", `<report id="7"><device>Thermostat-01</device><status>ok</status><reading unit="C">1</reading>` +
+			`<note><code>1</code><text>x</text></note></report>`, "element 'text' is not allowed with 'code'"},
+		{"Element in simple content", `<report id="7"><device><name/></device><status>ok</status><reading unit="C">1</reading></report>`,
+			"element 'name' is not allowed in simple content"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.Name, func(t *testing.T) {
+			continuePipeline, result := validator.Validate(ctx, []byte(testCase.Data))
+			if len(testCase.ExpectedViolation) == 0 {
+				require.True(t, continuePipeline, "unexpected violation: %v", result)
+				assert.Equal(t, []byte(testCase.Data), result)
+				return
+			}
+
+			require.False(t, continuePipeline)
+			err, ok := result.(error)
+			require.True(t, ok)
+			assert.Contains(t, err.Error(), testCase.ExpectedViolation)
+		})
+	}
+}
+
+func TestSchemaValidatorValidateJSON(t *testing.T) {
+	validator, err := NewJSONSchemaValidator(testValidationJSONSchema, true)
+	require.NoError(t, err)
+
+	continuePipeline, result := validator.Validate(ctx, `{"id": "sensor-1", "temperature": 21.5}`)
+	require.True(t, continuePipeline)
+	assert.Equal(t, `{"id": "sensor-1", "temperature": 21.5}`, result)
+
+	continuePipeline, result = validator.Validate(ctx, []byte(`{"id": "sensor-1", "temperature": 212}`))
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "$.temperature: value 212 is greater than maximum 100")
+
+	continuePipeline, result = validator.Validate(ctx, nil)
+	require.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+
+	_, err = NewJSONSchemaValidator(`{"type": 1}`, true)
+	require.Error(t, err)
+
+	_, err = NewJSONSchemaValidator(`{"type": "string", "format": "date-time"}`, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'format'")
+
+	_, err = NewJSONSchemaValidator(`{"type": "object", "properties": {"id": {"oneOf": [{"type": "string"}], "$ref": "#/definitions/id"}}}`, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'$ref', 'oneOf'")
+
+	_, err = NewJSONSchemaValidator(`{"$schema": "http://json-schema.org/draft-07/schema#", "title": "Reading", "type": "string", "pattern": "^[a-z]+$"}`, true)
+	require.NoError(t, err, "annotations and supported keywords are allowed")
+}
+
+func TestSchemaValidatorValidateTagOnViolation(t *testing.T) {
+	validator, err := NewJSONSchemaValidator(`{"type": "object", "required": ["missing"]}`, false)
+	require.NoError(t, err)
+
+	event := dtos.NewEvent("profile", "device", "source")
+	event.Tags = map[string]string{"Tag1": "Value1"}
+
+	continuePipeline, result := validator.Validate(ctx, event)
+	require.True(t, continuePipeline)
+	actual, ok := result.(dtos.Event)
+	require.True(t, ok)
+	assert.Equal(t, "Value1", actual.Tags["Tag1"])
+	assert.Contains(t, actual.Tags[SchemaViolationsTag], "missing required property 'missing'")
+	assert.NotContains(t, event.Tags, SchemaViolationsTag, "Event received must not be modified")
+
+	payload := []byte(`{"other": 1}`)
+	continuePipeline, result = validator.Validate(ctx, payload)
+	require.True(t, continuePipeline)
+	assert.Equal(t, payload, result)
+	violations, found := ctx.GetValue(SchemaViolations)
+	require.True(t, found)
+	assert.Contains(t, violations, "missing required property 'missing'")
+}
+
+func TestSchemaValidatorValidateXMLEvent(t *testing.T) {
+	validator, err := NewXMLSchemaValidator(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="Event"/></xs:schema>`, true)
+	require.NoError(t, err)
+
+	event := dtos.NewEvent("profile", "device", "source")
+	continuePipeline, result := validator.Validate(ctx, event)
+	require.True(t, continuePipeline, "unexpected violation: %v", result)
+	assert.Equal(t, event, result)
+}
@@ -0,0 +1,96 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// sendLimiter bounds the number of sends in flight to a destination. Sends which have to wait for a slot get it in
+// the order they started waiting, so with a single slot the data is sent in the order the pipelines exported it.
+type sendLimiter struct {
+	mutex    sync.Mutex
+	slots    int
+	inFlight int
+	waiting  []chan struct{}
+}
+
+// newSendLimiter returns a sendLimiter allowing maxConcurrency sends in flight, or nil when maxConcurrency isn't
+// positive, which doesn't limit the sends
+func newSendLimiter(maxConcurrency int) *sendLimiter {
+	if maxConcurrency <= 0 {
+		return nil
+	}
+
+	return &sendLimiter{slots: maxConcurrency}
+}
+
+// acquire waits for a free slot and returns the function which releases it once the send is done. An error is
+// returned, without a slot being held, if the context is done before a slot is free.
+func (limiter *sendLimiter) acquire(ctx context.Context) (func(), error) {
+	if limiter == nil {
+		return func() {}, nil
+	}
+
+	limiter.mutex.Lock()
+	if limiter.inFlight < limiter.slots && len(limiter.waiting) == 0 {
+		limiter.inFlight++
+		limiter.mutex.Unlock()
+		return limiter.release, nil
+	}
+
+	ready := make(chan struct{})
+	limiter.waiting = append(limiter.waiting, ready)
+	limiter.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return limiter.release, nil
+
+	case <-ctx.Done():
+		limiter.mutex.Lock()
+		for index, waiting := range limiter.waiting {
+			if waiting == ready {
+				limiter.waiting = append(limiter.waiting[:index], limiter.waiting[index+1:]...)
+				limiter.mutex.Unlock()
+				return nil, fmt.Errorf("gave up waiting to send: %w", ctx.Err())
+			}
+		}
+		limiter.mutex.Unlock()
+
+		// The slot was handed over as the context was done, so is passed on to the next send
+		limiter.release()
+		return nil, fmt.Errorf("gave up waiting to send: %w", ctx.Err())
+	}
+}
+
+// release hands the slot over to the longest waiting send, if any, otherwise frees it
+func (limiter *sendLimiter) release() {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if len(limiter.waiting) > 0 {
+		next := limiter.waiting[0]
+		limiter.waiting = limiter.waiting[1:]
+		close(next)
+		return
+	}
+
+	limiter.inFlight--
+}
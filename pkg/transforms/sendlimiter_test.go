@@ -0,0 +1,124 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendLimiterNoLimit(t *testing.T) {
+	limiter := newSendLimiter(0)
+	assert.Nil(t, limiter)
+
+	// A nil limiter never waits
+	release, err := limiter.acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestSendLimiter(t *testing.T) {
+	limiter := newSendLimiter(2)
+
+	first, err := limiter.acquire(context.Background())
+	require.NoError(t, err)
+	second, err := limiter.acquire(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, limiter.inFlight)
+
+	// Both slots are in flight, so a further send waits
+	acquired := make(chan func())
+	go func() {
+		release, _ := limiter.acquire(context.Background())
+		acquired <- release
+	}()
+	select {
+	case <-acquired:
+		require.Fail(t, "send not limited")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first()
+	third := <-acquired
+	second()
+	third()
+	assert.Equal(t, 0, limiter.inFlight)
+}
+
+func TestSendLimiterOrder(t *testing.T) {
+	limiter := newSendLimiter(1)
+
+	first, err := limiter.acquire(context.Background())
+	require.NoError(t, err)
+
+	var mutex sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for index := 1; index <= 3; index++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			release, _ := limiter.acquire(context.Background())
+			mutex.Lock()
+			order = append(order, index)
+			mutex.Unlock()
+			release()
+		}(index)
+
+		// Waits until the send is waiting for a slot, so they wait in order
+		require.Eventually(t, func() bool {
+			limiter.mutex.Lock()
+			defer limiter.mutex.Unlock()
+			return len(limiter.waiting) == index
+		}, time.Second, time.Millisecond)
+	}
+
+	first()
+	wg.Wait()
+
+	assert.Equal(t, []int{1, 2, 3}, order)
+	assert.Equal(t, 0, limiter.inFlight)
+	assert.Empty(t, limiter.waiting)
+}
+
+func TestSendLimiterContextDone(t *testing.T) {
+	limiter := newSendLimiter(1)
+
+	first, err := limiter.acquire(context.Background())
+	require.NoError(t, err)
+
+	// A send waiting for a slot gives up once its context is done, without holding a slot
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	release, err := limiter.acquire(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, release)
+	assert.Empty(t, limiter.waiting)
+
+	first()
+	assert.Equal(t, 0, limiter.inFlight)
+
+	second, err := limiter.acquire(context.Background())
+	require.NoError(t, err)
+	second()
+}
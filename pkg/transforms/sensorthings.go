@@ -0,0 +1,305 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+// DefaultSensorThingsDatastreamName is the default name of the Datastream each reading's Observation belongs to
+const DefaultSensorThingsDatastreamName = "{devicename}.{resourcename}"
+
+// SensorThingsConfig contains the settings for exporting Events as OGC SensorThings API Observations
+type SensorThingsConfig struct {
+	// URL is the base URL of the SensorThings API service, i.e. http://frost:8080/FROST-Server/v1.1
+	URL string
+	// DatastreamName is the name of the Datastream the Observation of each reading is added to, which may contain
+	// {devicename}, {resourcename}, {profilename}, {sourcename} and other context value placeholders. Defaults to
+	// DefaultSensorThingsDatastreamName.
+	DatastreamName string
+	// PersistOnError enables use of store & forward loop if true
+	PersistOnError bool
+	// HTTPHeaderName to use for passing configured secret
+	HTTPHeaderName string
+	// SecretPath to search for configured secret
+	SecretPath string
+	// SecretName for configured secret
+	SecretName string
+}
+
+// SensorThings exports the readings of Events as Observations of existing Datastreams in an OGC SensorThings API
+// service, i.e. FROST-Server. The Datastream ids are resolved by name and cached.
+type SensorThings struct {
+	config      SensorThingsConfig
+	client      *http.Client
+	lock        sync.Mutex
+	datastreams map[string]interface{}
+}
+
+// sensorThingsObservation is the body of an Observation created for a reading
+type sensorThingsObservation struct {
+	PhenomenonTime string                 `json:"phenomenonTime"`
+	Result         interface{}            `json:"result"`
+	Datastream     map[string]interface{} `json:"Datastream"`
+}
+
+// NewSensorThings creates, initializes and returns a new instance of SensorThings
+func NewSensorThings(config SensorThingsConfig) (*SensorThings, error) {
+	if len(config.URL) == 0 {
+		return nil, errors.New("SensorThings URL must be specified")
+	}
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("SensorThings URL is invalid: %s", err.Error())
+	}
+
+	usingSecrets := len(config.SecretPath) > 0 || len(config.SecretName) > 0 || len(config.HTTPHeaderName) > 0
+	if usingSecrets && (len(config.SecretPath) == 0 || len(config.SecretName) == 0 || len(config.HTTPHeaderName) == 0) {
+		return nil, errors.New("HTTP Header Name, secretPath and secretName must all be specified when using secrets")
+	}
+
+	if len(config.DatastreamName) == 0 {
+		config.DatastreamName = DefaultSensorThingsDatastreamName
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+
+	return &SensorThings{
+		config:      config,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		datastreams: make(map[string]interface{}),
+	}, nil
+}
+
+// Export creates an Observation of the Datastream matching each of the Event's readings. Readings without a
+// matching Datastream are skipped. The data received is either an Event or, when retried from Store and Forward,
+// its JSON. When PersistOnError is set, the readings whose Observations couldn't be created are stored for retry.
+// This function will return an error and stop the pipeline if a non-edgex event is received, if no data is
+// received or if any of the Observations can not be created.
+func (sensorThings *SensorThings) Export(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Export: No Event Received")
+	}
+
+	var event dtos.Event
+	switch value := data.(type) {
+	case dtos.Event:
+		event = value
+	case []byte:
+		if err := json.Unmarshal(value, &event); err != nil {
+			return false, fmt.Errorf("Export: unable to unmarshal Event: %s", err.Error())
+		}
+	default:
+		return false, errors.New("Export: type received is not an Event")
+	}
+
+	header, err := sensorThings.secretHeader(ctx)
+	if err != nil {
+		return false, fmt.Errorf("Export: %s", err.Error())
+	}
+
+	lc := ctx.LoggingClient()
+	failed := event
+	failed.Readings = nil
+	var errs []string
+
+	for _, reading := range event.Readings {
+		created, err := sensorThings.createObservation(ctx, event, reading, header)
+		if err != nil {
+			failed.Readings = append(failed.Readings, reading)
+			errs = append(errs, err.Error())
+			continue
+		}
+		if !created {
+			lc.Debugf("No SensorThings Datastream for '%s' reading of '%s', skipping", reading.ResourceName, event.DeviceName)
+		}
+	}
+
+	if len(errs) > 0 {
+		if sensorThings.config.PersistOnError {
+			if retryData, err := json.Marshal(failed); err == nil {
+				ctx.SetRetryData(retryData)
+			}
+		}
+		return false, fmt.Errorf("Export: unable to create %d of %d SensorThings Observations: %s", len(errs), len(event.Readings), strings.Join(errs, "; "))
+	}
+
+	return true, event
+}
+
+// createObservation creates the Observation of the reading, returning false if there is no matching Datastream
+func (sensorThings *SensorThings) createObservation(ctx interfaces.AppFunctionContext, event dtos.Event, reading dtos.BaseReading, header string) (bool, error) {
+	name, err := sensorThings.datastreamName(ctx, event, reading)
+	if err != nil {
+		return false, fmt.Errorf("unable to format Datastream name for '%s': %s", reading.ResourceName, err.Error())
+	}
+
+	id, err := sensorThings.resolveDatastream(ctx, name, header)
+	if err != nil || id == nil {
+		return false, err
+	}
+
+	result, err := readingJSONValue(reading)
+	if err != nil {
+		return false, err
+	}
+
+	body, err := json.Marshal(sensorThingsObservation{
+		PhenomenonTime: time.Unix(0, reading.Origin).UTC().Format(time.RFC3339Nano),
+		Result:         result,
+		Datastream:     map[string]interface{}{"@iot.id": id},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	response, err := sensorThings.request(ctx, http.MethodPost, sensorThings.config.URL+"/Observations", body, header)
+	if err != nil {
+		return false, fmt.Errorf("unable to create Observation for Datastream '%s': %s", name, err.Error())
+	}
+	_ = response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		// The Datastream may have been deleted or re-created, so it is resolved again next time
+		sensorThings.lock.Lock()
+		delete(sensorThings.datastreams, name)
+		sensorThings.lock.Unlock()
+		return false, fmt.Errorf("unable to create Observation for Datastream '%s': status %d", name, response.StatusCode)
+	}
+
+	return true, nil
+}
+
+// resolveDatastream returns the id of the Datastream with the name, or nil if there isn't one. Ids are cached once
+// resolved. Ids may be numbers or strings depending on the service.
+func (sensorThings *SensorThings) resolveDatastream(ctx interfaces.AppFunctionContext, name string, header string) (interface{}, error) {
+	sensorThings.lock.Lock()
+	id, found := sensorThings.datastreams[name]
+	sensorThings.lock.Unlock()
+	if found {
+		return id, nil
+	}
+
+	query := url.Values{}
+	query.Set("$filter", fmt.Sprintf("name eq '%s'", strings.ReplaceAll(name, "'", "''")))
+	query.Set("$select", "id")
+	// Spaces must be encoded as %20 rather than +, which not all services decode
+	requestURL := sensorThings.config.URL + "/Datastreams?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	response, err := sensorThings.request(ctx, http.MethodGet, requestURL, nil, header)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve Datastream '%s': %s", name, err.Error())
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to resolve Datastream '%s': status %d", name, response.StatusCode)
+	}
+
+	var result struct {
+		Value []map[string]interface{} `json:"value"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to decode Datastreams for '%s': %s", name, err.Error())
+	}
+
+	if len(result.Value) == 0 {
+		return nil, nil
+	}
+
+	id, found = result.Value[0]["@iot.id"]
+	if !found || id == nil {
+		return nil, fmt.Errorf("Datastream '%s' has no @iot.id", name)
+	}
+
+	sensorThings.lock.Lock()
+	sensorThings.datastreams[name] = id
+	sensorThings.lock.Unlock()
+
+	ctx.LoggingClient().Debugf("Resolved SensorThings Datastream '%s' to id %v", name, id)
+	return id, nil
+}
+
+func (sensorThings *SensorThings) request(ctx interfaces.AppFunctionContext, method string, requestURL string, body []byte, header string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx.Context(), method, requestURL, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if len(header) > 0 {
+		request.Header.Set(sensorThings.config.HTTPHeaderName, header)
+	}
+
+	response, err := sensorThings.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if method != http.MethodGet {
+		// Drain the body so the connection can be re-used
+		_, _ = io.Copy(ioutil.Discard, response.Body)
+	}
+
+	ctx.LoggingClient().Tracef("SensorThings %s %s: %s", method, requestURL, response.Status)
+	return response, nil
+}
+
+// datastreamName formats the Datastream name for the reading, replacing the Event and reading placeholders
+// followed by the other context values
+func (sensorThings *SensorThings) datastreamName(ctx interfaces.AppFunctionContext, event dtos.Event, reading dtos.BaseReading) (string, error) {
+	replacer := strings.NewReplacer(
+		"{"+interfaces.DEVICENAME+"}", event.DeviceName,
+		"{"+interfaces.PROFILENAME+"}", event.ProfileName,
+		"{"+interfaces.SOURCENAME+"}", event.SourceName,
+		"{resourcename}", reading.ResourceName)
+
+	return ctx.ApplyValues(replacer.Replace(sensorThings.config.DatastreamName))
+}
+
+// secretHeader returns the secret header value, if using secrets
+func (sensorThings *SensorThings) secretHeader(ctx interfaces.AppFunctionContext) (string, error) {
+	if len(sensorThings.config.SecretPath) == 0 {
+		return "", nil
+	}
+
+	secrets, err := ctx.GetSecret(sensorThings.config.SecretPath, sensorThings.config.SecretName)
+	if err != nil {
+		return "", err
+	}
+
+	return secrets[sensorThings.config.SecretName], nil
+}
@@ -0,0 +1,178 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSensorThingsService is a SensorThings API service with the Datastreams, keyed by name
+type fakeSensorThingsService struct {
+	t            *testing.T
+	datastreams  map[string]int
+	lookups      int
+	observations []map[string]interface{}
+	failCreate   bool
+}
+
+func (service *fakeSensorThingsService) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	switch {
+	case request.Method == http.MethodGet && request.URL.Path == "/v1.1/Datastreams":
+		service.lookups++
+		assert.Contains(service.t, request.URL.RawQuery, "%20eq%20", "spaces must be encoded as %20")
+		filter := request.URL.Query().Get("$filter")
+		name := strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(filter, "name eq '"), "'"), "''", "'")
+		values := []map[string]interface{}{}
+		if id, found := service.datastreams[name]; found {
+			values = append(values, map[string]interface{}{"@iot.id": id})
+		}
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"value": values})
+	case request.Method == http.MethodPost && request.URL.Path == "/v1.1/Observations":
+		if service.failCreate {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		assert.Equal(service.t, "application/json", request.Header.Get("Content-Type"))
+		observation := map[string]interface{}{}
+		require.NoError(service.t, json.NewDecoder(request.Body).Decode(&observation))
+		service.observations = append(service.observations, observation)
+		writer.WriteHeader(http.StatusCreated)
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newSensorThingsTestEvent() dtos.Event {
+	event := dtos.NewEvent("thermostat", "room-1", "status")
+	event.Readings = []dtos.BaseReading{
+		{DeviceName: "room-1", ResourceName: "temperature", ProfileName: "thermostat", Origin: 1600000000000000000, ValueType: common.ValueTypeFloat64, SimpleReading: dtos.SimpleReading{Value: "21.5"}},
+		{DeviceName: "room-1", ResourceName: "on", ProfileName: "thermostat", Origin: 1600000000500000000, ValueType: common.ValueTypeBool, SimpleReading: dtos.SimpleReading{Value: "true"}},
+		{DeviceName: "room-1", ResourceName: "mode", ProfileName: "thermostat", Origin: 1600000000000000000, ValueType: common.ValueTypeString, SimpleReading: dtos.SimpleReading{Value: "heat"}},
+	}
+	return event
+}
+
+func TestNewSensorThings(t *testing.T) {
+	_, err := NewSensorThings(SensorThingsConfig{})
+	assert.Error(t, err)
+
+	_, err = NewSensorThings(SensorThingsConfig{URL: "http://frost/v1.1", SecretPath: "frost"})
+	assert.Error(t, err, "secret name and header name are required with the secret path")
+
+	target, err := NewSensorThings(SensorThingsConfig{URL: "http://frost/v1.1/"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://frost/v1.1", target.config.URL)
+	assert.Equal(t, DefaultSensorThingsDatastreamName, target.config.DatastreamName)
+}
+
+func TestSensorThingsExport(t *testing.T) {
+	service := &fakeSensorThingsService{t: t, datastreams: map[string]int{"room-1.temperature": 7, "room-1.on": 8}}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	target, err := NewSensorThings(SensorThingsConfig{URL: server.URL + "/v1.1"})
+	require.NoError(t, err)
+
+	event := newSensorThingsTestEvent()
+	continuePipeline, result := target.Export(ctx, event)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Equal(t, event, result)
+
+	// The mode reading has no Datastream so is skipped
+	require.Len(t, service.observations, 2)
+	assert.Equal(t, map[string]interface{}{
+		"phenomenonTime": "2020-09-13T12:26:40Z",
+		"result":         21.5,
+		"Datastream":     map[string]interface{}{"@iot.id": float64(7)},
+	}, service.observations[0])
+	assert.Equal(t, "2020-09-13T12:26:40.5Z", service.observations[1]["phenomenonTime"])
+	assert.Equal(t, true, service.observations[1]["result"])
+	assert.Equal(t, 3, service.lookups)
+
+	// Resolved Datastreams are cached
+	continuePipeline, _ = target.Export(ctx, event)
+	require.True(t, continuePipeline)
+	assert.Len(t, service.observations, 4)
+	assert.Equal(t, 4, service.lookups, "only the missing Datastream is looked up again")
+}
+
+func TestSensorThingsExportDatastreamName(t *testing.T) {
+	service := &fakeSensorThingsService{t: t, datastreams: map[string]int{"thermostat 'room-1' temperature": 1}}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	target, err := NewSensorThings(SensorThingsConfig{URL: server.URL + "/v1.1", DatastreamName: "{profilename} '{devicename}' {resourcename}"})
+	require.NoError(t, err)
+
+	continuePipeline, result := target.Export(ctx, newSensorThingsTestEvent())
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Len(t, service.observations, 1)
+}
+
+func TestSensorThingsExportErrors(t *testing.T) {
+	service := &fakeSensorThingsService{t: t, datastreams: map[string]int{"room-1.temperature": 7, "room-1.on": 8}}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	target, err := NewSensorThings(SensorThingsConfig{URL: server.URL + "/v1.1", PersistOnError: true})
+	require.NoError(t, err)
+
+	continuePipeline, result := target.Export(ctx, nil)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "No Event Received")
+
+	continuePipeline, result = target.Export(ctx, "bogus")
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "not an Event")
+
+	// The readings which failed are stored for retry, which passes the Event's JSON
+	ctx.SetRetryData(nil)
+	service.failCreate = true
+	event := newSensorThingsTestEvent()
+	continuePipeline, result = target.Export(ctx, event)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "unable to create 2 of 3 SensorThings Observations")
+	require.NotNil(t, ctx.RetryData())
+
+	var retry dtos.Event
+	require.NoError(t, json.Unmarshal(ctx.RetryData(), &retry))
+	assert.Equal(t, event.Id, retry.Id)
+	assert.Len(t, retry.Readings, 2)
+
+	service.failCreate = false
+	continuePipeline, result = target.Export(ctx, ctx.RetryData())
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Len(t, service.observations, 2)
+	ctx.SetRetryData(nil)
+
+	target, err = NewSensorThings(SensorThingsConfig{URL: fmt.Sprintf("%s/bogus", server.URL)})
+	require.NoError(t, err)
+	continuePipeline, result = target.Export(ctx, event)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "status 404")
+}
@@ -0,0 +1,280 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+const (
+	// SignatureSchemeHMAC verifies a hex encoded HMAC-SHA256 of the payload, optionally prefixed with "sha256=",
+	// found in the context value with the interfaces.SIGNATURE key, i.e. from the HTTP trigger's X-Signature header
+	SignatureSchemeHMAC = "hmac"
+	// SignatureSchemeJWS verifies the payload is a JSON Web Signature in compact serialization signed with HS256,
+	// HS384 or HS512 using a shared secret key, or RS256 or ES256 using a PEM encoded public key
+	SignatureSchemeJWS = "jws"
+)
+
+// SignatureVerifier houses the scheme and key used to verify the signature of inbound payloads
+type SignatureVerifier struct {
+	scheme     string
+	key        string
+	secretPath string
+	secretName string
+}
+
+// NewSignatureVerifier creates, initializes and returns a new instance of SignatureVerifier which verifies signatures
+// using the specified scheme, i.e. SignatureSchemeJWS, and key. The key is the shared secret for HMAC signatures or
+// a PEM encoded RSA or P-256 ECDSA public key for RS256 or ES256 JSON Web Signatures.
+// An error is returned if the scheme is unknown or the key is empty.
+func NewSignatureVerifier(scheme string, key string) (*SignatureVerifier, error) {
+	if len(key) == 0 {
+		return nil, errors.New("signature verification key must be specified")
+	}
+
+	return newSignatureVerifier(scheme, key, "", "")
+}
+
+// NewSignatureVerifierWithSecrets creates, initializes and returns a new instance of SignatureVerifier the same as
+// NewSignatureVerifier, but configured to retrieve the key from the Secret Store
+func NewSignatureVerifierWithSecrets(scheme string, secretPath string, secretName string) (*SignatureVerifier, error) {
+	if len(secretPath) == 0 || len(secretName) == 0 {
+		return nil, errors.New("secret path and secret name must both be specified")
+	}
+
+	return newSignatureVerifier(scheme, "", secretPath, secretName)
+}
+
+func newSignatureVerifier(scheme string, key string, secretPath string, secretName string) (*SignatureVerifier, error) {
+	scheme = strings.ToLower(scheme)
+	switch scheme {
+	case SignatureSchemeHMAC, SignatureSchemeJWS:
+	default:
+		return nil, fmt.Errorf("invalid signature scheme '%s'. Must be '%s' or '%s'", scheme, SignatureSchemeHMAC, SignatureSchemeJWS)
+	}
+
+	return &SignatureVerifier{
+		scheme:     scheme,
+		key:        key,
+		secretPath: secretPath,
+		secretName: secretName,
+	}, nil
+}
+
+// VerifySignature verifies the signature of the payload received, i.e. from the HTTP or MQTT trigger, so that only
+// payloads from trusted senders are processed. The pipeline must use a target type of byte array so the payload is
+// verified as it was received. HMAC verified payloads are passed on unchanged and the payload of a JSON Web Signature
+// is passed on as a byte array.
+// It will return an error and stop the pipeline if no data is received or if the key can't be retrieved from the
+// Secret Store. If the signature is missing or doesn't match, the pipeline is stopped with an error which results
+// in the HTTP trigger responding with 401 (Unauthorized).
+func (v *SignatureVerifier) VerifySignature(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("VerifySignature: no data received")
+	}
+
+	payload, err := util.CoerceType(data)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := v.getKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	ctx.LoggingClient().Debugf("Verifying %s signature of payload", v.scheme)
+
+	var result interface{} = data
+	if v.scheme == SignatureSchemeHMAC {
+		signature, _ := ctx.GetValue(interfaces.SIGNATURE)
+		err = verifyHMACSignature(payload, signature, key)
+	} else {
+		result, err = verifyJWS(payload, key)
+	}
+
+	if err != nil {
+		return false, util.NewHTTPStatusError(http.StatusUnauthorized, fmt.Errorf("signature verification failed: %s", err.Error()))
+	}
+
+	return true, result
+}
+
+// getKey returns the key from configuration or from the Secret Store
+func (v *SignatureVerifier) getKey(ctx interfaces.AppFunctionContext) ([]byte, error) {
+	if len(v.secretPath) == 0 {
+		return []byte(v.key), nil
+	}
+
+	// Note secrets are cached so this call doesn't result in unneeded calls to SecretStore Service
+	secretData, err := ctx.GetSecret(v.secretPath, v.secretName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve signature key at secret path=%s and name=%s", v.secretPath, v.secretName)
+	}
+
+	key, ok := secretData[v.secretName]
+	if !ok || len(key) == 0 {
+		return nil, fmt.Errorf("unable to find signature key in secret data for name=%s", v.secretName)
+	}
+
+	return []byte(key), nil
+}
+
+func verifyHMACSignature(payload []byte, signature string, key []byte) error {
+	if len(signature) == 0 {
+		return errors.New("missing signature")
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return errors.New("signature is not hex encoded")
+	}
+
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(payload)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+// verifyJWS verifies the JSON Web Signature in compact serialization and returns its decoded payload.
+// The algorithms accepted depend on the key, so an HMAC key can't be used to verify a token claiming to be
+// signed with a public key algorithm and vice versa.
+func verifyJWS(token []byte, key []byte) ([]byte, error) {
+	parts := strings.Split(strings.TrimSpace(string(token)), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("payload is not a JWS in compact serialization")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("invalid JWS header encoding")
+	}
+
+	var header struct {
+		Algorithm string   `json:"alg"`
+		Critical  []string `json:"crit"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, errors.New("invalid JWS header")
+	}
+
+	if len(header.Critical) > 0 {
+		return nil, fmt.Errorf("unsupported critical JWS header parameters %v", header.Critical)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("invalid JWS payload encoding")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("invalid JWS signature encoding")
+	}
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+
+	publicKey, isPublicKey, err := parsePublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch header.Algorithm {
+	case "HS256", "HS384", "HS512":
+		if isPublicKey {
+			return nil, fmt.Errorf("JWS algorithm %s can't be verified with a public key", header.Algorithm)
+		}
+
+		newHash := map[string]func() hash.Hash{"HS256": sha256.New, "HS384": sha512.New384, "HS512": sha512.New}[header.Algorithm]
+		mac := hmac.New(newHash, key)
+		_, _ = mac.Write(signingInput)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return nil, errors.New("signature mismatch")
+		}
+
+	case "RS256":
+		rsaKey, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("JWS algorithm RS256 requires an RSA public key")
+		}
+
+		digest := sha256.Sum256(signingInput)
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("signature mismatch")
+		}
+
+	case "ES256":
+		ecdsaKey, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok || ecdsaKey.Curve != elliptic.P256() {
+			return nil, errors.New("JWS algorithm ES256 requires a P-256 ECDSA public key")
+		}
+
+		if len(signature) != 64 {
+			return nil, errors.New("signature mismatch")
+		}
+
+		digest := sha256.Sum256(signingInput)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecdsaKey, digest[:], r, s) {
+			return nil, errors.New("signature mismatch")
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported JWS algorithm '%s'", header.Algorithm)
+	}
+
+	return payload, nil
+}
+
+// parsePublicKey parses the key as a PEM encoded public key, returning false if it isn't PEM encoded
+func parsePublicKey(key []byte) (crypto.PublicKey, bool, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, false, nil
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, true, fmt.Errorf("unable to parse PEM encoded public key: %s", err.Error())
+	}
+
+	return publicKey, true, nil
+}
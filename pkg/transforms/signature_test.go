@@ -0,0 +1,227 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signaturePayload = `{"temperature":21.5}`
+
+func newSignatureTestContext(signature string) *appfunction.Context {
+	signatureCtx := appfunction.NewContext("123", dic, "")
+	if len(signature) > 0 {
+		signatureCtx.AddValue(interfaces.SIGNATURE, signature)
+	}
+	return signatureCtx
+}
+
+func hmacTestSignature(payload string, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	_, _ = mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newTestJWS(algorithm string, payload string, sign func(signingInput []byte) []byte) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + algorithm + `","typ":"JWT"}`))
+	body := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	signingInput := header + "." + body
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signingInput)))
+}
+
+func encodeTestPublicKey(t *testing.T, publicKey crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func requireUnauthorized(t *testing.T, continuePipeline bool, result interface{}) {
+	assert.False(t, continuePipeline)
+	err, ok := result.(error)
+	require.True(t, ok, "expected an error")
+
+	var statusError util.HTTPStatusError
+	require.True(t, errors.As(err, &statusError), err.Error())
+	assert.Equal(t, http.StatusUnauthorized, statusError.StatusCode)
+}
+
+func TestNewSignatureVerifier(t *testing.T) {
+	_, err := NewSignatureVerifier("HMAC", "key")
+	require.NoError(t, err)
+	_, err = NewSignatureVerifier(SignatureSchemeJWS, "key")
+	require.NoError(t, err)
+	_, err = NewSignatureVerifier("pgp", "key")
+	require.Error(t, err)
+	_, err = NewSignatureVerifier(SignatureSchemeHMAC, "")
+	require.Error(t, err)
+	_, err = NewSignatureVerifierWithSecrets(SignatureSchemeHMAC, "signing", "")
+	require.Error(t, err)
+}
+
+func TestSignatureVerifier_VerifyHMAC(t *testing.T) {
+	verifier, err := NewSignatureVerifier(SignatureSchemeHMAC, "shared-secret")
+	require.NoError(t, err)
+
+	signature := hmacTestSignature(signaturePayload, "shared-secret")
+
+	continuePipeline, result := verifier.VerifySignature(newSignatureTestContext(signature), []byte(signaturePayload))
+	require.True(t, continuePipeline, result)
+	assert.Equal(t, []byte(signaturePayload), result)
+
+	continuePipeline, result = verifier.VerifySignature(newSignatureTestContext("sha256="+signature), []byte(signaturePayload))
+	require.True(t, continuePipeline, result)
+
+	tests := []struct {
+		Name      string
+		Signature string
+		Payload   string
+	}{
+		{"Missing signature", "", signaturePayload},
+		{"Not hex", "not-hex", signaturePayload},
+		{"Wrong key", hmacTestSignature(signaturePayload, "other-secret"), signaturePayload},
+		{"Tampered payload", signature, `{"temperature":99}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := verifier.VerifySignature(newSignatureTestContext(test.Signature), []byte(test.Payload))
+			requireUnauthorized(t, continuePipeline, result)
+		})
+	}
+}
+
+func TestSignatureVerifier_VerifyJWS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signHS256 := func(key string) func([]byte) []byte {
+		return func(signingInput []byte) []byte {
+			mac := hmac.New(sha256.New, []byte(key))
+			_, _ = mac.Write(signingInput)
+			return mac.Sum(nil)
+		}
+	}
+	signRS256 := func(signingInput []byte) []byte {
+		digest := sha256.Sum256(signingInput)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signature
+	}
+	signES256 := func(signingInput []byte) []byte {
+		digest := sha256.Sum256(signingInput)
+		r, s, err := ecdsa.Sign(rand.Reader, ecdsaKey, digest[:])
+		require.NoError(t, err)
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature
+	}
+
+	rsaPublicKey := encodeTestPublicKey(t, &rsaKey.PublicKey)
+	ecdsaPublicKey := encodeTestPublicKey(t, &ecdsaKey.PublicKey)
+
+	tests := []struct {
+		Name        string
+		Key         string
+		Token       string
+		ExpectValid bool
+	}{
+		{"HS256", "shared-secret", newTestJWS("HS256", signaturePayload, signHS256("shared-secret")), true},
+		{"RS256", rsaPublicKey, newTestJWS("RS256", signaturePayload, signRS256), true},
+		{"ES256", ecdsaPublicKey, newTestJWS("ES256", signaturePayload, signES256), true},
+		{"HS256 wrong key", "other-secret", newTestJWS("HS256", signaturePayload, signHS256("shared-secret")), false},
+		{"RS256 wrong key type", ecdsaPublicKey, newTestJWS("RS256", signaturePayload, signRS256), false},
+		{"HS256 with public key", rsaPublicKey, newTestJWS("HS256", signaturePayload, signHS256(rsaPublicKey)), false},
+		{"RS256 with shared secret", "shared-secret", newTestJWS("RS256", signaturePayload, signRS256), false},
+		{"none algorithm", "shared-secret", newTestJWS("none", signaturePayload, func([]byte) []byte { return nil }), false},
+		{"Not a JWS", "shared-secret", signaturePayload, false},
+		{"Tampered token", "shared-secret",
+			newTestJWS("HS256", signaturePayload, signHS256("shared-secret"))[:10] + "x" + newTestJWS("HS256", signaturePayload, signHS256("shared-secret"))[11:], false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			verifier, err := NewSignatureVerifier(SignatureSchemeJWS, test.Key)
+			require.NoError(t, err)
+
+			continuePipeline, result := verifier.VerifySignature(newSignatureTestContext(""), []byte(test.Token))
+			if !test.ExpectValid {
+				requireUnauthorized(t, continuePipeline, result)
+				return
+			}
+
+			require.True(t, continuePipeline, result)
+			assert.Equal(t, []byte(signaturePayload), result)
+		})
+	}
+}
+
+func TestSignatureVerifier_VerifyWithSecrets(t *testing.T) {
+	secretPath := "signing"
+	secretName := "signingKey"
+
+	mockSP := &mocks.SecretProvider{}
+	mockSP.On("GetSecret", secretPath, secretName).Return(map[string]string{secretName: "stored-secret"}, nil)
+
+	dic.Update(di.ServiceConstructorMap{
+		bootstrapContainer.SecretProviderName: func(get di.Get) interface{} {
+			return mockSP
+		},
+	})
+
+	verifier, err := NewSignatureVerifierWithSecrets(SignatureSchemeHMAC, secretPath, secretName)
+	require.NoError(t, err)
+
+	signature := hmacTestSignature(signaturePayload, "stored-secret")
+	continuePipeline, result := verifier.VerifySignature(newSignatureTestContext(signature), []byte(signaturePayload))
+	require.True(t, continuePipeline, result)
+}
+
+func TestSignatureVerifier_VerifyNoData(t *testing.T) {
+	verifier, err := NewSignatureVerifier(SignatureSchemeHMAC, "shared-secret")
+	require.NoError(t, err)
+
+	continuePipeline, result := verifier.VerifySignature(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "VerifySignature: no data received")
+}
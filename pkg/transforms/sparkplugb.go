@@ -0,0 +1,426 @@
+//go:build !no_sparkplugb
+// +build !no_sparkplugb
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/sparkplugb"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/secure"
+)
+
+const (
+	// SparkplugBNamespace is the first element of all Sparkplug B topics
+	SparkplugBNamespace = "spBv1.0"
+
+	sparkplugNodeBirth   = "NBIRTH"
+	sparkplugNodeDeath   = "NDEATH"
+	sparkplugNodeCommand = "NCMD"
+	sparkplugDeviceBirth = "DBIRTH"
+	sparkplugDeviceData  = "DDATA"
+
+	sparkplugBdSeqMetric   = "bdSeq"
+	sparkplugRebirthMetric = "Node Control/Rebirth"
+
+	defaultSparkplugConnectTimeout = 30 * time.Second
+)
+
+// SparkplugBConfig contains the settings for publishing Events to a Sparkplug B infrastructure
+type SparkplugBConfig struct {
+	// BrokerAddress should be set to the complete broker address i.e. mqtts://mosquitto:8883/mybroker
+	BrokerAddress string
+	// ClientId to connect with the broker with.
+	ClientId string
+	// AuthMode indicates what to use when connecting to the broker. Options are "none", "cacert" , "usernamepassword", "clientcert".
+	AuthMode string
+	// SecretPath is the path in the secret provider of the broker's credentials
+	SecretPath string
+	// SkipCertVerify
+	SkipCertVerify bool
+	// GroupId is the Sparkplug group the edge node belongs to
+	GroupId string
+	// EdgeNodeId identifies the app service as a Sparkplug edge node. Each Event's device is a device of the edge node.
+	EdgeNodeId string
+	// ConnectTimeout is the duration for timing out on connecting to the broker. Defaults to 30 seconds.
+	ConnectTimeout time.Duration
+}
+
+// SparkplugB publishes Events as Sparkplug B payloads. Each connection to the broker is a Sparkplug session which
+// starts with the edge node's NBIRTH and ends with its NDEATH, registered as the Last Will and Testament. Each
+// device's first Event in a session, or an Event with readings not previously seen for the device, is published as
+// the device's DBIRTH and the rest as its DDATA.
+type SparkplugB struct {
+	config SparkplugBConfig
+	lock   sync.Mutex
+	client MQTT.Client
+	// newClient creates the client for each session, which is replaced by tests
+	newClient func(ctx interfaces.AppFunctionContext, opts *MQTT.ClientOptions) (MQTT.Client, error)
+	bdSeq     uint64
+	seq       uint64
+	// devices holds the last value of each metric of the devices born in the current session
+	devices map[string]map[string]*sparkplugb.Payload_Metric
+}
+
+// NewSparkplugB creates, initializes and returns a new instance of SparkplugB
+func NewSparkplugB(config SparkplugBConfig) (*SparkplugB, error) {
+	if len(config.BrokerAddress) == 0 {
+		return nil, errors.New("Sparkplug B broker address must be specified")
+	}
+
+	for name, id := range map[string]string{"group id": config.GroupId, "edge node id": config.EdgeNodeId} {
+		if len(id) == 0 {
+			return nil, fmt.Errorf("Sparkplug B %s must be specified", name)
+		}
+		if strings.ContainsAny(id, "/+#") {
+			return nil, fmt.Errorf("Sparkplug B %s '%s' must not contain '/', '+' or '#'", name, id)
+		}
+	}
+
+	if config.ConnectTimeout <= 0 {
+		config.ConnectTimeout = defaultSparkplugConnectTimeout
+	}
+
+	return &SparkplugB{
+		config: config,
+		newClient: func(ctx interfaces.AppFunctionContext, opts *MQTT.ClientOptions) (MQTT.Client, error) {
+			return secure.NewMqttFactory(ctx, config.AuthMode, config.SecretPath, config.SkipCertVerify).Create(opts)
+		},
+	}, nil
+}
+
+// Export publishes the Event received as its device's DBIRTH or DDATA, first connecting and publishing the edge
+// node's NBIRTH when there isn't a session.
+// This function will return an error and stop the pipeline if a non-edgex event is received, if no data is
+// received or if the Event can not be published.
+func (sparkplug *SparkplugB) Export(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Export: no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("Export: type received is not an Event")
+	}
+
+	metrics := make([]*sparkplugb.Payload_Metric, 0, len(event.Readings))
+	for _, reading := range event.Readings {
+		metric, err := readingToSparkplugMetric(reading)
+		if err != nil {
+			return false, fmt.Errorf("Export: %s", err.Error())
+		}
+		metrics = append(metrics, metric)
+	}
+
+	sparkplug.lock.Lock()
+	defer sparkplug.lock.Unlock()
+
+	if err := sparkplug.connect(ctx); err != nil {
+		return false, fmt.Errorf("Export: unable to start Sparkplug B session: %s", err.Error())
+	}
+
+	deviceId := sparkplugId(event.DeviceName)
+	messageType, metrics := sparkplug.deviceMessage(deviceId, metrics)
+
+	if err := sparkplug.publish(sparkplug.topic(messageType, deviceId), metrics, true); err != nil {
+		// Starting a new session re-publishes the births so subscribers are back in sync
+		sparkplug.endSession()
+		return false, fmt.Errorf("Export: unable to publish Sparkplug B %s for '%s': %s", messageType, deviceId, err.Error())
+	}
+
+	ctx.LoggingClient().Debugf("Published Sparkplug B %s for '%s' with %d metrics", messageType, deviceId, len(metrics))
+
+	return true, event
+}
+
+// deviceMessage returns DBIRTH with all the device's metrics if the device hasn't been born in this session or has
+// new metrics, otherwise DDATA with the metrics
+func (sparkplug *SparkplugB) deviceMessage(deviceId string, metrics []*sparkplugb.Payload_Metric) (string, []*sparkplugb.Payload_Metric) {
+	known, born := sparkplug.devices[deviceId]
+	if !born {
+		known = make(map[string]*sparkplugb.Payload_Metric)
+		sparkplug.devices[deviceId] = known
+	}
+
+	rebirth := !born
+	for _, metric := range metrics {
+		if _, found := known[metric.GetName()]; !found {
+			rebirth = true
+		}
+		known[metric.GetName()] = metric
+	}
+
+	if !rebirth {
+		return sparkplugDeviceData, metrics
+	}
+
+	birth := make([]*sparkplugb.Payload_Metric, 0, len(known))
+	for _, metric := range known {
+		birth = append(birth, metric)
+	}
+	sort.Slice(birth, func(i, j int) bool {
+		return birth[i].GetName() < birth[j].GetName()
+	})
+
+	return sparkplugDeviceBirth, birth
+}
+
+// connect starts a new session when not connected. The lock must be held.
+func (sparkplug *SparkplugB) connect(ctx interfaces.AppFunctionContext) error {
+	if sparkplug.client != nil && sparkplug.client.IsConnected() {
+		return nil
+	}
+
+	sparkplug.endSession()
+	sparkplug.bdSeq++
+
+	death, err := marshalSparkplugPayload([]*sparkplugb.Payload_Metric{sparkplug.bdSeqMetric()}, nil)
+	if err != nil {
+		return err
+	}
+
+	opts := MQTT.NewClientOptions()
+	opts.AddBroker(sparkplug.config.BrokerAddress)
+	opts.SetClientID(sparkplug.config.ClientId)
+	opts.SetConnectTimeout(sparkplug.config.ConnectTimeout)
+	// Each session needs a new NDEATH with the session's bdSeq, so reconnects are made by starting a new session
+	opts.SetAutoReconnect(false)
+	opts.SetCleanSession(true)
+	opts.SetBinaryWill(sparkplug.topic(sparkplugNodeDeath, ""), death, 1, false)
+
+	client, err := sparkplug.newClient(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	token := client.Connect()
+	if !token.WaitTimeout(sparkplug.config.ConnectTimeout) {
+		return errors.New("timed out connecting to MQTT broker")
+	}
+	if token.Error() != nil {
+		return token.Error()
+	}
+
+	sparkplug.client = client
+	sparkplug.seq = 0
+	sparkplug.devices = make(map[string]map[string]*sparkplugb.Payload_Metric)
+
+	lc := ctx.LoggingClient()
+	token = client.Subscribe(sparkplug.topic(sparkplugNodeCommand, ""), 1, sparkplug.onCommand(lc))
+	if token.WaitTimeout(sparkplug.config.ConnectTimeout) && token.Error() != nil {
+		lc.Warnf("Unable to subscribe to Sparkplug B node commands, rebirth requests will be ignored: %s", token.Error().Error())
+	}
+
+	if err := sparkplug.publishNodeBirth(); err != nil {
+		sparkplug.endSession()
+		return err
+	}
+
+	lc.Infof("Started Sparkplug B session %d for edge node '%s/%s'", sparkplug.bdSeq, sparkplug.config.GroupId, sparkplug.config.EdgeNodeId)
+	return nil
+}
+
+// endSession disconnects from the broker, which publishes the NDEATH. The lock must be held.
+func (sparkplug *SparkplugB) endSession() {
+	if sparkplug.client != nil {
+		if sparkplug.client.IsConnected() {
+			sparkplug.client.Disconnect(0)
+		}
+		sparkplug.client = nil
+	}
+}
+
+// publishNodeBirth publishes the NBIRTH, which resets the sequence number and requires the devices to be born
+// again. The lock must be held.
+func (sparkplug *SparkplugB) publishNodeBirth() error {
+	sparkplug.seq = 0
+	sparkplug.devices = make(map[string]map[string]*sparkplugb.Payload_Metric)
+
+	rebirth := newSparkplugMetric(sparkplugRebirthMetric, sparkplugb.DataType_Boolean)
+	rebirth.Value = &sparkplugb.Payload_Metric_BooleanValue{BooleanValue: false}
+	return sparkplug.publish(sparkplug.topic(sparkplugNodeBirth, ""), []*sparkplugb.Payload_Metric{sparkplug.bdSeqMetric(), rebirth}, false)
+}
+
+// publish publishes the metrics with the next sequence number. The lock must be held.
+func (sparkplug *SparkplugB) publish(topic string, metrics []*sparkplugb.Payload_Metric, nextSeq bool) error {
+	if nextSeq {
+		sparkplug.seq = (sparkplug.seq + 1) % 256
+	}
+
+	payload, err := marshalSparkplugPayload(metrics, proto.Uint64(sparkplug.seq))
+	if err != nil {
+		return err
+	}
+
+	// Sparkplug B requires NBIRTH, DBIRTH and DDATA to be published with QoS 0 and not retained
+	token := sparkplug.client.Publish(topic, 0, false, payload)
+	if !token.WaitTimeout(sparkplug.config.ConnectTimeout) {
+		return errors.New("timed out publishing to MQTT broker")
+	}
+	return token.Error()
+}
+
+// onCommand returns the handler for the node commands, which re-publishes the births when a rebirth is requested
+func (sparkplug *SparkplugB) onCommand(lc logger.LoggingClient) MQTT.MessageHandler {
+	return func(_ MQTT.Client, message MQTT.Message) {
+		payload := &sparkplugb.Payload{}
+		if err := proto.Unmarshal(message.Payload(), payload); err != nil {
+			lc.Warnf("Unable to decode Sparkplug B node command: %s", err.Error())
+			return
+		}
+
+		for _, metric := range payload.GetMetrics() {
+			if metric.GetName() != sparkplugRebirthMetric || !metric.GetBooleanValue() {
+				continue
+			}
+
+			sparkplug.lock.Lock()
+			if sparkplug.client != nil {
+				if err := sparkplug.publishNodeBirth(); err != nil {
+					lc.Errorf("Unable to publish Sparkplug B NBIRTH for rebirth request: %s", err.Error())
+				} else {
+					lc.Info("Published Sparkplug B NBIRTH for rebirth request, devices are re-born with their next Event")
+				}
+			}
+			sparkplug.lock.Unlock()
+			return
+		}
+	}
+}
+
+func (sparkplug *SparkplugB) bdSeqMetric() *sparkplugb.Payload_Metric {
+	metric := newSparkplugMetric(sparkplugBdSeqMetric, sparkplugb.DataType_UInt64)
+	metric.Value = &sparkplugb.Payload_Metric_LongValue{LongValue: sparkplug.bdSeq}
+	return metric
+}
+
+// topic returns the topic in the Sparkplug B namespace, i.e. spBv1.0/<group id>/DDATA/<edge node id>/<device id>
+func (sparkplug *SparkplugB) topic(messageType string, deviceId string) string {
+	topic := strings.Join([]string{SparkplugBNamespace, sparkplug.config.GroupId, messageType, sparkplug.config.EdgeNodeId}, "/")
+	if len(deviceId) > 0 {
+		topic += "/" + deviceId
+	}
+	return topic
+}
+
+// sparkplugId replaces the characters which are not valid in Sparkplug B ids
+func sparkplugId(name string) string {
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(name)
+}
+
+func sparkplugTimestamp(nanoseconds int64) uint64 {
+	return uint64(nanoseconds / int64(time.Millisecond))
+}
+
+// marshalSparkplugPayload encodes the Sparkplug B payload of the metrics timestamped with the current time
+func marshalSparkplugPayload(metrics []*sparkplugb.Payload_Metric, seq *uint64) ([]byte, error) {
+	payload, err := proto.Marshal(&sparkplugb.Payload{
+		Timestamp: proto.Uint64(sparkplugTimestamp(time.Now().UnixNano())),
+		Metrics:   metrics,
+		Seq:       seq,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode Sparkplug B payload: %s", err.Error())
+	}
+	return payload, nil
+}
+
+func newSparkplugMetric(name string, dataType sparkplugb.DataType) *sparkplugb.Payload_Metric {
+	return &sparkplugb.Payload_Metric{
+		Name:     proto.String(name),
+		Datatype: proto.Uint32(uint32(dataType)),
+	}
+}
+
+// readingToSparkplugMetric converts the reading to the metric with the matching data type. Array readings are
+// sent as their string value.
+func readingToSparkplugMetric(reading dtos.BaseReading) (*sparkplugb.Payload_Metric, error) {
+	metric := &sparkplugb.Payload_Metric{Name: proto.String(sparkplugId(reading.ResourceName))}
+	if reading.Origin > 0 {
+		metric.Timestamp = proto.Uint64(sparkplugTimestamp(reading.Origin))
+	}
+
+	var dataType sparkplugb.DataType
+	var err error
+
+	// Sparkplug B sends the signed integer types as their two's complement in the unsigned value fields
+	switch reading.ValueType {
+	case common.ValueTypeBool:
+		dataType = sparkplugb.DataType_Boolean
+		var value bool
+		value, err = strconv.ParseBool(reading.Value)
+		metric.Value = &sparkplugb.Payload_Metric_BooleanValue{BooleanValue: value}
+	case common.ValueTypeInt8, common.ValueTypeInt16, common.ValueTypeInt32:
+		dataType = map[string]sparkplugb.DataType{common.ValueTypeInt8: sparkplugb.DataType_Int8, common.ValueTypeInt16: sparkplugb.DataType_Int16, common.ValueTypeInt32: sparkplugb.DataType_Int32}[reading.ValueType]
+		var value int64
+		value, err = strconv.ParseInt(reading.Value, 10, 32)
+		metric.Value = &sparkplugb.Payload_Metric_IntValue{IntValue: uint32(int32(value))}
+	case common.ValueTypeInt64:
+		dataType = sparkplugb.DataType_Int64
+		var value int64
+		value, err = strconv.ParseInt(reading.Value, 10, 64)
+		metric.Value = &sparkplugb.Payload_Metric_LongValue{LongValue: uint64(value)}
+	case common.ValueTypeUint8, common.ValueTypeUint16, common.ValueTypeUint32:
+		dataType = map[string]sparkplugb.DataType{common.ValueTypeUint8: sparkplugb.DataType_UInt8, common.ValueTypeUint16: sparkplugb.DataType_UInt16, common.ValueTypeUint32: sparkplugb.DataType_UInt32}[reading.ValueType]
+		var value uint64
+		value, err = strconv.ParseUint(reading.Value, 10, 32)
+		metric.Value = &sparkplugb.Payload_Metric_IntValue{IntValue: uint32(value)}
+	case common.ValueTypeUint64:
+		dataType = sparkplugb.DataType_UInt64
+		var value uint64
+		value, err = strconv.ParseUint(reading.Value, 10, 64)
+		metric.Value = &sparkplugb.Payload_Metric_LongValue{LongValue: value}
+	case common.ValueTypeFloat32:
+		dataType = sparkplugb.DataType_Float
+		var value float64
+		value, err = strconv.ParseFloat(reading.Value, 32)
+		metric.Value = &sparkplugb.Payload_Metric_FloatValue{FloatValue: float32(value)}
+	case common.ValueTypeFloat64:
+		dataType = sparkplugb.DataType_Double
+		var value float64
+		value, err = strconv.ParseFloat(reading.Value, 64)
+		metric.Value = &sparkplugb.Payload_Metric_DoubleValue{DoubleValue: value}
+	case common.ValueTypeBinary:
+		dataType = sparkplugb.DataType_Bytes
+		metric.Value = &sparkplugb.Payload_Metric_BytesValue{BytesValue: reading.BinaryValue}
+	default:
+		dataType = sparkplugb.DataType_String
+		metric.Value = &sparkplugb.Payload_Metric_StringValue{StringValue: reading.Value}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert '%s' reading value '%s' to %s: %s", reading.ResourceName, reading.Value, reading.ValueType, err.Error())
+	}
+
+	metric.Datatype = proto.Uint32(uint32(dataType))
+	return metric, nil
+}
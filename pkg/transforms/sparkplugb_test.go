@@ -0,0 +1,323 @@
+//go:build !no_sparkplugb
+// +build !no_sparkplugb
+
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"testing"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/sparkplugb"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSparkplugMessage is a Sparkplug B message published by the edge node
+type fakeSparkplugMessage struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  *sparkplugb.Payload
+}
+
+// fakeSparkplugBroker is an MQTT client which records the options of each session and decodes the messages published
+type fakeSparkplugBroker struct {
+	MQTT.Client
+	t         *testing.T
+	options   []*MQTT.ClientOptions
+	connected bool
+	handler   MQTT.MessageHandler
+	messages  []fakeSparkplugMessage
+	err       error
+}
+
+func (broker *fakeSparkplugBroker) newClient(_ interfaces.AppFunctionContext, opts *MQTT.ClientOptions) (MQTT.Client, error) {
+	broker.options = append(broker.options, opts)
+	return broker, nil
+}
+
+func (broker *fakeSparkplugBroker) IsConnected() bool {
+	return broker.connected
+}
+
+func (broker *fakeSparkplugBroker) Connect() MQTT.Token {
+	broker.connected = true
+	return &fakeToken{}
+}
+
+func (broker *fakeSparkplugBroker) Disconnect(_ uint) {
+	broker.connected = false
+}
+
+func (broker *fakeSparkplugBroker) Subscribe(_ string, _ byte, handler MQTT.MessageHandler) MQTT.Token {
+	broker.handler = handler
+	return &fakeToken{}
+}
+
+func (broker *fakeSparkplugBroker) Publish(topic string, qos byte, retained bool, payload interface{}) MQTT.Token {
+	if broker.err != nil {
+		return &fakeToken{err: broker.err}
+	}
+
+	decoded := decodeSparkplugTestPayload(broker.t, payload.([]byte))
+	broker.messages = append(broker.messages, fakeSparkplugMessage{topic: topic, qos: qos, retained: retained, payload: decoded})
+	return &fakeToken{}
+}
+
+func (broker *fakeSparkplugBroker) last() fakeSparkplugMessage {
+	require.NotEmpty(broker.t, broker.messages)
+	return broker.messages[len(broker.messages)-1]
+}
+
+func newSparkplugTestEvent(readings ...dtos.BaseReading) dtos.Event {
+	event := dtos.NewEvent("profile", "device/1", "source")
+	event.Readings = readings
+	return event
+}
+
+func newSparkplugTestReading(name string, valueType string, value string) dtos.BaseReading {
+	return dtos.BaseReading{
+		DeviceName:   "device/1",
+		ResourceName: name,
+		ProfileName:  "profile",
+		Origin:       1600000000000000000,
+		ValueType:    valueType,
+		SimpleReading: dtos.SimpleReading{
+			Value: value,
+		},
+	}
+}
+
+func newSparkplugTestTarget(t *testing.T) (*SparkplugB, *fakeSparkplugBroker) {
+	broker := &fakeSparkplugBroker{t: t}
+	target, err := NewSparkplugB(SparkplugBConfig{BrokerAddress: "tcp://broker:1883", ClientId: "edge", GroupId: "plant", EdgeNodeId: "edgex"})
+	require.NoError(t, err)
+	target.newClient = broker.newClient
+	return target, broker
+}
+
+func decodeSparkplugTestPayload(t *testing.T, data []byte) *sparkplugb.Payload {
+	payload := &sparkplugb.Payload{}
+	require.NoError(t, proto.Unmarshal(data, payload))
+	return payload
+}
+
+func encodeSparkplugTestCommand(t *testing.T, name string) []byte {
+	metric := newSparkplugMetric(name, sparkplugb.DataType_Boolean)
+	metric.Value = &sparkplugb.Payload_Metric_BooleanValue{BooleanValue: true}
+	payload, err := marshalSparkplugPayload([]*sparkplugb.Payload_Metric{metric}, nil)
+	require.NoError(t, err)
+	return payload
+}
+
+func metricNames(metrics []*sparkplugb.Payload_Metric) []string {
+	names := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		names = append(names, metric.GetName())
+	}
+	return names
+}
+
+func TestNewSparkplugB(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Config        SparkplugBConfig
+		ExpectedError string
+	}{
+		{"Valid", SparkplugBConfig{BrokerAddress: "tcp://broker:1883", GroupId: "plant", EdgeNodeId: "edgex"}, ""},
+		{"Missing broker", SparkplugBConfig{GroupId: "plant", EdgeNodeId: "edgex"}, "broker address"},
+		{"Missing group id", SparkplugBConfig{BrokerAddress: "tcp://broker:1883", EdgeNodeId: "edgex"}, "group id must be specified"},
+		{"Missing edge node id", SparkplugBConfig{BrokerAddress: "tcp://broker:1883", GroupId: "plant"}, "edge node id must be specified"},
+		{"Invalid group id", SparkplugBConfig{BrokerAddress: "tcp://broker:1883", GroupId: "plant/1", EdgeNodeId: "edgex"}, "must not contain"},
+		{"Invalid edge node id", SparkplugBConfig{BrokerAddress: "tcp://broker:1883", GroupId: "plant", EdgeNodeId: "edgex+"}, "must not contain"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := NewSparkplugB(test.Config)
+			if len(test.ExpectedError) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, defaultSparkplugConnectTimeout, target.config.ConnectTimeout)
+		})
+	}
+}
+
+func TestReadingToSparkplugMetric(t *testing.T) {
+	tests := []struct {
+		ValueType        string
+		Value            string
+		ExpectedDataType sparkplugb.DataType
+		ExpectedValue    interface{}
+	}{
+		{common.ValueTypeBool, "true", sparkplugb.DataType_Boolean, &sparkplugb.Payload_Metric_BooleanValue{BooleanValue: true}},
+		{common.ValueTypeInt8, "-1", sparkplugb.DataType_Int8, &sparkplugb.Payload_Metric_IntValue{IntValue: 4294967295}},
+		{common.ValueTypeInt32, "-2147483648", sparkplugb.DataType_Int32, &sparkplugb.Payload_Metric_IntValue{IntValue: 2147483648}},
+		{common.ValueTypeInt64, "-1", sparkplugb.DataType_Int64, &sparkplugb.Payload_Metric_LongValue{LongValue: 18446744073709551615}},
+		{common.ValueTypeUint16, "65535", sparkplugb.DataType_UInt16, &sparkplugb.Payload_Metric_IntValue{IntValue: 65535}},
+		{common.ValueTypeUint64, "18446744073709551615", sparkplugb.DataType_UInt64, &sparkplugb.Payload_Metric_LongValue{LongValue: 18446744073709551615}},
+		{common.ValueTypeFloat32, "1.5", sparkplugb.DataType_Float, &sparkplugb.Payload_Metric_FloatValue{FloatValue: 1.5}},
+		{common.ValueTypeFloat64, "-2.25", sparkplugb.DataType_Double, &sparkplugb.Payload_Metric_DoubleValue{DoubleValue: -2.25}},
+		{common.ValueTypeString, "text", sparkplugb.DataType_String, &sparkplugb.Payload_Metric_StringValue{StringValue: "text"}},
+		{common.ValueTypeInt32Array, "[1, 2]", sparkplugb.DataType_String, &sparkplugb.Payload_Metric_StringValue{StringValue: "[1, 2]"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ValueType, func(t *testing.T) {
+			metric, err := readingToSparkplugMetric(newSparkplugTestReading("resource/1", test.ValueType, test.Value))
+			require.NoError(t, err)
+			assert.Equal(t, "resource_1", metric.GetName())
+			assert.Equal(t, uint64(1600000000000), metric.GetTimestamp())
+			assert.Equal(t, uint32(test.ExpectedDataType), metric.GetDatatype())
+			assert.Equal(t, test.ExpectedValue, metric.GetValue())
+		})
+	}
+
+	_, err := readingToSparkplugMetric(newSparkplugTestReading("resource", common.ValueTypeInt8, "bogus"))
+	assert.Error(t, err)
+}
+
+func TestSparkplugBExportLifecycle(t *testing.T) {
+	target, broker := newSparkplugTestTarget(t)
+
+	temperature := newSparkplugTestReading("temperature", common.ValueTypeFloat64, "21.5")
+	humidity := newSparkplugTestReading("humidity", common.ValueTypeInt32, "40")
+
+	continuePipeline, result := target.Export(ctx, newSparkplugTestEvent(temperature))
+	require.True(t, continuePipeline, "unexpected error %v", result)
+
+	// The session starts with the NBIRTH, which has the will's bdSeq, followed by the device's DBIRTH
+	require.Len(t, broker.options, 1)
+	assert.Equal(t, "spBv1.0/plant/NDEATH/edgex", broker.options[0].WillTopic)
+	assert.False(t, broker.options[0].AutoReconnect)
+	will := decodeSparkplugTestPayload(t, broker.options[0].WillPayload)
+	require.Len(t, will.Metrics, 1)
+	assert.Equal(t, sparkplugBdSeqMetric, will.Metrics[0].GetName())
+	assert.Equal(t, uint32(sparkplugb.DataType_UInt64), will.Metrics[0].GetDatatype())
+	assert.Equal(t, uint64(1), will.Metrics[0].GetLongValue())
+	assert.Nil(t, will.Seq, "NDEATH must not have a sequence number")
+
+	require.Len(t, broker.messages, 2)
+	birth := broker.messages[0]
+	assert.Equal(t, "spBv1.0/plant/NBIRTH/edgex", birth.topic)
+	assert.Equal(t, byte(0), birth.qos)
+	assert.False(t, birth.retained)
+	assert.Equal(t, uint64(0), *birth.payload.Seq)
+	assert.Equal(t, []string{sparkplugBdSeqMetric, sparkplugRebirthMetric}, metricNames(birth.payload.Metrics))
+	assert.Equal(t, uint64(1), birth.payload.Metrics[0].GetLongValue())
+
+	deviceBirth := broker.messages[1]
+	assert.Equal(t, "spBv1.0/plant/DBIRTH/edgex/device_1", deviceBirth.topic)
+	assert.Equal(t, uint64(1), *deviceBirth.payload.Seq)
+	assert.Equal(t, []string{"temperature"}, metricNames(deviceBirth.payload.Metrics))
+	assert.Equal(t, 21.5, deviceBirth.payload.Metrics[0].GetDoubleValue())
+
+	continuePipeline, _ = target.Export(ctx, newSparkplugTestEvent(temperature))
+	require.True(t, continuePipeline)
+	assert.Equal(t, "spBv1.0/plant/DDATA/edgex/device_1", broker.last().topic)
+	assert.Equal(t, uint64(2), *broker.last().payload.Seq)
+
+	// A new metric requires the device to be born again with all its metrics
+	continuePipeline, _ = target.Export(ctx, newSparkplugTestEvent(humidity))
+	require.True(t, continuePipeline)
+	assert.Equal(t, "spBv1.0/plant/DBIRTH/edgex/device_1", broker.last().topic)
+	assert.Equal(t, []string{"humidity", "temperature"}, metricNames(broker.last().payload.Metrics))
+
+	// The sequence number wraps after 255
+	target.seq = 255
+	continuePipeline, _ = target.Export(ctx, newSparkplugTestEvent(humidity))
+	require.True(t, continuePipeline)
+	assert.Equal(t, uint64(0), *broker.last().payload.Seq)
+
+	// Losing the connection starts a new session with the next bdSeq and new births
+	broker.connected = false
+	continuePipeline, _ = target.Export(ctx, newSparkplugTestEvent(humidity))
+	require.True(t, continuePipeline)
+	require.Len(t, broker.options, 2)
+	will = decodeSparkplugTestPayload(t, broker.options[1].WillPayload)
+	assert.Equal(t, uint64(2), will.Metrics[0].GetLongValue())
+	messages := broker.messages[len(broker.messages)-2:]
+	assert.Equal(t, "spBv1.0/plant/NBIRTH/edgex", messages[0].topic)
+	assert.Equal(t, "spBv1.0/plant/DBIRTH/edgex/device_1", messages[1].topic)
+	assert.Equal(t, uint64(1), *messages[1].payload.Seq)
+}
+
+func TestSparkplugBRebirth(t *testing.T) {
+	target, broker := newSparkplugTestTarget(t)
+	event := newSparkplugTestEvent(newSparkplugTestReading("temperature", common.ValueTypeFloat64, "21.5"))
+
+	continuePipeline, _ := target.Export(ctx, event)
+	require.True(t, continuePipeline)
+	require.NotNil(t, broker.handler)
+
+	broker.handler(broker, &fakeMessage{payload: encodeSparkplugTestCommand(t, "other")})
+	assert.Len(t, broker.messages, 2)
+
+	broker.handler(broker, &fakeMessage{payload: []byte{0x12, 0x10, 0x01}})
+	assert.Len(t, broker.messages, 2, "invalid payload must be ignored")
+
+	broker.handler(broker, &fakeMessage{payload: encodeSparkplugTestCommand(t, sparkplugRebirthMetric)})
+	require.Len(t, broker.messages, 3)
+	assert.Equal(t, "spBv1.0/plant/NBIRTH/edgex", broker.last().topic)
+	assert.Equal(t, uint64(0), *broker.last().payload.Seq)
+
+	continuePipeline, _ = target.Export(ctx, event)
+	require.True(t, continuePipeline)
+	assert.Equal(t, "spBv1.0/plant/DBIRTH/edgex/device_1", broker.last().topic)
+	assert.Equal(t, uint64(1), *broker.last().payload.Seq)
+	assert.Len(t, broker.options, 1, "rebirth must not start a new session")
+}
+
+func TestSparkplugBExportErrors(t *testing.T) {
+	target, broker := newSparkplugTestTarget(t)
+
+	continuePipeline, result := target.Export(ctx, nil)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "no Event Received")
+
+	continuePipeline, result = target.Export(ctx, "bogus")
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "not an Event")
+
+	continuePipeline, result = target.Export(ctx, newSparkplugTestEvent(newSparkplugTestReading("count", common.ValueTypeUint8, "bogus")))
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "unable to convert 'count'")
+	assert.Empty(t, broker.options, "must not connect for an invalid Event")
+
+	continuePipeline, _ = target.Export(ctx, newSparkplugTestEvent(newSparkplugTestReading("count", common.ValueTypeUint8, "1")))
+	require.True(t, continuePipeline)
+
+	broker.err = errors.New("publish failed")
+	continuePipeline, result = target.Export(ctx, newSparkplugTestEvent(newSparkplugTestReading("count", common.ValueTypeUint8, "2")))
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "publish failed")
+	assert.Nil(t, target.client, "session must end when publish fails")
+	assert.False(t, broker.connected)
+}
@@ -0,0 +1,185 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+)
+
+// StreamJoin correlates Events from two sources, i.e. temperature and humidity readings from different devices,
+// whose Origin timestamps are within a time window of each other and combines each matched pair into a single Event.
+type StreamJoin struct {
+	sourceNames      [2]string
+	window           time.Duration
+	outputSourceName string
+	// pending holds the Events from each source that are waiting for a match from the other source
+	pending [2][]dtos.Event
+	mutex   sync.Mutex
+}
+
+// NewStreamJoin creates, initializes and returns a new instance of StreamJoin which joins the Events from the
+// specified left and right source names whose Origins are no more than window apart. The combined Events have
+// the specified output source name, which defaults to "<left>-<right>" when empty.
+func NewStreamJoin(leftSourceName string, rightSourceName string, window time.Duration, outputSourceName string) (*StreamJoin, error) {
+	if len(leftSourceName) == 0 || len(rightSourceName) == 0 {
+		return nil, errors.New("stream join source names must not be empty")
+	}
+
+	if leftSourceName == rightSourceName {
+		return nil, fmt.Errorf("stream join source names must be different, both are '%s'", leftSourceName)
+	}
+
+	if window <= 0 {
+		return nil, errors.New("stream join window must be greater than zero")
+	}
+
+	if len(outputSourceName) == 0 {
+		outputSourceName = leftSourceName + "-" + rightSourceName
+	}
+
+	return &StreamJoin{
+		sourceNames:      [2]string{leftSourceName, rightSourceName},
+		window:           window,
+		outputSourceName: outputSourceName,
+	}, nil
+}
+
+// Join matches the Event received with the pending Event from the other source whose Origin is closest to, and no
+// more than the window away from, its Origin. When a match is found the pipeline continues with a combined Event
+// which has the readings of the left Event followed by those of the right Event, the profile and device names of
+// the left Event, the output source name and the later of the two Origins. Tags from both Events are merged, with
+// those of the left Event taking precedence. Otherwise the Event is held until a match arrives and the pipeline
+// is stopped. Pending Events are discarded once an Event arrives whose Origin is more than the window after theirs.
+// Events from other sources are ignored and stop the pipeline.
+// This function will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
+func (join *StreamJoin) Join(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Join: no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("Join: type received is not an Event")
+	}
+
+	lc := ctx.LoggingClient()
+
+	side := -1
+	for index, sourceName := range join.sourceNames {
+		if event.SourceName == sourceName {
+			side = index
+		}
+	}
+
+	if side < 0 {
+		lc.Debugf("Event from source '%s' not joined. Pipeline execution terminating", event.SourceName)
+		ctx.SetFiltered(fmt.Sprintf("source '%s' not joined", event.SourceName))
+		return false, nil
+	}
+
+	other := 1 - side
+	window := join.window.Nanoseconds()
+
+	join.mutex.Lock()
+	defer join.mutex.Unlock()
+
+	join.evict(event.Origin - window)
+
+	match := -1
+	var closest int64
+	for index, pending := range join.pending[other] {
+		distance := pending.Origin - event.Origin
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance <= window && (match < 0 || distance < closest) {
+			match = index
+			closest = distance
+		}
+	}
+
+	if match < 0 {
+		lc.Debugf("Holding Event from source '%s' until matching Event from source '%s' is received",
+			event.SourceName, join.sourceNames[other])
+		join.pending[side] = append(join.pending[side], event)
+		return false, nil
+	}
+
+	matched := join.pending[other][match]
+	join.pending[other] = append(join.pending[other][:match], join.pending[other][match+1:]...)
+
+	left, right := event, matched
+	if side == 1 {
+		left, right = matched, event
+	}
+
+	lc.Debugf("Joined Events from sources '%s' and '%s' with Origins %dns apart",
+		join.sourceNames[0], join.sourceNames[1], closest)
+
+	combined := join.combine(left, right)
+
+	ctx.AddValue(interfaces.PROFILENAME, combined.ProfileName)
+	ctx.AddValue(interfaces.DEVICENAME, combined.DeviceName)
+	ctx.AddValue(interfaces.SOURCENAME, combined.SourceName)
+
+	return true, combined
+}
+
+// evict discards the pending Events with Origins before the specified Origin
+func (join *StreamJoin) evict(before int64) {
+	for side := range join.pending {
+		kept := join.pending[side][:0]
+		for _, pending := range join.pending[side] {
+			if pending.Origin >= before {
+				kept = append(kept, pending)
+			}
+		}
+		join.pending[side] = kept
+	}
+}
+
+func (join *StreamJoin) combine(left dtos.Event, right dtos.Event) dtos.Event {
+	combined := dtos.NewEvent(left.ProfileName, left.DeviceName, join.outputSourceName)
+
+	combined.Origin = left.Origin
+	if right.Origin > combined.Origin {
+		combined.Origin = right.Origin
+	}
+
+	combined.Readings = make([]dtos.BaseReading, 0, len(left.Readings)+len(right.Readings))
+	combined.Readings = append(combined.Readings, left.Readings...)
+	combined.Readings = append(combined.Readings, right.Readings...)
+
+	if len(left.Tags) > 0 || len(right.Tags) > 0 {
+		combined.Tags = make(map[string]string, len(left.Tags)+len(right.Tags))
+		for tag, value := range right.Tags {
+			combined.Tags[tag] = value
+		}
+		for tag, value := range left.Tags {
+			combined.Tags[tag] = value
+		}
+	}
+
+	return combined
+}
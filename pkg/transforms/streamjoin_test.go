@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamJoinTestEvent(deviceName string, sourceName string, origin int64, value string) dtos.Event {
+	event := dtos.NewEvent("profile-"+deviceName, deviceName, sourceName)
+	event.Origin = origin
+	_ = event.AddSimpleReading(sourceName, common.ValueTypeString, value)
+	return event
+}
+
+func TestNewStreamJoin(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Left        string
+		Right       string
+		Window      time.Duration
+		ExpectError bool
+	}{
+		{"Valid", "temperature", "humidity", time.Second, false},
+		{"Missing left", "", "humidity", time.Second, true},
+		{"Missing right", "temperature", "", time.Second, true},
+		{"Same sources", "temperature", "temperature", time.Second, true},
+		{"Zero window", "temperature", "humidity", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			join, err := NewStreamJoin(test.Left, test.Right, test.Window, "")
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "temperature-humidity", join.outputSourceName)
+		})
+	}
+}
+
+func TestStreamJoin_Join(t *testing.T) {
+	join, err := NewStreamJoin("temperature", "humidity", time.Second, "climate")
+	require.NoError(t, err)
+
+	base := time.Now().UnixNano()
+
+	humidity := newStreamJoinTestEvent("device2", "humidity", base, "4.500000e+01")
+	humidity.Tags = map[string]string{"location": "kitchen", "sensor": "humidity"}
+	continuePipeline, result := join.Join(ctx, humidity)
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+
+	temperature := newStreamJoinTestEvent("device1", "temperature", base+int64(200*time.Millisecond), "2.150000e+01")
+	temperature.Tags = map[string]string{"sensor": "temperature"}
+	continuePipeline, result = join.Join(ctx, temperature)
+	require.True(t, continuePipeline, result)
+
+	combined, ok := result.(dtos.Event)
+	require.True(t, ok)
+	assert.Equal(t, "profile-device1", combined.ProfileName)
+	assert.Equal(t, "device1", combined.DeviceName)
+	assert.Equal(t, "climate", combined.SourceName)
+	assert.Equal(t, temperature.Origin, combined.Origin)
+	require.Len(t, combined.Readings, 2)
+	assert.Equal(t, "temperature", combined.Readings[0].ResourceName)
+	assert.Equal(t, "humidity", combined.Readings[1].ResourceName)
+	assert.Equal(t, map[string]string{"location": "kitchen", "sensor": "temperature"}, combined.Tags)
+
+	sourceName, _ := ctx.GetValue(interfaces.SOURCENAME)
+	assert.Equal(t, "climate", sourceName)
+
+	// The matched humidity Event has been consumed
+	continuePipeline, _ = join.Join(ctx, newStreamJoinTestEvent("device1", "temperature", base+int64(300*time.Millisecond), "22"))
+	assert.False(t, continuePipeline)
+	assert.Len(t, join.pending[0], 1)
+	assert.Len(t, join.pending[1], 0)
+}
+
+func TestStreamJoin_JoinClosest(t *testing.T) {
+	join, err := NewStreamJoin("temperature", "humidity", time.Second, "")
+	require.NoError(t, err)
+
+	base := time.Now().UnixNano()
+
+	for _, offset := range []time.Duration{0, 600 * time.Millisecond} {
+		continuePipeline, _ := join.Join(ctx, newStreamJoinTestEvent("device1", "temperature", base+int64(offset), offset.String()))
+		require.False(t, continuePipeline)
+	}
+
+	continuePipeline, result := join.Join(ctx, newStreamJoinTestEvent("device2", "humidity", base+int64(500*time.Millisecond), "45"))
+	require.True(t, continuePipeline, result)
+	combined := result.(dtos.Event)
+	assert.Equal(t, "600ms", combined.Readings[0].Value)
+	assert.Equal(t, base+int64(600*time.Millisecond), combined.Origin)
+}
+
+func TestStreamJoin_JoinOutsideWindow(t *testing.T) {
+	join, err := NewStreamJoin("temperature", "humidity", time.Second, "")
+	require.NoError(t, err)
+
+	base := time.Now().UnixNano()
+
+	continuePipeline, _ := join.Join(ctx, newStreamJoinTestEvent("device1", "temperature", base, "21"))
+	require.False(t, continuePipeline)
+
+	// Too late to match, so the pending temperature Event is discarded and the humidity Event is held
+	continuePipeline, _ = join.Join(ctx, newStreamJoinTestEvent("device2", "humidity", base+int64(2*time.Second), "45"))
+	require.False(t, continuePipeline)
+	assert.Len(t, join.pending[0], 0)
+	assert.Len(t, join.pending[1], 1)
+}
+
+func TestStreamJoin_JoinOtherData(t *testing.T) {
+	join, err := NewStreamJoin("temperature", "humidity", time.Second, "")
+	require.NoError(t, err)
+
+	continuePipeline, result := join.Join(ctx, newStreamJoinTestEvent("device3", "pressure", time.Now().UnixNano(), "1013"))
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+	assert.Len(t, join.pending[0], 0)
+	assert.Len(t, join.pending[1], 0)
+
+	continuePipeline, result = join.Join(ctx, nil)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Join: no Event Received")
+
+	continuePipeline, result = join.Join(ctx, "not an event")
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Join: type received is not an Event")
+}
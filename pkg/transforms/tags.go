@@ -64,3 +64,31 @@ func (t *Tags) AddTags(ctx interfaces.AppFunctionContext, data interface{}) (boo
 
 	return true, event
 }
+
+// AddLatencyTags adds tags with the milliseconds the Event was queued before the functions pipeline started
+// processing it and has been processed for, so systems receiving the Event can compute the end-to-end latency
+func (t *Tags) AddLatencyTags(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	ctx.LoggingClient().Debug("Adding latency tags to Event")
+
+	if data == nil {
+		return false, errors.New("no Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("type received is not an Event")
+	}
+
+	queueTime, processingTime := latencyAnnotations(ctx)
+	if event.Tags == nil {
+		event.Tags = make(map[string]string)
+	}
+	if len(queueTime) > 0 {
+		event.Tags[QueueTimeTag] = queueTime
+	}
+	if len(processingTime) > 0 {
+		event.Tags[ProcessingTimeTag] = processingTime
+	}
+
+	return true, event
+}
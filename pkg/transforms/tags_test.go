@@ -17,10 +17,14 @@
 package transforms
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -90,3 +94,26 @@ func TestTags_AddTags(t *testing.T) {
 		})
 	}
 }
+
+func TestTags_AddLatencyTags(t *testing.T) {
+	ctx.AddValue(interfaces.QUEUETIME, "1500")
+	ctx.AddValue(interfaces.PIPELINESTART, strconv.FormatInt(time.Now().Add(-250*time.Millisecond).UnixNano(), 10))
+	defer ctx.RemoveValue(interfaces.QUEUETIME)
+	defer ctx.RemoveValue(interfaces.PIPELINESTART)
+
+	target := NewTags(nil)
+	continuePipeline, result := target.AddLatencyTags(ctx, eventWithExistingTags)
+	require.True(t, continuePipeline)
+
+	event, ok := result.(dtos.Event)
+	require.True(t, ok)
+	assert.Equal(t, "Value1", event.Tags["Tag1"])
+	assert.Equal(t, "1500", event.Tags[QueueTimeTag])
+	processingTime, err := strconv.ParseInt(event.Tags[ProcessingTimeTag], 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, processingTime, int64(250))
+
+	continuePipeline, result = target.AddLatencyTags(ctx, "Not an Event")
+	assert.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+}
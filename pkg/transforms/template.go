@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+)
+
+// TemplateData is the data passed to the template when it is rendered
+type TemplateData struct {
+	// Event is the Event received by the function. It is nil when the data received is not an Event.
+	Event *dtos.Event
+	// Data is the data received by the function. []byte and string data containing JSON is unmarshaled
+	// into generic maps and slices so its fields are accessible from the template.
+	Data interface{}
+	// Values is a copy of all the values stored in the context, i.e. {{ .Values.devicename }}
+	Values map[string]string
+}
+
+// Template houses the Go text/template used to render the data received
+type Template struct {
+	template *template.Template
+}
+
+// NewTemplate parses the specified Go text/template and returns a new instance of Template.
+// In addition to the standard template functions, 'json', 'lower' and 'upper' are available to the template.
+// An error is returned if the template text fails to parse.
+func NewTemplate(text string) (*Template, error) {
+	functions := template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+
+	parsed, err := template.New("template").Funcs(functions).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse template: %s", err.Error())
+	}
+
+	return &Template{
+		template: parsed,
+	}, nil
+}
+
+// Render renders the template against the data received, i.e. Event readings and tags, and the values stored
+// in the context. It returns the rendered text as a string so that it can be passed to an export function.
+// It will return an error and stop the pipeline if no data is received or the template fails to render.
+func (t *Template) Render(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("Render: no data received")
+	}
+
+	ctx.LoggingClient().Debug("Rendering template")
+
+	templateData := TemplateData{
+		Data:   data,
+		Values: ctx.GetAllValues(),
+	}
+
+	switch value := data.(type) {
+	case dtos.Event:
+		templateData.Event = &value
+	case []byte:
+		templateData.Data = unmarshalTemplateData(value)
+	case string:
+		templateData.Data = unmarshalTemplateData([]byte(value))
+	}
+
+	var buffer bytes.Buffer
+	if err := t.template.Execute(&buffer, templateData); err != nil {
+		return false, fmt.Errorf("unable to render template: %s", err.Error())
+	}
+
+	return true, buffer.String()
+}
+
+// unmarshalTemplateData returns the data unmarshaled from JSON or the data as a string if it isn't JSON
+func unmarshalTemplateData(data []byte) interface{} {
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return string(data)
+	}
+
+	return result
+}
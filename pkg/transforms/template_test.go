@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTemplate(t *testing.T) {
+	_, err := NewTemplate("{{ .Event.DeviceName }}")
+	assert.NoError(t, err)
+
+	_, err = NewTemplate("{{ .Event.DeviceName ")
+	assert.Error(t, err)
+}
+
+func TestTemplate_Render(t *testing.T) {
+	event := dtos.Event{
+		DeviceName: "device1",
+		Tags:       map[string]string{"GatewayId": "gateway1"},
+		Readings: []dtos.BaseReading{
+			{ResourceName: "temperature", SimpleReading: dtos.SimpleReading{Value: "72"}},
+			{ResourceName: "humidity", SimpleReading: dtos.SimpleReading{Value: "40"}},
+		},
+	}
+
+	ctx.AddValue("test", "foo")
+
+	tests := []struct {
+		Name          string
+		Template      string
+		Data          interface{}
+		Expected      string
+		ErrorExpected bool
+	}{
+		{"Event", `{"device":"{{ .Event.DeviceName }}","gateway":"{{ .Event.Tags.GatewayId }}","values":[{{ range $i, $r := .Event.Readings }}{{ if $i }},{{ end }}{{ $r.Value }}{{ end }}]}`,
+			event, `{"device":"device1","gateway":"gateway1","values":[72,40]}`, false},
+		{"Context Values", `<alert from="{{ .Values.test | upper }}"/>`, event, `<alert from="FOO"/>`, false},
+		{"JSON bytes", `{{ .Data.name }}`, []byte(`{"name":"bar"}`), "bar", false},
+		{"JSON string", `{{ json .Data.list }}`, `{"list":[1,2]}`, "[1,2]", false},
+		{"Non JSON string", `{{ .Data | lower }}`, "PLAIN TEXT", "plain text", false},
+		{"No data", `{{ .Data }}`, nil, "", true},
+		{"Missing key", `{{ .Values.missing }}`, event, "", true},
+		{"Non Event", `{{ .Event.DeviceName }}`, "not an event", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := NewTemplate(test.Template)
+			require.NoError(t, err)
+
+			continuePipeline, result := target.Render(ctx, test.Data)
+			if test.ErrorExpected {
+				require.False(t, continuePipeline)
+				require.Error(t, result.(error))
+				return
+			}
+
+			require.True(t, continuePipeline, result)
+			assert.Equal(t, test.Expected, result)
+		})
+	}
+}
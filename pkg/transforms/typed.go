@@ -0,0 +1,72 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+var (
+	contextType = reflect.TypeOf((*interfaces.AppFunctionContext)(nil)).Elem()
+	boolType    = reflect.TypeOf(true)
+	resultType  = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// Typed adapts a pipeline function taking the data as its expected type, i.e.
+//
+//	func(ctx interfaces.AppFunctionContext, event dtos.Event) (bool, interface{})
+//
+// to an interfaces.AppFunction. The returned function stops the pipeline with an error when no data is received or
+// the data isn't of the expected type, so the function itself doesn't need the type assertion and nil check. A pointer
+// to data of the expected type is dereferenced. An error is returned if the function doesn't have that signature.
+func Typed(function interface{}) (interfaces.AppFunction, error) {
+	if function == nil {
+		return nil, errors.New("Typed requires a func(interfaces.AppFunctionContext, T) (bool, interface{}), not nil")
+	}
+
+	value := reflect.ValueOf(function)
+	functionType := value.Type()
+	if functionType.Kind() != reflect.Func || functionType.NumIn() != 2 || functionType.NumOut() != 2 ||
+		functionType.IsVariadic() ||
+		functionType.In(0) != contextType ||
+		functionType.Out(0) != boolType || functionType.Out(1) != resultType {
+		return nil, fmt.Errorf("Typed requires a func(interfaces.AppFunctionContext, T) (bool, interface{}), not %s", functionType)
+	}
+
+	dataType := functionType.In(1)
+	return func(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		if data == nil {
+			return false, fmt.Errorf("no data received, expected %s", dataType)
+		}
+
+		dataValue := reflect.ValueOf(data)
+		if !dataValue.Type().AssignableTo(dataType) {
+			if dataValue.Kind() != reflect.Ptr || dataValue.IsNil() || !dataValue.Elem().Type().AssignableTo(dataType) {
+				return false, fmt.Errorf("unexpected type %T received, expected %s", data, dataType)
+			}
+			dataValue = dataValue.Elem()
+		}
+
+		// Via a pointer so the value has the interface type, even for a nil context
+		results := value.Call([]reflect.Value{reflect.ValueOf(&ctx).Elem(), dataValue})
+		return results[0].Bool(), results[1].Interface()
+	}, nil
+}
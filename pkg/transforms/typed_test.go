@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+)
+
+func deviceName(_ interfaces.AppFunctionContext, event dtos.Event) (bool, interface{}) {
+	return true, event.DeviceName
+}
+
+func TestTyped(t *testing.T) {
+	event := dtos.NewEvent("profile1", "dev1", "source1")
+	function, err := Typed(deviceName)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name          string
+		Data          interface{}
+		ExpectedError string
+	}{
+		{"Value", event, ""},
+		{"Pointer", &event, ""},
+		{"No Data", nil, "no data received, expected dtos.Event"},
+		{"Wrong Type", "dev1", "unexpected type string received, expected dtos.Event"},
+		{"Nil Pointer", (*dtos.Event)(nil), "unexpected type *dtos.Event received, expected dtos.Event"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := function(ctx, test.Data)
+			if test.ExpectedError != "" {
+				require.False(t, continuePipeline)
+				err, ok := result.(error)
+				require.True(t, ok)
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+
+			assert.True(t, continuePipeline)
+			assert.Equal(t, "dev1", result)
+		})
+	}
+}
+
+func TestTypedInterfaceData(t *testing.T) {
+	function, err := Typed(func(_ interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+		return false, data
+	})
+	require.NoError(t, err)
+
+	continuePipeline, result := function(ctx, 42)
+	assert.False(t, continuePipeline)
+	assert.Equal(t, 42, result)
+}
+
+func TestTypedNilResult(t *testing.T) {
+	function, err := Typed(func(_ interfaces.AppFunctionContext, data []byte) (bool, interface{}) {
+		return false, nil
+	})
+	require.NoError(t, err)
+
+	continuePipeline, result := function(nil, []byte("data"))
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+}
+
+func TestTypedInvalidSignature(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Function interface{}
+	}{
+		{"Nil", nil},
+		{"Not A Function", "deviceName"},
+		{"Missing Context", func(event dtos.Event) (bool, interface{}) { return true, nil }},
+		{"Wrong Result", func(_ interfaces.AppFunctionContext, event dtos.Event) (bool, string) { return true, "" }},
+		{"Variadic", func(_ interfaces.AppFunctionContext, events ...dtos.Event) (bool, interface{}) { return true, nil }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			function, err := Typed(test.Function)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "Typed requires a func(interfaces.AppFunctionContext, T) (bool, interface{})")
+			assert.Nil(t, function)
+		})
+	}
+}
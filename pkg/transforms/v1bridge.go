@@ -0,0 +1,369 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+const (
+	// V1ProfileNameTag is the tag of V1 Events keeping the device profile name of the V2 Event they were converted
+	// from, which V1 Events don't have, so the name is restored when converting back to V2
+	V1ProfileNameTag = "v2ProfileName"
+	// V1SourceNameTag is the tag of V1 Events keeping the source name of the V2 Event they were converted from
+	V1SourceNameTag = "v2SourceName"
+
+	// V1FloatEncodingENotation is the V1 float encoding of values in E notation, as all V2 float values are
+	V1FloatEncodingENotation = "eNotation"
+	// V1FloatEncodingBase64 is the V1 float encoding of values which are the base64 encoded big endian binary value
+	V1FloatEncodingBase64 = "Base64"
+
+	v1EventPath = "/api/v1/event"
+)
+
+// V1BridgeConfig contains the settings for converting Events between the V1 and V2 models and exporting V1 Events
+type V1BridgeConfig struct {
+	// ProfileNames maps device names to the device profile name of their Events converted from V1, which V1 Events
+	// don't have. Not needed for V1 Events converted from V2, which keep the name in the V1ProfileNameTag tag.
+	ProfileNames map[string]string
+	// DefaultProfileName is the device profile name of Events converted from V1 whose device isn't in the
+	// ProfileNames. Defaults to the device name.
+	DefaultProfileName string
+	// SourceName is the source name of Events converted from V1, which V1 Events don't have. Defaults to the name of
+	// the first reading, as for Events of a single resource.
+	SourceName string
+	// URL is the base URL of the V1 Core Data service the V1 Events are exported to, i.e. http://edgex-core-data:48080
+	URL string
+	// PersistOnError enables use of store & forward loop if true
+	PersistOnError bool
+	// HTTPHeaderName to use for passing configured secret
+	HTTPHeaderName string
+	// SecretPath to search for configured secret
+	SecretPath string
+	// SecretName for configured secret
+	SecretName string
+}
+
+// V1Bridge converts Events between the EdgeX V1 and V2 models, so processed V2 Events can be republished on the
+// topics and endpoints of V1 services, and V1 Events processed by V2 pipelines, while a fleet is migrated
+type V1Bridge struct {
+	config V1BridgeConfig
+	sender HTTPSender
+}
+
+// v1Event is the EdgeX V1 Event model
+type v1Event struct {
+	ID       string            `json:"id,omitempty"`
+	Pushed   int64             `json:"pushed,omitempty"`
+	Device   string            `json:"device,omitempty"`
+	Created  int64             `json:"created,omitempty"`
+	Modified int64             `json:"modified,omitempty"`
+	Origin   int64             `json:"origin,omitempty"`
+	Readings []v1Reading       `json:"readings,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// v1Reading is the EdgeX V1 Reading model
+type v1Reading struct {
+	ID            string `json:"id,omitempty"`
+	Pushed        int64  `json:"pushed,omitempty"`
+	Created       int64  `json:"created,omitempty"`
+	Origin        int64  `json:"origin,omitempty"`
+	Modified      int64  `json:"modified,omitempty"`
+	Device        string `json:"device,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Value         string `json:"value,omitempty"`
+	ValueType     string `json:"valueType,omitempty"`
+	FloatEncoding string `json:"floatEncoding,omitempty"`
+	BinaryValue   []byte `json:"binaryValue,omitempty"`
+	MediaType     string `json:"mediaType,omitempty"`
+}
+
+// NewV1Bridge creates, initializes and returns a new instance of V1Bridge
+func NewV1Bridge(config V1BridgeConfig) *V1Bridge {
+	return &V1Bridge{
+		config: config,
+		sender: NewHTTPSenderWithOptions(HTTPSenderOptions{
+			URL:            strings.TrimSuffix(config.URL, "/") + v1EventPath,
+			MimeType:       common.ContentTypeJSON,
+			PersistOnError: config.PersistOnError,
+			HTTPHeaderName: config.HTTPHeaderName,
+			SecretPath:     config.SecretPath,
+			SecretName:     config.SecretName,
+			// The URL is static so its placeholders, if any, are not replaced
+			URLFormatter: func(format string, _ interfaces.AppFunctionContext, _ interface{}) (string, error) {
+				return format, nil
+			},
+		}),
+	}
+}
+
+// ConvertToV1 converts the V2 Event received to a V1 Event in JSON. The Event's profile and source names are kept in
+// the V1ProfileNameTag and V1SourceNameTag tags.
+// It will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
+func (bridge *V1Bridge) ConvertToV1(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("ConvertToV1: No Event Received")
+	}
+
+	event, ok := data.(dtos.Event)
+	if !ok {
+		return false, errors.New("ConvertToV1: type received is not an Event")
+	}
+
+	result, err := json.Marshal(toV1Event(event))
+	if err != nil {
+		return false, fmt.Errorf("ConvertToV1: unable to marshal V1 Event: %s", err.Error())
+	}
+
+	ctx.LoggingClient().Debugf("Converted Event '%s' to V1", event.Id)
+	ctx.SetResponseContentType(common.ContentTypeJSON)
+	return true, result
+}
+
+// ConvertFromV1 converts the V1 Event received in JSON to a V2 Event, which is passed to the next function. The
+// profile and source names, which V1 Events don't have, are taken from the V1ProfileNameTag and V1SourceNameTag tags
+// when present, otherwise from the configuration.
+// It will return an error and stop the pipeline if no data is received or if the data is not a valid V1 Event.
+func (bridge *V1Bridge) ConvertFromV1(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("ConvertFromV1: No Event Received")
+	}
+
+	payload, err := util.CoerceType(data)
+	if err != nil {
+		return false, fmt.Errorf("ConvertFromV1: %s", err.Error())
+	}
+
+	var v1 v1Event
+	if err := json.Unmarshal(payload, &v1); err != nil {
+		return false, fmt.Errorf("ConvertFromV1: data received is not a V1 Event: %s", err.Error())
+	}
+
+	event, err := bridge.toV2Event(v1)
+	if err != nil {
+		return false, fmt.Errorf("ConvertFromV1: %s", err.Error())
+	}
+
+	ctx.LoggingClient().Debugf("Converted V1 Event '%s' of device '%s'", v1.ID, v1.Device)
+	return true, event
+}
+
+// ExportToV1 sends the V1 Event to the V1 Core Data service at the URL. The data received is either a V2 Event, which
+// is converted to V1, or a V1 Event in JSON, i.e. from ConvertToV1.
+// It will return an error and stop the pipeline if no data is received, if the data can not be converted or if
+// Core Data can not be reached or responds with a non 2xx status.
+func (bridge *V1Bridge) ExportToV1(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		return false, errors.New("ExportToV1: No Data Received")
+	}
+
+	if event, ok := data.(dtos.Event); ok {
+		continuePipeline, result := bridge.ConvertToV1(ctx, event)
+		if !continuePipeline {
+			return false, result
+		}
+		data = result
+	}
+
+	return bridge.sender.HTTPPost(ctx, data)
+}
+
+func toV1Event(event dtos.Event) v1Event {
+	v1 := v1Event{
+		ID:     event.Id,
+		Device: event.DeviceName,
+		Origin: event.Origin,
+		Tags:   make(map[string]string, len(event.Tags)+2),
+	}
+
+	for key, value := range event.Tags {
+		v1.Tags[key] = value
+	}
+	v1.Tags[V1ProfileNameTag] = event.ProfileName
+	v1.Tags[V1SourceNameTag] = event.SourceName
+
+	for _, reading := range event.Readings {
+		v1Reading := v1Reading{
+			ID:        reading.Id,
+			Origin:    reading.Origin,
+			Device:    reading.DeviceName,
+			Name:      reading.ResourceName,
+			ValueType: reading.ValueType,
+		}
+
+		if reading.ValueType == common.ValueTypeBinary {
+			v1Reading.BinaryValue = reading.BinaryValue
+			v1Reading.MediaType = reading.MediaType
+		} else {
+			v1Reading.Value = reading.Value
+		}
+
+		if reading.ValueType == common.ValueTypeFloat32 || reading.ValueType == common.ValueTypeFloat64 {
+			v1Reading.FloatEncoding = V1FloatEncodingENotation
+		}
+
+		v1.Readings = append(v1.Readings, v1Reading)
+	}
+
+	return v1
+}
+
+func (bridge *V1Bridge) toV2Event(v1 v1Event) (dtos.Event, error) {
+	if len(v1.Device) == 0 {
+		return dtos.Event{}, errors.New("V1 Event has no device")
+	}
+	if len(v1.Readings) == 0 {
+		return dtos.Event{}, errors.New("V1 Event has no readings")
+	}
+
+	profileName := v1.Tags[V1ProfileNameTag]
+	if len(profileName) == 0 {
+		profileName = bridge.config.ProfileNames[v1.Device]
+	}
+	if len(profileName) == 0 {
+		profileName = bridge.config.DefaultProfileName
+	}
+	if len(profileName) == 0 {
+		profileName = v1.Device
+	}
+
+	sourceName := v1.Tags[V1SourceNameTag]
+	if len(sourceName) == 0 {
+		sourceName = bridge.config.SourceName
+	}
+	if len(sourceName) == 0 {
+		sourceName = v1.Readings[0].Name
+	}
+
+	event := dtos.NewEvent(profileName, v1.Device, sourceName)
+	if _, err := uuid.Parse(v1.ID); err == nil {
+		event.Id = v1.ID
+	}
+
+	event.Origin = v1Origin(v1.Origin, v1.Created)
+
+	for key, value := range v1.Tags {
+		if key == V1ProfileNameTag || key == V1SourceNameTag {
+			continue
+		}
+		if event.Tags == nil {
+			event.Tags = make(map[string]string)
+		}
+		event.Tags[key] = value
+	}
+
+	for _, v1Reading := range v1.Readings {
+		reading, err := toV2Reading(v1Reading, event)
+		if err != nil {
+			return dtos.Event{}, err
+		}
+		event.Readings = append(event.Readings, reading)
+	}
+
+	return event, nil
+}
+
+func toV2Reading(v1 v1Reading, event dtos.Event) (dtos.BaseReading, error) {
+	if len(v1.Name) == 0 {
+		return dtos.BaseReading{}, errors.New("V1 Reading has no name")
+	}
+
+	deviceName := v1.Device
+	if len(deviceName) == 0 {
+		deviceName = event.DeviceName
+	}
+
+	valueType := v1.ValueType
+	if len(valueType) == 0 {
+		valueType = common.ValueTypeString
+	}
+
+	reading := dtos.BaseReading{
+		Id:           v1.ID,
+		Origin:       v1Origin(v1.Origin, v1.Created),
+		DeviceName:   deviceName,
+		ResourceName: v1.Name,
+		ProfileName:  event.ProfileName,
+		ValueType:    valueType,
+	}
+	if _, err := uuid.Parse(reading.Id); err != nil {
+		reading.Id = uuid.NewString()
+	}
+
+	if valueType == common.ValueTypeBinary {
+		reading.BinaryValue = v1.BinaryValue
+		reading.MediaType = v1.MediaType
+		return reading, nil
+	}
+
+	reading.Value = v1.Value
+	if v1.FloatEncoding == V1FloatEncodingBase64 {
+		value, err := decodeV1Base64Float(v1.Value, valueType)
+		if err != nil {
+			return dtos.BaseReading{}, fmt.Errorf("unable to decode value of V1 Reading '%s': %s", v1.Name, err.Error())
+		}
+		reading.Value = value
+	}
+
+	return reading, nil
+}
+
+// decodeV1Base64Float converts the base64 encoded big endian float value to E notation, as V2 float values are
+func decodeV1Base64Float(value string, valueType string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case valueType == common.ValueTypeFloat32 && len(data) == 4:
+		return strconv.FormatFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(data))), 'e', -1, 32), nil
+	case valueType == common.ValueTypeFloat64 && len(data) == 8:
+		return strconv.FormatFloat(math.Float64frombits(binary.BigEndian.Uint64(data)), 'e', -1, 64), nil
+	default:
+		return "", fmt.Errorf("%d bytes is not a base64 encoded %s value", len(data), valueType)
+	}
+}
+
+// v1Origin returns the origin, which V1 services may not have set, otherwise the created time in milliseconds or the
+// current time, in nanoseconds
+func v1Origin(origin int64, created int64) int64 {
+	switch {
+	case origin > 0:
+		return origin
+	case created > 0:
+		return created * int64(time.Millisecond)
+	default:
+		return time.Now().UnixNano()
+	}
+}
@@ -0,0 +1,199 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testV1EventId   = "d2a8e8ae-1a3e-4f4b-8b1a-5c1e6f3a2b10"
+	testV1ReadingId = "7f6e5d4c-3b2a-4190-8f7e-6d5c4b3a2910"
+)
+
+func newV1BridgeTestEvent() dtos.Event {
+	event := dtos.NewEvent("thermostat", "room-1", "status")
+	event.Id = testV1EventId
+	event.Origin = 1600000000000000000
+	event.Tags = map[string]string{"site": "plant-1"}
+	event.Readings = []dtos.BaseReading{
+		{
+			Id:            testV1ReadingId,
+			Origin:        1600000000000000000,
+			DeviceName:    "room-1",
+			ResourceName:  "temperature",
+			ProfileName:   "thermostat",
+			ValueType:     common.ValueTypeFloat64,
+			SimpleReading: dtos.SimpleReading{Value: "2.15e+01"},
+		},
+		{
+			Id:            testV1ReadingId,
+			Origin:        1600000000000000000,
+			DeviceName:    "room-1",
+			ResourceName:  "snapshot",
+			ProfileName:   "thermostat",
+			ValueType:     common.ValueTypeBinary,
+			BinaryReading: dtos.BinaryReading{BinaryValue: []byte{1, 2, 3}, MediaType: "image/jpeg"},
+		},
+	}
+	return event
+}
+
+func TestV1BridgeConvertToV1(t *testing.T) {
+	expected := `{
+		"id": "d2a8e8ae-1a3e-4f4b-8b1a-5c1e6f3a2b10",
+		"device": "room-1",
+		"origin": 1600000000000000000,
+		"tags": {"site": "plant-1", "v2ProfileName": "thermostat", "v2SourceName": "status"},
+		"readings": [
+			{"id": "7f6e5d4c-3b2a-4190-8f7e-6d5c4b3a2910", "origin": 1600000000000000000, "device": "room-1", "name": "temperature", "value": "2.15e+01", "valueType": "Float64", "floatEncoding": "eNotation"},
+			{"id": "7f6e5d4c-3b2a-4190-8f7e-6d5c4b3a2910", "origin": 1600000000000000000, "device": "room-1", "name": "snapshot", "valueType": "Binary", "binaryValue": "AQID", "mediaType": "image/jpeg"}
+		]
+	}`
+
+	ctx.SetResponseContentType("")
+	continuePipeline, result := NewV1Bridge(V1BridgeConfig{}).ConvertToV1(ctx, newV1BridgeTestEvent())
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.JSONEq(t, expected, string(result.([]byte)))
+	assert.Equal(t, common.ContentTypeJSON, ctx.ResponseContentType())
+	ctx.SetResponseContentType("")
+
+	continuePipeline, result = NewV1Bridge(V1BridgeConfig{}).ConvertToV1(ctx, nil)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "No Event Received")
+
+	continuePipeline, result = NewV1Bridge(V1BridgeConfig{}).ConvertToV1(ctx, "bogus")
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "not an Event")
+}
+
+func TestV1BridgeRoundTrip(t *testing.T) {
+	target := NewV1Bridge(V1BridgeConfig{DefaultProfileName: "other"})
+	event := newV1BridgeTestEvent()
+
+	continuePipeline, v1 := target.ConvertToV1(ctx, event)
+	require.True(t, continuePipeline, "unexpected error %v", v1)
+	ctx.SetResponseContentType("")
+
+	continuePipeline, result := target.ConvertFromV1(ctx, v1)
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Equal(t, event, result)
+}
+
+func TestV1BridgeConvertFromV1(t *testing.T) {
+	v1 := `{
+		"id": "not-a-uuid",
+		"device": "boiler-1",
+		"created": 1600000000000,
+		"tags": {"site": "plant-2"},
+		"readings": [
+			{"name": "pressure", "value": "QEkP2w==", "valueType": "Float32", "floatEncoding": "Base64"},
+			{"name": "state", "value": "on"}
+		]
+	}`
+
+	tests := []struct {
+		Name                string
+		Config              V1BridgeConfig
+		ExpectedProfileName string
+		ExpectedSourceName  string
+	}{
+		{"Defaults", V1BridgeConfig{}, "boiler-1", "pressure"},
+		{"Default profile name", V1BridgeConfig{DefaultProfileName: "boiler", SourceName: "all"}, "boiler", "all"},
+		{"Mapped profile name", V1BridgeConfig{ProfileNames: map[string]string{"boiler-1": "boiler-profile"}, DefaultProfileName: "boiler"}, "boiler-profile", "pressure"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := NewV1Bridge(test.Config).ConvertFromV1(ctx, []byte(v1))
+			require.True(t, continuePipeline, "unexpected error %v", result)
+
+			event := result.(dtos.Event)
+			assert.Equal(t, "boiler-1", event.DeviceName)
+			assert.Equal(t, test.ExpectedProfileName, event.ProfileName)
+			assert.Equal(t, test.ExpectedSourceName, event.SourceName)
+			assert.NotEqual(t, "not-a-uuid", event.Id)
+			assert.Equal(t, int64(1600000000000000000), event.Origin)
+			assert.Equal(t, map[string]string{"site": "plant-2"}, event.Tags)
+
+			require.Len(t, event.Readings, 2)
+			assert.Equal(t, "3.1415927e+00", event.Readings[0].Value)
+			assert.Equal(t, common.ValueTypeFloat32, event.Readings[0].ValueType)
+			assert.Equal(t, test.ExpectedProfileName, event.Readings[0].ProfileName)
+			assert.Equal(t, "boiler-1", event.Readings[0].DeviceName)
+			assert.Equal(t, "on", event.Readings[1].Value)
+			assert.Equal(t, common.ValueTypeString, event.Readings[1].ValueType)
+		})
+	}
+}
+
+func TestV1BridgeConvertFromV1Errors(t *testing.T) {
+	target := NewV1Bridge(V1BridgeConfig{})
+
+	tests := []struct {
+		Name          string
+		Data          interface{}
+		ExpectedError string
+	}{
+		{"No data", nil, "No Event Received"},
+		{"Not JSON", "bogus", "not a V1 Event"},
+		{"V2 Event", newV1BridgeTestEvent(), "no device"},
+		{"No readings", `{"device": "boiler-1"}`, "no readings"},
+		{"Reading without name", `{"device": "boiler-1", "readings": [{"value": "1"}]}`, "no name"},
+		{"Invalid base64 float", `{"device": "boiler-1", "readings": [{"name": "pressure", "value": "AQID", "valueType": "Float32", "floatEncoding": "Base64"}]}`, "unable to decode value"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := target.ConvertFromV1(ctx, test.Data)
+			require.False(t, continuePipeline)
+			assert.Contains(t, result.(error).Error(), test.ExpectedError)
+		})
+	}
+}
+
+func TestV1BridgeExportToV1(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/api/v1/event", request.URL.Path)
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &received))
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	target := NewV1Bridge(V1BridgeConfig{URL: server.URL + "/"})
+	continuePipeline, result := target.ExportToV1(ctx, newV1BridgeTestEvent())
+	require.True(t, continuePipeline, "unexpected error %v", result)
+	assert.Equal(t, "room-1", received["device"])
+	ctx.SetResponseContentType("")
+
+	continuePipeline, result = target.ExportToV1(ctx, nil)
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "No Data Received")
+}
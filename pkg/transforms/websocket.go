@@ -0,0 +1,247 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/gorilla/websocket"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/pkg/util"
+)
+
+const defaultWebSocketTimeout = 10 * time.Second
+
+// WebSocketConfig contains the settings for sending data to a remote WebSocket endpoint
+type WebSocketConfig struct {
+	// URL of the endpoint, i.e. ws://dashboard:8080/ingest or wss://dashboard:8443/ingest
+	URL string
+	// HeaderName is the handshake request header set to the secret value, i.e. Authorization
+	HeaderName string
+	// SecretPath to search for the secret set as the HeaderName header
+	SecretPath string
+	// SecretName of the secret set as the HeaderName header
+	SecretName string
+	// SkipCertVerify indicates whether to skip verification of the endpoint's TLS certificate
+	SkipCertVerify bool
+	// Timeout is the duration to wait for the connection and for each message to be sent. Defaults to 10s.
+	Timeout string
+	// PersistOnError enables use of store & forward loop if true
+	PersistOnError bool
+}
+
+// WebSocketSender sends data to a remote WebSocket endpoint as one message each, text messages for UTF-8 data and
+// binary messages otherwise. The connection is kept open between messages and re-established after failures.
+type WebSocketSender struct {
+	config  WebSocketConfig
+	timeout time.Duration
+	dialer  *websocket.Dialer
+	lock    sync.Mutex
+	conn    *websocket.Conn
+	// secretsLastRetrieved is when the secret header of the current connection was retrieved, so the connection is
+	// re-established with the new secret when the secrets are updated
+	secretsLastRetrieved time.Time
+}
+
+// NewWebSocketSender creates, initializes and returns a new instance of WebSocketSender
+func NewWebSocketSender(config WebSocketConfig) (*WebSocketSender, error) {
+	if len(config.URL) == 0 {
+		return nil, errors.New("WebSocket URL must be specified")
+	}
+
+	endpoint, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("WebSocket URL is invalid: %s", err.Error())
+	}
+
+	if endpoint.Scheme != "ws" && endpoint.Scheme != "wss" {
+		return nil, fmt.Errorf("WebSocket URL scheme must be 'ws' or 'wss', not '%s'", endpoint.Scheme)
+	}
+
+	usingSecret := len(config.HeaderName) > 0 || len(config.SecretPath) > 0 || len(config.SecretName) > 0
+	if usingSecret && (len(config.HeaderName) == 0 || len(config.SecretPath) == 0 || len(config.SecretName) == 0) {
+		return nil, errors.New("WebSocket HeaderName, SecretPath and SecretName must all be specified to use a secret header")
+	}
+
+	timeout := defaultWebSocketTimeout
+	if len(config.Timeout) > 0 {
+		timeout, err = time.ParseDuration(config.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("WebSocket Timeout '%s' is invalid", config.Timeout)
+		}
+	}
+
+	return &WebSocketSender{
+		config:  config,
+		timeout: timeout,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: timeout,
+			TLSClientConfig:  &tls.Config{InsecureSkipVerify: config.SkipCertVerify},
+		},
+	}, nil
+}
+
+// Send sends the data from the previous function to the WebSocket endpoint. If no previous function exists, then the
+// event that triggered the pipeline will be used.
+func (sender *WebSocketSender) Send(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+
+	exportData, err := util.CoerceType(data)
+	if err != nil {
+		return false, err
+	}
+
+	sender.lock.Lock()
+	defer sender.lock.Unlock()
+
+	if sender.conn != nil && len(sender.config.SecretPath) > 0 && sender.secretsLastRetrieved.Before(ctx.SecretsLastUpdated()) {
+		ctx.LoggingClient().Info("Secrets have been updated, reconnecting to the WebSocket endpoint")
+		sender.disconnect()
+	}
+
+	if sender.conn == nil {
+		if err := sender.connect(ctx); err != nil {
+			sender.setRetryData(ctx, exportData)
+			return false, err
+		}
+	}
+
+	messageType := websocket.TextMessage
+	if !utf8.Valid(exportData) {
+		messageType = websocket.BinaryMessage
+	}
+
+	_ = sender.conn.SetWriteDeadline(time.Now().Add(sender.timeout))
+	if err := sender.conn.WriteMessage(messageType, exportData); err != nil {
+		sender.disconnect()
+		sender.setRetryData(ctx, exportData)
+		return false, fmt.Errorf("failed to send data to WebSocket endpoint %s: %w", sender.config.URL, err)
+	}
+
+	recordProcessingTime(ctx)
+
+	ctx.LoggingClient().Debugf("Sent %d bytes of data to WebSocket endpoint %s", len(exportData), sender.config.URL)
+	ctx.LoggingClient().Trace("Data exported", "Transport", "WebSocket", common.CorrelationHeader, ctx.CorrelationID())
+
+	return true, nil
+}
+
+// connect establishes the connection to the endpoint. The lock must be held.
+func (sender *WebSocketSender) connect(ctx interfaces.AppFunctionContext) error {
+	header := http.Header{}
+	if len(sender.config.SecretPath) > 0 {
+		secrets, err := ctx.GetSecret(sender.config.SecretPath, sender.config.SecretName)
+		if err != nil {
+			return err
+		}
+		header.Set(sender.config.HeaderName, secrets[sender.config.SecretName])
+		sender.secretsLastRetrieved = time.Now()
+	}
+
+	conn, response, err := sender.dialer.Dial(sender.config.URL, header)
+	if err != nil {
+		if response != nil {
+			return fmt.Errorf("failed to connect to WebSocket endpoint %s: %w (%d HTTP status code)", sender.config.URL, err, response.StatusCode)
+		}
+		return fmt.Errorf("failed to connect to WebSocket endpoint %s: %w", sender.config.URL, err)
+	}
+
+	ctx.LoggingClient().Infof("Connected to WebSocket endpoint %s", sender.config.URL)
+	sender.conn = conn
+
+	go sender.discardReceived(conn)
+	return nil
+}
+
+// discardReceived reads the messages sent by the endpoint, which are discarded, so its control messages are handled.
+// The connection is closed once reading fails, i.e. the endpoint closed the connection.
+func (sender *WebSocketSender) discardReceived(conn *websocket.Conn) {
+	for {
+		if _, _, err := conn.NextReader(); err != nil {
+			sender.lock.Lock()
+			if sender.conn == conn {
+				sender.disconnect()
+			}
+			sender.lock.Unlock()
+			return
+		}
+	}
+}
+
+// disconnect closes the current connection. The lock must be held.
+func (sender *WebSocketSender) disconnect() {
+	if sender.conn == nil {
+		return
+	}
+
+	_ = sender.conn.Close()
+	sender.conn = nil
+}
+
+func (sender *WebSocketSender) setRetryData(ctx interfaces.AppFunctionContext, exportData []byte) {
+	if sender.config.PersistOnError {
+		ctx.SetRetryData(exportData)
+	}
+}
+
+// StreamSender publishes data to the WebSocket clients connected to the service's /stream endpoint, so local
+// dashboards can live-stream the pipeline's output. See the Stream configuration.
+type StreamSender struct {
+}
+
+// NewStreamSender creates, initializes and returns a new instance of StreamSender
+func NewStreamSender() StreamSender {
+	return StreamSender{}
+}
+
+// Publish publishes the data from the previous function to the connected clients and passes the data on, so the
+// pipeline can also export it elsewhere. If no previous function exists, then the event that triggered the pipeline
+// will be used. Clients which aren't keeping up miss the data rather than holding up the pipeline.
+func (sender StreamSender) Publish(ctx interfaces.AppFunctionContext, data interface{}) (bool, interface{}) {
+	if data == nil {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+
+	stream := ctx.Stream()
+	if stream == nil {
+		return false, errors.New("stream is not enabled. Stream.Enabled must be set to publish to stream clients")
+	}
+
+	streamData, err := util.CoerceType(data)
+	if err != nil {
+		return false, err
+	}
+
+	clients := stream.Publish(streamData)
+	ctx.LoggingClient().Debugf("Published %d bytes of data to %d stream clients", len(streamData), clients)
+
+	return true, data
+}
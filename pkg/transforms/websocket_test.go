@@ -0,0 +1,208 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/appfunction"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/bootstrap/container"
+	"github.com/edgexfoundry/app-functions-sdk-go/v2/internal/stream"
+)
+
+type receivedWebSocketMessage struct {
+	messageType int
+	data        []byte
+}
+
+// newWebSocketTestServer starts a WebSocket endpoint which sends the messages it receives to the returned channel
+func newWebSocketTestServer(t *testing.T) (*httptest.Server, chan receivedWebSocketMessage) {
+	received := make(chan receivedWebSocketMessage, 10)
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		conn, err := upgrader.Upgrade(writer, request, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- receivedWebSocketMessage{messageType: messageType, data: data}
+		}
+	}))
+
+	return server, received
+}
+
+func webSocketURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestNewWebSocketSender(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Config      WebSocketConfig
+		ExpectError bool
+	}{
+		{"Valid", WebSocketConfig{URL: "ws://dashboard:8080/ingest"}, false},
+		{"Valid with secret header", WebSocketConfig{URL: "wss://dashboard:8443/ingest", HeaderName: "Authorization", SecretPath: "dashboard", SecretName: "token", Timeout: "5s"}, false},
+		{"Missing URL", WebSocketConfig{}, true},
+		{"Invalid URL", WebSocketConfig{URL: "ws://dash board:8080\n"}, true},
+		{"Invalid scheme", WebSocketConfig{URL: "http://dashboard:8080/ingest"}, true},
+		{"Missing header name", WebSocketConfig{URL: "ws://dashboard:8080/ingest", SecretPath: "dashboard", SecretName: "token"}, true},
+		{"Missing secret path", WebSocketConfig{URL: "ws://dashboard:8080/ingest", HeaderName: "Authorization", SecretName: "token"}, true},
+		{"Invalid timeout", WebSocketConfig{URL: "ws://dashboard:8080/ingest", Timeout: "bogus"}, true},
+		{"Negative timeout", WebSocketConfig{URL: "ws://dashboard:8080/ingest", Timeout: "-1s"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			sender, err := NewWebSocketSender(test.Config)
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, sender)
+		})
+	}
+}
+
+func TestWebSocketSender_Send(t *testing.T) {
+	server, received := newWebSocketTestServer(t)
+	defer server.Close()
+
+	sender, err := NewWebSocketSender(WebSocketConfig{URL: webSocketURL(server)})
+	require.NoError(t, err)
+
+	continuePipeline, result := sender.Send(ctx, `{"id":"1"}`)
+	require.True(t, continuePipeline, result)
+	assert.Nil(t, result)
+
+	continuePipeline, result = sender.Send(ctx, []byte{0xff, 0xfe})
+	require.True(t, continuePipeline, result)
+
+	message := <-received
+	assert.Equal(t, websocket.TextMessage, message.messageType)
+	assert.Equal(t, `{"id":"1"}`, string(message.data))
+
+	message = <-received
+	assert.Equal(t, websocket.BinaryMessage, message.messageType)
+	assert.Equal(t, []byte{0xff, 0xfe}, message.data)
+}
+
+func TestWebSocketSender_SendFailure(t *testing.T) {
+	server, _ := newWebSocketTestServer(t)
+	serverURL := webSocketURL(server)
+	server.Close()
+
+	sender, err := NewWebSocketSender(WebSocketConfig{URL: serverURL, Timeout: "1s", PersistOnError: true})
+	require.NoError(t, err)
+
+	appContext := appfunction.NewContext("123", dic, "")
+	continuePipeline, result := sender.Send(appContext, "data")
+	require.False(t, continuePipeline)
+	require.Error(t, result.(error))
+	assert.Contains(t, result.(error).Error(), "failed to connect to WebSocket endpoint")
+	assert.Equal(t, []byte("data"), appContext.RetryData())
+
+	continuePipeline, result = sender.Send(ctx, nil)
+	require.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "No Data Received")
+}
+
+func TestWebSocketSender_Reconnect(t *testing.T) {
+	received := make(chan string, 10)
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		conn, err := upgrader.Upgrade(writer, request, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		received <- string(data)
+		// The endpoint closes the connection after the first message
+	}))
+	defer server.Close()
+
+	sender, err := NewWebSocketSender(WebSocketConfig{URL: webSocketURL(server)})
+	require.NoError(t, err)
+
+	continuePipeline, result := sender.Send(ctx, "1")
+	require.True(t, continuePipeline, result)
+	assert.Equal(t, "1", <-received)
+
+	// Wait until the sender has seen the connection close, then the next message is sent over a new connection
+	require.Eventually(t, func() bool {
+		sender.lock.Lock()
+		defer sender.lock.Unlock()
+		return sender.conn == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	continuePipeline, result = sender.Send(ctx, "2")
+	require.True(t, continuePipeline, result)
+	assert.Equal(t, "2", <-received)
+}
+
+func TestStreamSender_Publish(t *testing.T) {
+	sender := NewStreamSender()
+
+	continuePipeline, result := sender.Publish(ctx, "data")
+	require.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "stream is not enabled")
+
+	hub := stream.NewHub(1, 1)
+	dic.Update(di.ServiceConstructorMap{
+		container.StreamHubName: func(get di.Get) interface{} {
+			return hub
+		},
+	})
+	defer dic.Update(di.ServiceConstructorMap{
+		container.StreamHubName: func(get di.Get) interface{} {
+			return nil
+		},
+	})
+
+	client, err := hub.Register()
+	require.NoError(t, err)
+
+	continuePipeline, result = sender.Publish(ctx, "data")
+	require.True(t, continuePipeline)
+	assert.Equal(t, "data", result)
+	assert.Equal(t, "data", string(<-client.Messages()))
+
+	continuePipeline, result = sender.Publish(ctx, nil)
+	require.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "No Data Received")
+}
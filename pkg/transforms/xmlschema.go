@@ -0,0 +1,1041 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// xmlSchema is the subset of XML Schema (XSD 1.0) supported by the SDK. Supported constructs are global and local
+// element declarations and element references, named and anonymous complex and simple types, a single sequence, all
+// or choice content model of element declarations with minOccurs and maxOccurs on the elements, attributes with
+// use="optional" or use="required", simpleContent extensions and simple type restrictions with the enumeration,
+// pattern, length, minLength, maxLength, minInclusive, maxInclusive, minExclusive and maxExclusive facets.
+// Namespaces are ignored, so names are matched by their local part.
+//
+// Any other schema element or attribute, e.g. import, include, group, any, complexContent, list, union, mixed,
+// nillable, default, fixed or occurrence constraints on the content model itself, is rejected when the schema is
+// parsed rather than being ignored, so data is never validated against a schema only partially understood.
+// Annotations, id attributes and attributes from other namespaces are ignored.
+type xmlSchema struct {
+	Elements     []*xsdElement     `xml:"element"`
+	ComplexTypes []*xsdComplexType `xml:"complexType"`
+	SimpleTypes  []*xsdSimpleType  `xml:"simpleType"`
+	xsdUnsupported
+
+	elements     map[string]*xsdElement
+	complexTypes map[string]*xsdComplexType
+	simpleTypes  map[string]*xsdSimpleType
+}
+
+type xsdElement struct {
+	Name        string          `xml:"name,attr"`
+	Ref         string          `xml:"ref,attr"`
+	Type        string          `xml:"type,attr"`
+	MinOccurs   string          `xml:"minOccurs,attr"`
+	MaxOccurs   string          `xml:"maxOccurs,attr"`
+	ComplexType *xsdComplexType `xml:"complexType"`
+	SimpleType  *xsdSimpleType  `xml:"simpleType"`
+	xsdUnsupported
+
+	minOccurs int
+	// maxOccurs is -1 when unbounded
+	maxOccurs int
+}
+
+type xsdComplexType struct {
+	Name          string            `xml:"name,attr"`
+	Sequence      *xsdGroup         `xml:"sequence"`
+	All           *xsdGroup         `xml:"all"`
+	Choice        *xsdGroup         `xml:"choice"`
+	Attributes    []*xsdAttribute   `xml:"attribute"`
+	SimpleContent *xsdSimpleContent `xml:"simpleContent"`
+	xsdUnsupported
+}
+
+type xsdGroup struct {
+	Elements []*xsdElement `xml:"element"`
+	xsdUnsupported
+}
+
+type xsdSimpleContent struct {
+	Extension *xsdExtension `xml:"extension"`
+	xsdUnsupported
+}
+
+type xsdExtension struct {
+	Base       string          `xml:"base,attr"`
+	Attributes []*xsdAttribute `xml:"attribute"`
+	xsdUnsupported
+}
+
+type xsdAttribute struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Use        string         `xml:"use,attr"`
+	SimpleType *xsdSimpleType `xml:"simpleType"`
+	xsdUnsupported
+}
+
+type xsdSimpleType struct {
+	Name        string          `xml:"name,attr"`
+	Restriction *xsdRestriction `xml:"restriction"`
+	xsdUnsupported
+}
+
+type xsdRestriction struct {
+	Base         string         `xml:"base,attr"`
+	Enumerations []xsdFacet     `xml:"enumeration"`
+	Patterns     []xsdFacet     `xml:"pattern"`
+	Length       *xsdFacet      `xml:"length"`
+	MinLength    *xsdFacet      `xml:"minLength"`
+	MaxLength    *xsdFacet      `xml:"maxLength"`
+	MinInclusive *xsdFacet      `xml:"minInclusive"`
+	MaxInclusive *xsdFacet      `xml:"maxInclusive"`
+	MinExclusive *xsdFacet      `xml:"minExclusive"`
+	MaxExclusive *xsdFacet      `xml:"maxExclusive"`
+	SimpleType   *xsdSimpleType `xml:"simpleType"`
+	xsdUnsupported
+
+	patterns []*regexp.Regexp
+}
+
+type xsdFacet struct {
+	Value string `xml:"value,attr"`
+	xsdUnsupported
+}
+
+// xsdUnsupported captures the child elements and attributes of a schema construct which are not in the supported
+// subset so they are reported rather than ignored
+type xsdUnsupported struct {
+	UnsupportedElements   []xsdUnsupportedElement `xml:",any"`
+	UnsupportedAttributes []xml.Attr              `xml:",any,attr"`
+}
+
+type xsdUnsupportedElement struct {
+	XMLName xml.Name
+}
+
+// xsdBuiltinTypes are the supported built-in simple types
+var xsdBuiltinTypes = map[string]func(value string) error{
+	"anySimpleType":      func(string) error { return nil },
+	"string":             func(string) error { return nil },
+	"normalizedString":   func(string) error { return nil },
+	"token":              func(string) error { return nil },
+	"anyURI":             func(string) error { return nil },
+	"boolean":            validateXSDBoolean,
+	"decimal":            validateXSDFloat,
+	"float":              validateXSDFloat,
+	"double":             validateXSDFloat,
+	"integer":            validateXSDInteger(math.MinInt64, math.MaxInt64),
+	"long":               validateXSDInteger(math.MinInt64, math.MaxInt64),
+	"int":                validateXSDInteger(math.MinInt32, math.MaxInt32),
+	"short":              validateXSDInteger(math.MinInt16, math.MaxInt16),
+	"byte":               validateXSDInteger(math.MinInt8, math.MaxInt8),
+	"nonNegativeInteger": validateXSDInteger(0, math.MaxInt64),
+	"positiveInteger":    validateXSDInteger(1, math.MaxInt64),
+	"nonPositiveInteger": validateXSDInteger(math.MinInt64, 0),
+	"negativeInteger":    validateXSDInteger(math.MinInt64, -1),
+	"unsignedLong":       validateXSDUnsigned(math.MaxUint64),
+	"unsignedInt":        validateXSDUnsigned(math.MaxUint32),
+	"unsignedShort":      validateXSDUnsigned(math.MaxUint16),
+	"unsignedByte":       validateXSDUnsigned(math.MaxUint8),
+	"dateTime":           validateXSDTime("2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05"),
+	"date":               validateXSDTime("2006-01-02Z07:00", "2006-01-02"),
+	"time":               validateXSDTime("15:04:05Z07:00", "15:04:05"),
+	"base64Binary": func(value string) error {
+		_, err := base64.StdEncoding.DecodeString(value)
+		return err
+	},
+	"hexBinary": func(value string) error {
+		_, err := hex.DecodeString(value)
+		return err
+	},
+}
+
+// xsdSchemaAttributes are the attributes of the schema element which are accepted but ignored since namespaces are
+// ignored
+var xsdSchemaAttributes = []string{"targetNamespace", "elementFormDefault", "attributeFormDefault", "version"}
+
+// parseXMLSchema parses the XML Schema text and resolves the types and patterns it contains
+func parseXMLSchema(text string) (*xmlSchema, error) {
+	schema := &xmlSchema{}
+	if err := xml.Unmarshal([]byte(text), schema); err != nil {
+		return nil, fmt.Errorf("unable to parse XML schema: %s", err.Error())
+	}
+
+	if err := schema.compile(); err != nil {
+		return nil, fmt.Errorf("unable to parse XML schema: %s", err.Error())
+	}
+
+	return schema, nil
+}
+
+func (schema *xmlSchema) compile() error {
+	if err := schema.check("schema", xsdSchemaAttributes...); err != nil {
+		return err
+	}
+
+	schema.elements = make(map[string]*xsdElement)
+	for _, element := range schema.Elements {
+		schema.elements[element.Name] = element
+	}
+	schema.complexTypes = make(map[string]*xsdComplexType)
+	for _, complexType := range schema.ComplexTypes {
+		if len(complexType.Name) == 0 {
+			return errors.New("global complexType must have a name")
+		}
+		schema.complexTypes[complexType.Name] = complexType
+	}
+	schema.simpleTypes = make(map[string]*xsdSimpleType)
+	for _, simpleType := range schema.SimpleTypes {
+		if len(simpleType.Name) == 0 {
+			return errors.New("global simpleType must have a name")
+		}
+		schema.simpleTypes[simpleType.Name] = simpleType
+	}
+
+	if len(schema.elements) == 0 {
+		return errors.New("schema must declare at least one global element")
+	}
+
+	for _, element := range schema.Elements {
+		if len(element.Ref) > 0 || len(element.MinOccurs) > 0 || len(element.MaxOccurs) > 0 {
+			return fmt.Errorf("global element '%s' can not have ref, minOccurs or maxOccurs", element.Name)
+		}
+		if err := schema.compileElement(element); err != nil {
+			return err
+		}
+	}
+	for _, complexType := range schema.ComplexTypes {
+		if err := schema.compileComplexType(complexType); err != nil {
+			return err
+		}
+	}
+	for _, simpleType := range schema.SimpleTypes {
+		if err := schema.compileSimpleType(simpleType); err != nil {
+			return err
+		}
+		if err := schema.checkRestrictionCycle(simpleType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRestrictionCycle ensures the chain of base types of a named simple type ends at a built-in type
+func (schema *xmlSchema) checkRestrictionCycle(simpleType *xsdSimpleType) error {
+	visited := map[string]bool{simpleType.Name: true}
+	for base := xsdLocalName(simpleType.Restriction.Base); len(base) > 0; {
+		next, found := schema.simpleTypes[base]
+		if !found || next.Restriction == nil {
+			return nil
+		}
+		if visited[base] {
+			return fmt.Errorf("simple type '%s' is derived from itself", simpleType.Name)
+		}
+		visited[base] = true
+		base = xsdLocalName(next.Restriction.Base)
+	}
+	return nil
+}
+
+func (schema *xmlSchema) compileElement(element *xsdElement) error {
+	if err := element.check(xsdConstructName("element", element.Name+element.Ref)); err != nil {
+		return err
+	}
+
+	var err error
+	if element.minOccurs, element.maxOccurs, err = parseXSDOccurs(element.MinOccurs, element.MaxOccurs); err != nil {
+		return fmt.Errorf("element '%s': %s", element.Name+element.Ref, err.Error())
+	}
+
+	if len(element.Ref) > 0 {
+		if len(element.Name) > 0 || len(element.Type) > 0 || element.ComplexType != nil || element.SimpleType != nil {
+			return fmt.Errorf("element reference '%s' can not also declare a name or type", element.Ref)
+		}
+		if _, found := schema.elements[xsdLocalName(element.Ref)]; !found {
+			return fmt.Errorf("element reference '%s' is not declared", element.Ref)
+		}
+		return nil
+	}
+
+	if len(element.Name) == 0 {
+		return errors.New("element must have a name or ref")
+	}
+
+	if xsdCountSet(len(element.Type) > 0, element.ComplexType != nil, element.SimpleType != nil) > 1 {
+		return fmt.Errorf("element '%s' can only have one of a type attribute, complexType or simpleType", element.Name)
+	}
+
+	switch {
+	case element.ComplexType != nil:
+		return schema.compileComplexType(element.ComplexType)
+	case element.SimpleType != nil:
+		return schema.compileSimpleType(element.SimpleType)
+	case len(element.Type) > 0:
+		if _, found := schema.complexTypes[xsdLocalName(element.Type)]; found {
+			return nil
+		}
+		return schema.checkSimpleTypeName(element.Type)
+	}
+
+	return nil
+}
+
+func (schema *xmlSchema) compileComplexType(complexType *xsdComplexType) error {
+	construct := xsdConstructName("complexType", complexType.Name)
+	if err := complexType.check(construct); err != nil {
+		return err
+	}
+
+	if xsdCountSet(complexType.Sequence != nil, complexType.All != nil, complexType.Choice != nil,
+		complexType.SimpleContent != nil) > 1 {
+		return fmt.Errorf("%s can only have one of sequence, all, choice or simpleContent", construct)
+	}
+
+	attributes := complexType.Attributes
+	if simpleContent := complexType.SimpleContent; simpleContent != nil {
+		if err := simpleContent.check("simpleContent"); err != nil {
+			return err
+		}
+		if simpleContent.Extension == nil {
+			return fmt.Errorf("simpleContent of %s must be an extension", construct)
+		}
+		if err := simpleContent.Extension.check("extension"); err != nil {
+			return err
+		}
+		if err := schema.checkSimpleTypeName(simpleContent.Extension.Base); err != nil {
+			return err
+		}
+		attributes = append(attributes, simpleContent.Extension.Attributes...)
+	}
+
+	for _, attribute := range attributes {
+		if err := schema.compileAttribute(attribute); err != nil {
+			return err
+		}
+	}
+
+	for groupName, group := range map[string]*xsdGroup{
+		"sequence": complexType.Sequence,
+		"all":      complexType.All,
+		"choice":   complexType.Choice,
+	} {
+		if group == nil {
+			continue
+		}
+		if err := group.check(groupName); err != nil {
+			return err
+		}
+		for _, element := range group.Elements {
+			if err := schema.compileElement(element); err != nil {
+				return err
+			}
+			if groupName == "all" && element.maxOccurs != 1 {
+				return fmt.Errorf("element '%s' in all must have maxOccurs 1", element.Name+element.Ref)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (schema *xmlSchema) compileAttribute(attribute *xsdAttribute) error {
+	construct := xsdConstructName("attribute", attribute.Name)
+	if err := attribute.check(construct); err != nil {
+		return err
+	}
+
+	if len(attribute.Name) == 0 {
+		return errors.New("attribute must have a name")
+	}
+
+	switch attribute.Use {
+	case "", "optional", "required":
+	default:
+		return fmt.Errorf("%s: use '%s' is not supported", construct, attribute.Use)
+	}
+
+	if attribute.SimpleType != nil {
+		if len(attribute.Type) > 0 {
+			return fmt.Errorf("%s can only have one of a type attribute or simpleType", construct)
+		}
+		return schema.compileSimpleType(attribute.SimpleType)
+	}
+	if len(attribute.Type) > 0 {
+		return schema.checkSimpleTypeName(attribute.Type)
+	}
+	return nil
+}
+
+func (schema *xmlSchema) compileSimpleType(simpleType *xsdSimpleType) error {
+	construct := xsdConstructName("simpleType", simpleType.Name)
+	if err := simpleType.check(construct); err != nil {
+		return err
+	}
+
+	restriction := simpleType.Restriction
+	if restriction == nil {
+		return fmt.Errorf("%s must be a restriction", construct)
+	}
+	if err := restriction.check("restriction"); err != nil {
+		return err
+	}
+
+	if restriction.SimpleType != nil {
+		if len(restriction.Base) > 0 {
+			return fmt.Errorf("restriction of %s can only have one of a base or simpleType", construct)
+		}
+		if err := schema.compileSimpleType(restriction.SimpleType); err != nil {
+			return err
+		}
+	} else if err := schema.checkSimpleTypeName(restriction.Base); err != nil {
+		return err
+	}
+
+	facets := append([]xsdFacet{}, restriction.Enumerations...)
+	facets = append(facets, restriction.Patterns...)
+	for _, facet := range []*xsdFacet{restriction.Length, restriction.MinLength, restriction.MaxLength,
+		restriction.MinInclusive, restriction.MaxInclusive, restriction.MinExclusive, restriction.MaxExclusive} {
+		if facet != nil {
+			facets = append(facets, *facet)
+		}
+	}
+	for _, facet := range facets {
+		if err := facet.check("facet"); err != nil {
+			return err
+		}
+	}
+
+	for _, facet := range restriction.Patterns {
+		// XSD patterns are implicitly anchored to the whole value
+		pattern, err := regexp.Compile("^(?:" + facet.Value + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s': %s", facet.Value, err.Error())
+		}
+		restriction.patterns = append(restriction.patterns, pattern)
+	}
+
+	for _, facet := range []*xsdFacet{restriction.Length, restriction.MinLength, restriction.MaxLength} {
+		if facet == nil {
+			continue
+		}
+		if _, err := strconv.Atoi(facet.Value); err != nil {
+			return fmt.Errorf("invalid length facet '%s'", facet.Value)
+		}
+	}
+
+	for _, facet := range []*xsdFacet{restriction.MinInclusive, restriction.MaxInclusive, restriction.MinExclusive, restriction.MaxExclusive} {
+		if facet == nil {
+			continue
+		}
+		if _, err := strconv.ParseFloat(facet.Value, 64); err != nil {
+			return fmt.Errorf("invalid range facet '%s'", facet.Value)
+		}
+	}
+
+	return nil
+}
+
+func (schema *xmlSchema) checkSimpleTypeName(name string) error {
+	localName := xsdLocalName(name)
+	if _, found := schema.simpleTypes[localName]; found {
+		return nil
+	}
+	if _, found := xsdBuiltinTypes[localName]; found {
+		return nil
+	}
+	return fmt.Errorf("type '%s' is not declared or not supported", name)
+}
+
+// check returns an error naming the first child element or attribute of the construct which is not supported. The
+// ignored attributes are accepted in addition to id, namespace declarations and attributes from other namespaces.
+func (unsupported xsdUnsupported) check(construct string, ignoredAttributes ...string) error {
+	for _, element := range unsupported.UnsupportedElements {
+		if element.XMLName.Local != "annotation" {
+			return fmt.Errorf("schema construct '%s' in %s is not supported", element.XMLName.Local, construct)
+		}
+	}
+
+	for _, attribute := range unsupported.UnsupportedAttributes {
+		if len(attribute.Name.Space) > 0 || attribute.Name.Local == "xmlns" || attribute.Name.Local == "id" {
+			continue
+		}
+		ignored := false
+		for _, name := range ignoredAttributes {
+			if attribute.Name.Local == name {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			return fmt.Errorf("schema attribute '%s' of %s is not supported", attribute.Name.Local, construct)
+		}
+	}
+
+	return nil
+}
+
+// xsdConstructName describes the construct in errors, e.g. "element 'name'"
+func xsdConstructName(kind string, name string) string {
+	if len(name) == 0 {
+		return "anonymous " + kind
+	}
+	return fmt.Sprintf("%s '%s'", kind, name)
+}
+
+func xsdCountSet(values ...bool) int {
+	count := 0
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
+
+func parseXSDOccurs(minOccurs string, maxOccurs string) (int, int, error) {
+	min, max := 1, 1
+
+	if len(minOccurs) > 0 {
+		value, err := strconv.Atoi(minOccurs)
+		if err != nil || value < 0 {
+			return 0, 0, fmt.Errorf("invalid minOccurs '%s'", minOccurs)
+		}
+		min = value
+	}
+
+	switch maxOccurs {
+	case "":
+	case "unbounded":
+		max = -1
+	default:
+		value, err := strconv.Atoi(maxOccurs)
+		if err != nil || value < 0 {
+			return 0, 0, fmt.Errorf("invalid maxOccurs '%s'", maxOccurs)
+		}
+		max = value
+	}
+
+	if max >= 0 && min > max {
+		return 0, 0, fmt.Errorf("minOccurs %d is greater than maxOccurs %d", min, max)
+	}
+
+	return min, max, nil
+}
+
+// xsdLocalName removes the namespace prefix, if any, from a type or element reference
+func xsdLocalName(name string) string {
+	if index := strings.LastIndex(name, ":"); index >= 0 {
+		return name[index+1:]
+	}
+	return name
+}
+
+// xmlNode is an element of the XML document being validated
+type xmlNode struct {
+	name       string
+	attributes map[string]string
+	children   []*xmlNode
+	text       string
+}
+
+func parseXMLDocument(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root *xmlNode
+	var stack []*xmlNode
+	var text []*strings.Builder
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: token.Name.Local, attributes: make(map[string]string)}
+			for _, attribute := range token.Attr {
+				// Namespace declarations and schema instance attributes aren't part of the content being validated
+				if attribute.Name.Space == "xmlns" || attribute.Name.Local == "xmlns" ||
+					attribute.Name.Space == "http://www.w3.org/2001/XMLSchema-instance" {
+					continue
+				}
+				node.attributes[attribute.Name.Local] = attribute.Value
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+			text = append(text, &strings.Builder{})
+
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1].Write(token)
+			}
+
+		case xml.EndElement:
+			stack[len(stack)-1].text = text[len(text)-1].String()
+			stack = stack[:len(stack)-1]
+			text = text[:len(text)-1]
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("document has no root element")
+	}
+
+	return root, nil
+}
+
+// validateBytes parses the XML data and validates it against the schema
+func (schema *xmlSchema) validateBytes(data []byte) []string {
+	root, err := parseXMLDocument(data)
+	if err != nil {
+		return []string{fmt.Sprintf("data is not valid XML: %s", err.Error())}
+	}
+
+	declaration, found := schema.elements[root.name]
+	if !found {
+		return []string{fmt.Sprintf("/%s: no global element declaration for root element", root.name)}
+	}
+
+	return schema.validateElement("/"+root.name, declaration, root)
+}
+
+func (schema *xmlSchema) validateElement(path string, declaration *xsdElement, node *xmlNode) []string {
+	if len(declaration.Ref) > 0 {
+		declaration = schema.elements[xsdLocalName(declaration.Ref)]
+	}
+
+	switch {
+	case declaration.ComplexType != nil:
+		return schema.validateComplex(path, declaration.ComplexType, node)
+	case declaration.SimpleType != nil:
+		return schema.validateSimpleElement(path, declaration.SimpleType.Name, declaration.SimpleType, node)
+	case len(declaration.Type) > 0:
+		typeName := xsdLocalName(declaration.Type)
+		if complexType, found := schema.complexTypes[typeName]; found {
+			return schema.validateComplex(path, complexType, node)
+		}
+		return schema.validateSimpleElement(path, typeName, nil, node)
+	}
+
+	// Elements declared without a type accept any content
+	return nil
+}
+
+func (schema *xmlSchema) validateSimpleElement(path string, typeName string, simpleType *xsdSimpleType, node *xmlNode) []string {
+	var violations []string
+
+	if len(node.attributes) > 0 {
+		for _, name := range sortedXMLAttributeNames(node.attributes) {
+			violations = append(violations, fmt.Sprintf("%s: attribute '%s' is not allowed", path, name))
+		}
+	}
+
+	if len(node.children) > 0 {
+		return append(violations, fmt.Sprintf("%s: element '%s' is not allowed in simple content", path, node.children[0].name))
+	}
+
+	if err := schema.validateSimpleValue(typeName, simpleType, node.text); err != nil {
+		violations = append(violations, fmt.Sprintf("%s: %s", path, err.Error()))
+	}
+
+	return violations
+}
+
+func (schema *xmlSchema) validateComplex(path string, complexType *xsdComplexType, node *xmlNode) []string {
+	attributes := complexType.Attributes
+	if complexType.SimpleContent != nil {
+		attributes = append(attributes, complexType.SimpleContent.Extension.Attributes...)
+	}
+
+	violations := schema.validateAttributes(path, attributes, node.attributes)
+
+	if complexType.SimpleContent != nil {
+		if len(node.children) > 0 {
+			return append(violations, fmt.Sprintf("%s: element '%s' is not allowed in simple content", path, node.children[0].name))
+		}
+		if err := schema.validateSimpleValue(xsdLocalName(complexType.SimpleContent.Extension.Base), nil, node.text); err != nil {
+			violations = append(violations, fmt.Sprintf("%s: %s", path, err.Error()))
+		}
+		return violations
+	}
+
+	switch {
+	case complexType.Sequence != nil:
+		return append(violations, schema.validateSequence(path, complexType.Sequence, node.children)...)
+	case complexType.All != nil:
+		return append(violations, schema.validateAll(path, complexType.All, node.children)...)
+	case complexType.Choice != nil:
+		return append(violations, schema.validateChoice(path, complexType.Choice, node.children)...)
+	}
+
+	if len(node.children) > 0 {
+		violations = append(violations, fmt.Sprintf("%s: element '%s' is not allowed in empty content", path, node.children[0].name))
+	}
+
+	return violations
+}
+
+func (schema *xmlSchema) validateAttributes(path string, declarations []*xsdAttribute, attributes map[string]string) []string {
+	var violations []string
+
+	declared := make(map[string]*xsdAttribute, len(declarations))
+	for _, declaration := range declarations {
+		declared[declaration.Name] = declaration
+
+		value, found := attributes[declaration.Name]
+		if !found {
+			if declaration.Use == "required" {
+				violations = append(violations, fmt.Sprintf("%s: missing required attribute '%s'", path, declaration.Name))
+			}
+			continue
+		}
+
+		typeName := xsdLocalName(declaration.Type)
+		if declaration.SimpleType == nil && len(typeName) == 0 {
+			continue
+		}
+		if err := schema.validateSimpleValue(typeName, declaration.SimpleType, value); err != nil {
+			violations = append(violations, fmt.Sprintf("%s/@%s: %s", path, declaration.Name, err.Error()))
+		}
+	}
+
+	for _, name := range sortedXMLAttributeNames(attributes) {
+		if _, found := declared[name]; !found {
+			violations = append(violations, fmt.Sprintf("%s: attribute '%s' is not allowed", path, name))
+		}
+	}
+
+	return violations
+}
+
+// particleName returns the name of the elements matched by the group's element declaration
+func (schema *xmlSchema) particleName(particle *xsdElement) string {
+	if len(particle.Ref) > 0 {
+		return xsdLocalName(particle.Ref)
+	}
+	return particle.Name
+}
+
+func (schema *xmlSchema) validateSequence(path string, group *xsdGroup, children []*xmlNode) []string {
+	var violations []string
+
+	index := 0
+	for _, particle := range group.Elements {
+		name := schema.particleName(particle)
+
+		count := 0
+		for index < len(children) && children[index].name == name && (particle.maxOccurs < 0 || count < particle.maxOccurs) {
+			count++
+			violations = append(violations, schema.validateElement(xmlChildPath(path, name, count), particle, children[index])...)
+			index++
+		}
+
+		if count < particle.minOccurs {
+			violations = append(violations, fmt.Sprintf("%s: expected at least %d '%s' element(s) but found %d", path, particle.minOccurs, name, count))
+		}
+	}
+
+	if index < len(children) {
+		violations = append(violations, fmt.Sprintf("%s: unexpected element '%s'", path, children[index].name))
+	}
+
+	return violations
+}
+
+func (schema *xmlSchema) validateAll(path string, group *xsdGroup, children []*xmlNode) []string {
+	var violations []string
+
+	particles := make(map[string]*xsdElement, len(group.Elements))
+	for _, particle := range group.Elements {
+		particles[schema.particleName(particle)] = particle
+	}
+
+	counts := make(map[string]int)
+	for _, child := range children {
+		particle, found := particles[child.name]
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s: unexpected element '%s'", path, child.name))
+			continue
+		}
+
+		counts[child.name]++
+		if counts[child.name] > 1 {
+			violations = append(violations, fmt.Sprintf("%s: element '%s' may only occur once", path, child.name))
+			continue
+		}
+		violations = append(violations, schema.validateElement(path+"/"+child.name, particle, child)...)
+	}
+
+	for _, particle := range group.Elements {
+		name := schema.particleName(particle)
+		if particle.minOccurs > 0 && counts[name] == 0 {
+			violations = append(violations, fmt.Sprintf("%s: missing required element '%s'", path, name))
+		}
+	}
+
+	return violations
+}
+
+func (schema *xmlSchema) validateChoice(path string, group *xsdGroup, children []*xmlNode) []string {
+	particles := make(map[string]*xsdElement, len(group.Elements))
+	optional := false
+	for _, particle := range group.Elements {
+		particles[schema.particleName(particle)] = particle
+		optional = optional || particle.minOccurs == 0
+	}
+
+	if len(children) == 0 {
+		if !optional {
+			return []string{fmt.Sprintf("%s: expected one of the choice elements", path)}
+		}
+		return nil
+	}
+
+	var violations []string
+	count := 0
+	for _, child := range children {
+		particle, found := particles[child.name]
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s: unexpected element '%s'", path, child.name))
+			continue
+		}
+
+		// Only a single alternative may be chosen, although it may repeat
+		if child.name != children[0].name {
+			violations = append(violations, fmt.Sprintf("%s: element '%s' is not allowed with '%s'", path, child.name, children[0].name))
+			continue
+		}
+
+		count++
+		if particle.maxOccurs >= 0 && count > particle.maxOccurs {
+			violations = append(violations, fmt.Sprintf("%s: too many '%s' elements", path, child.name))
+			continue
+		}
+		violations = append(violations, schema.validateElement(xmlChildPath(path, child.name, count), particle, child)...)
+	}
+
+	if particle, found := particles[children[0].name]; found && count < particle.minOccurs {
+		violations = append(violations, fmt.Sprintf("%s: expected at least %d '%s' element(s) but found %d",
+			path, particle.minOccurs, children[0].name, count))
+	}
+
+	return violations
+}
+
+// validateSimpleValue validates the text against the named simple type, or the anonymous simple type if specified
+func (schema *xmlSchema) validateSimpleValue(typeName string, simpleType *xsdSimpleType, value string) error {
+	if simpleType == nil {
+		if named, found := schema.simpleTypes[typeName]; found {
+			simpleType = named
+		}
+	}
+
+	if simpleType == nil {
+		validate, found := xsdBuiltinTypes[typeName]
+		if !found {
+			return fmt.Errorf("unsupported type '%s'", typeName)
+		}
+
+		if typeName != "string" && typeName != "normalizedString" {
+			value = strings.TrimSpace(value)
+		}
+		if err := validate(value); err != nil {
+			return fmt.Errorf("value '%s' is not a valid %s", value, typeName)
+		}
+		return nil
+	}
+
+	restriction := simpleType.Restriction
+	if err := schema.validateSimpleValue(xsdLocalName(restriction.Base), restriction.SimpleType, value); err != nil {
+		return err
+	}
+
+	if !xsdIsStringType(restriction.Base) {
+		value = strings.TrimSpace(value)
+	}
+
+	if len(restriction.Enumerations) > 0 {
+		found := false
+		for _, enumeration := range restriction.Enumerations {
+			if enumeration.Value == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("value '%s' is not one of the allowed values", value)
+		}
+	}
+
+	if len(restriction.patterns) > 0 {
+		matched := false
+		for _, pattern := range restriction.patterns {
+			if pattern.MatchString(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("value '%s' does not match the required pattern", value)
+		}
+	}
+
+	length := utf8.RuneCountInString(value)
+	if restriction.Length != nil && length != xsdFacetInt(restriction.Length) {
+		return fmt.Errorf("length %d is not %s", length, restriction.Length.Value)
+	}
+	if restriction.MinLength != nil && length < xsdFacetInt(restriction.MinLength) {
+		return fmt.Errorf("length %d is less than minLength %s", length, restriction.MinLength.Value)
+	}
+	if restriction.MaxLength != nil && length > xsdFacetInt(restriction.MaxLength) {
+		return fmt.Errorf("length %d is greater than maxLength %s", length, restriction.MaxLength.Value)
+	}
+
+	if restriction.MinInclusive == nil && restriction.MaxInclusive == nil &&
+		restriction.MinExclusive == nil && restriction.MaxExclusive == nil {
+		return nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("value '%s' is not a number", value)
+	}
+	if restriction.MinInclusive != nil && number < xsdFacetFloat(restriction.MinInclusive) {
+		return fmt.Errorf("value %s is less than minInclusive %s", value, restriction.MinInclusive.Value)
+	}
+	if restriction.MaxInclusive != nil && number > xsdFacetFloat(restriction.MaxInclusive) {
+		return fmt.Errorf("value %s is greater than maxInclusive %s", value, restriction.MaxInclusive.Value)
+	}
+	if restriction.MinExclusive != nil && number <= xsdFacetFloat(restriction.MinExclusive) {
+		return fmt.Errorf("value %s is not greater than minExclusive %s", value, restriction.MinExclusive.Value)
+	}
+	if restriction.MaxExclusive != nil && number >= xsdFacetFloat(restriction.MaxExclusive) {
+		return fmt.Errorf("value %s is not less than maxExclusive %s", value, restriction.MaxExclusive.Value)
+	}
+
+	return nil
+}
+
+func xsdIsStringType(typeName string) bool {
+	switch xsdLocalName(typeName) {
+	case "string", "normalizedString":
+		return true
+	}
+	return false
+}
+
+// xsdFacetInt and xsdFacetFloat return the facet values, which have already been checked when the schema was compiled
+func xsdFacetInt(facet *xsdFacet) int {
+	value, _ := strconv.Atoi(facet.Value)
+	return value
+}
+
+func xsdFacetFloat(facet *xsdFacet) float64 {
+	value, _ := strconv.ParseFloat(facet.Value, 64)
+	return value
+}
+
+func validateXSDBoolean(value string) error {
+	switch value {
+	case "true", "false", "1", "0":
+		return nil
+	}
+	return errors.New("invalid boolean")
+}
+
+func validateXSDFloat(value string) error {
+	_, err := strconv.ParseFloat(value, 64)
+	return err
+}
+
+func validateXSDInteger(min int64, max int64) func(value string) error {
+	return func(value string) error {
+		number, err := strconv.ParseInt(strings.TrimPrefix(value, "+"), 10, 64)
+		if err != nil {
+			return err
+		}
+		if number < min || number > max {
+			return errors.New("integer out of range")
+		}
+		return nil
+	}
+}
+
+func validateXSDUnsigned(max uint64) func(value string) error {
+	return func(value string) error {
+		number, err := strconv.ParseUint(strings.TrimPrefix(value, "+"), 10, 64)
+		if err != nil {
+			return err
+		}
+		if number > max {
+			return errors.New("integer out of range")
+		}
+		return nil
+	}
+}
+
+func validateXSDTime(layouts ...string) func(value string) error {
+	return func(value string) error {
+		var err error
+		for _, layout := range layouts {
+			if _, err = time.Parse(layout, value); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+}
+
+func xmlChildPath(path string, name string, position int) string {
+	return fmt.Sprintf("%s/%s[%d]", path, name, position)
+}
+
+func sortedXMLAttributeNames(attributes map[string]string) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
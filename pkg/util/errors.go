@@ -0,0 +1,108 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package util
+
+import "errors"
+
+// HTTPStatusError is returned by a pipeline function which stops the pipeline with an error to set the status code
+// the HTTP trigger responds with, rather than the default of 422 (Unprocessable Entity)
+type HTTPStatusError struct {
+	StatusCode int
+	Err        error
+}
+
+// NewHTTPStatusError creates and returns a new HTTPStatusError for the specified status code and error
+func NewHTTPStatusError(statusCode int, err error) HTTPStatusError {
+	return HTTPStatusError{StatusCode: statusCode, Err: err}
+}
+
+// Error returns the message of the wrapped error
+func (e HTTPStatusError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e HTTPStatusError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCategory indicates how the runtime handles the error a pipeline function stops the pipeline with
+type ErrorCategory string
+
+const (
+	// ErrorCategoryRetryable is for failures expected to be temporary, i.e. the export destination being unreachable.
+	// The retry data set by the function, if any, is stored for later retry when Store and Forward is enabled.
+	ErrorCategoryRetryable ErrorCategory = "retryable"
+	// ErrorCategoryFatal is for failures which retrying can't fix, i.e. invalid data. The data is never stored for
+	// retry and stored data which fails with a fatal error is removed rather than retried again.
+	ErrorCategoryFatal ErrorCategory = "fatal"
+	// ErrorCategoryIgnore is for data which can't be processed but isn't a failure. The data is dropped as if
+	// filtered out and the error is only logged at debug level.
+	ErrorCategoryIgnore ErrorCategory = "ignore"
+)
+
+// PipelineError is returned by a pipeline function which stops the pipeline with an error to control how the runtime
+// handles it. Errors which aren't a PipelineError are handled as retryable.
+type PipelineError struct {
+	Category ErrorCategory
+	// StatusCode, when not zero, is the status code the HTTP trigger responds with, rather than the default
+	// of 422 (Unprocessable Entity)
+	StatusCode int
+	Err        error
+}
+
+// NewRetryableError creates and returns a new PipelineError with the retryable category for the specified error
+func NewRetryableError(err error) PipelineError {
+	return PipelineError{Category: ErrorCategoryRetryable, Err: err}
+}
+
+// NewFatalError creates and returns a new PipelineError with the fatal category for the specified error
+func NewFatalError(err error) PipelineError {
+	return PipelineError{Category: ErrorCategoryFatal, Err: err}
+}
+
+// NewIgnoreError creates and returns a new PipelineError with the ignore category for the specified error
+func NewIgnoreError(err error) PipelineError {
+	return PipelineError{Category: ErrorCategoryIgnore, Err: err}
+}
+
+// WithStatusCode returns a copy of the error with the status code the HTTP trigger responds with
+func (e PipelineError) WithStatusCode(statusCode int) PipelineError {
+	e.StatusCode = statusCode
+	return e
+}
+
+// Error returns the message of the wrapped error
+func (e PipelineError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e PipelineError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCategoryOf returns the category of the first PipelineError in the error's chain, or ErrorCategoryRetryable
+// if there isn't one
+func ErrorCategoryOf(err error) ErrorCategory {
+	var pipelineError PipelineError
+	if errors.As(err, &pipelineError) && len(pipelineError.Category) > 0 {
+		return pipelineError.Category
+	}
+
+	return ErrorCategoryRetryable
+}
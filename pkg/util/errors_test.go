@@ -0,0 +1,74 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPStatusError(t *testing.T) {
+	cause := errors.New("signature mismatch")
+	err := fmt.Errorf("wrapped: %w", NewHTTPStatusError(http.StatusUnauthorized, cause))
+
+	var statusError HTTPStatusError
+	require.True(t, errors.As(err, &statusError))
+	assert.Equal(t, http.StatusUnauthorized, statusError.StatusCode)
+	assert.Equal(t, "signature mismatch", statusError.Error())
+	assert.True(t, errors.Is(err, cause))
+}
+
+func TestPipelineError(t *testing.T) {
+	cause := errors.New("invalid reading")
+	err := fmt.Errorf("wrapped: %w", NewFatalError(cause).WithStatusCode(http.StatusBadRequest))
+
+	var pipelineError PipelineError
+	require.True(t, errors.As(err, &pipelineError))
+	assert.Equal(t, ErrorCategoryFatal, pipelineError.Category)
+	assert.Equal(t, http.StatusBadRequest, pipelineError.StatusCode)
+	assert.Equal(t, "invalid reading", pipelineError.Error())
+	assert.True(t, errors.Is(err, cause))
+}
+
+func TestErrorCategoryOf(t *testing.T) {
+	cause := errors.New("failed")
+
+	tests := []struct {
+		Name     string
+		Err      error
+		Expected ErrorCategory
+	}{
+		{"Plain error", cause, ErrorCategoryRetryable},
+		{"Retryable", NewRetryableError(cause), ErrorCategoryRetryable},
+		{"Fatal", NewFatalError(cause), ErrorCategoryFatal},
+		{"Ignore", NewIgnoreError(cause), ErrorCategoryIgnore},
+		{"Wrapped", fmt.Errorf("wrapped: %w", NewIgnoreError(cause)), ErrorCategoryIgnore},
+		{"No category", PipelineError{StatusCode: http.StatusConflict, Err: cause}, ErrorCategoryRetryable},
+		{"Status error", NewHTTPStatusError(http.StatusUnauthorized, cause), ErrorCategoryRetryable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, ErrorCategoryOf(test.Err))
+		})
+	}
+}
@@ -20,6 +20,9 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 )
 
 //SplitComma - use custom split func, on commas, instead of .Split to eliminate empty values (i.e Test,,,)
@@ -44,7 +47,8 @@ func DeleteEmptyAndTrim(s []string) []string {
 	return r
 }
 
-//CoerceType will accept a string, []byte, or json.Marshaller type and convert it to a []byte for use and consistency in the SDK
+//CoerceType will accept a string, []byte, or json.Marshaller type and convert it to a []byte for use and consistency in the SDK.
+//Binary readings are converted to their raw binary value.
 func CoerceType(param interface{}) ([]byte, error) {
 	var data []byte
 	var err error
@@ -57,15 +61,35 @@ func CoerceType(param interface{}) ([]byte, error) {
 	case []byte:
 		data = param.([]byte)
 
+	case dtos.BinaryReading:
+		// Binary payloads, i.e. images, are used as is rather than base64 encoded by JSON
+		data = param.(dtos.BinaryReading).BinaryValue
+
+	case dtos.BaseReading:
+		reading := param.(dtos.BaseReading)
+		if reading.ValueType != common.ValueTypeBinary {
+			return coerceToJSON(reading)
+		}
+		data = reading.BinaryValue
+
 	default:
-		data, err = json.Marshal(param)
+		data, err = coerceToJSON(param)
 		if err != nil {
-			return nil, errors.New(
-				"marshaling input data to JSON failed, " +
-					"passed in data must be of type []byte, string, or support marshaling to JSON",
-			)
+			return nil, err
 		}
 	}
 
 	return data, nil
 }
+
+func coerceToJSON(param interface{}) ([]byte, error) {
+	data, err := json.Marshal(param)
+	if err != nil {
+		return nil, errors.New(
+			"marshaling input data to JSON failed, " +
+				"passed in data must be of type []byte, string, or support marshaling to JSON",
+		)
+	}
+
+	return data, nil
+}
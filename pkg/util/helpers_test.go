@@ -17,13 +17,16 @@
 package util
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSplitComma(t *testing.T) {
@@ -77,6 +80,33 @@ func TestCoerceTypeJSONMarshallerToByteArray(t *testing.T) {
 	assert.NoError(t, err)
 	assert.IsType(t, reflect.TypeOf(expectedType), reflect.TypeOf(result))
 }
+func TestCoerceTypeBinaryReading(t *testing.T) {
+	binaryValue := []byte{0x00, 0xff, 0x10, 0x80}
+	binaryReading := dtos.NewBinaryReading("profile", "device", "image", binaryValue, "image/jpeg")
+	simpleReading, err := dtos.NewSimpleReading("profile", "device", "temperature", common.ValueTypeInt32, int32(21))
+	require.NoError(t, err)
+	expectedSimple, err := json.Marshal(simpleReading)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name     string
+		Data     interface{}
+		Expected []byte
+	}{
+		{"Binary reading", binaryReading, binaryValue},
+		{"Binary reading payload", binaryReading.BinaryReading, binaryValue},
+		{"Simple reading", simpleReading, expectedSimple},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			result, err := CoerceType(test.Data)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, result)
+		})
+	}
+}
+
 func TestCoerceTypeNotSupportedToByteArray(t *testing.T) {
 	// Channels are not marshalable to JSON and generate an error
 	myData := make(chan int)
@@ -0,0 +1,190 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// ContentTypeMsgPack is the content type of MessagePack encoded payloads
+const ContentTypeMsgPack = "application/msgpack"
+
+// maxMsgPackDepth limits the nesting of decoded MessagePack maps and arrays
+const maxMsgPackDepth = 1000
+
+// MarshalMsgPack encodes the value as MessagePack. The value is encoded via its JSON form, so the same field names
+// and custom JSON marshaling apply as for JSON payloads.
+func MarshalMsgPack(v interface{}) ([]byte, error) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	value, err = fromJSONNumbers(value)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := msgpack.NewEncoder(buffer)
+	// Keys are sorted, so the encoding is deterministic
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalMsgPack decodes the MessagePack data into the target. The data is decoded via its JSON form, so the same
+// field names, custom JSON unmarshaling and validation apply as for JSON payloads. Binary values are decoded as
+// base64 strings, which is how []byte fields are represented in JSON, and non-string map keys are converted to strings.
+func UnmarshalMsgPack(data []byte, target interface{}) error {
+	reader := bytes.NewReader(data)
+	value, err := decodeMsgPack(msgpack.NewDecoder(reader), 0)
+	if err != nil {
+		return err
+	}
+	if reader.Len() > 0 {
+		return fmt.Errorf("unexpected %d bytes after MessagePack value", reader.Len())
+	}
+
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, target)
+}
+
+// fromJSONNumbers replaces the JSON numbers in the value with integers, where they are whole numbers that fit,
+// otherwise floats, so they are encoded as MessagePack numbers rather than strings
+func fromJSONNumbers(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if number, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return number, nil
+		}
+		if number, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return number, nil
+		}
+		return v.Float64()
+	case []interface{}:
+		for index, item := range v {
+			converted, err := fromJSONNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[index] = converted
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			converted, err := fromJSONNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+	}
+
+	return value, nil
+}
+
+// decodeMsgPack decodes the next value, converting maps to have string keys so the value can be marshaled as JSON.
+// Maps and arrays are decoded here rather than by the decoder so their nesting can be limited.
+func decodeMsgPack(decoder *msgpack.Decoder, depth int) (interface{}, error) {
+	code, err := decoder.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+
+	isArray := msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32
+	isMap := msgpcode.IsFixedMap(code) || code == msgpcode.Map16 || code == msgpcode.Map32
+	if !isArray && !isMap {
+		return decoder.DecodeInterface()
+	}
+
+	if depth >= maxMsgPackDepth {
+		return nil, fmt.Errorf("MessagePack data is nested more than %d levels deep", maxMsgPackDepth)
+	}
+
+	if isArray {
+		return decodeMsgPackArray(decoder, depth)
+	}
+
+	return decodeMsgPackMap(decoder, depth)
+}
+
+func decodeMsgPackArray(decoder *msgpack.Decoder, depth int) (interface{}, error) {
+	length, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+
+	// Elements are appended rather than allocated up front, which guards against allocating for a bogus length
+	var array []interface{}
+	for i := 0; i < length; i++ {
+		value, err := decodeMsgPack(decoder, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+
+	return array, nil
+}
+
+func decodeMsgPackMap(decoder *msgpack.Decoder, depth int) (interface{}, error) {
+	length, err := decoder.DecodeMapLen()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for i := 0; i < length; i++ {
+		key, err := decodeMsgPack(decoder, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeMsgPack(decoder, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		if text, ok := key.(string); ok {
+			result[text] = value
+		} else {
+			result[fmt.Sprint(key)] = value
+		}
+	}
+
+	return result, nil
+}
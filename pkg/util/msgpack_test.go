@@ -0,0 +1,103 @@
+//
+// Copyright (c) 2021 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package util
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalMsgPack(t *testing.T) {
+	data := struct {
+		Name   string        `json:"name"`
+		Small  int           `json:"small"`
+		Neg    int           `json:"neg"`
+		Large  int64         `json:"large"`
+		Float  float64       `json:"float"`
+		Flag   bool          `json:"flag"`
+		Items  []interface{} `json:"items"`
+		Binary []byte        `json:"binary"`
+	}{"dev", 1, -1, 300, 1.5, true, nil, []byte{0x01}}
+
+	result, err := MarshalMsgPack(data)
+	require.NoError(t, err)
+
+	// Keys are sorted and integers use their smallest format, so the encoding is deterministic
+	expected := "88" +
+		"a6" + hex.EncodeToString([]byte("binary")) + "a4" + hex.EncodeToString([]byte("AQ==")) +
+		"a4" + hex.EncodeToString([]byte("flag")) + "c3" +
+		"a5" + hex.EncodeToString([]byte("float")) + "cb3ff8000000000000" +
+		"a5" + hex.EncodeToString([]byte("items")) + "c0" +
+		"a5" + hex.EncodeToString([]byte("large")) + "cd012c" +
+		"a4" + hex.EncodeToString([]byte("name")) + "a3" + hex.EncodeToString([]byte("dev")) +
+		"a3" + hex.EncodeToString([]byte("neg")) + "ff" +
+		"a5" + hex.EncodeToString([]byte("small")) + "01"
+	assert.Equal(t, expected, hex.EncodeToString(result))
+}
+
+func TestUnmarshalMsgPack(t *testing.T) {
+	type target struct {
+		Name   string            `json:"name"`
+		Count  uint64            `json:"count"`
+		Neg    int32             `json:"neg"`
+		Float  float32           `json:"float"`
+		Binary []byte            `json:"binary"`
+		Values []int             `json:"values"`
+		Keys   map[string]string `json:"keys"`
+	}
+
+	tests := []struct {
+		Name          string
+		Data          string
+		Expected      target
+		ErrorExpected bool
+	}{
+		{"String", "81a46e616d65a3646576", target{Name: "dev"}, false},
+		{"str8", "81a46e616d65d903646576", target{Name: "dev"}, false},
+		{"uint64", "81a5636f756e74cfffffffffffffffff", target{Count: 18446744073709551615}, false},
+		{"int16", "81a36e6567d1ff38", target{Neg: -200}, false},
+		{"float32", "81a5666c6f6174ca3fc00000", target{Float: 1.5}, false},
+		{"bin8", "81a662696e617279c4020102", target{Binary: []byte{0x01, 0x02}}, false},
+		{"array16", "81a676616c756573dc0002017f", target{Values: []int{1, 127}}, false},
+		{"Integer map key", "81a46b65797381" + "01" + "a161", target{Keys: map[string]string{"1": "a"}}, false},
+		{"Truncated", "81a46e616d65a36465", target{}, true},
+		{"Bogus length", "81a676616c756573ddffffffff", target{}, true},
+		{"Trailing data", "80c0", target{}, true},
+		{"Unsupported format", "c1", target{}, true},
+		{"Type mismatch", "81a46e616d6501", target{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			data, err := hex.DecodeString(test.Data)
+			require.NoError(t, err)
+
+			var result target
+			err = UnmarshalMsgPack(data, &result)
+			if test.ErrorExpected {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, result)
+		})
+	}
+}